@@ -0,0 +1,26 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var AuditLogActionEnum = &struct {
+	ContactsExported      postgres.StringExpression
+	ContactDataExported   postgres.StringExpression
+	ContactAnonymized     postgres.StringExpression
+	ContactDeleted        postgres.StringExpression
+	ContactsMerged        postgres.StringExpression
+	LegacyConsentsGranted postgres.StringExpression
+}{
+	ContactsExported:      postgres.NewEnumValue("ContactsExported"),
+	ContactDataExported:   postgres.NewEnumValue("ContactDataExported"),
+	ContactAnonymized:     postgres.NewEnumValue("ContactAnonymized"),
+	ContactDeleted:        postgres.NewEnumValue("ContactDeleted"),
+	ContactsMerged:        postgres.NewEnumValue("ContactsMerged"),
+	LegacyConsentsGranted: postgres.NewEnumValue("LegacyConsentsGranted"),
+}
@@ -0,0 +1,22 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var BackgroundJobStatusEnum = &struct {
+	Queued    postgres.StringExpression
+	Running   postgres.StringExpression
+	Completed postgres.StringExpression
+	Failed    postgres.StringExpression
+}{
+	Queued:    postgres.NewEnumValue("Queued"),
+	Running:   postgres.NewEnumValue("Running"),
+	Completed: postgres.NewEnumValue("Completed"),
+	Failed:    postgres.NewEnumValue("Failed"),
+}
@@ -0,0 +1,20 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var BackgroundJobTypeEnum = &struct {
+	ContactImport        postgres.StringExpression
+	ContactDuplicateScan postgres.StringExpression
+	ContactListOperation postgres.StringExpression
+}{
+	ContactImport:        postgres.NewEnumValue("ContactImport"),
+	ContactDuplicateScan: postgres.NewEnumValue("ContactDuplicateScan"),
+	ContactListOperation: postgres.NewEnumValue("ContactListOperation"),
+}
@@ -0,0 +1,28 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var ContactActivityTypeEnum = &struct {
+	AttributesChanged postgres.StringExpression
+	StatusChanged     postgres.StringExpression
+	ListJoined        postgres.StringExpression
+	ListLeft          postgres.StringExpression
+	Merged            postgres.StringExpression
+	OptedIn           postgres.StringExpression
+	OptedOut          postgres.StringExpression
+}{
+	AttributesChanged: postgres.NewEnumValue("AttributesChanged"),
+	StatusChanged:     postgres.NewEnumValue("StatusChanged"),
+	ListJoined:        postgres.NewEnumValue("ListJoined"),
+	ListLeft:          postgres.NewEnumValue("ListLeft"),
+	Merged:            postgres.NewEnumValue("Merged"),
+	OptedIn:           postgres.NewEnumValue("OptedIn"),
+	OptedOut:          postgres.NewEnumValue("OptedOut"),
+}
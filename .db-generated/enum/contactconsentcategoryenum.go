@@ -0,0 +1,18 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var ContactConsentCategoryEnum = &struct {
+	Marketing postgres.StringExpression
+	Utility   postgres.StringExpression
+}{
+	Marketing: postgres.NewEnumValue("Marketing"),
+	Utility:   postgres.NewEnumValue("Utility"),
+}
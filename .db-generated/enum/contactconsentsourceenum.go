@@ -0,0 +1,24 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var ContactConsentSourceEnum = &struct {
+	API     postgres.StringExpression
+	Import  postgres.StringExpression
+	Form    postgres.StringExpression
+	Keyword postgres.StringExpression
+	Legacy  postgres.StringExpression
+}{
+	API:     postgres.NewEnumValue("Api"),
+	Import:  postgres.NewEnumValue("Import"),
+	Form:    postgres.NewEnumValue("Form"),
+	Keyword: postgres.NewEnumValue("Keyword"),
+	Legacy:  postgres.NewEnumValue("Legacy"),
+}
@@ -0,0 +1,20 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var ContactConsentStatusEnum = &struct {
+	Granted postgres.StringExpression
+	Revoked postgres.StringExpression
+	Pending postgres.StringExpression
+}{
+	Granted: postgres.NewEnumValue("Granted"),
+	Revoked: postgres.NewEnumValue("Revoked"),
+	Pending: postgres.NewEnumValue("Pending"),
+}
@@ -0,0 +1,24 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var ContactFieldTypeEnum = &struct {
+	String  postgres.StringExpression
+	Number  postgres.StringExpression
+	Date    postgres.StringExpression
+	Boolean postgres.StringExpression
+	Enum    postgres.StringExpression
+}{
+	String:  postgres.NewEnumValue("String"),
+	Number:  postgres.NewEnumValue("Number"),
+	Date:    postgres.NewEnumValue("Date"),
+	Boolean: postgres.NewEnumValue("Boolean"),
+	Enum:    postgres.NewEnumValue("Enum"),
+}
@@ -14,9 +14,11 @@ var ConversationStatusEnum = &struct {
 	Closed   postgres.StringExpression
 	Deleted  postgres.StringExpression
 	Resolved postgres.StringExpression
+	Snoozed  postgres.StringExpression
 }{
 	Active:   postgres.NewEnumValue("Active"),
 	Closed:   postgres.NewEnumValue("Closed"),
 	Deleted:  postgres.NewEnumValue("Deleted"),
 	Resolved: postgres.NewEnumValue("Resolved"),
+	Snoozed:  postgres.NewEnumValue("Snoozed"),
 }
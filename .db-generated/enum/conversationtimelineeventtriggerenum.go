@@ -0,0 +1,22 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var ConversationTimelineEventTriggerEnum = &struct {
+	Member        postgres.StringExpression
+	Contact       postgres.StringExpression
+	Inactivity    postgres.StringExpression
+	SnoozeExpired postgres.StringExpression
+}{
+	Member:        postgres.NewEnumValue("Member"),
+	Contact:       postgres.NewEnumValue("Contact"),
+	Inactivity:    postgres.NewEnumValue("Inactivity"),
+	SnoozeExpired: postgres.NewEnumValue("SnoozeExpired"),
+}
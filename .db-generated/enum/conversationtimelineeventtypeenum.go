@@ -0,0 +1,24 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var ConversationTimelineEventTypeEnum = &struct {
+	Resolved postgres.StringExpression
+	Reopened postgres.StringExpression
+	Snoozed  postgres.StringExpression
+	Woken    postgres.StringExpression
+	Closed   postgres.StringExpression
+}{
+	Resolved: postgres.NewEnumValue("Resolved"),
+	Reopened: postgres.NewEnumValue("Reopened"),
+	Snoozed:  postgres.NewEnumValue("Snoozed"),
+	Woken:    postgres.NewEnumValue("Woken"),
+	Closed:   postgres.NewEnumValue("Closed"),
+}
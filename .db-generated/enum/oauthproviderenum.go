@@ -11,6 +11,8 @@ import "github.com/go-jet/jet/v2/postgres"
 
 var OauthProviderEnum = &struct {
 	Google postgres.StringExpression
+	Oidc   postgres.StringExpression
 }{
 	Google: postgres.NewEnumValue("Google"),
+	Oidc:   postgres.NewEnumValue("Oidc"),
 }
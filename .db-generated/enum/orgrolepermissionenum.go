@@ -51,6 +51,10 @@ var OrgRolePermissionEnum = &struct {
 	UpdateColonIntegrationsettings postgres.StringExpression
 	GetColonMessagetemplates       postgres.StringExpression
 	GetColonPhonenumbers           postgres.StringExpression
+	GetColonCannedresponse         postgres.StringExpression
+	CreateColonCannedresponse      postgres.StringExpression
+	UpdateColonCannedresponse      postgres.StringExpression
+	DeleteColonCannedresponse      postgres.StringExpression
 }{
 	GetColonOrganizationmember:     postgres.NewEnumValue("Get:OrganizationMember"),
 	CreateColonOrganizationmember:  postgres.NewEnumValue("Create:OrganizationMember"),
@@ -93,4 +97,8 @@ var OrgRolePermissionEnum = &struct {
 	UpdateColonIntegrationsettings: postgres.NewEnumValue("Update:IntegrationSettings"),
 	GetColonMessagetemplates:       postgres.NewEnumValue("Get:MessageTemplates"),
 	GetColonPhonenumbers:           postgres.NewEnumValue("Get:PhoneNumbers"),
+	GetColonCannedresponse:         postgres.NewEnumValue("Get:CannedResponse"),
+	CreateColonCannedresponse:      postgres.NewEnumValue("Create:CannedResponse"),
+	UpdateColonCannedresponse:      postgres.NewEnumValue("Update:CannedResponse"),
+	DeleteColonCannedresponse:      postgres.NewEnumValue("Delete:CannedResponse"),
 }
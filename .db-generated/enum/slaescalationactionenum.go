@@ -0,0 +1,20 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var SlaEscalationActionEnum = &struct {
+	Notify   postgres.StringExpression
+	Reassign postgres.StringExpression
+	Tag      postgres.StringExpression
+}{
+	Notify:   postgres.NewEnumValue("Notify"),
+	Reassign: postgres.NewEnumValue("Reassign"),
+	Tag:      postgres.NewEnumValue("Tag"),
+}
@@ -0,0 +1,22 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package enum
+
+import "github.com/go-jet/jet/v2/postgres"
+
+var WhatsappBusinessAccountHealthStatusEnum = &struct {
+	Unknown  postgres.StringExpression
+	Healthy  postgres.StringExpression
+	Expiring postgres.StringExpression
+	Invalid  postgres.StringExpression
+}{
+	Unknown:  postgres.NewEnumValue("Unknown"),
+	Healthy:  postgres.NewEnumValue("Healthy"),
+	Expiring: postgres.NewEnumValue("Expiring"),
+	Invalid:  postgres.NewEnumValue("Invalid"),
+}
@@ -0,0 +1,56 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type AuditLogActionEnum string
+
+const (
+	AuditLogActionEnum_ContactsExported      AuditLogActionEnum = "ContactsExported"
+	AuditLogActionEnum_ContactDataExported   AuditLogActionEnum = "ContactDataExported"
+	AuditLogActionEnum_ContactAnonymized     AuditLogActionEnum = "ContactAnonymized"
+	AuditLogActionEnum_ContactDeleted        AuditLogActionEnum = "ContactDeleted"
+	AuditLogActionEnum_ContactsMerged        AuditLogActionEnum = "ContactsMerged"
+	AuditLogActionEnum_LegacyConsentsGranted AuditLogActionEnum = "LegacyConsentsGranted"
+)
+
+func (e *AuditLogActionEnum) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "ContactsExported":
+		*e = AuditLogActionEnum_ContactsExported
+	case "ContactDataExported":
+		*e = AuditLogActionEnum_ContactDataExported
+	case "ContactAnonymized":
+		*e = AuditLogActionEnum_ContactAnonymized
+	case "ContactDeleted":
+		*e = AuditLogActionEnum_ContactDeleted
+	case "ContactsMerged":
+		*e = AuditLogActionEnum_ContactsMerged
+	case "LegacyConsentsGranted":
+		*e = AuditLogActionEnum_LegacyConsentsGranted
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for AuditLogActionEnum enum")
+	}
+
+	return nil
+}
+
+func (e AuditLogActionEnum) String() string {
+	return string(e)
+}
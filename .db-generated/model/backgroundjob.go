@@ -0,0 +1,31 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type BackgroundJob struct {
+	UniqueId                      uuid.UUID `sql:"primary_key"`
+	CreatedAt                     time.Time
+	UpdatedAt                     time.Time
+	OrganizationId                uuid.UUID
+	CreatedByOrganizationMemberId *uuid.UUID
+	Type                          BackgroundJobTypeEnum
+	Status                        BackgroundJobStatusEnum
+	Parameters                    string
+	TotalItems                    *int32
+	ProcessedItems                int32
+	FailedItems                   int32
+	Result                        *string
+	Error                         *string
+	StartedAt                     *time.Time
+	CompletedAt                   *time.Time
+}
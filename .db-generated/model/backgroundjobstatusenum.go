@@ -0,0 +1,50 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type BackgroundJobStatusEnum string
+
+const (
+	BackgroundJobStatusEnum_Queued    BackgroundJobStatusEnum = "Queued"
+	BackgroundJobStatusEnum_Running   BackgroundJobStatusEnum = "Running"
+	BackgroundJobStatusEnum_Completed BackgroundJobStatusEnum = "Completed"
+	BackgroundJobStatusEnum_Failed    BackgroundJobStatusEnum = "Failed"
+)
+
+func (e *BackgroundJobStatusEnum) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "Queued":
+		*e = BackgroundJobStatusEnum_Queued
+	case "Running":
+		*e = BackgroundJobStatusEnum_Running
+	case "Completed":
+		*e = BackgroundJobStatusEnum_Completed
+	case "Failed":
+		*e = BackgroundJobStatusEnum_Failed
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for BackgroundJobStatusEnum enum")
+	}
+
+	return nil
+}
+
+func (e BackgroundJobStatusEnum) String() string {
+	return string(e)
+}
@@ -0,0 +1,47 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type BackgroundJobTypeEnum string
+
+const (
+	BackgroundJobTypeEnum_ContactImport        BackgroundJobTypeEnum = "ContactImport"
+	BackgroundJobTypeEnum_ContactDuplicateScan BackgroundJobTypeEnum = "ContactDuplicateScan"
+	BackgroundJobTypeEnum_ContactListOperation BackgroundJobTypeEnum = "ContactListOperation"
+)
+
+func (e *BackgroundJobTypeEnum) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "ContactImport":
+		*e = BackgroundJobTypeEnum_ContactImport
+	case "ContactDuplicateScan":
+		*e = BackgroundJobTypeEnum_ContactDuplicateScan
+	case "ContactListOperation":
+		*e = BackgroundJobTypeEnum_ContactListOperation
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for BackgroundJobTypeEnum enum")
+	}
+
+	return nil
+}
+
+func (e BackgroundJobTypeEnum) String() string {
+	return string(e)
+}
@@ -0,0 +1,59 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type ContactActivityTypeEnum string
+
+const (
+	ContactActivityTypeEnum_AttributesChanged ContactActivityTypeEnum = "AttributesChanged"
+	ContactActivityTypeEnum_StatusChanged     ContactActivityTypeEnum = "StatusChanged"
+	ContactActivityTypeEnum_ListJoined        ContactActivityTypeEnum = "ListJoined"
+	ContactActivityTypeEnum_ListLeft          ContactActivityTypeEnum = "ListLeft"
+	ContactActivityTypeEnum_Merged            ContactActivityTypeEnum = "Merged"
+	ContactActivityTypeEnum_OptedIn           ContactActivityTypeEnum = "OptedIn"
+	ContactActivityTypeEnum_OptedOut          ContactActivityTypeEnum = "OptedOut"
+)
+
+func (e *ContactActivityTypeEnum) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "AttributesChanged":
+		*e = ContactActivityTypeEnum_AttributesChanged
+	case "StatusChanged":
+		*e = ContactActivityTypeEnum_StatusChanged
+	case "ListJoined":
+		*e = ContactActivityTypeEnum_ListJoined
+	case "ListLeft":
+		*e = ContactActivityTypeEnum_ListLeft
+	case "Merged":
+		*e = ContactActivityTypeEnum_Merged
+	case "OptedIn":
+		*e = ContactActivityTypeEnum_OptedIn
+	case "OptedOut":
+		*e = ContactActivityTypeEnum_OptedOut
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for ContactActivityTypeEnum enum")
+	}
+
+	return nil
+}
+
+func (e ContactActivityTypeEnum) String() string {
+	return string(e)
+}
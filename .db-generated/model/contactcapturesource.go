@@ -0,0 +1,30 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ContactCaptureSource struct {
+	UniqueId                uuid.UUID `sql:"primary_key"`
+	CreatedAt               time.Time
+	UpdatedAt               time.Time
+	OrganizationId          uuid.UUID
+	ContactListId           uuid.UUID
+	Type                    ContactCaptureSourceTypeEnum
+	Name                    string
+	Code                    string
+	PhoneNumber             *string
+	PrefilledMessage        *string
+	ConsentText             *string
+	Attributes              *string
+	WelcomeCannedResponseId *uuid.UUID
+	IsActive                bool
+}
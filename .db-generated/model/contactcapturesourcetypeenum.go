@@ -0,0 +1,44 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type ContactCaptureSourceTypeEnum string
+
+const (
+	ContactCaptureSourceTypeEnum_Form ContactCaptureSourceTypeEnum = "Form"
+	ContactCaptureSourceTypeEnum_Link ContactCaptureSourceTypeEnum = "Link"
+)
+
+func (e *ContactCaptureSourceTypeEnum) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "Form":
+		*e = ContactCaptureSourceTypeEnum_Form
+	case "Link":
+		*e = ContactCaptureSourceTypeEnum_Link
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for ContactCaptureSourceTypeEnum enum")
+	}
+
+	return nil
+}
+
+func (e ContactCaptureSourceTypeEnum) String() string {
+	return string(e)
+}
@@ -0,0 +1,28 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ContactConsent struct {
+	UniqueId               uuid.UUID `sql:"primary_key"`
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+	OrganizationId         uuid.UUID
+	ContactId              uuid.UUID
+	Category               ContactConsentCategoryEnum
+	Status                 ContactConsentStatusEnum
+	Source                 ContactConsentSourceEnum
+	ChangedAt              time.Time
+	Evidence               *string
+	ContactCaptureSourceId *uuid.UUID
+	OrganizationMemberId   *uuid.UUID
+}
@@ -0,0 +1,44 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type ContactConsentCategoryEnum string
+
+const (
+	ContactConsentCategoryEnum_Marketing ContactConsentCategoryEnum = "Marketing"
+	ContactConsentCategoryEnum_Utility   ContactConsentCategoryEnum = "Utility"
+)
+
+func (e *ContactConsentCategoryEnum) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "Marketing":
+		*e = ContactConsentCategoryEnum_Marketing
+	case "Utility":
+		*e = ContactConsentCategoryEnum_Utility
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for ContactConsentCategoryEnum enum")
+	}
+
+	return nil
+}
+
+func (e ContactConsentCategoryEnum) String() string {
+	return string(e)
+}
@@ -0,0 +1,53 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type ContactConsentSourceEnum string
+
+const (
+	ContactConsentSourceEnum_API     ContactConsentSourceEnum = "Api"
+	ContactConsentSourceEnum_Import  ContactConsentSourceEnum = "Import"
+	ContactConsentSourceEnum_Form    ContactConsentSourceEnum = "Form"
+	ContactConsentSourceEnum_Keyword ContactConsentSourceEnum = "Keyword"
+	ContactConsentSourceEnum_Legacy  ContactConsentSourceEnum = "Legacy"
+)
+
+func (e *ContactConsentSourceEnum) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "Api":
+		*e = ContactConsentSourceEnum_API
+	case "Import":
+		*e = ContactConsentSourceEnum_Import
+	case "Form":
+		*e = ContactConsentSourceEnum_Form
+	case "Keyword":
+		*e = ContactConsentSourceEnum_Keyword
+	case "Legacy":
+		*e = ContactConsentSourceEnum_Legacy
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for ContactConsentSourceEnum enum")
+	}
+
+	return nil
+}
+
+func (e ContactConsentSourceEnum) String() string {
+	return string(e)
+}
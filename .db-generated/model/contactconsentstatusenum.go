@@ -0,0 +1,47 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type ContactConsentStatusEnum string
+
+const (
+	ContactConsentStatusEnum_Granted ContactConsentStatusEnum = "Granted"
+	ContactConsentStatusEnum_Revoked ContactConsentStatusEnum = "Revoked"
+	ContactConsentStatusEnum_Pending ContactConsentStatusEnum = "Pending"
+)
+
+func (e *ContactConsentStatusEnum) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "Granted":
+		*e = ContactConsentStatusEnum_Granted
+	case "Revoked":
+		*e = ContactConsentStatusEnum_Revoked
+	case "Pending":
+		*e = ContactConsentStatusEnum_Pending
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for ContactConsentStatusEnum enum")
+	}
+
+	return nil
+}
+
+func (e ContactConsentStatusEnum) String() string {
+	return string(e)
+}
@@ -0,0 +1,53 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type ContactFieldTypeEnum string
+
+const (
+	ContactFieldTypeEnum_String  ContactFieldTypeEnum = "String"
+	ContactFieldTypeEnum_Number  ContactFieldTypeEnum = "Number"
+	ContactFieldTypeEnum_Date    ContactFieldTypeEnum = "Date"
+	ContactFieldTypeEnum_Boolean ContactFieldTypeEnum = "Boolean"
+	ContactFieldTypeEnum_Enum    ContactFieldTypeEnum = "Enum"
+)
+
+func (e *ContactFieldTypeEnum) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "String":
+		*e = ContactFieldTypeEnum_String
+	case "Number":
+		*e = ContactFieldTypeEnum_Number
+	case "Date":
+		*e = ContactFieldTypeEnum_Date
+	case "Boolean":
+		*e = ContactFieldTypeEnum_Boolean
+	case "Enum":
+		*e = ContactFieldTypeEnum_Enum
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for ContactFieldTypeEnum enum")
+	}
+
+	return nil
+}
+
+func (e ContactFieldTypeEnum) String() string {
+	return string(e)
+}
@@ -13,13 +13,21 @@ import (
 )
 
 type Conversation struct {
-	UniqueId              uuid.UUID `sql:"primary_key"`
-	CreatedAt             time.Time
-	UpdatedAt             time.Time
-	ContactId             uuid.UUID
-	OrganizationId        uuid.UUID
-	Status                ConversationStatusEnum
-	PhoneNumberUsed       string
-	InitiatedBy           ConversationInitiatedEnum
-	InitiatedByCampaignId *uuid.UUID
+	UniqueId                uuid.UUID `sql:"primary_key"`
+	CreatedAt               time.Time
+	UpdatedAt               time.Time
+	ContactId               uuid.UUID
+	OrganizationId          uuid.UUID
+	Status                  ConversationStatusEnum
+	PhoneNumberUsed         string
+	InitiatedBy             ConversationInitiatedEnum
+	InitiatedByCampaignId   *uuid.UUID
+	SlaPolicyId             *uuid.UUID
+	FirstResponseDueAt      *time.Time
+	FirstRespondedAt        *time.Time
+	FirstResponseBreachedAt *time.Time
+	ResolutionDueAt         *time.Time
+	ResolvedAt              *time.Time
+	ResolutionBreachedAt    *time.Time
+	SnoozedUntil            *time.Time
 }
@@ -0,0 +1,19 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ConversationNoteMention struct {
+	CreatedAt            time.Time
+	ConversationNoteId   uuid.UUID `sql:"primary_key"`
+	OrganizationMemberId uuid.UUID `sql:"primary_key"`
+}
@@ -0,0 +1,44 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type ConversationRoutingStrategyEnum string
+
+const (
+	ConversationRoutingStrategyEnum_RoundRobin ConversationRoutingStrategyEnum = "RoundRobin"
+	ConversationRoutingStrategyEnum_LeastBusy  ConversationRoutingStrategyEnum = "LeastBusy"
+)
+
+func (e *ConversationRoutingStrategyEnum) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "RoundRobin":
+		*e = ConversationRoutingStrategyEnum_RoundRobin
+	case "LeastBusy":
+		*e = ConversationRoutingStrategyEnum_LeastBusy
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for ConversationRoutingStrategyEnum enum")
+	}
+
+	return nil
+}
+
+func (e ConversationRoutingStrategyEnum) String() string {
+	return string(e)
+}
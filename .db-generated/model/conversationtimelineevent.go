@@ -0,0 +1,26 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ConversationTimelineEvent struct {
+	UniqueId                  uuid.UUID `sql:"primary_key"`
+	CreatedAt                 time.Time
+	ConversationId            uuid.UUID
+	OrganizationId            uuid.UUID
+	EventType                 ConversationTimelineEventTypeEnum
+	Trigger                   ConversationTimelineEventTriggerEnum
+	FromStatus                ConversationStatusEnum
+	ToStatus                  ConversationStatusEnum
+	ActorOrganizationMemberId *uuid.UUID
+	SnoozedUntil              *time.Time
+}
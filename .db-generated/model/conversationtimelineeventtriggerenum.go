@@ -0,0 +1,50 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type ConversationTimelineEventTriggerEnum string
+
+const (
+	ConversationTimelineEventTriggerEnum_Member        ConversationTimelineEventTriggerEnum = "Member"
+	ConversationTimelineEventTriggerEnum_Contact       ConversationTimelineEventTriggerEnum = "Contact"
+	ConversationTimelineEventTriggerEnum_Inactivity    ConversationTimelineEventTriggerEnum = "Inactivity"
+	ConversationTimelineEventTriggerEnum_SnoozeExpired ConversationTimelineEventTriggerEnum = "SnoozeExpired"
+)
+
+func (e *ConversationTimelineEventTriggerEnum) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "Member":
+		*e = ConversationTimelineEventTriggerEnum_Member
+	case "Contact":
+		*e = ConversationTimelineEventTriggerEnum_Contact
+	case "Inactivity":
+		*e = ConversationTimelineEventTriggerEnum_Inactivity
+	case "SnoozeExpired":
+		*e = ConversationTimelineEventTriggerEnum_SnoozeExpired
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for ConversationTimelineEventTriggerEnum enum")
+	}
+
+	return nil
+}
+
+func (e ConversationTimelineEventTriggerEnum) String() string {
+	return string(e)
+}
@@ -0,0 +1,53 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type ConversationTimelineEventTypeEnum string
+
+const (
+	ConversationTimelineEventTypeEnum_Resolved ConversationTimelineEventTypeEnum = "Resolved"
+	ConversationTimelineEventTypeEnum_Reopened ConversationTimelineEventTypeEnum = "Reopened"
+	ConversationTimelineEventTypeEnum_Snoozed  ConversationTimelineEventTypeEnum = "Snoozed"
+	ConversationTimelineEventTypeEnum_Woken    ConversationTimelineEventTypeEnum = "Woken"
+	ConversationTimelineEventTypeEnum_Closed   ConversationTimelineEventTypeEnum = "Closed"
+)
+
+func (e *ConversationTimelineEventTypeEnum) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "Resolved":
+		*e = ConversationTimelineEventTypeEnum_Resolved
+	case "Reopened":
+		*e = ConversationTimelineEventTypeEnum_Reopened
+	case "Snoozed":
+		*e = ConversationTimelineEventTypeEnum_Snoozed
+	case "Woken":
+		*e = ConversationTimelineEventTypeEnum_Woken
+	case "Closed":
+		*e = ConversationTimelineEventTypeEnum_Closed
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for ConversationTimelineEventTypeEnum enum")
+	}
+
+	return nil
+}
+
+func (e ConversationTimelineEventTypeEnum) String() string {
+	return string(e)
+}
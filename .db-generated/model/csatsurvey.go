@@ -0,0 +1,27 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type CsatSurvey struct {
+	UniqueId             uuid.UUID `sql:"primary_key"`
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	ConversationId       uuid.UUID
+	OrganizationId       uuid.UUID
+	OrganizationMemberId *uuid.UUID
+	PhoneNumberUsed      string
+	WhatsAppMessageId    *string
+	Rating               *int32
+	Comment              *string
+	RespondedAt          *time.Time
+}
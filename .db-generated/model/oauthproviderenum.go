@@ -13,6 +13,7 @@ type OauthProviderEnum string
 
 const (
 	OauthProviderEnum_Google OauthProviderEnum = "Google"
+	OauthProviderEnum_Oidc   OauthProviderEnum = "Oidc"
 )
 
 func (e *OauthProviderEnum) Scan(value interface{}) error {
@@ -29,6 +30,8 @@ func (e *OauthProviderEnum) Scan(value interface{}) error {
 	switch enumValue {
 	case "Google":
 		*e = OauthProviderEnum_Google
+	case "Oidc":
+		*e = OauthProviderEnum_Oidc
 	default:
 		return errors.New("jet: Invalid scan value '" + enumValue + "' for OauthProviderEnum enum")
 	}
@@ -13,21 +13,25 @@ import (
 )
 
 type Organization struct {
-	UniqueId           uuid.UUID `sql:"primary_key"`
-	CreatedAt          time.Time
-	UpdatedAt          time.Time
-	Name               string
-	Description        *string
-	WebsiteUrl         *string
-	LogoUrl            *string
-	FaviconUrl         string
-	SlackWebhookUrl    *string
-	SlackChannel       *string
-	SmtpClientHost     *string
-	SmtpClientUsername *string
-	SmtpClientPassword *string
-	SmtpClientPort     *string
-	IsAiEnabled        bool
-	AiModel            *AiModelEnum
-	AiApiKey           string
+	UniqueId                          uuid.UUID `sql:"primary_key"`
+	CreatedAt                         time.Time
+	UpdatedAt                         time.Time
+	Name                              string
+	Description                       *string
+	WebsiteUrl                        *string
+	LogoUrl                           *string
+	FaviconUrl                        string
+	SlackWebhookUrl                   *string
+	SlackChannel                      *string
+	SmtpClientHost                    *string
+	SmtpClientUsername                *string
+	SmtpClientPassword                *string
+	SmtpClientPort                    *string
+	IsAiEnabled                       bool
+	AiModel                           *AiModelEnum
+	AiApiKey                          string
+	IsTwoFactorRequiredForOwners      bool
+	ConversationAutoCloseAfterMinutes *int32
+	IsCsatEnabled                     bool
+	CsatMessage                       *string
 }
@@ -20,4 +20,5 @@ type OrganizationMember struct {
 	OrganizationId uuid.UUID
 	UserId         uuid.UUID
 	InviteId       *uuid.UUID
+	IsAvailable    bool
 }
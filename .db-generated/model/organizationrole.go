@@ -13,11 +13,12 @@ import (
 )
 
 type OrganizationRole struct {
-	UniqueId       uuid.UUID `sql:"primary_key"`
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	Name           string
-	Description    *string
-	Permissions    string
-	OrganizationId uuid.UUID
+	UniqueId            uuid.UUID `sql:"primary_key"`
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Description         *string
+	Permissions         string
+	OrganizationId      uuid.UUID
+	IsTwoFactorRequired bool
 }
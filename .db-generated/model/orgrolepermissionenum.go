@@ -53,6 +53,10 @@ const (
 	OrgRolePermissionEnum_UpdateColonIntegrationsettings OrgRolePermissionEnum = "Update:IntegrationSettings"
 	OrgRolePermissionEnum_GetColonMessagetemplates       OrgRolePermissionEnum = "Get:MessageTemplates"
 	OrgRolePermissionEnum_GetColonPhonenumbers           OrgRolePermissionEnum = "Get:PhoneNumbers"
+	OrgRolePermissionEnum_GetColonCannedresponse         OrgRolePermissionEnum = "Get:CannedResponse"
+	OrgRolePermissionEnum_CreateColonCannedresponse      OrgRolePermissionEnum = "Create:CannedResponse"
+	OrgRolePermissionEnum_UpdateColonCannedresponse      OrgRolePermissionEnum = "Update:CannedResponse"
+	OrgRolePermissionEnum_DeleteColonCannedresponse      OrgRolePermissionEnum = "Delete:CannedResponse"
 )
 
 func (e *OrgRolePermissionEnum) Scan(value interface{}) error {
@@ -149,6 +153,14 @@ func (e *OrgRolePermissionEnum) Scan(value interface{}) error {
 		*e = OrgRolePermissionEnum_GetColonMessagetemplates
 	case "Get:PhoneNumbers":
 		*e = OrgRolePermissionEnum_GetColonPhonenumbers
+	case "Get:CannedResponse":
+		*e = OrgRolePermissionEnum_GetColonCannedresponse
+	case "Create:CannedResponse":
+		*e = OrgRolePermissionEnum_CreateColonCannedresponse
+	case "Update:CannedResponse":
+		*e = OrgRolePermissionEnum_UpdateColonCannedresponse
+	case "Delete:CannedResponse":
+		*e = OrgRolePermissionEnum_DeleteColonCannedresponse
 	default:
 		return errors.New("jet: Invalid scan value '" + enumValue + "' for OrgRolePermissionEnum enum")
 	}
@@ -0,0 +1,47 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type SlaEscalationActionEnum string
+
+const (
+	SlaEscalationActionEnum_Notify   SlaEscalationActionEnum = "Notify"
+	SlaEscalationActionEnum_Reassign SlaEscalationActionEnum = "Reassign"
+	SlaEscalationActionEnum_Tag      SlaEscalationActionEnum = "Tag"
+)
+
+func (e *SlaEscalationActionEnum) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "Notify":
+		*e = SlaEscalationActionEnum_Notify
+	case "Reassign":
+		*e = SlaEscalationActionEnum_Reassign
+	case "Tag":
+		*e = SlaEscalationActionEnum_Tag
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for SlaEscalationActionEnum enum")
+	}
+
+	return nil
+}
+
+func (e SlaEscalationActionEnum) String() string {
+	return string(e)
+}
@@ -0,0 +1,31 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type SlaPolicy struct {
+	UniqueId                       uuid.UUID `sql:"primary_key"`
+	CreatedAt                      time.Time
+	UpdatedAt                      time.Time
+	OrganizationId                 uuid.UUID
+	Name                           string
+	IsDefault                      bool
+	IsEnabled                      bool
+	PhoneNumberId                  *string
+	FirstResponseTimeInMinutes     int32
+	ResolutionTimeInMinutes        *int32
+	Timezone                       string
+	BusinessCalendar               *string
+	EscalationAction               *SlaEscalationActionEnum
+	EscalationTagId                *uuid.UUID
+	EscalationOrganizationMemberId *uuid.UUID
+}
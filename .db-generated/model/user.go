@@ -13,15 +13,17 @@ import (
 )
 
 type User struct {
-	UniqueId          uuid.UUID `sql:"primary_key"`
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
-	Name              string
-	Email             string
-	PhoneNumber       *string
-	Username          string
-	Password          *string
-	OauthProvider     *OauthProviderEnum
-	ProfilePictureUrl *string
-	Status            UserAccountStatusEnum
+	UniqueId           uuid.UUID `sql:"primary_key"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	Name               string
+	Email              string
+	PhoneNumber        *string
+	Username           string
+	Password           *string
+	OauthProvider      *OauthProviderEnum
+	ProfilePictureUrl  *string
+	Status             UserAccountStatusEnum
+	TwoFactorSecret    *string
+	TwoFactorEnabledAt *time.Time
 }
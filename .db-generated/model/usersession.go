@@ -0,0 +1,27 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type UserSession struct {
+	UniqueId         uuid.UUID `sql:"primary_key"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	UserId           uuid.UUID
+	OrganizationId   *uuid.UUID
+	RefreshTokenHash string
+	UserAgent        *string
+	IpAddress        *string
+	LastUsedAt       time.Time
+	ExpiresAt        time.Time
+	RevokedAt        *time.Time
+}
@@ -13,11 +13,16 @@ import (
 )
 
 type WhatsappBusinessAccount struct {
-	UniqueId       uuid.UUID `sql:"primary_key"`
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	AccountId      string
-	AccessToken    string
-	WebhookSecret  string
-	OrganizationId uuid.UUID
+	UniqueId        uuid.UUID `sql:"primary_key"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	AccountId       string
+	AccessToken     string
+	WebhookSecret   string
+	OrganizationId  uuid.UUID
+	TokenExpiresAt  *time.Time
+	TokenScopes     *string
+	HealthStatus    WhatsappBusinessAccountHealthStatusEnum
+	HealthCheckedAt *time.Time
+	HealthError     *string
 }
@@ -0,0 +1,50 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package model
+
+import "errors"
+
+type WhatsappBusinessAccountHealthStatusEnum string
+
+const (
+	WhatsappBusinessAccountHealthStatusEnum_Unknown  WhatsappBusinessAccountHealthStatusEnum = "Unknown"
+	WhatsappBusinessAccountHealthStatusEnum_Healthy  WhatsappBusinessAccountHealthStatusEnum = "Healthy"
+	WhatsappBusinessAccountHealthStatusEnum_Expiring WhatsappBusinessAccountHealthStatusEnum = "Expiring"
+	WhatsappBusinessAccountHealthStatusEnum_Invalid  WhatsappBusinessAccountHealthStatusEnum = "Invalid"
+)
+
+func (e *WhatsappBusinessAccountHealthStatusEnum) Scan(value interface{}) error {
+	var enumValue string
+	switch val := value.(type) {
+	case string:
+		enumValue = val
+	case []byte:
+		enumValue = string(val)
+	default:
+		return errors.New("jet: Invalid scan value for AllTypesEnum enum. Enum value has to be of type string or []byte")
+	}
+
+	switch enumValue {
+	case "Unknown":
+		*e = WhatsappBusinessAccountHealthStatusEnum_Unknown
+	case "Healthy":
+		*e = WhatsappBusinessAccountHealthStatusEnum_Healthy
+	case "Expiring":
+		*e = WhatsappBusinessAccountHealthStatusEnum_Expiring
+	case "Invalid":
+		*e = WhatsappBusinessAccountHealthStatusEnum_Invalid
+	default:
+		return errors.New("jet: Invalid scan value '" + enumValue + "' for WhatsappBusinessAccountHealthStatusEnum enum")
+	}
+
+	return nil
+}
+
+func (e WhatsappBusinessAccountHealthStatusEnum) String() string {
+	return string(e)
+}
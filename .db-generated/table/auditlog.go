@@ -0,0 +1,93 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var AuditLog = newAuditLogTable("public", "AuditLog", "")
+
+type auditLogTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId             postgres.ColumnString
+	CreatedAt            postgres.ColumnTimestampz
+	OrganizationId       postgres.ColumnString
+	OrganizationMemberId postgres.ColumnString
+	Action               postgres.ColumnString
+	EntityId             postgres.ColumnString
+	Details              postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type AuditLogTable struct {
+	auditLogTable
+
+	EXCLUDED auditLogTable
+}
+
+// AS creates new AuditLogTable with assigned alias
+func (a AuditLogTable) AS(alias string) *AuditLogTable {
+	return newAuditLogTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new AuditLogTable with assigned schema name
+func (a AuditLogTable) FromSchema(schemaName string) *AuditLogTable {
+	return newAuditLogTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new AuditLogTable with assigned table prefix
+func (a AuditLogTable) WithPrefix(prefix string) *AuditLogTable {
+	return newAuditLogTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new AuditLogTable with assigned table suffix
+func (a AuditLogTable) WithSuffix(suffix string) *AuditLogTable {
+	return newAuditLogTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newAuditLogTable(schemaName, tableName, alias string) *AuditLogTable {
+	return &AuditLogTable{
+		auditLogTable: newAuditLogTableImpl(schemaName, tableName, alias),
+		EXCLUDED:      newAuditLogTableImpl("", "excluded", ""),
+	}
+}
+
+func newAuditLogTableImpl(schemaName, tableName, alias string) auditLogTable {
+	var (
+		UniqueIdColumn             = postgres.StringColumn("UniqueId")
+		CreatedAtColumn            = postgres.TimestampzColumn("CreatedAt")
+		OrganizationIdColumn       = postgres.StringColumn("OrganizationId")
+		OrganizationMemberIdColumn = postgres.StringColumn("OrganizationMemberId")
+		ActionColumn               = postgres.StringColumn("Action")
+		EntityIdColumn             = postgres.StringColumn("EntityId")
+		DetailsColumn              = postgres.StringColumn("Details")
+		allColumns                 = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, OrganizationIdColumn, OrganizationMemberIdColumn, ActionColumn, EntityIdColumn, DetailsColumn}
+		mutableColumns             = postgres.ColumnList{CreatedAtColumn, OrganizationIdColumn, OrganizationMemberIdColumn, ActionColumn, EntityIdColumn, DetailsColumn}
+	)
+
+	return auditLogTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:             UniqueIdColumn,
+		CreatedAt:            CreatedAtColumn,
+		OrganizationId:       OrganizationIdColumn,
+		OrganizationMemberId: OrganizationMemberIdColumn,
+		Action:               ActionColumn,
+		EntityId:             EntityIdColumn,
+		Details:              DetailsColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
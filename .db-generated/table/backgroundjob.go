@@ -0,0 +1,117 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var BackgroundJob = newBackgroundJobTable("public", "BackgroundJob", "")
+
+type backgroundJobTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId                      postgres.ColumnString
+	CreatedAt                     postgres.ColumnTimestampz
+	UpdatedAt                     postgres.ColumnTimestampz
+	OrganizationId                postgres.ColumnString
+	CreatedByOrganizationMemberId postgres.ColumnString
+	Type                          postgres.ColumnString
+	Status                        postgres.ColumnString
+	Parameters                    postgres.ColumnString
+	TotalItems                    postgres.ColumnInteger
+	ProcessedItems                postgres.ColumnInteger
+	FailedItems                   postgres.ColumnInteger
+	Result                        postgres.ColumnString
+	Error                         postgres.ColumnString
+	StartedAt                     postgres.ColumnTimestampz
+	CompletedAt                   postgres.ColumnTimestampz
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type BackgroundJobTable struct {
+	backgroundJobTable
+
+	EXCLUDED backgroundJobTable
+}
+
+// AS creates new BackgroundJobTable with assigned alias
+func (a BackgroundJobTable) AS(alias string) *BackgroundJobTable {
+	return newBackgroundJobTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new BackgroundJobTable with assigned schema name
+func (a BackgroundJobTable) FromSchema(schemaName string) *BackgroundJobTable {
+	return newBackgroundJobTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new BackgroundJobTable with assigned table prefix
+func (a BackgroundJobTable) WithPrefix(prefix string) *BackgroundJobTable {
+	return newBackgroundJobTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new BackgroundJobTable with assigned table suffix
+func (a BackgroundJobTable) WithSuffix(suffix string) *BackgroundJobTable {
+	return newBackgroundJobTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newBackgroundJobTable(schemaName, tableName, alias string) *BackgroundJobTable {
+	return &BackgroundJobTable{
+		backgroundJobTable: newBackgroundJobTableImpl(schemaName, tableName, alias),
+		EXCLUDED:           newBackgroundJobTableImpl("", "excluded", ""),
+	}
+}
+
+func newBackgroundJobTableImpl(schemaName, tableName, alias string) backgroundJobTable {
+	var (
+		UniqueIdColumn                      = postgres.StringColumn("UniqueId")
+		CreatedAtColumn                     = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn                     = postgres.TimestampzColumn("UpdatedAt")
+		OrganizationIdColumn                = postgres.StringColumn("OrganizationId")
+		CreatedByOrganizationMemberIdColumn = postgres.StringColumn("CreatedByOrganizationMemberId")
+		TypeColumn                          = postgres.StringColumn("Type")
+		StatusColumn                        = postgres.StringColumn("Status")
+		ParametersColumn                    = postgres.StringColumn("Parameters")
+		TotalItemsColumn                    = postgres.IntegerColumn("TotalItems")
+		ProcessedItemsColumn                = postgres.IntegerColumn("ProcessedItems")
+		FailedItemsColumn                   = postgres.IntegerColumn("FailedItems")
+		ResultColumn                        = postgres.StringColumn("Result")
+		ErrorColumn                         = postgres.StringColumn("Error")
+		StartedAtColumn                     = postgres.TimestampzColumn("StartedAt")
+		CompletedAtColumn                   = postgres.TimestampzColumn("CompletedAt")
+		allColumns                          = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, CreatedByOrganizationMemberIdColumn, TypeColumn, StatusColumn, ParametersColumn, TotalItemsColumn, ProcessedItemsColumn, FailedItemsColumn, ResultColumn, ErrorColumn, StartedAtColumn, CompletedAtColumn}
+		mutableColumns                      = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, CreatedByOrganizationMemberIdColumn, TypeColumn, StatusColumn, ParametersColumn, TotalItemsColumn, ProcessedItemsColumn, FailedItemsColumn, ResultColumn, ErrorColumn, StartedAtColumn, CompletedAtColumn}
+	)
+
+	return backgroundJobTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:                      UniqueIdColumn,
+		CreatedAt:                     CreatedAtColumn,
+		UpdatedAt:                     UpdatedAtColumn,
+		OrganizationId:                OrganizationIdColumn,
+		CreatedByOrganizationMemberId: CreatedByOrganizationMemberIdColumn,
+		Type:                          TypeColumn,
+		Status:                        StatusColumn,
+		Parameters:                    ParametersColumn,
+		TotalItems:                    TotalItemsColumn,
+		ProcessedItems:                ProcessedItemsColumn,
+		FailedItems:                   FailedItemsColumn,
+		Result:                        ResultColumn,
+		Error:                         ErrorColumn,
+		StartedAt:                     StartedAtColumn,
+		CompletedAt:                   CompletedAtColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
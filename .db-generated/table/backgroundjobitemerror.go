@@ -0,0 +1,90 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var BackgroundJobItemError = newBackgroundJobItemErrorTable("public", "BackgroundJobItemError", "")
+
+type backgroundJobItemErrorTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId        postgres.ColumnString
+	CreatedAt       postgres.ColumnTimestampz
+	BackgroundJobId postgres.ColumnString
+	ItemNumber      postgres.ColumnInteger
+	Item            postgres.ColumnString
+	Error           postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type BackgroundJobItemErrorTable struct {
+	backgroundJobItemErrorTable
+
+	EXCLUDED backgroundJobItemErrorTable
+}
+
+// AS creates new BackgroundJobItemErrorTable with assigned alias
+func (a BackgroundJobItemErrorTable) AS(alias string) *BackgroundJobItemErrorTable {
+	return newBackgroundJobItemErrorTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new BackgroundJobItemErrorTable with assigned schema name
+func (a BackgroundJobItemErrorTable) FromSchema(schemaName string) *BackgroundJobItemErrorTable {
+	return newBackgroundJobItemErrorTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new BackgroundJobItemErrorTable with assigned table prefix
+func (a BackgroundJobItemErrorTable) WithPrefix(prefix string) *BackgroundJobItemErrorTable {
+	return newBackgroundJobItemErrorTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new BackgroundJobItemErrorTable with assigned table suffix
+func (a BackgroundJobItemErrorTable) WithSuffix(suffix string) *BackgroundJobItemErrorTable {
+	return newBackgroundJobItemErrorTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newBackgroundJobItemErrorTable(schemaName, tableName, alias string) *BackgroundJobItemErrorTable {
+	return &BackgroundJobItemErrorTable{
+		backgroundJobItemErrorTable: newBackgroundJobItemErrorTableImpl(schemaName, tableName, alias),
+		EXCLUDED:                    newBackgroundJobItemErrorTableImpl("", "excluded", ""),
+	}
+}
+
+func newBackgroundJobItemErrorTableImpl(schemaName, tableName, alias string) backgroundJobItemErrorTable {
+	var (
+		UniqueIdColumn        = postgres.StringColumn("UniqueId")
+		CreatedAtColumn       = postgres.TimestampzColumn("CreatedAt")
+		BackgroundJobIdColumn = postgres.StringColumn("BackgroundJobId")
+		ItemNumberColumn      = postgres.IntegerColumn("ItemNumber")
+		ItemColumn            = postgres.StringColumn("Item")
+		ErrorColumn           = postgres.StringColumn("Error")
+		allColumns            = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, BackgroundJobIdColumn, ItemNumberColumn, ItemColumn, ErrorColumn}
+		mutableColumns        = postgres.ColumnList{CreatedAtColumn, BackgroundJobIdColumn, ItemNumberColumn, ItemColumn, ErrorColumn}
+	)
+
+	return backgroundJobItemErrorTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:        UniqueIdColumn,
+		CreatedAt:       CreatedAtColumn,
+		BackgroundJobId: BackgroundJobIdColumn,
+		ItemNumber:      ItemNumberColumn,
+		Item:            ItemColumn,
+		Error:           ErrorColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
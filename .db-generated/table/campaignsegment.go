@@ -0,0 +1,84 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var CampaignSegment = newCampaignSegmentTable("public", "CampaignSegment", "")
+
+type campaignSegmentTable struct {
+	postgres.Table
+
+	// Columns
+	CreatedAt  postgres.ColumnTimestampz
+	UpdatedAt  postgres.ColumnTimestampz
+	SegmentId  postgres.ColumnString
+	CampaignId postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type CampaignSegmentTable struct {
+	campaignSegmentTable
+
+	EXCLUDED campaignSegmentTable
+}
+
+// AS creates new CampaignSegmentTable with assigned alias
+func (a CampaignSegmentTable) AS(alias string) *CampaignSegmentTable {
+	return newCampaignSegmentTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new CampaignSegmentTable with assigned schema name
+func (a CampaignSegmentTable) FromSchema(schemaName string) *CampaignSegmentTable {
+	return newCampaignSegmentTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new CampaignSegmentTable with assigned table prefix
+func (a CampaignSegmentTable) WithPrefix(prefix string) *CampaignSegmentTable {
+	return newCampaignSegmentTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new CampaignSegmentTable with assigned table suffix
+func (a CampaignSegmentTable) WithSuffix(suffix string) *CampaignSegmentTable {
+	return newCampaignSegmentTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newCampaignSegmentTable(schemaName, tableName, alias string) *CampaignSegmentTable {
+	return &CampaignSegmentTable{
+		campaignSegmentTable: newCampaignSegmentTableImpl(schemaName, tableName, alias),
+		EXCLUDED:             newCampaignSegmentTableImpl("", "excluded", ""),
+	}
+}
+
+func newCampaignSegmentTableImpl(schemaName, tableName, alias string) campaignSegmentTable {
+	var (
+		CreatedAtColumn  = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn  = postgres.TimestampzColumn("UpdatedAt")
+		SegmentIdColumn  = postgres.StringColumn("SegmentId")
+		CampaignIdColumn = postgres.StringColumn("CampaignId")
+		allColumns       = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, SegmentIdColumn, CampaignIdColumn}
+		mutableColumns   = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn}
+	)
+
+	return campaignSegmentTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		CreatedAt:  CreatedAtColumn,
+		UpdatedAt:  UpdatedAtColumn,
+		SegmentId:  SegmentIdColumn,
+		CampaignId: CampaignIdColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
@@ -0,0 +1,102 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var CannedResponse = newCannedResponseTable("public", "CannedResponse", "")
+
+type cannedResponseTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId             postgres.ColumnString
+	CreatedAt            postgres.ColumnTimestampz
+	UpdatedAt            postgres.ColumnTimestampz
+	OrganizationId       postgres.ColumnString
+	OrganizationMemberId postgres.ColumnString
+	Name                 postgres.ColumnString
+	Shortcode            postgres.ColumnString
+	Content              postgres.ColumnString
+	Folder               postgres.ColumnString
+	Attachments          postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type CannedResponseTable struct {
+	cannedResponseTable
+
+	EXCLUDED cannedResponseTable
+}
+
+// AS creates new CannedResponseTable with assigned alias
+func (a CannedResponseTable) AS(alias string) *CannedResponseTable {
+	return newCannedResponseTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new CannedResponseTable with assigned schema name
+func (a CannedResponseTable) FromSchema(schemaName string) *CannedResponseTable {
+	return newCannedResponseTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new CannedResponseTable with assigned table prefix
+func (a CannedResponseTable) WithPrefix(prefix string) *CannedResponseTable {
+	return newCannedResponseTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new CannedResponseTable with assigned table suffix
+func (a CannedResponseTable) WithSuffix(suffix string) *CannedResponseTable {
+	return newCannedResponseTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newCannedResponseTable(schemaName, tableName, alias string) *CannedResponseTable {
+	return &CannedResponseTable{
+		cannedResponseTable: newCannedResponseTableImpl(schemaName, tableName, alias),
+		EXCLUDED:            newCannedResponseTableImpl("", "excluded", ""),
+	}
+}
+
+func newCannedResponseTableImpl(schemaName, tableName, alias string) cannedResponseTable {
+	var (
+		UniqueIdColumn             = postgres.StringColumn("UniqueId")
+		CreatedAtColumn            = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn            = postgres.TimestampzColumn("UpdatedAt")
+		OrganizationIdColumn       = postgres.StringColumn("OrganizationId")
+		OrganizationMemberIdColumn = postgres.StringColumn("OrganizationMemberId")
+		NameColumn                 = postgres.StringColumn("Name")
+		ShortcodeColumn            = postgres.StringColumn("Shortcode")
+		ContentColumn              = postgres.StringColumn("Content")
+		FolderColumn               = postgres.StringColumn("Folder")
+		AttachmentsColumn          = postgres.StringColumn("Attachments")
+		allColumns                 = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, OrganizationMemberIdColumn, NameColumn, ShortcodeColumn, ContentColumn, FolderColumn, AttachmentsColumn}
+		mutableColumns             = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, OrganizationMemberIdColumn, NameColumn, ShortcodeColumn, ContentColumn, FolderColumn, AttachmentsColumn}
+	)
+
+	return cannedResponseTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:             UniqueIdColumn,
+		CreatedAt:            CreatedAtColumn,
+		UpdatedAt:            UpdatedAtColumn,
+		OrganizationId:       OrganizationIdColumn,
+		OrganizationMemberId: OrganizationMemberIdColumn,
+		Name:                 NameColumn,
+		Shortcode:            ShortcodeColumn,
+		Content:              ContentColumn,
+		Folder:               FolderColumn,
+		Attachments:          AttachmentsColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
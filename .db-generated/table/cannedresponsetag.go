@@ -0,0 +1,84 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var CannedResponseTag = newCannedResponseTagTable("public", "CannedResponseTag", "")
+
+type cannedResponseTagTable struct {
+	postgres.Table
+
+	// Columns
+	CreatedAt        postgres.ColumnTimestampz
+	UpdatedAt        postgres.ColumnTimestampz
+	CannedResponseId postgres.ColumnString
+	TagId            postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type CannedResponseTagTable struct {
+	cannedResponseTagTable
+
+	EXCLUDED cannedResponseTagTable
+}
+
+// AS creates new CannedResponseTagTable with assigned alias
+func (a CannedResponseTagTable) AS(alias string) *CannedResponseTagTable {
+	return newCannedResponseTagTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new CannedResponseTagTable with assigned schema name
+func (a CannedResponseTagTable) FromSchema(schemaName string) *CannedResponseTagTable {
+	return newCannedResponseTagTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new CannedResponseTagTable with assigned table prefix
+func (a CannedResponseTagTable) WithPrefix(prefix string) *CannedResponseTagTable {
+	return newCannedResponseTagTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new CannedResponseTagTable with assigned table suffix
+func (a CannedResponseTagTable) WithSuffix(suffix string) *CannedResponseTagTable {
+	return newCannedResponseTagTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newCannedResponseTagTable(schemaName, tableName, alias string) *CannedResponseTagTable {
+	return &CannedResponseTagTable{
+		cannedResponseTagTable: newCannedResponseTagTableImpl(schemaName, tableName, alias),
+		EXCLUDED:               newCannedResponseTagTableImpl("", "excluded", ""),
+	}
+}
+
+func newCannedResponseTagTableImpl(schemaName, tableName, alias string) cannedResponseTagTable {
+	var (
+		CreatedAtColumn        = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn        = postgres.TimestampzColumn("UpdatedAt")
+		CannedResponseIdColumn = postgres.StringColumn("CannedResponseId")
+		TagIdColumn            = postgres.StringColumn("TagId")
+		allColumns             = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, CannedResponseIdColumn, TagIdColumn}
+		mutableColumns         = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn}
+	)
+
+	return cannedResponseTagTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		CreatedAt:        CreatedAtColumn,
+		UpdatedAt:        UpdatedAtColumn,
+		CannedResponseId: CannedResponseIdColumn,
+		TagId:            TagIdColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
@@ -0,0 +1,93 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var ContactActivity = newContactActivityTable("public", "ContactActivity", "")
+
+type contactActivityTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId             postgres.ColumnString
+	CreatedAt            postgres.ColumnTimestampz
+	OrganizationId       postgres.ColumnString
+	ContactId            postgres.ColumnString
+	Type                 postgres.ColumnString
+	OrganizationMemberId postgres.ColumnString
+	Data                 postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type ContactActivityTable struct {
+	contactActivityTable
+
+	EXCLUDED contactActivityTable
+}
+
+// AS creates new ContactActivityTable with assigned alias
+func (a ContactActivityTable) AS(alias string) *ContactActivityTable {
+	return newContactActivityTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new ContactActivityTable with assigned schema name
+func (a ContactActivityTable) FromSchema(schemaName string) *ContactActivityTable {
+	return newContactActivityTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new ContactActivityTable with assigned table prefix
+func (a ContactActivityTable) WithPrefix(prefix string) *ContactActivityTable {
+	return newContactActivityTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new ContactActivityTable with assigned table suffix
+func (a ContactActivityTable) WithSuffix(suffix string) *ContactActivityTable {
+	return newContactActivityTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newContactActivityTable(schemaName, tableName, alias string) *ContactActivityTable {
+	return &ContactActivityTable{
+		contactActivityTable: newContactActivityTableImpl(schemaName, tableName, alias),
+		EXCLUDED:             newContactActivityTableImpl("", "excluded", ""),
+	}
+}
+
+func newContactActivityTableImpl(schemaName, tableName, alias string) contactActivityTable {
+	var (
+		UniqueIdColumn             = postgres.StringColumn("UniqueId")
+		CreatedAtColumn            = postgres.TimestampzColumn("CreatedAt")
+		OrganizationIdColumn       = postgres.StringColumn("OrganizationId")
+		ContactIdColumn            = postgres.StringColumn("ContactId")
+		TypeColumn                 = postgres.StringColumn("Type")
+		OrganizationMemberIdColumn = postgres.StringColumn("OrganizationMemberId")
+		DataColumn                 = postgres.StringColumn("Data")
+		allColumns                 = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, OrganizationIdColumn, ContactIdColumn, TypeColumn, OrganizationMemberIdColumn, DataColumn}
+		mutableColumns             = postgres.ColumnList{CreatedAtColumn, OrganizationIdColumn, ContactIdColumn, TypeColumn, OrganizationMemberIdColumn, DataColumn}
+	)
+
+	return contactActivityTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:             UniqueIdColumn,
+		CreatedAt:            CreatedAtColumn,
+		OrganizationId:       OrganizationIdColumn,
+		ContactId:            ContactIdColumn,
+		Type:                 TypeColumn,
+		OrganizationMemberId: OrganizationMemberIdColumn,
+		Data:                 DataColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
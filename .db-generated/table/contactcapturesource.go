@@ -0,0 +1,114 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var ContactCaptureSource = newContactCaptureSourceTable("public", "ContactCaptureSource", "")
+
+type contactCaptureSourceTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId                postgres.ColumnString
+	CreatedAt               postgres.ColumnTimestampz
+	UpdatedAt               postgres.ColumnTimestampz
+	OrganizationId          postgres.ColumnString
+	ContactListId           postgres.ColumnString
+	Type                    postgres.ColumnString
+	Name                    postgres.ColumnString
+	Code                    postgres.ColumnString
+	PhoneNumber             postgres.ColumnString
+	PrefilledMessage        postgres.ColumnString
+	ConsentText             postgres.ColumnString
+	Attributes              postgres.ColumnString
+	WelcomeCannedResponseId postgres.ColumnString
+	IsActive                postgres.ColumnBool
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type ContactCaptureSourceTable struct {
+	contactCaptureSourceTable
+
+	EXCLUDED contactCaptureSourceTable
+}
+
+// AS creates new ContactCaptureSourceTable with assigned alias
+func (a ContactCaptureSourceTable) AS(alias string) *ContactCaptureSourceTable {
+	return newContactCaptureSourceTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new ContactCaptureSourceTable with assigned schema name
+func (a ContactCaptureSourceTable) FromSchema(schemaName string) *ContactCaptureSourceTable {
+	return newContactCaptureSourceTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new ContactCaptureSourceTable with assigned table prefix
+func (a ContactCaptureSourceTable) WithPrefix(prefix string) *ContactCaptureSourceTable {
+	return newContactCaptureSourceTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new ContactCaptureSourceTable with assigned table suffix
+func (a ContactCaptureSourceTable) WithSuffix(suffix string) *ContactCaptureSourceTable {
+	return newContactCaptureSourceTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newContactCaptureSourceTable(schemaName, tableName, alias string) *ContactCaptureSourceTable {
+	return &ContactCaptureSourceTable{
+		contactCaptureSourceTable: newContactCaptureSourceTableImpl(schemaName, tableName, alias),
+		EXCLUDED:                  newContactCaptureSourceTableImpl("", "excluded", ""),
+	}
+}
+
+func newContactCaptureSourceTableImpl(schemaName, tableName, alias string) contactCaptureSourceTable {
+	var (
+		UniqueIdColumn                = postgres.StringColumn("UniqueId")
+		CreatedAtColumn               = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn               = postgres.TimestampzColumn("UpdatedAt")
+		OrganizationIdColumn          = postgres.StringColumn("OrganizationId")
+		ContactListIdColumn           = postgres.StringColumn("ContactListId")
+		TypeColumn                    = postgres.StringColumn("Type")
+		NameColumn                    = postgres.StringColumn("Name")
+		CodeColumn                    = postgres.StringColumn("Code")
+		PhoneNumberColumn             = postgres.StringColumn("PhoneNumber")
+		PrefilledMessageColumn        = postgres.StringColumn("PrefilledMessage")
+		ConsentTextColumn             = postgres.StringColumn("ConsentText")
+		AttributesColumn              = postgres.StringColumn("Attributes")
+		WelcomeCannedResponseIdColumn = postgres.StringColumn("WelcomeCannedResponseId")
+		IsActiveColumn                = postgres.BoolColumn("IsActive")
+		allColumns                    = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, ContactListIdColumn, TypeColumn, NameColumn, CodeColumn, PhoneNumberColumn, PrefilledMessageColumn, ConsentTextColumn, AttributesColumn, WelcomeCannedResponseIdColumn, IsActiveColumn}
+		mutableColumns                = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, ContactListIdColumn, TypeColumn, NameColumn, CodeColumn, PhoneNumberColumn, PrefilledMessageColumn, ConsentTextColumn, AttributesColumn, WelcomeCannedResponseIdColumn, IsActiveColumn}
+	)
+
+	return contactCaptureSourceTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:                UniqueIdColumn,
+		CreatedAt:               CreatedAtColumn,
+		UpdatedAt:               UpdatedAtColumn,
+		OrganizationId:          OrganizationIdColumn,
+		ContactListId:           ContactListIdColumn,
+		Type:                    TypeColumn,
+		Name:                    NameColumn,
+		Code:                    CodeColumn,
+		PhoneNumber:             PhoneNumberColumn,
+		PrefilledMessage:        PrefilledMessageColumn,
+		ConsentText:             ConsentTextColumn,
+		Attributes:              AttributesColumn,
+		WelcomeCannedResponseId: WelcomeCannedResponseIdColumn,
+		IsActive:                IsActiveColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
@@ -0,0 +1,108 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var ContactConsent = newContactConsentTable("public", "ContactConsent", "")
+
+type contactConsentTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId               postgres.ColumnString
+	CreatedAt              postgres.ColumnTimestampz
+	UpdatedAt              postgres.ColumnTimestampz
+	OrganizationId         postgres.ColumnString
+	ContactId              postgres.ColumnString
+	Category               postgres.ColumnString
+	Status                 postgres.ColumnString
+	Source                 postgres.ColumnString
+	ChangedAt              postgres.ColumnTimestampz
+	Evidence               postgres.ColumnString
+	ContactCaptureSourceId postgres.ColumnString
+	OrganizationMemberId   postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type ContactConsentTable struct {
+	contactConsentTable
+
+	EXCLUDED contactConsentTable
+}
+
+// AS creates new ContactConsentTable with assigned alias
+func (a ContactConsentTable) AS(alias string) *ContactConsentTable {
+	return newContactConsentTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new ContactConsentTable with assigned schema name
+func (a ContactConsentTable) FromSchema(schemaName string) *ContactConsentTable {
+	return newContactConsentTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new ContactConsentTable with assigned table prefix
+func (a ContactConsentTable) WithPrefix(prefix string) *ContactConsentTable {
+	return newContactConsentTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new ContactConsentTable with assigned table suffix
+func (a ContactConsentTable) WithSuffix(suffix string) *ContactConsentTable {
+	return newContactConsentTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newContactConsentTable(schemaName, tableName, alias string) *ContactConsentTable {
+	return &ContactConsentTable{
+		contactConsentTable: newContactConsentTableImpl(schemaName, tableName, alias),
+		EXCLUDED:            newContactConsentTableImpl("", "excluded", ""),
+	}
+}
+
+func newContactConsentTableImpl(schemaName, tableName, alias string) contactConsentTable {
+	var (
+		UniqueIdColumn               = postgres.StringColumn("UniqueId")
+		CreatedAtColumn              = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn              = postgres.TimestampzColumn("UpdatedAt")
+		OrganizationIdColumn         = postgres.StringColumn("OrganizationId")
+		ContactIdColumn              = postgres.StringColumn("ContactId")
+		CategoryColumn               = postgres.StringColumn("Category")
+		StatusColumn                 = postgres.StringColumn("Status")
+		SourceColumn                 = postgres.StringColumn("Source")
+		ChangedAtColumn              = postgres.TimestampzColumn("ChangedAt")
+		EvidenceColumn               = postgres.StringColumn("Evidence")
+		ContactCaptureSourceIdColumn = postgres.StringColumn("ContactCaptureSourceId")
+		OrganizationMemberIdColumn   = postgres.StringColumn("OrganizationMemberId")
+		allColumns                   = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, ContactIdColumn, CategoryColumn, StatusColumn, SourceColumn, ChangedAtColumn, EvidenceColumn, ContactCaptureSourceIdColumn, OrganizationMemberIdColumn}
+		mutableColumns               = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, ContactIdColumn, CategoryColumn, StatusColumn, SourceColumn, ChangedAtColumn, EvidenceColumn, ContactCaptureSourceIdColumn, OrganizationMemberIdColumn}
+	)
+
+	return contactConsentTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:               UniqueIdColumn,
+		CreatedAt:              CreatedAtColumn,
+		UpdatedAt:              UpdatedAtColumn,
+		OrganizationId:         OrganizationIdColumn,
+		ContactId:              ContactIdColumn,
+		Category:               CategoryColumn,
+		Status:                 StatusColumn,
+		Source:                 SourceColumn,
+		ChangedAt:              ChangedAtColumn,
+		Evidence:               EvidenceColumn,
+		ContactCaptureSourceId: ContactCaptureSourceIdColumn,
+		OrganizationMemberId:   OrganizationMemberIdColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
@@ -0,0 +1,102 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var ContactField = newContactFieldTable("public", "ContactField", "")
+
+type contactFieldTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId       postgres.ColumnString
+	CreatedAt      postgres.ColumnTimestampz
+	UpdatedAt      postgres.ColumnTimestampz
+	OrganizationId postgres.ColumnString
+	Key            postgres.ColumnString
+	Label          postgres.ColumnString
+	Type           postgres.ColumnString
+	IsRequired     postgres.ColumnBool
+	DefaultValue   postgres.ColumnString
+	Options        postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type ContactFieldTable struct {
+	contactFieldTable
+
+	EXCLUDED contactFieldTable
+}
+
+// AS creates new ContactFieldTable with assigned alias
+func (a ContactFieldTable) AS(alias string) *ContactFieldTable {
+	return newContactFieldTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new ContactFieldTable with assigned schema name
+func (a ContactFieldTable) FromSchema(schemaName string) *ContactFieldTable {
+	return newContactFieldTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new ContactFieldTable with assigned table prefix
+func (a ContactFieldTable) WithPrefix(prefix string) *ContactFieldTable {
+	return newContactFieldTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new ContactFieldTable with assigned table suffix
+func (a ContactFieldTable) WithSuffix(suffix string) *ContactFieldTable {
+	return newContactFieldTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newContactFieldTable(schemaName, tableName, alias string) *ContactFieldTable {
+	return &ContactFieldTable{
+		contactFieldTable: newContactFieldTableImpl(schemaName, tableName, alias),
+		EXCLUDED:          newContactFieldTableImpl("", "excluded", ""),
+	}
+}
+
+func newContactFieldTableImpl(schemaName, tableName, alias string) contactFieldTable {
+	var (
+		UniqueIdColumn       = postgres.StringColumn("UniqueId")
+		CreatedAtColumn      = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn      = postgres.TimestampzColumn("UpdatedAt")
+		OrganizationIdColumn = postgres.StringColumn("OrganizationId")
+		KeyColumn            = postgres.StringColumn("Key")
+		LabelColumn          = postgres.StringColumn("Label")
+		TypeColumn           = postgres.StringColumn("Type")
+		IsRequiredColumn     = postgres.BoolColumn("IsRequired")
+		DefaultValueColumn   = postgres.StringColumn("DefaultValue")
+		OptionsColumn        = postgres.StringColumn("Options")
+		allColumns           = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, KeyColumn, LabelColumn, TypeColumn, IsRequiredColumn, DefaultValueColumn, OptionsColumn}
+		mutableColumns       = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, KeyColumn, LabelColumn, TypeColumn, IsRequiredColumn, DefaultValueColumn, OptionsColumn}
+	)
+
+	return contactFieldTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:       UniqueIdColumn,
+		CreatedAt:      CreatedAtColumn,
+		UpdatedAt:      UpdatedAtColumn,
+		OrganizationId: OrganizationIdColumn,
+		Key:            KeyColumn,
+		Label:          LabelColumn,
+		Type:           TypeColumn,
+		IsRequired:     IsRequiredColumn,
+		DefaultValue:   DefaultValueColumn,
+		Options:        OptionsColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
@@ -17,15 +17,23 @@ type conversationTable struct {
 	postgres.Table
 
 	// Columns
-	UniqueId              postgres.ColumnString
-	CreatedAt             postgres.ColumnTimestampz
-	UpdatedAt             postgres.ColumnTimestampz
-	ContactId             postgres.ColumnString
-	OrganizationId        postgres.ColumnString
-	Status                postgres.ColumnString
-	PhoneNumberUsed       postgres.ColumnString
-	InitiatedBy           postgres.ColumnString
-	InitiatedByCampaignId postgres.ColumnString
+	UniqueId                postgres.ColumnString
+	CreatedAt               postgres.ColumnTimestampz
+	UpdatedAt               postgres.ColumnTimestampz
+	ContactId               postgres.ColumnString
+	OrganizationId          postgres.ColumnString
+	Status                  postgres.ColumnString
+	PhoneNumberUsed         postgres.ColumnString
+	InitiatedBy             postgres.ColumnString
+	InitiatedByCampaignId   postgres.ColumnString
+	SlaPolicyId             postgres.ColumnString
+	FirstResponseDueAt      postgres.ColumnTimestampz
+	FirstRespondedAt        postgres.ColumnTimestampz
+	FirstResponseBreachedAt postgres.ColumnTimestampz
+	ResolutionDueAt         postgres.ColumnTimestampz
+	ResolvedAt              postgres.ColumnTimestampz
+	ResolutionBreachedAt    postgres.ColumnTimestampz
+	SnoozedUntil            postgres.ColumnTimestampz
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -66,32 +74,48 @@ func newConversationTable(schemaName, tableName, alias string) *ConversationTabl
 
 func newConversationTableImpl(schemaName, tableName, alias string) conversationTable {
 	var (
-		UniqueIdColumn              = postgres.StringColumn("UniqueId")
-		CreatedAtColumn             = postgres.TimestampzColumn("CreatedAt")
-		UpdatedAtColumn             = postgres.TimestampzColumn("UpdatedAt")
-		ContactIdColumn             = postgres.StringColumn("ContactId")
-		OrganizationIdColumn        = postgres.StringColumn("OrganizationId")
-		StatusColumn                = postgres.StringColumn("Status")
-		PhoneNumberUsedColumn       = postgres.StringColumn("PhoneNumberUsed")
-		InitiatedByColumn           = postgres.StringColumn("InitiatedBy")
-		InitiatedByCampaignIdColumn = postgres.StringColumn("InitiatedByCampaignId")
-		allColumns                  = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, ContactIdColumn, OrganizationIdColumn, StatusColumn, PhoneNumberUsedColumn, InitiatedByColumn, InitiatedByCampaignIdColumn}
-		mutableColumns              = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, ContactIdColumn, OrganizationIdColumn, StatusColumn, PhoneNumberUsedColumn, InitiatedByColumn, InitiatedByCampaignIdColumn}
+		UniqueIdColumn                = postgres.StringColumn("UniqueId")
+		CreatedAtColumn               = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn               = postgres.TimestampzColumn("UpdatedAt")
+		ContactIdColumn               = postgres.StringColumn("ContactId")
+		OrganizationIdColumn          = postgres.StringColumn("OrganizationId")
+		StatusColumn                  = postgres.StringColumn("Status")
+		PhoneNumberUsedColumn         = postgres.StringColumn("PhoneNumberUsed")
+		InitiatedByColumn             = postgres.StringColumn("InitiatedBy")
+		InitiatedByCampaignIdColumn   = postgres.StringColumn("InitiatedByCampaignId")
+		SlaPolicyIdColumn             = postgres.StringColumn("SlaPolicyId")
+		FirstResponseDueAtColumn      = postgres.TimestampzColumn("FirstResponseDueAt")
+		FirstRespondedAtColumn        = postgres.TimestampzColumn("FirstRespondedAt")
+		FirstResponseBreachedAtColumn = postgres.TimestampzColumn("FirstResponseBreachedAt")
+		ResolutionDueAtColumn         = postgres.TimestampzColumn("ResolutionDueAt")
+		ResolvedAtColumn              = postgres.TimestampzColumn("ResolvedAt")
+		ResolutionBreachedAtColumn    = postgres.TimestampzColumn("ResolutionBreachedAt")
+		SnoozedUntilColumn            = postgres.TimestampzColumn("SnoozedUntil")
+		allColumns                    = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, ContactIdColumn, OrganizationIdColumn, StatusColumn, PhoneNumberUsedColumn, InitiatedByColumn, InitiatedByCampaignIdColumn, SlaPolicyIdColumn, FirstResponseDueAtColumn, FirstRespondedAtColumn, FirstResponseBreachedAtColumn, ResolutionDueAtColumn, ResolvedAtColumn, ResolutionBreachedAtColumn, SnoozedUntilColumn}
+		mutableColumns                = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, ContactIdColumn, OrganizationIdColumn, StatusColumn, PhoneNumberUsedColumn, InitiatedByColumn, InitiatedByCampaignIdColumn, SlaPolicyIdColumn, FirstResponseDueAtColumn, FirstRespondedAtColumn, FirstResponseBreachedAtColumn, ResolutionDueAtColumn, ResolvedAtColumn, ResolutionBreachedAtColumn, SnoozedUntilColumn}
 	)
 
 	return conversationTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		UniqueId:              UniqueIdColumn,
-		CreatedAt:             CreatedAtColumn,
-		UpdatedAt:             UpdatedAtColumn,
-		ContactId:             ContactIdColumn,
-		OrganizationId:        OrganizationIdColumn,
-		Status:                StatusColumn,
-		PhoneNumberUsed:       PhoneNumberUsedColumn,
-		InitiatedBy:           InitiatedByColumn,
-		InitiatedByCampaignId: InitiatedByCampaignIdColumn,
+		UniqueId:                UniqueIdColumn,
+		CreatedAt:               CreatedAtColumn,
+		UpdatedAt:               UpdatedAtColumn,
+		ContactId:               ContactIdColumn,
+		OrganizationId:          OrganizationIdColumn,
+		Status:                  StatusColumn,
+		PhoneNumberUsed:         PhoneNumberUsedColumn,
+		InitiatedBy:             InitiatedByColumn,
+		InitiatedByCampaignId:   InitiatedByCampaignIdColumn,
+		SlaPolicyId:             SlaPolicyIdColumn,
+		FirstResponseDueAt:      FirstResponseDueAtColumn,
+		FirstRespondedAt:        FirstRespondedAtColumn,
+		FirstResponseBreachedAt: FirstResponseBreachedAtColumn,
+		ResolutionDueAt:         ResolutionDueAtColumn,
+		ResolvedAt:              ResolvedAtColumn,
+		ResolutionBreachedAt:    ResolutionBreachedAtColumn,
+		SnoozedUntil:            SnoozedUntilColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
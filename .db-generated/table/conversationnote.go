@@ -0,0 +1,93 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var ConversationNote = newConversationNoteTable("public", "ConversationNote", "")
+
+type conversationNoteTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId                   postgres.ColumnString
+	CreatedAt                  postgres.ColumnTimestampz
+	UpdatedAt                  postgres.ColumnTimestampz
+	ConversationId             postgres.ColumnString
+	OrganizationId             postgres.ColumnString
+	AuthorOrganizationMemberId postgres.ColumnString
+	Content                    postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type ConversationNoteTable struct {
+	conversationNoteTable
+
+	EXCLUDED conversationNoteTable
+}
+
+// AS creates new ConversationNoteTable with assigned alias
+func (a ConversationNoteTable) AS(alias string) *ConversationNoteTable {
+	return newConversationNoteTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new ConversationNoteTable with assigned schema name
+func (a ConversationNoteTable) FromSchema(schemaName string) *ConversationNoteTable {
+	return newConversationNoteTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new ConversationNoteTable with assigned table prefix
+func (a ConversationNoteTable) WithPrefix(prefix string) *ConversationNoteTable {
+	return newConversationNoteTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new ConversationNoteTable with assigned table suffix
+func (a ConversationNoteTable) WithSuffix(suffix string) *ConversationNoteTable {
+	return newConversationNoteTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newConversationNoteTable(schemaName, tableName, alias string) *ConversationNoteTable {
+	return &ConversationNoteTable{
+		conversationNoteTable: newConversationNoteTableImpl(schemaName, tableName, alias),
+		EXCLUDED:              newConversationNoteTableImpl("", "excluded", ""),
+	}
+}
+
+func newConversationNoteTableImpl(schemaName, tableName, alias string) conversationNoteTable {
+	var (
+		UniqueIdColumn                   = postgres.StringColumn("UniqueId")
+		CreatedAtColumn                  = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn                  = postgres.TimestampzColumn("UpdatedAt")
+		ConversationIdColumn             = postgres.StringColumn("ConversationId")
+		OrganizationIdColumn             = postgres.StringColumn("OrganizationId")
+		AuthorOrganizationMemberIdColumn = postgres.StringColumn("AuthorOrganizationMemberId")
+		ContentColumn                    = postgres.StringColumn("Content")
+		allColumns                       = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, ConversationIdColumn, OrganizationIdColumn, AuthorOrganizationMemberIdColumn, ContentColumn}
+		mutableColumns                   = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, ConversationIdColumn, OrganizationIdColumn, AuthorOrganizationMemberIdColumn, ContentColumn}
+	)
+
+	return conversationNoteTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:                   UniqueIdColumn,
+		CreatedAt:                  CreatedAtColumn,
+		UpdatedAt:                  UpdatedAtColumn,
+		ConversationId:             ConversationIdColumn,
+		OrganizationId:             OrganizationIdColumn,
+		AuthorOrganizationMemberId: AuthorOrganizationMemberIdColumn,
+		Content:                    ContentColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
@@ -0,0 +1,81 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var ConversationNoteMention = newConversationNoteMentionTable("public", "ConversationNoteMention", "")
+
+type conversationNoteMentionTable struct {
+	postgres.Table
+
+	// Columns
+	CreatedAt            postgres.ColumnTimestampz
+	ConversationNoteId   postgres.ColumnString
+	OrganizationMemberId postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type ConversationNoteMentionTable struct {
+	conversationNoteMentionTable
+
+	EXCLUDED conversationNoteMentionTable
+}
+
+// AS creates new ConversationNoteMentionTable with assigned alias
+func (a ConversationNoteMentionTable) AS(alias string) *ConversationNoteMentionTable {
+	return newConversationNoteMentionTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new ConversationNoteMentionTable with assigned schema name
+func (a ConversationNoteMentionTable) FromSchema(schemaName string) *ConversationNoteMentionTable {
+	return newConversationNoteMentionTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new ConversationNoteMentionTable with assigned table prefix
+func (a ConversationNoteMentionTable) WithPrefix(prefix string) *ConversationNoteMentionTable {
+	return newConversationNoteMentionTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new ConversationNoteMentionTable with assigned table suffix
+func (a ConversationNoteMentionTable) WithSuffix(suffix string) *ConversationNoteMentionTable {
+	return newConversationNoteMentionTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newConversationNoteMentionTable(schemaName, tableName, alias string) *ConversationNoteMentionTable {
+	return &ConversationNoteMentionTable{
+		conversationNoteMentionTable: newConversationNoteMentionTableImpl(schemaName, tableName, alias),
+		EXCLUDED:                     newConversationNoteMentionTableImpl("", "excluded", ""),
+	}
+}
+
+func newConversationNoteMentionTableImpl(schemaName, tableName, alias string) conversationNoteMentionTable {
+	var (
+		CreatedAtColumn            = postgres.TimestampzColumn("CreatedAt")
+		ConversationNoteIdColumn   = postgres.StringColumn("ConversationNoteId")
+		OrganizationMemberIdColumn = postgres.StringColumn("OrganizationMemberId")
+		allColumns                 = postgres.ColumnList{CreatedAtColumn, ConversationNoteIdColumn, OrganizationMemberIdColumn}
+		mutableColumns             = postgres.ColumnList{CreatedAtColumn}
+	)
+
+	return conversationNoteMentionTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		CreatedAt:            CreatedAtColumn,
+		ConversationNoteId:   ConversationNoteIdColumn,
+		OrganizationMemberId: OrganizationMemberIdColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
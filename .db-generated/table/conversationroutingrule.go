@@ -0,0 +1,108 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var ConversationRoutingRule = newConversationRoutingRuleTable("public", "ConversationRoutingRule", "")
+
+type conversationRoutingRuleTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId                         postgres.ColumnString
+	CreatedAt                        postgres.ColumnTimestampz
+	UpdatedAt                        postgres.ColumnTimestampz
+	OrganizationId                   postgres.ColumnString
+	Name                             postgres.ColumnString
+	Strategy                         postgres.ColumnString
+	Priority                         postgres.ColumnInteger
+	IsEnabled                        postgres.ColumnBool
+	PhoneNumberId                    postgres.ColumnString
+	TagId                            postgres.ColumnString
+	MaxConcurrentConversations       postgres.ColumnInteger
+	LastAssignedOrganizationMemberId postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type ConversationRoutingRuleTable struct {
+	conversationRoutingRuleTable
+
+	EXCLUDED conversationRoutingRuleTable
+}
+
+// AS creates new ConversationRoutingRuleTable with assigned alias
+func (a ConversationRoutingRuleTable) AS(alias string) *ConversationRoutingRuleTable {
+	return newConversationRoutingRuleTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new ConversationRoutingRuleTable with assigned schema name
+func (a ConversationRoutingRuleTable) FromSchema(schemaName string) *ConversationRoutingRuleTable {
+	return newConversationRoutingRuleTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new ConversationRoutingRuleTable with assigned table prefix
+func (a ConversationRoutingRuleTable) WithPrefix(prefix string) *ConversationRoutingRuleTable {
+	return newConversationRoutingRuleTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new ConversationRoutingRuleTable with assigned table suffix
+func (a ConversationRoutingRuleTable) WithSuffix(suffix string) *ConversationRoutingRuleTable {
+	return newConversationRoutingRuleTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newConversationRoutingRuleTable(schemaName, tableName, alias string) *ConversationRoutingRuleTable {
+	return &ConversationRoutingRuleTable{
+		conversationRoutingRuleTable: newConversationRoutingRuleTableImpl(schemaName, tableName, alias),
+		EXCLUDED:                     newConversationRoutingRuleTableImpl("", "excluded", ""),
+	}
+}
+
+func newConversationRoutingRuleTableImpl(schemaName, tableName, alias string) conversationRoutingRuleTable {
+	var (
+		UniqueIdColumn                         = postgres.StringColumn("UniqueId")
+		CreatedAtColumn                        = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn                        = postgres.TimestampzColumn("UpdatedAt")
+		OrganizationIdColumn                   = postgres.StringColumn("OrganizationId")
+		NameColumn                             = postgres.StringColumn("Name")
+		StrategyColumn                         = postgres.StringColumn("Strategy")
+		PriorityColumn                         = postgres.IntegerColumn("Priority")
+		IsEnabledColumn                        = postgres.BoolColumn("IsEnabled")
+		PhoneNumberIdColumn                    = postgres.StringColumn("PhoneNumberId")
+		TagIdColumn                            = postgres.StringColumn("TagId")
+		MaxConcurrentConversationsColumn       = postgres.IntegerColumn("MaxConcurrentConversations")
+		LastAssignedOrganizationMemberIdColumn = postgres.StringColumn("LastAssignedOrganizationMemberId")
+		allColumns                             = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, NameColumn, StrategyColumn, PriorityColumn, IsEnabledColumn, PhoneNumberIdColumn, TagIdColumn, MaxConcurrentConversationsColumn, LastAssignedOrganizationMemberIdColumn}
+		mutableColumns                         = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, NameColumn, StrategyColumn, PriorityColumn, IsEnabledColumn, PhoneNumberIdColumn, TagIdColumn, MaxConcurrentConversationsColumn, LastAssignedOrganizationMemberIdColumn}
+	)
+
+	return conversationRoutingRuleTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:                         UniqueIdColumn,
+		CreatedAt:                        CreatedAtColumn,
+		UpdatedAt:                        UpdatedAtColumn,
+		OrganizationId:                   OrganizationIdColumn,
+		Name:                             NameColumn,
+		Strategy:                         StrategyColumn,
+		Priority:                         PriorityColumn,
+		IsEnabled:                        IsEnabledColumn,
+		PhoneNumberId:                    PhoneNumberIdColumn,
+		TagId:                            TagIdColumn,
+		MaxConcurrentConversations:       MaxConcurrentConversationsColumn,
+		LastAssignedOrganizationMemberId: LastAssignedOrganizationMemberIdColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
@@ -0,0 +1,84 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var ConversationRoutingRuleMember = newConversationRoutingRuleMemberTable("public", "ConversationRoutingRuleMember", "")
+
+type conversationRoutingRuleMemberTable struct {
+	postgres.Table
+
+	// Columns
+	CreatedAt            postgres.ColumnTimestampz
+	UpdatedAt            postgres.ColumnTimestampz
+	RoutingRuleId        postgres.ColumnString
+	OrganizationMemberId postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type ConversationRoutingRuleMemberTable struct {
+	conversationRoutingRuleMemberTable
+
+	EXCLUDED conversationRoutingRuleMemberTable
+}
+
+// AS creates new ConversationRoutingRuleMemberTable with assigned alias
+func (a ConversationRoutingRuleMemberTable) AS(alias string) *ConversationRoutingRuleMemberTable {
+	return newConversationRoutingRuleMemberTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new ConversationRoutingRuleMemberTable with assigned schema name
+func (a ConversationRoutingRuleMemberTable) FromSchema(schemaName string) *ConversationRoutingRuleMemberTable {
+	return newConversationRoutingRuleMemberTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new ConversationRoutingRuleMemberTable with assigned table prefix
+func (a ConversationRoutingRuleMemberTable) WithPrefix(prefix string) *ConversationRoutingRuleMemberTable {
+	return newConversationRoutingRuleMemberTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new ConversationRoutingRuleMemberTable with assigned table suffix
+func (a ConversationRoutingRuleMemberTable) WithSuffix(suffix string) *ConversationRoutingRuleMemberTable {
+	return newConversationRoutingRuleMemberTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newConversationRoutingRuleMemberTable(schemaName, tableName, alias string) *ConversationRoutingRuleMemberTable {
+	return &ConversationRoutingRuleMemberTable{
+		conversationRoutingRuleMemberTable: newConversationRoutingRuleMemberTableImpl(schemaName, tableName, alias),
+		EXCLUDED:                           newConversationRoutingRuleMemberTableImpl("", "excluded", ""),
+	}
+}
+
+func newConversationRoutingRuleMemberTableImpl(schemaName, tableName, alias string) conversationRoutingRuleMemberTable {
+	var (
+		CreatedAtColumn            = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn            = postgres.TimestampzColumn("UpdatedAt")
+		RoutingRuleIdColumn        = postgres.StringColumn("RoutingRuleId")
+		OrganizationMemberIdColumn = postgres.StringColumn("OrganizationMemberId")
+		allColumns                 = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, RoutingRuleIdColumn, OrganizationMemberIdColumn}
+		mutableColumns             = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn}
+	)
+
+	return conversationRoutingRuleMemberTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		CreatedAt:            CreatedAtColumn,
+		UpdatedAt:            UpdatedAtColumn,
+		RoutingRuleId:        RoutingRuleIdColumn,
+		OrganizationMemberId: OrganizationMemberIdColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
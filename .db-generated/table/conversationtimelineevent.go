@@ -0,0 +1,102 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var ConversationTimelineEvent = newConversationTimelineEventTable("public", "ConversationTimelineEvent", "")
+
+type conversationTimelineEventTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId                  postgres.ColumnString
+	CreatedAt                 postgres.ColumnTimestampz
+	ConversationId            postgres.ColumnString
+	OrganizationId            postgres.ColumnString
+	EventType                 postgres.ColumnString
+	Trigger                   postgres.ColumnString
+	FromStatus                postgres.ColumnString
+	ToStatus                  postgres.ColumnString
+	ActorOrganizationMemberId postgres.ColumnString
+	SnoozedUntil              postgres.ColumnTimestampz
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type ConversationTimelineEventTable struct {
+	conversationTimelineEventTable
+
+	EXCLUDED conversationTimelineEventTable
+}
+
+// AS creates new ConversationTimelineEventTable with assigned alias
+func (a ConversationTimelineEventTable) AS(alias string) *ConversationTimelineEventTable {
+	return newConversationTimelineEventTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new ConversationTimelineEventTable with assigned schema name
+func (a ConversationTimelineEventTable) FromSchema(schemaName string) *ConversationTimelineEventTable {
+	return newConversationTimelineEventTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new ConversationTimelineEventTable with assigned table prefix
+func (a ConversationTimelineEventTable) WithPrefix(prefix string) *ConversationTimelineEventTable {
+	return newConversationTimelineEventTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new ConversationTimelineEventTable with assigned table suffix
+func (a ConversationTimelineEventTable) WithSuffix(suffix string) *ConversationTimelineEventTable {
+	return newConversationTimelineEventTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newConversationTimelineEventTable(schemaName, tableName, alias string) *ConversationTimelineEventTable {
+	return &ConversationTimelineEventTable{
+		conversationTimelineEventTable: newConversationTimelineEventTableImpl(schemaName, tableName, alias),
+		EXCLUDED:                       newConversationTimelineEventTableImpl("", "excluded", ""),
+	}
+}
+
+func newConversationTimelineEventTableImpl(schemaName, tableName, alias string) conversationTimelineEventTable {
+	var (
+		UniqueIdColumn                  = postgres.StringColumn("UniqueId")
+		CreatedAtColumn                 = postgres.TimestampzColumn("CreatedAt")
+		ConversationIdColumn            = postgres.StringColumn("ConversationId")
+		OrganizationIdColumn            = postgres.StringColumn("OrganizationId")
+		EventTypeColumn                 = postgres.StringColumn("EventType")
+		TriggerColumn                   = postgres.StringColumn("Trigger")
+		FromStatusColumn                = postgres.StringColumn("FromStatus")
+		ToStatusColumn                  = postgres.StringColumn("ToStatus")
+		ActorOrganizationMemberIdColumn = postgres.StringColumn("ActorOrganizationMemberId")
+		SnoozedUntilColumn              = postgres.TimestampzColumn("SnoozedUntil")
+		allColumns                      = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, ConversationIdColumn, OrganizationIdColumn, EventTypeColumn, TriggerColumn, FromStatusColumn, ToStatusColumn, ActorOrganizationMemberIdColumn, SnoozedUntilColumn}
+		mutableColumns                  = postgres.ColumnList{CreatedAtColumn, ConversationIdColumn, OrganizationIdColumn, EventTypeColumn, TriggerColumn, FromStatusColumn, ToStatusColumn, ActorOrganizationMemberIdColumn, SnoozedUntilColumn}
+	)
+
+	return conversationTimelineEventTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:                  UniqueIdColumn,
+		CreatedAt:                 CreatedAtColumn,
+		ConversationId:            ConversationIdColumn,
+		OrganizationId:            OrganizationIdColumn,
+		EventType:                 EventTypeColumn,
+		Trigger:                   TriggerColumn,
+		FromStatus:                FromStatusColumn,
+		ToStatus:                  ToStatusColumn,
+		ActorOrganizationMemberId: ActorOrganizationMemberIdColumn,
+		SnoozedUntil:              SnoozedUntilColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
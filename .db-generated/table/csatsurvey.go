@@ -0,0 +1,105 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var CsatSurvey = newCsatSurveyTable("public", "CsatSurvey", "")
+
+type csatSurveyTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId             postgres.ColumnString
+	CreatedAt            postgres.ColumnTimestampz
+	UpdatedAt            postgres.ColumnTimestampz
+	ConversationId       postgres.ColumnString
+	OrganizationId       postgres.ColumnString
+	OrganizationMemberId postgres.ColumnString
+	PhoneNumberUsed      postgres.ColumnString
+	WhatsAppMessageId    postgres.ColumnString
+	Rating               postgres.ColumnInteger
+	Comment              postgres.ColumnString
+	RespondedAt          postgres.ColumnTimestampz
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type CsatSurveyTable struct {
+	csatSurveyTable
+
+	EXCLUDED csatSurveyTable
+}
+
+// AS creates new CsatSurveyTable with assigned alias
+func (a CsatSurveyTable) AS(alias string) *CsatSurveyTable {
+	return newCsatSurveyTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new CsatSurveyTable with assigned schema name
+func (a CsatSurveyTable) FromSchema(schemaName string) *CsatSurveyTable {
+	return newCsatSurveyTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new CsatSurveyTable with assigned table prefix
+func (a CsatSurveyTable) WithPrefix(prefix string) *CsatSurveyTable {
+	return newCsatSurveyTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new CsatSurveyTable with assigned table suffix
+func (a CsatSurveyTable) WithSuffix(suffix string) *CsatSurveyTable {
+	return newCsatSurveyTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newCsatSurveyTable(schemaName, tableName, alias string) *CsatSurveyTable {
+	return &CsatSurveyTable{
+		csatSurveyTable: newCsatSurveyTableImpl(schemaName, tableName, alias),
+		EXCLUDED:        newCsatSurveyTableImpl("", "excluded", ""),
+	}
+}
+
+func newCsatSurveyTableImpl(schemaName, tableName, alias string) csatSurveyTable {
+	var (
+		UniqueIdColumn             = postgres.StringColumn("UniqueId")
+		CreatedAtColumn            = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn            = postgres.TimestampzColumn("UpdatedAt")
+		ConversationIdColumn       = postgres.StringColumn("ConversationId")
+		OrganizationIdColumn       = postgres.StringColumn("OrganizationId")
+		OrganizationMemberIdColumn = postgres.StringColumn("OrganizationMemberId")
+		PhoneNumberUsedColumn      = postgres.StringColumn("PhoneNumberUsed")
+		WhatsAppMessageIdColumn    = postgres.StringColumn("WhatsAppMessageId")
+		RatingColumn               = postgres.IntegerColumn("Rating")
+		CommentColumn              = postgres.StringColumn("Comment")
+		RespondedAtColumn          = postgres.TimestampzColumn("RespondedAt")
+		allColumns                 = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, ConversationIdColumn, OrganizationIdColumn, OrganizationMemberIdColumn, PhoneNumberUsedColumn, WhatsAppMessageIdColumn, RatingColumn, CommentColumn, RespondedAtColumn}
+		mutableColumns             = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, ConversationIdColumn, OrganizationIdColumn, OrganizationMemberIdColumn, PhoneNumberUsedColumn, WhatsAppMessageIdColumn, RatingColumn, CommentColumn, RespondedAtColumn}
+	)
+
+	return csatSurveyTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:             UniqueIdColumn,
+		CreatedAt:            CreatedAtColumn,
+		UpdatedAt:            UpdatedAtColumn,
+		ConversationId:       ConversationIdColumn,
+		OrganizationId:       OrganizationIdColumn,
+		OrganizationMemberId: OrganizationMemberIdColumn,
+		PhoneNumberUsed:      PhoneNumberUsedColumn,
+		WhatsAppMessageId:    WhatsAppMessageIdColumn,
+		Rating:               RatingColumn,
+		Comment:              CommentColumn,
+		RespondedAt:          RespondedAtColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
@@ -24,6 +24,7 @@ type organizationMemberTable struct {
 	OrganizationId postgres.ColumnString
 	UserId         postgres.ColumnString
 	InviteId       postgres.ColumnString
+	IsAvailable    postgres.ColumnBool
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -71,8 +72,9 @@ func newOrganizationMemberTableImpl(schemaName, tableName, alias string) organiz
 		OrganizationIdColumn = postgres.StringColumn("OrganizationId")
 		UserIdColumn         = postgres.StringColumn("UserId")
 		InviteIdColumn       = postgres.StringColumn("InviteId")
-		allColumns           = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, AccessLevelColumn, OrganizationIdColumn, UserIdColumn, InviteIdColumn}
-		mutableColumns       = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, AccessLevelColumn, OrganizationIdColumn, UserIdColumn, InviteIdColumn}
+		IsAvailableColumn    = postgres.BoolColumn("IsAvailable")
+		allColumns           = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, AccessLevelColumn, OrganizationIdColumn, UserIdColumn, InviteIdColumn, IsAvailableColumn}
+		mutableColumns       = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, AccessLevelColumn, OrganizationIdColumn, UserIdColumn, InviteIdColumn, IsAvailableColumn}
 	)
 
 	return organizationMemberTable{
@@ -86,6 +88,7 @@ func newOrganizationMemberTableImpl(schemaName, tableName, alias string) organiz
 		OrganizationId: OrganizationIdColumn,
 		UserId:         UserIdColumn,
 		InviteId:       InviteIdColumn,
+		IsAvailable:    IsAvailableColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
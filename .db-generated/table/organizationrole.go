@@ -17,13 +17,14 @@ type organizationRoleTable struct {
 	postgres.Table
 
 	// Columns
-	UniqueId       postgres.ColumnString
-	CreatedAt      postgres.ColumnTimestampz
-	UpdatedAt      postgres.ColumnTimestampz
-	Name           postgres.ColumnString
-	Description    postgres.ColumnString
-	Permissions    postgres.ColumnString
-	OrganizationId postgres.ColumnString
+	UniqueId            postgres.ColumnString
+	CreatedAt           postgres.ColumnTimestampz
+	UpdatedAt           postgres.ColumnTimestampz
+	Name                postgres.ColumnString
+	Description         postgres.ColumnString
+	Permissions         postgres.ColumnString
+	OrganizationId      postgres.ColumnString
+	IsTwoFactorRequired postgres.ColumnBool
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -64,28 +65,30 @@ func newOrganizationRoleTable(schemaName, tableName, alias string) *Organization
 
 func newOrganizationRoleTableImpl(schemaName, tableName, alias string) organizationRoleTable {
 	var (
-		UniqueIdColumn       = postgres.StringColumn("UniqueId")
-		CreatedAtColumn      = postgres.TimestampzColumn("CreatedAt")
-		UpdatedAtColumn      = postgres.TimestampzColumn("UpdatedAt")
-		NameColumn           = postgres.StringColumn("Name")
-		DescriptionColumn    = postgres.StringColumn("Description")
-		PermissionsColumn    = postgres.StringColumn("Permissions")
-		OrganizationIdColumn = postgres.StringColumn("OrganizationId")
-		allColumns           = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, NameColumn, DescriptionColumn, PermissionsColumn, OrganizationIdColumn}
-		mutableColumns       = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, NameColumn, DescriptionColumn, PermissionsColumn, OrganizationIdColumn}
+		UniqueIdColumn            = postgres.StringColumn("UniqueId")
+		CreatedAtColumn           = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn           = postgres.TimestampzColumn("UpdatedAt")
+		NameColumn                = postgres.StringColumn("Name")
+		DescriptionColumn         = postgres.StringColumn("Description")
+		PermissionsColumn         = postgres.StringColumn("Permissions")
+		OrganizationIdColumn      = postgres.StringColumn("OrganizationId")
+		IsTwoFactorRequiredColumn = postgres.BoolColumn("IsTwoFactorRequired")
+		allColumns                = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, NameColumn, DescriptionColumn, PermissionsColumn, OrganizationIdColumn, IsTwoFactorRequiredColumn}
+		mutableColumns            = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, NameColumn, DescriptionColumn, PermissionsColumn, OrganizationIdColumn, IsTwoFactorRequiredColumn}
 	)
 
 	return organizationRoleTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		UniqueId:       UniqueIdColumn,
-		CreatedAt:      CreatedAtColumn,
-		UpdatedAt:      UpdatedAtColumn,
-		Name:           NameColumn,
-		Description:    DescriptionColumn,
-		Permissions:    PermissionsColumn,
-		OrganizationId: OrganizationIdColumn,
+		UniqueId:            UniqueIdColumn,
+		CreatedAt:           CreatedAtColumn,
+		UpdatedAt:           UpdatedAtColumn,
+		Name:                NameColumn,
+		Description:         DescriptionColumn,
+		Permissions:         PermissionsColumn,
+		OrganizationId:      OrganizationIdColumn,
+		IsTwoFactorRequired: IsTwoFactorRequiredColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
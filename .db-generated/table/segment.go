@@ -0,0 +1,93 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var Segment = newSegmentTable("public", "Segment", "")
+
+type segmentTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId       postgres.ColumnString
+	CreatedAt      postgres.ColumnTimestampz
+	UpdatedAt      postgres.ColumnTimestampz
+	OrganizationId postgres.ColumnString
+	Name           postgres.ColumnString
+	Description    postgres.ColumnString
+	Rules          postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type SegmentTable struct {
+	segmentTable
+
+	EXCLUDED segmentTable
+}
+
+// AS creates new SegmentTable with assigned alias
+func (a SegmentTable) AS(alias string) *SegmentTable {
+	return newSegmentTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new SegmentTable with assigned schema name
+func (a SegmentTable) FromSchema(schemaName string) *SegmentTable {
+	return newSegmentTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new SegmentTable with assigned table prefix
+func (a SegmentTable) WithPrefix(prefix string) *SegmentTable {
+	return newSegmentTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new SegmentTable with assigned table suffix
+func (a SegmentTable) WithSuffix(suffix string) *SegmentTable {
+	return newSegmentTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newSegmentTable(schemaName, tableName, alias string) *SegmentTable {
+	return &SegmentTable{
+		segmentTable: newSegmentTableImpl(schemaName, tableName, alias),
+		EXCLUDED:     newSegmentTableImpl("", "excluded", ""),
+	}
+}
+
+func newSegmentTableImpl(schemaName, tableName, alias string) segmentTable {
+	var (
+		UniqueIdColumn       = postgres.StringColumn("UniqueId")
+		CreatedAtColumn      = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn      = postgres.TimestampzColumn("UpdatedAt")
+		OrganizationIdColumn = postgres.StringColumn("OrganizationId")
+		NameColumn           = postgres.StringColumn("Name")
+		DescriptionColumn    = postgres.StringColumn("Description")
+		RulesColumn          = postgres.StringColumn("Rules")
+		allColumns           = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, NameColumn, DescriptionColumn, RulesColumn}
+		mutableColumns       = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, NameColumn, DescriptionColumn, RulesColumn}
+	)
+
+	return segmentTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:       UniqueIdColumn,
+		CreatedAt:      CreatedAtColumn,
+		UpdatedAt:      UpdatedAtColumn,
+		OrganizationId: OrganizationIdColumn,
+		Name:           NameColumn,
+		Description:    DescriptionColumn,
+		Rules:          RulesColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
@@ -0,0 +1,117 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var SlaPolicy = newSlaPolicyTable("public", "SlaPolicy", "")
+
+type slaPolicyTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId                       postgres.ColumnString
+	CreatedAt                      postgres.ColumnTimestampz
+	UpdatedAt                      postgres.ColumnTimestampz
+	OrganizationId                 postgres.ColumnString
+	Name                           postgres.ColumnString
+	IsDefault                      postgres.ColumnBool
+	IsEnabled                      postgres.ColumnBool
+	PhoneNumberId                  postgres.ColumnString
+	FirstResponseTimeInMinutes     postgres.ColumnInteger
+	ResolutionTimeInMinutes        postgres.ColumnInteger
+	Timezone                       postgres.ColumnString
+	BusinessCalendar               postgres.ColumnString
+	EscalationAction               postgres.ColumnString
+	EscalationTagId                postgres.ColumnString
+	EscalationOrganizationMemberId postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type SlaPolicyTable struct {
+	slaPolicyTable
+
+	EXCLUDED slaPolicyTable
+}
+
+// AS creates new SlaPolicyTable with assigned alias
+func (a SlaPolicyTable) AS(alias string) *SlaPolicyTable {
+	return newSlaPolicyTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new SlaPolicyTable with assigned schema name
+func (a SlaPolicyTable) FromSchema(schemaName string) *SlaPolicyTable {
+	return newSlaPolicyTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new SlaPolicyTable with assigned table prefix
+func (a SlaPolicyTable) WithPrefix(prefix string) *SlaPolicyTable {
+	return newSlaPolicyTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new SlaPolicyTable with assigned table suffix
+func (a SlaPolicyTable) WithSuffix(suffix string) *SlaPolicyTable {
+	return newSlaPolicyTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newSlaPolicyTable(schemaName, tableName, alias string) *SlaPolicyTable {
+	return &SlaPolicyTable{
+		slaPolicyTable: newSlaPolicyTableImpl(schemaName, tableName, alias),
+		EXCLUDED:       newSlaPolicyTableImpl("", "excluded", ""),
+	}
+}
+
+func newSlaPolicyTableImpl(schemaName, tableName, alias string) slaPolicyTable {
+	var (
+		UniqueIdColumn                       = postgres.StringColumn("UniqueId")
+		CreatedAtColumn                      = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn                      = postgres.TimestampzColumn("UpdatedAt")
+		OrganizationIdColumn                 = postgres.StringColumn("OrganizationId")
+		NameColumn                           = postgres.StringColumn("Name")
+		IsDefaultColumn                      = postgres.BoolColumn("IsDefault")
+		IsEnabledColumn                      = postgres.BoolColumn("IsEnabled")
+		PhoneNumberIdColumn                  = postgres.StringColumn("PhoneNumberId")
+		FirstResponseTimeInMinutesColumn     = postgres.IntegerColumn("FirstResponseTimeInMinutes")
+		ResolutionTimeInMinutesColumn        = postgres.IntegerColumn("ResolutionTimeInMinutes")
+		TimezoneColumn                       = postgres.StringColumn("Timezone")
+		BusinessCalendarColumn               = postgres.StringColumn("BusinessCalendar")
+		EscalationActionColumn               = postgres.StringColumn("EscalationAction")
+		EscalationTagIdColumn                = postgres.StringColumn("EscalationTagId")
+		EscalationOrganizationMemberIdColumn = postgres.StringColumn("EscalationOrganizationMemberId")
+		allColumns                           = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, NameColumn, IsDefaultColumn, IsEnabledColumn, PhoneNumberIdColumn, FirstResponseTimeInMinutesColumn, ResolutionTimeInMinutesColumn, TimezoneColumn, BusinessCalendarColumn, EscalationActionColumn, EscalationTagIdColumn, EscalationOrganizationMemberIdColumn}
+		mutableColumns                       = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, OrganizationIdColumn, NameColumn, IsDefaultColumn, IsEnabledColumn, PhoneNumberIdColumn, FirstResponseTimeInMinutesColumn, ResolutionTimeInMinutesColumn, TimezoneColumn, BusinessCalendarColumn, EscalationActionColumn, EscalationTagIdColumn, EscalationOrganizationMemberIdColumn}
+	)
+
+	return slaPolicyTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:                       UniqueIdColumn,
+		CreatedAt:                      CreatedAtColumn,
+		UpdatedAt:                      UpdatedAtColumn,
+		OrganizationId:                 OrganizationIdColumn,
+		Name:                           NameColumn,
+		IsDefault:                      IsDefaultColumn,
+		IsEnabled:                      IsEnabledColumn,
+		PhoneNumberId:                  PhoneNumberIdColumn,
+		FirstResponseTimeInMinutes:     FirstResponseTimeInMinutesColumn,
+		ResolutionTimeInMinutes:        ResolutionTimeInMinutesColumn,
+		Timezone:                       TimezoneColumn,
+		BusinessCalendar:               BusinessCalendarColumn,
+		EscalationAction:               EscalationActionColumn,
+		EscalationTagId:                EscalationTagIdColumn,
+		EscalationOrganizationMemberId: EscalationOrganizationMemberIdColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
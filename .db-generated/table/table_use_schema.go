@@ -16,16 +16,32 @@ func UseSchema(schema string) {
 	AiChatMessageVote = AiChatMessageVote.FromSchema(schema)
 	AiChatSuggestions = AiChatSuggestions.FromSchema(schema)
 	ApiKey = ApiKey.FromSchema(schema)
+	AuditLog = AuditLog.FromSchema(schema)
+	BackgroundJob = BackgroundJob.FromSchema(schema)
+	BackgroundJobItemError = BackgroundJobItemError.FromSchema(schema)
 	Campaign = Campaign.FromSchema(schema)
 	CampaignList = CampaignList.FromSchema(schema)
+	CampaignSegment = CampaignSegment.FromSchema(schema)
 	CampaignTag = CampaignTag.FromSchema(schema)
+	CannedResponse = CannedResponse.FromSchema(schema)
+	CannedResponseTag = CannedResponseTag.FromSchema(schema)
 	Contact = Contact.FromSchema(schema)
+	ContactActivity = ContactActivity.FromSchema(schema)
+	ContactCaptureSource = ContactCaptureSource.FromSchema(schema)
+	ContactConsent = ContactConsent.FromSchema(schema)
+	ContactField = ContactField.FromSchema(schema)
 	ContactList = ContactList.FromSchema(schema)
 	ContactListContact = ContactListContact.FromSchema(schema)
 	ContactListTag = ContactListTag.FromSchema(schema)
 	Conversation = Conversation.FromSchema(schema)
 	ConversationAssignment = ConversationAssignment.FromSchema(schema)
+	ConversationNote = ConversationNote.FromSchema(schema)
+	ConversationNoteMention = ConversationNoteMention.FromSchema(schema)
+	ConversationRoutingRule = ConversationRoutingRule.FromSchema(schema)
+	ConversationRoutingRuleMember = ConversationRoutingRuleMember.FromSchema(schema)
 	ConversationTag = ConversationTag.FromSchema(schema)
+	ConversationTimelineEvent = ConversationTimelineEvent.FromSchema(schema)
+	CsatSurvey = CsatSurvey.FromSchema(schema)
 	Integration = Integration.FromSchema(schema)
 	Message = Message.FromSchema(schema)
 	Notification = Notification.FromSchema(schema)
@@ -36,9 +52,14 @@ func UseSchema(schema string) {
 	OrganizationMemberInvite = OrganizationMemberInvite.FromSchema(schema)
 	OrganizationRole = OrganizationRole.FromSchema(schema)
 	RoleAssignment = RoleAssignment.FromSchema(schema)
+	Segment = Segment.FromSchema(schema)
+	SlaPolicy = SlaPolicy.FromSchema(schema)
 	Tag = Tag.FromSchema(schema)
 	TrackLink = TrackLink.FromSchema(schema)
 	TrackLinkClick = TrackLinkClick.FromSchema(schema)
 	User = User.FromSchema(schema)
+	UserOauthAccount = UserOauthAccount.FromSchema(schema)
+	UserRecoveryCode = UserRecoveryCode.FromSchema(schema)
+	UserSession = UserSession.FromSchema(schema)
 	WhatsappBusinessAccount = WhatsappBusinessAccount.FromSchema(schema)
 }
@@ -17,17 +17,19 @@ type userTable struct {
 	postgres.Table
 
 	// Columns
-	UniqueId          postgres.ColumnString
-	CreatedAt         postgres.ColumnTimestampz
-	UpdatedAt         postgres.ColumnTimestampz
-	Name              postgres.ColumnString
-	Email             postgres.ColumnString
-	PhoneNumber       postgres.ColumnString
-	Username          postgres.ColumnString
-	Password          postgres.ColumnString
-	OauthProvider     postgres.ColumnString
-	ProfilePictureUrl postgres.ColumnString
-	Status            postgres.ColumnString
+	UniqueId           postgres.ColumnString
+	CreatedAt          postgres.ColumnTimestampz
+	UpdatedAt          postgres.ColumnTimestampz
+	Name               postgres.ColumnString
+	Email              postgres.ColumnString
+	PhoneNumber        postgres.ColumnString
+	Username           postgres.ColumnString
+	Password           postgres.ColumnString
+	OauthProvider      postgres.ColumnString
+	ProfilePictureUrl  postgres.ColumnString
+	Status             postgres.ColumnString
+	TwoFactorSecret    postgres.ColumnString
+	TwoFactorEnabledAt postgres.ColumnTimestampz
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -68,36 +70,40 @@ func newUserTable(schemaName, tableName, alias string) *UserTable {
 
 func newUserTableImpl(schemaName, tableName, alias string) userTable {
 	var (
-		UniqueIdColumn          = postgres.StringColumn("UniqueId")
-		CreatedAtColumn         = postgres.TimestampzColumn("CreatedAt")
-		UpdatedAtColumn         = postgres.TimestampzColumn("UpdatedAt")
-		NameColumn              = postgres.StringColumn("Name")
-		EmailColumn             = postgres.StringColumn("Email")
-		PhoneNumberColumn       = postgres.StringColumn("PhoneNumber")
-		UsernameColumn          = postgres.StringColumn("Username")
-		PasswordColumn          = postgres.StringColumn("Password")
-		OauthProviderColumn     = postgres.StringColumn("OauthProvider")
-		ProfilePictureUrlColumn = postgres.StringColumn("ProfilePictureUrl")
-		StatusColumn            = postgres.StringColumn("Status")
-		allColumns              = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, NameColumn, EmailColumn, PhoneNumberColumn, UsernameColumn, PasswordColumn, OauthProviderColumn, ProfilePictureUrlColumn, StatusColumn}
-		mutableColumns          = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, NameColumn, EmailColumn, PhoneNumberColumn, UsernameColumn, PasswordColumn, OauthProviderColumn, ProfilePictureUrlColumn, StatusColumn}
+		UniqueIdColumn           = postgres.StringColumn("UniqueId")
+		CreatedAtColumn          = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn          = postgres.TimestampzColumn("UpdatedAt")
+		NameColumn               = postgres.StringColumn("Name")
+		EmailColumn              = postgres.StringColumn("Email")
+		PhoneNumberColumn        = postgres.StringColumn("PhoneNumber")
+		UsernameColumn           = postgres.StringColumn("Username")
+		PasswordColumn           = postgres.StringColumn("Password")
+		OauthProviderColumn      = postgres.StringColumn("OauthProvider")
+		ProfilePictureUrlColumn  = postgres.StringColumn("ProfilePictureUrl")
+		StatusColumn             = postgres.StringColumn("Status")
+		TwoFactorSecretColumn    = postgres.StringColumn("TwoFactorSecret")
+		TwoFactorEnabledAtColumn = postgres.TimestampzColumn("TwoFactorEnabledAt")
+		allColumns               = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, NameColumn, EmailColumn, PhoneNumberColumn, UsernameColumn, PasswordColumn, OauthProviderColumn, ProfilePictureUrlColumn, StatusColumn, TwoFactorSecretColumn, TwoFactorEnabledAtColumn}
+		mutableColumns           = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, NameColumn, EmailColumn, PhoneNumberColumn, UsernameColumn, PasswordColumn, OauthProviderColumn, ProfilePictureUrlColumn, StatusColumn, TwoFactorSecretColumn, TwoFactorEnabledAtColumn}
 	)
 
 	return userTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		UniqueId:          UniqueIdColumn,
-		CreatedAt:         CreatedAtColumn,
-		UpdatedAt:         UpdatedAtColumn,
-		Name:              NameColumn,
-		Email:             EmailColumn,
-		PhoneNumber:       PhoneNumberColumn,
-		Username:          UsernameColumn,
-		Password:          PasswordColumn,
-		OauthProvider:     OauthProviderColumn,
-		ProfilePictureUrl: ProfilePictureUrlColumn,
-		Status:            StatusColumn,
+		UniqueId:           UniqueIdColumn,
+		CreatedAt:          CreatedAtColumn,
+		UpdatedAt:          UpdatedAtColumn,
+		Name:               NameColumn,
+		Email:              EmailColumn,
+		PhoneNumber:        PhoneNumberColumn,
+		Username:           UsernameColumn,
+		Password:           PasswordColumn,
+		OauthProvider:      OauthProviderColumn,
+		ProfilePictureUrl:  ProfilePictureUrlColumn,
+		Status:             StatusColumn,
+		TwoFactorSecret:    TwoFactorSecretColumn,
+		TwoFactorEnabledAt: TwoFactorEnabledAtColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
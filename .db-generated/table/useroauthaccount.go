@@ -0,0 +1,93 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var UserOauthAccount = newUserOauthAccountTable("public", "UserOauthAccount", "")
+
+type userOauthAccountTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId  postgres.ColumnString
+	CreatedAt postgres.ColumnTimestampz
+	UpdatedAt postgres.ColumnTimestampz
+	UserId    postgres.ColumnString
+	Provider  postgres.ColumnString
+	Subject   postgres.ColumnString
+	Email     postgres.ColumnString
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type UserOauthAccountTable struct {
+	userOauthAccountTable
+
+	EXCLUDED userOauthAccountTable
+}
+
+// AS creates new UserOauthAccountTable with assigned alias
+func (a UserOauthAccountTable) AS(alias string) *UserOauthAccountTable {
+	return newUserOauthAccountTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new UserOauthAccountTable with assigned schema name
+func (a UserOauthAccountTable) FromSchema(schemaName string) *UserOauthAccountTable {
+	return newUserOauthAccountTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new UserOauthAccountTable with assigned table prefix
+func (a UserOauthAccountTable) WithPrefix(prefix string) *UserOauthAccountTable {
+	return newUserOauthAccountTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new UserOauthAccountTable with assigned table suffix
+func (a UserOauthAccountTable) WithSuffix(suffix string) *UserOauthAccountTable {
+	return newUserOauthAccountTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newUserOauthAccountTable(schemaName, tableName, alias string) *UserOauthAccountTable {
+	return &UserOauthAccountTable{
+		userOauthAccountTable: newUserOauthAccountTableImpl(schemaName, tableName, alias),
+		EXCLUDED:              newUserOauthAccountTableImpl("", "excluded", ""),
+	}
+}
+
+func newUserOauthAccountTableImpl(schemaName, tableName, alias string) userOauthAccountTable {
+	var (
+		UniqueIdColumn  = postgres.StringColumn("UniqueId")
+		CreatedAtColumn = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn = postgres.TimestampzColumn("UpdatedAt")
+		UserIdColumn    = postgres.StringColumn("UserId")
+		ProviderColumn  = postgres.StringColumn("Provider")
+		SubjectColumn   = postgres.StringColumn("Subject")
+		EmailColumn     = postgres.StringColumn("Email")
+		allColumns      = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, UserIdColumn, ProviderColumn, SubjectColumn, EmailColumn}
+		mutableColumns  = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, UserIdColumn, ProviderColumn, SubjectColumn, EmailColumn}
+	)
+
+	return userOauthAccountTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:  UniqueIdColumn,
+		CreatedAt: CreatedAtColumn,
+		UpdatedAt: UpdatedAtColumn,
+		UserId:    UserIdColumn,
+		Provider:  ProviderColumn,
+		Subject:   SubjectColumn,
+		Email:     EmailColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
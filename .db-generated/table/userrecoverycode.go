@@ -0,0 +1,90 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var UserRecoveryCode = newUserRecoveryCodeTable("public", "UserRecoveryCode", "")
+
+type userRecoveryCodeTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId  postgres.ColumnString
+	CreatedAt postgres.ColumnTimestampz
+	UpdatedAt postgres.ColumnTimestampz
+	UserId    postgres.ColumnString
+	CodeHash  postgres.ColumnString
+	UsedAt    postgres.ColumnTimestampz
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type UserRecoveryCodeTable struct {
+	userRecoveryCodeTable
+
+	EXCLUDED userRecoveryCodeTable
+}
+
+// AS creates new UserRecoveryCodeTable with assigned alias
+func (a UserRecoveryCodeTable) AS(alias string) *UserRecoveryCodeTable {
+	return newUserRecoveryCodeTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new UserRecoveryCodeTable with assigned schema name
+func (a UserRecoveryCodeTable) FromSchema(schemaName string) *UserRecoveryCodeTable {
+	return newUserRecoveryCodeTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new UserRecoveryCodeTable with assigned table prefix
+func (a UserRecoveryCodeTable) WithPrefix(prefix string) *UserRecoveryCodeTable {
+	return newUserRecoveryCodeTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new UserRecoveryCodeTable with assigned table suffix
+func (a UserRecoveryCodeTable) WithSuffix(suffix string) *UserRecoveryCodeTable {
+	return newUserRecoveryCodeTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newUserRecoveryCodeTable(schemaName, tableName, alias string) *UserRecoveryCodeTable {
+	return &UserRecoveryCodeTable{
+		userRecoveryCodeTable: newUserRecoveryCodeTableImpl(schemaName, tableName, alias),
+		EXCLUDED:              newUserRecoveryCodeTableImpl("", "excluded", ""),
+	}
+}
+
+func newUserRecoveryCodeTableImpl(schemaName, tableName, alias string) userRecoveryCodeTable {
+	var (
+		UniqueIdColumn  = postgres.StringColumn("UniqueId")
+		CreatedAtColumn = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn = postgres.TimestampzColumn("UpdatedAt")
+		UserIdColumn    = postgres.StringColumn("UserId")
+		CodeHashColumn  = postgres.StringColumn("CodeHash")
+		UsedAtColumn    = postgres.TimestampzColumn("UsedAt")
+		allColumns      = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, UserIdColumn, CodeHashColumn, UsedAtColumn}
+		mutableColumns  = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, UserIdColumn, CodeHashColumn, UsedAtColumn}
+	)
+
+	return userRecoveryCodeTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:  UniqueIdColumn,
+		CreatedAt: CreatedAtColumn,
+		UpdatedAt: UpdatedAtColumn,
+		UserId:    UserIdColumn,
+		CodeHash:  CodeHashColumn,
+		UsedAt:    UsedAtColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
@@ -0,0 +1,105 @@
+//
+// Code generated by go-jet DO NOT EDIT.
+//
+// WARNING: Changes to this file may cause incorrect behavior
+// and will be lost if the code is regenerated
+//
+
+package table
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var UserSession = newUserSessionTable("public", "UserSession", "")
+
+type userSessionTable struct {
+	postgres.Table
+
+	// Columns
+	UniqueId         postgres.ColumnString
+	CreatedAt        postgres.ColumnTimestampz
+	UpdatedAt        postgres.ColumnTimestampz
+	UserId           postgres.ColumnString
+	OrganizationId   postgres.ColumnString
+	RefreshTokenHash postgres.ColumnString
+	UserAgent        postgres.ColumnString
+	IpAddress        postgres.ColumnString
+	LastUsedAt       postgres.ColumnTimestampz
+	ExpiresAt        postgres.ColumnTimestampz
+	RevokedAt        postgres.ColumnTimestampz
+
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+}
+
+type UserSessionTable struct {
+	userSessionTable
+
+	EXCLUDED userSessionTable
+}
+
+// AS creates new UserSessionTable with assigned alias
+func (a UserSessionTable) AS(alias string) *UserSessionTable {
+	return newUserSessionTable(a.SchemaName(), a.TableName(), alias)
+}
+
+// Schema creates new UserSessionTable with assigned schema name
+func (a UserSessionTable) FromSchema(schemaName string) *UserSessionTable {
+	return newUserSessionTable(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new UserSessionTable with assigned table prefix
+func (a UserSessionTable) WithPrefix(prefix string) *UserSessionTable {
+	return newUserSessionTable(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new UserSessionTable with assigned table suffix
+func (a UserSessionTable) WithSuffix(suffix string) *UserSessionTable {
+	return newUserSessionTable(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func newUserSessionTable(schemaName, tableName, alias string) *UserSessionTable {
+	return &UserSessionTable{
+		userSessionTable: newUserSessionTableImpl(schemaName, tableName, alias),
+		EXCLUDED:         newUserSessionTableImpl("", "excluded", ""),
+	}
+}
+
+func newUserSessionTableImpl(schemaName, tableName, alias string) userSessionTable {
+	var (
+		UniqueIdColumn         = postgres.StringColumn("UniqueId")
+		CreatedAtColumn        = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn        = postgres.TimestampzColumn("UpdatedAt")
+		UserIdColumn           = postgres.StringColumn("UserId")
+		OrganizationIdColumn   = postgres.StringColumn("OrganizationId")
+		RefreshTokenHashColumn = postgres.StringColumn("RefreshTokenHash")
+		UserAgentColumn        = postgres.StringColumn("UserAgent")
+		IpAddressColumn        = postgres.StringColumn("IpAddress")
+		LastUsedAtColumn       = postgres.TimestampzColumn("LastUsedAt")
+		ExpiresAtColumn        = postgres.TimestampzColumn("ExpiresAt")
+		RevokedAtColumn        = postgres.TimestampzColumn("RevokedAt")
+		allColumns             = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, UserIdColumn, OrganizationIdColumn, RefreshTokenHashColumn, UserAgentColumn, IpAddressColumn, LastUsedAtColumn, ExpiresAtColumn, RevokedAtColumn}
+		mutableColumns         = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, UserIdColumn, OrganizationIdColumn, RefreshTokenHashColumn, UserAgentColumn, IpAddressColumn, LastUsedAtColumn, ExpiresAtColumn, RevokedAtColumn}
+	)
+
+	return userSessionTable{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		//Columns
+		UniqueId:         UniqueIdColumn,
+		CreatedAt:        CreatedAtColumn,
+		UpdatedAt:        UpdatedAtColumn,
+		UserId:           UserIdColumn,
+		OrganizationId:   OrganizationIdColumn,
+		RefreshTokenHash: RefreshTokenHashColumn,
+		UserAgent:        UserAgentColumn,
+		IpAddress:        IpAddressColumn,
+		LastUsedAt:       LastUsedAtColumn,
+		ExpiresAt:        ExpiresAtColumn,
+		RevokedAt:        RevokedAtColumn,
+
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+	}
+}
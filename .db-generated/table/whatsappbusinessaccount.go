@@ -17,13 +17,18 @@ type whatsappBusinessAccountTable struct {
 	postgres.Table
 
 	// Columns
-	UniqueId       postgres.ColumnString
-	CreatedAt      postgres.ColumnTimestampz
-	UpdatedAt      postgres.ColumnTimestampz
-	AccountId      postgres.ColumnString
-	AccessToken    postgres.ColumnString
-	WebhookSecret  postgres.ColumnString
-	OrganizationId postgres.ColumnString
+	UniqueId        postgres.ColumnString
+	CreatedAt       postgres.ColumnTimestampz
+	UpdatedAt       postgres.ColumnTimestampz
+	AccountId       postgres.ColumnString
+	AccessToken     postgres.ColumnString
+	WebhookSecret   postgres.ColumnString
+	OrganizationId  postgres.ColumnString
+	TokenExpiresAt  postgres.ColumnTimestampz
+	TokenScopes     postgres.ColumnString
+	HealthStatus    postgres.ColumnString
+	HealthCheckedAt postgres.ColumnTimestampz
+	HealthError     postgres.ColumnString
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -64,28 +69,38 @@ func newWhatsappBusinessAccountTable(schemaName, tableName, alias string) *Whats
 
 func newWhatsappBusinessAccountTableImpl(schemaName, tableName, alias string) whatsappBusinessAccountTable {
 	var (
-		UniqueIdColumn       = postgres.StringColumn("UniqueId")
-		CreatedAtColumn      = postgres.TimestampzColumn("CreatedAt")
-		UpdatedAtColumn      = postgres.TimestampzColumn("UpdatedAt")
-		AccountIdColumn      = postgres.StringColumn("AccountId")
-		AccessTokenColumn    = postgres.StringColumn("AccessToken")
-		WebhookSecretColumn  = postgres.StringColumn("WebhookSecret")
-		OrganizationIdColumn = postgres.StringColumn("OrganizationId")
-		allColumns           = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, AccountIdColumn, AccessTokenColumn, WebhookSecretColumn, OrganizationIdColumn}
-		mutableColumns       = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, AccountIdColumn, AccessTokenColumn, WebhookSecretColumn, OrganizationIdColumn}
+		UniqueIdColumn        = postgres.StringColumn("UniqueId")
+		CreatedAtColumn       = postgres.TimestampzColumn("CreatedAt")
+		UpdatedAtColumn       = postgres.TimestampzColumn("UpdatedAt")
+		AccountIdColumn       = postgres.StringColumn("AccountId")
+		AccessTokenColumn     = postgres.StringColumn("AccessToken")
+		WebhookSecretColumn   = postgres.StringColumn("WebhookSecret")
+		OrganizationIdColumn  = postgres.StringColumn("OrganizationId")
+		TokenExpiresAtColumn  = postgres.TimestampzColumn("TokenExpiresAt")
+		TokenScopesColumn     = postgres.StringColumn("TokenScopes")
+		HealthStatusColumn    = postgres.StringColumn("HealthStatus")
+		HealthCheckedAtColumn = postgres.TimestampzColumn("HealthCheckedAt")
+		HealthErrorColumn     = postgres.StringColumn("HealthError")
+		allColumns            = postgres.ColumnList{UniqueIdColumn, CreatedAtColumn, UpdatedAtColumn, AccountIdColumn, AccessTokenColumn, WebhookSecretColumn, OrganizationIdColumn, TokenExpiresAtColumn, TokenScopesColumn, HealthStatusColumn, HealthCheckedAtColumn, HealthErrorColumn}
+		mutableColumns        = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, AccountIdColumn, AccessTokenColumn, WebhookSecretColumn, OrganizationIdColumn, TokenExpiresAtColumn, TokenScopesColumn, HealthStatusColumn, HealthCheckedAtColumn, HealthErrorColumn}
 	)
 
 	return whatsappBusinessAccountTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		UniqueId:       UniqueIdColumn,
-		CreatedAt:      CreatedAtColumn,
-		UpdatedAt:      UpdatedAtColumn,
-		AccountId:      AccountIdColumn,
-		AccessToken:    AccessTokenColumn,
-		WebhookSecret:  WebhookSecretColumn,
-		OrganizationId: OrganizationIdColumn,
+		UniqueId:        UniqueIdColumn,
+		CreatedAt:       CreatedAtColumn,
+		UpdatedAt:       UpdatedAtColumn,
+		AccountId:       AccountIdColumn,
+		AccessToken:     AccessTokenColumn,
+		WebhookSecret:   WebhookSecretColumn,
+		OrganizationId:  OrganizationIdColumn,
+		TokenExpiresAt:  TokenExpiresAtColumn,
+		TokenScopes:     TokenScopesColumn,
+		HealthStatus:    HealthStatusColumn,
+		HealthCheckedAt: HealthCheckedAtColumn,
+		HealthError:     HealthErrorColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -1,26 +1,38 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
+	controller "github.com/wapikit/wapikit/api/controllers"
 	"github.com/wapikit/wapikit/api/controllers/ai_controller"
 	"github.com/wapikit/wapikit/api/controllers/analytics_controller"
 	"github.com/wapikit/wapikit/api/controllers/auth_controller"
+	"github.com/wapikit/wapikit/api/controllers/background_job_controller"
 	"github.com/wapikit/wapikit/api/controllers/campaign_controller"
+	"github.com/wapikit/wapikit/api/controllers/canned_response_controller"
+	"github.com/wapikit/wapikit/api/controllers/contact_capture_controller"
 	"github.com/wapikit/wapikit/api/controllers/contact_controller"
+	"github.com/wapikit/wapikit/api/controllers/contact_field_controller"
 	"github.com/wapikit/wapikit/api/controllers/contact_list_controller"
 	"github.com/wapikit/wapikit/api/controllers/conversation_controller"
 	"github.com/wapikit/wapikit/api/controllers/integration_controller"
 	"github.com/wapikit/wapikit/api/controllers/next_files_controller"
 	"github.com/wapikit/wapikit/api/controllers/organization_controller"
+	"github.com/wapikit/wapikit/api/controllers/presence_controller"
 	"github.com/wapikit/wapikit/api/controllers/rbac_controller"
+	"github.com/wapikit/wapikit/api/controllers/routing_controller"
+	"github.com/wapikit/wapikit/api/controllers/search_controller"
+	"github.com/wapikit/wapikit/api/controllers/segment_controller"
+	"github.com/wapikit/wapikit/api/controllers/sla_controller"
 	"github.com/wapikit/wapikit/api/controllers/system_controller"
 	"github.com/wapikit/wapikit/api/controllers/user_controller"
 	"github.com/wapikit/wapikit/api/controllers/webhook_controller"
@@ -34,6 +46,7 @@ func InitHTTPServer(app *interfaces.App) *echo.Echo {
 	logger.Info("initializing HTTP server")
 	var server = echo.New()
 	server.HideBanner = true
+	server.IPExtractor = newIPExtractor(app)
 	server.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			c.Set("app", app)
@@ -49,6 +62,9 @@ func InitHTTPServer(app *interfaces.App) *echo.Echo {
 		server.GET("/*", echo.WrapHandler(fileServer))
 	}
 
+	// * drop the authorization details other instances invalidated from the cache of this one
+	go controller.ListenForAuthorizationCacheInvalidations(context.Background(), app)
+
 	// Mounting all HTTP handlers.
 	mountHandlerServices(server, app)
 
@@ -74,6 +90,41 @@ func InitHTTPServer(app *interfaces.App) *echo.Echo {
 	return server
 }
 
+// newIPExtractor returns the extractor of the ip address of the clients, the rate limits are kept per ip address. The
+// X-Forwarded-For header can be set by anyone, so it is only read when it was appended by one of the trusted proxies.
+func newIPExtractor(app *interfaces.App) echo.IPExtractor {
+	trustedProxies := app.Koa.Strings("app.trusted_proxies")
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	trustOptions := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+
+	for _, trustedProxy := range trustedProxies {
+		if !strings.Contains(trustedProxy, "/") {
+			if strings.Contains(trustedProxy, ":") {
+				trustedProxy += "/128"
+			} else {
+				trustedProxy += "/32"
+			}
+		}
+
+		_, ipRange, err := net.ParseCIDR(trustedProxy)
+		if err != nil {
+			app.Logger.Error("invalid trusted proxy, the ip addresses are read from the connections", "proxy", trustedProxy, "error", err.Error())
+			return echo.ExtractIPDirect()
+		}
+
+		trustOptions = append(trustOptions, echo.TrustIPRange(ipRange))
+	}
+
+	return echo.ExtractIPFromXFFHeader(trustOptions...)
+}
+
 // registerHandlers registers HTTP handlers.
 func mountHandlerServices(e *echo.Echo, app *interfaces.App) {
 	logger := app.Logger
@@ -119,7 +170,21 @@ func mountHandlerServices(e *echo.Echo, app *interfaces.App) {
 		MaxAge:           5,
 	}))
 
-	controllersToRegister := []interfaces.ApiController{}
+	controllersToRegister := newApiControllers(app)
+
+	if !isFrontendHostedSeparately {
+		logger.Info("Frontend is not hosted separately")
+		nextFileServerService := next_files_controller.NewNextFileServerController()
+		controllersToRegister = append(controllersToRegister, nextFileServerService)
+	}
+
+	for _, service := range controllersToRegister {
+		service.Register(e)
+	}
+}
+
+// newApiControllers returns the controllers of the rest api, the routes of the frontend are mounted separately
+func newApiControllers(app *interfaces.App) []interfaces.ApiController {
 	userController := user_controller.NewUserController()
 	authController := auth_controller.NewAuthController()
 	organizationController := organization_controller.NewOrganizationController()
@@ -133,11 +198,19 @@ func mountHandlerServices(e *echo.Echo, app *interfaces.App) {
 	roleBasedAccessControlController := rbac_controller.NewRoleBasedAccessControlController()
 	whatsappWebhookController := webhook_controller.NewWhatsappWebhookWebhookController(app.WapiClient)
 	aiController := ai_controller.NewAiController()
+	routingController := routing_controller.NewRoutingController()
+	presenceController := presence_controller.NewPresenceController()
+	slaController := sla_controller.NewSlaController()
+	cannedResponseController := canned_response_controller.NewCannedResponseController()
+	searchController := search_controller.NewSearchController()
+	backgroundJobController := background_job_controller.NewBackgroundJobController()
+	segmentController := segment_controller.NewSegmentController()
+	contactFieldController := contact_field_controller.NewContactFieldController()
+	contactCaptureController := contact_capture_controller.NewContactCaptureController()
 
 	// ! TODO: check for feature flags here before loading the services
 
-	controllersToRegister = append(
-		controllersToRegister,
+	return []interfaces.ApiController{
 		userController,
 		authController,
 		campaignController,
@@ -151,15 +224,14 @@ func mountHandlerServices(e *echo.Echo, app *interfaces.App) {
 		roleBasedAccessControlController,
 		whatsappWebhookController,
 		aiController,
-	)
-
-	if !isFrontendHostedSeparately {
-		logger.Info("Frontend is not hosted separately")
-		nextFileServerService := next_files_controller.NewNextFileServerController()
-		controllersToRegister = append(controllersToRegister, nextFileServerService)
-	}
-
-	for _, service := range controllersToRegister {
-		service.Register(e)
+		routingController,
+		presenceController,
+		slaController,
+		cannedResponseController,
+		searchController,
+		backgroundJobController,
+		segmentController,
+		contactFieldController,
+		contactCaptureController,
 	}
 }
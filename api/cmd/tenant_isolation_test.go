@@ -0,0 +1,458 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/wapikit/wapikit/internal/interfaces"
+	"github.com/wapikit/wapikit/internal/testutil"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// tenantResources are the rows seeded for an organization, one of every kind the routes take the id of
+type tenantResources struct {
+	testutil.Organization
+	Contact        model.Contact
+	ContactList    model.ContactList
+	Tag            model.Tag
+	Campaign       model.Campaign
+	Conversation   model.Conversation
+	Note           model.ConversationNote
+	AiChat         model.AiChat
+	AiChatMessage  model.AiChatMessage
+	CannedResponse model.CannedResponse
+	CaptureSource  model.ContactCaptureSource
+	ContactField   model.ContactField
+	BackgroundJob  model.BackgroundJob
+	Role           model.OrganizationRole
+	RoutingRule    model.ConversationRoutingRule
+	Segment        model.Segment
+	SlaPolicy      model.SlaPolicy
+}
+
+type isolationRequest struct {
+	path string
+	body interface{}
+}
+
+// skippedRoutes take an id which is not the id of a row of an organization, or are public
+var skippedRoutes = map[string]string{
+	"GET /api/organization/templates/:id":     "the template id is resolved through the business account of the organization of the session",
+	"GET /api/organization/phone-numbers/:id": "the phone number id is resolved through the business account of the organization of the session",
+	"GET /api/opt-in-forms/:code":             "public, the code of the form is the capability",
+	"POST /api/opt-in-forms/:code":            "public, the code of the form is the capability",
+	"GET /api/auth/oauth/:provider":           "public, the provider is a configured name",
+	"GET /api/analytics/:id":                  "integrations have no stored data yet",
+	"POST /api/analytics/:id/enable":          "integrations have no stored data yet",
+	"POST /api/analytics/:id/disable":         "integrations have no stored data yet",
+}
+
+// foreignIdRequests returns the request to send for every route taking an id, with the ids of the foreign organization
+// and a body which passes the validation done before the lookup of the row
+func foreignIdRequests(own, foreign tenantResources) map[string]isolationRequest {
+	contact := foreign.Contact.UniqueId.String()
+	list := foreign.ContactList.UniqueId.String()
+	campaign := foreign.Campaign.UniqueId.String()
+	conversation := foreign.Conversation.UniqueId.String()
+	aiChat := foreign.AiChat.UniqueId.String()
+	cannedResponse := foreign.CannedResponse.UniqueId.String()
+	captureSource := foreign.CaptureSource.UniqueId.String()
+	contactField := foreign.ContactField.UniqueId.String()
+	job := foreign.BackgroundJob.UniqueId.String()
+	role := foreign.Role.UniqueId.String()
+	routingRule := foreign.RoutingRule.UniqueId.String()
+	segment := foreign.Segment.UniqueId.String()
+	slaPolicy := foreign.SlaPolicy.UniqueId.String()
+	organization := foreign.Organization.Organization.UniqueId.String()
+	member := foreign.Member.UniqueId.String()
+
+	return map[string]isolationRequest{
+		"GET /api/contacts/:id":                   {path: "/api/contacts/" + contact},
+		"POST /api/contacts/:id":                  {path: "/api/contacts/" + contact},
+		"DELETE /api/contacts/:id":                {path: "/api/contacts/" + contact},
+		"GET /api/contacts/:id/data":              {path: "/api/contacts/" + contact + "/data"},
+		"GET /api/contacts/:id/timeline":          {path: "/api/contacts/" + contact + "/timeline"},
+		"GET /api/contacts/:id/consents":          {path: "/api/contacts/" + contact + "/consents"},
+		"POST /api/contacts/:id/consents":         {path: "/api/contacts/" + contact + "/consents"},
+		"POST /api/contacts/:id/erase":            {path: "/api/contacts/" + contact + "/erase", body: map[string]interface{}{"mode": "Delete"}},
+		"POST /api/contacts/:id/merge":            {path: "/api/contacts/" + contact + "/merge", body: map[string]interface{}{"duplicateIds": []string{own.Contact.UniqueId.String()}}},
+		"GET /api/lists/:id":                      {path: "/api/lists/" + list},
+		"POST /api/lists/:id":                     {path: "/api/lists/" + list},
+		"DELETE /api/lists/:id":                   {path: "/api/lists/" + list},
+		"GET /api/campaigns/:id":                  {path: "/api/campaigns/" + campaign},
+		"POST /api/campaigns/:id":                 {path: "/api/campaigns/" + campaign},
+		"DELETE /api/campaigns/:id":               {path: "/api/campaigns/" + campaign},
+		"GET /api/analytics/campaign/:campaignId": {path: "/api/analytics/campaign/" + campaign},
+
+		"GET /api/conversation/:id":                  {path: "/api/conversation/" + conversation},
+		"POST /api/conversation/:id":                 {path: "/api/conversation/" + conversation},
+		"DELETE /api/conversation/:id":               {path: "/api/conversation/" + conversation},
+		"GET /api/conversation/:id/assign":           {path: "/api/conversation/" + conversation + "/assign", body: map[string]interface{}{"organizationMemberId": own.Member.UniqueId.String()}},
+		"GET /api/conversation/:id/unassign":         {path: "/api/conversation/" + conversation + "/unassign"},
+		"GET /api/conversation/:id/messages":         {path: "/api/conversation/" + conversation + "/messages"},
+		"POST /api/conversation/:id/messages":        {path: "/api/conversation/" + conversation + "/messages"},
+		"POST /api/conversation/:id/resolve":         {path: "/api/conversation/" + conversation + "/resolve"},
+		"POST /api/conversation/:id/reopen":          {path: "/api/conversation/" + conversation + "/reopen"},
+		"POST /api/conversation/:id/snooze":          {path: "/api/conversation/" + conversation + "/snooze", body: map[string]interface{}{"snoozedUntil": time.Now().Add(time.Hour)}},
+		"GET /api/conversation/:id/timeline":         {path: "/api/conversation/" + conversation + "/timeline"},
+		"POST /api/conversation/:id/notes":           {path: "/api/conversation/" + conversation + "/notes"},
+		"DELETE /api/conversation/:id/notes/:noteId": {path: "/api/conversation/" + conversation + "/notes/" + foreign.Note.UniqueId.String()},
+		"GET /api/ai/chat/:id":                       {path: "/api/ai/chat/" + aiChat},
+		"GET /api/ai/chat/:id/messages":              {path: "/api/ai/chat/" + aiChat + "/messages"},
+		"POST /api/ai/chat/:id/messages":             {path: "/api/ai/chat/" + aiChat + "/messages"},
+		"GET /api/ai/chat/:id/vote":                  {path: "/api/ai/chat/" + aiChat + "/vote"},
+		"POST /api/ai/chat/:id/vote":                 {path: "/api/ai/chat/" + aiChat + "/vote", body: map[string]interface{}{"messageId": foreign.AiChatMessage.UniqueId.String(), "type": "Upvote"}},
+		"GET /api/canned-responses/:id":              {path: "/api/canned-responses/" + cannedResponse},
+		"POST /api/canned-responses/:id":             {path: "/api/canned-responses/" + cannedResponse},
+		"DELETE /api/canned-responses/:id":           {path: "/api/canned-responses/" + cannedResponse},
+		"POST /api/canned-responses/:id/render":      {path: "/api/canned-responses/" + cannedResponse + "/render", body: map[string]interface{}{"conversationId": own.Conversation.UniqueId.String()}},
+		"POST /api/contact-capture-sources/:id":      {path: "/api/contact-capture-sources/" + captureSource},
+		"DELETE /api/contact-capture-sources/:id":    {path: "/api/contact-capture-sources/" + captureSource},
+		"POST /api/contact-fields/:id":               {path: "/api/contact-fields/" + contactField},
+		"DELETE /api/contact-fields/:id":             {path: "/api/contact-fields/" + contactField},
+		"GET /api/jobs/:id":                          {path: "/api/jobs/" + job},
+		"GET /api/jobs/:id/errors":                   {path: "/api/jobs/" + job + "/errors"},
+		"GET /api/rbac/roles/:id":                    {path: "/api/rbac/roles/" + role},
+		"POST /api/rbac/roles/:id":                   {path: "/api/rbac/roles/" + role},
+		"DELETE /api/rbac/roles/:id":                 {path: "/api/rbac/roles/" + role},
+		"GET /api/routing/rules/:id":                 {path: "/api/routing/rules/" + routingRule},
+		"POST /api/routing/rules/:id":                {path: "/api/routing/rules/" + routingRule},
+		"DELETE /api/routing/rules/:id":              {path: "/api/routing/rules/" + routingRule},
+		"GET /api/segments/:id":                      {path: "/api/segments/" + segment},
+		"POST /api/segments/:id":                     {path: "/api/segments/" + segment},
+		"DELETE /api/segments/:id":                   {path: "/api/segments/" + segment},
+		"GET /api/sla/policies/:id":                  {path: "/api/sla/policies/" + slaPolicy},
+		"POST /api/sla/policies/:id":                 {path: "/api/sla/policies/" + slaPolicy},
+		"DELETE /api/sla/policies/:id":               {path: "/api/sla/policies/" + slaPolicy},
+		"GET /api/organization/:id":                  {path: "/api/organization/" + organization},
+		"POST /api/organization/:id":                 {path: "/api/organization/" + organization},
+		"POST /api/organization/:id/transfer":        {path: "/api/organization/" + organization + "/transfer", body: map[string]interface{}{"newOwnerId": own.Owner.UniqueId.String()}},
+		"GET /api/organization/members/:id":          {path: "/api/organization/members/" + member},
+		"POST /api/organization/members/:id":         {path: "/api/organization/members/" + member, body: map[string]interface{}{"accessLevel": "Member"}},
+		"DELETE /api/organization/members/:id":       {path: "/api/organization/members/" + member},
+		"POST /api/organization/members/:id/role":    {path: "/api/organization/members/" + member + "/role", body: map[string]interface{}{"updatedRoleIds": []string{}}},
+		"DELETE /api/user/sessions/:id":              {path: "/api/user/sessions/" + foreign.SessionId.String()},
+	}
+}
+
+// foreignBodyIdRequests send the ids of the foreign organization in the body, to routes whose path is of the own organization
+// or which take no id in the path
+func foreignBodyIdRequests(own, foreign tenantResources) map[string]isolationRequest {
+	list := foreign.ContactList.UniqueId.String()
+
+	return map[string]isolationRequest{
+		"POST /api/contacts":                         {path: "/api/contacts", body: []map[string]interface{}{{"name": "Contact", "phone": "919999999999", "listsIds": []string{list}}}},
+		"POST /api/contacts/:id":                     {path: "/api/contacts/" + own.Contact.UniqueId.String(), body: map[string]interface{}{"lists": []string{list}}},
+		"POST /api/contacts/:id/merge":               {path: "/api/contacts/" + own.Contact.UniqueId.String() + "/merge", body: map[string]interface{}{"duplicateIds": []string{foreign.Contact.UniqueId.String()}}},
+		"POST /api/lists/operations":                 {path: "/api/lists/operations", body: map[string]interface{}{"operation": "Copy", "name": "Copy", "sourceListIds": []string{list}}},
+		"POST /api/contact-capture-sources":          {path: "/api/contact-capture-sources", body: map[string]interface{}{"name": "Form", "type": "Form", "listId": list}},
+		"POST /api/campaigns":                        {path: "/api/campaigns", body: map[string]interface{}{"name": "Campaign", "tags": []string{}, "listIds": []string{list}}},
+		"POST /api/campaigns/:id":                    {path: "/api/campaigns/" + own.Campaign.UniqueId.String(), body: map[string]interface{}{"name": "Campaign", "tags": []string{}, "listIds": []string{list}}},
+		"GET /api/conversation/:id/assign":           {path: "/api/conversation/" + own.Conversation.UniqueId.String() + "/assign", body: map[string]interface{}{"organizationMemberId": foreign.Member.UniqueId.String()}},
+		"DELETE /api/conversation/:id/notes/:noteId": {path: "/api/conversation/" + own.Conversation.UniqueId.String() + "/notes/" + foreign.Note.UniqueId.String()},
+		"POST /api/canned-responses/:id/render":      {path: "/api/canned-responses/" + own.CannedResponse.UniqueId.String() + "/render", body: map[string]interface{}{"conversationId": foreign.Conversation.UniqueId.String()}},
+		"POST /api/organization/:id/transfer":        {path: "/api/organization/" + own.Organization.Organization.UniqueId.String() + "/transfer", body: map[string]interface{}{"newOwnerId": foreign.Owner.UniqueId.String()}},
+		"POST /api/organization/members/:id/role":    {path: "/api/organization/members/" + own.Member.UniqueId.String() + "/role", body: map[string]interface{}{"updatedRoleIds": []string{foreign.Role.UniqueId.String()}}},
+		"POST /api/auth/switch":                      {path: "/api/auth/switch", body: map[string]interface{}{"organizationId": foreign.Organization.Organization.UniqueId.String()}},
+	}
+}
+
+func TestRoutesDoNotServeTheRowsOfOtherOrganizations(t *testing.T) {
+	app := testutil.NewApp(t)
+	controllers := newApiControllers(app)
+	server := testutil.NewServer(app, controllers...)
+
+	own := seedTenantResources(t, app)
+	foreign := seedTenantResources(t, app)
+
+	// * a request of the own organization succeeds, so that the not found responses below are not those of a broken session
+	response := sendRequest(server, own.Token, http.MethodGet, "/api/contacts/"+own.Contact.UniqueId.String(), nil)
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected the contact of the own organization, got %d %s", response.Code, response.Body.String())
+	}
+
+	pathRequests := foreignIdRequests(own, foreign)
+	bodyRequests := foreignBodyIdRequests(own, foreign)
+	usedRequests := make(map[string]bool)
+	registeredRoutes := make(map[string]bool)
+
+	for _, apiController := range controllers {
+		routeProvider, ok := apiController.(interface{ GetRoutes() []interfaces.Route })
+		if !ok {
+			t.Fatalf("the controller %T does not list its routes", apiController)
+		}
+
+		for _, route := range routeProvider.GetRoutes() {
+			key := route.Method + " " + route.Path
+			if registeredRoutes[key] {
+				t.Errorf("the route %s is registered more than once", key)
+			}
+			registeredRoutes[key] = true
+
+			if _, ok := skippedRoutes[key]; ok {
+				continue
+			}
+
+			request, ok := pathRequests[key]
+			if !ok {
+				if strings.ContainsAny(route.Path, ":*") {
+					t.Errorf("the route %s takes an id but has no request with the id of another organization", key)
+				}
+			} else {
+				usedRequests["path "+key] = true
+				expectNotFound(t, server, own.Token, route.Method, "path id of "+key, request)
+			}
+
+			if request, ok := bodyRequests[key]; ok {
+				usedRequests["body "+key] = true
+				expectNotFound(t, server, own.Token, route.Method, "body id of "+key, request)
+			}
+		}
+	}
+
+	for key := range pathRequests {
+		if !usedRequests["path "+key] {
+			t.Errorf("the request for %s does not match a registered route", key)
+		}
+	}
+
+	for key := range bodyRequests {
+		if !usedRequests["body "+key] {
+			t.Errorf("the request for %s does not match a registered route", key)
+		}
+	}
+}
+
+func expectNotFound(t *testing.T, server *echo.Echo, token, method, name string, request isolationRequest) {
+	t.Run(name, func(t *testing.T) {
+		response := sendRequest(server, token, method, request.path, request.body)
+		if response.Code != http.StatusNotFound {
+			t.Errorf("expected %s %s to be not found, got %d %s", method, request.path, response.Code, response.Body.String())
+		}
+	})
+}
+
+func sendRequest(server *echo.Echo, token, method, path string, body interface{}) *httptest.ResponseRecorder {
+	if body == nil {
+		body = map[string]interface{}{}
+	}
+
+	var requestBody bytes.Buffer
+	json.NewEncoder(&requestBody).Encode(body)
+
+	request := httptest.NewRequest(method, path+"?page=1&per_page=10", &requestBody)
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set("x-access-token", token)
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// seedTenantResources seeds an organization with one row of every kind
+func seedTenantResources(t *testing.T, app *interfaces.App) tenantResources {
+	t.Helper()
+
+	resources := tenantResources{Organization: testutil.SeedOrganization(t, app)}
+	organizationId := resources.Organization.Organization.UniqueId
+	memberId := resources.Member.UniqueId
+	suffix := uuid.NewString()[:8]
+	now := time.Now()
+
+	insert := func(statement InsertStatement, dest interface{}) {
+		t.Helper()
+		if err := statement.QueryContext(context.Background(), app.Db, dest); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	insert(table.Contact.INSERT(table.Contact.MutableColumns).
+		MODEL(model.Contact{
+			OrganizationId: organizationId,
+			Status:         model.ContactStatusEnum_Active,
+			Name:           "Contact " + suffix,
+			PhoneNumber:    "91" + suffix,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}).
+		RETURNING(table.Contact.AllColumns), &resources.Contact)
+
+	insert(table.ContactList.INSERT(table.ContactList.MutableColumns).
+		MODEL(model.ContactList{
+			OrganizationId: organizationId,
+			Name:           "List " + suffix,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}).
+		RETURNING(table.ContactList.AllColumns), &resources.ContactList)
+
+	insert(table.Tag.INSERT(table.Tag.MutableColumns).
+		MODEL(model.Tag{
+			OrganizationId: organizationId,
+			Label:          "Tag " + suffix,
+			Slug:           "tag-" + suffix,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}).
+		RETURNING(table.Tag.AllColumns), &resources.Tag)
+
+	insert(table.Campaign.INSERT(table.Campaign.MutableColumns).
+		MODEL(model.Campaign{
+			OrganizationId:                organizationId,
+			Name:                          "Campaign " + suffix,
+			Status:                        model.CampaignStatusEnum_Draft,
+			CreatedByOrganizationMemberId: memberId,
+			PhoneNumber:                   "1234567890",
+			CreatedAt:                     now,
+			UpdatedAt:                     now,
+		}).
+		RETURNING(table.Campaign.AllColumns), &resources.Campaign)
+
+	insert(table.Conversation.INSERT(table.Conversation.MutableColumns).
+		MODEL(model.Conversation{
+			OrganizationId:  organizationId,
+			ContactId:       resources.Contact.UniqueId,
+			Status:          model.ConversationStatusEnum_Active,
+			PhoneNumberUsed: "1234567890",
+			InitiatedBy:     model.ConversationInitiatedEnum_Contact,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}).
+		RETURNING(table.Conversation.AllColumns), &resources.Conversation)
+
+	insert(table.ConversationNote.INSERT(table.ConversationNote.MutableColumns).
+		MODEL(model.ConversationNote{
+			OrganizationId:             organizationId,
+			ConversationId:             resources.Conversation.UniqueId,
+			AuthorOrganizationMemberId: &memberId,
+			Content:                    "Note " + suffix,
+			CreatedAt:                  now,
+			UpdatedAt:                  now,
+		}).
+		RETURNING(table.ConversationNote.AllColumns), &resources.Note)
+
+	insert(table.AiChat.INSERT(table.AiChat.MutableColumns).
+		MODEL(model.AiChat{
+			OrganizationId:       organizationId,
+			OrganizationMemberId: memberId,
+			Title:                "Chat " + suffix,
+			Status:               model.AiChatStatusEnum_Active,
+			Visibility:           model.AiChatVisibilityEnum_Private,
+			CreatedAt:            now,
+			UpdatedAt:            now,
+		}).
+		RETURNING(table.AiChat.AllColumns), &resources.AiChat)
+
+	insert(table.AiChatMessage.INSERT(table.AiChatMessage.MutableColumns).
+		MODEL(model.AiChatMessage{
+			OrganizationId:       organizationId,
+			OrganizationMemberId: memberId,
+			AiChatId:             resources.AiChat.UniqueId,
+			Role:                 model.AiChatMessageRoleEnum_User,
+			Content:              "Message " + suffix,
+			CreatedAt:            now,
+			UpdatedAt:            now,
+		}).
+		RETURNING(table.AiChatMessage.AllColumns), &resources.AiChatMessage)
+
+	insert(table.CannedResponse.INSERT(table.CannedResponse.MutableColumns).
+		MODEL(model.CannedResponse{
+			OrganizationId: organizationId,
+			Name:           "Canned response " + suffix,
+			Shortcode:      "canned-" + suffix,
+			Content:        "Hello",
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}).
+		RETURNING(table.CannedResponse.AllColumns), &resources.CannedResponse)
+
+	insert(table.ContactCaptureSource.INSERT(table.ContactCaptureSource.MutableColumns).
+		MODEL(model.ContactCaptureSource{
+			OrganizationId: organizationId,
+			ContactListId:  resources.ContactList.UniqueId,
+			Type:           model.ContactCaptureSourceTypeEnum_Form,
+			Name:           "Form " + suffix,
+			Code:           "form-" + suffix,
+			IsActive:       true,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}).
+		RETURNING(table.ContactCaptureSource.AllColumns), &resources.CaptureSource)
+
+	insert(table.ContactField.INSERT(table.ContactField.MutableColumns).
+		MODEL(model.ContactField{
+			OrganizationId: organizationId,
+			Key:            "field_" + strings.ReplaceAll(suffix, "-", ""),
+			Label:          "Field " + suffix,
+			Type:           model.ContactFieldTypeEnum_String,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}).
+		RETURNING(table.ContactField.AllColumns), &resources.ContactField)
+
+	insert(table.BackgroundJob.INSERT(table.BackgroundJob.MutableColumns).
+		MODEL(model.BackgroundJob{
+			OrganizationId:                organizationId,
+			CreatedByOrganizationMemberId: &memberId,
+			Type:                          model.BackgroundJobTypeEnum_ContactImport,
+			Status:                        model.BackgroundJobStatusEnum_Completed,
+			Parameters:                    "{}",
+			CreatedAt:                     now,
+			UpdatedAt:                     now,
+		}).
+		RETURNING(table.BackgroundJob.AllColumns), &resources.BackgroundJob)
+
+	insert(table.OrganizationRole.INSERT(table.OrganizationRole.MutableColumns).
+		MODEL(model.OrganizationRole{
+			OrganizationId: organizationId,
+			Name:           "Role " + suffix,
+			Permissions:    "",
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}).
+		RETURNING(table.OrganizationRole.AllColumns), &resources.Role)
+
+	insert(table.ConversationRoutingRule.INSERT(table.ConversationRoutingRule.MutableColumns).
+		MODEL(model.ConversationRoutingRule{
+			OrganizationId: organizationId,
+			Name:           "Rule " + suffix,
+			Strategy:       model.ConversationRoutingStrategyEnum_RoundRobin,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}).
+		RETURNING(table.ConversationRoutingRule.AllColumns), &resources.RoutingRule)
+
+	insert(table.Segment.INSERT(table.Segment.MutableColumns).
+		MODEL(model.Segment{
+			OrganizationId: organizationId,
+			Name:           "Segment " + suffix,
+			Rules:          "{}",
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}).
+		RETURNING(table.Segment.AllColumns), &resources.Segment)
+
+	insert(table.SlaPolicy.INSERT(table.SlaPolicy.MutableColumns).
+		MODEL(model.SlaPolicy{
+			OrganizationId:             organizationId,
+			Name:                       "Policy " + suffix,
+			FirstResponseTimeInMinutes: 30,
+			Timezone:                   "UTC",
+			CreatedAt:                  now,
+			UpdatedAt:                  now,
+		}).
+		RETURNING(table.SlaPolicy.AllColumns), &resources.SlaPolicy)
+
+	return resources
+}
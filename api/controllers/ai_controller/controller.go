@@ -10,6 +10,7 @@ import (
 	"github.com/wapikit/wapikit/.db-generated/model"
 	controller "github.com/wapikit/wapikit/api/controllers"
 	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
 	"github.com/wapikit/wapikit/internal/core/utils"
 	"github.com/wapikit/wapikit/internal/interfaces"
 
@@ -219,29 +220,10 @@ func handleGetChats(context interfaces.ContextWithSession) error {
 }
 
 func handleGetChatById(context interfaces.ContextWithSession) error {
-	chatId := context.Param("id")
-	if chatId == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid Chat Id")
-	}
-
-	chatUuid, _ := uuid.Parse(chatId)
 	var dest model.AiChat
-	fetchChatQuery := SELECT(
-		table.AiChat.AllColumns,
-	).FROM(
-		table.AiChat,
-	).WHERE(
-		table.AiChat.UniqueId.EQ(UUID(chatUuid)),
-	).LIMIT(1)
-
-	err := fetchChatQuery.Query(context.App.Db, &dest)
 
-	if err != nil {
-		if err.Error() == qrm.ErrNoRows.Error() {
-			return echo.NewHTTPError(http.StatusNotFound, "Chat not found")
-		} else {
-			return echo.NewHTTPError(http.StatusInternalServerError, "Error fetching chat")
-		}
+	if _, err := controller.FetchOwned(context, tenant_service.AiChat, &dest); err != nil {
+		return err
 	}
 
 	responseToReturn := api_types.GetAiChatByIdResponseSchema{
@@ -257,13 +239,11 @@ func handleGetChatById(context interfaces.ContextWithSession) error {
 }
 
 func handleGetChatMessages(context interfaces.ContextWithSession) error {
-	chatId := context.Param("id")
-	if chatId == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid Chat Id")
-	}
-	chatUuid, err := uuid.Parse(chatId)
+	var chat model.AiChat
+
+	chatUuid, err := controller.FetchOwned(context, tenant_service.AiChat, &chat)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Invalid chat Id")
+		return err
 	}
 
 	params := new(api_types.GetAiChatMessageVotesParams)
@@ -331,6 +311,11 @@ func voteMessage(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Invalid chat Id")
 	}
 
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
+
 	payload := new(api_types.AiChatMessageVoteCreateSchema)
 	if err := context.Bind(payload); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
@@ -354,7 +339,7 @@ func voteMessage(context interfaces.ContextWithSession) error {
 			table.AiChat.UniqueId.EQ(table.AiChatMessage.AiChatId),
 		),
 	).WHERE(
-		table.AiChat.UniqueId.EQ(UUID(chatUuid)).AND(
+		tenant_service.AiChat.ById(orgUuid, chatUuid).AND(
 			table.AiChatMessage.UniqueId.EQ(UUID(messageUuid)),
 		),
 	).LIMIT(1)
@@ -373,25 +358,22 @@ func voteMessage(context interfaces.ContextWithSession) error {
 }
 
 func getMessageVotes(context interfaces.ContextWithSession) error {
-	params := new(api_types.GetAiChatMessageVotesParams)
+	var chat model.AiChat
 
-	err := utils.BindQueryParams(context, params)
+	chatUuid, err := controller.FetchOwned(context, tenant_service.AiChat, &chat)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	pageNumber := params.Page
-	pageSize := params.PerPage
+	params := new(api_types.GetAiChatMessageVotesParams)
 
-	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+	err = utils.BindQueryParams(context, params)
 	if err != nil {
-		return context.String(http.StatusInternalServerError, "Error parsing organization UUID")
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
-	userUuid, err := uuid.Parse(context.Session.User.UniqueId)
 
-	if err != nil {
-		return context.String(http.StatusInternalServerError, "Error parsing user UUID")
-	}
+	pageNumber := params.Page
+	pageSize := params.PerPage
 
 	votesQuery := SELECT(
 		table.AiChatMessageVote.AllColumns,
@@ -407,8 +389,8 @@ func getMessageVotes(context interfaces.ContextWithSession) error {
 			table.AiChatMessage.OrganizationMemberId.EQ(table.OrganizationMember.UniqueId),
 		),
 	).WHERE(
-		table.AiChatMessage.OrganizationId.EQ(UUID(orgUuid)).AND(
-			table.AiChatMessage.OrganizationMemberId.EQ(UUID(userUuid)),
+		table.AiChatMessage.OrganizationId.EQ(UUID(chat.OrganizationId)).AND(
+			table.AiChatMessage.AiChatId.EQ(UUID(chatUuid)),
 		),
 	)
 
@@ -477,12 +459,17 @@ func handleReplyToChat(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Invalid chat Id")
 	}
 
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
+
 	userUuid, err := uuid.Parse(context.Session.User.UniqueId)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Invalid user id found")
 	}
 
-	logger.Info("User UUID: %v", userUuid, nil)
+	logger.Info("user uuid", "userUuid", userUuid.String())
 
 	// * get the chat from the db
 	var dest struct {
@@ -509,7 +496,7 @@ func handleReplyToChat(context interfaces.ContextWithSession) error {
 			table.AiChat.UniqueId.EQ(table.AiChatMessage.AiChatId),
 		),
 	).WHERE(
-		table.AiChat.UniqueId.EQ(UUID(chatUuid)),
+		tenant_service.AiChat.ById(orgUuid, chatUuid),
 	).ORDER_BY(
 		table.AiChatMessage.CreatedAt.ASC(),
 	).
@@ -3,6 +3,7 @@ package analytics_controller
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -10,6 +11,7 @@ import (
 	"github.com/wapikit/wapikit/.db-generated/table"
 	controller "github.com/wapikit/wapikit/api/controllers"
 	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
 	"github.com/wapikit/wapikit/internal/core/utils"
 	"github.com/wapikit/wapikit/internal/interfaces"
 
@@ -51,6 +53,18 @@ func NewAnalyticsController() *AnalyticsController {
 						},
 					},
 				},
+				{
+					Path:                    "/api/analytics/csat",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(handleGetCsatAnalytics),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetSecondaryAnalytics,
+						},
+					},
+				},
 				{
 					Path:                    "/api/analytics/campaign/:campaignId",
 					Method:                  http.MethodGet,
@@ -405,15 +419,263 @@ func handlePrimaryAnalyticsDashboardData(context interfaces.ContextWithSession)
 func handleSecondaryAnalyticsDashboardData(context interfaces.ContextWithSession) error {
 	// ! TODO: these analytics we will need once the live team inbox will be implemented
 
+	params := new(api_types.GetSecondaryAnalyticsParams)
+	err := utils.BindQueryParams(context, params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+
+	if err != nil {
+		return context.JSON(http.StatusInternalServerError, "Invalid organization id")
+	}
+
+	whereCondition := table.Conversation.OrganizationId.EQ(UUID(orgUuid)).
+		AND(table.Conversation.SlaPolicyId.IS_NOT_NULL())
+
+	if params.From != nil && !params.From.IsZero() {
+		whereCondition = whereCondition.AND(table.Conversation.CreatedAt.GT_EQ(TimestampzT(*params.From)))
+	}
+
+	if params.To != nil && !params.To.IsZero() {
+		whereCondition = whereCondition.AND(table.Conversation.CreatedAt.LT_EQ(TimestampzT(*params.To)))
+	}
+
+	var slaAnalytics api_types.SlaAnalyticsSchema
+
+	// * a timer stopped after its deadline is a breach even if the worker did not get to flag it
+	slaAnalyticsQuery := SELECT(
+		COUNT(table.Conversation.UniqueId).AS("conversationsWithSla"),
+		COALESCE(
+			SUM(CASE().WHEN(table.Conversation.FirstRespondedAt.LT_EQ(table.Conversation.FirstResponseDueAt).AND(table.Conversation.FirstResponseBreachedAt.IS_NULL())).
+				THEN(CAST(Int(1)).AS_INTEGER()).
+				ELSE(CAST(Int(0)).AS_INTEGER())), CAST(Int(0)).AS_INTEGER()).AS("firstResponsesMet"),
+		COALESCE(
+			SUM(CASE().WHEN(table.Conversation.FirstResponseBreachedAt.IS_NOT_NULL().OR(table.Conversation.FirstRespondedAt.GT(table.Conversation.FirstResponseDueAt))).
+				THEN(CAST(Int(1)).AS_INTEGER()).
+				ELSE(CAST(Int(0)).AS_INTEGER())), CAST(Int(0)).AS_INTEGER()).AS("firstResponseBreaches"),
+		COALESCE(
+			SUM(CASE().WHEN(table.Conversation.ResolvedAt.LT_EQ(table.Conversation.ResolutionDueAt).AND(table.Conversation.ResolutionBreachedAt.IS_NULL())).
+				THEN(CAST(Int(1)).AS_INTEGER()).
+				ELSE(CAST(Int(0)).AS_INTEGER())), CAST(Int(0)).AS_INTEGER()).AS("resolutionsMet"),
+		COALESCE(
+			SUM(CASE().WHEN(table.Conversation.ResolutionBreachedAt.IS_NOT_NULL().OR(table.Conversation.ResolvedAt.GT(table.Conversation.ResolutionDueAt))).
+				THEN(CAST(Int(1)).AS_INTEGER()).
+				ELSE(CAST(Int(0)).AS_INTEGER())), CAST(Int(0)).AS_INTEGER()).AS("resolutionBreaches"),
+		Raw(`COALESCE(AVG(EXTRACT(EPOCH FROM ("Conversation"."FirstRespondedAt" - "Conversation"."CreatedAt")) / 60), 0)::real`).AS("averageFirstResponseTimeInMinutes"),
+	).FROM(table.Conversation).
+		WHERE(whereCondition)
+
+	err = slaAnalyticsQuery.QueryContext(context.Request().Context(), context.App.Db, &slaAnalytics)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	responseToReturn := api_types.SecondaryAnalyticsDashboardResponseSchema{
 		ConversationsAnalytics:                  []api_types.ConversationAnalyticsDataPointSchema{},
 		MessageTypeTrafficDistributionAnalytics: []api_types.MessageTypeDistributionGraphDataPointSchema{},
+		SlaAnalytics:                            slaAnalytics,
+	}
+
+	return context.JSON(http.StatusOK, responseToReturn)
+}
+
+// csatStatsRow is a group of surveys, only the column the surveys are grouped by is set
+type csatStatsRow struct {
+	MemberId           *uuid.UUID
+	MemberName         *string
+	PhoneNumberId      *string
+	PeriodStart        *time.Time
+	SurveysSent        int
+	Responses          int
+	SatisfiedResponses int
+	AverageRating      float64
+}
+
+var csatPeriodUnits = map[api_types.AnalyticsPeriodEnum]string{
+	api_types.Day:   "day",
+	api_types.Week:  "week",
+	api_types.Month: "month",
+}
+
+func handleGetCsatAnalytics(context interfaces.ContextWithSession) error {
+	params := new(api_types.GetCsatAnalyticsParams)
+	err := utils.BindQueryParams(context, params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	period := api_types.Day
+	if params.Period != nil {
+		period = *params.Period
+	}
+
+	periodUnit, ok := csatPeriodUnits[period]
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid period")
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+
+	if err != nil {
+		return context.JSON(http.StatusInternalServerError, "Invalid organization id")
+	}
+
+	whereCondition := table.CsatSurvey.OrganizationId.EQ(UUID(orgUuid))
+
+	if params.From != nil && !params.From.IsZero() {
+		whereCondition = whereCondition.AND(table.CsatSurvey.CreatedAt.GT_EQ(TimestampzT(*params.From)))
+	}
+
+	if params.To != nil && !params.To.IsZero() {
+		whereCondition = whereCondition.AND(table.CsatSurvey.CreatedAt.LT_EQ(TimestampzT(*params.To)))
+	}
+
+	// * ratings of 4 and 5 count as satisfied
+	statsProjections := ProjectionList{
+		COUNT(table.CsatSurvey.UniqueId).AS("surveysSent"),
+		COUNT(table.CsatSurvey.Rating).AS("responses"),
+		COALESCE(
+			SUM(CASE().WHEN(table.CsatSurvey.Rating.GT_EQ(Int(4))).
+				THEN(CAST(Int(1)).AS_INTEGER()).
+				ELSE(CAST(Int(0)).AS_INTEGER())), CAST(Int(0)).AS_INTEGER()).AS("satisfiedResponses"),
+		Raw(`COALESCE(AVG("CsatSurvey"."Rating"), 0)::real`).AS("averageRating"),
+	}
+
+	// * the unit is one of the fixed units above, it is part of the sql so that the select and the group by are the same expression
+	periodStart := Raw(fmt.Sprintf(`date_trunc('%s', "CsatSurvey"."CreatedAt")`, periodUnit))
+
+	var overall csatStatsRow
+
+	err = SELECT(statsProjections).
+		FROM(table.CsatSurvey).
+		WHERE(whereCondition).
+		QueryContext(context.Request().Context(), context.App.Db, &overall)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var memberRows []csatStatsRow
+
+	err = SELECT(
+		table.CsatSurvey.OrganizationMemberId.AS("memberId"),
+		table.User.Name.AS("memberName"),
+		statsProjections,
+	).FROM(table.CsatSurvey.
+		LEFT_JOIN(table.OrganizationMember, table.OrganizationMember.UniqueId.EQ(table.CsatSurvey.OrganizationMemberId)).
+		LEFT_JOIN(table.User, table.User.UniqueId.EQ(table.OrganizationMember.UserId)),
+	).
+		WHERE(whereCondition).
+		GROUP_BY(table.CsatSurvey.OrganizationMemberId, table.User.Name).
+		ORDER_BY(table.User.Name.ASC()).
+		QueryContext(context.Request().Context(), context.App.Db, &memberRows)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var phoneNumberRows []csatStatsRow
+
+	err = SELECT(
+		table.CsatSurvey.PhoneNumberUsed.AS("phoneNumberId"),
+		statsProjections,
+	).FROM(table.CsatSurvey).
+		WHERE(whereCondition).
+		GROUP_BY(table.CsatSurvey.PhoneNumberUsed).
+		ORDER_BY(table.CsatSurvey.PhoneNumberUsed.ASC()).
+		QueryContext(context.Request().Context(), context.App.Db, &phoneNumberRows)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var periodRows []csatStatsRow
+
+	err = SELECT(
+		periodStart.AS("periodStart"),
+		statsProjections,
+	).FROM(table.CsatSurvey).
+		WHERE(whereCondition).
+		GROUP_BY(periodStart).
+		ORDER_BY(periodStart.ASC()).
+		QueryContext(context.Request().Context(), context.App.Db, &periodRows)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	responseToReturn := api_types.CsatAnalyticsResponseSchema{
+		Overall:       toCsatStatsSchema(overall),
+		ByMember:      []api_types.CsatMemberStatsSchema{},
+		ByPhoneNumber: []api_types.CsatPhoneNumberStatsSchema{},
+		ByPeriod:      []api_types.CsatPeriodStatsSchema{},
+	}
+
+	for _, row := range memberRows {
+		memberStats := api_types.CsatMemberStatsSchema{
+			MemberName: row.MemberName,
+			Stats:      toCsatStatsSchema(row),
+		}
+
+		if row.MemberId != nil {
+			memberId := row.MemberId.String()
+			memberStats.MemberId = &memberId
+		}
+
+		responseToReturn.ByMember = append(responseToReturn.ByMember, memberStats)
+	}
+
+	for _, row := range phoneNumberRows {
+		if row.PhoneNumberId == nil {
+			continue
+		}
+
+		responseToReturn.ByPhoneNumber = append(responseToReturn.ByPhoneNumber, api_types.CsatPhoneNumberStatsSchema{
+			PhoneNumberId: *row.PhoneNumberId,
+			Stats:         toCsatStatsSchema(row),
+		})
+	}
+
+	for _, row := range periodRows {
+		if row.PeriodStart == nil {
+			continue
+		}
+
+		responseToReturn.ByPeriod = append(responseToReturn.ByPeriod, api_types.CsatPeriodStatsSchema{
+			PeriodStart: *row.PeriodStart,
+			Stats:       toCsatStatsSchema(row),
+		})
 	}
 
 	return context.JSON(http.StatusOK, responseToReturn)
 }
 
+func toCsatStatsSchema(row csatStatsRow) api_types.CsatStatsSchema {
+	stats := api_types.CsatStatsSchema{
+		SurveysSent:        row.SurveysSent,
+		Responses:          row.Responses,
+		SatisfiedResponses: row.SatisfiedResponses,
+		AverageRating:      float32(row.AverageRating),
+	}
+
+	if row.Responses > 0 {
+		stats.CsatScore = float32(row.SatisfiedResponses) * 100 / float32(row.Responses)
+	}
+
+	return stats
+}
+
 func handleGetCampaignAnalyticsById(context interfaces.ContextWithSession) error {
+	var campaign model.Campaign
+
+	campaignUuid, err := controller.FetchOwnedOfParam(context, "campaignId", tenant_service.Campaign, &campaign)
+	if err != nil {
+		return err
+	}
+
 	var campaignAnalyticsData struct {
 		MessagesDelivered     int                                        `json:"messagesDelivered"`
 		MessagesFailed        int                                        `json:"messagesFailed"`
@@ -459,7 +721,7 @@ func handleGetCampaignAnalyticsById(context interfaces.ContextWithSession) error
 			).FROM(
 				table.Message,
 			).WHERE(
-				table.Message.CampaignId.EQ(UUID(uuid.MustParse(context.Param("campaignId")))),
+				table.Message.CampaignId.EQ(UUID(campaignUuid)),
 			),
 		),
 		linkClicksCountsCte.AS(
@@ -469,7 +731,7 @@ func handleGetCampaignAnalyticsById(context interfaces.ContextWithSession) error
 			).FROM(
 				table.TrackLinkClick.LEFT_JOIN(table.TrackLink, table.TrackLink.UniqueId.EQ(table.TrackLinkClick.TrackLinkId)),
 			).WHERE(
-				table.TrackLink.CampaignId.EQ(UUID(uuid.MustParse(context.Param("campaignId")))),
+				table.TrackLink.CampaignId.EQ(UUID(campaignUuid)),
 			),
 		),
 		conversationCountCte.AS(
@@ -480,7 +742,7 @@ func handleGetCampaignAnalyticsById(context interfaces.ContextWithSession) error
 				table.Conversation,
 			).WHERE(
 				table.Conversation.InitiatedByCampaignId.EQ(
-					UUID(uuid.MustParse(context.Param("campaignId"))),
+					UUID(campaignUuid),
 				).AND(
 					table.Conversation.InitiatedBy.EQ(utils.EnumExpression(model.ConversationInitiatedEnum_Campaign.String())),
 				),
@@ -494,7 +756,7 @@ func handleGetCampaignAnalyticsById(context interfaces.ContextWithSession) error
 				table.TrackLinkClick.
 					LEFT_JOIN(table.TrackLink, table.TrackLink.UniqueId.EQ(table.TrackLinkClick.TrackLinkId)),
 			).WHERE(
-				table.TrackLink.CampaignId.EQ(UUID(uuid.MustParse(context.Param("campaignId")))),
+				table.TrackLink.CampaignId.EQ(UUID(campaignUuid)),
 			).GROUP_BY(
 				table.TrackLinkClick.CreatedAt,
 			).ORDER_BY(
@@ -517,7 +779,7 @@ func handleGetCampaignAnalyticsById(context interfaces.ContextWithSession) error
 
 	fmt.Println("SQL", sql)
 
-	err := campaignAnalyticsQuery.QueryContext(context.Request().Context(), context.App.Db, &campaignAnalyticsData)
+	err = campaignAnalyticsQuery.QueryContext(context.Request().Context(), context.App.Db, &campaignAnalyticsData)
 
 	if err != nil {
 		fmt.Println("error is", err.Error())
@@ -1,18 +1,27 @@
 package auth_controller
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	gonanoid "github.com/matoous/go-nanoid/v2"
 	controller "github.com/wapikit/wapikit/api/controllers"
 	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/oauth_service"
+	cache "github.com/wapikit/wapikit/internal/core/redis"
+	"github.com/wapikit/wapikit/internal/core/session_service"
+	"github.com/wapikit/wapikit/internal/core/two_factor_service"
 	"github.com/wapikit/wapikit/internal/core/utils"
 	"github.com/wapikit/wapikit/internal/interfaces"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/oauth2"
 
 	"github.com/go-jet/jet/qrm"
 	. "github.com/go-jet/jet/v2/postgres"
@@ -86,6 +95,79 @@ func NewAuthController() *AuthController {
 					Handler:                 interfaces.HandlerWithoutSession(handleLoginWithOAuth),
 					IsAuthorizationRequired: false,
 				},
+				{
+					Path:                    "/api/auth/two-factor/verify",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithoutSession(handleVerifyTwoFactorLogin),
+					IsAuthorizationRequired: false,
+					MetaData: interfaces.RouteMetaData{
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+					},
+				},
+				{
+					Path:                    "/api/auth/oauth/providers",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithoutSession(getOAuthProviders),
+					IsAuthorizationRequired: false,
+				},
+				{
+					Path:                    "/api/auth/oauth/:provider",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithoutSession(getOAuthAuthorizationUrl),
+					IsAuthorizationRequired: false,
+				},
+				{
+					Path:                    "/api/auth/refresh",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithoutSession(refreshAccessToken),
+					IsAuthorizationRequired: false,
+				},
+				{
+					Path:                    "/api/auth/logout",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(handleLogout),
+					IsAuthorizationRequired: true,
+				},
+				{
+					Path:                    "/api/auth/logout-all",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(handleLogoutFromAllSessions),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60 * 60, // 1 hour
+						},
+					},
+				},
+				{
+					Path:                    "/api/auth/forgot-password",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithoutSession(handleForgotPassword),
+					IsAuthorizationRequired: false,
+					MetaData: interfaces.RouteMetaData{
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    5,
+							WindowTimeInMs: 1000 * 60 * 60, // 1 hour
+						},
+					},
+				},
+				{
+					Path:                    "/api/auth/reset-password",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithoutSession(handleResetPassword),
+					IsAuthorizationRequired: false,
+					MetaData: interfaces.RouteMetaData{
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    5,
+							WindowTimeInMs: 1000 * 60 * 60, // 1 hour
+						},
+					},
+				},
 				{
 					Path:                    "/api/auth/switch",
 					Method:                  http.MethodPost,
@@ -178,34 +260,27 @@ func acceptOrganizationInvite(context interfaces.ContextWithSession) error {
 		AccessLevel:    invite.AccessLevel,
 		OrganizationId: invite.OrganizationId,
 		UserId:         userUuid,
+		IsAvailable:    true,
 		InviteId:       &invite.UniqueId,
 	}).QueryContext(context.Request().Context(), context.App.Db, &insertedOrgMember)
 
 	if err != nil {
-		context.App.Logger.Error("database query error", err.Error())
+		context.App.Logger.Error("database query error", "error", err.Error())
 		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
 	}
 
-	// create the token
-
-	claims := &interfaces.JwtPayload{
-		ContextUser: interfaces.ContextUser{
-			Username:       context.Session.User.Username,
-			Email:          context.Session.User.Email,
-			Role:           api_types.UserPermissionLevelEnum(invite.AccessLevel),
-			UniqueId:       context.Session.User.UniqueId,
-			OrganizationId: invite.OrganizationId.String(),
-			Name:           context.Session.User.Name,
-		},
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Hour * 24 * 60).Unix(), // 60-day expiration
-			Issuer:    "wapikit",
-		},
-	}
+	// create the token, the current session now continues with the joined organization
+	token, err := _switchSessionOrganization(context, interfaces.ContextUser{
+		Username:       context.Session.User.Username,
+		Email:          context.Session.User.Email,
+		Role:           api_types.UserPermissionLevelEnum(invite.AccessLevel),
+		UniqueId:       context.Session.User.UniqueId,
+		OrganizationId: invite.OrganizationId.String(),
+		Name:           context.Session.User.Name,
+	})
 
-	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(context.App.Koa.String("app.jwt_secret")))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Error generating token")
+		return err
 	}
 	response := api_types.JoinOrganizationResponseBodySchema{
 		Token: token,
@@ -263,81 +338,565 @@ func handleSignIn(context interfaces.ContextWithoutSession) error {
 		return echo.NewHTTPError(http.StatusNotFound, "Invalid email / password")
 	}
 
-	var isOnboardingCompleted bool
-	var organizationIdToLoginWith string
-	var roleToLoginWith api_types.UserPermissionLevelEnum
-	var claims *interfaces.JwtPayload
-
-	// if no organization found, then simply return the user with a flag saying isOnboardingCompleted
-	if len(user.Organizations) == 0 {
-		isOnboardingCompleted = false
-		// onboarding to be completed by the user
-		// return the user with onboarding flag
-
-		claims = &interfaces.JwtPayload{
-			ContextUser: interfaces.ContextUser{
-				Username:       user.User.Username,
-				Email:          user.User.Email,
-				UniqueId:       user.User.UniqueId.String(),
-				Name:           user.User.Name,
-				OrganizationId: "",
-			},
-			StandardClaims: jwt.StandardClaims{
-				ExpiresAt: time.Now().Add(time.Hour * 24 * 60).Unix(), // 60-day expiration
-				Issuer:    "wapikit",
-			},
+	if two_factor_service.IsEnabled(user.User) {
+		return _respondWithTwoFactorChallenge(context, user.User.UniqueId)
+	}
+
+	memberships := make([]model.OrganizationMember, 0, len(user.Organizations))
+	for _, org := range user.Organizations {
+		memberships = append(memberships, org.MemberDetails.OrganizationMember)
+	}
+
+	contextUser, isOnboardingCompleted := _buildLoginContextUser(user.User, memberships)
+
+	//Create the session and the tokens
+	token, refreshToken, err := _createSessionWithTokens(context.Context, context.App, contextUser)
+
+	if err != nil {
+		return err
+	}
+
+	return context.JSON(http.StatusOK, api_types.LoginResponseBodySchema{
+		IsOnboardingCompleted: isOnboardingCompleted,
+		Token:                 token,
+		RefreshToken:          refreshToken,
+	})
+}
+
+// _buildLoginContextUser picks the organization to login with, an organization owned by the user takes precedence over the first one joined
+func _buildLoginContextUser(user model.User, memberships []model.OrganizationMember) (interfaces.ContextUser, bool) {
+	contextUser := interfaces.ContextUser{
+		Username:       user.Username,
+		Email:          user.Email,
+		UniqueId:       user.UniqueId.String(),
+		Name:           user.Name,
+		OrganizationId: "",
+	}
+
+	// if no organization found, then the onboarding is yet to be completed by the user
+	if len(memberships) == 0 {
+		return contextUser, false
+	}
+
+	membershipToLoginWith := memberships[0]
+
+	for _, membership := range memberships {
+		if membership.AccessLevel == model.UserPermissionLevelEnum_Owner {
+			membershipToLoginWith = membership
+			break
+		}
+	}
+
+	contextUser.OrganizationId = membershipToLoginWith.OrganizationId.String()
+	contextUser.Role = api_types.UserPermissionLevelEnum(membershipToLoginWith.AccessLevel)
+
+	return contextUser, true
+}
+
+func _fetchUserMemberships(context interfaces.ContextWithoutSession, userId uuid.UUID) ([]model.OrganizationMember, error) {
+	var memberships []model.OrganizationMember
+
+	err := SELECT(table.OrganizationMember.AllColumns).
+		FROM(table.OrganizationMember).
+		WHERE(table.OrganizationMember.UserId.EQ(UUID(userId))).
+		ORDER_BY(table.OrganizationMember.CreatedAt.ASC()).
+		QueryContext(context.Request().Context(), context.App.Db, &memberships)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return memberships, nil
+}
+
+// _respondWithTwoFactorChallenge completes the first step of the login, the tokens are only issued once the code is verified
+func _respondWithTwoFactorChallenge(context interfaces.ContextWithoutSession, userId uuid.UUID) error {
+	redis := context.App.Redis
+
+	twoFactorToken, err := session_service.GenerateOpaqueToken()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	err = redis.CacheData(redis.ComputeCacheKey("two-factor", session_service.HashToken(twoFactorToken), "login"), userId.String(), two_factor_service.LoginChallengeTTL)
+
+	if err != nil {
+		context.App.Logger.Error("error caching two factor challenge", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	isTwoFactorRequired := true
+
+	return context.JSON(http.StatusOK, api_types.LoginResponseBodySchema{
+		IsTwoFactorRequired: &isTwoFactorRequired,
+		TwoFactorToken:      &twoFactorToken,
+	})
+}
+
+func handleVerifyTwoFactorLogin(context interfaces.ContextWithoutSession) error {
+	redis := context.App.Redis
+
+	payload := new(api_types.TwoFactorLoginRequestBodySchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if payload.TwoFactorToken == "" || payload.Code == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Two factor token and code are required")
+	}
+
+	tokenHash := session_service.HashToken(payload.TwoFactorToken)
+	challengeKey := redis.ComputeCacheKey("two-factor", tokenHash, "login")
+	attemptsKey := redis.ComputeCacheKey("two-factor", tokenHash, "attempts")
+
+	userId, err := redis.GetCachedData(challengeKey)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Login has expired, please login again")
+	}
+
+	userUuid, err := uuid.Parse(userId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Login has expired, please login again")
+	}
+
+	var user model.User
+
+	err = SELECT(table.User.AllColumns).
+		FROM(table.User).
+		WHERE(table.User.UniqueId.EQ(UUID(userUuid))).
+		LIMIT(1).
+		QueryContext(context.Request().Context(), context.App.Db, &user)
+
+	if err != nil || user.Status != model.UserAccountStatusEnum_Active {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized access")
+	}
+
+	isValid, err := two_factor_service.VerifyCode(context.Request().Context(), context.App.Db, redis, context.App.Secrets, user, payload.Code)
+
+	if err != nil {
+		if errors.Is(err, two_factor_service.ErrNotEnrolled) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized access")
+		}
+		context.App.Logger.Error("error verifying two factor code", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	if !isValid {
+		// * the challenge is dropped after too many wrong codes, so the password has to be entered again
+		attempts, _ := redis.Incr(context.Request().Context(), attemptsKey).Result()
+		redis.Expire(context.Request().Context(), attemptsKey, two_factor_service.LoginChallengeTTL)
+		if attempts >= two_factor_service.MaxLoginAttempts {
+			redis.Del(context.Request().Context(), challengeKey, attemptsKey)
+		}
+		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid code")
+	}
+
+	redis.Del(context.Request().Context(), challengeKey, attemptsKey)
+
+	memberships, err := _fetchUserMemberships(context, user.UniqueId)
+	if err != nil {
+		context.App.Logger.Error("database query error", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	contextUser, isOnboardingCompleted := _buildLoginContextUser(user, memberships)
+
+	token, refreshToken, err := _createSessionWithTokens(context.Context, context.App, contextUser)
+	if err != nil {
+		return err
+	}
+
+	return context.JSON(http.StatusOK, api_types.LoginResponseBodySchema{
+		IsOnboardingCompleted: isOnboardingCompleted,
+		Token:                 token,
+		RefreshToken:          refreshToken,
+	})
+}
+
+// oauthLoginState is stored against the state parameter between the redirect to the provider and the callback
+type oauthLoginState struct {
+	Provider     string  `json:"provider"`
+	Nonce        string  `json:"nonce"`
+	CodeVerifier string  `json:"codeVerifier"`
+	InviteSlug   *string `json:"inviteSlug,omitempty"`
+}
+
+const oauthLoginStateTTL = 10 * time.Minute
+
+func getOAuthProviders(context interfaces.ContextWithoutSession) error {
+	response := api_types.GetOAuthProvidersResponseSchema{
+		Providers: []api_types.OAuthProviderSchema{},
+	}
+
+	if context.App.OAuthProviders == nil {
+		return context.JSON(http.StatusOK, response)
+	}
+
+	for _, provider := range context.App.OAuthProviders.EnabledProviders() {
+		response.Providers = append(response.Providers, api_types.OAuthProviderSchema{
+			Name:        provider.Name,
+			DisplayName: provider.DisplayName,
+		})
+	}
+
+	return context.JSON(http.StatusOK, response)
+}
+
+func getOAuthAuthorizationUrl(context interfaces.ContextWithoutSession) error {
+	redis := context.App.Redis
+
+	params := new(api_types.GetOAuthAuthorizationUrlParams)
+	if err := utils.BindQueryParams(context, params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	providerName := context.Param("provider")
+
+	if context.App.OAuthProviders == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "OAuth provider not found")
+	}
+
+	provider, err := context.App.OAuthProviders.Get(context.Request().Context(), providerName)
+
+	if err != nil {
+		if errors.Is(err, oauth_service.ErrProviderNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "OAuth provider not found")
+		}
+		context.App.Logger.Error("error initializing oauth provider", "provider", providerName, "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	state, err := session_service.GenerateOpaqueToken()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	nonce, err := session_service.GenerateOpaqueToken()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	loginState := oauthLoginState{
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: oauth2.GenerateVerifier(),
+		InviteSlug:   params.InviteSlug,
+	}
+
+	loginStateJson, err := json.Marshal(loginState)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	err = redis.CacheData(redis.ComputeCacheKey("oauth", state, "state"), string(loginStateJson), oauthLoginStateTTL)
+
+	if err != nil {
+		context.App.Logger.Error("error caching oauth state", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetOAuthAuthorizationUrlResponseSchema{
+		AuthorizationUrl: provider.AuthCodeURL(state, loginState.Nonce, loginState.CodeVerifier),
+	})
+}
+
+func handleLoginWithOAuth(context interfaces.ContextWithoutSession) error {
+	redis := context.App.Redis
+
+	payload := new(api_types.OAuthLoginRequestBodySchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if payload.Provider == "" || payload.Code == "" || payload.State == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Provider, code and state are required")
+	}
+
+	// * the state is single use, taking it drops it so that concurrent callbacks can not both use it
+	cacheKey := redis.ComputeCacheKey("oauth", payload.State, "state")
+	cachedState, err := redis.TakeCachedData(cacheKey)
+	if err != nil {
+		if !cache.IsNotCached(err) {
+			context.App.Logger.Error("error taking oauth state", "error", err.Error())
+			return echo.NewHTTPError(http.StatusServiceUnavailable, "Service unavailable")
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid or expired oauth state")
+	}
+
+	var loginState oauthLoginState
+	if err := json.Unmarshal([]byte(cachedState), &loginState); err != nil || loginState.Provider != payload.Provider {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid or expired oauth state")
+	}
+
+	if context.App.OAuthProviders == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "OAuth provider not found")
+	}
+
+	provider, err := context.App.OAuthProviders.Get(context.Request().Context(), payload.Provider)
+	if err != nil {
+		if errors.Is(err, oauth_service.ErrProviderNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "OAuth provider not found")
+		}
+		context.App.Logger.Error("error initializing oauth provider", "provider", payload.Provider, "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	userInfo, err := provider.Exchange(context.Request().Context(), payload.Code, loginState.Nonce, loginState.CodeVerifier)
+	if err != nil {
+		context.App.Logger.Error("error exchanging oauth code", "provider", payload.Provider, "error", err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, "Could not verify the login with the provider")
+	}
+
+	// * accounts are linked by email, so the email must be verified by the provider
+	if userInfo.Email == "" || !userInfo.EmailVerified {
+		return echo.NewHTTPError(http.StatusForbidden, "Email is not verified with the provider")
+	}
+
+	user, err := _findOrProvisionOAuthUser(context, provider.Config, userInfo, loginState.InviteSlug)
+	if err != nil {
+		return err
+	}
+
+	if user.Status != model.UserAccountStatusEnum_Active {
+		return echo.NewHTTPError(http.StatusForbidden, "Account is not active")
+	}
+
+	if two_factor_service.IsEnabled(*user) {
+		return _respondWithTwoFactorChallenge(context, user.UniqueId)
+	}
+
+	memberships, err := _fetchUserMemberships(context, user.UniqueId)
+	if err != nil {
+		context.App.Logger.Error("database query error", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	contextUser, isOnboardingCompleted := _buildLoginContextUser(*user, memberships)
+
+	token, refreshToken, err := _createSessionWithTokens(context.Context, context.App, contextUser)
+	if err != nil {
+		return err
+	}
+
+	return context.JSON(http.StatusOK, api_types.LoginResponseBodySchema{
+		IsOnboardingCompleted: isOnboardingCompleted,
+		Token:                 token,
+		RefreshToken:          refreshToken,
+	})
+}
+
+// _findOrProvisionOAuthUser resolves the user of an oauth identity. Identities already linked are used as is, otherwise
+// the identity is linked to the account with the same email when the provider is trusted for it. Users without an account
+// are only provisioned if they have a pending organization invite, or if the provider allows sign ups.
+func _findOrProvisionOAuthUser(context interfaces.ContextWithoutSession, providerConfig oauth_service.ProviderConfig, userInfo *oauth_service.UserInfo, inviteSlug *string) (*model.User, error) {
+	requestContext := context.Request().Context()
+
+	var linkedUser model.User
+
+	err := SELECT(table.User.AllColumns).
+		FROM(table.UserOauthAccount.
+			INNER_JOIN(table.User, table.User.UniqueId.EQ(table.UserOauthAccount.UserId))).
+		WHERE(
+			table.UserOauthAccount.Provider.EQ(String(providerConfig.Name)).
+				AND(table.UserOauthAccount.Subject.EQ(String(userInfo.Subject))),
+		).
+		LIMIT(1).
+		QueryContext(requestContext, context.App.Db, &linkedUser)
+
+	if err == nil {
+		return &linkedUser, nil
+	}
+
+	if err.Error() != qrm.ErrNoRows.Error() {
+		context.App.Logger.Error("database query error", "error", err.Error())
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	oauthProvider := model.OauthProviderEnum_Oidc
+	if providerConfig.Name == "google" {
+		oauthProvider = model.OauthProviderEnum_Google
+	}
+
+	tx, err := context.App.Db.BeginTx(requestContext, nil)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+	defer tx.Rollback()
+
+	var user model.User
+
+	err = SELECT(table.User.AllColumns).
+		FROM(table.User).
+		WHERE(table.User.Email.EQ(String(userInfo.Email))).
+		LIMIT(1).
+		QueryContext(requestContext, tx, &user)
+
+	if err != nil {
+		if err.Error() != qrm.ErrNoRows.Error() {
+			context.App.Logger.Error("database query error", "error", err.Error())
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+		}
+
+		// * no account with this email, look for pending invites to provision one
+		invitesCondition := table.OrganizationMemberInvite.Email.EQ(String(userInfo.Email)).
+			AND(table.OrganizationMemberInvite.Status.EQ(utils.EnumExpression(model.OrganizationInviteStatusEnum_Pending.String())))
+
+		if inviteSlug != nil {
+			invitesCondition = invitesCondition.AND(table.OrganizationMemberInvite.Slug.EQ(String(*inviteSlug)))
+		} else if !providerConfig.TrustEmailForLinking {
+			// * the email alone is not enough to redeem an invite through a provider which is not trusted with it, the
+			// * slug of the invite is only known to the invited person
+			invitesCondition = invitesCondition.AND(Bool(false))
+		}
+
+		var invites []model.OrganizationMemberInvite
+
+		err = SELECT(table.OrganizationMemberInvite.AllColumns).
+			FROM(table.OrganizationMemberInvite).
+			WHERE(invitesCondition).
+			QueryContext(requestContext, tx, &invites)
+
+		if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+			context.App.Logger.Error("database query error", "error", err.Error())
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+		}
+
+		if len(invites) == 0 && !providerConfig.AllowSignup {
+			return nil, echo.NewHTTPError(http.StatusForbidden, "No account exists for this email, please sign up or ask for an invite first")
+		}
+
+		username, err := _generateUsernameFromEmail(context, userInfo.Email)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+		}
+
+		name := userInfo.Name
+		if name == "" {
+			name = username
 		}
 
-	} else {
-		isOnboardingCompleted = true
-
-		// check for the owner org
-		for _, org := range user.Organizations {
-			if org.MemberDetails.AccessLevel == model.UserPermissionLevelEnum_Owner {
-				organizationIdToLoginWith = org.Organization.UniqueId.String()
-				roleToLoginWith = api_types.Owner
-				break
-			}
+		userToInsert := model.User{
+			Username:      username,
+			Email:         userInfo.Email,
+			Name:          name,
+			OauthProvider: &oauthProvider,
+			Status:        model.UserAccountStatusEnum_Active,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
 		}
 
-		// else login with the first org
-		if organizationIdToLoginWith == "" {
-			organizationIdToLoginWith = user.Organizations[0].Organization.UniqueId.String()
-			roleToLoginWith = api_types.Member
+		if userInfo.Picture != "" {
+			userToInsert.ProfilePictureUrl = &userInfo.Picture
 		}
 
-		claims = &interfaces.JwtPayload{
-			ContextUser: interfaces.ContextUser{
-				Username:       user.User.Username,
-				Email:          user.User.Email,
-				Role:           api_types.UserPermissionLevelEnum(roleToLoginWith),
-				UniqueId:       user.User.UniqueId.String(),
-				OrganizationId: organizationIdToLoginWith,
-				Name:           user.User.Name,
-			},
-			StandardClaims: jwt.StandardClaims{
-				ExpiresAt: time.Now().Add(time.Hour * 24 * 60).Unix(), // 60-day expiration
-				Issuer:    "wapikit",
-			},
+		err = table.User.INSERT(table.User.MutableColumns).
+			MODEL(userToInsert).
+			RETURNING(table.User.AllColumns).
+			QueryContext(requestContext, tx, &user)
+
+		if err != nil {
+			context.App.Logger.Error("database query error", "error", err.Error())
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+		}
+
+		for _, invite := range invites {
+			inviteId := invite.UniqueId
+			_, err = table.OrganizationMember.INSERT(table.OrganizationMember.MutableColumns).
+				MODEL(model.OrganizationMember{
+					AccessLevel:    invite.AccessLevel,
+					OrganizationId: invite.OrganizationId,
+					UserId:         user.UniqueId,
+					IsAvailable:    true,
+					InviteId:       &inviteId,
+					CreatedAt:      time.Now(),
+					UpdatedAt:      time.Now(),
+				}).
+				ExecContext(requestContext, tx)
+
+			if err != nil {
+				context.App.Logger.Error("database query error", "error", err.Error())
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+			}
+
+			_, err = table.OrganizationMemberInvite.UPDATE(table.OrganizationMemberInvite.Status, table.OrganizationMemberInvite.UpdatedAt).
+				SET(utils.EnumExpression(model.OrganizationInviteStatusEnum_Redeemed.String()), TimestampzT(time.Now())).
+				WHERE(table.OrganizationMemberInvite.UniqueId.EQ(UUID(invite.UniqueId))).
+				ExecContext(requestContext, tx)
+
+			if err != nil {
+				context.App.Logger.Error("database query error", "error", err.Error())
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+			}
 		}
+	} else if !providerConfig.TrustEmailForLinking {
+		// * whoever controls the realm of the provider controls its email_verified claim, linking by email would let them
+		// * take over any account
+		return nil, echo.NewHTTPError(http.StatusForbidden, "An account with this email already exists, please login with your password")
+	} else if user.OauthProvider == nil {
+		// * linking an existing password account, the user can login with either from now on
+		_, err = table.User.UPDATE(table.User.OauthProvider, table.User.UpdatedAt).
+			SET(utils.EnumExpression(oauthProvider.String()), TimestampzT(time.Now())).
+			WHERE(table.User.UniqueId.EQ(UUID(user.UniqueId))).
+			ExecContext(requestContext, tx)
 
+		if err != nil {
+			context.App.Logger.Error("database query error", "error", err.Error())
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+		}
 	}
 
-	//Create the token
-	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(context.App.Koa.String("app.jwt_secret")))
+	_, err = table.UserOauthAccount.INSERT(table.UserOauthAccount.MutableColumns).
+		MODEL(model.UserOauthAccount{
+			UserId:    user.UniqueId,
+			Provider:  providerConfig.Name,
+			Subject:   userInfo.Subject,
+			Email:     userInfo.Email,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}).
+		ExecContext(requestContext, tx)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Error generating token")
+		context.App.Logger.Error("database query error", "error", err.Error())
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
 	}
 
-	return context.JSON(http.StatusOK, api_types.LoginResponseBodySchema{
-		IsOnboardingCompleted: isOnboardingCompleted,
-		Token:                 token,
-	})
+	if err := tx.Commit(); err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	return &user, nil
 }
 
-func handleLoginWithOAuth(context interfaces.ContextWithoutSession) error {
-	return echo.NewHTTPError(http.StatusInternalServerError, "Not implemented")
+// _generateUsernameFromEmail derives a username from the local part of the email, with a random suffix if it is taken
+func _generateUsernameFromEmail(context interfaces.ContextWithoutSession, email string) (string, error) {
+	username := strings.ToLower(strings.Split(email, "@")[0])
+
+	for attempt := 0; attempt < 5; attempt++ {
+		var existingUser model.User
+
+		err := SELECT(table.User.UniqueId).
+			FROM(table.User).
+			WHERE(table.User.Username.EQ(String(username))).
+			LIMIT(1).
+			QueryContext(context.Request().Context(), context.App.Db, &existingUser)
+
+		if err != nil && err.Error() == qrm.ErrNoRows.Error() {
+			return username, nil
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		suffix, err := gonanoid.Generate("abcdefghijklmnopqrstuvwxyz0123456789", 6)
+		if err != nil {
+			return "", err
+		}
+		username = strings.ToLower(strings.Split(email, "@")[0]) + "-" + suffix
+	}
+
+	return "", errors.New("could not generate a unique username")
 }
 
 // this handler would validate the email and send an otp to it
@@ -391,7 +950,7 @@ func verifyEmailAndCreateAccount(context interfaces.ContextWithoutSession) error
 	cacheKey := redis.ComputeCacheKey("otp", payload.Email, "registration")
 	cachedOtp, err := redis.GetCachedData(cacheKey)
 	if err != nil {
-		context.App.Logger.Error("Error getting cached otp", err.Error())
+		context.App.Logger.Error("Error getting cached otp", "error", err.Error())
 		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
 	}
 
@@ -413,7 +972,7 @@ func verifyEmailAndCreateAccount(context interfaces.ContextWithoutSession) error
 		if err.Error() == qrm.ErrNoRows.Error() {
 			// do nothing
 		} else {
-			context.App.Logger.Error("database query error", err.Error())
+			context.App.Logger.Error("database query error", "error", err.Error())
 			return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
 		}
 	}
@@ -438,7 +997,7 @@ func verifyEmailAndCreateAccount(context interfaces.ContextWithoutSession) error
 			if err.Error() == qrm.ErrNoRows.Error() {
 				// do  nothing just move on, we cant let the user not register if they do not have a valid invite
 			} else {
-				context.App.Logger.Error("database query error", err.Error())
+				context.App.Logger.Error("database query error", "error", err.Error())
 				return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
 			}
 		}
@@ -465,7 +1024,7 @@ func verifyEmailAndCreateAccount(context interfaces.ContextWithoutSession) error
 	}).RETURNING(table.User.AllColumns).QueryContext(context.Request().Context(), context.App.Db, &insertedUser)
 
 	if err != nil {
-		context.App.Logger.Error("database query error", err.Error())
+		context.App.Logger.Error("database query error", "error", err.Error())
 		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
 	}
 
@@ -474,6 +1033,7 @@ func verifyEmailAndCreateAccount(context interfaces.ContextWithoutSession) error
 			AccessLevel:    invite.AccessLevel,
 			OrganizationId: invite.OrganizationId,
 			UserId:         insertedUser.UniqueId,
+			IsAvailable:    true,
 			InviteId:       &invite.UniqueId,
 			CreatedAt:      time.Now(),
 			UpdatedAt:      time.Now(),
@@ -492,22 +1052,15 @@ func verifyEmailAndCreateAccount(context interfaces.ContextWithoutSession) error
 		contextUser.OrganizationId = invite.OrganizationId.String()
 	}
 
-	claims := &interfaces.JwtPayload{
-		ContextUser: contextUser,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Hour * 24 * 60).Unix(), // 60-day expiration
-			Issuer:    "wapikit",
-		},
-	}
-
-	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(context.App.Koa.String("app.jwt_secret")))
+	token, refreshToken, err := _createSessionWithTokens(context.Context, context.App, contextUser)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Error generating token")
+		return err
 	}
 
 	return context.JSON(http.StatusOK, api_types.VerifyOtpResponseBodySchema{
-		Token: token,
+		Token:        token,
+		RefreshToken: refreshToken,
 	})
 }
 
@@ -588,6 +1141,10 @@ func regenerateApiKey(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
 	}
 
+	if apiKey.Key != "" {
+		controller.InvalidateApiKey(&context.App, apiKey.Key)
+	}
+
 	response := api_types.RegenerateApiKeyResponseSchema{
 		ApiKey: &api_types.ApiKeySchema{
 			CreatedAt: updatedApiKey.CreatedAt,
@@ -698,42 +1255,355 @@ func switchOrganization(context interfaces.ContextWithSession) error {
 		}
 	}
 
-	newOrgQuery.Query(context.App.Db, &newOrgDetails)
+	err = newOrgQuery.Query(context.App.Db, &newOrgDetails)
 
-	if newOrgDetails.UniqueId.String() == "" {
-		return echo.NewHTTPError(http.StatusNotFound, "Organization not found")
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	if newOrgDetails.MemberDetails.UniqueId.String() == "" {
-		return echo.NewHTTPError(http.StatusUnauthorized, "User not a member of the organization")
+	// * organizations the user is not a member of are not found, the same as organizations which do not exist
+	if newOrgDetails.UniqueId == uuid.Nil || newOrgDetails.MemberDetails.UniqueId == uuid.Nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Organization not found")
 	}
 
 	fmt.Println("newOrgDetails", newOrgDetails)
 
 	// create the token
-	claims := &interfaces.JwtPayload{
-		ContextUser: interfaces.ContextUser{
-			Username:       context.Session.User.Username,
-			Email:          context.Session.User.Email,
-			Role:           api_types.UserPermissionLevelEnum(newOrgDetails.MemberDetails.AccessLevel),
-			UniqueId:       context.Session.User.UniqueId,
-			OrganizationId: *payload.OrganizationId,
-			Name:           context.Session.User.Name,
-		},
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Hour * 24 * 60).Unix(), // 60-day expiration
-			Issuer:    "wapikit",
-		},
+	token, err := _switchSessionOrganization(context, interfaces.ContextUser{
+		Username:       context.Session.User.Username,
+		Email:          context.Session.User.Email,
+		Role:           api_types.UserPermissionLevelEnum(newOrgDetails.MemberDetails.AccessLevel),
+		UniqueId:       context.Session.User.UniqueId,
+		OrganizationId: *payload.OrganizationId,
+		Name:           context.Session.User.Name,
+	})
+
+	if err != nil {
+		return err
 	}
 
-	//Create the token
+	return context.JSON(http.StatusOK, api_types.SwitchOrganizationResponseSchema{
+		Token: token,
+	})
+}
 
-	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(context.App.Koa.String("app.jwt_secret")))
+// _createSessionWithTokens starts a new session for the user and returns a short lived access token along with the refresh token of the session
+func _createSessionWithTokens(context echo.Context, app interfaces.App, user interfaces.ContextUser) (string, string, error) {
+	userUuid, err := uuid.Parse(user.UniqueId)
+	if err != nil {
+		return "", "", echo.NewHTTPError(http.StatusInternalServerError, "Invalid user id")
+	}
+
+	var organizationId *uuid.UUID
+	if user.OrganizationId != "" {
+		orgUuid, err := uuid.Parse(user.OrganizationId)
+		if err != nil {
+			return "", "", echo.NewHTTPError(http.StatusInternalServerError, "Invalid organization id")
+		}
+		organizationId = &orgUuid
+	}
+
+	session, refreshToken, err := session_service.CreateSession(context.Request().Context(), app.Db, userUuid, organizationId, session_service.SessionMetaData{
+		UserAgent: context.Request().UserAgent(),
+		IpAddress: context.RealIP(),
+	})
+
+	if err != nil {
+		app.Logger.Error("error creating session", "error", err.Error())
+		return "", "", echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	token, err := session_service.SignAccessToken(user, session.UniqueId.String(), app.Koa.String("app.jwt_secret"))
+	if err != nil {
+		return "", "", echo.NewHTTPError(http.StatusInternalServerError, "Error generating token")
+	}
+
+	return token, refreshToken, nil
+}
+
+// _switchSessionOrganization moves the current session to another organization and returns a new access token for it
+func _switchSessionOrganization(context interfaces.ContextWithSession, user interfaces.ContextUser) (string, error) {
+	if context.Session.SessionId == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "Organization can not be switched using an api key")
+	}
+
+	sessionUuid, err := uuid.Parse(context.Session.SessionId)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized access")
+	}
+
+	orgUuid, err := uuid.Parse(user.OrganizationId)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "Invalid organization id")
+	}
+
+	err = session_service.UpdateSessionOrganization(context.Request().Context(), context.App.Db, sessionUuid, orgUuid)
+	if err != nil {
+		context.App.Logger.Error("error updating session organization", "error", err.Error())
+		return "", echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	token, err := session_service.SignAccessToken(user, context.Session.SessionId, context.App.Koa.String("app.jwt_secret"))
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusInternalServerError, "Error generating token")
+	}
+
+	return token, nil
+}
+
+func refreshAccessToken(context interfaces.ContextWithoutSession) error {
+	payload := new(api_types.RefreshTokenRequestBodySchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if payload.RefreshToken == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Refresh token is required")
+	}
+
+	session, refreshToken, err := session_service.RotateSession(context.Request().Context(), context.App.Db, payload.RefreshToken)
+
+	if err != nil {
+		if errors.Is(err, session_service.ErrInvalidRefreshToken) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid refresh token")
+		}
+		context.App.Logger.Error("error rotating session", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	var user model.User
+
+	userQuery := SELECT(table.User.AllColumns).
+		FROM(table.User).
+		WHERE(table.User.UniqueId.EQ(UUID(session.UserId))).
+		LIMIT(1)
+
+	err = userQuery.QueryContext(context.Request().Context(), context.App.Db, &user)
+
+	if err != nil || user.Status != model.UserAccountStatusEnum_Active {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized access")
+	}
+
+	contextUser := interfaces.ContextUser{
+		Username: user.Username,
+		Email:    user.Email,
+		UniqueId: user.UniqueId.String(),
+		Name:     user.Name,
+	}
+
+	if session.OrganizationId != nil {
+		var member model.OrganizationMember
+
+		memberQuery := SELECT(table.OrganizationMember.AllColumns).
+			FROM(table.OrganizationMember).
+			WHERE(
+				table.OrganizationMember.UserId.EQ(UUID(user.UniqueId)).
+					AND(table.OrganizationMember.OrganizationId.EQ(UUID(*session.OrganizationId))),
+			).
+			LIMIT(1)
+
+		err = memberQuery.QueryContext(context.Request().Context(), context.App.Db, &member)
+
+		// * if the user is no longer a member of the organization, the token is issued without one
+		if err == nil {
+			contextUser.OrganizationId = member.OrganizationId.String()
+			contextUser.Role = api_types.UserPermissionLevelEnum(member.AccessLevel)
+		}
+	}
+
+	token, err := session_service.SignAccessToken(contextUser, session.UniqueId.String(), context.App.Koa.String("app.jwt_secret"))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Error generating token")
 	}
 
-	return context.JSON(http.StatusOK, api_types.SwitchOrganizationResponseSchema{
-		Token: token,
+	return context.JSON(http.StatusOK, api_types.RefreshTokenResponseBodySchema{
+		Token:        token,
+		RefreshToken: refreshToken,
+	})
+}
+
+func handleLogout(context interfaces.ContextWithSession) error {
+	if context.Session.SessionId == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Api keys can not be logged out, regenerate the api key instead")
+	}
+
+	userUuid, err := uuid.Parse(context.Session.User.UniqueId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized access")
+	}
+
+	sessionUuid, err := uuid.Parse(context.Session.SessionId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized access")
+	}
+
+	_, err = session_service.RevokeSession(context.Request().Context(), context.App.Db, context.App.Redis, userUuid, sessionUuid)
+
+	if err != nil {
+		context.App.Logger.Error("error revoking session", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	return context.JSON(http.StatusOK, api_types.LogoutResponseSchema{
+		IsLoggedOut: true,
+	})
+}
+
+func handleLogoutFromAllSessions(context interfaces.ContextWithSession) error {
+	userUuid, err := uuid.Parse(context.Session.User.UniqueId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized access")
+	}
+
+	revokedSessions, err := session_service.RevokeAllSessions(context.Request().Context(), context.App.Db, context.App.Redis, userUuid)
+
+	if err != nil {
+		context.App.Logger.Error("error revoking sessions", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	controller.InvalidateAuthorizationCache(&context.App, userUuid.String())
+
+	return context.JSON(http.StatusOK, api_types.LogoutAllSessionsResponseSchema{
+		RevokedSessions: revokedSessions,
+	})
+}
+
+func handleForgotPassword(context interfaces.ContextWithoutSession) error {
+	redis := context.App.Redis
+
+	payload := new(api_types.ForgotPasswordRequestBodySchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if !utils.IsValidEmail(payload.Email) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid email")
+	}
+
+	// * without a mailer the link can only be logged, which is only done outside production
+	if context.App.Mailer == nil && context.App.Constants.IsProduction {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Password reset by email is not configured on this server")
+	}
+
+	// * the response is the same whether the user exists or not, so that this endpoint can not be used to find out registered emails
+	response := api_types.ForgotPasswordResponseBodySchema{
+		IsResetLinkSent: true,
+	}
+
+	var user model.User
+
+	userQuery := SELECT(table.User.AllColumns).
+		FROM(table.User).
+		WHERE(table.User.Email.EQ(String(payload.Email))).
+		LIMIT(1)
+
+	err := userQuery.QueryContext(context.Request().Context(), context.App.Db, &user)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return context.JSON(http.StatusOK, response)
+		}
+		context.App.Logger.Error("database query error", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	if user.Status != model.UserAccountStatusEnum_Active {
+		return context.JSON(http.StatusOK, response)
+	}
+
+	resetToken, err := session_service.GenerateOpaqueToken()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	cacheKey := redis.ComputeCacheKey("password-reset", session_service.HashToken(resetToken), "user")
+
+	err = redis.CacheData(cacheKey, user.UniqueId.String(), session_service.PasswordResetTokenTTL)
+
+	if err != nil {
+		context.App.Logger.Error("error caching password reset token", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	resetLink := fmt.Sprintf("%s/reset-password?token=%s", context.App.Constants.RootURL, resetToken)
+
+	if context.App.Mailer == nil {
+		context.App.Logger.Info("password reset link generated", "email", user.Email, "link", resetLink)
+		return context.JSON(http.StatusOK, response)
+	}
+
+	body := fmt.Sprintf(
+		"Hi %s,\n\nWe received a request to reset the password of your account. Open the link below to choose a new password, it expires in %d minutes:\n\n%s\n\nIf you did not ask for it, you can ignore this email, your password stays the same.",
+		user.Name,
+		int(session_service.PasswordResetTokenTTL.Minutes()),
+		resetLink,
+	)
+
+	// * sent in the background, so that the time of the response does not tell whether the email is registered
+	app := context.App
+	go func() {
+		if err := app.Mailer.Send(user.Email, "Reset your password", body); err != nil {
+			app.Logger.Error("error sending password reset email", "error", err.Error())
+		}
+	}()
+
+	return context.JSON(http.StatusOK, response)
+}
+
+func handleResetPassword(context interfaces.ContextWithoutSession) error {
+	redis := context.App.Redis
+
+	payload := new(api_types.ResetPasswordRequestBodySchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if payload.Token == "" || payload.Password == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Token and password are required")
+	}
+
+	cacheKey := redis.ComputeCacheKey("password-reset", session_service.HashToken(payload.Token), "user")
+
+	// * reset tokens are single use, taking the token drops it so that concurrent requests can not both use it
+	userId, err := redis.TakeCachedData(cacheKey)
+	if err != nil {
+		if !cache.IsNotCached(err) {
+			context.App.Logger.Error("error taking password reset token", "error", err.Error())
+			return echo.NewHTTPError(http.StatusServiceUnavailable, "Service unavailable")
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid or expired reset token")
+	}
+
+	userUuid, err := uuid.Parse(userId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid or expired reset token")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Error hashing password")
+	}
+
+	_, err = table.User.UPDATE(table.User.Password, table.User.UpdatedAt).
+		SET(String(string(hashedPassword)), TimestampzT(time.Now())).
+		WHERE(table.User.UniqueId.EQ(UUID(userUuid))).
+		ExecContext(context.Request().Context(), context.App.Db)
+
+	if err != nil {
+		context.App.Logger.Error("error updating password", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+	}
+
+	// * every existing session is signed out once the password changes
+	_, err = session_service.RevokeAllSessions(context.Request().Context(), context.App.Db, context.App.Redis, userUuid)
+	if err != nil {
+		context.App.Logger.Error("error revoking sessions", "error", err.Error())
+	}
+
+	controller.InvalidateAuthorizationCache(&context.App, userUuid.String())
+
+	return context.JSON(http.StatusOK, api_types.ResetPasswordResponseBodySchema{
+		IsPasswordReset: true,
 	})
 }
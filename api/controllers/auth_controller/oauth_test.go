@@ -0,0 +1,343 @@
+package auth_controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knadh/koanf/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/oauth_service"
+	"github.com/wapikit/wapikit/internal/core/oauth_service/oauthtest"
+	"github.com/wapikit/wapikit/internal/interfaces"
+	"github.com/wapikit/wapikit/internal/testutil"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+type oauthTestProvider struct {
+	allowSignup          bool
+	trustEmailForLinking bool
+}
+
+type oauthTest struct {
+	t      *testing.T
+	app    *interfaces.App
+	server *echo.Echo
+	issuer *oauthtest.Issuer
+}
+
+func newOAuthTest(t *testing.T, provider oauthTestProvider) *oauthTest {
+	app := testutil.NewApp(t)
+
+	issuer, err := oauthtest.NewIssuer("wapikit", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(issuer.Close)
+
+	koa := koanf.New(".")
+	koa.Set("oauth.mock.enabled", true)
+	koa.Set("oauth.mock.issuer_url", issuer.URL())
+	koa.Set("oauth.mock.client_id", issuer.ClientId)
+	koa.Set("oauth.mock.client_secret", issuer.ClientSecret)
+	koa.Set("oauth.mock.redirect_url", "http://localhost:3000/oauth/callback")
+	koa.Set("oauth.mock.allow_signup", provider.allowSignup)
+	koa.Set("oauth.mock.trust_email_for_linking", provider.trustEmailForLinking)
+	app.OAuthProviders = oauth_service.NewProviderRegistry(koa)
+
+	return &oauthTest{
+		t:      t,
+		app:    app,
+		server: testutil.NewServer(app, NewAuthController()),
+		issuer: issuer,
+	}
+}
+
+func (test *oauthTest) request(method, target string, body interface{}) *httptest.ResponseRecorder {
+	test.t.Helper()
+
+	var requestBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&requestBody).Encode(body); err != nil {
+			test.t.Fatal(err)
+		}
+	}
+
+	request := httptest.NewRequest(method, target, &requestBody)
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder := httptest.NewRecorder()
+	test.server.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// authorizationUrl starts a login and returns the url the user is sent to
+func (test *oauthTest) authorizationUrl(inviteSlug string) string {
+	test.t.Helper()
+
+	target := "/api/auth/oauth/mock"
+	if inviteSlug != "" {
+		target += "?inviteSlug=" + url.QueryEscape(inviteSlug)
+	}
+
+	response := test.request(http.MethodGet, target, nil)
+	if response.Code != http.StatusOK {
+		test.t.Fatalf("expected the authorization url, got %d %s", response.Code, response.Body.String())
+	}
+
+	var body api_types.GetOAuthAuthorizationUrlResponseSchema
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		test.t.Fatal(err)
+	}
+
+	return body.AuthorizationUrl
+}
+
+// callback completes the login the way the callback page of the frontend does
+func (test *oauthTest) callback(code, state string) *httptest.ResponseRecorder {
+	return test.request(http.MethodPost, "/api/auth/oauth", api_types.OAuthLoginRequestBodySchema{
+		Provider: "mock",
+		Code:     code,
+		State:    state,
+	})
+}
+
+// login signs the identity in at the provider and completes the login
+func (test *oauthTest) login(identity oauthtest.Identity, inviteSlug string) *httptest.ResponseRecorder {
+	test.t.Helper()
+
+	code, state, err := test.issuer.Authorize(test.authorizationUrl(inviteSlug), identity)
+	if err != nil {
+		test.t.Fatal(err)
+	}
+
+	return test.callback(code, state)
+}
+
+func (test *oauthTest) linkedUserId(subject string) *uuid.UUID {
+	test.t.Helper()
+
+	var oauthAccounts []model.UserOauthAccount
+
+	err := SELECT(table.UserOauthAccount.AllColumns).
+		FROM(table.UserOauthAccount).
+		WHERE(
+			table.UserOauthAccount.Provider.EQ(String("mock")).
+				AND(table.UserOauthAccount.Subject.EQ(String(subject))),
+		).
+		QueryContext(context.Background(), test.app.Db, &oauthAccounts)
+
+	if err != nil {
+		test.t.Fatal(err)
+	}
+
+	if len(oauthAccounts) == 0 {
+		return nil
+	}
+
+	return &oauthAccounts[0].UserId
+}
+
+func newIdentity(email string) oauthtest.Identity {
+	return oauthtest.Identity{
+		Subject:       uuid.NewString(),
+		Email:         email,
+		EmailVerified: true,
+		Name:          "OAuth User",
+	}
+}
+
+func uniqueEmail() string {
+	return "oauth-" + uuid.NewString()[:8] + "@example.com"
+}
+
+func expectStatus(t *testing.T, response *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if response.Code != status {
+		t.Fatalf("expected status %d, got %d %s", status, response.Code, response.Body.String())
+	}
+}
+
+func TestOAuthLoginProvisionsAUserWhenSignupIsAllowed(t *testing.T) {
+	test := newOAuthTest(t, oauthTestProvider{allowSignup: true})
+	identity := newIdentity(uniqueEmail())
+
+	response := test.login(identity, "")
+	expectStatus(t, response, http.StatusOK)
+
+	var body api_types.LoginResponseBodySchema
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	if body.Token == "" || body.RefreshToken == "" {
+		t.Fatalf("expected the tokens of a session, got %s", response.Body.String())
+	}
+
+	if test.linkedUserId(identity.Subject) == nil {
+		t.Fatal("expected the identity to be linked to the provisioned user")
+	}
+}
+
+func TestOAuthLoginRefusesUnknownUsersWithoutSignup(t *testing.T) {
+	test := newOAuthTest(t, oauthTestProvider{})
+	identity := newIdentity(uniqueEmail())
+
+	expectStatus(t, test.login(identity, ""), http.StatusForbidden)
+
+	if test.linkedUserId(identity.Subject) != nil {
+		t.Fatal("expected no user to be provisioned")
+	}
+}
+
+func TestOAuthLoginRefusesUnverifiedEmails(t *testing.T) {
+	test := newOAuthTest(t, oauthTestProvider{allowSignup: true, trustEmailForLinking: true})
+	identity := newIdentity(uniqueEmail())
+	identity.EmailVerified = false
+
+	expectStatus(t, test.login(identity, ""), http.StatusForbidden)
+}
+
+func TestOAuthLoginLinksTheAccountOfTheEmailWhenTheProviderIsTrusted(t *testing.T) {
+	test := newOAuthTest(t, oauthTestProvider{trustEmailForLinking: true})
+	user := testutil.SeedUser(t, test.app, uniqueEmail())
+	identity := newIdentity(user.Email)
+
+	expectStatus(t, test.login(identity, ""), http.StatusOK)
+
+	linkedUserId := test.linkedUserId(identity.Subject)
+	if linkedUserId == nil || *linkedUserId != user.UniqueId {
+		t.Fatalf("expected the identity to be linked to the existing user %s, got %v", user.UniqueId, linkedUserId)
+	}
+
+	// * the linked identity is used from then on, even once the email changes at the provider
+	identity.Email = uniqueEmail()
+	expectStatus(t, test.login(identity, ""), http.StatusOK)
+}
+
+func TestOAuthLoginDoesNotLinkByEmailWhenTheProviderIsNotTrusted(t *testing.T) {
+	test := newOAuthTest(t, oauthTestProvider{allowSignup: true})
+	user := testutil.SeedUser(t, test.app, uniqueEmail())
+	identity := newIdentity(user.Email)
+
+	expectStatus(t, test.login(identity, ""), http.StatusForbidden)
+
+	if test.linkedUserId(identity.Subject) != nil {
+		t.Fatal("expected the identity not to be linked to the existing user")
+	}
+}
+
+func TestOAuthLoginRedeemsInvitesOfUntrustedProvidersOnlyWithTheSlug(t *testing.T) {
+	test := newOAuthTest(t, oauthTestProvider{})
+	organization := testutil.SeedOrganization(t, test.app)
+	email := uniqueEmail()
+
+	invite := model.OrganizationMemberInvite{
+		Slug:            uuid.NewString(),
+		Email:           email,
+		AccessLevel:     model.UserPermissionLevelEnum_Member,
+		OrganizationId:  organization.Organization.UniqueId,
+		Status:          model.OrganizationInviteStatusEnum_Pending,
+		InvitedByUserId: organization.Owner.UniqueId,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	_, err := table.OrganizationMemberInvite.INSERT(table.OrganizationMemberInvite.MutableColumns).
+		MODEL(invite).
+		ExecContext(context.Background(), test.app.Db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, test.login(newIdentity(email), ""), http.StatusForbidden)
+
+	identity := newIdentity(email)
+	expectStatus(t, test.login(identity, invite.Slug), http.StatusOK)
+
+	if test.linkedUserId(identity.Subject) == nil {
+		t.Fatal("expected the invited user to be provisioned")
+	}
+}
+
+func TestOAuthLoginStateIsSingleUse(t *testing.T) {
+	test := newOAuthTest(t, oauthTestProvider{allowSignup: true})
+
+	code, state, err := test.issuer.Authorize(test.authorizationUrl(""), newIdentity(uniqueEmail()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, test.callback(code, "unknown-state"), http.StatusBadRequest)
+	expectStatus(t, test.callback(code, state), http.StatusOK)
+	expectStatus(t, test.callback(code, state), http.StatusBadRequest)
+}
+
+func TestOAuthLoginRejectsAStateOfAnotherProvider(t *testing.T) {
+	test := newOAuthTest(t, oauthTestProvider{allowSignup: true})
+
+	code, state, err := test.issuer.Authorize(test.authorizationUrl(""), newIdentity(uniqueEmail()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := test.request(http.MethodPost, "/api/auth/oauth", api_types.OAuthLoginRequestBodySchema{
+		Provider: "another",
+		Code:     code,
+		State:    state,
+	})
+
+	expectStatus(t, response, http.StatusBadRequest)
+}
+
+func TestOAuthLoginRejectsAnIdTokenOfAnotherNonce(t *testing.T) {
+	test := newOAuthTest(t, oauthTestProvider{allowSignup: true})
+
+	authorizationUrl, err := url.Parse(test.authorizationUrl(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// * the provider signs whatever nonce it is sent, as if the id token had been issued for another login
+	query := authorizationUrl.Query()
+	query.Set("nonce", "another-nonce")
+	authorizationUrl.RawQuery = query.Encode()
+
+	code, state, err := test.issuer.Authorize(authorizationUrl.String(), newIdentity(uniqueEmail()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, test.callback(code, state), http.StatusBadRequest)
+}
+
+func TestOAuthLoginRequiresTheCodeVerifierOfTheLogin(t *testing.T) {
+	test := newOAuthTest(t, oauthTestProvider{allowSignup: true})
+
+	authorizationUrl, err := url.Parse(test.authorizationUrl(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// * a code obtained with another challenge can not be exchanged with the verifier of this login
+	query := authorizationUrl.Query()
+	query.Set("code_challenge", "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM")
+	authorizationUrl.RawQuery = query.Encode()
+
+	code, state, err := test.issuer.Authorize(authorizationUrl.String(), newIdentity(uniqueEmail()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, test.callback(code, state), http.StatusBadRequest)
+}
@@ -0,0 +1,145 @@
+package auth_controller
+
+import (
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/testutil"
+)
+
+type sentEmail struct {
+	to      string
+	subject string
+	body    string
+}
+
+// recordingMailer keeps the emails it is asked to send instead of sending them
+type recordingMailer struct {
+	sent chan sentEmail
+}
+
+func (mailer *recordingMailer) Send(to, subject, body string) error {
+	mailer.sent <- sentEmail{to, subject, body}
+	return nil
+}
+
+var resetTokenPattern = regexp.MustCompile(`reset-password\?token=(\S+)`)
+
+type passwordResetTest struct {
+	*oauthTest
+	mailer *recordingMailer
+}
+
+func newPasswordResetTest(t *testing.T) *passwordResetTest {
+	test := newOAuthTest(t, oauthTestProvider{})
+	mailer := &recordingMailer{sent: make(chan sentEmail, 1)}
+	test.app.Mailer = mailer
+	return &passwordResetTest{oauthTest: test, mailer: mailer}
+}
+
+// resetToken asks for the reset link of the email and returns the token of the link sent to it
+func (test *passwordResetTest) resetToken(email string) string {
+	test.t.Helper()
+
+	response := test.request(http.MethodPost, "/api/auth/forgot-password", api_types.ForgotPasswordRequestBodySchema{
+		Email: email,
+	})
+	expectStatus(test.t, response, http.StatusOK)
+
+	select {
+	case sent := <-test.mailer.sent:
+		if sent.to != email {
+			test.t.Fatalf("expected the link to be sent to %s, got %s", email, sent.to)
+		}
+
+		match := resetTokenPattern.FindStringSubmatch(sent.body)
+		if match == nil {
+			test.t.Fatalf("expected a reset link in the email, got %s", sent.body)
+		}
+		return match[1]
+	case <-time.After(5 * time.Second):
+		test.t.Fatal("expected the reset link to be sent")
+		return ""
+	}
+}
+
+func (test *passwordResetTest) resetPassword(token string) int {
+	return test.request(http.MethodPost, "/api/auth/reset-password", api_types.ResetPasswordRequestBodySchema{
+		Token:    token,
+		Password: "a-new-password",
+	}).Code
+}
+
+func TestForgotPasswordSendsASingleUseResetLink(t *testing.T) {
+	test := newPasswordResetTest(t)
+	user := testutil.SeedUser(t, test.app, uniqueEmail())
+
+	token := test.resetToken(user.Email)
+
+	if status := test.resetPassword(token); status != http.StatusOK {
+		t.Fatalf("expected the password to be reset, got %d", status)
+	}
+
+	if status := test.resetPassword(token); status != http.StatusBadRequest {
+		t.Fatalf("expected the used token to be refused, got %d", status)
+	}
+}
+
+func TestResetTokenCanOnlyBeUsedByOneOfConcurrentRequests(t *testing.T) {
+	test := newPasswordResetTest(t)
+	user := testutil.SeedUser(t, test.app, uniqueEmail())
+
+	token := test.resetToken(user.Email)
+
+	statuses := make([]int, 10)
+	var wg sync.WaitGroup
+	for index := range statuses {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			statuses[index] = test.resetPassword(token)
+		}(index)
+	}
+	wg.Wait()
+
+	resets := 0
+	for _, status := range statuses {
+		if status == http.StatusOK {
+			resets++
+		}
+	}
+
+	if resets != 1 {
+		t.Fatalf("expected the token to reset the password once, got %d resets %v", resets, statuses)
+	}
+}
+
+func TestForgotPasswordSendsNothingToUnknownEmails(t *testing.T) {
+	test := newPasswordResetTest(t)
+
+	response := test.request(http.MethodPost, "/api/auth/forgot-password", api_types.ForgotPasswordRequestBodySchema{
+		Email: uniqueEmail(),
+	})
+	expectStatus(t, response, http.StatusOK)
+
+	select {
+	case sent := <-test.mailer.sent:
+		t.Fatalf("expected no email to be sent, got one to %s", sent.to)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestForgotPasswordIsRefusedInProductionWithoutAMailer(t *testing.T) {
+	test := newPasswordResetTest(t)
+	test.app.Mailer = nil
+	test.app.Constants.IsProduction = true
+
+	response := test.request(http.MethodPost, "/api/auth/forgot-password", api_types.ForgotPasswordRequestBodySchema{
+		Email: uniqueEmail(),
+	})
+	expectStatus(t, response, http.StatusServiceUnavailable)
+}
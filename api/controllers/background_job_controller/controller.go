@@ -0,0 +1,216 @@
+package background_job_controller
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	controller "github.com/wapikit/wapikit/api/controllers"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/background_job_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+	"github.com/wapikit/wapikit/internal/interfaces"
+
+	"github.com/go-jet/jet/qrm"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// the item errors are read and written to the download in pages of this size
+const itemErrorPageSize = 1000
+
+type BackgroundJobController struct {
+	controller.BaseController `json:"-,inline"`
+}
+
+func NewBackgroundJobController() *BackgroundJobController {
+	return &BackgroundJobController{
+		BaseController: controller.BaseController{
+			Name:        "Background Job Controller",
+			RestApiPath: "/api/jobs",
+			Routes: []interfaces.Route{
+				{
+					Path:                    "/api/jobs",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getBackgroundJobs),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+					},
+				},
+				{
+					Path:                    "/api/jobs/:id",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getBackgroundJobById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    120,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+					},
+				},
+				{
+					Path:                    "/api/jobs/:id/errors",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(downloadBackgroundJobErrors),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getBackgroundJobs(context interfaces.ContextWithSession) error {
+	params := new(api_types.GetBackgroundJobsParams)
+	if err := utils.BindQueryParams(context, params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	whereCondition := table.BackgroundJob.OrganizationId.EQ(UUID(orgUuid))
+
+	if params.Type != nil {
+		whereCondition = whereCondition.AND(table.BackgroundJob.Type.EQ(utils.EnumExpression(string(*params.Type))))
+	}
+
+	if params.Status != nil {
+		whereCondition = whereCondition.AND(table.BackgroundJob.Status.EQ(utils.EnumExpression(string(*params.Status))))
+	}
+
+	var jobs []struct {
+		TotalJobs int `json:"totalJobs"`
+		model.BackgroundJob
+	}
+
+	err = SELECT(
+		table.BackgroundJob.AllColumns,
+		COUNT(table.BackgroundJob.UniqueId).OVER().AS("totalJobs"),
+	).
+		FROM(table.BackgroundJob).
+		WHERE(whereCondition).
+		ORDER_BY(table.BackgroundJob.CreatedAt.DESC()).
+		LIMIT(params.PerPage).
+		OFFSET((params.Page-1)*params.PerPage).
+		QueryContext(context.Request().Context(), context.App.Db, &jobs)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	jobsToReturn := make([]api_types.BackgroundJobSchema, 0, len(jobs))
+	for _, job := range jobs {
+		jobsToReturn = append(jobsToReturn, background_job_service.ToSchema(job.BackgroundJob))
+	}
+
+	total := 0
+	if len(jobs) > 0 {
+		total = jobs[0].TotalJobs
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetBackgroundJobsResponseSchema{
+		Jobs: jobsToReturn,
+		PaginationMeta: api_types.PaginationMeta{
+			Page:    params.Page,
+			PerPage: params.PerPage,
+			Total:   total,
+		},
+	})
+}
+
+func getBackgroundJobById(context interfaces.ContextWithSession) error {
+	job, err := fetchJob(context)
+	if err != nil {
+		return err
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetBackgroundJobByIdResponseSchema{
+		Job: background_job_service.ToSchema(*job),
+	})
+}
+
+// downloadBackgroundJobErrors streams the items which failed as a CSV file, with the item number, the reason and the item
+func downloadBackgroundJobErrors(context interfaces.ContextWithSession) error {
+	job, err := fetchJob(context)
+	if err != nil {
+		return err
+	}
+
+	response := context.Response()
+	response.Header().Set(echo.HeaderContentType, "text/csv")
+	response.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+job.UniqueId.String()+`-errors.csv"`)
+	response.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(response)
+	writer.Write([]string{"row", "error", "data"})
+
+	lastItemNumber := 0
+
+	for {
+		itemErrors, err := background_job_service.FetchItemErrors(context.Request().Context(), context.App.Db, job.UniqueId, lastItemNumber, itemErrorPageSize)
+		if err != nil {
+			// * the status has already been sent, the download is cut short instead
+			context.App.Logger.Error("error fetching background job errors", "jobId", job.UniqueId.String(), "error", err.Error())
+			break
+		}
+
+		for _, itemError := range itemErrors {
+			item := ""
+			if itemError.Item != nil {
+				item = *itemError.Item
+			}
+			writer.Write([]string{strconv.Itoa(int(itemError.ItemNumber)), itemError.Error, item})
+		}
+
+		writer.Flush()
+
+		if len(itemErrors) < itemErrorPageSize {
+			break
+		}
+
+		lastItemNumber = int(itemErrors[len(itemErrors)-1].ItemNumber)
+	}
+
+	return writer.Error()
+}
+
+func fetchJob(context interfaces.ContextWithSession) (*model.BackgroundJob, error) {
+	jobUuid, err := uuid.Parse(context.Param("id"))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid job id")
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	job, err := background_job_service.FetchJob(context.Request().Context(), context.App.Db, orgUuid, jobUuid)
+	if err != nil {
+		if err == background_job_service.ErrJobNotFound {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Job not found")
+		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return job, nil
+}
@@ -1,15 +1,23 @@
 package controller
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	wapi "github.com/wapikit/wapi.go/pkg/client"
 	"github.com/wapikit/wapikit/internal/api_types"
 	"github.com/wapikit/wapikit/internal/core/ai_service"
+	"github.com/wapikit/wapikit/internal/core/session_service"
+	"github.com/wapikit/wapikit/internal/core/two_factor_service"
 	"github.com/wapikit/wapikit/internal/interfaces"
 
 	. "github.com/go-jet/jet/v2/postgres"
@@ -54,6 +62,353 @@ func _isPermissionInList(requiredPermission api_types.RolePermissionEnum, userPe
 	return false
 }
 
+// sensitivePermissions guard credentials and access control, members required to use two factor authentication
+// by their organization can not use routes requiring these until they have enrolled
+var sensitivePermissions = []api_types.RolePermissionEnum{
+	api_types.GetApiKey,
+	api_types.RegenerateApiKey,
+	api_types.UpdateAppSettings,
+	api_types.UpdateOrganization,
+	api_types.CreateOrganizationMember,
+	api_types.UpdateOrganizationMember,
+	api_types.DeleteOrganizationMember,
+	api_types.CreateOrganizationRole,
+	api_types.UpdateOrganizationRole,
+	api_types.DeleteOrganizationRole,
+}
+
+func _isSensitiveRoute(routeMetadata interfaces.RouteMetaData) bool {
+	if routeMetadata.PermissionRoleLevel == api_types.Owner {
+		return true
+	}
+	for _, requiredPermission := range routeMetadata.RequiredPermission {
+		if _isPermissionInList(requiredPermission, sensitivePermissions) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizationDetails is everything authMiddleware needs to know about a user within an organization
+type authorizationDetails struct {
+	User         model.User
+	Organization *model.Organization
+	AccessLevel  model.UserPermissionLevelEnum
+	Permissions  []api_types.RolePermissionEnum
+	WapiClient   *wapi.Client
+	AiService    *ai_service.AiService
+	// whether the two factor policy of the organization applies to the user
+	IsTwoFactorRequired bool
+	expiresAt           time.Time
+}
+
+// * the user and organization graph is cached in memory for a short while so that authMiddleware does not have to
+// * query it on every request, revocation of sessions is checked on every request separately. Every instance keeps its
+// * own cache, the invalidations are published over redis so that all of them drop the invalidated entries.
+const authorizationCacheTTL = time.Minute
+
+var (
+	authorizationCache      = make(map[string]*authorizationDetails)
+	validatedApiKeys        = make(map[string]time.Time)
+	authorizationCacheMutex sync.RWMutex
+)
+
+// authorizationCacheInvalidation is published to the other instances when cached authorization details change, only one
+// of the fields is set
+type authorizationCacheInvalidation struct {
+	UserId         string `json:"userId,omitempty"`
+	OrganizationId string `json:"organizationId,omitempty"`
+	ApiKeyHash     string `json:"apiKeyHash,omitempty"`
+}
+
+func _authorizationCacheKey(userId, organizationId string) string {
+	return userId + ":" + organizationId
+}
+
+func _authorizationCacheChannel(app *interfaces.App) string {
+	return app.Constants.RedisEventChannelName + ":authorization_cache_invalidation"
+}
+
+// InvalidateOrganizationAuthorizationCache drops the cached authorization details of every member of the organization
+func InvalidateOrganizationAuthorizationCache(app *interfaces.App, organizationId string) {
+	invalidation := authorizationCacheInvalidation{OrganizationId: organizationId}
+	_dropAuthorizationCacheEntries(invalidation)
+	_publishAuthorizationCacheInvalidation(app, invalidation)
+}
+
+// InvalidateAuthorizationCache drops the cached authorization details of the user for every organization
+func InvalidateAuthorizationCache(app *interfaces.App, userId string) {
+	invalidation := authorizationCacheInvalidation{UserId: userId}
+	_dropAuthorizationCacheEntries(invalidation)
+	_publishAuthorizationCacheInvalidation(app, invalidation)
+}
+
+// InvalidateApiKey stops accepting the api key right away, to be called once the key has been regenerated
+func InvalidateApiKey(app *interfaces.App, apiKey string) {
+	invalidation := authorizationCacheInvalidation{ApiKeyHash: session_service.HashToken(apiKey)}
+	_dropAuthorizationCacheEntries(invalidation)
+	_publishAuthorizationCacheInvalidation(app, invalidation)
+}
+
+func _dropAuthorizationCacheEntries(invalidation authorizationCacheInvalidation) {
+	authorizationCacheMutex.Lock()
+	defer authorizationCacheMutex.Unlock()
+
+	if invalidation.ApiKeyHash != "" {
+		delete(validatedApiKeys, invalidation.ApiKeyHash)
+	}
+
+	for key := range authorizationCache {
+		if (invalidation.UserId != "" && strings.HasPrefix(key, invalidation.UserId+":")) ||
+			(invalidation.OrganizationId != "" && strings.HasSuffix(key, ":"+invalidation.OrganizationId)) {
+			delete(authorizationCache, key)
+		}
+	}
+}
+
+func _publishAuthorizationCacheInvalidation(app *interfaces.App, invalidation authorizationCacheInvalidation) {
+	message, err := json.Marshal(invalidation)
+	if err != nil {
+		app.Logger.Error("error encoding authorization cache invalidation", "error", err.Error())
+		return
+	}
+
+	// * the other instances still drop the entry once its ttl is over, if the publish fails
+	if err := app.Redis.Publish(context.Background(), _authorizationCacheChannel(app), message).Err(); err != nil {
+		app.Logger.Error("error publishing authorization cache invalidation", "error", err.Error())
+	}
+}
+
+// ListenForAuthorizationCacheInvalidations drops the cache entries invalidated by the other instances, it blocks until
+// the context is done
+func ListenForAuthorizationCacheInvalidations(ctx context.Context, app *interfaces.App) {
+	pubsub := app.Redis.Subscribe(ctx, _authorizationCacheChannel(app))
+	defer pubsub.Close()
+
+	for message := range pubsub.Channel() {
+		var invalidation authorizationCacheInvalidation
+		if err := json.Unmarshal([]byte(message.Payload), &invalidation); err != nil {
+			app.Logger.Error("unable to decode authorization cache invalidation", "error", err.Error())
+			continue
+		}
+		_dropAuthorizationCacheEntries(invalidation)
+	}
+}
+
+func _pruneAuthorizationCache() {
+	now := time.Now()
+	for key, details := range authorizationCache {
+		if details.expiresAt.Before(now) {
+			delete(authorizationCache, key)
+		}
+	}
+	for key, expiresAt := range validatedApiKeys {
+		if expiresAt.Before(now) {
+			delete(validatedApiKeys, key)
+		}
+	}
+}
+
+// NewWapiClient returns a whatsapp api client for the business account, the access token is stored encrypted
+func NewWapiClient(app *interfaces.App, businessAccount model.WhatsappBusinessAccount) (*wapi.Client, error) {
+	accessToken, err := app.Secrets.Decrypt(businessAccount.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return wapi.New(&wapi.ClientConfig{
+		BusinessAccountId: businessAccount.AccountId,
+		ApiAccessToken:    accessToken,
+		WebhookSecret:     businessAccount.WebhookSecret,
+	}), nil
+}
+
+// BuildBusinessAccountDetails returns the business account details sent to the clients, the access token is masked
+func BuildBusinessAccountDetails(app *interfaces.App, businessAccount model.WhatsappBusinessAccount, phoneNumbers []api_types.PhoneNumberSchema) api_types.WhatsAppBusinessAccountDetailsSchema {
+	health := api_types.WhatsAppBusinessAccountHealthSchema{
+		Status:         api_types.WhatsAppBusinessAccountHealthStatusEnum(businessAccount.HealthStatus),
+		CheckedAt:      businessAccount.HealthCheckedAt,
+		TokenExpiresAt: businessAccount.TokenExpiresAt,
+		Error:          businessAccount.HealthError,
+		Scopes:         []string{},
+	}
+
+	if businessAccount.TokenScopes != nil && *businessAccount.TokenScopes != "" {
+		health.Scopes = strings.Split(*businessAccount.TokenScopes, ",")
+	}
+
+	details := api_types.WhatsAppBusinessAccountDetailsSchema{
+		BusinessAccountId: businessAccount.AccountId,
+		AccessToken:       app.Secrets.MaskStored(businessAccount.AccessToken),
+		WebhookSecret:     businessAccount.WebhookSecret,
+		Health:            &health,
+	}
+
+	if phoneNumbers != nil {
+		details.PhoneNumbers = &phoneNumbers
+	}
+
+	return details
+}
+
+func _fetchAuthorizationDetails(ctx echo.Context, app *interfaces.App, userId, organizationId string) (*authorizationDetails, error) {
+	cacheKey := _authorizationCacheKey(userId, organizationId)
+
+	authorizationCacheMutex.RLock()
+	cachedDetails, ok := authorizationCache[cacheKey]
+	authorizationCacheMutex.RUnlock()
+
+	if ok && cachedDetails.expiresAt.After(time.Now()) {
+		return cachedDetails, nil
+	}
+
+	userUuid, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	type UserWithOrgDetails struct {
+		model.User
+		Organizations []struct {
+			model.Organization
+			WhatsappBusinessAccount *model.WhatsappBusinessAccount
+			MemberDetails           struct {
+				model.OrganizationMember
+				AssignedRoles []struct {
+					model.RoleAssignment
+					Role model.OrganizationRole
+				}
+			}
+		}
+	}
+
+	user := UserWithOrgDetails{}
+
+	if organizationId == "" {
+		err = SELECT(table.User.AllColumns).
+			FROM(table.User).
+			WHERE(table.User.UniqueId.EQ(UUID(userUuid))).
+			QueryContext(ctx.Request().Context(), app.Db, &user.User)
+	} else {
+		orgUuid, parseErr := uuid.Parse(organizationId)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		// * only the organization the user is currently logged in with is joined
+		err = SELECT(
+			table.User.AllColumns,
+			table.OrganizationMember.AllColumns,
+			table.Organization.AllColumns,
+			table.WhatsappBusinessAccount.AllColumns,
+			table.RoleAssignment.AllColumns,
+			table.OrganizationRole.AllColumns,
+		).FROM(
+			table.User.
+				LEFT_JOIN(table.OrganizationMember, table.User.UniqueId.EQ(table.OrganizationMember.UserId).
+					AND(table.OrganizationMember.OrganizationId.EQ(UUID(orgUuid)))).
+				LEFT_JOIN(table.Organization, table.Organization.UniqueId.EQ(table.OrganizationMember.OrganizationId)).
+				LEFT_JOIN(table.WhatsappBusinessAccount, table.WhatsappBusinessAccount.OrganizationId.EQ(table.Organization.UniqueId)).
+				LEFT_JOIN(table.RoleAssignment, table.OrganizationMember.UniqueId.EQ(table.RoleAssignment.OrganizationMemberId)).
+				LEFT_JOIN(table.OrganizationRole, table.RoleAssignment.OrganizationRoleId.EQ(table.OrganizationRole.UniqueId)),
+		).WHERE(
+			table.User.UniqueId.EQ(UUID(userUuid)),
+		).QueryContext(ctx.Request().Context(), app.Db, &user)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	details := &authorizationDetails{
+		User:      user.User,
+		expiresAt: time.Now().Add(authorizationCacheTTL),
+	}
+
+	for _, org := range user.Organizations {
+		if org.Organization.UniqueId.String() != organizationId {
+			continue
+		}
+
+		organization := org.Organization
+		details.Organization = &organization
+		details.AccessLevel = org.MemberDetails.AccessLevel
+
+		if org.WhatsappBusinessAccount != nil {
+			wapiClient, err := NewWapiClient(app, *org.WhatsappBusinessAccount)
+			if err != nil {
+				app.Logger.Error("error decrypting whatsapp access token", "organization_id", organizationId, "error", err.Error())
+			} else {
+				details.WapiClient = wapiClient
+			}
+
+			if org.IsAiEnabled {
+				// * initialize AI service
+				aiApiKey, err := app.Secrets.Decrypt(org.AiApiKey)
+				if err != nil {
+					app.Logger.Error("error decrypting ai api key", "organization_id", organizationId, "error", err.Error())
+				} else {
+					details.AiService = ai_service.NewAiService(&app.Logger, app.Redis, app.Db, aiApiKey)
+				}
+			}
+		}
+
+		// * extracting out mutually exclusive permissions from the assigned roles
+		permissionSet := make(map[api_types.RolePermissionEnum]struct{})
+		assignedRoles := make([]model.OrganizationRole, 0, len(org.MemberDetails.AssignedRoles))
+		for _, roleAssignment := range org.MemberDetails.AssignedRoles {
+			assignedRoles = append(assignedRoles, roleAssignment.Role)
+			permissionArray := strings.Split(roleAssignment.Role.Permissions, ",")
+			for _, permission := range permissionArray {
+				perm := api_types.RolePermissionEnum(permission)
+				if _, exists := permissionSet[perm]; !exists {
+					permissionSet[perm] = struct{}{}
+					details.Permissions = append(details.Permissions, perm)
+				}
+			}
+		}
+
+		details.IsTwoFactorRequired = two_factor_service.IsRequired(organization, details.AccessLevel, assignedRoles)
+	}
+
+	authorizationCacheMutex.Lock()
+	_pruneAuthorizationCache()
+	authorizationCache[cacheKey] = details
+	authorizationCacheMutex.Unlock()
+
+	return details, nil
+}
+
+// _isValidApiKey checks that a token without a session is an api key which has not been regenerated since
+func _isValidApiKey(ctx echo.Context, app *interfaces.App, token string) bool {
+	tokenHash := session_service.HashToken(token)
+
+	authorizationCacheMutex.RLock()
+	expiresAt, ok := validatedApiKeys[tokenHash]
+	authorizationCacheMutex.RUnlock()
+
+	if ok && expiresAt.After(time.Now()) {
+		return true
+	}
+
+	var apiKey model.ApiKey
+	err := SELECT(table.ApiKey.UniqueId).
+		FROM(table.ApiKey).
+		WHERE(table.ApiKey.Key.EQ(String(token))).
+		LIMIT(1).
+		QueryContext(ctx.Request().Context(), app.Db, &apiKey)
+
+	if err != nil {
+		return false
+	}
+
+	authorizationCacheMutex.Lock()
+	validatedApiKeys[tokenHash] = time.Now().Add(authorizationCacheTTL)
+	authorizationCacheMutex.Unlock()
+
+	return true
+}
+
 func authMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(ctx echo.Context) error {
 		app := ctx.Get("app").(*interfaces.App)
@@ -77,192 +432,147 @@ func authMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 			return []byte(app.Koa.String("app.jwt_secret")), nil
 		})
 
-		if err != nil {
+		if err != nil || !parsedPayload.Valid {
 			return echo.NewHTTPError(echo.ErrUnauthorized.Code, "Unauthorized access")
 		}
 
-		if parsedPayload.Valid {
-			castedPayload := parsedPayload.Claims.(jwt.MapClaims)
-			type UserWithOrgDetails struct {
-				model.User
-				Organizations []struct {
-					model.Organization
-					WhatsappBusinessAccount *model.WhatsappBusinessAccount
-					MemberDetails           struct {
-						model.OrganizationMember
-						AssignedRoles []struct {
-							model.RoleAssignment
-							role model.OrganizationRole
-						}
-					}
-				}
-			}
+		castedPayload := parsedPayload.Claims.(jwt.MapClaims)
 
-			email := castedPayload["email"].(string)
-			uniqueId := castedPayload["unique_id"].(string)
-			organizationId := castedPayload["organization_id"].(string)
+		email, _ := castedPayload["email"].(string)
+		uniqueId, _ := castedPayload["unique_id"].(string)
+		organizationId, _ := castedPayload["organization_id"].(string)
+		sessionId, _ := castedPayload["session_id"].(string)
 
-			if email == "" || uniqueId == "" {
-				return echo.NewHTTPError(echo.ErrUnauthorized.Code, "Unauthorized access")
-			}
+		if email == "" || uniqueId == "" {
+			return echo.NewHTTPError(echo.ErrUnauthorized.Code, "Unauthorized access")
+		}
 
-			user := UserWithOrgDetails{}
-			userQuery := SELECT(
-				table.User.AllColumns,
-				table.OrganizationMember.AllColumns,
-				table.Organization.AllColumns,
-				table.WhatsappBusinessAccount.AllColumns,
-				table.RoleAssignment.AllColumns,
-				table.OrganizationRole.AllColumns,
-			).FROM(
-				table.User.
-					LEFT_JOIN(table.OrganizationMember, table.User.UniqueId.EQ(table.OrganizationMember.UserId)).
-					LEFT_JOIN(table.Organization, table.Organization.UniqueId.EQ(table.OrganizationMember.OrganizationId)).
-					LEFT_JOIN(table.WhatsappBusinessAccount, table.WhatsappBusinessAccount.OrganizationId.EQ(table.Organization.UniqueId)).
-					LEFT_JOIN(table.RoleAssignment, table.OrganizationMember.UniqueId.EQ(table.RoleAssignment.OrganizationMemberId)).
-					LEFT_JOIN(table.OrganizationRole, table.RoleAssignment.OrganizationRoleId.EQ(table.OrganizationRole.UniqueId)),
-			).WHERE(
-				table.User.Email.EQ(String(email)),
-			)
-
-			userQuery.QueryContext(ctx.Request().Context(), app.Db, &user)
-
-			if user.User.UniqueId.String() == "" || user.User.Status != model.UserAccountStatusEnum_Active {
-				app.Logger.Info("user not found or inactive")
-				return echo.NewHTTPError(echo.ErrUnauthorized.Code, "Unauthorized access")
+		if sessionId != "" {
+			if session_service.IsSessionRevoked(app.Redis, sessionId) {
+				return echo.NewHTTPError(echo.ErrUnauthorized.Code, "Session has been revoked")
 			}
+		} else if !_isValidApiKey(ctx, app, authToken) {
+			// * tokens without a session are only accepted if they are an api key
+			return echo.NewHTTPError(echo.ErrUnauthorized.Code, "Unauthorized access")
+		}
 
-			// ! TODO: fetch the integrations and enabled integration for the users and feed the booleans flags to the context
-
-			if organizationId == "" {
-				return next(interfaces.ContextWithSession{
-					Context: ctx,
-					App:     *app,
-					Session: interfaces.ContextSession{
-						Token: authToken,
-						User: interfaces.ContextUser{
-							UniqueId: user.User.UniqueId.String(),
-							Username: user.User.Username,
-							Email:    user.User.Email,
-							Name:     user.User.Name,
-						},
+		details, err := _fetchAuthorizationDetails(ctx, app, uniqueId, organizationId)
+
+		if err != nil || details.User.UniqueId.String() == uuid.Nil.String() || details.User.Status != model.UserAccountStatusEnum_Active {
+			app.Logger.Info("user not found or inactive")
+			return echo.NewHTTPError(echo.ErrUnauthorized.Code, "Unauthorized access")
+		}
+
+		// ! TODO: fetch the integrations and enabled integration for the users and feed the booleans flags to the context
+
+		if organizationId == "" {
+			return next(interfaces.ContextWithSession{
+				Context: ctx,
+				App:     *app,
+				Session: interfaces.ContextSession{
+					Token:     authToken,
+					SessionId: sessionId,
+					User: interfaces.ContextUser{
+						UniqueId: details.User.UniqueId.String(),
+						Username: details.User.Username,
+						Email:    details.User.Email,
+						Name:     details.User.Name,
 					},
-				})
-			}
+				},
+			})
+		}
+
+		if details.Organization == nil {
+			return echo.NewHTTPError(echo.ErrUnauthorized.Code, "Unauthorized access")
+		}
+
+		var routeMetadata interfaces.RouteMetaData
+		metadata := ctx.Get("routeMetaData")
+		if meta, ok := metadata.(interfaces.RouteMetaData); ok {
+			routeMetadata = meta
+		}
+
+		// * the app is shared between requests, so the organization specific clients are set on a copy of it
+		requestApp := *app
+		if details.WapiClient != nil {
+			requestApp.WapiClient = details.WapiClient
+		}
+		if details.AiService != nil {
+			requestApp.AiService = details.AiService
+		}
 
-			for _, org := range user.Organizations {
-				if org.Organization.UniqueId.String() == organizationId {
-					var routeMetadata interfaces.RouteMetaData
-					metadata := ctx.Get("routeMetaData")
-					if meta, ok := metadata.(interfaces.RouteMetaData); ok {
-						routeMetadata = meta
-					}
-
-					var wapiClient *wapi.Client
-
-					if org.WhatsappBusinessAccount != nil {
-						wapiClient = wapi.New(&wapi.ClientConfig{
-							BusinessAccountId: org.WhatsappBusinessAccount.AccountId,
-							ApiAccessToken:    org.WhatsappBusinessAccount.AccessToken,
-							WebhookSecret:     org.WhatsappBusinessAccount.WebhookSecret,
-						})
-
-						if org.IsAiEnabled {
-							// * initialize AI service
-							ai_service := ai_service.NewAiService(&app.Logger, app.Redis, app.Db, org.AiApiKey)
-							app.AiService = ai_service
-						}
-
-						app.WapiClient = wapiClient
-					}
-
-					// create a set of all permissions this user has
-					if org.MemberDetails.AccessLevel == model.UserPermissionLevelEnum_Owner ||
-						routeMetadata.RequiredPermission == nil ||
-						len(routeMetadata.RequiredPermission) == 0 {
-						return next(interfaces.ContextWithSession{
-							Context: ctx,
-							App:     *app,
-							Session: interfaces.ContextSession{
-								Token: authToken,
-								User: interfaces.ContextUser{
-									UniqueId:       user.User.UniqueId.String(),
-									Username:       user.User.Username,
-									Email:          user.User.Email,
-									Role:           api_types.UserPermissionLevelEnum(org.MemberDetails.AccessLevel),
-									Name:           user.User.Name,
-									OrganizationId: org.Organization.UniqueId.String(),
-								},
-							},
-						})
-					}
-
-					userCurrentOrgPermissions := []api_types.RolePermissionEnum{}
-					permissionSet := make(map[api_types.RolePermissionEnum]struct{})
-
-					// * extracting out mutually exclusive permissions from the assigned roles
-					for _, roleAssignment := range org.MemberDetails.AssignedRoles {
-						permissionArray := strings.Split(roleAssignment.role.Permissions, ",")
-						for _, permission := range permissionArray {
-							perm := api_types.RolePermissionEnum(permission)
-							if _, exists := permissionSet[perm]; !exists {
-								permissionSet[perm] = struct{}{}
-								userCurrentOrgPermissions = append(userCurrentOrgPermissions, perm)
-							}
-						}
-					}
-
-					// * now check if user  has required permission in the list of permissions it has
-					for _, requiredPermission := range routeMetadata.RequiredPermission {
-						if !_isPermissionInList(requiredPermission, userCurrentOrgPermissions) {
-							return echo.NewHTTPError(echo.ErrUnauthorized.Code, "You are not authorized to access this resource.")
-						}
-					}
-
-					return next(interfaces.ContextWithSession{
-						Context: ctx,
-						App:     *app,
-						Session: interfaces.ContextSession{
-							Token: authToken,
-							User: interfaces.ContextUser{
-								UniqueId:       user.User.UniqueId.String(),
-								Username:       user.User.Username,
-								Email:          user.User.Email,
-								Role:           api_types.UserPermissionLevelEnum(org.MemberDetails.AccessLevel),
-								Name:           user.User.Name,
-								OrganizationId: org.Organization.UniqueId.String(),
-							},
-						},
-					})
+		// * now check if user has required permission in the list of permissions it has
+		if details.AccessLevel != model.UserPermissionLevelEnum_Owner {
+			for _, requiredPermission := range routeMetadata.RequiredPermission {
+				if !_isPermissionInList(requiredPermission, details.Permissions) {
+					return echo.NewHTTPError(echo.ErrUnauthorized.Code, "You are not authorized to access this resource.")
 				}
 			}
+		}
 
-			return echo.NewHTTPError(echo.ErrUnauthorized.Code, "Unauthorized access")
-		} else {
-			return echo.NewHTTPError(echo.ErrUnauthorized.Code, "Unauthorized access")
+		// * api keys are not bound to a login, so the two factor policy only applies to sessions
+		if sessionId != "" && details.IsTwoFactorRequired && !two_factor_service.IsEnabled(details.User) && _isSensitiveRoute(routeMetadata) {
+			return echo.NewHTTPError(http.StatusForbidden, "Your organization requires two factor authentication, please enable it to access this resource.")
 		}
+
+		return next(interfaces.ContextWithSession{
+			Context: ctx,
+			App:     requestApp,
+			Session: interfaces.ContextSession{
+				Token:     authToken,
+				SessionId: sessionId,
+				User: interfaces.ContextUser{
+					UniqueId:       details.User.UniqueId.String(),
+					Username:       details.User.Username,
+					Email:          details.User.Email,
+					Role:           api_types.UserPermissionLevelEnum(details.AccessLevel),
+					Name:           details.User.Name,
+					OrganizationId: details.Organization.UniqueId.String(),
+				},
+			},
+		})
 	}
 }
 
+// rateLimiter limits the requests an ip address makes to a route to the MaxRequests of the route within its window, the
+// counts are kept in redis so that all instances share them. Only the routes with an enforced limit are limited, these
+// are refused when redis can not be reached, the same as authMiddleware refuses sessions then.
 func rateLimiter(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(context echo.Context) error {
 		app := context.Get("app").(*interfaces.App)
-		routerMetaData := context.Get("routeMetaData")
+		routeMetaData, ok := context.Get("routeMetaData").(interfaces.RouteMetaData)
 
-		if routerMetaData == nil {
-			// skip rate limiting if no metadata is found
-		} else {
-			routerMetaData, ok := routerMetaData.(interfaces.RouteMetaData)
+		if !ok || !routeMetaData.RateLimitConfig.IsEnforced || routeMetaData.RateLimitConfig.MaxRequests <= 0 || routeMetaData.RateLimitConfig.WindowTimeInMs <= 0 {
+			return next(context)
+		}
 
-			if !ok {
-				// skip rate limiting if metadata is not of the correct type
-			} else {
-				rateLimitConfig := routerMetaData.RateLimitConfig
-				// ! TODO: redis rate limit here
-				app.Logger.Info("rateLimitConfig: ", rateLimitConfig)
-			}
+		rateLimitConfig := routeMetaData.RateLimitConfig
 
+		if app.Redis == nil {
+			return echo.NewHTTPError(http.StatusServiceUnavailable, "Service unavailable")
 		}
+
+		// * fixed windows, the key of a window is dropped once it is over
+		window := time.Duration(rateLimitConfig.WindowTimeInMs) * time.Millisecond
+		windowIndex := time.Now().UnixMilli() / rateLimitConfig.WindowTimeInMs
+		key := app.Redis.ComputeCacheKey(
+			app.Constants.RedisEventChannelName+":rate_limit",
+			strings.Join([]string{context.Request().Method, context.Path(), context.RealIP(), strconv.FormatInt(windowIndex, 10)}, ":"),
+			"requests",
+		)
+
+		requests, err := app.Redis.CountRequest(key, window)
+		if err != nil {
+			app.Logger.Error("error counting the requests of the rate limit", "path", context.Path(), "error", err.Error())
+			return echo.NewHTTPError(http.StatusServiceUnavailable, "Service unavailable")
+		}
+
+		if requests > int64(rateLimitConfig.MaxRequests) {
+			windowEnd := time.UnixMilli((windowIndex + 1) * rateLimitConfig.WindowTimeInMs)
+			context.Response().Header().Set("Retry-After", strconv.Itoa(int(time.Until(windowEnd).Seconds())+1))
+			return echo.NewHTTPError(http.StatusTooManyRequests, "Too many requests, try again later")
+		}
+
 		return next(context)
 	}
 }
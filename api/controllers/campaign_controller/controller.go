@@ -2,6 +2,7 @@ package campaign_controller
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -10,6 +11,8 @@ import (
 	"github.com/labstack/echo/v4"
 	controller "github.com/wapikit/wapikit/api/controllers"
 	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/segment_service"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
 	"github.com/wapikit/wapikit/internal/core/utils"
 	"github.com/wapikit/wapikit/internal/interfaces"
 
@@ -195,6 +198,16 @@ func getCampaigns(context interfaces.ContextWithSession) error {
 
 	fmt.Println("Campaigns: ", dest)
 
+	campaignIds := make([]uuid.UUID, 0, len(dest))
+	for _, campaign := range dest {
+		campaignIds = append(campaignIds, campaign.UniqueId)
+	}
+
+	campaignSegments, err := segment_service.FetchCampaignSegments(context.Request().Context(), context.App.Db, campaignIds)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	campaignsToReturn := []api_types.CampaignSchema{}
 
 	if len(dest) > 0 {
@@ -234,7 +247,7 @@ func getCampaigns(context interfaces.ContextWithSession) error {
 				var unmarshalled map[string]interface{}
 				err := json.Unmarshal([]byte(*campaign.TemplateMessageComponentParameters), &unmarshalled)
 				if err != nil {
-					context.App.Logger.Error("error unmarshalling template component parameters: %v", err.Error())
+					context.App.Logger.Error("error unmarshalling template component parameters", "error", err.Error())
 				}
 				templateComponentParameters = &unmarshalled
 			}
@@ -247,6 +260,7 @@ func getCampaigns(context interfaces.ContextWithSession) error {
 				TemplateMessageId:           campaign.MessageTemplateId,
 				Status:                      status,
 				Lists:                       lists,
+				Segments:                    segmentsToSchema(campaignSegments[campaign.UniqueId]),
 				Tags:                        tags,
 				SentAt:                      nil,
 				UniqueId:                    campaign.UniqueId.String(),
@@ -304,6 +318,15 @@ func createNewCampaign(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	// * tags and lists of other organizations can not be linked to the campaign
+	if _, err := controller.CheckOwnedIds(context, tenant_service.Tag, payload.Tags); err != nil {
+		return err
+	}
+
+	if _, err := controller.CheckOwnedIds(context, tenant_service.ContactList, payload.ListIds); err != nil {
+		return err
+	}
+
 	var newCampaign model.Campaign
 	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
 	if err != nil {
@@ -335,7 +358,7 @@ func createNewCampaign(context interfaces.ContextWithSession) error {
 		for _, payloadTag := range payload.Tags {
 			tagUUID, err := uuid.Parse(payloadTag)
 			if err != nil {
-				context.App.Logger.Error("Error converting tag unique id to uuid: %v", err)
+				context.App.Logger.Error("error converting tag unique id to uuid", "error", err.Error())
 				continue
 			}
 			campaignTags = append(campaignTags, model.CampaignTag{
@@ -365,7 +388,7 @@ func createNewCampaign(context interfaces.ContextWithSession) error {
 			listUUID, err := uuid.Parse(listId)
 			fmt.Println("List UUID: ", listUUID)
 			if err != nil {
-				context.App.Logger.Error("Error converting list unique id to uuid: %v", err)
+				context.App.Logger.Error("error converting list unique id to uuid", "error", err.Error())
 				continue
 			}
 			campaignLists = append(campaignLists, model.CampaignList{
@@ -387,6 +410,17 @@ func createNewCampaign(context interfaces.ContextWithSession) error {
 		}
 	}
 
+	// 4. Insert Campaign Segments (if any)
+	if payload.SegmentIds != nil && len(*payload.SegmentIds) > 0 {
+		err = segment_service.SetCampaignSegments(context.Request().Context(), tx, organizationUuid, newCampaign.UniqueId, *payload.SegmentIds)
+		if err != nil {
+			if errors.Is(err, segment_service.ErrSegmentNotFound) {
+				return echo.NewHTTPError(http.StatusBadRequest, "Segment not found")
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
 	err = tx.Commit()
 
 	if err != nil {
@@ -417,7 +451,15 @@ func getCampaignById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid Campaign Id")
 	}
 
-	campaignUuid, _ := uuid.Parse(campaignId)
+	campaignUuid, err := uuid.Parse(campaignId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid Campaign Id")
+	}
+
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
 
 	sqlStatement := SELECT(
 		table.Campaign.AllColumns,
@@ -432,7 +474,7 @@ func getCampaignById(context interfaces.ContextWithSession) error {
 			LEFT_JOIN(table.CampaignList, table.CampaignList.CampaignId.EQ(UUID(campaignUuid))).
 			LEFT_JOIN(table.ContactList, table.ContactList.UniqueId.EQ(table.CampaignList.ContactListId))).
 		WHERE(
-			table.Campaign.UniqueId.EQ(UUID(campaignUuid)),
+			tenant_service.Campaign.ById(orgUuid, campaignUuid),
 		)
 
 	var campaignResponse struct {
@@ -441,10 +483,13 @@ func getCampaignById(context interfaces.ContextWithSession) error {
 		Lists []model.ContactList
 	}
 
-	sqlStatement.Query(context.App.Db, &campaignResponse)
+	err = sqlStatement.QueryContext(context.Request().Context(), context.App.Db, &campaignResponse)
 
-	if campaignResponse.UniqueId.String() == "" {
-		return echo.NewHTTPError(http.StatusNotFound, "Campaign not found")
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusNotFound, "Campaign not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
 	status := api_types.CampaignStatusEnum(campaignResponse.Status)
@@ -458,7 +503,7 @@ func getCampaignById(context interfaces.ContextWithSession) error {
 		var unmarshalled map[string]interface{}
 		err := json.Unmarshal([]byte(*campaignResponse.TemplateMessageComponentParameters), &unmarshalled)
 		if err != nil {
-			context.App.Logger.Error("error unmarshalling template component parameters: %v", err.Error())
+			context.App.Logger.Error("error unmarshalling template component parameters", "error", err.Error())
 		}
 		templateComponentParameters = &unmarshalled
 	}
@@ -490,6 +535,11 @@ func getCampaignById(context interfaces.ContextWithSession) error {
 		}
 	}
 
+	campaignSegments, err := segment_service.FetchCampaignSegments(context.Request().Context(), context.App.Db, []uuid.UUID{campaignResponse.UniqueId})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	return context.JSON(http.StatusOK, api_types.GetCampaignByIdResponseSchema{
 		Campaign: api_types.CampaignSchema{
 			CreatedAt:                   campaignResponse.CreatedAt,
@@ -501,6 +551,7 @@ func getCampaignById(context interfaces.ContextWithSession) error {
 			PhoneNumberInUse:            &campaignResponse.PhoneNumber,
 			Status:                      status,
 			Lists:                       lists,
+			Segments:                    segmentsToSchema(campaignSegments[campaignResponse.UniqueId]),
 			Tags:                        tags,
 			SentAt:                      nil,
 			TemplateComponentParameters: templateComponentParameters,
@@ -568,18 +619,18 @@ func updateCampaignById(context interfaces.ContextWithSession) error {
 			table.Campaign.UPDATE(table.Campaign.Status).
 				WHERE(table.Campaign.UniqueId.EQ(UUID(campaignUuid)))
 
-		if *payload.Status == api_types.Finished {
+		if *payload.Status == api_types.CampaignStatusEnumFinished {
 			return echo.NewHTTPError(http.StatusBadRequest, "user can not finish a campaign, but can cancel it.")
 		}
 
-		if *payload.Status == api_types.Running {
+		if *payload.Status == api_types.CampaignStatusEnumRunning {
 			updateStatusQuery.SET(table.Campaign.Status.SET(utils.EnumExpression(model.CampaignStatusEnum_Running.String())))
 			_, err := updateStatusQuery.ExecContext(context.Request().Context(), context.App.Db)
 			if err != nil {
 				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 			}
 
-		} else if *payload.Status == api_types.Paused || *payload.Status == api_types.Cancelled {
+		} else if *payload.Status == api_types.CampaignStatusEnumPaused || *payload.Status == api_types.CampaignStatusEnumCancelled {
 			if campaign.Status != model.CampaignStatusEnum_Running {
 				return echo.NewHTTPError(http.StatusBadRequest, "Cannot pause a campaign that is not running")
 			}
@@ -606,6 +657,15 @@ func updateCampaignById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Cannot update a running campaign, pause the campaign first to update")
 	}
 
+	// * tags and lists of other organizations can not be linked to the campaign
+	if _, err := controller.CheckOwnedIds(context, tenant_service.Tag, payload.Tags); err != nil {
+		return err
+	}
+
+	if _, err := controller.CheckOwnedIds(context, tenant_service.ContactList, payload.ListIds); err != nil {
+		return err
+	}
+
 	// * ====== SYNC TAGS FOR THIS CAMPAIGN ======
 
 	if len(campaign.Tags) != 0 || len(payload.Tags) != 0 {
@@ -765,11 +825,24 @@ func updateCampaignById(context interfaces.ContextWithSession) error {
 		}
 	}
 
+	// * ====== SYNC SEGMENTS FOR THIS CAMPAIGN ======
+
+	// * the segments are only replaced when given, so that older clients do not clear them
+	if payload.SegmentIds != nil {
+		err = segment_service.SetCampaignSegments(context.Request().Context(), context.App.Db, orgUuid, campaignUuid, *payload.SegmentIds)
+		if err != nil {
+			if errors.Is(err, segment_service.ErrSegmentNotFound) {
+				return echo.NewHTTPError(http.StatusBadRequest, "Segment not found")
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
 	// * use default = {} if no parameters are provided
 	var stringifiedParameters []byte
 	stringifiedParameters, err = json.Marshal(payload.TemplateComponentParameters)
 	if err != nil {
-		context.App.Logger.Error("Error marshalling template component parameters: %v", err.Error())
+		context.App.Logger.Error("error marshalling template component parameters", "error", err.Error())
 	}
 
 	// pitch in default if no parameters are provided
@@ -836,6 +909,11 @@ func deleteCampaignById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Cannot delete a running campaign, pause the campaign first to delete")
 	}
 
+	_, err = table.CampaignSegment.DELETE().WHERE(table.CampaignSegment.CampaignId.EQ(UUID(campaignUuid))).ExecContext(context.Request().Context(), context.App.Db)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	result, err := table.Campaign.DELETE().WHERE(table.Campaign.UniqueId.EQ(String(campaignId))).ExecContext(context.Request().Context(), context.App.Db)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
@@ -847,3 +925,11 @@ func deleteCampaignById(context interfaces.ContextWithSession) error {
 
 	return context.String(http.StatusOK, "OK")
 }
+
+func segmentsToSchema(segments []model.Segment) *[]api_types.SegmentSchema {
+	segmentsToReturn := make([]api_types.SegmentSchema, 0, len(segments))
+	for _, segment := range segments {
+		segmentsToReturn = append(segmentsToReturn, segment_service.ToSchema(segment))
+	}
+	return &segmentsToReturn
+}
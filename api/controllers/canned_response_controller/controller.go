@@ -0,0 +1,735 @@
+package canned_response_controller
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	controller "github.com/wapikit/wapikit/api/controllers"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/canned_response_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+	"github.com/wapikit/wapikit/internal/interfaces"
+
+	"github.com/go-jet/jet/qrm"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+type CannedResponseController struct {
+	controller.BaseController `json:"-,inline"`
+}
+
+func NewCannedResponseController() *CannedResponseController {
+	return &CannedResponseController{
+		BaseController: controller.BaseController{
+			Name:        "Canned Response Controller",
+			RestApiPath: "/api/canned-responses",
+			Routes: []interfaces.Route{
+				{
+					Path:                    "/api/canned-responses",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getCannedResponses),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetCannedResponse,
+						},
+					},
+				},
+				{
+					Path:                    "/api/canned-responses",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(createCannedResponse),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.CreateCannedResponse,
+						},
+					},
+				},
+				{
+					Path:                    "/api/canned-responses/:id",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getCannedResponseById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetCannedResponse,
+						},
+					},
+				},
+				{
+					Path:                    "/api/canned-responses/:id",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(updateCannedResponseById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateCannedResponse,
+						},
+					},
+				},
+				{
+					Path:                    "/api/canned-responses/:id",
+					Method:                  http.MethodDelete,
+					Handler:                 interfaces.HandlerWithSession(deleteCannedResponseById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.DeleteCannedResponse,
+						},
+					},
+				},
+				{
+					Path:                    "/api/canned-responses/:id/render",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(renderCannedResponse),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetCannedResponse,
+							api_types.GetConversation,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type cannedResponseWithTags struct {
+	model.CannedResponse
+	Tags []model.Tag
+}
+
+func getCannedResponses(context interfaces.ContextWithSession) error {
+	params := new(api_types.GetCannedResponsesParams)
+	if err := utils.BindQueryParams(context, params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
+
+	visibleCondition := table.CannedResponse.OrganizationId.EQ(UUID(member.OrganizationId)).
+		AND(
+			table.CannedResponse.OrganizationMemberId.IS_NULL().
+				OR(table.CannedResponse.OrganizationMemberId.EQ(UUID(member.UniqueId))),
+		)
+
+	whereCondition := visibleCondition
+
+	if params.Folder != nil && *params.Folder != "" {
+		whereCondition = whereCondition.AND(table.CannedResponse.Folder.EQ(String(*params.Folder)))
+	}
+
+	if params.TagId != nil && *params.TagId != "" {
+		tagUuid, err := uuid.Parse(*params.TagId)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid tag id")
+		}
+
+		whereCondition = whereCondition.AND(
+			EXISTS(
+				SELECT(table.CannedResponseTag.TagId).
+					FROM(table.CannedResponseTag).
+					WHERE(
+						table.CannedResponseTag.CannedResponseId.EQ(table.CannedResponse.UniqueId).
+							AND(table.CannedResponseTag.TagId.EQ(UUID(tagUuid))),
+					),
+			),
+		)
+	}
+
+	if params.Query != nil && *params.Query != "" {
+		searchPattern := String("%" + strings.ToLower(*params.Query) + "%")
+		whereCondition = whereCondition.AND(
+			LOWER(table.CannedResponse.Name).LIKE(searchPattern).
+				OR(LOWER(table.CannedResponse.Shortcode).LIKE(searchPattern)),
+		)
+	}
+
+	var cannedResponses []cannedResponseWithTags
+
+	err = SELECT(table.CannedResponse.AllColumns, table.Tag.AllColumns).
+		FROM(table.CannedResponse.
+			LEFT_JOIN(table.CannedResponseTag, table.CannedResponseTag.CannedResponseId.EQ(table.CannedResponse.UniqueId)).
+			LEFT_JOIN(table.Tag, table.Tag.UniqueId.EQ(table.CannedResponseTag.TagId)),
+		).
+		WHERE(whereCondition).
+		ORDER_BY(table.CannedResponse.Folder.ASC().NULLS_FIRST(), table.CannedResponse.Name.ASC()).
+		QueryContext(context.Request().Context(), context.App.Db, &cannedResponses)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var folders []struct {
+		Folder string `alias:"CannedResponse.Folder"`
+	}
+
+	// * folders are listed regardless of the filters, so that the inbox can show all of them
+	err = SELECT(table.CannedResponse.Folder).
+		DISTINCT().
+		FROM(table.CannedResponse).
+		WHERE(visibleCondition.AND(table.CannedResponse.Folder.IS_NOT_NULL())).
+		ORDER_BY(table.CannedResponse.Folder.ASC()).
+		QueryContext(context.Request().Context(), context.App.Db, &folders)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	responseToReturn := api_types.GetCannedResponsesResponseSchema{
+		CannedResponses: []api_types.CannedResponseSchema{},
+		Folders:         []string{},
+	}
+
+	for _, cannedResponse := range cannedResponses {
+		responseToReturn.CannedResponses = append(responseToReturn.CannedResponses, buildCannedResponse(cannedResponse))
+	}
+
+	for _, folder := range folders {
+		responseToReturn.Folders = append(responseToReturn.Folders, folder.Folder)
+	}
+
+	return context.JSON(http.StatusOK, responseToReturn)
+}
+
+func getCannedResponseById(context interfaces.ContextWithSession) error {
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
+
+	cannedResponse, err := fetchCannedResponse(context, member)
+	if err != nil {
+		return err
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetCannedResponseByIdResponseSchema{
+		CannedResponse: buildCannedResponse(*cannedResponse),
+	})
+}
+
+func createCannedResponse(context interfaces.ContextWithSession) error {
+	payload := new(api_types.NewCannedResponseSchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
+
+	cannedResponse, tagUuids, err := parseCannedResponsePayload(context, member, uuid.Nil, payload)
+	if err != nil {
+		return err
+	}
+
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	var insertedCannedResponse cannedResponseWithTags
+
+	err = table.CannedResponse.INSERT(table.CannedResponse.MutableColumns).
+		MODEL(cannedResponse).
+		RETURNING(table.CannedResponse.AllColumns).
+		QueryContext(context.Request().Context(), tx, &insertedCannedResponse.CannedResponse)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	insertedCannedResponse.Tags, err = replaceCannedResponseTags(context, tx, insertedCannedResponse.UniqueId, tagUuids)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusCreated, api_types.CreateCannedResponseResponseSchema{
+		CannedResponse: buildCannedResponse(insertedCannedResponse),
+	})
+}
+
+func updateCannedResponseById(context interfaces.ContextWithSession) error {
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
+
+	existingCannedResponse, err := fetchCannedResponse(context, member)
+	if err != nil {
+		return err
+	}
+
+	payload := new(api_types.NewCannedResponseSchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	cannedResponse, tagUuids, err := parseCannedResponsePayload(context, member, existingCannedResponse.UniqueId, payload)
+	if err != nil {
+		return err
+	}
+
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	var updatedCannedResponse cannedResponseWithTags
+
+	err = table.CannedResponse.UPDATE(
+		table.CannedResponse.OrganizationMemberId,
+		table.CannedResponse.Name,
+		table.CannedResponse.Shortcode,
+		table.CannedResponse.Content,
+		table.CannedResponse.Folder,
+		table.CannedResponse.Attachments,
+		table.CannedResponse.UpdatedAt,
+	).
+		MODEL(cannedResponse).
+		WHERE(table.CannedResponse.UniqueId.EQ(UUID(existingCannedResponse.UniqueId))).
+		RETURNING(table.CannedResponse.AllColumns).
+		QueryContext(context.Request().Context(), tx, &updatedCannedResponse.CannedResponse)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	updatedCannedResponse.Tags, err = replaceCannedResponseTags(context, tx, updatedCannedResponse.UniqueId, tagUuids)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.UpdateCannedResponseByIdResponseSchema{
+		CannedResponse: buildCannedResponse(updatedCannedResponse),
+	})
+}
+
+func deleteCannedResponseById(context interfaces.ContextWithSession) error {
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
+
+	cannedResponse, err := fetchCannedResponse(context, member)
+	if err != nil {
+		return err
+	}
+
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	_, err = table.CannedResponseTag.DELETE().
+		WHERE(table.CannedResponseTag.CannedResponseId.EQ(UUID(cannedResponse.UniqueId))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * links welcoming their contacts with the response keep capturing contacts without a welcome message
+	_, err = table.ContactCaptureSource.UPDATE(table.ContactCaptureSource.WelcomeCannedResponseId).
+		SET(NULL).
+		WHERE(table.ContactCaptureSource.WelcomeCannedResponseId.EQ(UUID(cannedResponse.UniqueId))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	_, err = table.CannedResponse.DELETE().
+		WHERE(table.CannedResponse.UniqueId.EQ(UUID(cannedResponse.UniqueId))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.DeleteCannedResponseByIdResponseSchema{
+		Data: true,
+	})
+}
+
+func renderCannedResponse(context interfaces.ContextWithSession) error {
+	payload := new(api_types.RenderCannedResponseSchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	conversationUuid, err := uuid.Parse(payload.ConversationId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid conversation id")
+	}
+
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
+
+	cannedResponse, err := fetchCannedResponse(context, member)
+	if err != nil {
+		return err
+	}
+
+	var conversation struct {
+		model.Conversation
+		Contact      model.Contact
+		Organization model.Organization
+	}
+
+	err = SELECT(table.Conversation.AllColumns, table.Contact.AllColumns, table.Organization.AllColumns).
+		FROM(table.Conversation.
+			INNER_JOIN(table.Contact, table.Contact.UniqueId.EQ(table.Conversation.ContactId)).
+			INNER_JOIN(table.Organization, table.Organization.UniqueId.EQ(table.Conversation.OrganizationId)),
+		).
+		WHERE(
+			table.Conversation.UniqueId.EQ(UUID(conversationUuid)).
+				AND(table.Conversation.OrganizationId.EQ(UUID(member.OrganizationId))),
+		).
+		LIMIT(1).
+		QueryContext(context.Request().Context(), context.App.Db, &conversation)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusNotFound, "Conversation not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	variables := canned_response_service.RenderContext{
+		Contact:      conversation.Contact,
+		Conversation: conversation.Conversation,
+		Organization: conversation.Organization,
+		AgentName:    context.Session.User.Name,
+		AgentEmail:   context.Session.User.Email,
+	}.Variables()
+
+	content, unresolvedVariables := canned_response_service.Render(cannedResponse.Content, variables)
+
+	attachments := parseAttachments(cannedResponse.CannedResponse)
+	for index, attachment := range attachments {
+		if attachment.Caption == nil {
+			continue
+		}
+
+		caption, unresolvedCaptionVariables := canned_response_service.Render(*attachment.Caption, variables)
+		attachments[index].Caption = &caption
+
+		for _, variable := range unresolvedCaptionVariables {
+			if !slices.Contains(unresolvedVariables, variable) {
+				unresolvedVariables = append(unresolvedVariables, variable)
+			}
+		}
+	}
+
+	return context.JSON(http.StatusOK, api_types.RenderCannedResponseResponseSchema{
+		Content:             content,
+		Attachments:         attachments,
+		UnresolvedVariables: unresolvedVariables,
+	})
+}
+
+// fetchCannedResponse loads the response from the id param of the route, personal responses of other members are not found
+func fetchCannedResponse(context interfaces.ContextWithSession, member *model.OrganizationMember) (*cannedResponseWithTags, error) {
+	cannedResponseUuid, err := uuid.Parse(context.Param("id"))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid canned response id")
+	}
+
+	var cannedResponse cannedResponseWithTags
+
+	err = SELECT(table.CannedResponse.AllColumns, table.Tag.AllColumns).
+		FROM(table.CannedResponse.
+			LEFT_JOIN(table.CannedResponseTag, table.CannedResponseTag.CannedResponseId.EQ(table.CannedResponse.UniqueId)).
+			LEFT_JOIN(table.Tag, table.Tag.UniqueId.EQ(table.CannedResponseTag.TagId)),
+		).
+		WHERE(table.CannedResponse.UniqueId.EQ(UUID(cannedResponseUuid))).
+		QueryContext(context.Request().Context(), context.App.Db, &cannedResponse)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Canned response not found")
+		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if cannedResponse.OrganizationId != member.OrganizationId {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "Canned response not found")
+	}
+
+	if cannedResponse.OrganizationMemberId != nil && *cannedResponse.OrganizationMemberId != member.UniqueId {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "Canned response not found")
+	}
+
+	return &cannedResponse, nil
+}
+
+// parseCannedResponsePayload validates the payload, the shortcode must not be used by another response in the same scope
+func parseCannedResponsePayload(context interfaces.ContextWithSession, member *model.OrganizationMember, existingId uuid.UUID, payload *api_types.NewCannedResponseSchema) (model.CannedResponse, []uuid.UUID, error) {
+	cannedResponse := model.CannedResponse{
+		OrganizationId: member.OrganizationId,
+		Name:           strings.TrimSpace(payload.Name),
+		Shortcode:      canned_response_service.NormalizeShortcode(payload.Shortcode),
+		Content:        payload.Content,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if cannedResponse.Name == "" {
+		return cannedResponse, nil, echo.NewHTTPError(http.StatusBadRequest, "Name of the canned response is required")
+	}
+
+	if cannedResponse.Shortcode == "" || strings.ContainsAny(cannedResponse.Shortcode, " \t\n") {
+		return cannedResponse, nil, echo.NewHTTPError(http.StatusBadRequest, "Shortcode is required and can not contain spaces")
+	}
+
+	if strings.TrimSpace(cannedResponse.Content) == "" {
+		return cannedResponse, nil, echo.NewHTTPError(http.StatusBadRequest, "Content of the canned response is required")
+	}
+
+	if payload.IsPersonal != nil && *payload.IsPersonal {
+		cannedResponse.OrganizationMemberId = &member.UniqueId
+	}
+
+	if payload.Folder != nil && strings.TrimSpace(*payload.Folder) != "" {
+		folder := strings.TrimSpace(*payload.Folder)
+		cannedResponse.Folder = &folder
+	}
+
+	if payload.Attachments != nil && len(*payload.Attachments) > 0 {
+		for _, attachment := range *payload.Attachments {
+			switch attachment.MediaType {
+			case api_types.Image, api_types.Video, api_types.Audio, api_types.Document:
+			default:
+				return cannedResponse, nil, echo.NewHTTPError(http.StatusBadRequest, "Attachments can only be images, videos, audios or documents")
+			}
+
+			hasMediaId := attachment.MediaId != nil && *attachment.MediaId != ""
+			hasLink := attachment.Link != nil && *attachment.Link != ""
+			if !hasMediaId && !hasLink {
+				return cannedResponse, nil, echo.NewHTTPError(http.StatusBadRequest, "Attachments require a media id or a link")
+			}
+		}
+
+		attachments, err := json.Marshal(payload.Attachments)
+		if err != nil {
+			return cannedResponse, nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		stringifiedAttachments := string(attachments)
+		cannedResponse.Attachments = &stringifiedAttachments
+	}
+
+	scopeCondition := table.CannedResponse.OrganizationMemberId.IS_NULL()
+	if cannedResponse.OrganizationMemberId != nil {
+		scopeCondition = table.CannedResponse.OrganizationMemberId.EQ(UUID(member.UniqueId))
+	}
+
+	var conflictingResponses []model.CannedResponse
+
+	err := SELECT(table.CannedResponse.UniqueId).
+		FROM(table.CannedResponse).
+		WHERE(
+			table.CannedResponse.OrganizationId.EQ(UUID(member.OrganizationId)).
+				AND(table.CannedResponse.Shortcode.EQ(String(cannedResponse.Shortcode))).
+				AND(table.CannedResponse.UniqueId.NOT_EQ(UUID(existingId))).
+				AND(scopeCondition),
+		).
+		LIMIT(1).
+		QueryContext(context.Request().Context(), context.App.Db, &conflictingResponses)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return cannedResponse, nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if len(conflictingResponses) > 0 {
+		return cannedResponse, nil, echo.NewHTTPError(http.StatusConflict, "Another canned response already uses this shortcode")
+	}
+
+	if payload.TagIds == nil || len(*payload.TagIds) == 0 {
+		return cannedResponse, nil, nil
+	}
+
+	tagUuids := make([]uuid.UUID, 0, len(*payload.TagIds))
+	tagIds := make([]Expression, 0, len(*payload.TagIds))
+	for _, tagId := range *payload.TagIds {
+		tagUuid, err := uuid.Parse(tagId)
+		if err != nil {
+			return cannedResponse, nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid tag id")
+		}
+		tagUuids = append(tagUuids, tagUuid)
+		tagIds = append(tagIds, UUID(tagUuid))
+	}
+
+	var tags []model.Tag
+
+	err = SELECT(table.Tag.UniqueId).
+		FROM(table.Tag).
+		WHERE(
+			table.Tag.UniqueId.IN(tagIds...).
+				AND(table.Tag.OrganizationId.EQ(UUID(member.OrganizationId))),
+		).
+		QueryContext(context.Request().Context(), context.App.Db, &tags)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return cannedResponse, nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	isOrganizationTag := make(map[uuid.UUID]bool, len(tags))
+	for _, tag := range tags {
+		isOrganizationTag[tag.UniqueId] = true
+	}
+
+	for _, tagUuid := range tagUuids {
+		if !isOrganizationTag[tagUuid] {
+			return cannedResponse, nil, echo.NewHTTPError(http.StatusBadRequest, "Tag not found")
+		}
+	}
+
+	return cannedResponse, tagUuids, nil
+}
+
+func replaceCannedResponseTags(context interfaces.ContextWithSession, db *sql.Tx, cannedResponseId uuid.UUID, tagUuids []uuid.UUID) ([]model.Tag, error) {
+	_, err := table.CannedResponseTag.DELETE().
+		WHERE(table.CannedResponseTag.CannedResponseId.EQ(UUID(cannedResponseId))).
+		ExecContext(context.Request().Context(), db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tagUuids) == 0 {
+		return []model.Tag{}, nil
+	}
+
+	cannedResponseTags := make([]model.CannedResponseTag, 0, len(tagUuids))
+	tagIds := make([]Expression, 0, len(tagUuids))
+	for _, tagUuid := range tagUuids {
+		cannedResponseTags = append(cannedResponseTags, model.CannedResponseTag{
+			CannedResponseId: cannedResponseId,
+			TagId:            tagUuid,
+			CreatedAt:        time.Now(),
+			UpdatedAt:        time.Now(),
+		})
+		tagIds = append(tagIds, UUID(tagUuid))
+	}
+
+	_, err = table.CannedResponseTag.INSERT(table.CannedResponseTag.AllColumns).
+		MODELS(cannedResponseTags).
+		ON_CONFLICT(table.CannedResponseTag.CannedResponseId, table.CannedResponseTag.TagId).
+		DO_NOTHING().
+		ExecContext(context.Request().Context(), db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []model.Tag
+
+	err = SELECT(table.Tag.AllColumns).
+		FROM(table.Tag).
+		WHERE(table.Tag.UniqueId.IN(tagIds...)).
+		QueryContext(context.Request().Context(), db, &tags)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func parseAttachments(cannedResponse model.CannedResponse) []api_types.CannedResponseAttachmentSchema {
+	attachments := []api_types.CannedResponseAttachmentSchema{}
+
+	if cannedResponse.Attachments == nil {
+		return attachments
+	}
+
+	// * attachments are validated before they are stored, a response with unreadable attachments is still usable without them
+	_ = json.Unmarshal([]byte(*cannedResponse.Attachments), &attachments)
+
+	return attachments
+}
+
+func buildCannedResponse(cannedResponse cannedResponseWithTags) api_types.CannedResponseSchema {
+	cannedResponseToReturn := api_types.CannedResponseSchema{
+		UniqueId:    cannedResponse.UniqueId.String(),
+		CreatedAt:   cannedResponse.CreatedAt,
+		Name:        cannedResponse.Name,
+		Shortcode:   cannedResponse.Shortcode,
+		Content:     cannedResponse.Content,
+		Folder:      cannedResponse.Folder,
+		IsPersonal:  cannedResponse.OrganizationMemberId != nil,
+		Tags:        []api_types.TagSchema{},
+		Attachments: parseAttachments(cannedResponse.CannedResponse),
+	}
+
+	for _, tag := range cannedResponse.Tags {
+		cannedResponseToReturn.Tags = append(cannedResponseToReturn.Tags, api_types.TagSchema{
+			UniqueId: tag.UniqueId.String(),
+			Name:     tag.Label,
+		})
+	}
+
+	return cannedResponseToReturn
+}
@@ -0,0 +1,441 @@
+package contact_capture_controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	controller "github.com/wapikit/wapikit/api/controllers"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/contact_capture_service"
+	"github.com/wapikit/wapikit/internal/core/contact_field_service"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+	"github.com/wapikit/wapikit/internal/interfaces"
+
+	"github.com/go-jet/jet/qrm"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+type ContactCaptureController struct {
+	controller.BaseController `json:"-,inline"`
+}
+
+func NewContactCaptureController() *ContactCaptureController {
+	return &ContactCaptureController{
+		BaseController: controller.BaseController{
+			Name:        "Contact Capture Controller",
+			RestApiPath: "/api/contact-capture-sources",
+			Routes: []interfaces.Route{
+				{
+					Path:                    "/api/contact-capture-sources",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getContactCaptureSources),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetList,
+						},
+					},
+				},
+				{
+					Path:                    "/api/contact-capture-sources",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(createContactCaptureSource),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.CreateList,
+						},
+					},
+				},
+				{
+					Path:                    "/api/contact-capture-sources/:id",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(updateContactCaptureSourceById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateList,
+						},
+					},
+				},
+				{
+					Path:                    "/api/contact-capture-sources/:id",
+					Method:                  http.MethodDelete,
+					Handler:                 interfaces.HandlerWithSession(deleteContactCaptureSourceById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.DeleteList,
+						},
+					},
+				},
+				{
+					Path:                    "/api/opt-in-forms/:code",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithoutSession(getOptInForm),
+					IsAuthorizationRequired: false,
+					MetaData: interfaces.RouteMetaData{
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+							IsEnforced:     true,
+						},
+					},
+				},
+				{
+					Path:                    "/api/opt-in-forms/:code",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithoutSession(submitOptInForm),
+					IsAuthorizationRequired: false,
+					MetaData: interfaces.RouteMetaData{
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+							IsEnforced:     true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getContactCaptureSources(context interfaces.ContextWithSession) error {
+	params := new(api_types.GetContactCaptureSourcesParams)
+	if err := utils.BindQueryParams(context, params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
+
+	condition := tenant_service.ContactCaptureSource.Scope(orgUuid)
+
+	if params.ListId != nil {
+		listIds, err := controller.CheckOwnedIds(context, tenant_service.ContactList, []string{*params.ListId})
+		if err != nil {
+			return err
+		}
+		condition = condition.AND(table.ContactCaptureSource.ContactListId.EQ(UUID(listIds[0])))
+	}
+
+	var sources []model.ContactCaptureSource
+
+	err = SELECT(table.ContactCaptureSource.AllColumns).
+		FROM(table.ContactCaptureSource).
+		WHERE(condition).
+		ORDER_BY(table.ContactCaptureSource.CreatedAt.ASC()).
+		QueryContext(context.Request().Context(), context.App.Db, &sources)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	sourcesToReturn := make([]api_types.ContactCaptureSourceSchema, 0, len(sources))
+	for _, source := range sources {
+		sourcesToReturn = append(sourcesToReturn, contact_capture_service.ToSchema(source, context.App.Constants.RootURL))
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetContactCaptureSourcesResponseSchema{
+		Sources: sourcesToReturn,
+	})
+}
+
+func createContactCaptureSource(context interfaces.ContextWithSession) error {
+	payload := new(api_types.CreateContactCaptureSourceJSONRequestBody)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
+
+	listIds, err := controller.CheckOwnedIds(context, tenant_service.ContactList, []string{payload.ListId})
+	if err != nil {
+		return err
+	}
+
+	code, err := contact_capture_service.NewCode()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	source := model.ContactCaptureSource{
+		OrganizationId: orgUuid,
+		ContactListId:  listIds[0],
+		Type:           model.ContactCaptureSourceTypeEnum(payload.Type),
+		Name:           payload.Name,
+		Code:           code,
+		IsActive:       true,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	err = applySettings(context, &source, payload.PhoneNumber, payload.PrefilledMessage, payload.ConsentText, payload.Attributes, payload.WelcomeCannedResponseId)
+	if err != nil {
+		return err
+	}
+
+	var insertedSource model.ContactCaptureSource
+
+	err = table.ContactCaptureSource.INSERT(table.ContactCaptureSource.MutableColumns).
+		MODEL(source).
+		RETURNING(table.ContactCaptureSource.AllColumns).
+		QueryContext(context.Request().Context(), context.App.Db, &insertedSource)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.CreateContactCaptureSourceResponseSchema{
+		Source: contact_capture_service.ToSchema(insertedSource, context.App.Constants.RootURL),
+	})
+}
+
+// updateContactCaptureSourceById changes the settings of the source, the list, the type and the code are kept as the
+// form or link may have been shared already
+func updateContactCaptureSourceById(context interfaces.ContextWithSession) error {
+	var source model.ContactCaptureSource
+
+	if _, err := controller.FetchOwned(context, tenant_service.ContactCaptureSource, &source); err != nil {
+		return err
+	}
+
+	payload := new(api_types.UpdateContactCaptureSourceByIdJSONRequestBody)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	source.Name = payload.Name
+	source.IsActive = payload.IsActive
+	source.UpdatedAt = time.Now()
+
+	err := applySettings(context, &source, payload.PhoneNumber, payload.PrefilledMessage, payload.ConsentText, payload.Attributes, payload.WelcomeCannedResponseId)
+	if err != nil {
+		return err
+	}
+
+	var updatedSource model.ContactCaptureSource
+
+	err = table.ContactCaptureSource.UPDATE(
+		table.ContactCaptureSource.Name,
+		table.ContactCaptureSource.PhoneNumber,
+		table.ContactCaptureSource.PrefilledMessage,
+		table.ContactCaptureSource.ConsentText,
+		table.ContactCaptureSource.Attributes,
+		table.ContactCaptureSource.WelcomeCannedResponseId,
+		table.ContactCaptureSource.IsActive,
+		table.ContactCaptureSource.UpdatedAt,
+	).
+		MODEL(source).
+		WHERE(table.ContactCaptureSource.UniqueId.EQ(UUID(source.UniqueId))).
+		RETURNING(table.ContactCaptureSource.AllColumns).
+		QueryContext(context.Request().Context(), context.App.Db, &updatedSource)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.UpdateContactCaptureSourceByIdResponseSchema{
+		Source: contact_capture_service.ToSchema(updatedSource, context.App.Constants.RootURL),
+	})
+}
+
+// deleteContactCaptureSourceById removes the source only, the contacts captured through it stay in the list and keep
+// their consent, the evidence of the consent holds the consent text of the form
+func deleteContactCaptureSourceById(context interfaces.ContextWithSession) error {
+	var source model.ContactCaptureSource
+
+	if _, err := controller.FetchOwned(context, tenant_service.ContactCaptureSource, &source); err != nil {
+		return err
+	}
+
+	_, err := table.ContactConsent.UPDATE(table.ContactConsent.ContactCaptureSourceId).
+		SET(NULL).
+		WHERE(table.ContactConsent.ContactCaptureSourceId.EQ(UUID(source.UniqueId))).
+		ExecContext(context.Request().Context(), context.App.Db)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	_, err = table.ContactCaptureSource.DELETE().
+		WHERE(table.ContactCaptureSource.UniqueId.EQ(UUID(source.UniqueId))).
+		ExecContext(context.Request().Context(), context.App.Db)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.DeleteContactCaptureSourceByIdResponseSchema{
+		Data: true,
+	})
+}
+
+// applySettings sets the settings of the payload on the source and validates them, the welcome message must be a canned
+// response of the organization and the attributes must match the contact fields of the organization
+func applySettings(context interfaces.ContextWithSession, source *model.ContactCaptureSource, phoneNumber, prefilledMessage, consentText *string, attributes *map[string]interface{}, welcomeCannedResponseId *string) error {
+	source.PhoneNumber = nonEmpty(phoneNumber)
+	source.PrefilledMessage = nonEmpty(prefilledMessage)
+	source.ConsentText = nonEmpty(consentText)
+	source.WelcomeCannedResponseId = nil
+
+	if welcomeCannedResponseId := nonEmpty(welcomeCannedResponseId); welcomeCannedResponseId != nil {
+		cannedResponseIds, err := controller.CheckOwnedIds(context, tenant_service.CannedResponse, []string{*welcomeCannedResponseId})
+		if err != nil {
+			return err
+		}
+		source.WelcomeCannedResponseId = &cannedResponseIds[0]
+	}
+
+	sourceAttributes := map[string]interface{}{}
+	if attributes != nil {
+		sourceAttributes = *attributes
+	}
+
+	fields, err := contact_field_service.FetchFields(context.Request().Context(), context.App.Db, source.OrganizationId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := contact_field_service.ValidateValues(fields, sourceAttributes); err != nil {
+		if errors.Is(err, contact_field_service.ErrInvalidAttributes) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	attributesJson, err := json.Marshal(sourceAttributes)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	stringAttributes := string(attributesJson)
+	source.Attributes = &stringAttributes
+
+	if err := contact_capture_service.Validate(*source); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return nil
+}
+
+func nonEmpty(value *string) *string {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil
+	}
+	trimmedValue := strings.TrimSpace(*value)
+	return &trimmedValue
+}
+
+func getOptInForm(context interfaces.ContextWithoutSession) error {
+	source, err := fetchOptInForm(context)
+	if err != nil {
+		return err
+	}
+
+	var organization model.Organization
+
+	err = SELECT(table.Organization.AllColumns).
+		FROM(table.Organization).
+		WHERE(table.Organization.UniqueId.EQ(UUID(source.OrganizationId))).
+		QueryContext(context.Request().Context(), context.App.Db, &organization)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	consentText := ""
+	if source.ConsentText != nil {
+		consentText = *source.ConsentText
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetOptInFormResponseSchema{
+		Form: api_types.OptInFormSchema{
+			Code:             source.Code,
+			Name:             source.Name,
+			OrganizationName: organization.Name,
+			ConsentText:      consentText,
+		},
+	})
+}
+
+// submitOptInForm captures the contact through the form, the consent is recorded along with where it was given from
+func submitOptInForm(context interfaces.ContextWithoutSession) error {
+	source, err := fetchOptInForm(context)
+	if err != nil {
+		return err
+	}
+
+	payload := new(api_types.SubmitOptInFormJSONRequestBody)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if !payload.Consent {
+		return echo.NewHTTPError(http.StatusBadRequest, "Consent is required to submit the form")
+	}
+
+	_, err = contact_capture_service.SubmitForm(context.Request().Context(), context.App.Db, *source, contact_capture_service.Submission{
+		Name:        payload.Name,
+		PhoneNumber: payload.Phone,
+		IpAddress:   context.RealIP(),
+		UserAgent:   context.Request().UserAgent(),
+	})
+
+	if err != nil {
+		if errors.Is(err, contact_capture_service.ErrInvalidSubmission) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * the response is the same whatever the consent of the contact was, the form does not tell who is subscribed already
+	return context.JSON(http.StatusOK, api_types.SubmitOptInFormResponseSchema{
+		Message:         "Thank you, send the prefilled message on WhatsApp to confirm your subscription",
+		ConfirmationUrl: contact_capture_service.ConfirmationUrl(*source),
+	})
+}
+
+func fetchOptInForm(context interfaces.ContextWithoutSession) (*model.ContactCaptureSource, error) {
+	source, err := contact_capture_service.FetchByCode(context.Request().Context(), context.App.Db, context.Param("code"), model.ContactCaptureSourceTypeEnum_Form)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if source == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "Form not found")
+	}
+
+	return source, nil
+}
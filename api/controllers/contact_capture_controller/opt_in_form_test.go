@@ -0,0 +1,317 @@
+package contact_capture_controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/contact_capture_service"
+	"github.com/wapikit/wapikit/internal/core/contact_consent_service"
+	"github.com/wapikit/wapikit/internal/interfaces"
+	"github.com/wapikit/wapikit/internal/testutil"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+type optInFormTest struct {
+	t      *testing.T
+	app    *interfaces.App
+	server *echo.Echo
+	source model.ContactCaptureSource
+}
+
+func newOptInFormTest(t *testing.T) *optInFormTest {
+	app := testutil.NewApp(t)
+	organization := testutil.SeedOrganization(t, app)
+	ctx := context.Background()
+
+	var list model.ContactList
+
+	err := table.ContactList.INSERT(table.ContactList.MutableColumns).
+		MODEL(model.ContactList{
+			OrganizationId: organization.Organization.UniqueId,
+			Name:           "Subscribers",
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}).
+		RETURNING(table.ContactList.AllColumns).
+		QueryContext(ctx, app.Db, &list)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := contact_capture_service.NewCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	phoneNumber := "919876543210"
+	consentText := "I agree to receive offers on WhatsApp"
+	attributes := `{"city": "Mumbai", "plan": "free"}`
+	var source model.ContactCaptureSource
+
+	err = table.ContactCaptureSource.INSERT(table.ContactCaptureSource.MutableColumns).
+		MODEL(model.ContactCaptureSource{
+			OrganizationId: organization.Organization.UniqueId,
+			ContactListId:  list.UniqueId,
+			Type:           model.ContactCaptureSourceTypeEnum_Form,
+			Name:           "Newsletter",
+			Code:           code,
+			PhoneNumber:    &phoneNumber,
+			ConsentText:    &consentText,
+			Attributes:     &attributes,
+			IsActive:       true,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}).
+		RETURNING(table.ContactCaptureSource.AllColumns).
+		QueryContext(ctx, app.Db, &source)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &optInFormTest{
+		t:      t,
+		app:    app,
+		server: testutil.NewServer(app, NewContactCaptureController()),
+		source: source,
+	}
+}
+
+func (test *optInFormTest) submit(name, phoneNumber string) *httptest.ResponseRecorder {
+	test.t.Helper()
+	return test.submitForwardedFor(name, phoneNumber, "")
+}
+
+// submitForwardedFor submits the form with the X-Forwarded-For header a client can set to pass for another ip address
+func (test *optInFormTest) submitForwardedFor(name, phoneNumber, forwardedFor string) *httptest.ResponseRecorder {
+	test.t.Helper()
+
+	var requestBody bytes.Buffer
+	err := json.NewEncoder(&requestBody).Encode(api_types.OptInFormSubmissionSchema{
+		Name:    name,
+		Phone:   phoneNumber,
+		Consent: true,
+	})
+
+	if err != nil {
+		test.t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/opt-in-forms/"+test.source.Code, &requestBody)
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if forwardedFor != "" {
+		request.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+	}
+	recorder := httptest.NewRecorder()
+	test.server.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (test *optInFormTest) seedContact(name, phoneNumber, attributes string) model.Contact {
+	test.t.Helper()
+
+	var contact model.Contact
+
+	err := table.Contact.INSERT(table.Contact.MutableColumns).
+		MODEL(model.Contact{
+			OrganizationId: test.source.OrganizationId,
+			Name:           name,
+			PhoneNumber:    phoneNumber,
+			Attributes:     &attributes,
+			Status:         model.ContactStatusEnum_Active,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}).
+		RETURNING(table.Contact.AllColumns).
+		QueryContext(context.Background(), test.app.Db, &contact)
+
+	if err != nil {
+		test.t.Fatal(err)
+	}
+
+	return contact
+}
+
+func (test *optInFormTest) contact(phoneNumber string) model.Contact {
+	test.t.Helper()
+
+	var contact model.Contact
+
+	err := SELECT(table.Contact.AllColumns).
+		FROM(table.Contact).
+		WHERE(
+			table.Contact.OrganizationId.EQ(UUID(test.source.OrganizationId)).
+				AND(table.Contact.PhoneNumber.EQ(String(phoneNumber))),
+		).
+		QueryContext(context.Background(), test.app.Db, &contact)
+
+	if err != nil {
+		test.t.Fatal(err)
+	}
+
+	return contact
+}
+
+func (test *optInFormTest) marketingConsent(contact model.Contact) *model.ContactConsent {
+	test.t.Helper()
+
+	consents, err := contact_consent_service.FetchConsents(context.Background(), test.app.Db, contact.UniqueId)
+	if err != nil {
+		test.t.Fatal(err)
+	}
+
+	for _, consent := range consents {
+		if consent.Category == model.ContactConsentCategoryEnum_Marketing {
+			return &consent
+		}
+	}
+
+	return nil
+}
+
+// newPhoneNumber returns a valid indian mobile number no other test uses, as it is normalized
+func newPhoneNumber() string {
+	return fmt.Sprintf("919%09d", uuid.New().ID()%1000000000)
+}
+
+func expectStatus(t *testing.T, response *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if response.Code != status {
+		t.Fatalf("expected status %d, got %d %s", status, response.Code, response.Body.String())
+	}
+}
+
+func TestOptInFormConsentIsPendingUntilTheContactConfirmsIt(t *testing.T) {
+	test := newOptInFormTest(t)
+	phoneNumber := newPhoneNumber()
+
+	response := test.submit("New Subscriber", "+"+phoneNumber)
+	expectStatus(t, response, http.StatusOK)
+
+	var body api_types.SubmitOptInFormResponseSchema
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	if body.ConfirmationUrl != contact_capture_service.ConfirmationUrl(test.source) {
+		t.Fatalf("expected the confirmation link of the form, got %s", body.ConfirmationUrl)
+	}
+
+	contact := test.contact(phoneNumber)
+	if contact.Name != "New Subscriber" {
+		t.Fatalf("expected the contact to be created with the submitted name, got %s", contact.Name)
+	}
+
+	consent := test.marketingConsent(contact)
+	if consent == nil || consent.Status != model.ContactConsentStatusEnum_Pending {
+		t.Fatalf("expected a pending marketing consent, got %+v", consent)
+	}
+
+	message := "Yes, I want to subscribe #" + test.source.Code
+	isConfirmed, err := contact_capture_service.ConfirmForm(context.Background(), test.app.Db, contact_capture_service.FindCode(message), contact, message, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consent = test.marketingConsent(contact)
+	if !isConfirmed || consent.Status != model.ContactConsentStatusEnum_Granted {
+		t.Fatalf("expected the consent to be granted once confirmed, got %+v", consent)
+	}
+}
+
+func TestOptInFormLeavesTheNameAndAttributesOfExistingContacts(t *testing.T) {
+	test := newOptInFormTest(t)
+	phoneNumber := newPhoneNumber()
+	existingContact := test.seedContact("Known Contact", phoneNumber, `{"city": "Pune"}`)
+
+	expectStatus(t, test.submit("Someone Else", phoneNumber), http.StatusOK)
+
+	contact := test.contact(phoneNumber)
+	if contact.Name != existingContact.Name {
+		t.Fatalf("expected the name to stay %s, got %s", existingContact.Name, contact.Name)
+	}
+
+	var attributes map[string]interface{}
+	if err := json.Unmarshal([]byte(*contact.Attributes), &attributes); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(attributes) != 1 || attributes["city"] != "Pune" {
+		t.Fatalf("expected the attributes to stay as they were, got %s", *contact.Attributes)
+	}
+}
+
+func TestOptInFormDoesNotOverwriteAnOptOutKeyword(t *testing.T) {
+	test := newOptInFormTest(t)
+	phoneNumber := newPhoneNumber()
+	contact := test.seedContact("Opted Out", phoneNumber, "{}")
+
+	err := contact_consent_service.OptOut(context.Background(), test.app.Db, contact, map[string]interface{}{"message": "STOP"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, test.submit("Opted Out", phoneNumber), http.StatusOK)
+
+	consent := test.marketingConsent(contact)
+	if consent.Status != model.ContactConsentStatusEnum_Revoked || consent.Source != model.ContactConsentSourceEnum_Keyword {
+		t.Fatalf("expected the opt-out to be kept, got %+v", consent)
+	}
+
+	message := "Yes, I want to subscribe #" + test.source.Code
+	isConfirmed, err := contact_capture_service.ConfirmForm(context.Background(), test.app.Db, test.source.Code, contact, message, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if isConfirmed {
+		t.Fatal("expected no consent to be confirmed for a contact which opted out")
+	}
+}
+
+func TestOptInFormSubmissionsAreRateLimited(t *testing.T) {
+	test := newOptInFormTest(t)
+
+	// * every submission pretends to come from another ip address, the header is not trusted without a proxy
+	var response *httptest.ResponseRecorder
+	for submission := 0; submission < 20; submission++ {
+		response = test.submitForwardedFor("Subscriber", newPhoneNumber(), fmt.Sprintf("203.0.113.%d", submission+1))
+		if response.Code != http.StatusOK {
+			break
+		}
+	}
+
+	expectStatus(t, response, http.StatusTooManyRequests)
+
+	if response.Header().Get("Retry-After") == "" {
+		t.Fatal("expected the time to retry after")
+	}
+}
+
+func TestOnlyEnforcedRateLimitsAreApplied(t *testing.T) {
+	app := testutil.NewApp(t)
+	organization := testutil.SeedOrganization(t, app)
+	server := testutil.NewServer(app, NewContactCaptureController())
+
+	// * the list of capture sources allows 60 requests a minute, which is not enforced
+	for request := 0; request < 70; request++ {
+		recorder := httptest.NewRecorder()
+		httpRequest := httptest.NewRequest(http.MethodGet, "/api/contact-capture-sources?page=1&per_page=10", nil)
+		httpRequest.Header.Set("x-access-token", organization.Token)
+		server.ServeHTTP(recorder, httpRequest)
+		expectStatus(t, recorder, http.StatusOK)
+	}
+}
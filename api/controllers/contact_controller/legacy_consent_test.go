@@ -0,0 +1,201 @@
+package contact_controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/contact_consent_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+	"github.com/wapikit/wapikit/internal/interfaces"
+	"github.com/wapikit/wapikit/internal/testutil"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+type legacyConsentTest struct {
+	t            *testing.T
+	app          *interfaces.App
+	server       *echo.Echo
+	organization testutil.Organization
+}
+
+func newLegacyConsentTest(t *testing.T) *legacyConsentTest {
+	app := testutil.NewApp(t)
+
+	return &legacyConsentTest{
+		t:            t,
+		app:          app,
+		server:       testutil.NewServer(app, NewContactController()),
+		organization: testutil.SeedOrganization(t, app),
+	}
+}
+
+func (test *legacyConsentTest) grant(token, statement string) *httptest.ResponseRecorder {
+	test.t.Helper()
+
+	var requestBody bytes.Buffer
+	err := json.NewEncoder(&requestBody).Encode(api_types.GrantLegacyConsentsSchema{
+		Statement: statement,
+	})
+
+	if err != nil {
+		test.t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/contacts/consents/legacy", &requestBody)
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set("x-access-token", token)
+	recorder := httptest.NewRecorder()
+	test.server.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (test *legacyConsentTest) seedContact(status model.ContactStatusEnum) model.Contact {
+	test.t.Helper()
+
+	var contact model.Contact
+	attributes := "{}"
+
+	err := table.Contact.INSERT(table.Contact.MutableColumns).
+		MODEL(model.Contact{
+			OrganizationId: test.organization.Organization.UniqueId,
+			Name:           "Contact",
+			PhoneNumber:    "91" + uuid.NewString()[:8],
+			Attributes:     &attributes,
+			Status:         status,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}).
+		RETURNING(table.Contact.AllColumns).
+		QueryContext(context.Background(), test.app.Db, &contact)
+
+	if err != nil {
+		test.t.Fatal(err)
+	}
+
+	return contact
+}
+
+func (test *legacyConsentTest) marketingConsent(contact model.Contact) *model.ContactConsent {
+	test.t.Helper()
+
+	consents, err := contact_consent_service.FetchConsents(context.Background(), test.app.Db, contact.UniqueId)
+	if err != nil {
+		test.t.Fatal(err)
+	}
+
+	for _, consent := range consents {
+		if consent.Category == model.ContactConsentCategoryEnum_Marketing {
+			return &consent
+		}
+	}
+
+	return nil
+}
+
+func expectLegacyStatus(t *testing.T, response *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if response.Code != status {
+		t.Fatalf("expected status %d, got %d %s", status, response.Code, response.Body.String())
+	}
+}
+
+func TestLegacyConsentsAreOnlyGrantedToContactsWithoutAConsent(t *testing.T) {
+	test := newLegacyConsentTest(t)
+	ctx := context.Background()
+
+	contactWithoutConsent := test.seedContact(model.ContactStatusEnum_Active)
+	blockedContact := test.seedContact(model.ContactStatusEnum_Blocked)
+	optedOutContact := test.seedContact(model.ContactStatusEnum_Active)
+
+	err := contact_consent_service.OptOut(ctx, test.app.Db, optedOutContact, map[string]interface{}{"message": "STOP"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statement := "Customers opted in at the checkout of the online store"
+	response := test.grant(test.organization.Token, statement)
+	expectLegacyStatus(t, response, http.StatusOK)
+
+	var body api_types.GrantLegacyConsentsResponseSchema
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	if body.GrantedCount != 1 {
+		t.Fatalf("expected one consent to be granted, got %d", body.GrantedCount)
+	}
+
+	consent := test.marketingConsent(contactWithoutConsent)
+	if consent == nil || consent.Status != model.ContactConsentStatusEnum_Granted || consent.Source != model.ContactConsentSourceEnum_Legacy {
+		t.Fatalf("expected a granted legacy consent, got %+v", consent)
+	}
+
+	var evidence map[string]interface{}
+	if err := json.Unmarshal([]byte(*consent.Evidence), &evidence); err != nil {
+		t.Fatal(err)
+	}
+
+	if evidence["statement"] != statement {
+		t.Fatalf("expected the statement to be the evidence, got %s", *consent.Evidence)
+	}
+
+	if consent := test.marketingConsent(blockedContact); consent != nil {
+		t.Fatalf("expected no consent for the blocked contact, got %+v", consent)
+	}
+
+	consent = test.marketingConsent(optedOutContact)
+	if consent.Status != model.ContactConsentStatusEnum_Revoked || consent.Source != model.ContactConsentSourceEnum_Keyword {
+		t.Fatalf("expected the opt-out to be kept, got %+v", consent)
+	}
+
+	var auditLogs []model.AuditLog
+
+	err = SELECT(table.AuditLog.AllColumns).
+		FROM(table.AuditLog).
+		WHERE(
+			table.AuditLog.OrganizationId.EQ(UUID(test.organization.Organization.UniqueId)).
+				AND(table.AuditLog.Action.EQ(utils.EnumExpression(model.AuditLogActionEnum_LegacyConsentsGranted.String()))),
+		).
+		QueryContext(ctx, test.app.Db, &auditLogs)
+
+	if err != nil || len(auditLogs) != 1 {
+		t.Fatalf("expected the grant to be in the audit log, got %d entries, %v", len(auditLogs), err)
+	}
+}
+
+func TestLegacyConsentsNeedTheStatementOfTheOwner(t *testing.T) {
+	test := newLegacyConsentTest(t)
+	test.seedContact(model.ContactStatusEnum_Active)
+
+	expectLegacyStatus(t, test.grant(test.organization.Token, "  "), http.StatusBadRequest)
+
+	member := testutil.SeedUser(t, test.app, "member-"+uuid.NewString()[:8]+"@example.com")
+
+	_, err := table.OrganizationMember.INSERT(table.OrganizationMember.MutableColumns).
+		MODEL(model.OrganizationMember{
+			AccessLevel:    model.UserPermissionLevelEnum_Member,
+			OrganizationId: test.organization.Organization.UniqueId,
+			UserId:         member.UniqueId,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}).
+		ExecContext(context.Background(), test.app.Db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	memberToken := testutil.Login(t, test.app, member, &test.organization.Organization.UniqueId)
+	expectLegacyStatus(t, test.grant(memberToken, "Customers opted in at the checkout"), http.StatusForbidden)
+}
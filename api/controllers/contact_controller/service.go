@@ -1,10 +1,13 @@
 package contact_controller
 
 import (
-	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -13,6 +16,16 @@ import (
 	"github.com/labstack/echo/v4"
 	controller "github.com/wapikit/wapikit/api/controllers"
 	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/audit_service"
+	"github.com/wapikit/wapikit/internal/core/background_job_service"
+	"github.com/wapikit/wapikit/internal/core/contact_activity_service"
+	"github.com/wapikit/wapikit/internal/core/contact_consent_service"
+	"github.com/wapikit/wapikit/internal/core/contact_duplicate_service"
+	"github.com/wapikit/wapikit/internal/core/contact_field_service"
+	"github.com/wapikit/wapikit/internal/core/contact_import_service"
+	"github.com/wapikit/wapikit/internal/core/contact_lifecycle_service"
+	"github.com/wapikit/wapikit/internal/core/contact_timeline_service"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
 	"github.com/wapikit/wapikit/internal/core/utils"
 	"github.com/wapikit/wapikit/internal/interfaces"
 
@@ -129,6 +142,152 @@ func NewContactController() *ContactController {
 						},
 					},
 				},
+				{
+					Path:                    "/api/contacts/export",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(exportContacts),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    5,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetContact,
+						},
+					},
+				},
+				{
+					Path:                    "/api/contacts/:id/data",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(exportContactData),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetContact,
+						},
+					},
+				},
+				{
+					Path:                    "/api/contacts/:id/timeline",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getContactTimeline),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetContact,
+						},
+					},
+				},
+				{
+					Path:                    "/api/contacts/:id/consents",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getContactConsents),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetContact,
+						},
+					},
+				},
+				{
+					Path:                    "/api/contacts/:id/consents",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(updateContactConsents),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateContact,
+						},
+					},
+				},
+				{
+					Path:                    "/api/contacts/consents/legacy",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(grantLegacyConsents),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Owner,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateContact,
+						},
+					},
+				},
+				{
+					Path:                    "/api/contacts/:id/erase",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(eraseContactById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.DeleteContact,
+						},
+					},
+				},
+				{
+					Path:                    "/api/contacts/:id/merge",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(mergeContacts),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateContact,
+							api_types.DeleteContact,
+						},
+					},
+				},
+				{
+					Path:                    "/api/contacts/duplicates/scan",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(scanContactDuplicates),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateContact,
+							api_types.DeleteContact,
+						},
+					},
+				},
 			},
 		},
 	}
@@ -274,6 +433,39 @@ func createNewContacts(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	// * contacts can only be added to the lists of the organization
+	payloadListIds := []string{}
+	for _, contact := range *payload {
+		payloadListIds = append(payloadListIds, contact.ListsIds...)
+	}
+
+	if _, err := controller.CheckOwnedIds(context, tenant_service.ContactList, payloadListIds); err != nil {
+		return err
+	}
+
+	fields, err := contact_field_service.FetchFields(context.Request().Context(), context.App.Db, orgUuid)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	for index := range *payload {
+		phoneNumber, err := normalizePhoneNumber((*payload)[index].Phone)
+		if err != nil {
+			return err
+		}
+		(*payload)[index].Phone = phoneNumber
+
+		attributes, err := validateAttributes(fields, (*payload)[index].Attributes)
+		if err != nil {
+			return err
+		}
+		(*payload)[index].Attributes = attributes
+
+		if err := validateConsents((*payload)[index].Consents); err != nil {
+			return err
+		}
+	}
+
 	// * insert contact into the contact table
 	contactsToInsert := []model.Contact{}
 	var insertedContacts []model.Contact
@@ -358,10 +550,55 @@ func createNewContacts(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	if len(insertedContactListContact) > 0 {
+		member, err := controller.FetchCurrentMember(context)
+		if err != nil {
+			return err
+		}
+
+		var lists []model.ContactList
+
+		err = SELECT(table.ContactList.AllColumns).
+			FROM(table.ContactList).
+			WHERE(table.ContactList.OrganizationId.EQ(UUID(orgUuid))).
+			QueryContext(context.Request().Context(), context.App.Db, &lists)
+
+		if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+
+		listNames := map[uuid.UUID]string{}
+		for _, list := range lists {
+			listNames[list.UniqueId] = list.Name
+		}
+
+		activities := make([]contact_activity_service.Activity, 0, len(insertedContactListContact))
+		for _, contactListContact := range insertedContactListContact {
+			activities = append(activities, contact_activity_service.Activity{
+				OrganizationId:       orgUuid,
+				ContactId:            contactListContact.ContactId,
+				OrganizationMemberId: &member.UniqueId,
+				Type:                 model.ContactActivityTypeEnum_ListJoined,
+				Data: contact_activity_service.ListData{
+					ContactListId: contactListContact.ContactListId,
+					Name:          listNames[contactListContact.ContactListId],
+				},
+			})
+		}
+
+		if err := contact_activity_service.Record(context.Request().Context(), context.App.Db, activities...); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	if err := recordNewContactConsents(context, *payload, insertedContacts); err != nil {
+		return err
+	}
+
 	numberOfRows := len(contactsToInsert)
 
 	response := api_types.CreateNewContactResponseSchema{
-		Message: strings.Join([]string{"Successfully created ", string(numberOfRows), " contacts"}, " "),
+		Message: strings.Join([]string{"Successfully created ", strconv.Itoa(numberOfRows), " contacts"}, " "),
 	}
 
 	return context.JSON(http.StatusOK, response)
@@ -397,6 +634,9 @@ func getContactById(context interfaces.ContextWithSession) error {
 	err := contactsQuery.QueryContext(context.Request().Context(), context.App.Db, &dest)
 
 	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusNotFound, "Contact not found")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
@@ -471,6 +711,46 @@ func updateContactById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
+	if _, err := controller.CheckOwnedIds(context, tenant_service.ContactList, payload.Lists); err != nil {
+		return err
+	}
+
+	fields, err := contact_field_service.FetchFields(context.Request().Context(), context.App.Db, orgUuid)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * the attributes replace the ones of the contact, so required fields must be present in them
+	payload.Attributes, err = validateAttributes(fields, payload.Attributes)
+	if err != nil {
+		return err
+	}
+
+	payload.Phone, err = normalizePhoneNumber(payload.Phone)
+	if err != nil {
+		return err
+	}
+
+	var contactsWithPhoneNumber []model.Contact
+
+	err = SELECT(table.Contact.UniqueId).
+		FROM(table.Contact).
+		WHERE(
+			table.Contact.OrganizationId.EQ(UUID(orgUuid)).
+				AND(table.Contact.PhoneNumber.EQ(String(payload.Phone))).
+				AND(table.Contact.UniqueId.NOT_EQ(UUID(existingContact.UniqueId))),
+		).
+		LIMIT(1).
+		QueryContext(context.Request().Context(), context.App.Db, &contactsWithPhoneNumber)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if len(contactsWithPhoneNumber) > 0 {
+		return echo.NewHTTPError(http.StatusConflict, "Another contact has this phone number, merge the two contacts instead")
+	}
+
 	// * updating lists
 
 	// * CTE for both these cases
@@ -610,6 +890,10 @@ func updateContactById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	if err := recordContactUpdate(context, existingContact.Contact, updatedContact, existingContact.ContactLists, commonListIds, insertedLists); err != nil {
+		return err
+	}
+
 	listToReturn := []api_types.ContactListSchema{}
 
 	for _, list := range existingContact.ContactLists {
@@ -655,218 +939,723 @@ func updateContactById(context interfaces.ContextWithSession) error {
 	})
 }
 
-func bulkImport(context interfaces.ContextWithSession) error {
-	logger := context.App.Logger
+// recordContactUpdate adds the lists the contact joined and left, and the changes of its status and attributes, to the
+// activity of the contact
+func recordContactUpdate(context interfaces.ContextWithSession, existingContact, updatedContact model.Contact, existingLists []struct{ model.ContactList }, keptListIds []uuid.UUID, insertedLists []model.ContactList) error {
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
 
-	r := context.Request()
+	activities := []contact_activity_service.Activity{}
 
-	err := r.ParseMultipartForm(10 << 20) // 10 MB max memory
-	if err != nil {
-		logger.Error("Error parsing form data:", err.Error(), nil)
+	newActivity := func(activityType model.ContactActivityTypeEnum, data interface{}) contact_activity_service.Activity {
+		return contact_activity_service.Activity{
+			OrganizationId:       existingContact.OrganizationId,
+			ContactId:            existingContact.UniqueId,
+			OrganizationMemberId: &member.UniqueId,
+			Type:                 activityType,
+			Data:                 data,
+		}
+	}
+
+	// * the lists of the contact which were not kept are the ones it left
+	for _, list := range existingLists {
+		kept := false
+		for _, keptListId := range keptListIds {
+			if list.UniqueId == keptListId {
+				kept = true
+				break
+			}
+		}
+		if !kept {
+			activities = append(activities, newActivity(model.ContactActivityTypeEnum_ListLeft, contact_activity_service.ListData{
+				ContactListId: list.UniqueId,
+				Name:          list.Name,
+			}))
+		}
+	}
+
+	for _, list := range insertedLists {
+		activities = append(activities, newActivity(model.ContactActivityTypeEnum_ListJoined, contact_activity_service.ListData{
+			ContactListId: list.UniqueId,
+			Name:          list.Name,
+		}))
+	}
+
+	if existingContact.Status != updatedContact.Status {
+		activities = append(activities, newActivity(model.ContactActivityTypeEnum_StatusChanged, contact_activity_service.StatusData{
+			OldStatus: existingContact.Status.String(),
+			NewStatus: updatedContact.Status.String(),
+		}))
 	}
 
-	// Get the file
-	file, _, err := r.FormFile("file")
+	attributeChanges, err := contact_activity_service.AttributeChanges(existingContact.Attributes, updatedContact.Attributes)
 	if err != nil {
-		logger.Error("Error getting file:", err.Error(), nil)
-		return echo.NewHTTPError(http.StatusBadRequest, "Error getting file")
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if len(attributeChanges) > 0 {
+		activities = append(activities, newActivity(model.ContactActivityTypeEnum_AttributesChanged, contact_activity_service.AttributesData{
+			Changes: attributeChanges,
+		}))
+	}
+
+	if err := contact_activity_service.Record(context.Request().Context(), context.App.Db, activities...); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
-	defer file.Close()
 
-	// Get the delimiter
-	payloadDelimiter := r.FormValue("delimiter")
+	return nil
+}
 
-	// Get listIds as a JSON string, then parse it into a slice
-	listIdsStr := r.FormValue("listIds")
-	var listIds []string
+// bulkImport stores the uploaded file and queues a job importing its contacts, the rows are read and validated by the job
+// so that large files do not hold up the request
+func bulkImport(context interfaces.ContextWithSession) error {
+	r := context.Request()
 
-	err = json.Unmarshal([]byte(listIdsStr), &listIds)
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
 	if err != nil {
-		logger.Error("Error parsing list IDs:", err.Error(), nil)
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid list IDs")
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	delimeter := ','
+	fileHeader, err := context.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Error getting file")
+	}
 
-	if payloadDelimiter == "" && len(payloadDelimiter) != 1 {
-		return echo.NewHTTPError(http.StatusBadRequest, "Delimiter must be a single character")
+	fileFormat, ok := contact_import_service.FileFormatOf(fileHeader.Filename)
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "Only CSV and XLSX files can be imported")
 	}
 
-	if payloadDelimiter != "" {
-		delimeter = rune((payloadDelimiter)[0])
+	parameters := contact_import_service.Parameters{
+		FileFormat:     fileFormat,
+		Delimiter:      r.FormValue("delimiter"),
+		ColumnMapping:  contact_import_service.DefaultColumnMapping,
+		DefaultCountry: strings.ToUpper(strings.TrimSpace(r.FormValue("defaultCountry"))),
+		OnConflict:     api_types.Skip,
 	}
 
-	// Initialize the CSV reader
-	reader := csv.NewReader(file)
-	reader.Comma = delimeter
+	if len([]rune(parameters.Delimiter)) > 1 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Delimiter must be a single character")
+	}
 
-	var contactToImport []model.Contact
-	orgUuid, _ := uuid.Parse(context.Session.User.OrganizationId)
+	if parameters.DefaultCountry != "" && len(parameters.DefaultCountry) != 2 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Default country must be a two letter country code")
+	}
 
-	// Skip the first row (header)
-	if _, err := reader.Read(); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid CSV format or empty file")
+	if onConflict := r.FormValue("onConflict"); onConflict != "" {
+		parameters.OnConflict = api_types.ContactImportConflictStrategyEnum(onConflict)
+		if parameters.OnConflict != api_types.Skip && parameters.OnConflict != api_types.Upsert {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid conflict strategy")
+		}
 	}
 
-	// Iterate through CSV rows and parse each contact
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
+	if columnMapping := r.FormValue("columnMapping"); columnMapping != "" {
+		parameters.ColumnMapping = contact_import_service.ColumnMapping{}
+		if err := json.Unmarshal([]byte(columnMapping), &parameters.ColumnMapping); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid column mapping")
 		}
-		if err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, "Invalid CSV format")
+		if parameters.ColumnMapping.Phone == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "The column of the phone number must be mapped")
 		}
-
-		// Assuming the CSV columns: Name, Phone, Attributes (in JSON format)
-		if len(record) < 3 {
-			return echo.NewHTTPError(http.StatusBadRequest, "Each row must contain Name, Phone, and Attributes")
+		if err := parameters.ColumnMapping.Validate(); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		}
+	}
 
-		name := record[0]
-		phone := record[1]
-		attributes := record[2]
-
-		// Convert attributes JSON string to map
-		var attrMap map[string]interface{}
-		if err := json.Unmarshal([]byte(attributes), &attrMap); err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, "Invalid JSON format in attributes")
+	if listIds := r.FormValue("listIds"); listIds != "" {
+		var listIdStrings []string
+		if err := json.Unmarshal([]byte(listIds), &listIdStrings); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid list IDs")
 		}
 
-		// Prepare the contact to insert
-		jsonAttributes, _ := json.Marshal(attrMap)
-		stringAttributes := string(jsonAttributes)
-
-		contact := model.Contact{
-			OrganizationId: orgUuid,
-			Name:           name,
-			PhoneNumber:    phone,
-			Attributes:     &stringAttributes,
-			Status:         model.ContactStatusEnum_Active,
-			CreatedAt:      time.Now(),
-			UpdatedAt:      time.Now(),
+		parameters.ListIds, err = fetchListIdsOfOrganization(context, listIdStrings)
+		if err != nil {
+			return err
 		}
-
-		contactToImport = append(contactToImport, contact)
 	}
 
-	// Insert contacts into the database
-	insertQuery := table.Contact.
-		INSERT(table.Contact.MutableColumns).
-		MODELS(contactToImport).
-		ON_CONFLICT(table.Contact.PhoneNumber, table.Contact.OrganizationId).
-		DO_NOTHING().
-		RETURNING(table.Contact.AllColumns)
-
-	var importedContact []model.Contact
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
 
-	err = insertQuery.QueryContext(context.Request().Context(), context.App.Db, &importedContact)
+	parameters.FilePath, err = saveUpload(context, fileHeader)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to insert contacts")
+		context.App.Logger.Error("error saving contact import file", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Error saving file")
 	}
 
-	if len(listIds) == 0 {
-		return context.JSON(http.StatusOK, api_types.BulkImportResponseSchema{
-			Message: strconv.Itoa(len(contactToImport)) + " contacts imported successfully",
-		})
+	job, err := background_job_service.Create(r.Context(), context.App.Db, orgUuid, &member.UniqueId, model.BackgroundJobTypeEnum_ContactImport, parameters)
+	if err != nil {
+		os.Remove(parameters.FilePath)
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	listUuids := make([]uuid.UUID, 0)
+	return context.JSON(http.StatusAccepted, api_types.BulkImportResponseSchema{
+		Message: "The import has been queued, you will be notified as it progresses",
+		Job:     background_job_service.ToSchema(*job),
+	})
+}
 
-	for _, listId := range listIds {
-		var list model.ContactList
-		listUuid, err := uuid.Parse(listId)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, "Invalid list ID format")
-		}
+// saveUpload copies the uploaded file to the upload directory, where the import job reads it from
+func saveUpload(context interfaces.ContextWithSession, fileHeader *multipart.FileHeader) (string, error) {
+	uploadDirectory := filepath.Join(context.App.Constants.UploadDirectory, "imports")
+	if err := os.MkdirAll(uploadDirectory, 0o700); err != nil {
+		return "", err
+	}
 
-		// check if the list exists
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
 
-		listQuery := table.ContactList.
-			SELECT(table.ContactList.UniqueId).
-			WHERE(table.ContactList.UniqueId.EQ(UUID(listUuid)))
+	filePath := filepath.Join(uploadDirectory, uuid.NewString()+strings.ToLower(filepath.Ext(fileHeader.Filename)))
 
-		err = listQuery.QueryContext(context.Request().Context(), context.App.Db, &list)
-		if err != nil {
-			// do nothing
-		} else {
-			listUuids = append(listUuids, listUuid)
+	destination, err := os.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer destination.Close()
+
+	if _, err := io.Copy(destination, file); err != nil {
+		os.Remove(filePath)
+		return "", err
+	}
+
+	return filePath, nil
+}
+
+// fetchListIdsOfOrganization parses the list ids, every list must belong to the organization
+func fetchListIdsOfOrganization(context interfaces.ContextWithSession, listIds []string) ([]uuid.UUID, error) {
+	if len(listIds) == 0 {
+		return nil, nil
+	}
+
+	return controller.CheckOwnedIds(context, tenant_service.ContactList, listIds)
+}
+
+// normalizePhoneNumber stores phone numbers in one format, so that differently formatted copies of a number are the same contact
+func normalizePhoneNumber(phoneNumber string) (string, error) {
+	normalizedPhoneNumber, err := utils.NormalizePhoneNumber(phoneNumber, "")
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusBadRequest, phoneNumber+" is not a valid phone number, it must include the country code")
+	}
+	return normalizedPhoneNumber, nil
+}
+
+// validateAttributes converts the attributes to the types of the custom fields of the organization and fills in defaults
+func validateAttributes(fields []contact_field_service.Field, attributes map[string]interface{}) (map[string]interface{}, error) {
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+
+	if err := contact_field_service.Validate(fields, attributes); err != nil {
+		if errors.Is(err, contact_field_service.ErrInvalidAttributes) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	if len(listUuids) == 0 {
-		return context.JSON(http.StatusOK, api_types.BulkImportResponseSchema{
-			Message: strconv.Itoa(len(contactToImport)) + " contacts imported successfully",
-		})
+	return attributes, nil
+}
+
+func deleteContactById(context interfaces.ContextWithSession) error {
+	if err := eraseContact(context, api_types.Delete); err != nil {
+		return err
 	}
 
-	for _, listId := range listUuids {
-		var records []model.ContactListContact
-		for _, contact := range importedContact {
-			contactListContact := model.ContactListContact{
-				ContactId:     contact.UniqueId,
-				ContactListId: listId,
-				CreatedAt:     time.Now(),
-				UpdatedAt:     time.Now(),
-			}
+	return context.JSON(http.StatusOK, api_types.DeleteContactByIdResponseSchema{
+		Data: true,
+	})
+}
+
+func eraseContactById(context interfaces.ContextWithSession) error {
+	payload := new(api_types.EraseContactByIdJSONRequestBody)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if payload.Mode != api_types.Anonymize && payload.Mode != api_types.Delete {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid erasure mode")
+	}
+
+	if err := eraseContact(context, payload.Mode); err != nil {
+		return err
+	}
+
+	return context.JSON(http.StatusOK, api_types.EraseContactByIdResponseSchema{
+		Data: true,
+	})
+}
+
+// eraseContact erases the contact of the path in the organization of the member, and records who erased it
+func eraseContact(context interfaces.ContextWithSession, mode api_types.ContactErasureModeEnum) error {
+	contact, err := fetchContactOfPath(context)
+	if err != nil {
+		return err
+	}
+
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
+
+	err = contact_lifecycle_service.Erase(context.Request().Context(), context.App.Db, *contact, mode, &member.UniqueId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// mergeContacts merges the duplicates of the payload into the contact of the path
+func mergeContacts(context interfaces.ContextWithSession) error {
+	contact, err := fetchContactOfPath(context)
+	if err != nil {
+		return err
+	}
+
+	payload := new(api_types.MergeContactsJSONRequestBody)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if len(payload.DuplicateIds) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "No duplicates to merge")
+	}
+
+	duplicateUuids, err := controller.CheckOwnedIds(context, tenant_service.Contact, payload.DuplicateIds)
+	if err != nil {
+		return err
+	}
+
+	duplicateIdExpressions := make([]Expression, 0, len(duplicateUuids))
+	for _, duplicateUuid := range duplicateUuids {
+		duplicateIdExpressions = append(duplicateIdExpressions, UUID(duplicateUuid))
+	}
 
-			records = append(records, contactListContact)
+	var duplicates []model.Contact
+
+	err = SELECT(table.Contact.AllColumns).
+		FROM(table.Contact).
+		WHERE(
+			table.Contact.UniqueId.IN(duplicateIdExpressions...).
+				AND(tenant_service.Contact.Scope(contact.OrganizationId)),
+		).
+		QueryContext(context.Request().Context(), context.App.Db, &duplicates)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
+
+	mergedContact, err := contact_lifecycle_service.Merge(context.Request().Context(), context.App.Db, *contact, duplicates, contact_lifecycle_service.MergeReasonManual, &member.UniqueId)
+	if err != nil {
+		if err == contact_lifecycle_service.ErrInvalidMerge {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
 
-		if len(records) == 0 {
-			return context.JSON(http.StatusOK, api_types.BulkImportResponseSchema{
-				Message: strconv.Itoa(len(contactToImport)) + " contacts imported successfully",
-			})
+	contactLists, err := contact_lifecycle_service.FetchContactLists(context.Request().Context(), context.App.Db, []uuid.UUID{mergedContact.UniqueId})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.MergeContactsResponseSchema{
+		Contact: contact_lifecycle_service.ToSchema(*mergedContact, contactLists[mergedContact.UniqueId]),
+	})
+}
+
+// scanContactDuplicates queues a job finding the contacts of the organization with the same normalized phone number
+func scanContactDuplicates(context interfaces.ContextWithSession) error {
+	payload := new(api_types.ScanContactDuplicatesJSONRequestBody)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
+
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
+
+	job, err := background_job_service.Create(context.Request().Context(), context.App.Db, orgUuid, &member.UniqueId, model.BackgroundJobTypeEnum_ContactDuplicateScan, contact_duplicate_service.Parameters{
+		Merge: payload.Merge,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusAccepted, api_types.ScanContactDuplicatesResponseSchema{
+		Message: "The duplicate scan has been queued, you will be notified as it progresses",
+		Job:     background_job_service.ToSchema(*job),
+	})
+}
+
+// getContactTimeline returns a page of everything that happened with the contact, newest first
+func getContactTimeline(context interfaces.ContextWithSession) error {
+	params := new(api_types.GetContactTimelineParams)
+	if err := utils.BindQueryParams(context, params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if params.Page < 1 || params.PerPage < 1 || params.PerPage > 50 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid page or perPage value")
+	}
+
+	contact, err := fetchContactOfPath(context)
+	if err != nil {
+		return err
+	}
+
+	events, total, err := contact_timeline_service.GetTimeline(context.Request().Context(), context.App.Db, *contact, params.Page, params.PerPage)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetContactTimelineResponseSchema{
+		Events: events,
+		PaginationMeta: api_types.PaginationMeta{
+			Page:    params.Page,
+			PerPage: params.PerPage,
+			Total:   total,
+		},
+	})
+}
+
+func getContactConsents(context interfaces.ContextWithSession) error {
+	contact, err := fetchContactOfPath(context)
+	if err != nil {
+		return err
+	}
+
+	consents, err := contact_consent_service.FetchConsents(context.Request().Context(), context.App.Db, contact.UniqueId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	consentsToReturn := make([]api_types.ContactConsentSchema, 0, len(consents))
+	for _, consent := range consents {
+		consentsToReturn = append(consentsToReturn, contact_consent_service.ToSchema(consent))
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetContactConsentsResponseSchema{
+		Consents: consentsToReturn,
+	})
+}
+
+// updateContactConsents records the consents given or withdrawn by the contact, as reported by the member
+func updateContactConsents(context interfaces.ContextWithSession) error {
+	contact, err := fetchContactOfPath(context)
+	if err != nil {
+		return err
+	}
+
+	payload := new(api_types.UpdateContactConsentsJSONRequestBody)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := validateConsents(&payload.Consents); err != nil {
+		return err
+	}
+
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
+
+	changes := make([]contact_consent_service.Change, 0, len(payload.Consents))
+	for _, consent := range payload.Consents {
+		changes = append(changes, contact_consent_service.FromSchema(consent, *contact, &member.UniqueId))
+	}
+
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	if err := contact_consent_service.Apply(context.Request().Context(), tx, changes...); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	consents, err := contact_consent_service.FetchConsents(context.Request().Context(), context.App.Db, contact.UniqueId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	consentsToReturn := make([]api_types.ContactConsentSchema, 0, len(consents))
+	for _, consent := range consents {
+		consentsToReturn = append(consentsToReturn, contact_consent_service.ToSchema(consent))
+	}
+
+	return context.JSON(http.StatusOK, api_types.UpdateContactConsentsResponseSchema{
+		Consents: consentsToReturn,
+	})
+}
+
+// grantLegacyConsents grants the marketing consent of the contacts created before consents were recorded, the owner states
+// how these contacts opted in and the statement is kept as the evidence of every consent
+func grantLegacyConsents(context interfaces.ContextWithSession) error {
+	payload := new(api_types.GrantLegacyConsentsJSONRequestBody)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	statement := strings.TrimSpace(payload.Statement)
+	if statement == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "The statement of how the contacts opted in is required")
+	}
+
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
+
+	// * the route level is not checked by the middleware, the owner answers for the statement
+	if member.AccessLevel != model.UserPermissionLevelEnum_Owner {
+		return echo.NewHTTPError(http.StatusForbidden, "Only the owner of the organization can grant legacy consents")
+	}
+
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	grantedCount, err := contact_consent_service.GrantLegacy(context.Request().Context(), tx, member.OrganizationId, member.UniqueId, statement, time.Now())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	err = audit_service.Record(context.Request().Context(), tx, audit_service.Entry{
+		OrganizationId:       member.OrganizationId,
+		OrganizationMemberId: &member.UniqueId,
+		Action:               model.AuditLogActionEnum_LegacyConsentsGranted,
+		Details: map[string]interface{}{
+			"statement":    statement,
+			"grantedCount": grantedCount,
+		},
+	})
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.GrantLegacyConsentsResponseSchema{
+		GrantedCount: grantedCount,
+	})
+}
+
+// validateConsents checks the consents of a request, a consent can only be given with its evidence
+func validateConsents(consents *[]api_types.NewContactConsentSchema) error {
+	if consents == nil {
+		return nil
+	}
+
+	for _, consent := range *consents {
+		if err := contact_consent_service.Validate(contact_consent_service.FromSchema(consent, model.Contact{}, nil)); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		}
+	}
 
-		contactInsertionToListQuery := table.ContactListContact.
-			INSERT(table.ContactListContact.MutableColumns).
-			MODELS(records).
-			ON_CONFLICT(table.ContactListContact.ContactId, table.ContactListContact.ContactListId).
-			DO_NOTHING()
+	return nil
+}
+
+// recordNewContactConsents records the consents given with the created contacts, the contacts which already existed are
+// not created and keep their consents
+func recordNewContactConsents(context interfaces.ContextWithSession, newContacts []api_types.NewContactSchema, insertedContacts []model.Contact) error {
+	insertedContactsByPhoneNumber := make(map[string]model.Contact, len(insertedContacts))
+	for _, contact := range insertedContacts {
+		insertedContactsByPhoneNumber[contact.PhoneNumber] = contact
+	}
 
-		_, err = contactInsertionToListQuery.ExecContext(context.Request().Context(), context.App.Db)
+	var member *model.OrganizationMember
+	changes := []contact_consent_service.Change{}
 
-		if err != nil {
-			logger.Error("Error inserting contacts into list:", err.Error(), nil)
-			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to insert contacts into list")
+	for _, newContact := range newContacts {
+		insertedContact, ok := insertedContactsByPhoneNumber[newContact.Phone]
+		if newContact.Consents == nil || !ok {
+			continue
+		}
+
+		if member == nil {
+			var err error
+			if member, err = controller.FetchCurrentMember(context); err != nil {
+				return err
+			}
+		}
+
+		for _, consent := range *newContact.Consents {
+			changes = append(changes, contact_consent_service.FromSchema(consent, insertedContact, &member.UniqueId))
 		}
 	}
 
-	// Prepare a success message
-	response := api_types.BulkImportResponseSchema{
-		Message: strconv.Itoa(len(contactToImport)) + " contacts imported successfully",
+	if err := contact_consent_service.Apply(context.Request().Context(), context.App.Db, changes...); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	return context.JSON(http.StatusOK, response)
+	return nil
 }
 
-func deleteContactById(context interfaces.ContextWithSession) error {
-	contactId := context.Param("id")
+// exportContactData returns everything stored about the contact, as a file to hand over to the contact
+func exportContactData(context interfaces.ContextWithSession) error {
+	contact, err := fetchContactOfPath(context)
+	if err != nil {
+		return err
+	}
 
-	if contactId == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid contact id")
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
 	}
 
-	contactUuid, _ := uuid.Parse(contactId)
+	data, err := contact_lifecycle_service.ExportContactData(context.Request().Context(), context.App.Db, *contact)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
 
-	// ! TODO: check if there is any conversation associated with this contact
-	// ! TODO: also before deleting the contact, remove the contact from all the lists and delete all their messages
+	err = audit_service.Record(context.Request().Context(), context.App.Db, audit_service.Entry{
+		OrganizationId:       contact.OrganizationId,
+		OrganizationMemberId: &member.UniqueId,
+		Action:               model.AuditLogActionEnum_ContactDataExported,
+		EntityId:             &contact.UniqueId,
+	})
 
-	contactQuery := table.Contact.DELETE().WHERE(table.Contact.UniqueId.EQ(UUID(contactUuid)))
-	result, err := contactQuery.ExecContext(context.Request().Context(), context.App.Db)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
 
+	context.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="contact-`+contact.UniqueId.String()+`.json"`)
+	return context.JSON(http.StatusOK, data)
+}
+
+// exportContacts streams the contacts matching the filters as a CSV or JSON file
+func exportContacts(context interfaces.ContextWithSession) error {
+	params := new(api_types.ExportContactsParams)
+	if err := utils.BindQueryParams(context, params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	if res, _ := result.RowsAffected(); res == 0 {
-		return echo.NewHTTPError(http.StatusNotFound, "Contact not found")
+	format := api_types.Csv
+	if params.Format != nil {
+		format = *params.Format
 	}
 
-	response := api_types.DeleteContactByIdResponseSchema{
-		Data: true,
+	filter := contact_lifecycle_service.ExportFilter{
+		Order: api_types.Desc,
 	}
 
-	return context.JSON(http.StatusOK, response)
+	if params.Order != nil {
+		filter.Order = *params.Order
+	}
+
+	if params.ListId != nil {
+		listUuid, err := uuid.Parse(*params.ListId)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid list id")
+		}
+		filter.ListId = &listUuid
+	}
+
+	if params.Status != nil {
+		status := model.ContactStatusEnum("")
+		if err := status.Scan(*params.Status); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid contact status")
+		}
+		filter.Status = &status
+	}
+
+	var contentType, extension string
+
+	switch format {
+	case api_types.Csv:
+		contentType, extension = "text/csv", "csv"
+	case api_types.Json:
+		contentType, extension = echo.MIMEApplicationJSON, "json"
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid export format")
+	}
+
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
+
+	// * the export is recorded before it starts, a download cut short has still handed out personal data
+	err = audit_service.Record(context.Request().Context(), context.App.Db, audit_service.Entry{
+		OrganizationId:       orgUuid,
+		OrganizationMemberId: &member.UniqueId,
+		Action:               model.AuditLogActionEnum_ContactsExported,
+		Details: map[string]interface{}{
+			"format": format,
+			"listId": params.ListId,
+			"status": params.Status,
+		},
+	})
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	response := context.Response()
+	response.Header().Set(echo.HeaderContentType, contentType)
+	response.Header().Set(echo.HeaderContentDisposition, `attachment; filename="contacts-`+time.Now().Format("2006-01-02")+`.`+extension+`"`)
+	response.WriteHeader(http.StatusOK)
+
+	_, err = contact_lifecycle_service.Export(context.Request().Context(), context.App.Db, orgUuid, filter, format, response)
+	if err != nil {
+		// * the status has already been sent, the download is cut short instead
+		context.App.Logger.Error("error exporting contacts", "organizationId", orgUuid.String(), "error", err.Error())
+	}
+
+	return nil
+}
+
+func fetchContactOfPath(context interfaces.ContextWithSession) (*model.Contact, error) {
+	contactUuid, err := uuid.Parse(context.Param("id"))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid contact id")
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	contact, err := contact_lifecycle_service.FetchContact(context.Request().Context(), context.App.Db, orgUuid, contactUuid)
+	if err != nil {
+		if err == contact_lifecycle_service.ErrContactNotFound {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Contact not found")
+		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return contact, nil
 }
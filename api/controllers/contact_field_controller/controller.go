@@ -0,0 +1,287 @@
+package contact_field_controller
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	controller "github.com/wapikit/wapikit/api/controllers"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/contact_field_service"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
+	"github.com/wapikit/wapikit/internal/interfaces"
+
+	"github.com/go-jet/jet/qrm"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+type ContactFieldController struct {
+	controller.BaseController `json:"-,inline"`
+}
+
+func NewContactFieldController() *ContactFieldController {
+	return &ContactFieldController{
+		BaseController: controller.BaseController{
+			Name:        "Contact Field Controller",
+			RestApiPath: "/api/contact-fields",
+			Routes: []interfaces.Route{
+				{
+					Path:                    "/api/contact-fields",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getContactFields),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+					},
+				},
+				{
+					Path:                    "/api/contact-fields",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(createContactField),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateOrganization,
+						},
+					},
+				},
+				{
+					Path:                    "/api/contact-fields/:id",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(updateContactFieldById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateOrganization,
+						},
+					},
+				},
+				{
+					Path:                    "/api/contact-fields/:id",
+					Method:                  http.MethodDelete,
+					Handler:                 interfaces.HandlerWithSession(deleteContactFieldById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateOrganization,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getContactFields(context interfaces.ContextWithSession) error {
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
+
+	fields, err := contact_field_service.FetchFields(context.Request().Context(), context.App.Db, orgUuid)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	fieldsToReturn := make([]api_types.ContactFieldSchema, 0, len(fields))
+	for _, field := range fields {
+		fieldsToReturn = append(fieldsToReturn, contact_field_service.ToSchema(field))
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetContactFieldsResponseSchema{
+		Fields: fieldsToReturn,
+	})
+}
+
+func createContactField(context interfaces.ContextWithSession) error {
+	payload := new(api_types.CreateContactFieldJSONRequestBody)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
+
+	options := []string{}
+	if payload.Options != nil {
+		options = *payload.Options
+	}
+
+	contactField, err := contact_field_service.NewField(orgUuid, payload.Key, payload.Label, payload.Type, payload.IsRequired, payload.DefaultValue, options)
+	if err != nil {
+		return fieldError(err)
+	}
+
+	var existingFields []model.ContactField
+	err = SELECT(table.ContactField.UniqueId).
+		FROM(table.ContactField).
+		WHERE(
+			table.ContactField.OrganizationId.EQ(UUID(orgUuid)).
+				AND(table.ContactField.Key.EQ(String(contactField.Key))),
+		).
+		LIMIT(1).
+		QueryContext(context.Request().Context(), context.App.Db, &existingFields)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if len(existingFields) > 0 {
+		return echo.NewHTTPError(http.StatusConflict, "Another contact field already uses this key")
+	}
+
+	return saveContactField(context, http.StatusCreated, func(tx *sql.Tx) (model.ContactField, error) {
+		var insertedField model.ContactField
+		err := table.ContactField.INSERT(table.ContactField.MutableColumns).
+			MODEL(contactField).
+			RETURNING(table.ContactField.AllColumns).
+			QueryContext(context.Request().Context(), tx, &insertedField)
+		return insertedField, err
+	})
+}
+
+// updateContactFieldById changes the definition of the field, the key and the type are kept as the attributes of the
+// contacts are stored under the key with a value of the type
+func updateContactFieldById(context interfaces.ContextWithSession) error {
+	existingField, err := fetchContactField(context)
+	if err != nil {
+		return err
+	}
+
+	payload := new(api_types.UpdateContactFieldByIdJSONRequestBody)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	options := []string{}
+	if payload.Options != nil {
+		options = *payload.Options
+	}
+
+	contactField, err := contact_field_service.NewField(
+		existingField.OrganizationId,
+		existingField.Key,
+		payload.Label,
+		api_types.ContactFieldTypeEnum(existingField.Type.String()),
+		payload.IsRequired,
+		payload.DefaultValue,
+		options,
+	)
+	if err != nil {
+		return fieldError(err)
+	}
+
+	return saveContactField(context, http.StatusOK, func(tx *sql.Tx) (model.ContactField, error) {
+		var updatedField model.ContactField
+		err := table.ContactField.UPDATE(
+			table.ContactField.Label,
+			table.ContactField.IsRequired,
+			table.ContactField.DefaultValue,
+			table.ContactField.Options,
+			table.ContactField.UpdatedAt,
+		).
+			MODEL(contactField).
+			WHERE(table.ContactField.UniqueId.EQ(UUID(existingField.UniqueId))).
+			RETURNING(table.ContactField.AllColumns).
+			QueryContext(context.Request().Context(), tx, &updatedField)
+		return updatedField, err
+	})
+}
+
+// deleteContactFieldById removes the definition only, the values contacts hold for the field stay in their attributes
+func deleteContactFieldById(context interfaces.ContextWithSession) error {
+	contactField, err := fetchContactField(context)
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ContactField.DELETE().
+		WHERE(table.ContactField.UniqueId.EQ(UUID(contactField.UniqueId))).
+		ExecContext(context.Request().Context(), context.App.Db)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.DeleteContactFieldByIdResponseSchema{
+		Data: true,
+	})
+}
+
+// saveContactField writes the field and gives its default value to the contacts which have none, in one transaction
+func saveContactField(context interfaces.ContextWithSession, status int, write func(tx *sql.Tx) (model.ContactField, error)) error {
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	savedField, err := write(tx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := contact_field_service.FillDefault(context.Request().Context(), tx, savedField); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	field, err := contact_field_service.Decode(savedField)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if status == http.StatusCreated {
+		return context.JSON(status, api_types.CreateContactFieldResponseSchema{
+			Field: contact_field_service.ToSchema(field),
+		})
+	}
+
+	return context.JSON(status, api_types.UpdateContactFieldByIdResponseSchema{
+		Field: contact_field_service.ToSchema(field),
+	})
+}
+
+func fetchContactField(context interfaces.ContextWithSession) (*model.ContactField, error) {
+	var contactField model.ContactField
+
+	if _, err := controller.FetchOwned(context, tenant_service.ContactField, &contactField); err != nil {
+		return nil, err
+	}
+
+	return &contactField, nil
+}
+
+func fieldError(err error) error {
+	if errors.Is(err, contact_field_service.ErrInvalidField) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
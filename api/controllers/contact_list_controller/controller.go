@@ -8,6 +8,9 @@ import (
 	"github.com/labstack/echo/v4"
 	controller "github.com/wapikit/wapikit/api/controllers"
 	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/background_job_service"
+	"github.com/wapikit/wapikit/internal/core/contact_list_operation_service"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
 	"github.com/wapikit/wapikit/internal/core/utils"
 	"github.com/wapikit/wapikit/internal/interfaces"
 
@@ -59,6 +62,23 @@ func NewContactListController() *ContactListController {
 						},
 					},
 				},
+				{
+					Path:                    "/api/lists/operations",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(runContactListOperation),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.CreateList,
+							api_types.UpdateList,
+						},
+					},
+				},
 				{
 					Path:                    "/api/lists/:id",
 					Method:                  http.MethodGet,
@@ -76,7 +96,7 @@ func NewContactListController() *ContactListController {
 					},
 				},
 				{
-					Path:                    "/api/contacts/:id",
+					Path:                    "/api/lists/:id",
 					Method:                  http.MethodDelete,
 					Handler:                 interfaces.HandlerWithSession(DeleteContactListById),
 					IsAuthorizationRequired: true,
@@ -92,7 +112,7 @@ func NewContactListController() *ContactListController {
 					},
 				},
 				{
-					Path:                    "/api/contacts/:id",
+					Path:                    "/api/lists/:id",
 					Method:                  http.MethodPost,
 					Handler:                 interfaces.HandlerWithSession(UpdateContactListById),
 					IsAuthorizationRequired: true,
@@ -283,8 +303,15 @@ func GetContactListById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Contact list id is required")
 	}
 
-	listUuid, _ := uuid.Parse(contactListId)
-	orgId, _ := uuid.Parse(context.Session.User.OrganizationId)
+	listUuid, err := uuid.Parse(contactListId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid contact list id")
+	}
+
+	orgId, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
 
 	listQuery := SELECT(
 		table.ContactList.AllColumns,
@@ -309,9 +336,12 @@ func GetContactListById(context interfaces.ContextWithSession) error {
 		}
 	}
 
-	err := listQuery.QueryContext(context.Request().Context(), context.App.Db, &dest)
+	err = listQuery.QueryContext(context.Request().Context(), context.App.Db, &dest)
 
 	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusNotFound, "Contact list not found")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Error fetching list")
 	}
 
@@ -351,13 +381,50 @@ func DeleteContactListById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Contact list id is required")
 	}
 
+	listUuid, err := uuid.Parse(contactListId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid contact list id")
+	}
+
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
+
 	// ! TODO: check for the running campaigns associated with this list, if there's any do not allow deleting the list
 
+	// * the opt-in forms and links of the list stop capturing contacts along with it, the consents given through them stay
+	listCaptureSourceIds := SELECT(table.ContactCaptureSource.UniqueId).
+		FROM(table.ContactCaptureSource).
+		WHERE(
+			tenant_service.ContactCaptureSource.Scope(orgUuid).
+				AND(table.ContactCaptureSource.ContactListId.EQ(UUID(listUuid))),
+		)
+
+	_, err = table.ContactConsent.UPDATE(table.ContactConsent.ContactCaptureSourceId).
+		SET(NULL).
+		WHERE(table.ContactConsent.ContactCaptureSourceId.IN(listCaptureSourceIds)).
+		ExecContext(context.Request().Context(), context.App.Db)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	_, err = table.ContactCaptureSource.DELETE().
+		WHERE(
+			tenant_service.ContactCaptureSource.Scope(orgUuid).
+				AND(table.ContactCaptureSource.ContactListId.EQ(UUID(listUuid))),
+		).
+		ExecContext(context.Request().Context(), context.App.Db)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	deleteQuery := table.ContactList.
 		DELETE().
 		WHERE(
-			table.ContactList.OrganizationId.EQ(String(context.Session.User.OrganizationId)).
-				AND(table.ContactList.UniqueId.EQ(String(contactListId))),
+			tenant_service.ContactList.ById(orgUuid, listUuid),
 		)
 
 	result, err := deleteQuery.ExecContext(context.Request().Context(), context.App.Db)
@@ -444,3 +511,70 @@ func UpdateContactListById(context interfaces.ContextWithSession) error {
 
 	return context.JSON(http.StatusOK, response)
 }
+
+// runContactListOperation queues a job building lists out of other lists, the lists are checked here so that the job only
+// fails when one of them is deleted in the meantime
+func runContactListOperation(context interfaces.ContextWithSession) error {
+	payload := new(api_types.RunContactListOperationJSONRequestBody)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
+
+	sourceListIds, err := controller.CheckOwnedIds(context, tenant_service.ContactList, payload.SourceListIds)
+	if err != nil {
+		return err
+	}
+
+	parameters := contact_list_operation_service.Parameters{
+		Operation:     contact_list_operation_service.Operation(payload.Operation),
+		SourceListIds: sourceListIds,
+	}
+
+	if payload.Name != nil {
+		parameters.Name = *payload.Name
+	}
+
+	if payload.Groups != nil {
+		parameters.Groups = *payload.Groups
+	}
+
+	if payload.TargetListId != nil {
+		targetListIds, err := controller.CheckOwnedIds(context, tenant_service.ContactList, []string{*payload.TargetListId})
+		if err != nil {
+			return err
+		}
+		parameters.TargetListId = &targetListIds[0]
+	}
+
+	if payload.ContactIds != nil {
+		contactIds, err := controller.CheckOwnedIds(context, tenant_service.Contact, *payload.ContactIds)
+		if err != nil {
+			return err
+		}
+		parameters.ContactIds = contactIds
+	}
+
+	if err := parameters.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
+
+	job, err := background_job_service.Create(context.Request().Context(), context.App.Db, orgUuid, &member.UniqueId, model.BackgroundJobTypeEnum_ContactListOperation, parameters)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusAccepted, api_types.RunContactListOperationResponseSchema{
+		Message: "The list operation has been queued, you will be notified as it progresses",
+		Job:     background_job_service.ToSchema(*job),
+	})
+}
@@ -2,18 +2,26 @@ package conversation_controller
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
-	"github.com/wapikit/wapi.go/pkg/components"
 	"github.com/wapikit/wapikit/.db-generated/model"
 	"github.com/wapikit/wapikit/.db-generated/table"
 	controller "github.com/wapikit/wapikit/api/controllers"
 	"github.com/wapikit/wapikit/internal/api_types"
 	"github.com/wapikit/wapikit/internal/core/api_server_events"
+	"github.com/wapikit/wapikit/internal/core/conversation_lifecycle_service"
+	"github.com/wapikit/wapikit/internal/core/conversation_note_service"
+	"github.com/wapikit/wapikit/internal/core/csat_service"
+	"github.com/wapikit/wapikit/internal/core/message_service"
+	"github.com/wapikit/wapikit/internal/core/routing_service"
+	"github.com/wapikit/wapikit/internal/core/search_service"
+	"github.com/wapikit/wapikit/internal/core/sla_service"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
 	"github.com/wapikit/wapikit/internal/core/utils"
 	"github.com/wapikit/wapikit/internal/interfaces"
 
@@ -159,6 +167,102 @@ func NewConversationController() *ConversationController {
 						},
 					},
 				},
+				{
+					Path:                    "/api/conversation/:id/resolve",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(handleResolveConversation),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateConversation,
+						},
+					},
+				},
+				{
+					Path:                    "/api/conversation/:id/reopen",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(handleReopenConversation),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateConversation,
+						},
+					},
+				},
+				{
+					Path:                    "/api/conversation/:id/snooze",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(handleSnoozeConversation),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateConversation,
+						},
+					},
+				},
+				{
+					Path:                    "/api/conversation/:id/timeline",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(handleGetConversationTimeline),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    100,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetConversation,
+						},
+					},
+				},
+				{
+					Path:                    "/api/conversation/:id/notes",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(handleCreateConversationNote),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    100,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetConversation,
+						},
+					},
+				},
+				{
+					Path:                    "/api/conversation/:id/notes/:noteId",
+					Method:                  http.MethodDelete,
+					Handler:                 interfaces.HandlerWithSession(handleDeleteConversationNoteById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetConversation,
+						},
+					},
+				},
 			},
 		},
 	}
@@ -221,6 +325,7 @@ func handleGetConversations(context interfaces.ContextWithSession) error {
 			table.Conversation.Status.NOT_IN(
 				utils.EnumExpression(model.ConversationStatusEnum_Deleted.String()),
 				utils.EnumExpression(model.ConversationStatusEnum_Closed.String()),
+				utils.EnumExpression(model.ConversationStatusEnum_Snoozed.String()),
 			),
 		)
 	}
@@ -229,6 +334,57 @@ func handleGetConversations(context interfaces.ContextWithSession) error {
 		conversationWhereQuery = conversationWhereQuery.AND(table.Conversation.InitiatedByCampaignId.EQ(UUID(uuid.MustParse(*campaignId))))
 	}
 
+	searchText := search_service.NormalizeQuery(queryParams.Query)
+
+	// * the conversations with the latest messages come first, unless they are searched
+	orderBy := []OrderByClause{
+		Raw(` MAX("Message"."CreatedAt") OVER (PARTITION BY "Conversation"."UniqueId") DESC,
+			     "Message"."CreatedAt" ASC`,
+		),
+	}
+
+	if searchText != "" || queryParams.From != nil || queryParams.To != nil {
+		messagesOfConversation := table.Message.ConversationId.EQ(table.Conversation.UniqueId).
+			AND(search_service.MessageSentBetween(queryParams.From, queryParams.To))
+
+		hasMessagesInSpan := EXISTS(
+			SELECT(table.Message.UniqueId).
+				FROM(table.Message).
+				WHERE(messagesOfConversation),
+		)
+
+		if searchText == "" {
+			conversationWhereQuery = conversationWhereQuery.AND(hasMessagesInSpan)
+		} else {
+			hasMatchingMessages := EXISTS(
+				SELECT(table.Message.UniqueId).
+					FROM(table.Message).
+					WHERE(messagesOfConversation.AND(search_service.MessageMatches(searchText))),
+			)
+
+			contactMatches := search_service.ContactMatches(searchText)
+			if queryParams.From != nil || queryParams.To != nil {
+				// * a matching contact only counts when the conversation was active in the time span
+				contactMatches = contactMatches.AND(hasMessagesInSpan)
+			}
+
+			conversationWhereQuery = conversationWhereQuery.AND(hasMatchingMessages.OR(contactMatches))
+
+			// * a conversation ranks like its best matching message or its contact, the same way the global search ranks them
+			messageRank := MAXf(FloatExp(CASE().
+				WHEN(search_service.MessageMatches(searchText).AND(search_service.MessageSentBetween(queryParams.From, queryParams.To))).
+				THEN(search_service.MessageRank(searchText)).
+				ELSE(Float(0)),
+			)).OVER(PARTITION_BY(table.Conversation.UniqueId))
+
+			contactRank := CASE().
+				WHEN(contactMatches).THEN(search_service.ContactRank(searchText)).
+				ELSE(Float(0))
+
+			orderBy = append([]OrderByClause{GREATEST(messageRank, contactRank).DESC()}, orderBy...)
+		}
+	}
+
 	conversationQuery := SELECT(
 		table.Conversation.AllColumns,
 		table.Contact.AllColumns,
@@ -248,11 +404,7 @@ func handleGetConversations(context interfaces.ContextWithSession) error {
 		LEFT_JOIN(table.Tag, table.ConversationTag.TagId.EQ(table.Tag.UniqueId)),
 	).
 		WHERE(conversationWhereQuery).
-		ORDER_BY(
-			Raw(` MAX("Message"."CreatedAt") OVER (PARTITION BY "Conversation"."UniqueId") DESC,
-			     "Message"."CreatedAt" ASC`,
-			),
-		).
+		ORDER_BY(orderBy...).
 		LIMIT(limit).
 		OFFSET((page - 1) * limit)
 
@@ -262,6 +414,28 @@ func handleGetConversations(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	conversationIds := make([]uuid.UUID, 0, len(fetchedConversations))
+	for _, conversation := range fetchedConversations {
+		conversationIds = append(conversationIds, conversation.UniqueId)
+	}
+
+	unreadCounts, err := message_service.CountUnreadMessages(context.Request().Context(), context.App.Db, conversationIds)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	for index := range fetchedConversations {
+		fetchedConversations[index].NumberOfUnreadMessages = unreadCounts[fetchedConversations[index].UniqueId]
+	}
+
+	var searchMatches map[uuid.UUID][]api_types.ConversationSearchMatchSchema
+	if searchText != "" {
+		searchMatches, err = fetchSearchMatches(context, conversationIds, searchText, queryParams.From, queryParams.To)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
 	response := api_types.GetConversationsResponseSchema{
 		Conversations: make([]api_types.ConversationSchema, 0),
 		PaginationMeta: api_types.PaginationMeta{
@@ -308,7 +482,17 @@ func handleGetConversations(context interfaces.ContextWithSession) error {
 				CreatedAt:  conversation.Contact.CreatedAt,
 				Lists:      lists,
 			},
-			Tags: []api_types.TagSchema{},
+			Tags:         []api_types.TagSchema{},
+			Sla:          sla_service.BuildConversationSla(conversation.Conversation),
+			SnoozedUntil: conversation.SnoozedUntil,
+		}
+
+		if searchText != "" {
+			matches := searchMatches[conversation.UniqueId]
+			if matches == nil {
+				matches = []api_types.ConversationSearchMatchSchema{}
+			}
+			conversationToAppend.SearchMatches = &matches
 		}
 
 		if conversation.AssignedTo.UniqueId != uuid.Nil {
@@ -355,6 +539,78 @@ func handleGetConversations(context interfaces.ContextWithSession) error {
 	return context.JSON(http.StatusOK, response)
 }
 
+// the number of matching messages highlighted for each of the searched conversations
+const searchMatchesPerConversation = 3
+
+// fetchSearchMatches returns the best matching messages of each conversation, with their highlights
+func fetchSearchMatches(context interfaces.ContextWithSession, conversationIds []uuid.UUID, searchText string, from, to *time.Time) (map[uuid.UUID][]api_types.ConversationSearchMatchSchema, error) {
+	matches := map[uuid.UUID][]api_types.ConversationSearchMatchSchema{}
+
+	if len(conversationIds) == 0 {
+		return matches, nil
+	}
+
+	conversationIdExpressions := make([]Expression, 0, len(conversationIds))
+	for _, conversationId := range conversationIds {
+		conversationIdExpressions = append(conversationIdExpressions, UUID(conversationId))
+	}
+
+	position := IntegerColumn("position")
+
+	// * the messages are ranked first, so that only the few highlighted per conversation go through ts_headline
+	rankedMessages := SELECT(
+		table.Message.UniqueId,
+		ROW_NUMBER().OVER(
+			PARTITION_BY(table.Message.ConversationId).
+				ORDER_BY(search_service.MessageRank(searchText).DESC(), table.Message.CreatedAt.DESC()),
+		).AS(position.Name()),
+	).
+		FROM(table.Message).
+		WHERE(
+			table.Message.ConversationId.IN(conversationIdExpressions...).
+				AND(search_service.MessageMatches(searchText)).
+				AND(search_service.MessageSentBetween(from, to)),
+		).
+		AsTable("ranked_message")
+
+	var rows []struct {
+		model.Message
+		Rank      float64
+		Highlight string
+	}
+
+	err := SELECT(
+		table.Message.AllColumns,
+		search_service.MessageRank(searchText).AS("rank"),
+		search_service.MessageHighlight(searchText).AS("highlight"),
+	).
+		FROM(table.Message).
+		WHERE(
+			table.Message.UniqueId.IN(
+				SELECT(table.Message.UniqueId.From(rankedMessages)).
+					FROM(rankedMessages).
+					WHERE(position.From(rankedMessages).LT_EQ(Int(searchMatchesPerConversation))),
+			),
+		).
+		ORDER_BY(FloatColumn("rank").DESC(), table.Message.CreatedAt.DESC()).
+		QueryContext(context.Request().Context(), context.App.Db, &rows)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		matches[*row.ConversationId] = append(matches[*row.ConversationId], api_types.ConversationSearchMatchSchema{
+			MessageId: row.UniqueId.String(),
+			Rank:      float32(row.Rank),
+			Highlight: row.Highlight,
+			CreatedAt: row.CreatedAt,
+		})
+	}
+
+	return matches, nil
+}
+
 func handleGetConversationById(context interfaces.ContextWithSession) error {
 	conversationId := context.Param("id")
 
@@ -367,6 +623,11 @@ func handleGetConversationById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid conversation id")
 	}
 
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
+
 	type FetchedConversation struct {
 		model.Conversation
 		Contact struct {
@@ -405,7 +666,7 @@ func handleGetConversationById(context interfaces.ContextWithSession) error {
 		LEFT_JOIN(table.Tag, table.ConversationTag.TagId.EQ(table.Tag.UniqueId)),
 	).
 		WHERE(
-			table.Conversation.UniqueId.EQ(UUID(conversationUuid)),
+			tenant_service.Conversation.ById(orgUuid, conversationUuid),
 		).
 		ORDER_BY(
 			Raw(` MAX("Message"."CreatedAt") OVER (PARTITION BY "Conversation"."UniqueId") DESC,
@@ -422,6 +683,13 @@ func handleGetConversationById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	unreadCounts, err := message_service.CountUnreadMessages(context.Request().Context(), context.App.Db, []uuid.UUID{conversation.UniqueId})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	conversation.NumberOfUnreadMessages = unreadCounts[conversation.UniqueId]
+
 	response := api_types.GetConversationByIdResponseSchema{
 		Conversation: api_types.ConversationSchema{},
 	}
@@ -463,7 +731,9 @@ func handleGetConversationById(context interfaces.ContextWithSession) error {
 			CreatedAt:  conversation.Contact.CreatedAt,
 			Lists:      lists,
 		},
-		Tags: []api_types.TagSchema{},
+		Tags:         []api_types.TagSchema{},
+		Sla:          sla_service.BuildConversationSla(conversation.Conversation),
+		SnoozedUntil: conversation.SnoozedUntil,
 	}
 
 	if conversation.AssignedTo.UniqueId != uuid.Nil {
@@ -512,10 +782,11 @@ func handleUpdateConversationById(context interfaces.ContextWithSession) error {
 	if conversationId == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "conversation id is required")
 	}
-	conversationUuid, err := uuid.Parse(conversationId)
+	conversation, err := fetchConversation(context)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid conversation id")
+		return err
 	}
+	conversationUuid := conversation.UniqueId
 
 	type FetchedConversation struct {
 		model.Conversation
@@ -534,7 +805,7 @@ func handleUpdateConversationById(context interfaces.ContextWithSession) error {
 		NumberOfUnreadMessages int `json:"numberOfUnreadMessages"`
 	}
 
-	context.App.Logger.Info("conversation id: %v", conversationUuid)
+	context.App.Logger.Info("conversation id", "conversationId", conversationUuid.String())
 
 	return nil
 }
@@ -544,24 +815,21 @@ func handleDeleteConversationById(context interfaces.ContextWithSession) error {
 	if conversationId == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "conversation id is required")
 	}
-	conversationUuid, err := uuid.Parse(conversationId)
+	conversation, err := fetchConversation(context)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid conversation id")
+		return err
 	}
+	conversationUuid := conversation.UniqueId
 
-	context.App.Logger.Info("conversation id: %v", conversationUuid)
+	context.App.Logger.Info("conversation id", "conversationId", conversationUuid.String())
 
 	return context.JSON(http.StatusBadRequest, nil)
 }
 
 func handleGetConversationMessages(context interfaces.ContextWithSession) error {
-	conversationId := context.Param("id")
-	if conversationId == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "conversation id is required")
-	}
-	conversationUuid, err := uuid.Parse(conversationId)
+	conversation, err := fetchConversation(context)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid conversation id")
+		return err
 	}
 
 	queryParams := new(api_types.GetConversationMessagesParams)
@@ -576,55 +844,90 @@ func handleGetConversationMessages(context interfaces.ContextWithSession) error
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid page or perPage value")
 	}
 
-	type FetchedMessage struct {
-		model.Message
-	}
-
-	var dest []struct {
-		TotalMessages int `json:"totalMessages"`
-		model.Message
+	// * notes are paginated along with the messages, so that a page holds the timeline of the conversation as it happened
+	type conversationItem struct {
+		UniqueId  uuid.UUID
+		CreatedAt time.Time
+		ItemType  string
 	}
 
-	messageQuery := SELECT(
-		table.Message.AllColumns,
-		COUNT(table.Contact.UniqueId).OVER().AS("totalMessages"),
-	).FROM(table.Message).
-		WHERE(
-			table.Message.ConversationId.EQ(UUID(conversationUuid)),
-		).
+	var itemsOfPage []conversationItem
+
+	itemsQuery := UNION_ALL(
+		SELECT(
+			table.Message.UniqueId.AS("conversation_item.unique_id"),
+			table.Message.CreatedAt.AS("conversation_item.created_at"),
+			String(string(api_types.ConversationMessageItemTypeEnumMessage)).AS("conversation_item.item_type"),
+		).FROM(table.Message).
+			WHERE(table.Message.ConversationId.EQ(UUID(conversation.UniqueId))),
+		SELECT(
+			table.ConversationNote.UniqueId.AS("conversation_item.unique_id"),
+			table.ConversationNote.CreatedAt.AS("conversation_item.created_at"),
+			String(string(api_types.ConversationMessageItemTypeEnumNote)).AS("conversation_item.item_type"),
+		).FROM(table.ConversationNote).
+			WHERE(table.ConversationNote.ConversationId.EQ(UUID(conversation.UniqueId))),
+	).
 		ORDER_BY(
-			table.Message.CreatedAt.ASC(),
+			TimestampzColumn("conversation_item.created_at").ASC(),
 		).
 		LIMIT(limit).
 		OFFSET((page - 1) * limit)
 
-	err = messageQuery.QueryContext(context.Request().Context(), context.App.Db, &dest)
+	err = itemsQuery.QueryContext(context.Request().Context(), context.App.Db, &itemsOfPage)
 
-	if err != nil {
-		if err.Error() == qrm.ErrNoRows.Error() {
-			total := 0
-			messages := make([]api_types.MessageSchema, 0)
-			return context.JSON(http.StatusOK, api_types.GetConversationMessagesResponseSchema{
-				Messages: messages,
-				PaginationMeta: api_types.PaginationMeta{
-					Page:    page,
-					PerPage: limit,
-					Total:   total,
-				},
-			})
-		}
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
 
+	var counts struct {
+		TotalMessages int
+		TotalNotes    int
+	}
+
+	err = SELECT(
+		SELECT(COUNT(table.Message.UniqueId)).
+			FROM(table.Message).
+			WHERE(table.Message.ConversationId.EQ(UUID(conversation.UniqueId))).
+			AS("totalMessages"),
+		SELECT(COUNT(table.ConversationNote.UniqueId)).
+			FROM(table.ConversationNote).
+			WHERE(table.ConversationNote.ConversationId.EQ(UUID(conversation.UniqueId))).
+			AS("totalNotes"),
+	).QueryContext(context.Request().Context(), context.App.Db, &counts)
+
+	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	messagesToReturn := []api_types.MessageSchema{}
-	totalMessages := 0
+	messageIds := []Expression{}
+	noteIds := []uuid.UUID{}
+
+	for _, item := range itemsOfPage {
+		if item.ItemType == string(api_types.ConversationMessageItemTypeEnumNote) {
+			noteIds = append(noteIds, item.UniqueId)
+		} else {
+			messageIds = append(messageIds, UUID(item.UniqueId))
+		}
+	}
+
+	messagesById := make(map[uuid.UUID]api_types.MessageSchema, len(messageIds))
+
+	if len(messageIds) > 0 {
+		var messages []model.Message
+
+		err = SELECT(table.Message.AllColumns).
+			FROM(table.Message).
+			WHERE(table.Message.UniqueId.IN(messageIds...)).
+			QueryContext(context.Request().Context(), context.App.Db, &messages)
+
+		if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
 
-	if len(dest) > 0 {
-		for _, message := range dest {
+		for _, message := range messages {
 			messageData := map[string]interface{}{}
 			json.Unmarshal([]byte(*message.MessageData), &messageData)
-			message := api_types.MessageSchema{
+			messagesById[message.UniqueId] = api_types.MessageSchema{
 				UniqueId:       message.UniqueId.String(),
 				ConversationId: message.ConversationId.String(),
 				CreatedAt:      message.CreatedAt,
@@ -633,138 +936,229 @@ func handleGetConversationMessages(context interfaces.ContextWithSession) error
 				MessageType:    api_types.MessageTypeEnum(message.MessageType.String()),
 				Status:         api_types.MessageStatusEnum(message.Status.String()),
 			}
-			messagesToReturn = append(messagesToReturn, message)
 		}
+	}
+
+	notesById, err := conversation_note_service.GetNotes(context.Request().Context(), context.App.Db, noteIds)
 
-		totalMessages = dest[0].TotalMessages
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	messagesToReturn := []api_types.MessageSchema{}
+	itemsToReturn := []api_types.ConversationMessageItemSchema{}
+
+	for _, item := range itemsOfPage {
+		if item.ItemType == string(api_types.ConversationMessageItemTypeEnumNote) {
+			note, ok := notesById[item.UniqueId]
+			if !ok {
+				continue
+			}
+			itemsToReturn = append(itemsToReturn, api_types.ConversationMessageItemSchema{
+				Type: api_types.ConversationMessageItemTypeEnumNote,
+				Note: &note,
+			})
+			continue
+		}
+
+		message, ok := messagesById[item.UniqueId]
+		if !ok {
+			continue
+		}
+		messagesToReturn = append(messagesToReturn, message)
+		itemsToReturn = append(itemsToReturn, api_types.ConversationMessageItemSchema{
+			Type:    api_types.ConversationMessageItemTypeEnumMessage,
+			Message: &message,
+		})
 	}
 
 	response := api_types.GetConversationMessagesResponseSchema{
 		Messages: messagesToReturn,
+		Items:    itemsToReturn,
 		PaginationMeta: api_types.PaginationMeta{
 			Page:    page,
 			PerPage: limit,
-			Total:   totalMessages,
+			Total:   counts.TotalMessages + counts.TotalNotes,
 		},
 	}
 
 	return context.JSON(http.StatusOK, response)
 }
 
-func handleSendMessage(context interfaces.ContextWithSession) error {
-	conversationId := context.Param("id")
-	if conversationId == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "conversation id is required")
-	}
-	conversationUuid, err := uuid.Parse(conversationId)
+func handleCreateConversationNote(context interfaces.ContextWithSession) error {
+	conversation, err := fetchConversation(context)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid conversation id")
+		return err
 	}
 
-	payload := new(api_types.NewMessageSchema)
-
+	payload := new(api_types.NewConversationNoteSchema)
 	if err := context.Bind(payload); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	var conversationWithContact struct {
-		model.Conversation
-		Contact model.Contact `json:"contact"`
+	author, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
 	}
 
-	conversationFetchQuery := SELECT(
-		table.Conversation.AllColumns,
-		table.Contact.AllColumns,
-	).FROM(
-		table.Conversation.LEFT_JOIN(
-			table.Contact, table.Conversation.ContactId.EQ(table.Contact.UniqueId),
-		),
-	).WHERE(
-		table.Conversation.UniqueId.EQ(UUID(conversationUuid)),
-	).LIMIT(1)
+	mentionedMemberIds := []string{}
+	if payload.MentionedMemberIds != nil {
+		mentionedMemberIds = *payload.MentionedMemberIds
+	}
 
-	err = conversationFetchQuery.QueryContext(context.Request().Context(), context.App.Db, &conversationWithContact)
+	createdNote, err := conversation_note_service.CreateNote(
+		context.Request().Context(),
+		context.App.Db,
+		*conversation,
+		*author,
+		context.Session.User.Name,
+		payload.Content,
+		mentionedMemberIds,
+	)
 
 	if err != nil {
-		if err.Error() == qrm.ErrNoRows.Error() {
-			return echo.NewHTTPError(http.StatusNotFound, "conversation not found")
+		switch err {
+		case conversation_note_service.ErrEmptyNote:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case conversation_note_service.ErrMemberNotFound:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	messageData, err := json.Marshal(payload.MessageData)
+	conversation_note_service.PublishNoteEvents(context.App.Redis, context.App.Constants.RedisEventChannelName, *createdNote)
+
+	return context.JSON(http.StatusOK, api_types.CreateConversationNoteResponseSchema{
+		Note: createdNote.Note,
+	})
+}
+
+func handleDeleteConversationNoteById(context interfaces.ContextWithSession) error {
+	conversation, err := fetchConversation(context)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
+	noteUuid, err := uuid.Parse(context.Param("noteId"))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid note id")
 	}
 
-	messagingClient := context.App.WapiClient.NewMessagingClient(
-		conversationWithContact.PhoneNumberUsed,
-	)
+	author, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
 
-	var whatsappMessageId string
+	var note model.ConversationNote
 
-	//  ! handle all the message type to send here
-	switch *payload.MessageType {
-	case api_types.Text:
-		if payload.MessageData != nil {
-			messageData, err = json.Marshal(payload.MessageData)
-			if err != nil {
-				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-			}
+	err = SELECT(table.ConversationNote.AllColumns).
+		FROM(table.ConversationNote).
+		WHERE(
+			table.ConversationNote.UniqueId.EQ(UUID(noteUuid)).
+				AND(table.ConversationNote.ConversationId.EQ(UUID(conversation.UniqueId))),
+		).
+		LIMIT(1).
+		QueryContext(context.Request().Context(), context.App.Db, &note)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusNotFound, "note not found")
 		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
 
-		textMessageData := *payload.MessageData
+	if note.AuthorOrganizationMemberId == nil || *note.AuthorOrganizationMemberId != author.UniqueId {
+		return echo.NewHTTPError(http.StatusForbidden, "only the author can delete a note")
+	}
 
-		{
-			textMessage, err := components.NewTextMessage(components.TextMessageConfigs{
-				Text: textMessageData["text"].(string),
-			})
+	err = conversation_note_service.DeleteNote(context.Request().Context(), context.App.Db, note.UniqueId)
 
-			if err != nil {
-				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-			}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
 
-			response, err := messagingClient.Message.Send(textMessage, conversationWithContact.Contact.PhoneNumber)
-			if err != nil {
-				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-			}
+	return context.JSON(http.StatusOK, api_types.DeleteConversationNoteByIdResponseSchema{
+		Data: true,
+	})
+}
 
-			var jsonResponse map[string]interface{}
-			err = json.Unmarshal([]byte(response), &jsonResponse)
+func handleSendMessage(context interfaces.ContextWithSession) error {
+	conversation, err := fetchConversation(context)
+	if err != nil {
+		return err
+	}
 
-			fmt.Println("response: %v", jsonResponse)
+	payload := new(api_types.NewMessageSchema)
 
-			if err != nil {
-				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-			}
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	var contact model.Contact
 
-			whatsappMessageId = jsonResponse["messages"].([]interface{})[0].(map[string]interface{})["id"].(string)
+	err = SELECT(table.Contact.AllColumns).
+		FROM(table.Contact).
+		WHERE(table.Contact.UniqueId.EQ(UUID(conversation.ContactId))).
+		LIMIT(1).
+		QueryContext(context.Request().Context(), context.App.Db, &contact)
 
-			context.App.Logger.Info("response: %v", response, nil)
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusNotFound, "contact of the conversation not found")
 		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	outboundMessage, err := message_service.BuildOutboundMessage(context.Request().Context(), context.App.Db, conversation.UniqueId, *payload)
+	if err != nil {
+		if errors.Is(err, message_service.ErrInvalidMessage) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var businessAccount model.WhatsappBusinessAccount
+
+	err = SELECT(table.WhatsappBusinessAccount.AllColumns).
+		FROM(table.WhatsappBusinessAccount).
+		WHERE(table.WhatsappBusinessAccount.OrganizationId.EQ(UUID(conversation.OrganizationId))).
+		LIMIT(1).
+		QueryContext(context.Request().Context(), context.App.Db, &businessAccount)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusBadRequest, "whatsapp business account not connected")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	whatsappMessageId, err := message_service.Send(context.App.WapiClient, conversation.PhoneNumberUsed, contact.PhoneNumber, outboundMessage.Message)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	messageData, err := json.Marshal(outboundMessage.MessageData)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
 	stringMessageData := string(messageData)
-	businessAccountId := "103043282674158"
 
 	messageToInsert := model.Message{
-		ConversationId:            &conversationWithContact.UniqueId,
+		ConversationId:            &conversation.UniqueId,
 		Direction:                 model.MessageDirectionEnum_OutBound,
 		WhatsAppMessageId:         &whatsappMessageId,
-		WhatsappBusinessAccountId: &businessAccountId,
+		WhatsappBusinessAccountId: &businessAccount.AccountId,
 		CampaignId:                nil,
-		ContactId:                 conversationWithContact.ContactId,
-		MessageType:               model.MessageTypeEnum_Text,
+		ContactId:                 conversation.ContactId,
+		MessageType:               outboundMessage.MessageType,
 		Status:                    model.MessageStatusEnum_Sent,
 		MessageData:               &stringMessageData,
-		OrganizationId:            conversationWithContact.OrganizationId,
+		OrganizationId:            conversation.OrganizationId,
 		CreatedAt:                 time.Now(),
 		UpdatedAt:                 time.Now(),
-		PhoneNumberUsed:           conversationWithContact.PhoneNumberUsed,
+		PhoneNumberUsed:           conversation.PhoneNumberUsed,
+		RepliedTo:                 outboundMessage.RepliedTo,
 	}
 
 	var insertedMessage model.Message
@@ -780,13 +1174,19 @@ func handleSendMessage(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	err = sla_service.RecordFirstResponse(context.Request().Context(), context.App.Db, conversation.UniqueId)
+
+	if err != nil {
+		context.App.Logger.Error("error recording first response", "conversationId", conversation.UniqueId.String(), "error", err.Error())
+	}
+
 	responseToReturn := api_types.SendMessageInConversationResponseSchema{
 		Message: api_types.MessageSchema{
 			UniqueId:       insertedMessage.UniqueId.String(),
 			ConversationId: insertedMessage.ConversationId.String(),
 			CreatedAt:      insertedMessage.CreatedAt,
 			Direction:      api_types.MessageDirectionEnum(insertedMessage.Direction.String()),
-			MessageData:    payload.MessageData,
+			MessageData:    &outboundMessage.MessageData,
 			MessageType:    api_types.MessageTypeEnum(insertedMessage.MessageType.String()),
 			Status:         api_types.MessageStatusEnum(insertedMessage.Status.String()),
 		},
@@ -810,6 +1210,11 @@ func handleAssignConversation(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
+
 	orgMemberUuid, err := uuid.Parse(payload.OrganizationMemberId)
 
 	if err != nil {
@@ -833,7 +1238,7 @@ func handleAssignConversation(context interfaces.ContextWithSession) error {
 			)),
 	).
 		WHERE(
-			table.Conversation.UniqueId.EQ(UUID(conversationUuid)),
+			tenant_service.Conversation.ById(orgUuid, conversationUuid),
 		).LIMIT(1)
 
 	organizationMemberQuery := SELECT(
@@ -841,7 +1246,7 @@ func handleAssignConversation(context interfaces.ContextWithSession) error {
 	).FROM(
 		table.OrganizationMember,
 	).WHERE(
-		table.OrganizationMember.UniqueId.EQ(UUID(orgMemberUuid)),
+		tenant_service.OrganizationMember.ById(orgUuid, orgMemberUuid),
 	).LIMIT(1)
 
 	err = organizationMemberQuery.QueryContext(context.Request().Context(), context.App.Db, &organizationMember)
@@ -862,58 +1267,16 @@ func handleAssignConversation(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	assignmentToInsert := model.ConversationAssignment{
-		ConversationId:                 conversationUuid,
-		Status:                         model.ConversationAssignmentStatus_Assigned,
-		CreatedAt:                      time.Now(),
-		UpdatedAt:                      time.Now(),
-		AssignedToOrganizationMemberId: orgMemberUuid,
-	}
-
-	// ! update the conversation assignment record and create a new record for new assignment.
-
-	if conversation.Assignment.ConversationId == uuid.Nil {
-		unassignFromPreviousMemberCte := CTE("unassign_from_previous_member_cte")
-		assignmentUpdateQuery := WITH(
-			unassignFromPreviousMemberCte.AS(
-				table.ConversationAssignment.UPDATE(table.ConversationAssignment.Status).
-					SET(
-						table.ConversationAssignment.Status.SET(utils.EnumExpression(model.ConversationAssignmentStatus_Unassigned.String())),
-					).
-					WHERE(
-						table.ConversationAssignment.ConversationId.EQ(UUID(conversationUuid)),
-					).
-					RETURNING(table.ConversationAssignment.AllColumns),
-			),
-		)(
-			table.ConversationAssignment.
-				INSERT().
-				MODEL(assignmentToInsert).
-				RETURNING(table.ConversationAssignment.AllColumns),
-		)
-
-		err = assignmentUpdateQuery.QueryContext(context.Request().Context(), context.App.Db, &assignmentToInsert)
-
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-		}
-	} else {
-		insertQuery := table.ConversationAssignment.
-			INSERT().
-			MODEL(assignmentToInsert).
-			RETURNING(table.ConversationAssignment.AllColumns)
+	err = routing_service.AssignConversation(context.Request().Context(), context.App.Db, conversationUuid, orgMemberUuid)
 
-		err = insertQuery.QueryContext(context.Request().Context(), context.App.Db, &assignmentToInsert)
-
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-		}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
 	// ! send assignment notification to the user
-	event := api_server_events.BaseApiServerEvent{
-		EventType: api_server_events.ApiServerChatAssignmentEvent,
-	}
+	event := api_server_events.NewChatAssignmentEvent(api_server_events.ConversationWithAllDetails{
+		Conversation: conversation.Conversation,
+	}, organizationMember.UserId.String())
 	context.App.Redis.PublishMessageToRedisChannel(context.App.Constants.RedisEventChannelName, event.ToJson())
 
 	responseToReturn := api_types.AssignConversationResponseSchema{
@@ -933,6 +1296,11 @@ func handleUnassignConversation(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid conversation id")
 	}
 
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
+
 	var conversation struct {
 		model.Conversation
 		Assignment model.ConversationAssignment
@@ -948,7 +1316,7 @@ func handleUnassignConversation(context interfaces.ContextWithSession) error {
 			)),
 	).
 		WHERE(
-			table.Conversation.UniqueId.EQ(UUID(conversationUuid)),
+			tenant_service.Conversation.ById(orgUuid, conversationUuid),
 		).LIMIT(1)
 
 	err = conversationFetchQuery.QueryContext(context.Request().Context(), context.App.Db, &conversation)
@@ -991,3 +1359,102 @@ func handleUnassignConversation(context interfaces.ContextWithSession) error {
 
 	return context.JSON(http.StatusOK, responseToReturn)
 }
+
+func handleResolveConversation(context interfaces.ContextWithSession) error {
+	return transitionConversation(context, model.ConversationStatusEnum_Resolved, nil)
+}
+
+func handleReopenConversation(context interfaces.ContextWithSession) error {
+	return transitionConversation(context, model.ConversationStatusEnum_Active, nil)
+}
+
+func handleSnoozeConversation(context interfaces.ContextWithSession) error {
+	payload := new(api_types.SnoozeConversationSchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if !payload.SnoozedUntil.After(time.Now()) {
+		return echo.NewHTTPError(http.StatusBadRequest, "snoozedUntil must be in the future")
+	}
+
+	return transitionConversation(context, model.ConversationStatusEnum_Snoozed, &payload.SnoozedUntil)
+}
+
+func handleGetConversationTimeline(context interfaces.ContextWithSession) error {
+	conversation, err := fetchConversation(context)
+	if err != nil {
+		return err
+	}
+
+	timeline, err := conversation_lifecycle_service.GetTimeline(context.Request().Context(), context.App.Db, conversation.UniqueId)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetConversationTimelineResponseSchema{
+		Events: timeline,
+	})
+}
+
+// transitionConversation moves the conversation of the id param to the status on behalf of the current member
+func transitionConversation(context interfaces.ContextWithSession, to model.ConversationStatusEnum, snoozedUntil *time.Time) error {
+	conversation, err := fetchConversation(context)
+	if err != nil {
+		return err
+	}
+
+	member, err := controller.FetchCurrentMember(context)
+	if err != nil {
+		return err
+	}
+
+	updatedConversation, timelineEvent, err := conversation_lifecycle_service.TransitionConversation(
+		context.Request().Context(),
+		context.App.Db,
+		*conversation,
+		conversation_lifecycle_service.Transition{
+			To:            to,
+			Trigger:       model.ConversationTimelineEventTriggerEnum_Member,
+			ActorMemberId: &member.UniqueId,
+			SnoozedUntil:  snoozedUntil,
+		},
+	)
+
+	if err != nil {
+		if err == conversation_lifecycle_service.ErrInvalidTransition {
+			return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("%s conversations can not be moved to %s", conversation.Status.String(), to.String()))
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	timelineEventToReturn := conversation_lifecycle_service.ToSchema(*timelineEvent, context.Session.User.Name)
+	conversation_lifecycle_service.PublishTransition(context.App.Redis, context.App.Constants.RedisEventChannelName, timelineEventToReturn, conversation.OrganizationId.String())
+
+	if updatedConversation.Status == model.ConversationStatusEnum_Resolved {
+		// * the conversation stays resolved even if the survey could not be sent
+		_, err = csat_service.SendSurvey(context.Request().Context(), context.App.Db, context.App.WapiClient, *updatedConversation)
+
+		if err != nil {
+			context.App.Logger.Error("error sending csat survey", "conversationId", conversation.UniqueId.String(), "error", err.Error())
+		}
+	}
+
+	return context.JSON(http.StatusOK, api_types.UpdateConversationStatusResponseSchema{
+		Status:       api_types.ConversationStatusEnum(updatedConversation.Status.String()),
+		SnoozedUntil: updatedConversation.SnoozedUntil,
+		Event:        timelineEventToReturn,
+	})
+}
+
+// fetchConversation loads the conversation of the id param, conversations of other organizations are not found
+func fetchConversation(context interfaces.ContextWithSession) (*model.Conversation, error) {
+	var conversation model.Conversation
+
+	if _, err := controller.FetchOwned(context, tenant_service.Conversation, &conversation); err != nil {
+		return nil, err
+	}
+
+	return &conversation, nil
+}
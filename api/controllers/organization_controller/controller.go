@@ -13,7 +13,10 @@ import (
 	wapi "github.com/wapikit/wapi.go/pkg/client"
 	controller "github.com/wapikit/wapikit/api/controllers"
 	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/secret_service"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
 	"github.com/wapikit/wapikit/internal/core/utils"
+	"github.com/wapikit/wapikit/internal/core/whatsapp_service"
 	"github.com/wapikit/wapikit/internal/interfaces"
 
 	"github.com/go-jet/jet/qrm"
@@ -306,6 +309,19 @@ func NewOrganizationController() *OrganizationController {
 						},
 					},
 				},
+				{
+					Path:                    "/api/organization/whatsappBusinessAccount",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getWhatsappBusinessAccountDetails),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Owner,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+					},
+				},
 				{
 					Path:                    "/api/organization/whatsappBusinessAccount",
 					Method:                  http.MethodPost,
@@ -362,6 +378,7 @@ func createNewOrganization(context interfaces.ContextWithSession) error {
 		AccessLevel:    model.UserPermissionLevelEnum_Owner,
 		OrganizationId: newOrg.UniqueId,
 		UserId:         userUuid,
+		IsAvailable:    true,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}).RETURNING(table.OrganizationMember.AllColumns).QueryContext(context.Request().Context(), tx, &member)
@@ -506,7 +523,7 @@ func getOrganizations(context interfaces.ContextWithSession) error {
 		if org.SmtpClientHost != nil && org.SmtpClientPassword != nil && org.SmtpClientPort != nil && org.SmtpClientUsername != nil {
 			organization.EmailNotificationConfiguration = &api_types.EmailNotificationConfigurationSchema{
 				SmtpHost:     *org.SmtpClientHost,
-				SmtpPassword: *org.SmtpClientPassword,
+				SmtpPassword: context.App.Secrets.MaskStored(*org.SmtpClientPassword),
 				SmtpPort:     *org.SmtpClientPort,
 				SmtpUsername: *org.SmtpClientUsername,
 			}
@@ -553,16 +570,9 @@ func getOrganizationById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Invalid organization Id")
 	}
 
-	userUuid, err := uuid.Parse(context.Session.User.UniqueId)
-
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Invalid user Id")
-	}
-
-	hasAccess := _verifyAccessToOrganization(context, userUuid, orgUuid)
-
-	if !hasAccess {
-		return echo.NewHTTPError(http.StatusForbidden, "You do not have access to this organization")
+	// * the permissions of the session are the ones of its organization, other organizations of the user are not found here
+	if orgUuid.String() != context.Session.User.OrganizationId {
+		return echo.NewHTTPError(http.StatusNotFound, "Organization not found")
 	}
 
 	var dest model.Organization
@@ -582,6 +592,18 @@ func getOrganizationById(context interfaces.ContextWithSession) error {
 		FaviconUrl: &dest.FaviconUrl,
 		LogoUrl:    dest.LogoUrl,
 		WebsiteUrl: dest.WebsiteUrl,
+
+		IsTwoFactorRequiredForOwners: &dest.IsTwoFactorRequiredForOwners,
+	}
+
+	if dest.ConversationAutoCloseAfterMinutes != nil {
+		autoCloseAfterMinutes := int(*dest.ConversationAutoCloseAfterMinutes)
+		orgToReturn.ConversationAutoCloseAfterMinutes = &autoCloseAfterMinutes
+	}
+
+	orgToReturn.CsatConfiguration = &api_types.CsatConfigurationSchema{
+		IsEnabled: dest.IsCsatEnabled,
+		Message:   dest.CsatMessage,
 	}
 
 	if dest.SlackChannel != nil && dest.SlackWebhookUrl != nil {
@@ -594,7 +616,7 @@ func getOrganizationById(context interfaces.ContextWithSession) error {
 	if dest.SmtpClientHost != nil && dest.SmtpClientPassword != nil && dest.SmtpClientPort != nil && dest.SmtpClientUsername != nil {
 		orgToReturn.EmailNotificationConfiguration = &api_types.EmailNotificationConfigurationSchema{
 			SmtpHost:     *dest.SmtpClientHost,
-			SmtpPassword: *dest.SmtpClientPassword,
+			SmtpPassword: context.App.Secrets.MaskStored(*dest.SmtpClientPassword),
 			SmtpPort:     *dest.SmtpClientPort,
 			SmtpUsername: *dest.SmtpClientUsername,
 		}
@@ -628,16 +650,9 @@ func deleteOrganization(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Invalid organization Id")
 	}
 
-	userUuid, err := uuid.Parse(context.Session.User.UniqueId)
-
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Invalid user Id")
-	}
-
-	hasAccess := _verifyAccessToOrganization(context, userUuid, orgUuid)
-
-	if !hasAccess {
-		return echo.NewHTTPError(http.StatusForbidden, "You do not have access to this organization")
+	// * the permissions of the session are the ones of its organization, other organizations of the user are not found here
+	if orgUuid.String() != context.Session.User.OrganizationId {
+		return echo.NewHTTPError(http.StatusNotFound, "Organization not found")
 	}
 
 	return context.String(http.StatusOK, "OK")
@@ -656,16 +671,9 @@ func updateOrganizationById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Invalid organization Id")
 	}
 
-	userUuid, err := uuid.Parse(context.Session.User.UniqueId)
-
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Invalid user Id")
-	}
-
-	hasAccess := _verifyAccessToOrganization(context, userUuid, orgUuid)
-
-	if !hasAccess {
-		return echo.NewHTTPError(http.StatusForbidden, "You do not have access to this organization")
+	// * the permissions of the session are the ones of its organization, other organizations of the user are not found here
+	if orgUuid.String() != context.Session.User.OrganizationId {
+		return echo.NewHTTPError(http.StatusNotFound, "Organization not found")
 	}
 
 	payload := new(api_types.UpdateOrganizationSchema)
@@ -674,16 +682,78 @@ func updateOrganizationById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
+	var existingOrg model.Organization
+
+	err = SELECT(table.Organization.AllColumns).
+		FROM(table.Organization).
+		WHERE(table.Organization.UniqueId.EQ(UUID(orgUuid))).
+		LIMIT(1).
+		QueryContext(context.Request().Context(), context.App.Db, &existingOrg)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusNotFound, "Organization not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	orgUpdates := model.Organization{
 		Name:        payload.Name,
 		UpdatedAt:   time.Now(),
 		Description: payload.Description,
+		// * secrets are only sent back masked, so unless they are part of the payload the stored ones are kept
+		SmtpClientHost:     existingOrg.SmtpClientHost,
+		SmtpClientUsername: existingOrg.SmtpClientUsername,
+		SmtpClientPassword: existingOrg.SmtpClientPassword,
+		SmtpClientPort:     existingOrg.SmtpClientPort,
+		SlackWebhookUrl:    existingOrg.SlackWebhookUrl,
+		SlackChannel:       existingOrg.SlackChannel,
+		IsAiEnabled:        existingOrg.IsAiEnabled,
+		AiModel:            existingOrg.AiModel,
+		AiApiKey:           existingOrg.AiApiKey,
+
+		ConversationAutoCloseAfterMinutes: existingOrg.ConversationAutoCloseAfterMinutes,
+		IsCsatEnabled:                     existingOrg.IsCsatEnabled,
+		CsatMessage:                       existingOrg.CsatMessage,
+	}
+
+	if payload.CsatConfiguration != nil {
+		orgUpdates.IsCsatEnabled = payload.CsatConfiguration.IsEnabled
+		orgUpdates.CsatMessage = nil
+
+		if payload.CsatConfiguration.Message != nil && strings.TrimSpace(*payload.CsatConfiguration.Message) != "" {
+			orgUpdates.CsatMessage = payload.CsatConfiguration.Message
+		}
+	}
+
+	if payload.ConversationAutoCloseAfterMinutes != nil {
+		switch {
+		case *payload.ConversationAutoCloseAfterMinutes < 0:
+			return echo.NewHTTPError(http.StatusBadRequest, "conversationAutoCloseAfterMinutes can not be negative")
+		case *payload.ConversationAutoCloseAfterMinutes == 0:
+			orgUpdates.ConversationAutoCloseAfterMinutes = nil
+		default:
+			autoCloseAfterMinutes := int32(*payload.ConversationAutoCloseAfterMinutes)
+			orgUpdates.ConversationAutoCloseAfterMinutes = &autoCloseAfterMinutes
+		}
 	}
 
 	if payload.EmailNotificationConfiguration != nil {
+		storedPassword := ""
+		if existingOrg.SmtpClientPassword != nil {
+			storedPassword = *existingOrg.SmtpClientPassword
+		}
+
+		smtpPassword, err := context.App.Secrets.EncryptUpdate(payload.EmailNotificationConfiguration.SmtpPassword, storedPassword)
+
+		if err != nil {
+			context.App.Logger.Error("error encrypting smtp password", "error", err.Error())
+			return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+		}
+
 		orgUpdates.SmtpClientHost = &payload.EmailNotificationConfiguration.SmtpHost
 		orgUpdates.SmtpClientUsername = &payload.EmailNotificationConfiguration.SmtpUsername
-		orgUpdates.SmtpClientPassword = &payload.EmailNotificationConfiguration.SmtpPassword
+		orgUpdates.SmtpClientPassword = &smtpPassword
 		orgUpdates.SmtpClientPort = &payload.EmailNotificationConfiguration.SmtpPort
 	}
 
@@ -695,13 +765,28 @@ func updateOrganizationById(context interfaces.ContextWithSession) error {
 	if payload.AiConfiguration != nil {
 		orgUpdates.IsAiEnabled = *payload.AiConfiguration.IsEnabled
 		orgUpdates.AiModel = (*model.AiModelEnum)(&payload.AiConfiguration.Model)
-		orgUpdates.AiApiKey = payload.AiConfiguration.ApiKey
+		aiApiKey, err := context.App.Secrets.EncryptUpdate(payload.AiConfiguration.ApiKey, existingOrg.AiApiKey)
+
+		if err != nil {
+			context.App.Logger.Error("error encrypting ai api key", "error", err.Error())
+			return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+		}
+
+		orgUpdates.AiApiKey = aiApiKey
+	}
+
+	// * the two factor policy is only changed when it is part of the payload
+	columnsToUpdate := table.Organization.MutableColumns.Except(table.Organization.IsTwoFactorRequiredForOwners)
+
+	if payload.IsTwoFactorRequiredForOwners != nil {
+		orgUpdates.IsTwoFactorRequiredForOwners = *payload.IsTwoFactorRequiredForOwners
+		columnsToUpdate = table.Organization.MutableColumns
 	}
 
 	var updatedOrg model.Organization
 
 	updateOrgQuery := table.Organization.
-		UPDATE(table.Organization.MutableColumns).
+		UPDATE(columnsToUpdate).
 		MODEL(orgUpdates).
 		WHERE(table.Organization.UniqueId.EQ(UUID(orgUuid))).
 		RETURNING(table.Organization.AllColumns)
@@ -713,6 +798,8 @@ func updateOrganizationById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	controller.InvalidateOrganizationAuthorizationCache(&context.App, orgUuid.String())
+
 	// if AI chat has been enabled, we have to create a default chat for every user in the organization
 
 	if payload.AiConfiguration != nil && *payload.AiConfiguration.IsEnabled {
@@ -975,6 +1062,7 @@ func getOrganizationMembers(context interfaces.ContextWithSession) error {
 				Email:       member.User.Email,
 				Name:        member.User.Name,
 				Roles:       memberRoles,
+				IsAvailable: &member.OrganizationMember.IsAvailable,
 			}
 
 			membersToReturn = append(membersToReturn, mmbr)
@@ -1003,7 +1091,16 @@ func getOrgMemberById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid member id")
 	}
 
-	memberUuid, _ := uuid.Parse(memberId)
+	memberUuid, err := uuid.Parse(memberId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid member id")
+	}
+
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
+
 	memberQuery := SELECT(table.OrganizationMember.AllColumns,
 		table.User.Username,
 		table.User.Name,
@@ -1015,7 +1112,7 @@ func getOrgMemberById(context interfaces.ContextWithSession) error {
 			LEFT_JOIN(table.User, table.User.UniqueId.EQ(table.OrganizationMember.UserId)).
 			LEFT_JOIN(table.RoleAssignment, table.RoleAssignment.OrganizationMemberId.EQ(table.OrganizationMember.UniqueId)).
 			LEFT_JOIN(table.OrganizationRole, table.OrganizationRole.UniqueId.EQ(table.RoleAssignment.OrganizationRoleId))).
-		WHERE(table.OrganizationMember.UniqueId.EQ(UUID(memberUuid))).
+		WHERE(tenant_service.OrganizationMember.ById(orgUuid, memberUuid)).
 		LIMIT(1)
 
 	var dest struct {
@@ -1028,14 +1125,11 @@ func getOrgMemberById(context interfaces.ContextWithSession) error {
 		}
 	}
 
-	err := memberQuery.QueryContext(context.Request().Context(), context.App.Db, &dest)
+	err = memberQuery.QueryContext(context.Request().Context(), context.App.Db, &dest)
 
 	if err != nil {
 		if err.Error() == qrm.ErrNoRows.Error() {
-			member := new(api_types.OrganizationMemberSchema)
-			return context.JSON(http.StatusOK, api_types.GetOrganizationMemberByIdResponseSchema{
-				Member: *member,
-			})
+			return echo.NewHTTPError(http.StatusNotFound, "Member not found")
 		} else {
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
@@ -1068,6 +1162,7 @@ func getOrgMemberById(context interfaces.ContextWithSession) error {
 		Email:       dest.member.User.Email,
 		Name:        dest.member.User.Name,
 		Roles:       memberRoles,
+		IsAvailable: &dest.member.OrganizationMember.IsAvailable,
 	}
 
 	return context.JSON(http.StatusOK, api_types.GetOrganizationMemberByIdResponseSchema{
@@ -1076,19 +1171,152 @@ func getOrgMemberById(context interfaces.ContextWithSession) error {
 }
 
 func deleteOrgMemberById(context interfaces.ContextWithSession) error {
-	memberId := context.Param("id")
+	var member model.OrganizationMember
 
-	if memberId == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid member id")
+	memberUuid, err := controller.FetchOwned(context, tenant_service.OrganizationMember, &member)
+	if err != nil {
+		return err
 	}
 
-	memberUuid, _ := uuid.Parse(memberId)
+	// * the member is removed with everything pointing at them or not at all
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
 
 	// * delete all role assignments first
 	deleteRoleAssignmentQuery := table.RoleAssignment.DELETE().
 		WHERE(table.RoleAssignment.OrganizationMemberId.EQ(UUID(memberUuid)))
 
-	_, err := deleteRoleAssignmentQuery.ExecContext(context.Request().Context(), context.App.Db)
+	_, err = deleteRoleAssignmentQuery.ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * remove the member from the conversation routing rules
+	_, err = table.ConversationRoutingRuleMember.DELETE().
+		WHERE(table.ConversationRoutingRuleMember.OrganizationMemberId.EQ(UUID(memberUuid))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * sla policies escalating to the member stop reassigning breached conversations
+	_, err = table.SlaPolicy.UPDATE(table.SlaPolicy.EscalationOrganizationMemberId, table.SlaPolicy.EscalationAction).
+		SET(NULL, NULL).
+		WHERE(table.SlaPolicy.EscalationOrganizationMemberId.EQ(UUID(memberUuid))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * personal canned responses of the member are deleted along with them
+	personalCannedResponses := SELECT(table.CannedResponse.UniqueId).
+		FROM(table.CannedResponse).
+		WHERE(table.CannedResponse.OrganizationMemberId.EQ(UUID(memberUuid)))
+
+	_, err = table.CannedResponseTag.DELETE().
+		WHERE(table.CannedResponseTag.CannedResponseId.IN(personalCannedResponses)).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	_, err = table.ContactCaptureSource.UPDATE(table.ContactCaptureSource.WelcomeCannedResponseId).
+		SET(NULL).
+		WHERE(table.ContactCaptureSource.WelcomeCannedResponseId.IN(personalCannedResponses)).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	_, err = table.CannedResponse.DELETE().
+		WHERE(table.CannedResponse.OrganizationMemberId.EQ(UUID(memberUuid))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * ratings of the member stay in the csat analytics, as ratings of unassigned conversations
+	_, err = table.CsatSurvey.UPDATE(table.CsatSurvey.OrganizationMemberId).
+		SET(NULL).
+		WHERE(table.CsatSurvey.OrganizationMemberId.EQ(UUID(memberUuid))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * jobs started by the member stay in the job history of the organization
+	_, err = table.BackgroundJob.UPDATE(table.BackgroundJob.CreatedByOrganizationMemberId).
+		SET(NULL).
+		WHERE(table.BackgroundJob.CreatedByOrganizationMemberId.EQ(UUID(memberUuid))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * the audit log outlives the members, the actions of a removed member are kept without the member
+	_, err = table.AuditLog.UPDATE(table.AuditLog.OrganizationMemberId).
+		SET(NULL).
+		WHERE(table.AuditLog.OrganizationMemberId.EQ(UUID(memberUuid))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * changes made to contacts by the member stay in the timelines of the contacts
+	_, err = table.ContactActivity.UPDATE(table.ContactActivity.OrganizationMemberId).
+		SET(NULL).
+		WHERE(table.ContactActivity.OrganizationMemberId.EQ(UUID(memberUuid))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * consents recorded by the member stay valid, they keep their evidence
+	_, err = table.ContactConsent.UPDATE(table.ContactConsent.OrganizationMemberId).
+		SET(NULL).
+		WHERE(table.ContactConsent.OrganizationMemberId.EQ(UUID(memberUuid))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * status changes made by the member stay in the timelines of the conversations
+	_, err = table.ConversationTimelineEvent.UPDATE(table.ConversationTimelineEvent.ActorOrganizationMemberId).
+		SET(NULL).
+		WHERE(table.ConversationTimelineEvent.ActorOrganizationMemberId.EQ(UUID(memberUuid))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * notes written by the member stay on the conversations without their author, the mentions of the member go away
+	_, err = table.ConversationNoteMention.DELETE().
+		WHERE(table.ConversationNoteMention.OrganizationMemberId.EQ(UUID(memberUuid))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	_, err = table.ConversationNote.UPDATE(table.ConversationNote.AuthorOrganizationMemberId).
+		SET(NULL).
+		WHERE(table.ConversationNote.AuthorOrganizationMemberId.EQ(UUID(memberUuid))).
+		ExecContext(context.Request().Context(), tx)
 
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
@@ -1099,12 +1327,19 @@ func deleteOrgMemberById(context interfaces.ContextWithSession) error {
 		WHERE(table.OrganizationMember.UniqueId.EQ(UUID(memberUuid))).
 		RETURNING(table.OrganizationMember.AllColumns)
 
-	_, err = deleteMemberQuery.ExecContext(context.Request().Context(), context.App.Db)
+	_, err = deleteMemberQuery.ExecContext(context.Request().Context(), tx)
 
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * the removed member must lose access right away, not once the cached permissions expire
+	controller.InvalidateAuthorizationCache(&context.App, member.UserId.String())
+
 	response := api_types.DeleteOrganizationMemberByIdResponseSchema{
 		Data: true,
 	}
@@ -1118,7 +1353,15 @@ func updateOrgMemberById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid member id")
 	}
 
-	memberUuid, _ := uuid.Parse(memberId)
+	memberUuid, err := uuid.Parse(memberId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid member id")
+	}
+
+	orgUuid, err := controller.OrganizationIdOf(context)
+	if err != nil {
+		return err
+	}
 
 	payload := new(api_types.UpdateOrganizationMemberSchema)
 	if err := context.Bind(payload); err != nil {
@@ -1128,15 +1371,22 @@ func updateOrgMemberById(context interfaces.ContextWithSession) error {
 	updateMemberQuery := table.OrganizationMember.
 		UPDATE(table.OrganizationMember.AccessLevel).
 		SET(payload.AccessLevel).
-		WHERE(table.OrganizationMember.UniqueId.EQ(UUID(memberUuid))).
+		WHERE(tenant_service.OrganizationMember.ById(orgUuid, memberUuid)).
 		RETURNING(table.OrganizationMember.AllColumns)
 
-	_, err := updateMemberQuery.ExecContext(context.Request().Context(), context.App.Db)
+	var updatedMember model.OrganizationMember
+	err = updateMemberQuery.QueryContext(context.Request().Context(), context.App.Db, &updatedMember)
 
 	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusNotFound, "Member not found")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	// * the new access level applies right away, not once the cached one expires
+	controller.InvalidateAuthorizationCache(&context.App, updatedMember.UserId.String())
+
 	return context.String(http.StatusOK, "OK")
 }
 
@@ -1146,7 +1396,6 @@ func updateOrganizationMemberRoles(context interfaces.ContextWithSession) error
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid member id")
 	}
 
-	memberUuid, _ := uuid.Parse(memberId)
 	payload := new(api_types.UpdateOrganizationMemberRoleSchema)
 	if err := context.Bind(payload); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
@@ -1154,19 +1403,14 @@ func updateOrganizationMemberRoles(context interfaces.ContextWithSession) error
 
 	var orgMember model.OrganizationMember
 
-	memberQuery := SELECT(table.OrganizationMember.AllColumns).
-		FROM(table.OrganizationMember).
-		WHERE(table.OrganizationMember.UniqueId.EQ(UUID(memberUuid))).
-		LIMIT(1)
-
-	err := memberQuery.QueryContext(context.Request().Context(), context.App.Db, &orgMember)
-
+	memberUuid, err := controller.FetchOwned(context, tenant_service.OrganizationMember, &orgMember)
 	if err != nil {
-		if err.Error() == qrm.ErrNoRows.Error() {
-			return echo.NewHTTPError(http.StatusNotFound, "Member not found")
-		} else {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-		}
+		return err
+	}
+
+	// * only the roles of the organization can be assigned to its members
+	if _, err := controller.CheckOwnedIds(context, tenant_service.OrganizationRole, payload.UpdatedRoleIds); err != nil {
+		return err
 	}
 
 	roleIdExpressions := make([]Expression, 0)
@@ -1221,6 +1465,8 @@ func updateOrganizationMemberRoles(context interfaces.ContextWithSession) error
 
 	// if all roles are removed then return
 	if len(payload.UpdatedRoleIds) == 0 {
+		controller.InvalidateAuthorizationCache(&context.App, orgMember.UserId.String())
+
 		responseToReturn := api_types.UpdateOrganizationMemberRoleByIdResponseSchema{
 			IsRoleUpdated: true,
 		}
@@ -1252,6 +1498,9 @@ func updateOrganizationMemberRoles(context interfaces.ContextWithSession) error
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	// * the permissions of the new roles apply right away, not once the cached ones expire
+	controller.InvalidateAuthorizationCache(&context.App, orgMember.UserId.String())
+
 	responseToReturn := api_types.UpdateOrganizationMemberRoleByIdResponseSchema{
 		IsRoleUpdated: true,
 	}
@@ -1470,11 +1719,12 @@ func getMessageTemplateById(context interfaces.ContextWithSession) error {
 
 	// initialize a wapi client and fetch the templates
 
-	wapiClient := wapi.New(&wapi.ClientConfig{
-		BusinessAccountId: businessAccount.AccountId,
-		ApiAccessToken:    businessAccount.AccessToken,
-		WebhookSecret:     businessAccount.WebhookSecret,
-	})
+	wapiClient, err := controller.NewWapiClient(&context.App, businessAccount)
+
+	if err != nil {
+		context.App.Logger.Error("error decrypting whatsapp access token", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Error fetching business account details")
+	}
 
 	templateResponse, err := wapiClient.Business.Template.Fetch(templateId)
 
@@ -1515,11 +1765,12 @@ func getAllMessageTemplates(context interfaces.ContextWithSession) error {
 
 	// initialize a wapi client and fetch the templates
 
-	wapiClient := wapi.New(&wapi.ClientConfig{
-		BusinessAccountId: businessAccount.AccountId,
-		ApiAccessToken:    businessAccount.AccessToken,
-		WebhookSecret:     businessAccount.WebhookSecret,
-	})
+	wapiClient, err := controller.NewWapiClient(&context.App, businessAccount)
+
+	if err != nil {
+		context.App.Logger.Error("error decrypting whatsapp access token", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Error fetching business account details")
+	}
 
 	templateResponse, err := wapiClient.Business.Template.FetchAll()
 
@@ -1562,11 +1813,12 @@ func getAllPhoneNumbers(context interfaces.ContextWithSession) error {
 
 	// initialize a wapi client and fetch the templates
 
-	wapiClient := wapi.New(&wapi.ClientConfig{
-		BusinessAccountId: businessAccount.AccountId,
-		ApiAccessToken:    businessAccount.AccessToken,
-		WebhookSecret:     businessAccount.WebhookSecret,
-	})
+	wapiClient, err := controller.NewWapiClient(&context.App, businessAccount)
+
+	if err != nil {
+		context.App.Logger.Error("error decrypting whatsapp access token", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Error fetching business account details")
+	}
 
 	phoneNumbersResponse, err := wapiClient.Business.PhoneNumber.FetchAll(true)
 
@@ -1611,11 +1863,12 @@ func getPhoneNumberById(context interfaces.ContextWithSession) error {
 
 	// initialize a wapi client and fetch the templates
 
-	wapiClient := wapi.New(&wapi.ClientConfig{
-		BusinessAccountId: businessAccount.AccountId,
-		ApiAccessToken:    businessAccount.AccessToken,
-		WebhookSecret:     businessAccount.WebhookSecret,
-	})
+	wapiClient, err := controller.NewWapiClient(&context.App, businessAccount)
+
+	if err != nil {
+		context.App.Logger.Error("error decrypting whatsapp access token", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Error fetching business account details")
+	}
 
 	phoneNumberResponse, err := wapiClient.Business.PhoneNumber.Fetch(phoneNumberId)
 
@@ -1646,6 +1899,11 @@ func transferOwnershipOfOrganization(context interfaces.ContextWithSession) erro
 		return echo.NewHTTPError(http.StatusInternalServerError, "Invalid organization id")
 	}
 
+	// * only the organization of the session can be transferred, other organizations of the user are not found here
+	if context.Param("id") != organizationUuid.String() {
+		return echo.NewHTTPError(http.StatusNotFound, "Organization not found")
+	}
+
 	newOwnerUuid, err := uuid.Parse(payload.NewOwnerId)
 
 	if err != nil {
@@ -1678,12 +1936,15 @@ func transferOwnershipOfOrganization(context interfaces.ContextWithSession) erro
 	var newOwnerOrganizationMemberRecord model.OrganizationMember
 	newOwnerOrganizationMemberRecordQuery := SELECT(table.OrganizationMember.AllColumns).
 		FROM(table.OrganizationMember).
-		WHERE(table.OrganizationMember.UniqueId.EQ(UUID(organizationUuid)).AND(table.OrganizationMember.UserId.EQ(UUID(newOwnerUuid)))).
+		WHERE(table.OrganizationMember.OrganizationId.EQ(UUID(organizationUuid)).AND(table.OrganizationMember.UserId.EQ(UUID(newOwnerUuid)))).
 		LIMIT(1)
 
 	err = newOwnerOrganizationMemberRecordQuery.QueryContext(context.Request().Context(), context.App.Db, &newOwnerOrganizationMemberRecord)
 
 	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusNotFound, "New owner is not a member of this organization")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Error fetching new owner organization member record")
 	}
 
@@ -1700,14 +1961,14 @@ func transferOwnershipOfOrganization(context interfaces.ContextWithSession) erro
 
 	stmt := WITH(updatedOrganizationOriginalOwner.AS(
 		table.OrganizationMember.UPDATE().
-			WHERE(table.OrganizationMember.UniqueId.EQ(UUID(organizationUuid)).
+			WHERE(table.OrganizationMember.OrganizationId.EQ(UUID(organizationUuid)).
 				AND(table.OrganizationMember.UserId.EQ(UUID(currentUserUuid)))).
 			SET(table.OrganizationMember.AccessLevel.SET(String(model.UserPermissionLevelEnum_Member.String()))).
 			RETURNING(table.OrganizationMember.AllColumns),
 	),
 		updatedOrganizationNewOwner.AS(
 			table.OrganizationMember.UPDATE().
-				WHERE(table.OrganizationMember.UniqueId.EQ(UUID(organizationUuid)).
+				WHERE(table.OrganizationMember.OrganizationId.EQ(UUID(organizationUuid)).
 					AND(table.OrganizationMember.UserId.EQ(UUID(newOwnerUuid)))).
 				SET(table.OrganizationMember.AccessLevel.SET(String(model.UserPermissionLevelEnum_Owner.String()))).
 				RETURNING(table.OrganizationMember.AllColumns),
@@ -1727,6 +1988,31 @@ func transferOwnershipOfOrganization(context interfaces.ContextWithSession) erro
 	return context.JSON(http.StatusOK, responseToReturn)
 }
 
+func getWhatsappBusinessAccountDetails(context interfaces.ContextWithSession) error {
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Invalid organization id")
+	}
+
+	var businessAccount model.WhatsappBusinessAccount
+
+	err = SELECT(table.WhatsappBusinessAccount.AllColumns).
+		FROM(table.WhatsappBusinessAccount).
+		WHERE(table.WhatsappBusinessAccount.OrganizationId.EQ(UUID(orgUuid))).
+		LIMIT(1).
+		QueryContext(context.Request().Context(), context.App.Db, &businessAccount)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusNotFound, "WhatsApp business account not configured")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Error fetching business account details")
+	}
+
+	return context.JSON(http.StatusOK, controller.BuildBusinessAccountDetails(&context.App, businessAccount, nil))
+}
+
 func handleUpdateWhatsappBusinessAccountDetails(context interfaces.ContextWithSession) error {
 
 	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
@@ -1741,7 +2027,10 @@ func handleUpdateWhatsappBusinessAccountDetails(context interfaces.ContextWithSe
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	// ! TODO: sanity check if the details are valid
+	if payload.BusinessAccountId == "" || payload.AccessToken == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Business account id and access token are required")
+	}
+
 	businessAccountRecordQuery := SELECT(table.WhatsappBusinessAccount.AllColumns).
 		FROM(table.WhatsappBusinessAccount).
 		WHERE(table.WhatsappBusinessAccount.OrganizationId.EQ(UUID(orgUuid))).
@@ -1751,83 +2040,150 @@ func handleUpdateWhatsappBusinessAccountDetails(context interfaces.ContextWithSe
 
 	err = businessAccountRecordQuery.QueryContext(context.Request().Context(), context.App.Db, &businessAccount)
 
+	isNewBusinessAccount := false
+
 	if err != nil {
-		if err.Error() == qrm.ErrNoRows.Error() {
-			// create a new record the user is updating its details for the first time
+		if err.Error() != qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		// the user is updating its details for the first time
+		isNewBusinessAccount = true
+	}
 
-			webhookSecret, _ := utils.GenerateUniqueWebhookSecret(payload.BusinessAccountId, orgUuid.String(), context.App.Koa.String("app.encryption_key"))
+	// * the stored access token is only ever sent to the clients masked, a masked token means the stored one is kept
+	accessToken := payload.AccessToken
 
-			insertQuery := table.WhatsappBusinessAccount.
-				INSERT(table.WhatsappBusinessAccount.MutableColumns).
-				MODEL(model.WhatsappBusinessAccount{
-					OrganizationId: orgUuid,
-					AccountId:      payload.BusinessAccountId,
-					AccessToken:    payload.AccessToken,
-					WebhookSecret:  webhookSecret,
-					CreatedAt:      time.Now(),
-					UpdatedAt:      time.Now(),
-				}).
-				RETURNING(table.WhatsappBusinessAccount.AllColumns)
+	if secret_service.IsMasked(accessToken) {
+		if isNewBusinessAccount {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid access token")
+		}
 
-			err = insertQuery.QueryContext(context.Request().Context(), context.App.Db, &businessAccount)
+		accessToken, err = context.App.Secrets.Decrypt(businessAccount.AccessToken)
 
-			if err != nil {
-				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-			}
+		if err != nil {
+			context.App.Logger.Error("error decrypting access token", "error", err.Error())
+			return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
+		}
+	}
 
-			responseToReturn := api_types.WhatsAppBusinessAccountDetailsSchema{
-				BusinessAccountId: businessAccount.AccountId,
-				AccessToken:       businessAccount.AccessToken,
-				WebhookSecret:     webhookSecret,
-			}
+	// verify the credentials before storing them, so that campaigns and conversations do not fail later on
+	healthReport, err := whatsapp_service.CheckCredentials(context.Request().Context(), payload.BusinessAccountId, accessToken)
 
-			return context.JSON(http.StatusOK, responseToReturn)
+	if err != nil {
+		context.App.Logger.Error("error checking whatsapp business account credentials", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to verify the credentials with WhatsApp, please try again later")
+	}
 
-		} else {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-		}
+	if !healthReport.IsValid() {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid WhatsApp business account credentials: "+healthReport.Error)
 	}
 
-	var updatedBusinessAccount model.WhatsappBusinessAccount
+	wapiClient := wapi.New(&wapi.ClientConfig{
+		BusinessAccountId: payload.BusinessAccountId,
+		ApiAccessToken:    accessToken,
+	})
 
-	webhookSecret := businessAccount.WebhookSecret
+	phoneNumbersResponse, err := wapiClient.Business.PhoneNumber.FetchAll(true)
 
-	if businessAccount.AccountId != payload.BusinessAccountId {
-		// changing the business account id, let change the webhook secret too
-		webhookSecret, _ = utils.GenerateUniqueWebhookSecret(payload.BusinessAccountId, orgUuid.String(), context.App.Koa.String("app.encryption_key"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Unable to fetch the phone numbers of the business account: "+err.Error())
 	}
 
-	// update the record
-	updateQuery := table.WhatsappBusinessAccount.UPDATE(
-		table.WhatsappBusinessAccount.AccessToken,
-		table.WhatsappBusinessAccount.AccountId,
-		table.WhatsappBusinessAccount.WebhookSecret,
-		table.WhatsappBusinessAccount.OrganizationId,
-		table.WhatsappBusinessAccount.UniqueId,
-	).
-		MODEL(model.WhatsappBusinessAccount{
-			AccountId:      payload.BusinessAccountId,
-			AccessToken:    payload.AccessToken,
-			OrganizationId: orgUuid,
-			UniqueId:       businessAccount.UniqueId,
-			WebhookSecret:  webhookSecret,
-		}).
-		WHERE(table.WhatsappBusinessAccount.UniqueId.EQ(UUID(businessAccount.UniqueId))).
-		RETURNING(table.WhatsappBusinessAccount.AllColumns)
+	err = whatsapp_service.SubscribeApp(context.Request().Context(), payload.BusinessAccountId, accessToken)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Unable to subscribe to the webhooks of the business account: "+err.Error())
+	}
 
-	err = updateQuery.QueryContext(context.Request().Context(), context.App.Db, &updatedBusinessAccount)
+	encryptedAccessToken, err := context.App.Secrets.Encrypt(accessToken)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		context.App.Logger.Error("error encrypting access token", "error", err.Error())
+		return echo.NewHTTPError(http.StatusInternalServerError, "Something went wrong while processing your request.")
 	}
 
-	responseToReturn := api_types.WhatsAppBusinessAccountDetailsSchema{
-		BusinessAccountId: updatedBusinessAccount.AccountId,
-		AccessToken:       updatedBusinessAccount.AccessToken,
-		WebhookSecret:     updatedBusinessAccount.WebhookSecret,
+	var updatedBusinessAccount model.WhatsappBusinessAccount
+
+	if isNewBusinessAccount {
+		webhookSecret, _ := utils.GenerateUniqueWebhookSecret(payload.BusinessAccountId, orgUuid.String(), context.App.Koa.String("app.encryption_key"))
+
+		insertQuery := table.WhatsappBusinessAccount.
+			INSERT(table.WhatsappBusinessAccount.MutableColumns).
+			MODEL(model.WhatsappBusinessAccount{
+				OrganizationId: orgUuid,
+				AccountId:      payload.BusinessAccountId,
+				AccessToken:    encryptedAccessToken,
+				WebhookSecret:  webhookSecret,
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
+				HealthStatus:   model.WhatsappBusinessAccountHealthStatusEnum_Unknown,
+			}).
+			RETURNING(table.WhatsappBusinessAccount.AllColumns)
+
+		err = insertQuery.QueryContext(context.Request().Context(), context.App.Db, &updatedBusinessAccount)
+
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	} else {
+		webhookSecret := businessAccount.WebhookSecret
+
+		if businessAccount.AccountId != payload.BusinessAccountId {
+			// changing the business account id, let change the webhook secret too
+			webhookSecret, _ = utils.GenerateUniqueWebhookSecret(payload.BusinessAccountId, orgUuid.String(), context.App.Koa.String("app.encryption_key"))
+		}
+
+		// update the record
+		updateQuery := table.WhatsappBusinessAccount.UPDATE(
+			table.WhatsappBusinessAccount.AccessToken,
+			table.WhatsappBusinessAccount.AccountId,
+			table.WhatsappBusinessAccount.WebhookSecret,
+			table.WhatsappBusinessAccount.OrganizationId,
+			table.WhatsappBusinessAccount.UniqueId,
+		).
+			MODEL(model.WhatsappBusinessAccount{
+				AccountId:      payload.BusinessAccountId,
+				AccessToken:    encryptedAccessToken,
+				OrganizationId: orgUuid,
+				UniqueId:       businessAccount.UniqueId,
+				WebhookSecret:  webhookSecret,
+			}).
+			WHERE(table.WhatsappBusinessAccount.UniqueId.EQ(UUID(businessAccount.UniqueId))).
+			RETURNING(table.WhatsappBusinessAccount.AllColumns)
+
+		err = updateQuery.QueryContext(context.Request().Context(), context.App.Db, &updatedBusinessAccount)
+
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
 	}
 
-	return context.JSON(http.StatusOK, responseToReturn)
+	savedBusinessAccount, err := whatsapp_service.SaveHealthReport(context.Request().Context(), context.App.Db, updatedBusinessAccount.UniqueId, *healthReport)
+
+	if err != nil {
+		context.App.Logger.Error("error saving whatsapp business account health", "error", err.Error())
+	} else {
+		updatedBusinessAccount = *savedBusinessAccount
+	}
+
+	controller.InvalidateOrganizationAuthorizationCache(&context.App, orgUuid.String())
+
+	phoneNumbers := make([]api_types.PhoneNumberSchema, 0, len(phoneNumbersResponse.Data))
+	for _, phoneNumber := range phoneNumbersResponse.Data {
+		phoneNumberToReturn := api_types.PhoneNumberSchema{
+			DisplayPhoneNumber: phoneNumber.DisplayPhoneNumber,
+			Id:                 phoneNumber.Id,
+			PlatformType:       phoneNumber.PlatformType,
+			QualityRating:      phoneNumber.QualityRating,
+			VerifiedName:       phoneNumber.VerifiedName,
+		}
+		if phoneNumber.CodeVerification.Status != "" {
+			phoneNumberToReturn.CodeVerificationStatus.Status = &phoneNumber.CodeVerification.Status
+		}
+		phoneNumbers = append(phoneNumbers, phoneNumberToReturn)
+	}
+
+	return context.JSON(http.StatusOK, controller.BuildBusinessAccountDetails(&context.App, updatedBusinessAccount, phoneNumbers))
 }
 
 func getFullAiConfiguration(context interfaces.ContextWithSession) error {
@@ -1859,38 +2215,9 @@ func getFullAiConfiguration(context interfaces.ContextWithSession) error {
 		AiConfiguration: api_types.FullAiConfiguration{
 			IsEnabled: organization.IsAiEnabled,
 			Model:     model,
-			ApiKey:    organization.AiApiKey,
+			ApiKey:    context.App.Secrets.MaskStored(organization.AiApiKey),
 		},
 	}
 
 	return context.JSON(http.StatusOK, responseToReturn)
 }
-
-func _verifyAccessToOrganization(context interfaces.ContextWithSession, userId, organizationId uuid.UUID) bool {
-
-	orgQuery := SELECT(table.OrganizationMember.AllColumns, table.Organization.AllColumns).
-		FROM(table.OrganizationMember.
-			LEFT_JOIN(table.Organization, table.Organization.UniqueId.EQ(table.OrganizationMember.OrganizationId)),
-		).
-		WHERE(table.OrganizationMember.UserId.EQ(UUID(userId)).
-			AND(table.OrganizationMember.OrganizationId.EQ(UUID(organizationId))))
-
-	var dest struct {
-		model.OrganizationMember
-		Organization model.Organization
-	}
-
-	err := orgQuery.Query(context.App.Db, &dest)
-
-	context.App.Logger.Info("dest", dest)
-
-	if err != nil {
-		return false
-	}
-
-	if dest.Organization.UniqueId.String() == "" {
-		return false
-	}
-
-	return true
-}
@@ -0,0 +1,159 @@
+package presence_controller
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	controller "github.com/wapikit/wapikit/api/controllers"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/api_server_events"
+	"github.com/wapikit/wapikit/internal/core/presence_service"
+	"github.com/wapikit/wapikit/internal/interfaces"
+
+	"github.com/go-jet/jet/qrm"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+type PresenceController struct {
+	controller.BaseController `json:"-,inline"`
+}
+
+func NewPresenceController() *PresenceController {
+	return &PresenceController{
+		BaseController: controller.BaseController{
+			Name:        "Presence Controller",
+			RestApiPath: "/api/presence",
+			Routes: []interfaces.Route{
+				{
+					Path:                    "/api/presence",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getOrganizationPresence),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    30,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+					},
+				},
+				{
+					Path:                    "/api/presence",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(updatePresence),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    20,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getOrganizationPresence(context interfaces.ContextWithSession) error {
+	if context.App.Redis == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Presence is not available")
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var members []model.OrganizationMember
+
+	err = SELECT(table.OrganizationMember.AllColumns).
+		FROM(table.OrganizationMember).
+		WHERE(table.OrganizationMember.OrganizationId.EQ(UUID(orgUuid))).
+		ORDER_BY(table.OrganizationMember.CreatedAt.ASC()).
+		QueryContext(context.Request().Context(), context.App.Db, &members)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	userIds := make([]string, 0, len(members))
+	for _, member := range members {
+		userIds = append(userIds, member.UserId.String())
+	}
+
+	presences, err := presence_service.GetPresences(context.Request().Context(), context.App.Redis, orgUuid.String(), userIds)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	membersToReturn := make([]api_types.MemberPresenceSchema, 0, len(members))
+	for _, member := range members {
+		membersToReturn = append(membersToReturn, presences[member.UserId.String()].ToSchema(member.UniqueId.String()))
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetOrganizationPresenceResponseSchema{
+		Members: membersToReturn,
+	})
+}
+
+func updatePresence(context interfaces.ContextWithSession) error {
+	payload := new(api_types.UpdatePresenceSchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if context.App.Redis == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Presence is not available")
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	userUuid, err := uuid.Parse(context.Session.User.UniqueId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var member model.OrganizationMember
+
+	err = SELECT(table.OrganizationMember.AllColumns).
+		FROM(table.OrganizationMember).
+		WHERE(
+			table.OrganizationMember.OrganizationId.EQ(UUID(orgUuid)).
+				AND(table.OrganizationMember.UserId.EQ(UUID(userUuid))),
+		).
+		LIMIT(1).
+		QueryContext(context.Request().Context(), context.App.Db, &member)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusNotFound, "Organization member not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	presence, isChanged, err := presence_service.SetStatus(context.Request().Context(), context.App.Redis, orgUuid.String(), userUuid.String(), payload.Status)
+	if err != nil {
+		if err == presence_service.ErrInvalidStatus {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	presenceToReturn := presence.ToSchema(member.UniqueId.String())
+
+	if isChanged {
+		event := api_server_events.NewPresenceChangedEvent(orgUuid.String(), presenceToReturn)
+		context.App.Redis.PublishMessageToRedisChannel(context.App.Constants.RedisEventChannelName, event.ToJson())
+	}
+
+	return context.JSON(http.StatusOK, api_types.UpdatePresenceResponseSchema{
+		Presence: presenceToReturn,
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/labstack/echo/v4"
 	controller "github.com/wapikit/wapikit/api/controllers"
 	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
 	"github.com/wapikit/wapikit/internal/core/utils"
 	"github.com/wapikit/wapikit/internal/interfaces"
 
@@ -184,12 +185,15 @@ func getOrganizationRoles(context interfaces.ContextWithSession) error {
 
 			roleId := role.UniqueId.String()
 
+			isTwoFactorRequired := role.IsTwoFactorRequired
+
 			roleToReturn := api_types.OrganizationRoleSchema{
 
-				Description: role.Description,
-				Name:        role.Name,
-				Permissions: permissions,
-				UniqueId:    roleId,
+				Description:         role.Description,
+				Name:                role.Name,
+				Permissions:         permissions,
+				UniqueId:            roleId,
+				IsTwoFactorRequired: &isTwoFactorRequired,
 			}
 
 			rolesToReturn = append(rolesToReturn, roleToReturn)
@@ -213,31 +217,14 @@ func getOrganizationRoles(context interfaces.ContextWithSession) error {
 }
 
 func getRoleById(context interfaces.ContextWithSession) error {
-	roleId := context.Param("id")
-	if roleId == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid role id")
-	}
-
-	roleUuid, _ := uuid.Parse(roleId)
-	roleQuery := SELECT(table.OrganizationRole.AllColumns).FROM(table.OrganizationRole).WHERE(table.OrganizationRole.UniqueId.EQ(UUID(roleUuid))).LIMIT(1)
-
 	var dest model.OrganizationRole
-	err := roleQuery.QueryContext(context.Request().Context(), context.App.Db, &dest)
 
+	roleUuid, err := controller.FetchOwned(context, tenant_service.OrganizationRole, &dest)
 	if err != nil {
-		if err.Error() == qrm.ErrNoRows.Error() {
-			role := new(api_types.OrganizationRoleSchema)
-			return context.JSON(http.StatusOK, api_types.GetRoleByIdResponseSchema{
-				Role: *role,
-			})
-		} else {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-		}
+		return err
 	}
 
-	if dest.OrganizationId.String() != context.Session.User.OrganizationId {
-		return echo.NewHTTPError(http.StatusForbidden, "You do not have access to this resource")
-	}
+	roleId := roleUuid.String()
 
 	var permissionToReturn []api_types.RolePermissionEnum
 
@@ -251,10 +238,11 @@ func getRoleById(context interfaces.ContextWithSession) error {
 	}
 
 	role := api_types.OrganizationRoleSchema{
-		Description: dest.Description,
-		Name:        dest.Name,
-		Permissions: permissionToReturn,
-		UniqueId:    roleId,
+		Description:         dest.Description,
+		Name:                dest.Name,
+		Permissions:         permissionToReturn,
+		UniqueId:            roleId,
+		IsTwoFactorRequired: &dest.IsTwoFactorRequired,
 	}
 
 	return context.JSON(http.StatusOK, role)
@@ -290,6 +278,8 @@ func createRole(context interfaces.ContextWithSession) error {
 			OrganizationId: orgUuid,
 			CreatedAt:      time.Now(),
 			UpdatedAt:      time.Now(),
+
+			IsTwoFactorRequired: payload.IsTwoFactorRequired != nil && *payload.IsTwoFactorRequired,
 		}).
 		RETURNING(table.OrganizationRole.AllColumns).
 		QueryContext(context.Request().Context(), context.App.Db, &insertedRole)
@@ -310,10 +300,11 @@ func createRole(context interfaces.ContextWithSession) error {
 	}
 
 	roleToReturn := api_types.OrganizationRoleSchema{
-		Description: insertedRole.Description,
-		Name:        insertedRole.Name,
-		Permissions: permissionsToReturn,
-		UniqueId:    insertedRole.UniqueId.String(),
+		Description:         insertedRole.Description,
+		Name:                insertedRole.Name,
+		Permissions:         permissionsToReturn,
+		UniqueId:            insertedRole.UniqueId.String(),
+		IsTwoFactorRequired: &insertedRole.IsTwoFactorRequired,
 	}
 
 	return context.JSON(http.StatusCreated, api_types.CreateNewRoleResponseSchema{
@@ -325,33 +316,11 @@ func deleteRoleById(context interfaces.ContextWithSession) error {
 	// ! destructive endpoint, we are currently allowing deletion of roles even though its being assigned to user,
 	// ! at the frontend, there must be double confirmation before deleting a role
 
-	roleId := context.Param("id")
-	if roleId == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid role id")
-	}
-
-	roleUuid, _ := uuid.Parse(roleId)
-
-	// check if the role exists and belongs to the organization
-
 	var role model.OrganizationRole
 
-	existingRoleQuery := SELECT(table.OrganizationRole.AllColumns).
-		WHERE(table.OrganizationRole.UniqueId.EQ(UUID(roleUuid))).
-		LIMIT(1)
-
-	err := existingRoleQuery.QueryContext(context.Request().Context(), context.App.Db, &role)
-
+	roleUuid, err := controller.FetchOwned(context, tenant_service.OrganizationRole, &role)
 	if err != nil {
-		if err.Error() == qrm.ErrNoRows.Error() {
-			return echo.NewHTTPError(http.StatusNotFound, "Role not found")
-		} else {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-		}
-	}
-
-	if role.OrganizationId.String() != context.Session.User.OrganizationId {
-		return echo.NewHTTPError(http.StatusForbidden, "You do not have access to this resource")
+		return err
 	}
 
 	roleAssignmentDeleteQuery := table.RoleAssignment.DELETE().WHERE(table.RoleAssignment.OrganizationRoleId.EQ(UUID(roleUuid)))
@@ -372,6 +341,9 @@ func deleteRoleById(context interfaces.ContextWithSession) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	// * the members of the role lose its permissions right away, not once the cached ones expire
+	controller.InvalidateOrganizationAuthorizationCache(&context.App, context.Session.User.OrganizationId)
+
 	response := api_types.DeleteRoleByIdResponseSchema{
 		Data: true,
 	}
@@ -380,36 +352,16 @@ func deleteRoleById(context interfaces.ContextWithSession) error {
 }
 
 func updateRoleById(context interfaces.ContextWithSession) error {
-	roleId := context.Param("id")
-
-	if roleId == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid role id")
-	}
-
-	roleUuid, _ := uuid.Parse(roleId)
-
 	payload := new(api_types.RoleUpdateSchema)
-
-	// check if the role exists and belongs to the organization
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
 
 	var role model.OrganizationRole
 
-	existingRoleQuery := SELECT(table.OrganizationRole.AllColumns).
-		WHERE(table.OrganizationRole.UniqueId.EQ(UUID(roleUuid))).
-		LIMIT(1)
-
-	err := existingRoleQuery.QueryContext(context.Request().Context(), context.App.Db, &role)
-
+	roleUuid, err := controller.FetchOwned(context, tenant_service.OrganizationRole, &role)
 	if err != nil {
-		if err.Error() == qrm.ErrNoRows.Error() {
-			return echo.NewHTTPError(http.StatusNotFound, "Role not found")
-		} else {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-		}
-	}
-
-	if role.OrganizationId.String() != context.Session.User.OrganizationId {
-		return echo.NewHTTPError(http.StatusForbidden, "You do not have access to this resource")
+		return err
 	}
 
 	var updatedPermissions string
@@ -420,10 +372,15 @@ func updateRoleById(context interfaces.ContextWithSession) error {
 
 	var updatedRole model.OrganizationRole
 
+	isTwoFactorRequired := role.IsTwoFactorRequired
+	if payload.IsTwoFactorRequired != nil {
+		isTwoFactorRequired = *payload.IsTwoFactorRequired
+	}
+
 	// update the role
 	updateRoleQuery := table.OrganizationRole.
-		UPDATE(table.OrganizationRole.Name, table.OrganizationRole.Description, table.OrganizationRole.Permissions).
-		SET(payload.Name, *payload.Description, updatedPermissions).
+		UPDATE(table.OrganizationRole.Name, table.OrganizationRole.Description, table.OrganizationRole.Permissions, table.OrganizationRole.IsTwoFactorRequired).
+		SET(payload.Name, *payload.Description, updatedPermissions, Bool(isTwoFactorRequired)).
 		WHERE(table.OrganizationRole.UniqueId.EQ(UUID(roleUuid))).
 		RETURNING(table.OrganizationRole.AllColumns)
 
@@ -439,11 +396,14 @@ func updateRoleById(context interfaces.ContextWithSession) error {
 		permissionsToReturn = append(permissionsToReturn, api_types.RolePermissionEnum(perm))
 	}
 
+	controller.InvalidateOrganizationAuthorizationCache(&context.App, context.Session.User.OrganizationId)
+
 	roleToReturn := api_types.OrganizationRoleSchema{
-		Description: updatedRole.Description,
-		Name:        updatedRole.Name,
-		Permissions: permissionsToReturn,
-		UniqueId:    roleId,
+		Description:         updatedRole.Description,
+		Name:                updatedRole.Name,
+		Permissions:         permissionsToReturn,
+		UniqueId:            roleUuid.String(),
+		IsTwoFactorRequired: &updatedRole.IsTwoFactorRequired,
 	}
 
 	return context.JSON(http.StatusOK, api_types.UpdateRoleByIdResponseSchema{
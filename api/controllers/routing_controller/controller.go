@@ -0,0 +1,563 @@
+package routing_controller
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	controller "github.com/wapikit/wapikit/api/controllers"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
+	"github.com/wapikit/wapikit/internal/interfaces"
+
+	"github.com/go-jet/jet/qrm"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+type RoutingController struct {
+	controller.BaseController `json:"-,inline"`
+}
+
+func NewRoutingController() *RoutingController {
+	return &RoutingController{
+		BaseController: controller.BaseController{
+			Name:        "Routing Controller",
+			RestApiPath: "/api/routing",
+			Routes: []interfaces.Route{
+				{
+					Path:                    "/api/routing/rules",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getRoutingRules),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+					},
+				},
+				{
+					Path:                    "/api/routing/rules",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(createRoutingRule),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateOrganization,
+						},
+					},
+				},
+				{
+					Path:                    "/api/routing/rules/:id",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getRoutingRuleById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+					},
+				},
+				{
+					Path:                    "/api/routing/rules/:id",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(updateRoutingRuleById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateOrganization,
+						},
+					},
+				},
+				{
+					Path:                    "/api/routing/rules/:id",
+					Method:                  http.MethodDelete,
+					Handler:                 interfaces.HandlerWithSession(deleteRoutingRuleById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateOrganization,
+						},
+					},
+				},
+				{
+					Path:                    "/api/routing/availability",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(updateAvailability),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    20,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getRoutingRules(context interfaces.ContextWithSession) error {
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var rules []model.ConversationRoutingRule
+
+	err = SELECT(table.ConversationRoutingRule.AllColumns).
+		FROM(table.ConversationRoutingRule).
+		WHERE(table.ConversationRoutingRule.OrganizationId.EQ(UUID(orgUuid))).
+		ORDER_BY(table.ConversationRoutingRule.Priority.ASC(), table.ConversationRoutingRule.CreatedAt.ASC()).
+		QueryContext(context.Request().Context(), context.App.Db, &rules)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	rulesToReturn := []api_types.RoutingRuleSchema{}
+
+	if len(rules) == 0 {
+		return context.JSON(http.StatusOK, api_types.GetRoutingRulesResponseSchema{
+			Rules: rulesToReturn,
+		})
+	}
+
+	ruleIds := make([]Expression, 0, len(rules))
+	for _, rule := range rules {
+		ruleIds = append(ruleIds, UUID(rule.UniqueId))
+	}
+
+	var ruleMembers []model.ConversationRoutingRuleMember
+
+	err = SELECT(table.ConversationRoutingRuleMember.AllColumns).
+		FROM(table.ConversationRoutingRuleMember).
+		WHERE(table.ConversationRoutingRuleMember.RoutingRuleId.IN(ruleIds...)).
+		QueryContext(context.Request().Context(), context.App.Db, &ruleMembers)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	for _, rule := range rules {
+		rulesToReturn = append(rulesToReturn, buildRoutingRule(rule, ruleMembers))
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetRoutingRulesResponseSchema{
+		Rules: rulesToReturn,
+	})
+}
+
+func getRoutingRuleById(context interfaces.ContextWithSession) error {
+	rule, err := fetchRoutingRule(context)
+	if err != nil {
+		return err
+	}
+
+	ruleMembers, err := fetchRoutingRuleMembers(context, rule.UniqueId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetRoutingRuleByIdResponseSchema{
+		Rule: buildRoutingRule(*rule, ruleMembers),
+	})
+}
+
+func createRoutingRule(context interfaces.ContextWithSession) error {
+	payload := new(api_types.NewRoutingRuleSchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	rule, memberUuids, err := parseRoutingRulePayload(context, orgUuid, payload)
+	if err != nil {
+		return err
+	}
+
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	var insertedRule model.ConversationRoutingRule
+
+	err = table.ConversationRoutingRule.INSERT(table.ConversationRoutingRule.MutableColumns).
+		MODEL(rule).
+		RETURNING(table.ConversationRoutingRule.AllColumns).
+		QueryContext(context.Request().Context(), tx, &insertedRule)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	ruleMembers, err := replaceRoutingRuleMembers(context, tx, insertedRule.UniqueId, memberUuids)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusCreated, api_types.CreateRoutingRuleResponseSchema{
+		Rule: buildRoutingRule(insertedRule, ruleMembers),
+	})
+}
+
+func updateRoutingRuleById(context interfaces.ContextWithSession) error {
+	existingRule, err := fetchRoutingRule(context)
+	if err != nil {
+		return err
+	}
+
+	payload := new(api_types.NewRoutingRuleSchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	rule, memberUuids, err := parseRoutingRulePayload(context, existingRule.OrganizationId, payload)
+	if err != nil {
+		return err
+	}
+
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	var updatedRule model.ConversationRoutingRule
+
+	err = table.ConversationRoutingRule.UPDATE(
+		table.ConversationRoutingRule.Name,
+		table.ConversationRoutingRule.Strategy,
+		table.ConversationRoutingRule.Priority,
+		table.ConversationRoutingRule.IsEnabled,
+		table.ConversationRoutingRule.PhoneNumberId,
+		table.ConversationRoutingRule.TagId,
+		table.ConversationRoutingRule.MaxConcurrentConversations,
+		table.ConversationRoutingRule.UpdatedAt,
+	).
+		MODEL(rule).
+		WHERE(table.ConversationRoutingRule.UniqueId.EQ(UUID(existingRule.UniqueId))).
+		RETURNING(table.ConversationRoutingRule.AllColumns).
+		QueryContext(context.Request().Context(), tx, &updatedRule)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	ruleMembers, err := replaceRoutingRuleMembers(context, tx, updatedRule.UniqueId, memberUuids)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.UpdateRoutingRuleByIdResponseSchema{
+		Rule: buildRoutingRule(updatedRule, ruleMembers),
+	})
+}
+
+func deleteRoutingRuleById(context interfaces.ContextWithSession) error {
+	rule, err := fetchRoutingRule(context)
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ConversationRoutingRuleMember.DELETE().
+		WHERE(table.ConversationRoutingRuleMember.RoutingRuleId.EQ(UUID(rule.UniqueId))).
+		ExecContext(context.Request().Context(), context.App.Db)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	_, err = table.ConversationRoutingRule.DELETE().
+		WHERE(table.ConversationRoutingRule.UniqueId.EQ(UUID(rule.UniqueId))).
+		ExecContext(context.Request().Context(), context.App.Db)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.DeleteRoutingRuleByIdResponseSchema{
+		Data: true,
+	})
+}
+
+func updateAvailability(context interfaces.ContextWithSession) error {
+	payload := new(api_types.UpdateAvailabilitySchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	userUuid, err := uuid.Parse(context.Session.User.UniqueId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var updatedMember model.OrganizationMember
+
+	err = table.OrganizationMember.UPDATE(table.OrganizationMember.IsAvailable, table.OrganizationMember.UpdatedAt).
+		SET(Bool(payload.IsAvailable), TimestampzT(time.Now())).
+		WHERE(
+			table.OrganizationMember.OrganizationId.EQ(UUID(orgUuid)).
+				AND(table.OrganizationMember.UserId.EQ(UUID(userUuid))),
+		).
+		RETURNING(table.OrganizationMember.AllColumns).
+		QueryContext(context.Request().Context(), context.App.Db, &updatedMember)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return echo.NewHTTPError(http.StatusNotFound, "Organization member not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.UpdateAvailabilityResponseSchema{
+		IsAvailable: updatedMember.IsAvailable,
+	})
+}
+
+// fetchRoutingRule loads the rule from the id param of the route, and makes sure it belongs to the organization of the user
+func fetchRoutingRule(context interfaces.ContextWithSession) (*model.ConversationRoutingRule, error) {
+	var rule model.ConversationRoutingRule
+
+	if _, err := controller.FetchOwned(context, tenant_service.ConversationRoutingRule, &rule); err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func fetchRoutingRuleMembers(context interfaces.ContextWithSession, ruleId uuid.UUID) ([]model.ConversationRoutingRuleMember, error) {
+	var ruleMembers []model.ConversationRoutingRuleMember
+
+	err := SELECT(table.ConversationRoutingRuleMember.AllColumns).
+		FROM(table.ConversationRoutingRuleMember).
+		WHERE(table.ConversationRoutingRuleMember.RoutingRuleId.EQ(UUID(ruleId))).
+		QueryContext(context.Request().Context(), context.App.Db, &ruleMembers)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return ruleMembers, nil
+}
+
+// parseRoutingRulePayload validates the payload, the tag and the members of the rule must belong to the organization
+func parseRoutingRulePayload(context interfaces.ContextWithSession, orgUuid uuid.UUID, payload *api_types.NewRoutingRuleSchema) (model.ConversationRoutingRule, []uuid.UUID, error) {
+	rule := model.ConversationRoutingRule{
+		OrganizationId: orgUuid,
+		Name:           payload.Name,
+		IsEnabled:      payload.IsEnabled == nil || *payload.IsEnabled,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if payload.Name == "" {
+		return rule, nil, echo.NewHTTPError(http.StatusBadRequest, "Name of the routing rule is required")
+	}
+
+	strategy := new(model.ConversationRoutingStrategyEnum)
+	if err := strategy.Scan(string(payload.Strategy)); err != nil {
+		return rule, nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid routing strategy")
+	}
+	rule.Strategy = *strategy
+
+	if payload.Priority != nil {
+		rule.Priority = int32(*payload.Priority)
+	}
+
+	if payload.PhoneNumberId != nil && *payload.PhoneNumberId != "" {
+		rule.PhoneNumberId = payload.PhoneNumberId
+	}
+
+	if payload.MaxConcurrentConversations != nil {
+		if *payload.MaxConcurrentConversations < 1 {
+			return rule, nil, echo.NewHTTPError(http.StatusBadRequest, "Max concurrent conversations must be at least 1")
+		}
+		maxConcurrentConversations := int32(*payload.MaxConcurrentConversations)
+		rule.MaxConcurrentConversations = &maxConcurrentConversations
+	}
+
+	if payload.TagId != nil && *payload.TagId != "" {
+		tagUuid, err := uuid.Parse(*payload.TagId)
+		if err != nil {
+			return rule, nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid tag id")
+		}
+
+		var tag model.Tag
+
+		err = SELECT(table.Tag.AllColumns).
+			FROM(table.Tag).
+			WHERE(
+				table.Tag.UniqueId.EQ(UUID(tagUuid)).
+					AND(table.Tag.OrganizationId.EQ(UUID(orgUuid))),
+			).
+			LIMIT(1).
+			QueryContext(context.Request().Context(), context.App.Db, &tag)
+
+		if err != nil {
+			if err.Error() == qrm.ErrNoRows.Error() {
+				return rule, nil, echo.NewHTTPError(http.StatusBadRequest, "Tag not found")
+			}
+			return rule, nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+
+		rule.TagId = &tag.UniqueId
+	}
+
+	if payload.MemberIds == nil || len(*payload.MemberIds) == 0 {
+		return rule, nil, nil
+	}
+
+	memberUuids := make([]uuid.UUID, 0, len(*payload.MemberIds))
+	memberIds := make([]Expression, 0, len(*payload.MemberIds))
+	for _, memberId := range *payload.MemberIds {
+		memberUuid, err := uuid.Parse(memberId)
+		if err != nil {
+			return rule, nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid member id")
+		}
+		memberUuids = append(memberUuids, memberUuid)
+		memberIds = append(memberIds, UUID(memberUuid))
+	}
+
+	var members []model.OrganizationMember
+
+	err := SELECT(table.OrganizationMember.UniqueId).
+		FROM(table.OrganizationMember).
+		WHERE(
+			table.OrganizationMember.UniqueId.IN(memberIds...).
+				AND(table.OrganizationMember.OrganizationId.EQ(UUID(orgUuid))),
+		).
+		QueryContext(context.Request().Context(), context.App.Db, &members)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return rule, nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	isMember := make(map[uuid.UUID]bool, len(members))
+	for _, member := range members {
+		isMember[member.UniqueId] = true
+	}
+
+	for _, memberUuid := range memberUuids {
+		if !isMember[memberUuid] {
+			return rule, nil, echo.NewHTTPError(http.StatusBadRequest, "Organization member not found")
+		}
+	}
+
+	return rule, memberUuids, nil
+}
+
+func replaceRoutingRuleMembers(context interfaces.ContextWithSession, db *sql.Tx, ruleId uuid.UUID, memberUuids []uuid.UUID) ([]model.ConversationRoutingRuleMember, error) {
+	_, err := table.ConversationRoutingRuleMember.DELETE().
+		WHERE(table.ConversationRoutingRuleMember.RoutingRuleId.EQ(UUID(ruleId))).
+		ExecContext(context.Request().Context(), db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ruleMembers := make([]model.ConversationRoutingRuleMember, 0, len(memberUuids))
+	isAdded := make(map[uuid.UUID]bool, len(memberUuids))
+	for _, memberUuid := range memberUuids {
+		if isAdded[memberUuid] {
+			continue
+		}
+		isAdded[memberUuid] = true
+		ruleMembers = append(ruleMembers, model.ConversationRoutingRuleMember{
+			RoutingRuleId:        ruleId,
+			OrganizationMemberId: memberUuid,
+			CreatedAt:            time.Now(),
+			UpdatedAt:            time.Now(),
+		})
+	}
+
+	if len(ruleMembers) == 0 {
+		return ruleMembers, nil
+	}
+
+	_, err = table.ConversationRoutingRuleMember.INSERT(table.ConversationRoutingRuleMember.AllColumns).
+		MODELS(ruleMembers).
+		ExecContext(context.Request().Context(), db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ruleMembers, nil
+}
+
+func buildRoutingRule(rule model.ConversationRoutingRule, ruleMembers []model.ConversationRoutingRuleMember) api_types.RoutingRuleSchema {
+	memberIds := []string{}
+	for _, ruleMember := range ruleMembers {
+		if ruleMember.RoutingRuleId == rule.UniqueId {
+			memberIds = append(memberIds, ruleMember.OrganizationMemberId.String())
+		}
+	}
+
+	ruleToReturn := api_types.RoutingRuleSchema{
+		UniqueId:      rule.UniqueId.String(),
+		CreatedAt:     rule.CreatedAt,
+		Name:          rule.Name,
+		Strategy:      api_types.ConversationRoutingStrategyEnum(rule.Strategy),
+		Priority:      int(rule.Priority),
+		IsEnabled:     rule.IsEnabled,
+		PhoneNumberId: rule.PhoneNumberId,
+		MemberIds:     memberIds,
+	}
+
+	if rule.TagId != nil {
+		tagId := rule.TagId.String()
+		ruleToReturn.TagId = &tagId
+	}
+
+	if rule.MaxConcurrentConversations != nil {
+		maxConcurrentConversations := int(*rule.MaxConcurrentConversations)
+		ruleToReturn.MaxConcurrentConversations = &maxConcurrentConversations
+	}
+
+	return ruleToReturn
+}
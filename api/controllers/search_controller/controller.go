@@ -0,0 +1,202 @@
+package search_controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	controller "github.com/wapikit/wapikit/api/controllers"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/search_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+	"github.com/wapikit/wapikit/internal/interfaces"
+
+	"github.com/go-jet/jet/qrm"
+	. "github.com/go-jet/jet/v2/postgres"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+type SearchController struct {
+	controller.BaseController `json:"-,inline"`
+}
+
+func NewSearchController() *SearchController {
+	return &SearchController{
+		BaseController: controller.BaseController{
+			Name:        "Search Controller",
+			RestApiPath: "/api/search",
+			Routes: []interfaces.Route{
+				{
+					Path:                    "/api/search",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(handleGlobalSearch),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetConversation,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type searchResult struct {
+	Type           string
+	Rank           float64
+	Highlight      string
+	ContactId      uuid.UUID
+	ContactName    string
+	ContactPhone   string
+	ConversationId *uuid.UUID
+	MessageId      *uuid.UUID
+	CreatedAt      time.Time
+}
+
+func handleGlobalSearch(context interfaces.ContextWithSession) error {
+	params := new(api_types.GlobalSearchParams)
+	if err := utils.BindQueryParams(context, params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	page := params.Page
+	limit := params.PerPage
+
+	if page == 0 || limit > 50 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid page or perPage value")
+	}
+
+	searchText := search_service.NormalizeQuery(&params.Query)
+	if searchText == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Search query is required")
+	}
+
+	if params.From != nil && params.To != nil && params.From.After(*params.To) {
+		return echo.NewHTTPError(http.StatusBadRequest, "from must be before to")
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	matchingMessages := table.Message.OrganizationId.EQ(UUID(orgUuid)).
+		AND(search_service.MessageMatches(searchText)).
+		AND(search_service.MessageSentBetween(params.From, params.To))
+
+	matchingContacts := table.Contact.OrganizationId.EQ(UUID(orgUuid)).
+		AND(search_service.ContactMatches(searchText))
+
+	// * contacts link to their latest conversation, so that opening a result always lands in the inbox
+	latestConversationOfContact := SELECT(table.Conversation.UniqueId).
+		FROM(table.Conversation).
+		WHERE(table.Conversation.ContactId.EQ(table.Contact.UniqueId)).
+		ORDER_BY(table.Conversation.CreatedAt.DESC()).
+		LIMIT(1)
+
+	searchQuery := UNION_ALL(
+		SELECT(
+			String(string(api_types.SearchResultTypeEnumMessage)).AS("search_result.type"),
+			search_service.MessageRank(searchText).AS("search_result.rank"),
+			search_service.MessageHighlight(searchText).AS("search_result.highlight"),
+			table.Contact.UniqueId.AS("search_result.contact_id"),
+			table.Contact.Name.AS("search_result.contact_name"),
+			table.Contact.PhoneNumber.AS("search_result.contact_phone"),
+			table.Message.ConversationId.AS("search_result.conversation_id"),
+			table.Message.UniqueId.AS("search_result.message_id"),
+			table.Message.CreatedAt.AS("search_result.created_at"),
+		).FROM(table.Message.
+			INNER_JOIN(table.Contact, table.Contact.UniqueId.EQ(table.Message.ContactId)),
+		).WHERE(matchingMessages),
+		SELECT(
+			String(string(api_types.SearchResultTypeEnumContact)).AS("search_result.type"),
+			search_service.ContactRank(searchText).AS("search_result.rank"),
+			search_service.ContactHighlight(searchText).AS("search_result.highlight"),
+			table.Contact.UniqueId.AS("search_result.contact_id"),
+			table.Contact.Name.AS("search_result.contact_name"),
+			table.Contact.PhoneNumber.AS("search_result.contact_phone"),
+			latestConversationOfContact.AS("search_result.conversation_id"),
+			Raw("NULL::uuid").AS("search_result.message_id"),
+			table.Contact.CreatedAt.AS("search_result.created_at"),
+		).FROM(table.Contact).
+			WHERE(matchingContacts),
+	).
+		ORDER_BY(
+			FloatColumn("search_result.rank").DESC(),
+			TimestampzColumn("search_result.created_at").DESC(),
+		).
+		LIMIT(limit).
+		OFFSET((page - 1) * limit)
+
+	var results []searchResult
+
+	err = searchQuery.QueryContext(context.Request().Context(), context.App.Db, &results)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var counts struct {
+		TotalMessages int
+		TotalContacts int
+	}
+
+	err = SELECT(
+		SELECT(COUNT(table.Message.UniqueId)).
+			FROM(table.Message.
+				INNER_JOIN(table.Contact, table.Contact.UniqueId.EQ(table.Message.ContactId)),
+			).
+			WHERE(matchingMessages).
+			AS("totalMessages"),
+		SELECT(COUNT(table.Contact.UniqueId)).
+			FROM(table.Contact).
+			WHERE(matchingContacts).
+			AS("totalContacts"),
+	).QueryContext(context.Request().Context(), context.App.Db, &counts)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	response := api_types.GlobalSearchResponseSchema{
+		Results: []api_types.SearchResultSchema{},
+		PaginationMeta: api_types.PaginationMeta{
+			Page:    page,
+			PerPage: limit,
+			Total:   counts.TotalMessages + counts.TotalContacts,
+		},
+	}
+
+	for _, result := range results {
+		resultToAppend := api_types.SearchResultSchema{
+			Type:         api_types.SearchResultTypeEnum(result.Type),
+			Rank:         float32(result.Rank),
+			Highlight:    result.Highlight,
+			ContactId:    result.ContactId.String(),
+			ContactName:  result.ContactName,
+			ContactPhone: result.ContactPhone,
+			CreatedAt:    result.CreatedAt,
+		}
+
+		if result.ConversationId != nil {
+			conversationId := result.ConversationId.String()
+			resultToAppend.ConversationId = &conversationId
+		}
+
+		if result.MessageId != nil {
+			messageId := result.MessageId.String()
+			resultToAppend.MessageId = &messageId
+		}
+
+		response.Results = append(response.Results, resultToAppend)
+	}
+
+	return context.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,442 @@
+package segment_controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	controller "github.com/wapikit/wapikit/api/controllers"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/segment_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+	"github.com/wapikit/wapikit/internal/interfaces"
+
+	"github.com/go-jet/jet/qrm"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// the preview shows a sample of the matching contacts, the count tells how many there are in total
+const previewContactLimit = 10
+
+type SegmentController struct {
+	controller.BaseController `json:"-,inline"`
+}
+
+func NewSegmentController() *SegmentController {
+	return &SegmentController{
+		BaseController: controller.BaseController{
+			Name:        "Segment Controller",
+			RestApiPath: "/api/segments",
+			Routes: []interfaces.Route{
+				{
+					Path:                    "/api/segments",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getSegments),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetList,
+						},
+					},
+				},
+				{
+					Path:                    "/api/segments",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(createSegment),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.CreateList,
+						},
+					},
+				},
+				{
+					Path:                    "/api/segments/preview",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(previewSegment),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    30,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetList,
+						},
+					},
+				},
+				{
+					Path:                    "/api/segments/:id",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getSegmentById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    60,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.GetList,
+						},
+					},
+				},
+				{
+					Path:                    "/api/segments/:id",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(updateSegmentById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateList,
+						},
+					},
+				},
+				{
+					Path:                    "/api/segments/:id",
+					Method:                  http.MethodDelete,
+					Handler:                 interfaces.HandlerWithSession(deleteSegmentById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.DeleteList,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getSegments(context interfaces.ContextWithSession) error {
+	params := new(api_types.GetSegmentsParams)
+	if err := utils.BindQueryParams(context, params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if params.Page < 1 || params.PerPage < 1 || params.PerPage > 50 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid page or perPage value")
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var segments []struct {
+		TotalSegments int `json:"totalSegments"`
+		model.Segment
+	}
+
+	err = SELECT(
+		table.Segment.AllColumns,
+		COUNT(table.Segment.UniqueId).OVER().AS("totalSegments"),
+	).
+		FROM(table.Segment).
+		WHERE(table.Segment.OrganizationId.EQ(UUID(orgUuid))).
+		ORDER_BY(table.Segment.CreatedAt.DESC()).
+		LIMIT(params.PerPage).
+		OFFSET((params.Page-1)*params.PerPage).
+		QueryContext(context.Request().Context(), context.App.Db, &segments)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	segmentsToReturn := make([]api_types.SegmentSchema, 0, len(segments))
+	for _, segment := range segments {
+		segmentsToReturn = append(segmentsToReturn, segment_service.ToSchema(segment.Segment))
+	}
+
+	total := 0
+	if len(segments) > 0 {
+		total = segments[0].TotalSegments
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetSegmentsResponseSchema{
+		Segments: segmentsToReturn,
+		PaginationMeta: api_types.PaginationMeta{
+			Page:    params.Page,
+			PerPage: params.PerPage,
+			Total:   total,
+		},
+	})
+}
+
+func createSegment(context interfaces.ContextWithSession) error {
+	payload := new(api_types.CreateSegmentJSONRequestBody)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	name := strings.TrimSpace(payload.Name)
+	if name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Segment name is required")
+	}
+
+	rules, err := validateRules(orgUuid, payload.Rules)
+	if err != nil {
+		return err
+	}
+
+	segment := model.Segment{
+		OrganizationId: orgUuid,
+		Name:           name,
+		Description:    payload.Description,
+		Rules:          rules,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	var insertedSegment model.Segment
+
+	err = table.Segment.INSERT(table.Segment.MutableColumns).
+		MODEL(segment).
+		RETURNING(table.Segment.AllColumns).
+		QueryContext(context.Request().Context(), context.App.Db, &insertedSegment)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusCreated, api_types.CreateSegmentResponseSchema{
+		Segment: segment_service.ToSchema(insertedSegment),
+	})
+}
+
+func getSegmentById(context interfaces.ContextWithSession) error {
+	segment, err := fetchSegment(context)
+	if err != nil {
+		return err
+	}
+
+	condition, err := segment_service.SegmentCondition(*segment)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	numberOfContacts, err := segment_service.CountContacts(context.Request().Context(), context.App.Db, condition)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	segmentToReturn := segment_service.ToSchema(*segment)
+	segmentToReturn.NumberOfContacts = &numberOfContacts
+
+	return context.JSON(http.StatusOK, api_types.GetSegmentByIdResponseSchema{
+		Segment: segmentToReturn,
+	})
+}
+
+func updateSegmentById(context interfaces.ContextWithSession) error {
+	segment, err := fetchSegment(context)
+	if err != nil {
+		return err
+	}
+
+	payload := new(api_types.UpdateSegmentByIdJSONRequestBody)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	name := strings.TrimSpace(payload.Name)
+	if name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Segment name is required")
+	}
+
+	rules, err := validateRules(segment.OrganizationId, payload.Rules)
+	if err != nil {
+		return err
+	}
+
+	var updatedSegment model.Segment
+
+	err = table.Segment.UPDATE(
+		table.Segment.Name,
+		table.Segment.Description,
+		table.Segment.Rules,
+		table.Segment.UpdatedAt,
+	).
+		SET(
+			name,
+			payload.Description,
+			rules,
+			time.Now(),
+		).
+		WHERE(table.Segment.UniqueId.EQ(UUID(segment.UniqueId))).
+		RETURNING(table.Segment.AllColumns).
+		QueryContext(context.Request().Context(), context.App.Db, &updatedSegment)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.UpdateSegmentByIdResponseSchema{
+		Segment: segment_service.ToSchema(updatedSegment),
+	})
+}
+
+func deleteSegmentById(context interfaces.ContextWithSession) error {
+	segment, err := fetchSegment(context)
+	if err != nil {
+		return err
+	}
+
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	// * campaigns using the segment keep their lists and other segments
+	_, err = table.CampaignSegment.DELETE().
+		WHERE(table.CampaignSegment.SegmentId.EQ(UUID(segment.UniqueId))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	_, err = table.Segment.DELETE().
+		WHERE(table.Segment.UniqueId.EQ(UUID(segment.UniqueId))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.DeleteSegmentByIdResponseSchema{
+		Data: true,
+	})
+}
+
+// previewSegment returns the number of contacts matching the rules and a sample of them, before the segment is saved
+func previewSegment(context interfaces.ContextWithSession) error {
+	payload := new(api_types.PreviewSegmentJSONRequestBody)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	condition, err := segment_service.Condition(orgUuid, payload.Rules)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	count, err := segment_service.CountContacts(context.Request().Context(), context.App.Db, condition)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var contacts []model.Contact
+
+	err = SELECT(table.Contact.AllColumns).
+		FROM(table.Contact).
+		WHERE(condition).
+		ORDER_BY(table.Contact.CreatedAt.DESC()).
+		LIMIT(previewContactLimit).
+		QueryContext(context.Request().Context(), context.App.Db, &contacts)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	contactsToReturn := make([]api_types.ContactSchema, 0, len(contacts))
+	for _, contact := range contacts {
+		attr := map[string]interface{}{}
+		if contact.Attributes != nil {
+			json.Unmarshal([]byte(*contact.Attributes), &attr)
+		}
+		contactsToReturn = append(contactsToReturn, api_types.ContactSchema{
+			UniqueId:   contact.UniqueId.String(),
+			CreatedAt:  contact.CreatedAt,
+			Name:       contact.Name,
+			Lists:      []api_types.ContactListSchema{},
+			Phone:      contact.PhoneNumber,
+			Attributes: attr,
+			Status:     api_types.ContactStatusEnum(contact.Status),
+		})
+	}
+
+	return context.JSON(http.StatusOK, api_types.SegmentPreviewResponseSchema{
+		Count:    count,
+		Contacts: contactsToReturn,
+	})
+}
+
+// validateRules compiles the rules once so that a segment which can not be evaluated is never saved, the rules are
+// returned in the form they are stored in
+func validateRules(organizationId uuid.UUID, rules api_types.SegmentRuleGroupSchema) (string, error) {
+	if _, err := segment_service.Condition(organizationId, rules); err != nil {
+		return "", echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	rulesJson, err := json.Marshal(rules)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return string(rulesJson), nil
+}
+
+func fetchSegment(context interfaces.ContextWithSession) (*model.Segment, error) {
+	segmentUuid, err := uuid.Parse(context.Param("id"))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid segment id")
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	segment, err := segment_service.FetchSegment(context.Request().Context(), context.App.Db, orgUuid, segmentUuid)
+	if err != nil {
+		if errors.Is(err, segment_service.ErrSegmentNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Segment not found")
+		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return segment, nil
+}
@@ -0,0 +1,495 @@
+package sla_controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	controller "github.com/wapikit/wapikit/api/controllers"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/sla_service"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
+	"github.com/wapikit/wapikit/internal/interfaces"
+
+	"github.com/go-jet/jet/qrm"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+type SlaController struct {
+	controller.BaseController `json:"-,inline"`
+}
+
+func NewSlaController() *SlaController {
+	return &SlaController{
+		BaseController: controller.BaseController{
+			Name:        "SLA Controller",
+			RestApiPath: "/api/sla",
+			Routes: []interfaces.Route{
+				{
+					Path:                    "/api/sla/policies",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getSlaPolicies),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+					},
+				},
+				{
+					Path:                    "/api/sla/policies",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(createSlaPolicy),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateOrganization,
+						},
+					},
+				},
+				{
+					Path:                    "/api/sla/policies/:id",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getSlaPolicyById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+					},
+				},
+				{
+					Path:                    "/api/sla/policies/:id",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(updateSlaPolicyById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateOrganization,
+						},
+					},
+				},
+				{
+					Path:                    "/api/sla/policies/:id",
+					Method:                  http.MethodDelete,
+					Handler:                 interfaces.HandlerWithSession(deleteSlaPolicyById),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60, // 1 minute
+						},
+						RequiredPermission: []api_types.RolePermissionEnum{
+							api_types.UpdateOrganization,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getSlaPolicies(context interfaces.ContextWithSession) error {
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var policies []model.SlaPolicy
+
+	err = SELECT(table.SlaPolicy.AllColumns).
+		FROM(table.SlaPolicy).
+		WHERE(table.SlaPolicy.OrganizationId.EQ(UUID(orgUuid))).
+		ORDER_BY(table.SlaPolicy.CreatedAt.ASC()).
+		QueryContext(context.Request().Context(), context.App.Db, &policies)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	policiesToReturn := []api_types.SlaPolicySchema{}
+	for _, policy := range policies {
+		policiesToReturn = append(policiesToReturn, buildSlaPolicy(policy))
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetSlaPoliciesResponseSchema{
+		Policies: policiesToReturn,
+	})
+}
+
+func getSlaPolicyById(context interfaces.ContextWithSession) error {
+	policy, err := fetchSlaPolicy(context)
+	if err != nil {
+		return err
+	}
+
+	return context.JSON(http.StatusOK, api_types.GetSlaPolicyByIdResponseSchema{
+		Policy: buildSlaPolicy(*policy),
+	})
+}
+
+func createSlaPolicy(context interfaces.ContextWithSession) error {
+	payload := new(api_types.NewSlaPolicySchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	policy, err := parseSlaPolicyPayload(context, orgUuid, payload)
+	if err != nil {
+		return err
+	}
+
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	var insertedPolicy model.SlaPolicy
+
+	err = table.SlaPolicy.INSERT(table.SlaPolicy.MutableColumns).
+		MODEL(policy).
+		RETURNING(table.SlaPolicy.AllColumns).
+		QueryContext(context.Request().Context(), tx, &insertedPolicy)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if insertedPolicy.IsDefault {
+		// * an organization has a single default policy
+		_, err = table.SlaPolicy.UPDATE(table.SlaPolicy.IsDefault, table.SlaPolicy.UpdatedAt).
+			SET(Bool(false), TimestampzT(time.Now())).
+			WHERE(
+				table.SlaPolicy.OrganizationId.EQ(UUID(orgUuid)).
+					AND(table.SlaPolicy.UniqueId.NOT_EQ(UUID(insertedPolicy.UniqueId))),
+			).
+			ExecContext(context.Request().Context(), tx)
+
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusCreated, api_types.CreateSlaPolicyResponseSchema{
+		Policy: buildSlaPolicy(insertedPolicy),
+	})
+}
+
+func updateSlaPolicyById(context interfaces.ContextWithSession) error {
+	existingPolicy, err := fetchSlaPolicy(context)
+	if err != nil {
+		return err
+	}
+
+	payload := new(api_types.NewSlaPolicySchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	policy, err := parseSlaPolicyPayload(context, existingPolicy.OrganizationId, payload)
+	if err != nil {
+		return err
+	}
+
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	var updatedPolicy model.SlaPolicy
+
+	// * deadlines of running conversations are kept, the updated policy applies from the next conversation it starts timers for
+	err = table.SlaPolicy.UPDATE(
+		table.SlaPolicy.Name,
+		table.SlaPolicy.IsDefault,
+		table.SlaPolicy.IsEnabled,
+		table.SlaPolicy.PhoneNumberId,
+		table.SlaPolicy.FirstResponseTimeInMinutes,
+		table.SlaPolicy.ResolutionTimeInMinutes,
+		table.SlaPolicy.Timezone,
+		table.SlaPolicy.BusinessCalendar,
+		table.SlaPolicy.EscalationAction,
+		table.SlaPolicy.EscalationTagId,
+		table.SlaPolicy.EscalationOrganizationMemberId,
+		table.SlaPolicy.UpdatedAt,
+	).
+		MODEL(policy).
+		WHERE(table.SlaPolicy.UniqueId.EQ(UUID(existingPolicy.UniqueId))).
+		RETURNING(table.SlaPolicy.AllColumns).
+		QueryContext(context.Request().Context(), tx, &updatedPolicy)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if updatedPolicy.IsDefault {
+		_, err = table.SlaPolicy.UPDATE(table.SlaPolicy.IsDefault, table.SlaPolicy.UpdatedAt).
+			SET(Bool(false), TimestampzT(time.Now())).
+			WHERE(
+				table.SlaPolicy.OrganizationId.EQ(UUID(updatedPolicy.OrganizationId)).
+					AND(table.SlaPolicy.UniqueId.NOT_EQ(UUID(updatedPolicy.UniqueId))),
+			).
+			ExecContext(context.Request().Context(), tx)
+
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.UpdateSlaPolicyByIdResponseSchema{
+		Policy: buildSlaPolicy(updatedPolicy),
+	})
+}
+
+func deleteSlaPolicyById(context interfaces.ContextWithSession) error {
+	policy, err := fetchSlaPolicy(context)
+	if err != nil {
+		return err
+	}
+
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	// * conversations keep their deadlines, they are only detached from the deleted policy
+	_, err = table.Conversation.UPDATE(table.Conversation.SlaPolicyId).
+		SET(NULL).
+		WHERE(table.Conversation.SlaPolicyId.EQ(UUID(policy.UniqueId))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	_, err = table.SlaPolicy.DELETE().
+		WHERE(table.SlaPolicy.UniqueId.EQ(UUID(policy.UniqueId))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.DeleteSlaPolicyByIdResponseSchema{
+		Data: true,
+	})
+}
+
+// fetchSlaPolicy loads the policy from the id param of the route, and makes sure it belongs to the organization of the user
+func fetchSlaPolicy(context interfaces.ContextWithSession) (*model.SlaPolicy, error) {
+	var policy model.SlaPolicy
+
+	if _, err := controller.FetchOwned(context, tenant_service.SlaPolicy, &policy); err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// parseSlaPolicyPayload validates the payload, the escalation tag and member must belong to the organization
+func parseSlaPolicyPayload(context interfaces.ContextWithSession, orgUuid uuid.UUID, payload *api_types.NewSlaPolicySchema) (model.SlaPolicy, error) {
+	policy := model.SlaPolicy{
+		OrganizationId: orgUuid,
+		Name:           payload.Name,
+		IsDefault:      payload.IsDefault != nil && *payload.IsDefault,
+		IsEnabled:      payload.IsEnabled == nil || *payload.IsEnabled,
+		Timezone:       "UTC",
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if payload.Name == "" {
+		return policy, echo.NewHTTPError(http.StatusBadRequest, "Name of the SLA policy is required")
+	}
+
+	if payload.FirstResponseTimeInMinutes < 1 {
+		return policy, echo.NewHTTPError(http.StatusBadRequest, "First response time must be at least 1 minute")
+	}
+	policy.FirstResponseTimeInMinutes = int32(payload.FirstResponseTimeInMinutes)
+
+	if payload.ResolutionTimeInMinutes != nil {
+		if *payload.ResolutionTimeInMinutes < 1 {
+			return policy, echo.NewHTTPError(http.StatusBadRequest, "Resolution time must be at least 1 minute")
+		}
+		resolutionTimeInMinutes := int32(*payload.ResolutionTimeInMinutes)
+		policy.ResolutionTimeInMinutes = &resolutionTimeInMinutes
+	}
+
+	if payload.PhoneNumberId != nil && *payload.PhoneNumberId != "" {
+		policy.PhoneNumberId = payload.PhoneNumberId
+	}
+
+	if payload.Timezone != nil && *payload.Timezone != "" {
+		if _, err := time.LoadLocation(*payload.Timezone); err != nil {
+			return policy, echo.NewHTTPError(http.StatusBadRequest, "Invalid timezone")
+		}
+		policy.Timezone = *payload.Timezone
+	}
+
+	if payload.BusinessCalendar != nil {
+		if err := sla_service.ValidateCalendar(*payload.BusinessCalendar); err != nil {
+			return policy, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		calendar, err := json.Marshal(payload.BusinessCalendar)
+		if err != nil {
+			return policy, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		stringifiedCalendar := string(calendar)
+		policy.BusinessCalendar = &stringifiedCalendar
+	}
+
+	if payload.EscalationAction == nil {
+		return policy, nil
+	}
+
+	escalationAction := new(model.SlaEscalationActionEnum)
+	if err := escalationAction.Scan(string(*payload.EscalationAction)); err != nil {
+		return policy, echo.NewHTTPError(http.StatusBadRequest, "Invalid escalation action")
+	}
+	policy.EscalationAction = escalationAction
+
+	switch *escalationAction {
+	case model.SlaEscalationActionEnum_Reassign:
+		if payload.EscalationMemberId == nil || *payload.EscalationMemberId == "" {
+			return policy, echo.NewHTTPError(http.StatusBadRequest, "Escalation member is required to reassign breached conversations")
+		}
+
+		memberUuid, err := uuid.Parse(*payload.EscalationMemberId)
+		if err != nil {
+			return policy, echo.NewHTTPError(http.StatusBadRequest, "Invalid member id")
+		}
+
+		var member model.OrganizationMember
+
+		err = SELECT(table.OrganizationMember.UniqueId).
+			FROM(table.OrganizationMember).
+			WHERE(
+				table.OrganizationMember.UniqueId.EQ(UUID(memberUuid)).
+					AND(table.OrganizationMember.OrganizationId.EQ(UUID(orgUuid))),
+			).
+			LIMIT(1).
+			QueryContext(context.Request().Context(), context.App.Db, &member)
+
+		if err != nil {
+			if err.Error() == qrm.ErrNoRows.Error() {
+				return policy, echo.NewHTTPError(http.StatusBadRequest, "Organization member not found")
+			}
+			return policy, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+
+		policy.EscalationOrganizationMemberId = &member.UniqueId
+
+	case model.SlaEscalationActionEnum_Tag:
+		if payload.EscalationTagId == nil || *payload.EscalationTagId == "" {
+			return policy, echo.NewHTTPError(http.StatusBadRequest, "Escalation tag is required to tag breached conversations")
+		}
+
+		tagUuid, err := uuid.Parse(*payload.EscalationTagId)
+		if err != nil {
+			return policy, echo.NewHTTPError(http.StatusBadRequest, "Invalid tag id")
+		}
+
+		var tag model.Tag
+
+		err = SELECT(table.Tag.AllColumns).
+			FROM(table.Tag).
+			WHERE(
+				table.Tag.UniqueId.EQ(UUID(tagUuid)).
+					AND(table.Tag.OrganizationId.EQ(UUID(orgUuid))),
+			).
+			LIMIT(1).
+			QueryContext(context.Request().Context(), context.App.Db, &tag)
+
+		if err != nil {
+			if err.Error() == qrm.ErrNoRows.Error() {
+				return policy, echo.NewHTTPError(http.StatusBadRequest, "Tag not found")
+			}
+			return policy, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+
+		policy.EscalationTagId = &tag.UniqueId
+	}
+
+	return policy, nil
+}
+
+func buildSlaPolicy(policy model.SlaPolicy) api_types.SlaPolicySchema {
+	policyToReturn := api_types.SlaPolicySchema{
+		UniqueId:                   policy.UniqueId.String(),
+		CreatedAt:                  policy.CreatedAt,
+		Name:                       policy.Name,
+		IsDefault:                  policy.IsDefault,
+		IsEnabled:                  policy.IsEnabled,
+		PhoneNumberId:              policy.PhoneNumberId,
+		FirstResponseTimeInMinutes: int(policy.FirstResponseTimeInMinutes),
+		Timezone:                   policy.Timezone,
+	}
+
+	if policy.ResolutionTimeInMinutes != nil {
+		resolutionTimeInMinutes := int(*policy.ResolutionTimeInMinutes)
+		policyToReturn.ResolutionTimeInMinutes = &resolutionTimeInMinutes
+	}
+
+	if calendar, err := sla_service.ParseCalendar(policy); err == nil {
+		policyToReturn.BusinessCalendar = calendar
+	}
+
+	if policy.EscalationAction != nil {
+		escalationAction := api_types.SlaEscalationActionEnum(*policy.EscalationAction)
+		policyToReturn.EscalationAction = &escalationAction
+	}
+
+	if policy.EscalationTagId != nil {
+		escalationTagId := policy.EscalationTagId.String()
+		policyToReturn.EscalationTagId = &escalationTagId
+	}
+
+	if policy.EscalationOrganizationMemberId != nil {
+		escalationMemberId := policy.EscalationOrganizationMemberId.String()
+		policyToReturn.EscalationMemberId = &escalationMemberId
+	}
+
+	return policyToReturn
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
+	"github.com/wapikit/wapikit/internal/interfaces"
+
+	"github.com/go-jet/jet/qrm"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// OrganizationIdOf returns the organization of the session, the ids a handler reads from the request must belong to it
+func OrganizationIdOf(context interfaces.ContextWithSession) (uuid.UUID, error) {
+	orgUuid, err := uuid.Parse(context.Session.User.OrganizationId)
+	if err != nil {
+		return uuid.Nil, echo.NewHTTPError(http.StatusInternalServerError, "Invalid organization id in session")
+	}
+	return orgUuid, nil
+}
+
+// FetchOwned loads the row of the id path param into dest, rows of other organizations are not found
+func FetchOwned(context interfaces.ContextWithSession, t tenant_service.OwnedTable, dest interface{}) (uuid.UUID, error) {
+	return FetchOwnedOfParam(context, "id", t, dest)
+}
+
+// FetchOwnedOfParam loads the row of the path param into dest, rows of other organizations are not found
+func FetchOwnedOfParam(context interfaces.ContextWithSession, param string, t tenant_service.OwnedTable, dest interface{}) (uuid.UUID, error) {
+	id, err := uuid.Parse(context.Param(param))
+	if err != nil {
+		return uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid "+t.Name+" id")
+	}
+
+	orgUuid, err := OrganizationIdOf(context)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = t.Fetch(context.Request().Context(), context.App.Db, orgUuid, id, dest)
+	if err != nil {
+		if err == tenant_service.ErrNotFound {
+			return uuid.Nil, echo.NewHTTPError(http.StatusNotFound, t.Name+" not found")
+		}
+		return uuid.Nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return id, nil
+}
+
+// CheckOwnedIds parses the ids of a payload and makes sure they all belong to the organization of the session
+func CheckOwnedIds(context interfaces.ContextWithSession, t tenant_service.OwnedTable, ids []string) ([]uuid.UUID, error) {
+	orgUuid, err := OrganizationIdOf(context)
+	if err != nil {
+		return nil, err
+	}
+
+	uuids := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		parsedId, err := uuid.Parse(id)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid "+t.Name+" id "+id)
+		}
+		uuids = append(uuids, parsedId)
+	}
+
+	err = t.CheckOwned(context.Request().Context(), context.App.Db, orgUuid, uuids...)
+	if err != nil {
+		if err == tenant_service.ErrNotFound {
+			return nil, echo.NewHTTPError(http.StatusNotFound, t.Name+" not found")
+		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return uuids, nil
+}
+
+// FetchCurrentMember loads the membership of the user of the session in its organization
+func FetchCurrentMember(context interfaces.ContextWithSession) (*model.OrganizationMember, error) {
+	orgUuid, err := OrganizationIdOf(context)
+	if err != nil {
+		return nil, err
+	}
+
+	userUuid, err := uuid.Parse(context.Session.User.UniqueId)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var member model.OrganizationMember
+
+	err = SELECT(table.OrganizationMember.AllColumns).
+		FROM(table.OrganizationMember).
+		WHERE(
+			table.OrganizationMember.OrganizationId.EQ(UUID(orgUuid)).
+				AND(table.OrganizationMember.UserId.EQ(UUID(userUuid))),
+		).
+		LIMIT(1).
+		QueryContext(context.Request().Context(), context.App.Db, &member)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Organization member not found")
+		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return &member, nil
+}
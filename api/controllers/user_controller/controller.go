@@ -2,6 +2,7 @@ package user_controller
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-jet/jet/qrm"
 	. "github.com/go-jet/jet/v2/postgres"
@@ -11,6 +12,8 @@ import (
 	table "github.com/wapikit/wapikit/.db-generated/table"
 	controller "github.com/wapikit/wapikit/api/controllers"
 	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/session_service"
+	"github.com/wapikit/wapikit/internal/core/two_factor_service"
 	"github.com/wapikit/wapikit/internal/core/utils"
 	"github.com/wapikit/wapikit/internal/interfaces"
 )
@@ -77,6 +80,84 @@ func NewUserController() *UserController {
 						},
 					},
 				},
+				{
+					Path:                    "/api/user/sessions",
+					Method:                  http.MethodGet,
+					Handler:                 interfaces.HandlerWithSession(getUserSessions),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60 * 60,
+						},
+					},
+				},
+				{
+					Path:                    "/api/user/sessions/:id",
+					Method:                  http.MethodDelete,
+					Handler:                 interfaces.HandlerWithSession(revokeUserSession),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60 * 60,
+						},
+					},
+				},
+				{
+					Path:                    "/api/user/two-factor/setup",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(setupTwoFactor),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60 * 60,
+						},
+					},
+				},
+				{
+					Path:                    "/api/user/two-factor/enable",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(enableTwoFactor),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60 * 60,
+						},
+					},
+				},
+				{
+					Path:                    "/api/user/two-factor/disable",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(disableTwoFactor),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60 * 60,
+						},
+					},
+				},
+				{
+					Path:                    "/api/user/two-factor/recovery-codes",
+					Method:                  http.MethodPost,
+					Handler:                 interfaces.HandlerWithSession(regenerateRecoveryCodes),
+					IsAuthorizationRequired: true,
+					MetaData: interfaces.RouteMetaData{
+						PermissionRoleLevel: api_types.Member,
+						RateLimitConfig: interfaces.RateLimitConfig{
+							MaxRequests:    10,
+							WindowTimeInMs: 1000 * 60 * 60,
+						},
+					},
+				},
 			},
 		},
 	}
@@ -133,6 +214,15 @@ func getUser(context interfaces.ContextWithSession) error {
 
 	currentPermissionLevel := api_types.UserPermissionLevelEnum(user.OrganizationMember.AccessLevel)
 
+	isTwoFactorRequired := false
+
+	if user.Organization.UniqueId.String() != uuid.Nil.String() {
+		isTwoFactorRequired, err = two_factor_service.IsRequiredForMember(context.Request().Context(), context.App.Db, user.Organization, user.OrganizationMember)
+		if err != nil {
+			context.App.Logger.Error("error checking two factor policy", "error", err.Error())
+		}
+	}
+
 	// find the current logged in organization
 	response := api_types.GetUserResponseSchema{
 		User: api_types.UserSchema{
@@ -144,6 +234,8 @@ func getUser(context interfaces.ContextWithSession) error {
 			ProfilePicture:                 user.User.ProfilePictureUrl,
 			IsOwner:                        isOwner,
 			CurrentOrganizationAccessLevel: &currentPermissionLevel,
+			IsTwoFactorEnabled:             two_factor_service.IsEnabled(user.User),
+			IsTwoFactorRequired:            isTwoFactorRequired,
 		},
 	}
 
@@ -159,6 +251,8 @@ func getUser(context interfaces.ContextWithSession) error {
 			LogoUrl:     org.LogoUrl,
 			WebsiteUrl:  org.WebsiteUrl,
 			Description: org.Description,
+
+			IsTwoFactorRequiredForOwners: &org.IsTwoFactorRequiredForOwners,
 		}
 
 		if org.SlackChannel != nil && org.SlackWebhookUrl != nil {
@@ -171,7 +265,7 @@ func getUser(context interfaces.ContextWithSession) error {
 		if org.SmtpClientHost != nil && org.SmtpClientPassword != nil && org.SmtpClientPort != nil && org.SmtpClientUsername != nil {
 			response.User.Organization.EmailNotificationConfiguration = &api_types.EmailNotificationConfigurationSchema{
 				SmtpHost:     *org.SmtpClientHost,
-				SmtpPassword: *org.SmtpClientPassword,
+				SmtpPassword: context.App.Secrets.MaskStored(*org.SmtpClientPassword),
 				SmtpPort:     *org.SmtpClientPort,
 				SmtpUsername: *org.SmtpClientUsername,
 			}
@@ -187,11 +281,8 @@ func getUser(context interfaces.ContextWithSession) error {
 	}
 
 	if user.WhatsappBusinessAccount.AccessToken != "" {
-		response.User.Organization.WhatsappBusinessAccountDetails = &api_types.WhatsAppBusinessAccountDetailsSchema{
-			AccessToken:       user.WhatsappBusinessAccount.AccessToken,
-			BusinessAccountId: user.WhatsappBusinessAccount.AccountId,
-			WebhookSecret:     user.WhatsappBusinessAccount.WebhookSecret,
-		}
+		businessAccountDetails := controller.BuildBusinessAccountDetails(&context.App, user.WhatsappBusinessAccount, nil)
+		response.User.Organization.WhatsappBusinessAccountDetails = &businessAccountDetails
 	}
 
 	return context.JSON(http.StatusOK, response)
@@ -320,6 +411,316 @@ func getFeatureFlags(context interfaces.ContextWithSession) error {
 	return context.JSON(http.StatusOK, responseToReturn)
 }
 
+func getUserSessions(context interfaces.ContextWithSession) error {
+	userUuid, err := uuid.Parse(context.Session.User.UniqueId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Error parsing user UUID")
+	}
+
+	var sessions []model.UserSession
+
+	sessionsQuery := SELECT(table.UserSession.AllColumns).
+		FROM(table.UserSession).
+		WHERE(
+			table.UserSession.UserId.EQ(UUID(userUuid)).
+				AND(table.UserSession.RevokedAt.IS_NULL()).
+				AND(table.UserSession.ExpiresAt.GT(TimestampzT(time.Now()))),
+		).
+		ORDER_BY(table.UserSession.LastUsedAt.DESC())
+
+	err = sessionsQuery.QueryContext(context.Request().Context(), context.App.Db, &sessions)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	response := api_types.GetUserSessionsResponseSchema{
+		Sessions: []api_types.UserSessionSchema{},
+	}
+
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, api_types.UserSessionSchema{
+			UniqueId:   session.UniqueId.String(),
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			UserAgent:  session.UserAgent,
+			IpAddress:  session.IpAddress,
+			IsCurrent:  session.UniqueId.String() == context.Session.SessionId,
+		})
+	}
+
+	return context.JSON(http.StatusOK, response)
+}
+
+func revokeUserSession(context interfaces.ContextWithSession) error {
+	userUuid, err := uuid.Parse(context.Session.User.UniqueId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Error parsing user UUID")
+	}
+
+	sessionUuid, err := uuid.Parse(context.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid session id")
+	}
+
+	isRevoked, err := session_service.RevokeSession(context.Request().Context(), context.App.Db, context.App.Redis, userUuid, sessionUuid)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if !isRevoked {
+		return echo.NewHTTPError(http.StatusNotFound, "Session not found")
+	}
+
+	return context.JSON(http.StatusOK, api_types.RevokeUserSessionResponseSchema{
+		IsRevoked: true,
+	})
+}
+
+func _fetchUserById(context interfaces.ContextWithSession) (*model.User, error) {
+	userUuid, err := uuid.Parse(context.Session.User.UniqueId)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Error parsing user UUID")
+	}
+
+	var user model.User
+
+	err = SELECT(table.User.AllColumns).
+		FROM(table.User).
+		WHERE(table.User.UniqueId.EQ(UUID(userUuid))).
+		LIMIT(1).
+		QueryContext(context.Request().Context(), context.App.Db, &user)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return &user, nil
+}
+
+func setupTwoFactor(context interfaces.ContextWithSession) error {
+	user, err := _fetchUserById(context)
+	if err != nil {
+		return err
+	}
+
+	if two_factor_service.IsEnabled(*user) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Two factor authentication is already enabled")
+	}
+
+	key, err := two_factor_service.GenerateKey(user.Email)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	qrCode, err := two_factor_service.ProvisioningQrCode(key)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	encryptedSecret, err := context.App.Secrets.Encrypt(key.Secret())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// * the secret is stored right away, but two factor authentication is only enforced once the first code is verified
+	_, err = table.User.UPDATE(table.User.TwoFactorSecret, table.User.UpdatedAt).
+		SET(String(encryptedSecret), TimestampzT(time.Now())).
+		WHERE(table.User.UniqueId.EQ(UUID(user.UniqueId))).
+		ExecContext(context.Request().Context(), context.App.Db)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.TwoFactorSetupResponseSchema{
+		Secret:     key.Secret(),
+		OtpauthUrl: key.URL(),
+		QrCode:     qrCode,
+	})
+}
+
+func enableTwoFactor(context interfaces.ContextWithSession) error {
+	payload := new(api_types.TwoFactorCodeRequestBodySchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	user, err := _fetchUserById(context)
+	if err != nil {
+		return err
+	}
+
+	if two_factor_service.IsEnabled(*user) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Two factor authentication is already enabled")
+	}
+
+	if user.TwoFactorSecret == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Two factor authentication setup has not been started")
+	}
+
+	if !two_factor_service.ValidateTotpCode(context.App.Redis, context.App.Secrets, user.UniqueId.String(), *user.TwoFactorSecret, payload.Code) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid code")
+	}
+
+	recoveryCodes, err := two_factor_service.GenerateRecoveryCodes()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	_, err = table.User.UPDATE(table.User.TwoFactorEnabledAt, table.User.UpdatedAt).
+		SET(TimestampzT(time.Now()), TimestampzT(time.Now())).
+		WHERE(table.User.UniqueId.EQ(UUID(user.UniqueId))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := two_factor_service.ReplaceRecoveryCodes(context.Request().Context(), tx, user.UniqueId, recoveryCodes); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	controller.InvalidateAuthorizationCache(&context.App, user.UniqueId.String())
+
+	return context.JSON(http.StatusOK, api_types.TwoFactorRecoveryCodesResponseSchema{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+func disableTwoFactor(context interfaces.ContextWithSession) error {
+	payload := new(api_types.TwoFactorCodeRequestBodySchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	user, err := _fetchUserById(context)
+	if err != nil {
+		return err
+	}
+
+	if !two_factor_service.IsEnabled(*user) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Two factor authentication is not enabled")
+	}
+
+	// * members can not opt out while the policy of the organization they are logged in with requires it
+	if orgUuid, parseErr := uuid.Parse(context.Session.User.OrganizationId); parseErr == nil {
+		type MemberWithOrganization struct {
+			model.OrganizationMember
+			Organization model.Organization
+		}
+
+		var member MemberWithOrganization
+
+		err = SELECT(table.OrganizationMember.AllColumns, table.Organization.AllColumns).
+			FROM(table.OrganizationMember.
+				INNER_JOIN(table.Organization, table.Organization.UniqueId.EQ(table.OrganizationMember.OrganizationId))).
+			WHERE(
+				table.OrganizationMember.UserId.EQ(UUID(user.UniqueId)).
+					AND(table.Organization.UniqueId.EQ(UUID(orgUuid))),
+			).
+			LIMIT(1).
+			QueryContext(context.Request().Context(), context.App.Db, &member)
+
+		if err == nil {
+			isRequired, err := two_factor_service.IsRequiredForMember(context.Request().Context(), context.App.Db, member.Organization, member.OrganizationMember)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			}
+			if isRequired {
+				return echo.NewHTTPError(http.StatusForbidden, "Two factor authentication is required by your organization")
+			}
+		}
+	}
+
+	isValid, err := two_factor_service.VerifyCode(context.Request().Context(), context.App.Db, context.App.Redis, context.App.Secrets, *user, payload.Code)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if !isValid {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid code")
+	}
+
+	tx, err := context.App.Db.BeginTx(context.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	_, err = table.User.UPDATE(table.User.TwoFactorSecret, table.User.TwoFactorEnabledAt, table.User.UpdatedAt).
+		SET(NULL, NULL, TimestampzT(time.Now())).
+		WHERE(table.User.UniqueId.EQ(UUID(user.UniqueId))).
+		ExecContext(context.Request().Context(), tx)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := two_factor_service.ReplaceRecoveryCodes(context.Request().Context(), tx, user.UniqueId, nil); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	controller.InvalidateAuthorizationCache(&context.App, user.UniqueId.String())
+
+	return context.JSON(http.StatusOK, api_types.DisableTwoFactorResponseSchema{
+		IsDisabled: true,
+	})
+}
+
+func regenerateRecoveryCodes(context interfaces.ContextWithSession) error {
+	payload := new(api_types.TwoFactorCodeRequestBodySchema)
+	if err := context.Bind(payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	user, err := _fetchUserById(context)
+	if err != nil {
+		return err
+	}
+
+	if !two_factor_service.IsEnabled(*user) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Two factor authentication is not enabled")
+	}
+
+	// * only a code from the authenticator app is accepted here, a leaked recovery code must not be able to replace the rest
+	if !two_factor_service.ValidateTotpCode(context.App.Redis, context.App.Secrets, user.UniqueId.String(), *user.TwoFactorSecret, payload.Code) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid code")
+	}
+
+	recoveryCodes, err := two_factor_service.GenerateRecoveryCodes()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := two_factor_service.ReplaceRecoveryCodes(context.Request().Context(), context.App.Db, user.UniqueId, recoveryCodes); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return context.JSON(http.StatusOK, api_types.TwoFactorRecoveryCodesResponseSchema{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
 func DeleteAccountStepOne(context interfaces.ContextWithSession) error {
 	// ! generate a deletion token here
 	// ! send the link to delete account with token in it to the user email
@@ -2,6 +2,7 @@ package webhook_controller
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -15,6 +16,17 @@ import (
 	controller "github.com/wapikit/wapikit/api/controllers"
 	"github.com/wapikit/wapikit/internal/api_types"
 	"github.com/wapikit/wapikit/internal/core/api_server_events"
+	"github.com/wapikit/wapikit/internal/core/canned_response_service"
+	"github.com/wapikit/wapikit/internal/core/contact_capture_service"
+	"github.com/wapikit/wapikit/internal/core/contact_consent_service"
+	"github.com/wapikit/wapikit/internal/core/contact_duplicate_service"
+	"github.com/wapikit/wapikit/internal/core/contact_lifecycle_service"
+	"github.com/wapikit/wapikit/internal/core/conversation_lifecycle_service"
+	"github.com/wapikit/wapikit/internal/core/csat_service"
+	"github.com/wapikit/wapikit/internal/core/message_service"
+	"github.com/wapikit/wapikit/internal/core/routing_service"
+	"github.com/wapikit/wapikit/internal/core/sla_service"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
 	"github.com/wapikit/wapikit/internal/core/utils"
 	"github.com/wapikit/wapikit/internal/interfaces"
 
@@ -64,7 +76,6 @@ func NewWhatsappWebhookWebhookController(wapiClient *wapi.Client) *WebhookContro
 		events.SecurityEventType:                     handleSecurityEvent,
 		events.ErrorEventType:                        handleErrorEvent,
 		events.AdInteractionEventType:                handleAdInteractionEvent,
-		events.CustomerNumberChangedEventType:        handlePhoneNumberChangeEvent,
 		events.AccountReviewUpdateEventType:          handleAccountReviewUpdateEvent,
 		events.AccountUpdateEventType:                handleAccountUpdateEvent,
 		events.TemplateMessageEventType:              handleTemplateMessageEvent,
@@ -102,7 +113,15 @@ func (service *WebhookController) handleWebhookGetRequest(context interfaces.Con
 	webhookVerificationToken := context.QueryParam("hub.verify_token")
 	logger.Info("webhook verification token", webhookVerificationToken, nil)
 	decryptedDetails, err := utils.DecryptWebhookSecret(webhookVerificationToken, context.App.Koa.String("app.encryption_key"))
-	logger.Info("decrypted details", decryptedDetails, nil)
+	if err != nil {
+		// * verification tokens generated before a key rotation are still encrypted with one of the previous keys
+		for _, previousKey := range context.App.Koa.Strings("app.previous_encryption_keys") {
+			if decryptedDetails, err = utils.DecryptWebhookSecret(webhookVerificationToken, previousKey); err == nil {
+				break
+			}
+		}
+	}
+	logger.Info("decrypted details", "details", decryptedDetails)
 	if err != nil {
 		logger.Error("error decrypting webhook verification token", err.Error(), nil)
 		return context.JSON(http.StatusBadRequest, "Invalid verification token")
@@ -146,11 +165,12 @@ func (service *WebhookController) handleWebhookGetRequest(context interfaces.Con
 		return context.JSON(http.StatusInternalServerError, "Internal server error")
 	}
 
-	wapiClient := wapi.New(&wapi.ClientConfig{
-		BusinessAccountId: businessAccount.AccountId,
-		ApiAccessToken:    businessAccount.AccessToken,
-		WebhookSecret:     businessAccount.WebhookSecret,
-	})
+	wapiClient, err := controller.NewWapiClient(&context.App, businessAccount)
+
+	if err != nil {
+		logger.Error("error decrypting whatsapp access token", "error", err.Error())
+		return context.JSON(http.StatusInternalServerError, "Internal server error")
+	}
 
 	getHandler := wapiClient.GetWebhookGetRequestHandler()
 	getHandler(context)
@@ -220,6 +240,18 @@ func fetchContact(sentByContactNumber, businessAccountId string, app interfaces.
 }
 
 func fetchConversation(businessAccountId, sentByContactNumber string, app interfaces.App) (*api_server_events.ConversationWithAllDetails, error) {
+	return queryConversationDetails(app, table.Conversation.Status.EQ(utils.EnumExpression(model.ConversationStatusEnum_Active.String())).
+		AND(table.WhatsappBusinessAccount.AccountId.EQ(String(businessAccountId))).
+		AND(table.Contact.PhoneNumber.EQ(String(sentByContactNumber))))
+}
+
+// fetchConversationById fetches the conversation whatever its status, the conversation is not reopened
+func fetchConversationById(businessAccountId string, conversationId uuid.UUID, app interfaces.App) (*api_server_events.ConversationWithAllDetails, error) {
+	return queryConversationDetails(app, table.Conversation.UniqueId.EQ(UUID(conversationId)).
+		AND(table.WhatsappBusinessAccount.AccountId.EQ(String(businessAccountId))))
+}
+
+func queryConversationDetails(app interfaces.App, condition BoolExpression) (*api_server_events.ConversationWithAllDetails, error) {
 	var dest api_server_events.ConversationWithAllDetails
 
 	conversationQuery := SELECT(
@@ -235,14 +267,12 @@ func fetchConversation(businessAccountId, sentByContactNumber string, app interf
 			LEFT_JOIN(table.Organization, table.Organization.UniqueId.EQ(table.Conversation.OrganizationId)).
 			LEFT_JOIN(table.WhatsappBusinessAccount, table.WhatsappBusinessAccount.OrganizationId.EQ(table.Organization.UniqueId)).
 			LEFT_JOIN(table.Contact, table.Contact.UniqueId.EQ(table.Conversation.ContactId)).
-			LEFT_JOIN(table.ConversationAssignment, table.ConversationAssignment.ConversationId.EQ(table.Conversation.UniqueId)).
+			LEFT_JOIN(table.ConversationAssignment, table.ConversationAssignment.ConversationId.EQ(table.Conversation.UniqueId).AND(
+				table.ConversationAssignment.Status.EQ(utils.EnumExpression(model.ConversationAssignmentStatus_Assigned.String())),
+			)).
 			LEFT_JOIN(table.OrganizationMember, table.OrganizationMember.UniqueId.EQ(table.ConversationAssignment.AssignedToOrganizationMemberId)).
 			LEFT_JOIN(table.User, table.User.UniqueId.EQ(table.OrganizationMember.UserId)),
-	).WHERE(
-		table.Conversation.Status.EQ(utils.EnumExpression(model.ConversationStatusEnum_Active.String())).
-			AND(table.WhatsappBusinessAccount.AccountId.EQ(String(businessAccountId))).
-			AND(table.Contact.PhoneNumber.EQ(String(sentByContactNumber))),
-	).LIMIT(1)
+	).WHERE(condition).LIMIT(1)
 
 	err := conversationQuery.Query(app.Db, &dest)
 
@@ -304,11 +334,12 @@ func (service *WebhookController) handleWebhookPostRequest(context interfaces.Co
 	context.Request().Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 
 	// 4) Create the wapiClient with the discovered businessAccountId
-	wapiClient := wapi.New(&wapi.ClientConfig{
-		BusinessAccountId: businessAccountId,
-		ApiAccessToken:    businessAccount.AccessToken,
-		WebhookSecret:     businessAccount.WebhookSecret,
-	})
+	wapiClient, err := controller.NewWapiClient(&context.App, businessAccount)
+
+	if err != nil {
+		context.App.Logger.Error("error decrypting whatsapp access token", "error", err.Error())
+		return context.JSON(http.StatusInternalServerError, "Internal server error")
+	}
 
 	for eventType, handler := range service.handlerMap {
 		//  ! TODO: a middleware here which parses the required event handler parameter and type cast it to the corresponding type
@@ -317,6 +348,11 @@ func (service *WebhookController) handleWebhookPostRequest(context interfaces.Co
 		})
 	}
 
+	// * the number change event does not say which business account it is for, it only applies to the organization of this one
+	wapiClient.On(events.CustomerNumberChangedEventType, func(event events.BaseEvent) {
+		handlePhoneNumberChangeEvent(event, context.App, businessAccount.OrganizationId)
+	})
+
 	postHandler := wapiClient.GetWebhookPostRequestHandler()
 	err = postHandler(context)
 
@@ -327,7 +363,24 @@ func (service *WebhookController) handleWebhookPostRequest(context interfaces.Co
 	return context.JSON(http.StatusOK, "Success")
 }
 
-func preHandlerHook(app interfaces.App, businessAccountId string, phoneNumber events.BusinessPhoneNumber, sentByContactNumber string) (*api_server_events.ConversationWithAllDetails, error) {
+// preHandlerHook resolves the contact and the conversation of an inbound message, they are created when needed. A message
+// prefilled by a click-to-chat link carries the code of the link, the contact is captured through it. The one prefilled by
+// the confirmation link of an opt-in form carries the code of the form, it confirms the consent given through the form.
+func preHandlerHook(app interfaces.App, businessAccountId string, phoneNumber events.BusinessPhoneNumber, sentByContactNumber, messageText string) (*api_server_events.ConversationWithAllDetails, error) {
+	conversationDetails, err := resolveConversation(app, businessAccountId, phoneNumber, sentByContactNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if code := contact_capture_service.FindCode(messageText); code != "" {
+		captureThroughLink(app, conversationDetails, code)
+		confirmOptInForm(app, conversationDetails, code, messageText)
+	}
+
+	return conversationDetails, nil
+}
+
+func resolveConversation(app interfaces.App, businessAccountId string, phoneNumber events.BusinessPhoneNumber, sentByContactNumber string) (*api_server_events.ConversationWithAllDetails, error) {
 	conversationDetailsToReturn := &api_server_events.ConversationWithAllDetails{}
 	businessAccount, err := fetchBusinessAccountDetails(businessAccountId, app)
 
@@ -403,6 +456,26 @@ func preHandlerHook(app interfaces.App, businessAccountId string, phoneNumber ev
 
 	fetchedConversation, err := fetchConversation(businessAccountId, sentByContactNumber, app)
 
+	if err != nil && err.Error() == qrm.ErrNoRows.Error() {
+		// * the contact is replying to a conversation which has been closed, resolved or snoozed, reopen it instead of starting a new one
+		reopenedConversation, previousStatus, reopenErr := reopenConversation(contactId, businessAccount.OrganizationId, phoneNumber.Id, app)
+
+		if reopenErr != nil {
+			app.Logger.Error("error reopening conversation", "error", reopenErr.Error())
+			return nil, fmt.Errorf("error reopening conversation")
+		}
+
+		if reopenedConversation != nil {
+			conversationDetailsToReturn.Conversation = *reopenedConversation
+			// * a snoozed conversation is still being handled, it keeps its timers and assignee
+			if previousStatus != model.ConversationStatusEnum_Snoozed {
+				startSlaTimers(app, conversationDetailsToReturn)
+				routeConversation(app, conversationDetailsToReturn)
+			}
+			return conversationDetailsToReturn, nil
+		}
+	}
+
 	if err != nil {
 		if err.Error() == qrm.ErrNoRows.Error() {
 			// * this is a new message from the user, so we need to create a new conversation
@@ -440,6 +513,9 @@ func preHandlerHook(app interfaces.App, businessAccountId string, phoneNumber ev
 				InitiatedByCampaignId: insertedConversation.InitiatedByCampaignId,
 			}
 
+			startSlaTimers(app, conversationDetailsToReturn)
+			routeConversation(app, conversationDetailsToReturn)
+
 		} else {
 			return nil, fmt.Errorf("error fetching conversation from the database")
 		}
@@ -463,6 +539,197 @@ func preHandlerHook(app interfaces.App, businessAccountId string, phoneNumber ev
 	return conversationDetailsToReturn, nil
 }
 
+// captureThroughLink adds the contact to the list of the link of the code and gives it the attributes of the link, the
+// message is still handled when it fails. Contacts joining the list get the welcome message of the link.
+func captureThroughLink(app interfaces.App, conversationDetails *api_server_events.ConversationWithAllDetails, code string) {
+	source, err := contact_capture_service.FetchByCode(context.Background(), app.Db, code, model.ContactCaptureSourceTypeEnum_Link)
+
+	if err != nil {
+		app.Logger.Error("error fetching contact capture link", "code", code, "error", err.Error())
+		return
+	}
+
+	// * codes are unique across organizations, a code of another organization is just text
+	if source == nil || source.OrganizationId != conversationDetails.Contact.OrganizationId {
+		return
+	}
+
+	contact, joined, err := contact_capture_service.Capture(context.Background(), app.Db, *source, conversationDetails.Contact)
+
+	if err != nil {
+		app.Logger.Error("error capturing contact through link", "code", code, "error", err.Error())
+		return
+	}
+
+	conversationDetails.Contact = *contact
+
+	if joined && source.WelcomeCannedResponseId != nil {
+		sendWelcomeMessage(app, conversationDetails, *source.WelcomeCannedResponseId)
+	}
+}
+
+// confirmOptInForm grants the pending consent the contact gave through the form of the code, the message is still handled
+// when it fails
+func confirmOptInForm(app interfaces.App, conversationDetails *api_server_events.ConversationWithAllDetails, code, messageText string) {
+	_, err := contact_capture_service.ConfirmForm(context.Background(), app.Db, code, conversationDetails.Contact, messageText, time.Now())
+
+	if err != nil {
+		app.Logger.Error("error confirming opt-in form", "code", code, "error", err.Error())
+	}
+}
+
+// sendWelcomeMessage sends the text of the canned response to the contact in the conversation, its attachments are not
+// sent as the contact has only just started the conversation
+func sendWelcomeMessage(app interfaces.App, conversationDetails *api_server_events.ConversationWithAllDetails, cannedResponseId uuid.UUID) {
+	var cannedResponse model.CannedResponse
+
+	err := SELECT(table.CannedResponse.AllColumns).
+		FROM(table.CannedResponse).
+		WHERE(tenant_service.CannedResponse.ById(conversationDetails.OrganizationId, cannedResponseId)).
+		Query(app.Db, &cannedResponse)
+
+	if err != nil {
+		app.Logger.Error("error fetching welcome message", "cannedResponseId", cannedResponseId.String(), "error", err.Error())
+		return
+	}
+
+	text, _ := canned_response_service.Render(cannedResponse.Content, canned_response_service.RenderContext{
+		Contact:      conversationDetails.Contact,
+		Conversation: conversationDetails.Conversation,
+		Organization: conversationDetails.WhatsappBusinessAccount.Organization,
+	}.Variables())
+
+	outboundMessage, err := message_service.BuildOutboundMessage(context.Background(), app.Db, conversationDetails.UniqueId, api_types.NewMessageSchema{
+		MessageType: api_types.Text,
+		Text:        &api_types.OutboundTextMessageSchema{Body: text},
+	})
+
+	if err != nil {
+		app.Logger.Error("error building welcome message", "cannedResponseId", cannedResponseId.String(), "error", err.Error())
+		return
+	}
+
+	whatsAppMessageId, err := message_service.Send(app.WapiClient, conversationDetails.PhoneNumberUsed, conversationDetails.Contact.PhoneNumber, outboundMessage.Message)
+
+	if err != nil {
+		app.Logger.Error("error sending welcome message", "conversationId", conversationDetails.UniqueId.String(), "error", err.Error())
+		return
+	}
+
+	messageData, _ := json.Marshal(outboundMessage.MessageData)
+	stringMessageData := string(messageData)
+
+	_, err = table.Message.
+		INSERT(table.Message.MutableColumns).
+		MODEL(model.Message{
+			WhatsAppMessageId:         &whatsAppMessageId,
+			WhatsappBusinessAccountId: &conversationDetails.WhatsappBusinessAccount.AccountId,
+			ConversationId:            &conversationDetails.UniqueId,
+			ContactId:                 conversationDetails.ContactId,
+			PhoneNumberUsed:           conversationDetails.PhoneNumberUsed,
+			Direction:                 model.MessageDirectionEnum_OutBound,
+			MessageType:               outboundMessage.MessageType,
+			Status:                    model.MessageStatusEnum_Sent,
+			MessageData:               &stringMessageData,
+			OrganizationId:            conversationDetails.OrganizationId,
+			CreatedAt:                 time.Now(),
+			UpdatedAt:                 time.Now(),
+		}).
+		Exec(app.Db)
+
+	if err != nil {
+		app.Logger.Error("error inserting welcome message in the database", "conversationId", conversationDetails.UniqueId.String(), "error", err.Error())
+	}
+}
+
+// reopenConversation reactivates the latest closed, resolved or snoozed conversation of the contact, nil is returned if there is none
+func reopenConversation(contactId, organizationId uuid.UUID, phoneNumberId string, app interfaces.App) (*model.Conversation, model.ConversationStatusEnum, error) {
+	var closedConversation model.Conversation
+
+	err := SELECT(table.Conversation.AllColumns).
+		FROM(table.Conversation).
+		WHERE(
+			table.Conversation.ContactId.EQ(UUID(contactId)).
+				AND(table.Conversation.OrganizationId.EQ(UUID(organizationId))).
+				AND(table.Conversation.PhoneNumberUsed.EQ(String(phoneNumberId))).
+				AND(table.Conversation.Status.IN(
+					utils.EnumExpression(model.ConversationStatusEnum_Closed.String()),
+					utils.EnumExpression(model.ConversationStatusEnum_Resolved.String()),
+					utils.EnumExpression(model.ConversationStatusEnum_Snoozed.String()),
+				)),
+		).
+		ORDER_BY(table.Conversation.UpdatedAt.DESC()).
+		LIMIT(1).
+		Query(app.Db, &closedConversation)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+
+	reopenedConversation, timelineEvent, err := conversation_lifecycle_service.TransitionConversation(context.Background(), app.Db, closedConversation, conversation_lifecycle_service.Transition{
+		To:      model.ConversationStatusEnum_Active,
+		Trigger: model.ConversationTimelineEventTriggerEnum_Contact,
+	})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	conversation_lifecycle_service.PublishTransition(
+		app.Redis,
+		app.Constants.RedisEventChannelName,
+		conversation_lifecycle_service.ToSchema(*timelineEvent, ""),
+		reopenedConversation.OrganizationId.String(),
+	)
+
+	return reopenedConversation, closedConversation.Status, nil
+}
+
+// startSlaTimers computes the sla deadlines of the conversation, the conversation is still handled without them if it fails
+func startSlaTimers(app interfaces.App, conversationDetails *api_server_events.ConversationWithAllDetails) {
+	conversation, err := sla_service.StartTimers(context.Background(), app.Db, conversationDetails.Conversation)
+
+	if err != nil {
+		app.Logger.Error("error starting sla timers", "conversationId", conversationDetails.UniqueId.String(), "error", err.Error())
+		return
+	}
+
+	conversationDetails.Conversation = *conversation
+}
+
+// routeConversation assigns the conversation using the routing rules of the organization, and notifies the assigned member
+func routeConversation(app interfaces.App, conversationDetails *api_server_events.ConversationWithAllDetails) {
+	member, err := routing_service.RouteConversation(context.Background(), app.Db, app.Redis, conversationDetails.Conversation)
+
+	if err != nil {
+		app.Logger.Error("error routing conversation", "conversationId", conversationDetails.UniqueId.String(), "error", err.Error())
+		return
+	}
+
+	if member == nil {
+		// * no rule could assign the conversation, whoever opens it first gets it
+		return
+	}
+
+	conversationDetails.AssignedTo.OrganizationMember = *member
+
+	var user model.User
+	err = SELECT(table.User.AllColumns).
+		FROM(table.User).
+		WHERE(table.User.UniqueId.EQ(UUID(member.UserId))).
+		Query(app.Db, &user)
+
+	if err == nil {
+		conversationDetails.AssignedTo.User = user
+	}
+
+	event := api_server_events.NewChatAssignmentEvent(*conversationDetails, member.UserId.String())
+	app.Redis.PublishMessageToRedisChannel(app.Constants.RedisEventChannelName, event.ToJson())
+}
+
 func handleTextMessage(event events.BaseEvent, app interfaces.App) {
 	textMessageEvent := event.(*events.TextMessageEvent)
 	businessAccountId := textMessageEvent.BusinessAccountId
@@ -480,7 +747,24 @@ func handleTextMessage(event events.BaseEvent, app interfaces.App) {
 
 	app.Logger.Debug("details", "businessAccountId", businessAccountId, "phoneNumber", phoneNumber, "sentByContactNumber", sentByContactNumber)
 
-	conversationDetails, err := preHandlerHook(app, businessAccountId, phoneNumber, sentByContactNumber)
+	// * a text right after rating a survey is the comment of the rating, it must not reopen the resolved conversation. Opt-out
+	// * keywords and the codes of capture sources are never comments, they are handled like any other message
+	var csatSurvey *model.CsatSurvey
+	if !contact_consent_service.IsOptOutKeyword(textMessageEvent.Text) && contact_capture_service.FindCode(textMessageEvent.Text) == "" {
+		csatSurvey, err = csat_service.RecordComment(context.Background(), app.Db, sentByContactNumber, phoneNumber.Id, textMessageEvent.Text)
+
+		if err != nil {
+			app.Logger.Error("error recording csat comment", "error", err.Error())
+		}
+	}
+
+	var conversationDetails *api_server_events.ConversationWithAllDetails
+	if csatSurvey != nil {
+		// * the comment is still stored as a message of the resolved conversation, so the team can read it in the chat
+		conversationDetails, err = fetchConversationById(businessAccountId, csatSurvey.ConversationId, app)
+	} else {
+		conversationDetails, err = preHandlerHook(app, businessAccountId, phoneNumber, sentByContactNumber, textMessageEvent.Text)
+	}
 
 	if err != nil {
 		app.Logger.Error("error fetching conversation details", err.Error(), nil)
@@ -552,6 +836,10 @@ func handleTextMessage(event events.BaseEvent, app interfaces.App) {
 		fmt.Println("error sending api server event", err)
 	}
 
+	if contact_consent_service.IsOptOutKeyword(textMessageEvent.Text) {
+		optOutThroughKeyword(app, conversationDetails.Contact, textMessageEvent.BaseMessageEvent, textMessageEvent.Text)
+	}
+
 	// ! TODO: quick actions, AI automation replies and other stuff will be added in the future version here
 	// ! check for quick action, now feature flag must be checked here
 	// ! if quick action keywords are enabled then send a quick reply
@@ -563,7 +851,7 @@ func handleVideoMessageEvent(event events.BaseEvent, app interfaces.App) {
 	phoneNumber := videoMessageEvent.PhoneNumber
 	sentByContactNumber := videoMessageEvent.BaseMessageEvent.From
 
-	conversationDetails, err := preHandlerHook(app, businessAccountId, phoneNumber, sentByContactNumber)
+	conversationDetails, err := preHandlerHook(app, businessAccountId, phoneNumber, sentByContactNumber, "")
 
 	if err != nil {
 		app.Logger.Error("error fetching conversation details", err.Error(), nil)
@@ -588,20 +876,43 @@ func handleAudioMessageEvent(event events.BaseEvent, app interfaces.App) {
 }
 
 func handleMessageReadEvent(event events.BaseEvent, app interfaces.App) {
+	messageReadEvent := event.(*events.MessageReadEvent)
+	updateMessageStatus(app, messageReadEvent.MessageId, model.MessageStatusEnum_Read)
+}
 
-	// ! TODO: mark the message as read in the database
+// handlePhoneNumberChangeEvent moves the contact to the new number of the customer, a contact already existing with the new
+// number is merged into it
+func handlePhoneNumberChangeEvent(event events.BaseEvent, app interfaces.App, organizationId uuid.UUID) {
+	var numberChangedEvent events.CustomerNumberChangedEvent
+
+	switch event := event.(type) {
+	case events.CustomerNumberChangedEvent:
+		numberChangedEvent = event
+	case *events.CustomerNumberChangedEvent:
+		numberChangedEvent = *event
+	default:
+		return
+	}
 
-	// ! send an api_server_event to webhook
+	oldPhoneNumber := contact_duplicate_service.NormalizedPhoneNumber(numberChangedEvent.OldWaId)
+	newPhoneNumber := contact_duplicate_service.NormalizedPhoneNumber(numberChangedEvent.NewWaId)
 
-}
+	if oldPhoneNumber == "" || newPhoneNumber == "" || oldPhoneNumber == newPhoneNumber {
+		return
+	}
 
-func handlePhoneNumberChangeEvent(event events.BaseEvent, app interfaces.App) {
+	contact, err := contact_lifecycle_service.ChangePhoneNumber(context.Background(), app.Db, organizationId, oldPhoneNumber, newPhoneNumber)
 
-	// ! check for the contact in the database
+	if err != nil {
+		app.Logger.Error("error changing the phone number of the contact", "organizationId", organizationId.String(), "error", err.Error())
+		return
+	}
 
-	// ! change the phone number
-	// send an api_server_event to webhook
+	if contact == nil {
+		return
+	}
 
+	app.Logger.Info("contact moved to the new phone number of the customer", "contactId", contact.UniqueId.String())
 }
 
 func handleSecurityEvent(event events.BaseEvent, app interfaces.App) {
@@ -641,7 +952,11 @@ func handleContactMessageEvent(event events.BaseEvent, app interfaces.App) {
 }
 
 func handleListInteractionMessageEvent(event events.BaseEvent, app interfaces.App) {
+	listInteractionEvent := event.(*events.ListInteractionEvent)
 
+	if rating, isCsatRating := csat_service.ParseRating(listInteractionEvent.ListId); isCsatRating {
+		recordCsatRating(app, listInteractionEvent.BaseMessageEvent, rating)
+	}
 }
 
 func handleLocationMessageEvent(event events.BaseEvent, app interfaces.App) {
@@ -649,19 +964,92 @@ func handleLocationMessageEvent(event events.BaseEvent, app interfaces.App) {
 }
 
 func handleMessageDeliveredEvent(event events.BaseEvent, app interfaces.App) {
-
+	messageDeliveredEvent := event.(*events.MessageDeliveredEvent)
+	updateMessageStatus(app, messageDeliveredEvent.MessageId, model.MessageStatusEnum_Delivered)
 }
 
 func handleMessageFailedEvent(event events.BaseEvent, app interfaces.App) {
-
+	messageFailedEvent := event.(*events.MessageFailedEvent)
+	app.Logger.Info("message failed", "whatsAppMessageId", messageFailedEvent.MessageId, "reason", messageFailedEvent.FailReason)
+	updateMessageStatus(app, messageFailedEvent.MessageId, model.MessageStatusEnum_Failed)
 }
 
+// handleQuickReplyMessageEvent handles the buttons of the templates, the opt-out button of the marketing templates opts
+// the contact out
 func handleQuickReplyMessageEvent(event events.BaseEvent, app interfaces.App) {
+	quickReplyEvent := event.(*events.QuickReplyButtonInteractionEvent)
 
+	if !contact_consent_service.IsOptOutKeyword(quickReplyEvent.ButtonText) && !contact_consent_service.IsOptOutKeyword(quickReplyEvent.ButtonPayload) {
+		return
+	}
+
+	contact, err := fetchContact(quickReplyEvent.From, quickReplyEvent.BusinessAccountId, app)
+
+	if err != nil {
+		if err.Error() != qrm.ErrNoRows.Error() {
+			app.Logger.Error("error fetching the contact opting out", "error", err.Error())
+		}
+		return
+	}
+
+	optOutThroughKeyword(app, contact.Contact, quickReplyEvent.BaseMessageEvent, quickReplyEvent.ButtonText)
+}
+
+// optOutThroughKeyword withdraws the consents of the contact which sent an opt-out keyword, campaigns skip the contact
+// from now on. The message is kept as the evidence of the opt-out.
+func optOutThroughKeyword(app interfaces.App, contact model.Contact, messageEvent events.BaseMessageEvent, text string) {
+	optedOutAt := time.Now()
+	if unixTimestamp, err := strconv.ParseInt(messageEvent.Timestamp, 10, 64); err == nil {
+		optedOutAt = time.Unix(unixTimestamp, 0)
+	}
+
+	tx, err := app.Db.BeginTx(context.Background(), nil)
+	if err != nil {
+		app.Logger.Error("error opting out contact", "contactId", contact.UniqueId.String(), "error", err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	err = contact_consent_service.OptOut(context.Background(), tx, contact, map[string]interface{}{
+		"message":           text,
+		"whatsappMessageId": messageEvent.MessageId,
+	}, optedOutAt)
+
+	if err == nil {
+		err = tx.Commit()
+	}
+
+	if err != nil {
+		app.Logger.Error("error opting out contact", "contactId", contact.UniqueId.String(), "error", err.Error())
+	}
 }
 
 func handleReplyButtonInteractionEvent(event events.BaseEvent, app interfaces.App) {
+	replyButtonInteractionEvent := event.(*events.ReplyButtonInteractionEvent)
+
+	if rating, isCsatRating := csat_service.ParseRating(replyButtonInteractionEvent.ButtonId); isCsatRating {
+		recordCsatRating(app, replyButtonInteractionEvent.BaseMessageEvent, rating)
+	}
+}
+
+// recordCsatRating stores the rating the contact picked in a survey, the resolved conversation is left as it is
+func recordCsatRating(app interfaces.App, messageEvent events.BaseMessageEvent, rating int) {
+	survey, err := csat_service.RecordRating(context.Background(), app.Db, messageEvent.From, messageEvent.PhoneNumber.Id, messageEvent.Context.RepliedToMessageId, rating)
+
+	if err != nil {
+		if err == csat_service.ErrSurveyNotFound {
+			app.Logger.Info("csat rating received without a pending survey", "from", messageEvent.From)
+			return
+		}
+		app.Logger.Error("error recording csat rating", "error", err.Error())
+		return
+	}
+
+	err = csat_service.SendCommentPrompt(app.WapiClient, *survey, messageEvent.From)
 
+	if err != nil {
+		app.Logger.Error("error sending csat comment prompt", "surveyId", survey.UniqueId.String(), "error", err.Error())
+	}
 }
 
 func handleReactionMessageEvent(event events.BaseEvent, app interfaces.App) {
@@ -685,7 +1073,8 @@ func handleStickerMessageEvent(event events.BaseEvent, app interfaces.App) {
 }
 
 func handleMessageUndeliveredEvent(event events.BaseEvent, app interfaces.App) {
-
+	messageUndeliveredEvent := event.(*events.MessageUndeliveredEvent)
+	updateMessageStatus(app, messageUndeliveredEvent.MessageId, model.MessageStatusEnum_UnDelivered)
 }
 
 func handleCustomerIdentityChangedEvent(event events.BaseEvent, app interfaces.App) {
@@ -696,7 +1085,35 @@ func handleCustomerIdentityChangedEvent(event events.BaseEvent, app interfaces.A
 }
 
 func handleMessageSentEvent(event events.BaseEvent, app interfaces.App) {
+	// * messages are stored as sent as soon as whatsapp accepts them, so the sent receipt changes nothing
+}
 
+// updateMessageStatus applies the receipt to the message and lets the inbox of the organization know
+func updateMessageStatus(app interfaces.App, whatsAppMessageId string, status model.MessageStatusEnum) {
+	message, err := message_service.UpdateStatus(context.Background(), app.Db, whatsAppMessageId, status)
+
+	if err != nil {
+		app.Logger.Error("error updating message status", "whatsAppMessageId", whatsAppMessageId, "error", err.Error())
+		return
+	}
+
+	// * unknown message, or a receipt arriving after a later one
+	if message == nil || message.ConversationId == nil {
+		return
+	}
+
+	statusChangedEvent := api_server_events.NewMessageStatusChangedEvent(
+		message.OrganizationId.String(),
+		message.ConversationId.String(),
+		message.UniqueId.String(),
+		api_types.MessageStatusEnum(message.Status.String()),
+	)
+
+	err = app.Redis.PublishMessageToRedisChannel(app.Constants.RedisEventChannelName, statusChangedEvent.ToJson())
+
+	if err != nil {
+		app.Logger.Error("error publishing message status change", "error", err.Error())
+	}
 }
 
 func handleUnknownEvent(event events.BaseEvent, app interfaces.App) {
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/knadh/koanf/parsers/toml"
@@ -36,6 +37,11 @@ func initConstants() *interfaces.Constants {
 	c.SiteName = "Wapikit"
 	c.RedisEventChannelName = "ApiServerEvents"
 	c.IsDebugModeEnabled = isDebugModeEnabled
+
+	if c.UploadDirectory == "" {
+		c.UploadDirectory = filepath.Join(os.TempDir(), "wapikit")
+	}
+
 	return &c
 }
 
@@ -54,6 +60,7 @@ func initFlags() {
 	f.Bool("new-config", false, "generate a new config file")
 	f.Bool("idempotent", false, "make --install run only if the database isn't already setup")
 	f.Bool("yes", false, "assume 'yes' to prompts during --install/upgrade")
+	f.Bool("rotate-encryption-key", false, "re-encrypt the stored secrets with the current app.encryption_key")
 	// ! TODO: implement and enable the below flags
 	// f.Bool("upgrade", false, "upgrade database to the current version")
 	// f.Bool("version", false, "show current version of the build")
@@ -61,11 +68,11 @@ func initFlags() {
 	// f.Bool("db-apply", false, "apply database migrations")
 
 	if err := f.Parse(os.Args[1:]); err != nil {
-		logger.Error("error loading flags: %v", err)
+		logger.Error("error loading flags", "error", err.Error())
 	}
 
 	if err := koa.Load(posflag.Provider(f, ".", koa), nil); err != nil {
-		logger.Error("error loading config: %v", err)
+		logger.Error("error loading config", "error", err.Error())
 	}
 }
 
@@ -75,7 +82,7 @@ func loadConfigFiles(filePaths []string, koa *koanf.Koanf) {
 			if os.IsNotExist(err) {
 				logger.Error("config file not found. If there isn't one yet, run --config to generate one.")
 			}
-			logger.Error("error loading config from file: %v.", err)
+			logger.Error("error loading config from file", "error", err.Error())
 		}
 		logger.Info("loaded config file.")
 		logger.Debug("loaded config file: %s", "filePath", filePath)
@@ -83,8 +83,8 @@ func installApp(db *sql.DB, fs stuffbin.FileSystem, prompt, idempotent bool) {
 		var users []model.User
 		err := userQuery.Query(db, &users)
 		if err != nil {
-			logger.Error("error checking existing DB schema: %v", err)
-			logger.Error("db is not initialized yet: %v", err)
+			logger.Error("error checking existing DB schema", "error", err.Error())
+			logger.Error("db is not initialized yet", "error", err.Error())
 		} else {
 			logger.Error("skipping install as database seems to be already ready with schema migrations")
 			os.Exit(0)
@@ -93,7 +93,7 @@ func installApp(db *sql.DB, fs stuffbin.FileSystem, prompt, idempotent bool) {
 
 	// Migrate the tables.
 	if err := applyMigrations(db, fs); err != nil {
-		logger.Error("error migrating DB schema: %v", err)
+		logger.Error("error migrating DB schema", "error", err.Error())
 		os.Exit(1)
 	}
 
@@ -140,12 +140,13 @@ func installApp(db *sql.DB, fs stuffbin.FileSystem, prompt, idempotent bool) {
 		panic(err)
 	}
 
-	logger.Info("inserted default user: %v", insertedUser, insertedOrg)
+	logger.Info("inserted default user", "userId", insertedUser.UniqueId.String(), "organizationId", insertedOrg.UniqueId.String())
 
 	defaultOrgMember := model.OrganizationMember{
 		AccessLevel:    model.UserPermissionLevelEnum_Owner,
 		OrganizationId: insertedOrg.UniqueId,
 		UserId:         insertedUser.UniqueId,
+		IsAvailable:    true,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
@@ -198,5 +199,5 @@ func installApp(db *sql.DB, fs stuffbin.FileSystem, prompt, idempotent bool) {
 	}
 
 	logger.Info("setup complete")
-	logger.Info(`run the program and access the dashboard at %s`, koa.MustString("app.address"))
+	logger.Info("run the program and access the dashboard", "address", koa.MustString("app.address"))
 }
@@ -10,12 +10,23 @@ import (
 	"github.com/knadh/koanf/providers/env"
 	"github.com/knadh/koanf/v2"
 	"github.com/knadh/stuffbin"
+	"github.com/wapikit/wapikit/.db-generated/model"
 	api "github.com/wapikit/wapikit/api/cmd"
 	"github.com/wapikit/wapikit/internal/core/ai_service"
+	"github.com/wapikit/wapikit/internal/core/contact_duplicate_service"
+	"github.com/wapikit/wapikit/internal/core/contact_import_service"
+	"github.com/wapikit/wapikit/internal/core/contact_list_operation_service"
+	"github.com/wapikit/wapikit/internal/core/mail_service"
+	"github.com/wapikit/wapikit/internal/core/oauth_service"
 	cache "github.com/wapikit/wapikit/internal/core/redis"
+	"github.com/wapikit/wapikit/internal/core/secret_service"
 	"github.com/wapikit/wapikit/internal/database"
 	"github.com/wapikit/wapikit/internal/interfaces"
 	campaign_manager "github.com/wapikit/wapikit/manager/campaign"
+	health_manager "github.com/wapikit/wapikit/manager/health"
+	job_manager "github.com/wapikit/wapikit/manager/job"
+	lifecycle_manager "github.com/wapikit/wapikit/manager/lifecycle"
+	sla_manager "github.com/wapikit/wapikit/manager/sla"
 	websocket_server "github.com/wapikit/wapikit/websocket-server"
 )
 
@@ -71,7 +82,7 @@ func init() {
 		return strings.Replace(strings.ToLower(
 			strings.TrimPrefix(s, "WAPIKIT_")), "__", ".", -1)
 	}), nil); err != nil {
-		logger.Error("error loading config from env: %v", err, nil)
+		logger.Error("error loading config from env", "error", err.Error())
 	}
 
 	if koa.Bool("install") {
@@ -81,6 +92,12 @@ func init() {
 		os.Exit(0)
 	}
 
+	if koa.Bool("rotate-encryption-key") {
+		logger.Info("Rotating the encryption key of the stored secrets")
+		rotateEncryptionKey(database.GetDbInstance(koa.String("database.url")))
+		os.Exit(0)
+	}
+
 	if koa.Bool("upgrade") {
 		logger.Info("Upgrading the application")
 		// ! should not upgrade without asking for thr permission, because database migration can be destructive
@@ -104,8 +121,22 @@ func main() {
 	fmt.Println("Redis URL: ", redisUrl)
 
 	redisClient := cache.NewRedisClient(redisUrl)
+
+	// * revoked sessions and the authorization cache invalidations are shared between the instances through redis, the
+	// * sessions could not be revoked without it
+	if redisClient == nil {
+		logger.Error("unable to connect to redis", "url", redisUrl)
+		os.Exit(1)
+	}
 	dbInstance := database.GetDbInstance(koa.String("database.url"))
 
+	secrets, err := secret_service.NewSecretService(koa.String("app.encryption_key"), koa.Strings("app.previous_encryption_keys"))
+
+	if err != nil {
+		logger.Error("error initializing secrets", "error", err.Error())
+		os.Exit(1)
+	}
+
 	aiService := ai_service.NewAiService(logger, redisClient, dbInstance, koa.String("ai.api_key"))
 
 	app := &interfaces.App{
@@ -115,8 +146,11 @@ func main() {
 		Koa:             koa,
 		Fs:              fs,
 		Constants:       initConstants(),
-		CampaignManager: campaign_manager.NewCampaignManager(dbInstance, *logger),
+		CampaignManager: campaign_manager.NewCampaignManager(dbInstance, *logger, secrets),
+		Secrets:         secrets,
 		AiService:       aiService,
+		OAuthProviders:  oauth_service.NewProviderRegistry(koa),
+		Mailer:          mail_service.NewMailer(koa),
 	}
 
 	var wg sync.WaitGroup
@@ -125,6 +159,22 @@ func main() {
 	// * indefinitely run the campaign manager
 	go app.CampaignManager.Run()
 
+	// * periodically verify the whatsapp business account credentials of every organization
+	go health_manager.NewHealthManager(dbInstance, *logger, secrets).Run()
+
+	// * flag conversations breaching their sla and escalate them
+	go sla_manager.NewSlaManager(dbInstance, *logger, redisClient, app.Constants.RedisEventChannelName).Run()
+
+	// * wake snoozed conversations and close the inactive ones
+	go lifecycle_manager.NewLifecycleManager(dbInstance, *logger, redisClient, app.Constants.RedisEventChannelName).Run()
+
+	// * run the queued background jobs, like contact imports
+	jobManager := job_manager.NewJobManager(dbInstance, *logger, redisClient, app.Constants.RedisEventChannelName)
+	jobManager.Register(model.BackgroundJobTypeEnum_ContactImport, contact_import_service.NewJobHandler(dbInstance))
+	jobManager.Register(model.BackgroundJobTypeEnum_ContactDuplicateScan, contact_duplicate_service.NewJobHandler(dbInstance))
+	jobManager.Register(model.BackgroundJobTypeEnum_ContactListOperation, contact_list_operation_service.NewJobHandler(dbInstance))
+	go jobManager.Run()
+
 	// Start HTTP server in a goroutine
 	go func() {
 		defer wg.Done()
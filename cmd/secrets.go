@@ -0,0 +1,162 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+	"github.com/wapikit/wapikit/internal/core/secret_service"
+)
+
+// rotateEncryptionKey re-wraps every stored secret with the current app.encryption_key, secrets stored in plaintext are encrypted.
+// The old key must be listed in app.previous_encryption_keys until this has been run, after which it can be removed. The
+// process exits with a non-zero status if any row could not be rotated, the old key must be kept until a re-run succeeds.
+func rotateEncryptionKey(db *sql.DB) {
+	secrets, err := secret_service.NewSecretService(koa.String("app.encryption_key"), koa.Strings("app.previous_encryption_keys"))
+	if err != nil {
+		logger.Error("error initializing secrets", "error", err.Error())
+		os.Exit(1)
+	}
+
+	var businessAccounts []model.WhatsappBusinessAccount
+	err = SELECT(table.WhatsappBusinessAccount.AllColumns).
+		FROM(table.WhatsappBusinessAccount).
+		Query(db, &businessAccounts)
+
+	if err != nil {
+		logger.Error("error fetching business accounts", "error", err.Error())
+		os.Exit(1)
+	}
+
+	rotatedAccessTokens := 0
+	failedAccessTokens := 0
+	for _, businessAccount := range businessAccounts {
+		accessToken, isChanged, err := secrets.Rewrap(businessAccount.AccessToken)
+		if err != nil {
+			logger.Error("error re-encrypting access token", "businessAccountId", businessAccount.UniqueId.String(), "error", err.Error())
+			failedAccessTokens++
+			continue
+		}
+
+		if !isChanged {
+			continue
+		}
+
+		_, err = table.WhatsappBusinessAccount.UPDATE(table.WhatsappBusinessAccount.AccessToken).
+			SET(String(accessToken)).
+			WHERE(table.WhatsappBusinessAccount.UniqueId.EQ(UUID(businessAccount.UniqueId))).
+			Exec(db)
+
+		if err != nil {
+			logger.Error("error updating access token", "businessAccountId", businessAccount.UniqueId.String(), "error", err.Error())
+			failedAccessTokens++
+			continue
+		}
+
+		rotatedAccessTokens++
+	}
+
+	var organizations []model.Organization
+	err = SELECT(table.Organization.AllColumns).
+		FROM(table.Organization).
+		Query(db, &organizations)
+
+	if err != nil {
+		logger.Error("error fetching organizations", "error", err.Error())
+		os.Exit(1)
+	}
+
+	rotatedOrganizations := 0
+	failedOrganizations := 0
+	for _, organization := range organizations {
+		aiApiKey, isAiApiKeyChanged, err := secrets.Rewrap(organization.AiApiKey)
+		if err != nil {
+			logger.Error("error re-encrypting ai api key", "organizationId", organization.UniqueId.String(), "error", err.Error())
+			failedOrganizations++
+			continue
+		}
+
+		smtpPassword := ""
+		if organization.SmtpClientPassword != nil {
+			smtpPassword = *organization.SmtpClientPassword
+		}
+
+		smtpPassword, isSmtpPasswordChanged, err := secrets.Rewrap(smtpPassword)
+		if err != nil {
+			logger.Error("error re-encrypting smtp password", "organizationId", organization.UniqueId.String(), "error", err.Error())
+			failedOrganizations++
+			continue
+		}
+
+		if !isAiApiKeyChanged && !isSmtpPasswordChanged {
+			continue
+		}
+
+		updateQuery := table.Organization.UPDATE(table.Organization.AiApiKey).
+			SET(String(aiApiKey)).
+			WHERE(table.Organization.UniqueId.EQ(UUID(organization.UniqueId)))
+
+		if isSmtpPasswordChanged {
+			updateQuery = table.Organization.UPDATE(table.Organization.AiApiKey, table.Organization.SmtpClientPassword).
+				SET(String(aiApiKey), String(smtpPassword)).
+				WHERE(table.Organization.UniqueId.EQ(UUID(organization.UniqueId)))
+		}
+
+		if _, err = updateQuery.Exec(db); err != nil {
+			logger.Error("error updating organization secrets", "organizationId", organization.UniqueId.String(), "error", err.Error())
+			failedOrganizations++
+			continue
+		}
+
+		rotatedOrganizations++
+	}
+
+	var users []model.User
+	err = SELECT(table.User.UniqueId, table.User.TwoFactorSecret).
+		FROM(table.User).
+		WHERE(table.User.TwoFactorSecret.IS_NOT_NULL()).
+		Query(db, &users)
+
+	if err != nil {
+		logger.Error("error fetching two factor secrets", "error", err.Error())
+		os.Exit(1)
+	}
+
+	rotatedTwoFactorSecrets := 0
+	failedTwoFactorSecrets := 0
+	for _, user := range users {
+		twoFactorSecret, isChanged, err := secrets.Rewrap(*user.TwoFactorSecret)
+		if err != nil {
+			logger.Error("error re-encrypting two factor secret", "userId", user.UniqueId.String(), "error", err.Error())
+			failedTwoFactorSecrets++
+			continue
+		}
+
+		if !isChanged {
+			continue
+		}
+
+		_, err = table.User.UPDATE(table.User.TwoFactorSecret).
+			SET(String(twoFactorSecret)).
+			WHERE(table.User.UniqueId.EQ(UUID(user.UniqueId))).
+			Exec(db)
+
+		if err != nil {
+			logger.Error("error updating two factor secret", "userId", user.UniqueId.String(), "error", err.Error())
+			failedTwoFactorSecrets++
+			continue
+		}
+
+		rotatedTwoFactorSecrets++
+	}
+
+	fmt.Printf("re-encrypted %d business account access tokens, the secrets of %d organizations and %d two factor secrets\n", rotatedAccessTokens, rotatedOrganizations, rotatedTwoFactorSecrets)
+
+	if failedAccessTokens > 0 || failedOrganizations > 0 || failedTwoFactorSecrets > 0 {
+		fmt.Printf("failed to re-encrypt %d business account access tokens, the secrets of %d organizations and %d two factor secrets, keep the previous encryption keys and run this again\n", failedAccessTokens, failedOrganizations, failedTwoFactorSecrets)
+		os.Exit(1)
+	}
+}
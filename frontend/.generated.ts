@@ -83,6 +83,21 @@ export type GetSecondaryAnalyticsParams = {
 	to?: string
 }
 
+export type GetCsatAnalyticsParams = {
+	/**
+	 * starting range of time span to get analytics for
+	 */
+	from?: string
+	/**
+	 * ending range of time span to get analytics for
+	 */
+	to?: string
+	/**
+	 * length of the periods the ratings are grouped in, defaults to Day
+	 */
+	period?: AnalyticsPeriodEnum
+}
+
 export type GetPrimaryAnalyticsParams = {
 	/**
 	 * starting range of time span to get analytics for
@@ -215,6 +230,41 @@ export type GetConversationsParams = {
 	 * query conversations with a message id.
 	 */
 	message_id?: string
+	/**
+	 * full text search over the messages of the conversations and the name and phone number of their contact. The best matching conversations come first, with the highlights of their matching messages.
+	 */
+	query?: string
+	/**
+	 * only consider conversations with messages sent after this time
+	 */
+	from?: string
+	/**
+	 * only consider conversations with messages sent before this time
+	 */
+	to?: string
+}
+
+export type GlobalSearchParams = {
+	/**
+	 * words to search for, quoted phrases, OR and -word are supported
+	 */
+	query: string
+	/**
+	 * number of records to skip
+	 */
+	page: number
+	/**
+	 * max number of records to return per page
+	 */
+	per_page: number
+	/**
+	 * only search messages sent after this time
+	 */
+	from?: string
+	/**
+	 * only search messages sent before this time
+	 */
+	to?: string
 }
 
 export type DeleteCampaignById404 = {
@@ -332,8 +382,20 @@ export type BulkImportContacts400 = {
 }
 
 export type BulkImportContactsBodyOne = {
-	/** The CSV file to be imported */
-	file?: Blob
+	/** The CSV or XLSX file to be imported, the first row must hold the column names */
+	file: Blob
+	/** JSON array of the ids of the lists to add the imported contacts to */
+	listIds?: string
+	/** the delimiter of the CSV file, a comma by default */
+	delimiter?: string
+	/** JSON object naming the columns to read the contacts from, { "phone": "...", "name": "...", "attributes": { "<attribute>": "<column>" }, "attributesJson": "..." }.
+phone is required, attributesJson names a column holding the attributes as a JSON object.
+When left out the columns named name, phone and attributes are used.
+ */
+	columnMapping?: string
+	/** ISO 3166-1 alpha-2 code of the country of phone numbers without a country code */
+	defaultCountry?: string
+	onConflict?: ContactImportConflictStrategyEnum
 }
 
 export type DeleteContactsByList400 = {
@@ -378,6 +440,25 @@ export type GetContactsParams = {
 	status?: string
 }
 
+export type ExportContactsParams = {
+	/**
+	 * the format of the file, CSV by default
+	 */
+	format?: ContactExportFormatEnum
+	/**
+	 * only export the contacts of this list
+	 */
+	list_id?: string
+	/**
+	 * order by asc or desc
+	 */
+	order?: OrderEnum
+	/**
+	 * only export the contacts in this status
+	 */
+	status?: string
+}
+
 export type UpdateOrganizationMemberById200 = {
 	data?: UpdateOrganizationMemberByIdResponseSchema
 }
@@ -743,9 +824,30 @@ export type GetMessageTemplateByIdResponseSchema = MessageTemplateSchema
 export interface WhatsAppBusinessAccountDetailsSchema {
 	accessToken: string
 	businessAccountId: string
+	health?: WhatsAppBusinessAccountHealthSchema
+	phoneNumbers?: PhoneNumberSchema[]
 	webhookSecret: string
 }
 
+export type WhatsAppBusinessAccountHealthStatusEnum =
+	(typeof WhatsAppBusinessAccountHealthStatusEnum)[keyof typeof WhatsAppBusinessAccountHealthStatusEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const WhatsAppBusinessAccountHealthStatusEnum = {
+	Unknown: 'Unknown',
+	Healthy: 'Healthy',
+	Expiring: 'Expiring',
+	Invalid: 'Invalid'
+} as const
+
+export interface WhatsAppBusinessAccountHealthSchema {
+	checkedAt?: string
+	error?: string
+	scopes: string[]
+	status: WhatsAppBusinessAccountHealthStatusEnum
+	tokenExpiresAt?: string
+}
+
 export interface UpdateWhatsAppBusinessAccountDetailsSchema {
 	accessToken: string
 	businessAccountId: string
@@ -767,9 +869,62 @@ export interface ConversationAnalyticsDataPointSchema {
 	numberOfNewConversationOpened: number
 }
 
+export interface SlaAnalyticsSchema {
+	averageFirstResponseTimeInMinutes: number
+	conversationsWithSla: number
+	firstResponseBreaches: number
+	firstResponsesMet: number
+	resolutionBreaches: number
+	resolutionsMet: number
+}
+
+export type AnalyticsPeriodEnum = (typeof AnalyticsPeriodEnum)[keyof typeof AnalyticsPeriodEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const AnalyticsPeriodEnum = {
+	Day: 'Day',
+	Week: 'Week',
+	Month: 'Month'
+} as const
+
+export interface CsatStatsSchema {
+	averageRating: number
+	/** percentage of the responses which are satisfied */
+	csatScore: number
+	responses: number
+	/** responses rated 4 or 5 */
+	satisfiedResponses: number
+	surveysSent: number
+}
+
+export interface CsatMemberStatsSchema {
+	/** not set for conversations which were not assigned when resolved */
+	memberId?: string
+	memberName?: string
+	stats: CsatStatsSchema
+}
+
+export interface CsatPhoneNumberStatsSchema {
+	phoneNumberId: string
+	stats: CsatStatsSchema
+}
+
+export interface CsatPeriodStatsSchema {
+	periodStart: string
+	stats: CsatStatsSchema
+}
+
+export interface CsatAnalyticsResponseSchema {
+	byMember: CsatMemberStatsSchema[]
+	byPeriod: CsatPeriodStatsSchema[]
+	byPhoneNumber: CsatPhoneNumberStatsSchema[]
+	overall: CsatStatsSchema
+}
+
 export interface SecondaryAnalyticsDashboardResponseSchema {
 	conversationsAnalytics: ConversationAnalyticsDataPointSchema[]
 	messageTypeTrafficDistributionAnalytics: MessageTypeDistributionGraphDataPointSchema[]
+	slaAnalytics: SlaAnalyticsSchema
 }
 
 export interface LinkClicksGraphDataPointSchema {
@@ -852,164 +1007,1325 @@ export interface AggregateAnalyticsSchema {
 
 export interface BulkImportResponseSchema {
 	message: string
+	job: BackgroundJobSchema
 }
 
-export interface BulkImportSchema {
-	delimiter?: string
-	listIds?: string[]
-}
+export type ContactImportConflictStrategyEnum =
+	(typeof ContactImportConflictStrategyEnum)[keyof typeof ContactImportConflictStrategyEnum]
 
-export interface TransferOrganizationOwnershipResponseSchema {
-	isTransferred: boolean
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const ContactImportConflictStrategyEnum = {
+	Skip: 'Skip',
+	Upsert: 'Upsert'
+} as const
+
+export type BackgroundJobTypeEnum = (typeof BackgroundJobTypeEnum)[keyof typeof BackgroundJobTypeEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const BackgroundJobTypeEnum = {
+	ContactImport: 'ContactImport',
+	ContactDuplicateScan: 'ContactDuplicateScan',
+	ContactListOperation: 'ContactListOperation'
+} as const
+
+export type BackgroundJobStatusEnum =
+	(typeof BackgroundJobStatusEnum)[keyof typeof BackgroundJobStatusEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const BackgroundJobStatusEnum = {
+	Queued: 'Queued',
+	Running: 'Running',
+	Completed: 'Completed',
+	Failed: 'Failed'
+} as const
+
+/**
+ * the summary of a completed job, imports report the created, updated, skipped and failed contacts
+ */
+export type BackgroundJobSchemaResult = { [key: string]: unknown }
+
+export interface BackgroundJobSchema {
+	uniqueId: string
+	type: BackgroundJobTypeEnum
+	status: BackgroundJobStatusEnum
+	/** unknown until the job has started */
+	totalItems?: number
+	processedItems: number
+	failedItems: number
+	/** the summary of a completed job, imports report the created, updated, skipped and failed contacts */
+	result?: BackgroundJobSchemaResult
+	error?: string
+	createdAt: string
+	startedAt?: string
+	completedAt?: string
 }
 
-export interface TransferOrganizationOwnershipSchema {
-	newOwnerId: string
+export interface GetBackgroundJobsResponseSchema {
+	jobs: BackgroundJobSchema[]
+	paginationMeta: PaginationMeta
 }
 
-export interface NotFoundErrorResponseSchema {
-	message: string
+export interface GetBackgroundJobByIdResponseSchema {
+	job: BackgroundJobSchema
 }
 
-export interface SendMessageInConversationResponseSchema {
-	message: MessageSchema
+export type GetBackgroundJobsParams = {
+	/**
+	 * number of records to skip
+	 */
+	page: number
+	/**
+	 * max number of records to return per page
+	 */
+	per_page: number
+	/**
+	 * only return jobs of this type
+	 */
+	type?: BackgroundJobTypeEnum
+	/**
+	 * only return jobs in this status
+	 */
+	status?: BackgroundJobStatusEnum
 }
 
-export type NewMessageSchemaMessageData = { [key: string]: unknown }
+export type SegmentMatchEnum = (typeof SegmentMatchEnum)[keyof typeof SegmentMatchEnum]
 
-export interface NewMessageSchema {
-	createdAt?: string
-	messageData?: NewMessageSchemaMessageData
-	messageType?: MessageTypeEnum
-}
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const SegmentMatchEnum = {
+	All: 'All',
+	Any: 'Any'
+} as const
 
-export type MessageSchemaMessageData = { [key: string]: unknown }
+export type SegmentConditionFieldEnum =
+	(typeof SegmentConditionFieldEnum)[keyof typeof SegmentConditionFieldEnum]
 
-export interface MessageSchema {
-	conversationId: string
-	createdAt: string
-	direction: MessageDirectionEnum
-	message_type: MessageTypeEnum
-	messageData?: MessageSchemaMessageData
-	status: MessageStatusEnum
-	uniqueId: string
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const SegmentConditionFieldEnum = {
+	Attribute: 'Attribute',
+	Status: 'Status',
+	ConversationTag: 'ConversationTag',
+	ListMembership: 'ListMembership',
+	Replied: 'Replied',
+	ClickedCampaignLink: 'ClickedCampaignLink',
+	ReadCampaign: 'ReadCampaign'
+} as const
+
+export type SegmentConditionOperatorEnum =
+	(typeof SegmentConditionOperatorEnum)[keyof typeof SegmentConditionOperatorEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const SegmentConditionOperatorEnum = {
+	Equals: 'Equals',
+	NotEquals: 'NotEquals',
+	Contains: 'Contains',
+	NotContains: 'NotContains',
+	GreaterThan: 'GreaterThan',
+	LessThan: 'LessThan',
+	Exists: 'Exists',
+	NotExists: 'NotExists',
+	WithinDays: 'WithinDays',
+	NotWithinDays: 'NotWithinDays'
+} as const
+
+export interface SegmentConditionSchema {
+	field: SegmentConditionFieldEnum
+	operator: SegmentConditionOperatorEnum
+	/** the attribute to compare, only for Attribute conditions */
+	attribute?: string
+	/** the value to compare with, the status, the tag, list or campaign id, or the number of days */
+	value?: string
 }
 
-export interface DeleteContactByIdResponseSchema {
-	data: boolean
+export interface SegmentRuleGroupSchema {
+	match: SegmentMatchEnum
+	conditions: SegmentConditionSchema[]
+	/** nested groups, evaluated as one condition each */
+	groups?: SegmentRuleGroupSchema[]
 }
 
-export interface DeleteConversationByIdResponseSchema {
-	data: boolean
+export interface SegmentSchema {
+	uniqueId: string
+	name: string
+	description?: string
+	rules: SegmentRuleGroupSchema
+	/** the number of contacts matching the segment right now, only returned for a single segment */
+	numberOfContacts?: number
+	createdAt: string
+	updatedAt: string
 }
 
-export interface UpdateConversationByIdResponseSchema {
-	conversation: ConversationSchema
+export interface NewSegmentSchema {
+	name: string
+	description?: string
+	rules: SegmentRuleGroupSchema
 }
 
-export interface UpdateConversationSchema {
-	status: ConversationStatusEnum
+export interface UpdateSegmentSchema {
+	name: string
+	description?: string
+	rules: SegmentRuleGroupSchema
 }
 
-export interface GetConversationMessagesResponseSchema {
-	messages: MessageSchema[]
+export interface GetSegmentsResponseSchema {
+	segments: SegmentSchema[]
 	paginationMeta: PaginationMeta
 }
 
-export interface ConversationSchema {
-	assignedTo?: OrganizationMemberSchema
-	campaignId?: string
-	contact: ContactSchema
-	contactId: string
-	createdAt: string
-	initiatedBy: ConversationInitiatedByEnum
-	messages: MessageSchema[]
-	numberOfUnreadMessages: number
-	organizationId: string
-	status: ConversationStatusEnum
-	tags: TagSchema[]
-	uniqueId: string
+export interface GetSegmentByIdResponseSchema {
+	segment: SegmentSchema
 }
 
-export interface GetConversationsResponseSchema {
-	conversations: ConversationSchema[]
-	paginationMeta: PaginationMeta
+export interface CreateSegmentResponseSchema {
+	segment: SegmentSchema
 }
 
-export interface GetConversationByIdResponseSchema {
-	conversation: ConversationSchema
+export interface UpdateSegmentByIdResponseSchema {
+	segment: SegmentSchema
 }
 
-export type UpdateCampaignSchemaTemplateComponentParameters = { [key: string]: unknown }
+export interface DeleteSegmentByIdResponseSchema {
+	data: boolean
+}
 
-export interface UpdateCampaignSchema {
-	description?: string
-	enableLinkTracking: boolean
-	listIds: string[]
-	name: string
-	phoneNumber?: string
-	status?: CampaignStatusEnum
-	tags: string[]
-	templateComponentParameters?: UpdateCampaignSchemaTemplateComponentParameters
-	templateMessageId?: string
+export interface SegmentPreviewRequestSchema {
+	rules: SegmentRuleGroupSchema
 }
 
-export interface NewCampaignSchema {
-	description?: string
-	isLinkTrackingEnabled: boolean
-	listIds: string[]
-	name: string
-	phoneNumberToUse: string
-	tags: string[]
-	templateMessageId: string
+export interface SegmentPreviewResponseSchema {
+	count: number
+	/** the first contacts matching the rules */
+	contacts: ContactSchema[]
 }
 
-export type CampaignSchemaTemplateComponentParameters = { [key: string]: unknown }
+export type GetSegmentsParams = {
+	/**
+	 * number of records to skip
+	 */
+	page: number
+	/**
+	 * max number of records to return per page
+	 */
+	per_page: number
+}
 
-export interface CampaignSchema {
-	createdAt: string
-	description?: string
-	isLinkTrackingEnabled: boolean
-	lists: ContactListSchema[]
-	name: string
-	phoneNumberInUse?: string
-	scheduledAt?: string
-	sentAt?: string
-	status: CampaignStatusEnum
-	tags: TagSchema[]
-	templateComponentParameters?: CampaignSchemaTemplateComponentParameters
-	templateMessageId?: string
+export type ContactFieldTypeEnum = (typeof ContactFieldTypeEnum)[keyof typeof ContactFieldTypeEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const ContactFieldTypeEnum = {
+	String: 'String',
+	Number: 'Number',
+	Date: 'Date',
+	Boolean: 'Boolean',
+	Enum: 'Enum'
+} as const
+
+export interface ContactFieldSchema {
 	uniqueId: string
+	/** the key of the field in the attributes of the contacts, also usable as the template variable contact.<key> */
+	key: string
+	label: string
+	type: ContactFieldTypeEnum
+	isRequired: boolean
+	/** the value given to contacts written without the field, of the type of the field */
+	defaultValue?: unknown
+	/** the allowed values of Enum fields */
+	options: string[]
+	createdAt: string
+	updatedAt: string
 }
 
-export type TemplateSchemaHeader = {
-	content?: string
-	headerType?: string
+export interface NewContactFieldSchema {
+	key: string
+	label: string
+	type: ContactFieldTypeEnum
+	isRequired: boolean
+	/** the value given to contacts written without the field, of the type of the field */
+	defaultValue?: unknown
+	options?: string[]
 }
 
-export type TemplateSchemaFooter = { [key: string]: unknown }
-
-export interface TemplateSchema {
-	bodyText: string
-	content: string
-	createdAt: string
-	footer?: TemplateSchemaFooter
-	header: TemplateSchemaHeader
-	templateId: string
+export interface UpdateContactFieldSchema {
+	label: string
+	isRequired: boolean
+	/** the value given to contacts written without the field, of the type of the field */
+	defaultValue?: unknown
+	options?: string[]
 }
 
-export interface UpdateOrganizationMemberSchema {
-	accessLevel?: UserPermissionLevelEnum
+export interface GetContactFieldsResponseSchema {
+	fields: ContactFieldSchema[]
 }
 
-export interface PaginationMeta {
-	page: number
-	per_page: number
-	total: number
+export interface CreateContactFieldResponseSchema {
+	field: ContactFieldSchema
 }
 
-export interface UpdateListByIdResponseSchema {
-	list: ContactListSchema
+export interface UpdateContactFieldByIdResponseSchema {
+	field: ContactFieldSchema
+}
+
+export interface DeleteContactFieldByIdResponseSchema {
+	data: boolean
+}
+
+export type ContactCaptureSourceTypeEnum =
+	(typeof ContactCaptureSourceTypeEnum)[keyof typeof ContactCaptureSourceTypeEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const ContactCaptureSourceTypeEnum = {
+	Form: 'Form',
+	Link: 'Link'
+} as const
+
+export interface ContactCaptureSourceSchema {
+	/** added to the attributes of the captured contacts, the values the contacts already have are kept */
+	attributes: ContactCaptureSourceSchemaAttributes
+	/** identifies the form in its url, and the link in the message it prefills */
+	code: string
+	/** forms only, the statement the contact agrees to by submitting the form */
+	consentText?: string
+	createdAt: string
+	isActive: boolean
+	listId: string
+	name: string
+	/** the business phone number the link opens a chat with, or the contacts confirm the subscription of the form with */
+	phoneNumber?: string
+	/** links only, the message prefilled for the contact before the code */
+	prefilledMessage?: string
+	type: ContactCaptureSourceTypeEnum
+	uniqueId: string
+	updatedAt: string
+	/** the url of the hosted form, or the wa.me link */
+	url: string
+	/** links only, the canned response sent to the contacts joining the list through the link */
+	welcomeCannedResponseId?: string
+}
+
+/**
+ * added to the attributes of the captured contacts, the values the contacts already have are kept
+ */
+export type ContactCaptureSourceSchemaAttributes = { [key: string]: unknown }
+
+export type NewContactCaptureSourceSchemaAttributes = { [key: string]: unknown }
+
+export interface NewContactCaptureSourceSchema {
+	attributes?: NewContactCaptureSourceSchemaAttributes
+	/** required for forms */
+	consentText?: string
+	listId: string
+	name: string
+	/** required, the business phone number with its country code */
+	phoneNumber?: string
+	prefilledMessage?: string
+	type: ContactCaptureSourceTypeEnum
+	welcomeCannedResponseId?: string
+}
+
+export type UpdateContactCaptureSourceSchemaAttributes = { [key: string]: unknown }
+
+export interface UpdateContactCaptureSourceSchema {
+	attributes?: UpdateContactCaptureSourceSchemaAttributes
+	consentText?: string
+	isActive: boolean
+	name: string
+	phoneNumber?: string
+	prefilledMessage?: string
+	welcomeCannedResponseId?: string
+}
+
+export interface GetContactCaptureSourcesResponseSchema {
+	sources: ContactCaptureSourceSchema[]
+}
+
+export interface CreateContactCaptureSourceResponseSchema {
+	source: ContactCaptureSourceSchema
+}
+
+export interface UpdateContactCaptureSourceByIdResponseSchema {
+	source: ContactCaptureSourceSchema
+}
+
+export interface DeleteContactCaptureSourceByIdResponseSchema {
+	data: boolean
+}
+
+export type GetContactCaptureSourcesParams = {
+	/**
+	 * only return the forms and links of this list
+	 */
+	list_id?: string
+}
+
+export interface OptInFormSchema {
+	code: string
+	consentText: string
+	name: string
+	organizationName: string
+}
+
+export interface GetOptInFormResponseSchema {
+	form: OptInFormSchema
+}
+
+export interface OptInFormSubmissionSchema {
+	/** the contact agrees to the consent text of the form, the form is rejected without it */
+	consent: boolean
+	name: string
+	phone: string
+}
+
+export interface SubmitOptInFormResponseSchema {
+	/** the wa.me link the contact confirms the subscription with, it prefills the code of the form */
+	confirmationUrl: string
+	message: string
+}
+
+export interface BulkImportSchema {
+	delimiter?: string
+	listIds?: string[]
+}
+
+export interface TransferOrganizationOwnershipResponseSchema {
+	isTransferred: boolean
+}
+
+export interface TransferOrganizationOwnershipSchema {
+	newOwnerId: string
+}
+
+export interface NotFoundErrorResponseSchema {
+	message: string
+}
+
+export interface SendMessageInConversationResponseSchema {
+	message: MessageSchema
+}
+
+/**
+ * @deprecated
+ */
+export type NewMessageSchemaMessageData = { [key: string]: unknown }
+
+export interface OutboundTextMessageSchema {
+	body: string
+	/** render a preview of the first url in the body */
+	previewUrl?: boolean
+}
+
+export interface OutboundMediaMessageSchema {
+	caption?: string
+	filename?: string
+	id?: string
+	link?: string
+}
+
+export interface OutboundLocationMessageSchema {
+	address?: string
+	latitude: number
+	longitude: number
+	name?: string
+}
+
+export interface OutboundContactCardSchema {
+	company?: string
+	emails?: string[]
+	firstName?: string
+	formattedName: string
+	lastName?: string
+	phones?: string[]
+	urls?: string[]
+}
+
+export interface OutboundReactionMessageSchema {
+	emoji: string
+	messageId: string
+}
+
+export type InteractiveMessageTypeEnum =
+	(typeof InteractiveMessageTypeEnum)[keyof typeof InteractiveMessageTypeEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const InteractiveMessageTypeEnum = {
+	List: 'List',
+	Buttons: 'Buttons'
+} as const
+
+export interface OutboundInteractiveListRowSchema {
+	description?: string
+	id: string
+	title: string
+}
+
+export interface OutboundInteractiveListSectionSchema {
+	rows: OutboundInteractiveListRowSchema[]
+	title: string
+}
+
+export interface OutboundInteractiveButtonSchema {
+	id: string
+	title: string
+}
+
+export interface OutboundInteractiveMessageSchema {
+	body: string
+	buttonText?: string
+	buttons?: OutboundInteractiveButtonSchema[]
+	sections?: OutboundInteractiveListSectionSchema[]
+	type: InteractiveMessageTypeEnum
+}
+
+export interface OutboundTemplateMessageSchema {
+	bodyParameters?: string[]
+	headerParameters?: string[]
+	language: string
+	name: string
+}
+
+export interface NewMessageSchema {
+	contacts?: OutboundContactCardSchema[]
+	createdAt?: string
+	interactive?: OutboundInteractiveMessageSchema
+	location?: OutboundLocationMessageSchema
+	media?: OutboundMediaMessageSchema
+	/** @deprecated */
+	messageData?: NewMessageSchemaMessageData
+	messageType: MessageTypeEnum
+	reaction?: OutboundReactionMessageSchema
+	replyToMessageId?: string
+	template?: OutboundTemplateMessageSchema
+	text?: OutboundTextMessageSchema
+}
+
+export type MessageSchemaMessageData = { [key: string]: unknown }
+
+export interface MessageSchema {
+	conversationId: string
+	createdAt: string
+	direction: MessageDirectionEnum
+	message_type: MessageTypeEnum
+	messageData?: MessageSchemaMessageData
+	status: MessageStatusEnum
+	uniqueId: string
+}
+
+export type ConversationRoutingStrategyEnum =
+	(typeof ConversationRoutingStrategyEnum)[keyof typeof ConversationRoutingStrategyEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const ConversationRoutingStrategyEnum = {
+	RoundRobin: 'RoundRobin',
+	LeastBusy: 'LeastBusy'
+} as const
+
+export interface RoutingRuleSchema {
+	createdAt: string
+	isEnabled: boolean
+	/** members with this many active conversations are skipped */
+	maxConcurrentConversations?: number
+	/** members the conversations are distributed to, every member of the organization when empty */
+	memberIds: string[]
+	name: string
+	/** only conversations on this phone number match the rule */
+	phoneNumberId?: string
+	/** rules with a lower priority are evaluated first */
+	priority: number
+	strategy: ConversationRoutingStrategyEnum
+	/** only conversations with contacts in a list with this tag match the rule */
+	tagId?: string
+	uniqueId: string
+}
+
+export interface NewRoutingRuleSchema {
+	isEnabled?: boolean
+	maxConcurrentConversations?: number
+	memberIds?: string[]
+	name: string
+	phoneNumberId?: string
+	priority?: number
+	strategy: ConversationRoutingStrategyEnum
+	tagId?: string
+}
+
+export interface GetRoutingRulesResponseSchema {
+	rules: RoutingRuleSchema[]
+}
+
+export interface GetRoutingRuleByIdResponseSchema {
+	rule: RoutingRuleSchema
+}
+
+export interface CreateRoutingRuleResponseSchema {
+	rule: RoutingRuleSchema
+}
+
+export interface UpdateRoutingRuleByIdResponseSchema {
+	rule: RoutingRuleSchema
+}
+
+export interface DeleteRoutingRuleByIdResponseSchema {
+	data: boolean
+}
+
+export interface UpdateAvailabilitySchema {
+	isAvailable: boolean
+}
+
+export interface UpdateAvailabilityResponseSchema {
+	isAvailable: boolean
+}
+
+export type PresenceStatusEnum = (typeof PresenceStatusEnum)[keyof typeof PresenceStatusEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const PresenceStatusEnum = {
+	Online: 'Online',
+	Away: 'Away',
+	OnBreak: 'OnBreak',
+	Offline: 'Offline'
+} as const
+
+export interface MemberPresenceSchema {
+	lastSeenAt?: string
+	memberId: string
+	status: PresenceStatusEnum
+	userId: string
+}
+
+export interface GetOrganizationPresenceResponseSchema {
+	members: MemberPresenceSchema[]
+}
+
+export interface UpdatePresenceSchema {
+	status: PresenceStatusEnum
+}
+
+export interface UpdatePresenceResponseSchema {
+	presence: MemberPresenceSchema
+}
+
+export type SlaEscalationActionEnum =
+	(typeof SlaEscalationActionEnum)[keyof typeof SlaEscalationActionEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const SlaEscalationActionEnum = {
+	Notify: 'Notify',
+	Reassign: 'Reassign',
+	Tag: 'Tag'
+} as const
+
+export type SlaStatusEnum = (typeof SlaStatusEnum)[keyof typeof SlaStatusEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const SlaStatusEnum = {
+	Pending: 'Pending',
+	Met: 'Met',
+	Breached: 'Breached'
+} as const
+
+export type WeekdayEnum = (typeof WeekdayEnum)[keyof typeof WeekdayEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const WeekdayEnum = {
+	Monday: 'Monday',
+	Tuesday: 'Tuesday',
+	Wednesday: 'Wednesday',
+	Thursday: 'Thursday',
+	Friday: 'Friday',
+	Saturday: 'Saturday',
+	Sunday: 'Sunday'
+} as const
+
+export interface BusinessHoursWindowSchema {
+	day: WeekdayEnum
+	/** closing time in the HH:MM format */
+	end: string
+	/** opening time in the HH:MM format */
+	start: string
+}
+
+export interface BusinessCalendarSchema {
+	/** dates in the YYYY-MM-DD format on which the timers are paused for the whole day */
+	holidays: string[]
+	weeklyHours: BusinessHoursWindowSchema[]
+}
+
+export interface SlaPolicySchema {
+	businessCalendar?: BusinessCalendarSchema
+	createdAt: string
+	escalationAction?: SlaEscalationActionEnum
+	escalationMemberId?: string
+	escalationTagId?: string
+	firstResponseTimeInMinutes: number
+	/** the default policy applies to conversations on phone numbers without a policy of their own */
+	isDefault: boolean
+	isEnabled: boolean
+	name: string
+	phoneNumberId?: string
+	resolutionTimeInMinutes?: number
+	timezone: string
+	uniqueId: string
+}
+
+export interface NewSlaPolicySchema {
+	businessCalendar?: BusinessCalendarSchema
+	escalationAction?: SlaEscalationActionEnum
+	escalationMemberId?: string
+	escalationTagId?: string
+	firstResponseTimeInMinutes: number
+	isDefault?: boolean
+	isEnabled?: boolean
+	name: string
+	phoneNumberId?: string
+	resolutionTimeInMinutes?: number
+	timezone?: string
+}
+
+export interface GetSlaPoliciesResponseSchema {
+	policies: SlaPolicySchema[]
+}
+
+export interface GetSlaPolicyByIdResponseSchema {
+	policy: SlaPolicySchema
+}
+
+export interface CreateSlaPolicyResponseSchema {
+	policy: SlaPolicySchema
+}
+
+export interface UpdateSlaPolicyByIdResponseSchema {
+	policy: SlaPolicySchema
+}
+
+export interface DeleteSlaPolicyByIdResponseSchema {
+	data: boolean
+}
+
+export interface CannedResponseAttachmentSchema {
+	caption?: string
+	fileName?: string
+	/** public url of the media */
+	link?: string
+	/** id of media uploaded to whatsapp, either this or the link is required */
+	mediaId?: string
+	mediaType: MessageTypeEnum
+}
+
+export interface CannedResponseSchema {
+	attachments: CannedResponseAttachmentSchema[]
+	/** text of the response, variables like {{contact.name}} are resolved when it is rendered for a conversation */
+	content: string
+	createdAt: string
+	folder?: string
+	/** personal responses are only visible to the member who created them */
+	isPersonal: boolean
+	name: string
+	shortcode: string
+	tags: TagSchema[]
+	uniqueId: string
+}
+
+export interface NewCannedResponseSchema {
+	attachments?: CannedResponseAttachmentSchema[]
+	content: string
+	folder?: string
+	isPersonal?: boolean
+	name: string
+	shortcode: string
+	tagIds?: string[]
+}
+
+export interface GetCannedResponsesResponseSchema {
+	cannedResponses: CannedResponseSchema[]
+	folders: string[]
+}
+
+export interface GetCannedResponseByIdResponseSchema {
+	cannedResponse: CannedResponseSchema
+}
+
+export interface CreateCannedResponseResponseSchema {
+	cannedResponse: CannedResponseSchema
+}
+
+export interface UpdateCannedResponseByIdResponseSchema {
+	cannedResponse: CannedResponseSchema
+}
+
+export interface DeleteCannedResponseByIdResponseSchema {
+	data: boolean
+}
+
+export interface RenderCannedResponseSchema {
+	conversationId: string
+}
+
+export interface RenderCannedResponseResponseSchema {
+	attachments: CannedResponseAttachmentSchema[]
+	content: string
+	/** variables without a value for the conversation, they are left empty in the content */
+	unresolvedVariables: string[]
+}
+
+export type GetCannedResponsesParams = {
+	/**
+	 * only return responses in this folder
+	 */
+	folder?: string
+	/**
+	 * only return responses with this tag
+	 */
+	tag_id?: string
+	/**
+	 * search the name and shortcode of the responses
+	 */
+	query?: string
+}
+
+export interface ConversationSlaSchema {
+	firstRespondedAt?: string
+	firstResponseDueAt?: string
+	firstResponseStatus: SlaStatusEnum
+	policyId: string
+	resolutionDueAt?: string
+	resolutionStatus?: SlaStatusEnum
+	resolvedAt?: string
+}
+
+export type ContactExportFormatEnum =
+	(typeof ContactExportFormatEnum)[keyof typeof ContactExportFormatEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const ContactExportFormatEnum = {
+	Csv: 'Csv',
+	Json: 'Json'
+} as const
+
+/**
+ * Anonymize replaces the personal data of the contact and keeps its conversations and statistics, Delete removes the contact and everything related to it
+ */
+export type ContactErasureModeEnum =
+	(typeof ContactErasureModeEnum)[keyof typeof ContactErasureModeEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const ContactErasureModeEnum = {
+	Anonymize: 'Anonymize',
+	Delete: 'Delete'
+} as const
+
+export interface ContactErasureSchema {
+	mode: ContactErasureModeEnum
+}
+
+export interface EraseContactByIdResponseSchema {
+	data: boolean
+}
+
+export interface MergeContactsSchema {
+	/** the ids of the contacts to merge into the contact */
+	duplicateIds: string[]
+}
+
+export interface MergeContactsResponseSchema {
+	contact: ContactSchema
+}
+
+export interface ContactDuplicateScanSchema {
+	/** merge each group of duplicates into its oldest contact, instead of only reporting them */
+	merge: boolean
+}
+
+export interface ScanContactDuplicatesResponseSchema {
+	message: string
+	job: BackgroundJobSchema
+}
+
+export interface ContactLinkClickSchema {
+	clickedAt: string
+	campaignId: string
+	url?: string
+}
+
+export interface ContactDataConversationSchema {
+	uniqueId: string
+	status: ConversationStatusEnum
+	createdAt: string
+}
+
+export interface ContactDataExportSchema {
+	exportedAt: string
+	contact: ContactSchema
+	conversations: ContactDataConversationSchema[]
+	messages: MessageSchema[]
+	linkClicks: ContactLinkClickSchema[]
+	consents: ContactConsentSchema[]
+}
+
+export type ContactConsentCategoryEnum =
+	(typeof ContactConsentCategoryEnum)[keyof typeof ContactConsentCategoryEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const ContactConsentCategoryEnum = {
+	Marketing: 'Marketing',
+	Utility: 'Utility'
+} as const
+
+/**
+ * a Pending consent was given through an opt-in form and waits for the contact to confirm it on WhatsApp, campaigns are only sent to Granted consents. Consents can only be Granted or Revoked through the API
+ */
+export type ContactConsentStatusEnum =
+	(typeof ContactConsentStatusEnum)[keyof typeof ContactConsentStatusEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const ContactConsentStatusEnum = {
+	Granted: 'Granted',
+	Revoked: 'Revoked',
+	Pending: 'Pending'
+} as const
+
+/**
+ * a Legacy consent was granted by the owner of the organization for a contact created before consents were recorded, its evidence is the statement of the owner and not the proof of the contact
+ */
+export type ContactConsentSourceEnum =
+	(typeof ContactConsentSourceEnum)[keyof typeof ContactConsentSourceEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const ContactConsentSourceEnum = {
+	Api: 'Api',
+	Import: 'Import',
+	Form: 'Form',
+	Keyword: 'Keyword',
+	Legacy: 'Legacy'
+} as const
+
+/**
+ * the proof of the consent, like the statement the contact agreed to or the message it opted out with
+ */
+export type ContactConsentSchemaEvidence = { [key: string]: unknown }
+
+export interface ContactConsentSchema {
+	/** the opt-in form the consent was given through */
+	captureSourceId?: string
+	category: ContactConsentCategoryEnum
+	/** when the consent was given or withdrawn */
+	changedAt: string
+	/** the proof of the consent, like the statement the contact agreed to or the message it opted out with */
+	evidence: ContactConsentSchemaEvidence
+	/** the member who recorded the consent, missing when the contact gave or withdrew it itself */
+	recordedByMemberId?: string
+	source: ContactConsentSourceEnum
+	status: ContactConsentStatusEnum
+	updatedAt: string
+}
+
+/**
+ * the proof of the consent, required to grant it. For example the text the contact agreed to and where
+ */
+export type NewContactConsentSchemaEvidence = { [key: string]: unknown }
+
+export interface NewContactConsentSchema {
+	category: ContactConsentCategoryEnum
+	/** when the consent was given or withdrawn, now by default */
+	changedAt?: string
+	/** the proof of the consent, required to grant it. For example the text the contact agreed to and where */
+	evidence?: NewContactConsentSchemaEvidence
+	status: ContactConsentStatusEnum
+}
+
+export interface UpdateContactConsentsSchema {
+	consents: NewContactConsentSchema[]
+}
+
+export interface GetContactConsentsResponseSchema {
+	consents: ContactConsentSchema[]
+}
+
+export interface UpdateContactConsentsResponseSchema {
+	consents: ContactConsentSchema[]
+}
+
+export interface GrantLegacyConsentsSchema {
+	/** how the contacts opted in to the marketing messages of the organization before consents were recorded, it is the evidence of every consent granted */
+	statement: string
+}
+
+export interface GrantLegacyConsentsResponseSchema {
+	/** the number of contacts whose marketing consent has been granted */
+	grantedCount: number
+}
+
+export type GetContactTimelineParams = {
+	/**
+	 * number of records to skip
+	 */
+	page: number
+	/**
+	 * max number of records to return per page
+	 */
+	per_page: number
+}
+
+export type ContactTimelineEventTypeEnum =
+	(typeof ContactTimelineEventTypeEnum)[keyof typeof ContactTimelineEventTypeEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const ContactTimelineEventTypeEnum = {
+	CampaignMessage: 'CampaignMessage',
+	LinkClicked: 'LinkClicked',
+	ConversationStarted: 'ConversationStarted',
+	ConversationStatusChanged: 'ConversationStatusChanged',
+	ConversationAssigned: 'ConversationAssigned',
+	ConversationUnassigned: 'ConversationUnassigned',
+	ListJoined: 'ListJoined',
+	ListLeft: 'ListLeft',
+	StatusChanged: 'StatusChanged',
+	AttributesChanged: 'AttributesChanged',
+	Merged: 'Merged',
+	OptedIn: 'OptedIn',
+	OptedOut: 'OptedOut'
+} as const
+
+/**
+ * an attribute of the contact which changed, the old value is missing when the attribute was added and the new one when it was removed
+ */
+export interface ContactAttributeChangeSchema {
+	key: string
+	newValue?: unknown
+	oldValue?: unknown
+}
+
+/**
+ * the proof of the consent given or withdrawn
+ */
+export type ContactTimelineEventSchemaConsentEvidence = { [key: string]: unknown }
+
+/**
+ * an event of the timeline of a contact, only the properties of its type are set
+ */
+export interface ContactTimelineEventSchema {
+	/** the member who made the change, missing when it was not made by a member */
+	actorMemberId?: string
+	actorName?: string
+	assignedMemberId?: string
+	assignedMemberName?: string
+	attributeChanges?: ContactAttributeChangeSchema[]
+	campaignId?: string
+	campaignName?: string
+	/** the opt-in form the contact submitted */
+	captureSourceId?: string
+	captureSourceName?: string
+	consentCategory?: ContactConsentCategoryEnum
+	/** the proof of the consent given or withdrawn */
+	consentEvidence?: ContactTimelineEventSchemaConsentEvidence
+	consentSource?: ContactConsentSourceEnum
+	/** the statement the contact agreed to when opting in */
+	consentText?: string
+	conversationEventType?: ConversationTimelineEventTypeEnum
+	conversationId?: string
+	createdAt: string
+	eventType: ContactTimelineEventTypeEnum
+	fromConversationStatus?: ConversationStatusEnum
+	fromStatus?: ContactStatusEnum
+	listId?: string
+	/** the name of the list when the contact joined or left it */
+	listName?: string
+	mergedContactIds?: string[]
+	messageId?: string
+	messageStatus?: MessageStatusEnum
+	toConversationStatus?: ConversationStatusEnum
+	toStatus?: ContactStatusEnum
+	uniqueId: string
+	/** the destination of the clicked link */
+	url?: string
+}
+
+export interface GetContactTimelineResponseSchema {
+	events: ContactTimelineEventSchema[]
+	paginationMeta: PaginationMeta
+}
+
+export interface DeleteContactByIdResponseSchema {
+	data: boolean
+}
+
+export interface DeleteConversationByIdResponseSchema {
+	data: boolean
+}
+
+export interface UpdateConversationByIdResponseSchema {
+	conversation: ConversationSchema
+}
+
+export interface UpdateConversationSchema {
+	status: ConversationStatusEnum
+}
+
+export interface ConversationNoteSchema {
+	authorMemberId: string
+	authorName: string
+	content: string
+	conversationId: string
+	createdAt: string
+	mentionedMemberIds: string[]
+	uniqueId: string
+}
+
+export type ConversationMessageItemTypeEnum =
+	(typeof ConversationMessageItemTypeEnum)[keyof typeof ConversationMessageItemTypeEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const ConversationMessageItemTypeEnum = {
+	Message: 'Message',
+	Note: 'Note'
+} as const
+
+export interface ConversationMessageItemSchema {
+	message?: MessageSchema
+	note?: ConversationNoteSchema
+	type: ConversationMessageItemTypeEnum
+}
+
+export interface GetConversationMessagesResponseSchema {
+	/** messages and notes of the page in the order they were created */
+	items: ConversationMessageItemSchema[]
+	messages: MessageSchema[]
+	paginationMeta: PaginationMeta
+}
+
+export interface NewConversationNoteSchema {
+	content: string
+	/** members mentioned with @ in the content, they are notified of the note */
+	mentionedMemberIds?: string[]
+}
+
+export interface CreateConversationNoteResponseSchema {
+	note: ConversationNoteSchema
+}
+
+export interface DeleteConversationNoteByIdResponseSchema {
+	data: boolean
+}
+
+export type ConversationTimelineEventTypeEnum =
+	(typeof ConversationTimelineEventTypeEnum)[keyof typeof ConversationTimelineEventTypeEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const ConversationTimelineEventTypeEnum = {
+	Resolved: 'Resolved',
+	Reopened: 'Reopened',
+	Snoozed: 'Snoozed',
+	Woken: 'Woken',
+	Closed: 'Closed'
+} as const
+
+export type ConversationTimelineEventTriggerEnum =
+	(typeof ConversationTimelineEventTriggerEnum)[keyof typeof ConversationTimelineEventTriggerEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const ConversationTimelineEventTriggerEnum = {
+	Member: 'Member',
+	Contact: 'Contact',
+	Inactivity: 'Inactivity',
+	SnoozeExpired: 'SnoozeExpired'
+} as const
+
+export interface ConversationTimelineEventSchema {
+	actorMemberId?: string
+	actorName?: string
+	conversationId: string
+	createdAt: string
+	eventType: ConversationTimelineEventTypeEnum
+	fromStatus: ConversationStatusEnum
+	snoozedUntil?: string
+	toStatus: ConversationStatusEnum
+	trigger: ConversationTimelineEventTriggerEnum
+	uniqueId: string
+}
+
+export interface SnoozeConversationSchema {
+	snoozedUntil: string
+}
+
+export interface UpdateConversationStatusResponseSchema {
+	event: ConversationTimelineEventSchema
+	snoozedUntil?: string
+	status: ConversationStatusEnum
+}
+
+export interface GetConversationTimelineResponseSchema {
+	events: ConversationTimelineEventSchema[]
+}
+
+export type SearchResultTypeEnum = (typeof SearchResultTypeEnum)[keyof typeof SearchResultTypeEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const SearchResultTypeEnum = {
+	Message: 'Message',
+	Contact: 'Contact'
+} as const
+
+export interface SearchResultSchema {
+	contactId: string
+	contactName: string
+	contactPhone: string
+	conversationId?: string
+	createdAt: string
+	/** the matched text with the matched words wrapped in <mark> tags */
+	highlight: string
+	messageId?: string
+	rank: number
+	type: SearchResultTypeEnum
+}
+
+export interface GlobalSearchResponseSchema {
+	paginationMeta: PaginationMeta
+	results: SearchResultSchema[]
+}
+
+export interface ConversationSchema {
+	assignedTo?: OrganizationMemberSchema
+	campaignId?: string
+	contact: ContactSchema
+	contactId: string
+	createdAt: string
+	initiatedBy: ConversationInitiatedByEnum
+	messages: MessageSchema[]
+	numberOfUnreadMessages: number
+	organizationId: string
+	sla?: ConversationSlaSchema
+	/** the messages of the conversation matching the search query, the best match first. Only returned when conversations are searched */
+	searchMatches?: ConversationSearchMatchSchema[]
+	snoozedUntil?: string
+	status: ConversationStatusEnum
+	tags: TagSchema[]
+	uniqueId: string
+}
+
+export interface ConversationSearchMatchSchema {
+	createdAt: string
+	/** the matched text with the matched words wrapped in <mark> tags, as safe html. The text itself is html escaped, so it can be rendered as html as it is */
+	highlight: string
+	messageId: string
+	rank: number
+}
+
+export interface GetConversationsResponseSchema {
+	conversations: ConversationSchema[]
+	paginationMeta: PaginationMeta
+}
+
+export interface GetConversationByIdResponseSchema {
+	conversation: ConversationSchema
+}
+
+export type UpdateCampaignSchemaTemplateComponentParameters = { [key: string]: unknown }
+
+export interface UpdateCampaignSchema {
+	description?: string
+	enableLinkTracking: boolean
+	listIds: string[]
+	name: string
+	phoneNumber?: string
+	/** replaces the segments of the campaign when given */
+	segmentIds?: string[]
+	status?: CampaignStatusEnum
+	tags: string[]
+	templateComponentParameters?: UpdateCampaignSchemaTemplateComponentParameters
+	templateMessageId?: string
+}
+
+export interface NewCampaignSchema {
+	description?: string
+	isLinkTrackingEnabled: boolean
+	listIds: string[]
+	name: string
+	phoneNumberToUse: string
+	/** the segments to send the campaign to, in addition to the contacts of the lists */
+	segmentIds?: string[]
+	tags: string[]
+	templateMessageId: string
+}
+
+export type CampaignSchemaTemplateComponentParameters = { [key: string]: unknown }
+
+export interface CampaignSchema {
+	createdAt: string
+	description?: string
+	isLinkTrackingEnabled: boolean
+	lists: ContactListSchema[]
+	name: string
+	phoneNumberInUse?: string
+	scheduledAt?: string
+	/** the segments the campaign is sent to along with its lists */
+	segments?: SegmentSchema[]
+	sentAt?: string
+	status: CampaignStatusEnum
+	tags: TagSchema[]
+	templateComponentParameters?: CampaignSchemaTemplateComponentParameters
+	templateMessageId?: string
+	uniqueId: string
+}
+
+export type TemplateSchemaHeader = {
+	content?: string
+	headerType?: string
+}
+
+export type TemplateSchemaFooter = { [key: string]: unknown }
+
+export interface TemplateSchema {
+	bodyText: string
+	content: string
+	createdAt: string
+	footer?: TemplateSchemaFooter
+	header: TemplateSchemaHeader
+	templateId: string
+}
+
+export interface UpdateOrganizationMemberSchema {
+	accessLevel?: UserPermissionLevelEnum
+}
+
+export interface PaginationMeta {
+	page: number
+	per_page: number
+	total: number
+}
+
+export type ContactListOperationEnum =
+	(typeof ContactListOperationEnum)[keyof typeof ContactListOperationEnum]
+
+// eslint-disable-next-line @typescript-eslint/no-redeclare
+export const ContactListOperationEnum = {
+	Union: 'Union',
+	Intersection: 'Intersection',
+	Difference: 'Difference',
+	Copy: 'Copy',
+	Split: 'Split',
+	Move: 'Move',
+	Dedupe: 'Dedupe'
+} as const
+
+export interface ContactListOperationSchema {
+	operation: ContactListOperationEnum
+	/** at least two lists for Union, Intersection and Difference, a single list for the other operations */
+	sourceListIds: string[]
+	/** the name of the list created by Union, Intersection, Difference and Copy, and the prefix of the names of the lists created by Split. Required by Union, Intersection and Difference, the name of the source list is used otherwise */
+	name?: string
+	/** the number of lists created by Split, from 2 to 20 */
+	groups?: number
+	/** the list the contacts are moved to, required by Move */
+	targetListId?: string
+	/** the contacts moved by Move, every contact of the source list when left out */
+	contactIds?: string[]
+}
+
+export interface RunContactListOperationResponseSchema {
+	message: string
+	job: BackgroundJobSchema
+}
+
+export interface UpdateListByIdResponseSchema {
+	list: ContactListSchema
 }
 
 export interface TagSchema {
@@ -1060,6 +2376,7 @@ export interface UpdateContactSchema {
 
 export interface OrganizationRoleSchema {
 	description?: string
+	isTwoFactorRequired?: boolean
 	name: string
 	permissions: RolePermissionEnum[]
 	uniqueId: string
@@ -1067,6 +2384,7 @@ export interface OrganizationRoleSchema {
 
 export interface NewOrganizationRoleSchema {
 	description?: string
+	isTwoFactorRequired?: boolean
 	name: string
 	permissions: RolePermissionEnum[]
 }
@@ -1075,6 +2393,8 @@ export type NewContactSchemaAttributes = { [key: string]: unknown }
 
 export interface NewContactSchema {
 	attributes: NewContactSchemaAttributes
+	/** the consents the contact has given, campaigns are only sent to the contacts which consented to marketing messages */
+	consents?: NewContactConsentSchema[]
 	listsIds: string[]
 	name: string
 	phone: string
@@ -1103,6 +2423,7 @@ export interface UpdateRoleByIdResponseSchema {
 
 export interface RoleUpdateSchema {
 	description?: string
+	isTwoFactorRequired?: boolean
 	name: string
 	permissions: RolePermissionEnum[]
 }
@@ -1180,10 +2501,21 @@ export interface CreateNewOrganizationResponseSchema {
 	organization: OrganizationSchema
 }
 
+export interface CsatConfigurationSchema {
+	isEnabled: boolean
+	/** the question sent to the contact with the ratings, a default question is used when not set */
+	message?: string
+}
+
 export interface UpdateOrganizationSchema {
 	aiConfiguration?: UpdateAIConfigurationDetailsSchema
 	description?: string
 	emailNotificationConfiguration?: EmailNotificationConfigurationSchema
+	/** close conversations after this many minutes without activity, 0 turns automatic closing off. left unchanged when not sent */
+	conversationAutoCloseAfterMinutes?: number
+	/** surveys sent to contacts when their conversation is resolved. left unchanged when not sent */
+	csatConfiguration?: CsatConfigurationSchema
+	isTwoFactorRequiredForOwners?: boolean
 	name: string
 	slackNotificationConfiguration?: SlackNotificationConfigurationSchema
 }
@@ -1332,6 +2664,7 @@ export interface GetFeatureFlagsResponseSchema {
 }
 
 export interface VerifyOtpResponseBodySchema {
+	refreshToken: string
 	token: string
 }
 
@@ -1358,7 +2691,10 @@ export interface RegisterRequestBodySchema {
 
 export interface LoginResponseBodySchema {
 	isOnboardingCompleted: boolean
+	isTwoFactorRequired?: boolean
+	refreshToken: string
 	token: string
+	twoFactorToken?: string
 }
 
 export interface LoginRequestBodySchema {
@@ -1371,6 +2707,8 @@ export interface OrganizationMemberSchema {
 	createdAt: string
 	email: string
 	name: string
+	/** unavailable members are not assigned new conversations by the routing rules */
+	isAvailable?: boolean
 	roles: OrganizationRoleSchema[]
 	uniqueId: string
 }
@@ -1394,6 +2732,10 @@ export interface OrganizationSchema {
 	description?: string
 	emailNotificationConfiguration?: EmailNotificationConfigurationSchema
 	faviconUrl?: string
+	/** active and resolved conversations without activity for this many minutes are closed, not set when conversations are never closed automatically */
+	conversationAutoCloseAfterMinutes?: number
+	csatConfiguration?: CsatConfigurationSchema
+	isTwoFactorRequiredForOwners?: boolean
 	logoUrl?: string
 	name: string
 	slackNotificationConfiguration?: SlackNotificationConfigurationSchema
@@ -1419,6 +2761,8 @@ export interface UserSchema {
 	email: string
 	featureFlags?: FeatureFlags
 	isOwner: boolean
+	isTwoFactorEnabled: boolean
+	isTwoFactorRequired: boolean
 	name: string
 	organization?: OrganizationSchema
 	profilePicture?: string
@@ -1546,7 +2890,11 @@ export const RolePermissionEnum = {
 	'Delete:OrganizationRole': 'Delete:OrganizationRole',
 	'Update:IntegrationSettings': 'Update:IntegrationSettings',
 	'Get:MessageTemplates': 'Get:MessageTemplates',
-	'Get:PhoneNumbers': 'Get:PhoneNumbers'
+	'Get:PhoneNumbers': 'Get:PhoneNumbers',
+	'Get:CannedResponse': 'Get:CannedResponse',
+	'Create:CannedResponse': 'Create:CannedResponse',
+	'Update:CannedResponse': 'Update:CannedResponse',
+	'Delete:CannedResponse': 'Delete:CannedResponse'
 } as const
 
 export type ContactStatusEnum = (typeof ContactStatusEnum)[keyof typeof ContactStatusEnum]
@@ -1571,7 +2919,9 @@ export const MessageTypeEnum = {
 	Location: 'Location',
 	Contacts: 'Contacts',
 	Reaction: 'Reaction',
-	Address: 'Address'
+	Address: 'Address',
+	Interactive: 'Interactive',
+	Template: 'Template'
 } as const
 
 export type MessageDirectionEnum = (typeof MessageDirectionEnum)[keyof typeof MessageDirectionEnum]
@@ -1640,7 +2990,9 @@ export type ConversationStatusEnum =
 export const ConversationStatusEnum = {
 	Active: 'Active',
 	Closed: 'Closed',
-	Deleted: 'Deleted'
+	Deleted: 'Deleted',
+	Resolved: 'Resolved',
+	Snoozed: 'Snoozed'
 } as const
 
 export type OrderEnum = (typeof OrderEnum)[keyof typeof OrderEnum]
@@ -4225,7 +5577,7 @@ export const useDeleteContactsByList = <
 }
 
 /**
- * handles bulk import of contacts
+ * queues a background job importing the contacts of a CSV or XLSX file, progress is reported over the websocket
  */
 export const bulkImportContacts = (
 	bulkImportContactsBody: BulkImportContactsBodyOne | BulkImportSchema
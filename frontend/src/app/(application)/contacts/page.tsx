@@ -257,7 +257,7 @@ const ContactsPage = () => {
 			{/* bulk import contacts */}
 			<Modal
 				title="Import Contacts"
-				description="Upload a CSV file with the following columns: name, phoneNumber, attributes"
+				description="Upload a CSV or XLSX file with the columns name, phone and attributes, the contacts are imported in the background"
 				isOpen={isBulkImportModalOpen}
 				onClose={() => {
 					setIsBulkImportModalOpen(false)
@@ -275,9 +275,9 @@ const ContactsPage = () => {
 									name="file"
 									render={({ field }) => (
 										<FormItem>
-											<FormLabel>Upload CSV File</FormLabel>
+											<FormLabel>Upload CSV or XLSX File</FormLabel>
 											<FileUploaderComponent
-												descriptionString="CSV File"
+												descriptionString="CSV or XLSX File"
 												{...field}
 												onFileUpload={e => {
 													const file = e.target.files?.[0]
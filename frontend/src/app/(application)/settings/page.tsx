@@ -6,6 +6,7 @@ import { ScrollArea } from '~/components/ui/scroll-area'
 import { Tabs, TabsContent, TabsList, TabsTrigger } from '~/components/ui/tabs'
 import { Input } from '~/components/ui/input'
 import RolesTable from '~/components/settings/roles-table'
+import TwoFactorSettings from '~/components/settings/two-factor-settings'
 import { useEffect, useRef, useState } from 'react'
 import { useRouter, useSearchParams } from 'next/navigation'
 import { useLayoutStore } from '~/store/layout.store'
@@ -35,9 +36,11 @@ import {
 	useUpdateUser,
 	useUpdateOrganization,
 	AiModelEnum,
-	getAIConfiguration
+	getAIConfiguration,
+	WhatsAppBusinessAccountHealthStatusEnum
 } from 'root/.generated'
 import { Modal } from '~/components/ui/modal'
+import { Badge } from '~/components/ui/badge'
 import {
 	EmailNotificationConfigurationFormSchema,
 	NewRoleFormSchema,
@@ -411,7 +414,8 @@ export default function SettingsPage() {
 						whatsappBusinessAccountDetails: {
 							businessAccountId: response.businessAccountId,
 							accessToken: response.accessToken,
-							webhookSecret: response.webhookSecret
+							webhookSecret: response.webhookSecret,
+							health: response.health
 						}
 					}
 				})
@@ -423,7 +427,7 @@ export default function SettingsPage() {
 		} catch (error) {
 			console.error(error)
 			errorNotification({
-				message: 'Error updating WhatsApp Business Account ID'
+				message: (error as Error).message || 'Error updating WhatsApp Business Account ID'
 			})
 		}
 	}
@@ -976,6 +980,62 @@ export default function SettingsPage() {
 											</form>
 										</Form>
 
+										{currentOrganization?.whatsappBusinessAccountDetails?.health ? (
+											<Card className="min-w-4xl flex-1 border-none">
+												<CardHeader>
+													<CardTitle className="flex flex-row items-center gap-2">
+														Credentials Health
+														<Badge
+															variant={
+																currentOrganization
+																	.whatsappBusinessAccountDetails.health
+																	.status ===
+																WhatsAppBusinessAccountHealthStatusEnum.Healthy
+																	? 'default'
+																	: currentOrganization
+																				.whatsappBusinessAccountDetails
+																				.health.status ===
+																		  WhatsAppBusinessAccountHealthStatusEnum.Unknown
+																		? 'secondary'
+																		: 'destructive'
+															}
+														>
+															{
+																currentOrganization
+																	.whatsappBusinessAccountDetails.health
+																	.status
+															}
+														</Badge>
+													</CardTitle>
+													<CardDescription>
+														{currentOrganization.whatsappBusinessAccountDetails
+															.health.error ||
+															'The access token is valid and has the required permissions.'}
+													</CardDescription>
+												</CardHeader>
+												<CardContent className="flex flex-col gap-1 text-sm text-muted-foreground">
+													{currentOrganization.whatsappBusinessAccountDetails
+														.health.tokenExpiresAt ? (
+														<span>
+															Token expires on{' '}
+															{new Date(
+																currentOrganization.whatsappBusinessAccountDetails.health.tokenExpiresAt
+															).toLocaleString()}
+														</span>
+													) : null}
+													{currentOrganization.whatsappBusinessAccountDetails
+														.health.checkedAt ? (
+														<span>
+															Last checked on{' '}
+															{new Date(
+																currentOrganization.whatsappBusinessAccountDetails.health.checkedAt
+															).toLocaleString()}
+														</span>
+													) : null}
+												</CardContent>
+											</Card>
+										) : null}
+
 										<Card className="min-w-4xl flex-1 border-none ">
 											<CardHeader>
 												<CardTitle>Your Unique Webhook Secret</CardTitle>
@@ -1285,6 +1345,20 @@ export default function SettingsPage() {
 														</TooltipProvider>
 													</CardContent>
 												</Card>
+												{user ? (
+													<TwoFactorSettings
+														isTwoFactorEnabled={user.isTwoFactorEnabled}
+														isTwoFactorRequired={user.isTwoFactorRequired}
+														onChange={isEnabled => {
+															writeProperty({
+																user: {
+																	...user,
+																	isTwoFactorEnabled: isEnabled
+																}
+															})
+														}}
+													/>
+												) : null}
 											</>
 										) : (
 											<LoadingSpinner />
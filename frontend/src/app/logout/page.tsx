@@ -3,17 +3,30 @@
 import { useRouter } from 'next/navigation'
 import { useEffect } from 'react'
 import LoadingSpinner from '~/components/loader'
-import { AUTH_TOKEN_LS } from '~/constants'
+import { AUTH_TOKEN_LS, REFRESH_TOKEN_LS, getBackendUrl } from '~/constants'
 import { useLocalStorage } from '~/hooks/use-local-storage'
 
 const LogoutPage = () => {
 	const setAuthToken = useLocalStorage<string | null>(AUTH_TOKEN_LS, '')[1]
+	const setRefreshToken = useLocalStorage<string | null>(REFRESH_TOKEN_LS, '')[1]
 	const router = useRouter()
 
 	useEffect(() => {
-		setAuthToken(null)
-		router.push('/')
-	}, [router, setAuthToken])
+		const authToken = localStorage.getItem(AUTH_TOKEN_LS)
+
+		// revoke the session on the server, the local tokens are cleared regardless of the outcome
+		fetch(`${getBackendUrl()}/auth/logout`, {
+			method: 'POST',
+			headers: { 'x-access-token': authToken || '' },
+			mode: 'cors'
+		})
+			.catch(() => null)
+			.finally(() => {
+				setAuthToken(null)
+				setRefreshToken(null)
+				router.push('/')
+			})
+	}, [router, setAuthToken, setRefreshToken])
 
 	return (
 		<div className="flex h-[100vh] w-full flex-col items-center justify-center gap-4">
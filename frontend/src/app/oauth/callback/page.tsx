@@ -0,0 +1,113 @@
+'use client'
+
+import { useRouter, useSearchParams } from 'next/navigation'
+import { Suspense, useEffect, useRef, useState } from 'react'
+import LoadingSpinner from '~/components/loader'
+import { Button } from '~/components/ui/button'
+import { Input } from '~/components/ui/input'
+import { AUTH_TOKEN_LS, OAUTH_PROVIDER_SS, REFRESH_TOKEN_LS } from '~/constants'
+import { useLocalStorage } from '~/hooks/use-local-storage'
+import customInstance from '~/utils/api-client'
+import { type LoginResponseBodySchema } from 'root/.generated'
+
+const OAuthCallback = () => {
+	const setAuthToken = useLocalStorage<string | undefined>(AUTH_TOKEN_LS, undefined)[1]
+	const setRefreshToken = useLocalStorage<string | undefined>(REFRESH_TOKEN_LS, undefined)[1]
+	const searchParams = useSearchParams()
+	const router = useRouter()
+	const [error, setError] = useState<string | null>(null)
+	const [twoFactorToken, setTwoFactorToken] = useState<string | null>(null)
+	const [twoFactorCode, setTwoFactorCode] = useState('')
+	// the authorization code can only be exchanged once
+	const isExchanged = useRef(false)
+
+	const completeLogin = (response: LoginResponseBodySchema) => {
+		if (response.isTwoFactorRequired && response.twoFactorToken) {
+			setTwoFactorToken(response.twoFactorToken)
+			return
+		}
+		setAuthToken(response.token)
+		setRefreshToken(response.refreshToken)
+		window.location.href = response.isOnboardingCompleted ? '/dashboard' : '/onboarding'
+	}
+
+	useEffect(() => {
+		if (isExchanged.current) return
+		isExchanged.current = true
+
+		const code = searchParams.get('code')
+		const state = searchParams.get('state')
+		const provider = sessionStorage.getItem(OAUTH_PROVIDER_SS)
+		sessionStorage.removeItem(OAUTH_PROVIDER_SS)
+
+		if (!code || !state || !provider) {
+			setError(searchParams.get('error_description') || 'Login was cancelled')
+			return
+		}
+
+		customInstance<LoginResponseBodySchema>({
+			url: '/auth/oauth',
+			method: 'POST',
+			data: { provider, code, state }
+		})
+			.then(completeLogin)
+			.catch((error: { message?: string }) => {
+				setError(error.message || 'Something went wrong while logging you in')
+			})
+		// eslint-disable-next-line react-hooks/exhaustive-deps
+	}, [searchParams])
+
+	const verifyTwoFactorCode = () => {
+		customInstance<LoginResponseBodySchema>({
+			url: '/auth/two-factor/verify',
+			method: 'POST',
+			data: { twoFactorToken, code: twoFactorCode }
+		})
+			.then(completeLogin)
+			.catch((error: { message?: string }) => {
+				setError(error.message || 'Invalid code')
+			})
+	}
+
+	return (
+		<div className="flex h-[100vh] w-full flex-col items-center justify-center gap-4">
+			{twoFactorToken ? (
+				<div className="flex w-full max-w-sm flex-col gap-2">
+					<p className="text-sm">
+						Enter the code from your authenticator app, or one of your recovery codes.
+					</p>
+					<Input
+						placeholder="123456"
+						autoComplete="one-time-code"
+						value={twoFactorCode}
+						onChange={event => setTwoFactorCode(event.target.value)}
+					/>
+					{error ? <div className="text-sm text-red-500">{error}</div> : null}
+					<Button onClick={verifyTwoFactorCode}>Verify</Button>
+				</div>
+			) : error ? (
+				<>
+					<div className="text-sm text-red-500">{error}</div>
+					<button className="text-sm underline" onClick={() => router.push('/signin')}>
+						Back to sign in
+					</button>
+				</>
+			) : (
+				<>
+					<div className="text-sm">Logging you in, please wait....</div>
+					<LoadingSpinner />
+				</>
+			)}
+		</div>
+	)
+}
+
+const OAuthCallbackPage = () => {
+	return (
+		<Suspense>
+			<OAuthCallback />
+		</Suspense>
+	)
+}
+
+export default OAuthCallbackPage
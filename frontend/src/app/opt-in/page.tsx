@@ -0,0 +1,124 @@
+'use client'
+
+import { useSearchParams } from 'next/navigation'
+import { Suspense, useEffect, useState } from 'react'
+import LoadingSpinner from '~/components/loader'
+import { Button } from '~/components/ui/button'
+import { Checkbox } from '~/components/ui/checkbox'
+import { Input } from '~/components/ui/input'
+import customInstance from '~/utils/api-client'
+import {
+	type GetOptInFormResponseSchema,
+	type OptInFormSchema,
+	type SubmitOptInFormResponseSchema
+} from 'root/.generated'
+
+const OptInForm = () => {
+	const searchParams = useSearchParams()
+	const [form, setForm] = useState<OptInFormSchema | null>(null)
+	const [error, setError] = useState<string | null>(null)
+	const [message, setMessage] = useState<string | null>(null)
+	const [confirmationUrl, setConfirmationUrl] = useState<string | null>(null)
+	const [name, setName] = useState('')
+	const [phone, setPhone] = useState('')
+	const [consent, setConsent] = useState(false)
+	const [isSubmitting, setIsSubmitting] = useState(false)
+
+	const code = searchParams.get('code')
+
+	useEffect(() => {
+		if (!code) {
+			setError('This form does not exist')
+			return
+		}
+
+		customInstance<GetOptInFormResponseSchema>({
+			url: `/opt-in-forms/${encodeURIComponent(code)}`,
+			method: 'GET'
+		})
+			.then(response => setForm(response.form))
+			.catch(() => setError('This form does not exist or is no longer accepting responses'))
+	}, [code])
+
+	const submit = () => {
+		if (!form) return
+		setIsSubmitting(true)
+		setError(null)
+
+		customInstance<SubmitOptInFormResponseSchema>({
+			url: `/opt-in-forms/${encodeURIComponent(form.code)}`,
+			method: 'POST',
+			data: { name, phone, consent }
+		})
+			.then(response => {
+				setMessage(response.message)
+				setConfirmationUrl(response.confirmationUrl)
+			})
+			.catch((error: { message?: string }) => {
+				setError(error.message || 'Something went wrong while submitting the form')
+			})
+			.finally(() => setIsSubmitting(false))
+	}
+
+	return (
+		<div className="flex h-[100vh] w-full flex-col items-center justify-center gap-4">
+			{message ? (
+				<div className="flex w-full max-w-sm flex-col gap-3">
+					<div className="text-sm">{message}</div>
+					{confirmationUrl ? (
+						<Button asChild>
+							<a href={confirmationUrl} target="_blank" rel="noopener noreferrer">
+								Confirm on WhatsApp
+							</a>
+						</Button>
+					) : null}
+				</div>
+			) : form ? (
+				<div className="flex w-full max-w-sm flex-col gap-3">
+					<div>
+						<h1 className="text-lg font-semibold">{form.name}</h1>
+						<p className="text-sm text-muted-foreground">{form.organizationName}</p>
+					</div>
+					<Input
+						placeholder="Name"
+						autoComplete="name"
+						value={name}
+						onChange={event => setName(event.target.value)}
+					/>
+					<Input
+						placeholder="WhatsApp number with country code"
+						autoComplete="tel"
+						value={phone}
+						onChange={event => setPhone(event.target.value)}
+					/>
+					<label className="flex items-start gap-2 text-sm">
+						<Checkbox
+							className="mt-0.5"
+							checked={consent}
+							onCheckedChange={checked => setConsent(checked === true)}
+						/>
+						<span>{form.consentText}</span>
+					</label>
+					{error ? <div className="text-sm text-red-500">{error}</div> : null}
+					<Button disabled={!name || !phone || !consent || isSubmitting} onClick={submit}>
+						Subscribe
+					</Button>
+				</div>
+			) : error ? (
+				<div className="text-sm text-red-500">{error}</div>
+			) : (
+				<LoadingSpinner />
+			)}
+		</div>
+	)
+}
+
+const OptInPage = () => {
+	return (
+		<Suspense>
+			<OptInForm />
+		</Suspense>
+	)
+}
+
+export default OptInPage
@@ -186,8 +186,8 @@ const ChatCanvas = ({ conversationId }: { conversationId?: string }) => {
 
 			const sendMessageResponse = await sendMessageInConversation.mutateAsync({
 				data: {
-					messageData: {
-						text: messageContent
+					text: {
+						body: messageContent
 					},
 					messageType: MessageTypeEnum.Text
 				},
@@ -10,12 +10,13 @@ import {
 } from '~/components/ui/form'
 import { Input } from '~/components/ui/input'
 import { zodResolver } from '@hookform/resolvers/zod'
-import { useState } from 'react'
+import { useEffect, useState } from 'react'
 import { useForm } from 'react-hook-form'
 import { z } from 'zod'
 import { useLogin } from '~/generated'
 import { useLocalStorage } from '~/hooks/use-local-storage'
-import { AUTH_TOKEN_LS } from '~/constants'
+import { AUTH_TOKEN_LS, OAUTH_PROVIDER_SS, REFRESH_TOKEN_LS } from '~/constants'
+import customInstance from '~/utils/api-client'
 
 const formSchema = z.object({
 	email: z.string().email({ message: 'Enter a valid email address' }),
@@ -26,6 +27,7 @@ type UserFormValue = z.infer<typeof formSchema>
 
 export default function UserLoginForm() {
 	const setAuthToken = useLocalStorage<string | undefined>(AUTH_TOKEN_LS, undefined)[1]
+	const setRefreshToken = useLocalStorage<string | undefined>(REFRESH_TOKEN_LS, undefined)[1]
 
 	const [loading] = useState(false)
 
@@ -40,6 +42,51 @@ export default function UserLoginForm() {
 
 	const mutation = useLogin()
 
+	const [twoFactorToken, setTwoFactorToken] = useState<string | null>(null)
+	const [twoFactorCode, setTwoFactorCode] = useState('')
+	const [twoFactorError, setTwoFactorError] = useState<string | null>(null)
+
+	const verifyTwoFactorCode = async () => {
+		if (!twoFactorToken || !twoFactorCode) return
+		try {
+			const response = await customInstance<{ token: string; refreshToken: string }>({
+				url: '/auth/two-factor/verify',
+				method: 'POST',
+				data: { twoFactorToken, code: twoFactorCode }
+			})
+			setAuthToken(response.token)
+			setRefreshToken(response.refreshToken)
+			window.location.href = '/dashboard'
+		} catch (error) {
+			setTwoFactorError((error as { message?: string }).message || 'Invalid code')
+		}
+	}
+
+	const [oauthProviders, setOauthProviders] = useState<{ name: string; displayName: string }[]>([])
+
+	useEffect(() => {
+		customInstance<{ providers: { name: string; displayName: string }[] }>({
+			url: '/auth/oauth/providers',
+			method: 'GET'
+		})
+			.then(response => setOauthProviders(response.providers || []))
+			.catch(() => setOauthProviders([]))
+	}, [])
+
+	const loginWithOAuthProvider = async (provider: string) => {
+		try {
+			const response = await customInstance<{ authorizationUrl: string }>({
+				url: `/auth/oauth/${provider}`,
+				method: 'GET'
+			})
+			// the provider redirects back without its name, the callback page reads it from here
+			sessionStorage.setItem(OAUTH_PROVIDER_SS, provider)
+			window.location.href = response.authorizationUrl
+		} catch (error) {
+			console.error(error)
+		}
+	}
+
 	const onSubmit = async (data: UserFormValue) => {
 		await mutation.mutateAsync(
 			{
@@ -50,8 +97,11 @@ export default function UserLoginForm() {
 			},
 			{
 				onSuccess: data => {
-					if (data.token) {
+					if (data.isTwoFactorRequired && data.twoFactorToken) {
+						setTwoFactorToken(data.twoFactorToken)
+					} else if (data.token) {
 						setAuthToken(data.token)
+						setRefreshToken(data.refreshToken)
 						window.location.href = '/dashboard'
 					} else {
 						// something went wrong show error token not found
@@ -64,6 +114,26 @@ export default function UserLoginForm() {
 		)
 	}
 
+	if (twoFactorToken) {
+		return (
+			<div className="flex w-full flex-col gap-2 space-y-2">
+				<p className="text-sm">
+					Enter the code from your authenticator app, or one of your recovery codes.
+				</p>
+				<Input
+					placeholder="123456"
+					autoComplete="one-time-code"
+					value={twoFactorCode}
+					onChange={event => setTwoFactorCode(event.target.value)}
+				/>
+				{twoFactorError ? <p className="text-sm text-red-500">{twoFactorError}</p> : null}
+				<Button className="ml-auto w-full" type="button" onClick={verifyTwoFactorCode}>
+					Verify
+				</Button>
+			</div>
+		)
+	}
+
 	return (
 		<>
 			<Form {...form}>
@@ -114,6 +184,18 @@ export default function UserLoginForm() {
 					</Button>
 				</form>
 			</Form>
+			{oauthProviders.map(provider => (
+				<Button
+					key={provider.name}
+					variant="outline"
+					disabled={loading}
+					className="w-full"
+					type="button"
+					onClick={() => loginWithOAuthProvider(provider.name)}
+				>
+					Continue with {provider.displayName}
+				</Button>
+			))}
 		</>
 	)
 }
@@ -15,7 +15,7 @@ import { useForm } from 'react-hook-form'
 import { z } from 'zod'
 import { useRegister, useVerifyOtp } from '~/generated'
 import { useLocalStorage } from '~/hooks/use-local-storage'
-import { AUTH_TOKEN_LS } from '~/constants'
+import { AUTH_TOKEN_LS, REFRESH_TOKEN_LS } from '~/constants'
 import { errorNotification } from '~/reusable-functions'
 
 const otpFormSchema = z.object({
@@ -36,6 +36,7 @@ type OtpFormValue = z.infer<typeof otpFormSchema>
 
 export default function UserSignupForm() {
 	const setAuthToken = useLocalStorage<string | undefined>(AUTH_TOKEN_LS, undefined)[1]
+	const setRefreshToken = useLocalStorage<string | undefined>(REFRESH_TOKEN_LS, undefined)[1]
 
 	const [isBusy, setIsBusy] = useState(false)
 	const [activeForm, setActiveForm] = useState<'registrationDetailsForm' | 'otpForm'>(
@@ -127,6 +128,7 @@ export default function UserSignupForm() {
 
 			if (response.token) {
 				setAuthToken(response.token)
+				setRefreshToken(response.refreshToken)
 				window.location.href = '/dashboard'
 			} else {
 				// something went wrong show error token not found
@@ -16,7 +16,13 @@ const AuthProvisioner: React.FC<{ children: React.ReactNode }> = ({ children })
 	const { writeProperty, onboardingSteps, currentOrganization, user } = useLayoutStore()
 
 	useEffect(() => {
-		if (pathname === '/signin' || pathname === '/logout' || pathname === '/signup') {
+		if (
+			pathname === '/signin' ||
+			pathname === '/logout' ||
+			pathname === '/signup' ||
+			pathname === '/oauth/callback' ||
+			pathname === '/opt-in'
+		) {
 			return
 		} else {
 			if (authState.isAuthenticated === false) {
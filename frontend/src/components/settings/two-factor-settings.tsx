@@ -0,0 +1,152 @@
+'use client'
+
+import { useState } from 'react'
+import Image from 'next/image'
+import { Button } from '~/components/ui/button'
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '~/components/ui/card'
+import { Input } from '~/components/ui/input'
+import { errorNotification, successNotification } from '~/reusable-functions'
+import customInstance from '~/utils/api-client'
+
+type TwoFactorSetup = { secret: string; otpauthUrl: string; qrCode: string }
+
+const TwoFactorSettings: React.FC<{
+	isTwoFactorEnabled: boolean
+	isTwoFactorRequired: boolean
+	onChange: (isEnabled: boolean) => void
+}> = ({ isTwoFactorEnabled, isTwoFactorRequired, onChange }) => {
+	const [isBusy, setIsBusy] = useState(false)
+	const [setup, setSetup] = useState<TwoFactorSetup | null>(null)
+	const [code, setCode] = useState('')
+	const [recoveryCodes, setRecoveryCodes] = useState<string[] | null>(null)
+
+	const run = async (action: () => Promise<void>) => {
+		try {
+			setIsBusy(true)
+			await action()
+		} catch (error) {
+			errorNotification({
+				message: (error as { message?: string }).message || 'Something went wrong'
+			})
+		} finally {
+			setIsBusy(false)
+		}
+	}
+
+	const startSetup = () =>
+		run(async () => {
+			const response = await customInstance<TwoFactorSetup>({
+				url: '/user/two-factor/setup',
+				method: 'POST'
+			})
+			setSetup(response)
+			setRecoveryCodes(null)
+		})
+
+	const submitCode = (url: string) =>
+		run(async () => {
+			const response = await customInstance<{ recoveryCodes?: string[] }>({
+				url,
+				method: 'POST',
+				data: { code }
+			})
+			setCode('')
+			if (url === '/user/two-factor/disable') {
+				successNotification({ message: 'Two factor authentication disabled' })
+				onChange(false)
+				return
+			}
+			setSetup(null)
+			setRecoveryCodes(response.recoveryCodes || [])
+			if (url === '/user/two-factor/enable') {
+				successNotification({ message: 'Two factor authentication enabled' })
+				onChange(true)
+			}
+		})
+
+	return (
+		<Card>
+			<CardHeader>
+				<CardTitle>Two Factor Authentication</CardTitle>
+				<CardDescription>
+					{isTwoFactorEnabled
+						? 'Two factor authentication is enabled for your account.'
+						: isTwoFactorRequired
+							? 'Your organization requires two factor authentication, some settings are unavailable until you enable it.'
+							: 'Protect your account with a code from an authenticator app.'}
+				</CardDescription>
+			</CardHeader>
+			<CardContent className="flex flex-col gap-3">
+				{recoveryCodes ? (
+					<div className="flex flex-col gap-2">
+						<p className="text-sm">
+							Save these recovery codes somewhere safe, each can be used once if you
+							lose access to your authenticator app. They will not be shown again.
+						</p>
+						<div className="grid grid-cols-2 gap-1 rounded-md border p-3 font-mono text-sm">
+							{recoveryCodes.map(recoveryCode => (
+								<span key={recoveryCode}>{recoveryCode}</span>
+							))}
+						</div>
+					</div>
+				) : null}
+
+				{setup ? (
+					<div className="flex flex-col gap-2">
+						<p className="text-sm">
+							Scan the QR code with your authenticator app, or enter the secret manually.
+						</p>
+						<Image src={setup.qrCode} width={200} height={200} alt="two factor QR code" />
+						<code className="text-sm">{setup.secret}</code>
+					</div>
+				) : null}
+
+				{setup || isTwoFactorEnabled ? (
+					<Input
+						placeholder={isTwoFactorEnabled ? 'Authentication or recovery code' : '123456'}
+						value={code}
+						autoComplete="one-time-code"
+						disabled={isBusy}
+						onChange={event => setCode(event.target.value)}
+					/>
+				) : null}
+
+				<div className="flex flex-row gap-2">
+					{!isTwoFactorEnabled && !setup ? (
+						<Button disabled={isBusy} onClick={startSetup}>
+							Enable
+						</Button>
+					) : null}
+					{setup ? (
+						<Button
+							disabled={isBusy || !code}
+							onClick={() => submitCode('/user/two-factor/enable')}
+						>
+							Verify and Enable
+						</Button>
+					) : null}
+					{isTwoFactorEnabled ? (
+						<>
+							<Button
+								variant="outline"
+								disabled={isBusy || !code}
+								onClick={() => submitCode('/user/two-factor/recovery-codes')}
+							>
+								Regenerate Recovery Codes
+							</Button>
+							<Button
+								variant="destructive"
+								disabled={isBusy || !code || isTwoFactorRequired}
+								onClick={() => submitCode('/user/two-factor/disable')}
+							>
+								Disable
+							</Button>
+						</>
+					) : null}
+				</div>
+			</CardContent>
+		</Card>
+	)
+}
+
+export default TwoFactorSettings
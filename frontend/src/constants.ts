@@ -8,6 +8,8 @@ export const IS_PRODUCTION = process.env.NODE_ENV === 'production'
 export const IS_DEVELOPMENT = process.env.NODE_ENV === 'development'
 
 export const AUTH_TOKEN_LS = '__auth_token'
+export const REFRESH_TOKEN_LS = '__refresh_token'
+export const OAUTH_PROVIDER_SS = '__oauth_provider'
 
 export function getBackendUrl() {
 	if (IS_DEVELOPMENT) {
@@ -158,6 +158,36 @@ export function useWebsocket() {
 						break
 					}
 
+					case WebsocketEventEnum.PresenceChangedEvent: {
+						// handle presence changed event
+						break
+					}
+
+					case WebsocketEventEnum.NewConversationNoteEvent: {
+						// handle new conversation note event
+						break
+					}
+
+					case WebsocketEventEnum.ConversationStatusChangedEvent: {
+						// handle conversation status changed event
+						break
+					}
+
+					case WebsocketEventEnum.MessageStatusChangedEvent: {
+						// handle message status changed event
+						break
+					}
+
+					case WebsocketEventEnum.TypingEvent: {
+						// typing events are only sent by the client
+						break
+					}
+
+					case WebsocketEventEnum.BackgroundJobProgressEvent: {
+						// handle background job progress event
+						break
+					}
+
 					default: {
 						throw new Error('Unhandled event')
 					}
@@ -1,11 +1,39 @@
-import { AUTH_TOKEN_LS, getBackendUrl } from '~/constants'
-
-export const customInstance = async <T>({
-	url,
-	method,
-	params,
-	data
-}: {
+import { AUTH_TOKEN_LS, REFRESH_TOKEN_LS, getBackendUrl } from '~/constants'
+
+// shared between concurrent requests, so that a refresh token is only ever used once
+let refreshPromise: Promise<boolean> | null = null
+
+const refreshAccessToken = async (): Promise<boolean> => {
+	const refreshToken = localStorage.getItem(REFRESH_TOKEN_LS)
+	if (!refreshToken) {
+		return false
+	}
+
+	const response = await fetch(`${getBackendUrl()}/auth/refresh`, {
+		method: 'POST',
+		body: JSON.stringify({ refreshToken }),
+		headers: {
+			'Content-Type': 'application/json',
+			Accept: 'application/json'
+		},
+		credentials: 'include',
+		mode: 'cors',
+		cache: 'no-cache'
+	}).catch(() => null)
+
+	if (!response || !response.ok) {
+		localStorage.removeItem(AUTH_TOKEN_LS)
+		localStorage.removeItem(REFRESH_TOKEN_LS)
+		return false
+	}
+
+	const tokens: { token: string; refreshToken: string } = await response.json()
+	localStorage.setItem(AUTH_TOKEN_LS, tokens.token)
+	localStorage.setItem(REFRESH_TOKEN_LS, tokens.refreshToken)
+	return true
+}
+
+type RequestConfig = {
 	url: string
 	method: 'GET' | 'POST' | 'PUT' | 'DELETE' | 'PATCH'
 	params?: any
@@ -13,7 +41,9 @@ export const customInstance = async <T>({
 	responseType?: string
 	signal?: AbortSignal
 	headers?: Record<string, string>
-}): Promise<T> => {
+}
+
+const request = async <T>({ url, method, params, data }: RequestConfig, isRetry: boolean): Promise<T> => {
 	const authToken = localStorage.getItem(AUTH_TOKEN_LS)
 	const headers = new Headers()
 	headers.set('Content-Type', 'application/json')
@@ -37,6 +67,19 @@ export const customInstance = async <T>({
 		}
 	)
 
+	if (response.status === 401 && authToken && !isRetry) {
+		// access tokens are short lived, try to get a new one with the refresh token and retry once
+		if (!refreshPromise) {
+			refreshPromise = refreshAccessToken().finally(() => {
+				refreshPromise = null
+			})
+		}
+
+		if (await refreshPromise) {
+			return request<T>({ url, method, params, data }, true)
+		}
+	}
+
 	if (!response.ok) {
 		// Gracefully return an error object
 		const errorData = await response.json().catch(() => ({})) // Handle non-JSON error responses
@@ -51,4 +94,8 @@ export const customInstance = async <T>({
 	return responseData
 }
 
+export const customInstance = async <T>(config: RequestConfig): Promise<T> => {
+	return request<T>(config, false)
+}
+
 export default customInstance
@@ -1,4 +1,14 @@
-import { MessageDirectionEnum, MessageStatusEnum, MessageTypeEnum } from 'root/.generated'
+import {
+	BackgroundJobStatusEnum,
+	BackgroundJobTypeEnum,
+	ConversationStatusEnum,
+	ConversationTimelineEventTriggerEnum,
+	ConversationTimelineEventTypeEnum,
+	MessageDirectionEnum,
+	MessageStatusEnum,
+	MessageTypeEnum,
+	PresenceStatusEnum
+} from 'root/.generated'
 import { z } from 'zod'
 
 export enum WebsocketEventEnum {
@@ -11,7 +21,13 @@ export enum WebsocketEventEnum {
 	ConversationAssignmentEvent = 'ConversationAssignmentEvent',
 	ConversationClosedEvent = 'ConversationClosedEvent',
 	NewConversationEvent = 'NewConversationEvent',
-	PingEvent = 'PingEvent'
+	PingEvent = 'PingEvent',
+	PresenceChangedEvent = 'PresenceChangedEvent',
+	NewConversationNoteEvent = 'NewConversationNoteEvent',
+	ConversationStatusChangedEvent = 'ConversationStatusChangedEvent',
+	MessageStatusChangedEvent = 'MessageStatusChangedEvent',
+	TypingEvent = 'TypingEvent',
+	BackgroundJobProgressEvent = 'BackgroundJobProgressEvent'
 }
 
 export const WebsocketEventDataMap = {
@@ -41,7 +57,10 @@ export const WebsocketEventDataMap = {
 		eventId: z.string(),
 		eventName: z.literal(WebsocketEventEnum.MessageReadEvent),
 		data: z.object({
-			messageId: z.string()
+			conversationId: z.string(),
+			messageIds: z.array(z.string()),
+			numberOfUnreadMessages: z.number(),
+			readByMemberId: z.string()
 		})
 	}),
 	[WebsocketEventEnum.NewNotificationEvent]: z.object({
@@ -106,5 +125,79 @@ export const WebsocketEventDataMap = {
 		data: z.object({
 			message: z.string()
 		})
+	}),
+	[WebsocketEventEnum.PresenceChangedEvent]: z.object({
+		eventName: z.literal(WebsocketEventEnum.PresenceChangedEvent),
+		eventId: z.string(),
+		data: z.object({
+			memberId: z.string(),
+			userId: z.string(),
+			status: z.nativeEnum(PresenceStatusEnum),
+			lastSeenAt: z.string().optional()
+		})
+	}),
+	[WebsocketEventEnum.NewConversationNoteEvent]: z.object({
+		eventName: z.literal(WebsocketEventEnum.NewConversationNoteEvent),
+		eventId: z.string(),
+		data: z.object({
+			uniqueId: z.string(),
+			conversationId: z.string(),
+			createdAt: z.string(),
+			content: z.string(),
+			authorMemberId: z.string(),
+			authorName: z.string(),
+			mentionedMemberIds: z.array(z.string())
+		})
+	}),
+	[WebsocketEventEnum.ConversationStatusChangedEvent]: z.object({
+		eventName: z.literal(WebsocketEventEnum.ConversationStatusChangedEvent),
+		eventId: z.string(),
+		data: z.object({
+			uniqueId: z.string(),
+			conversationId: z.string(),
+			createdAt: z.string(),
+			eventType: z.nativeEnum(ConversationTimelineEventTypeEnum),
+			trigger: z.nativeEnum(ConversationTimelineEventTriggerEnum),
+			fromStatus: z.nativeEnum(ConversationStatusEnum),
+			toStatus: z.nativeEnum(ConversationStatusEnum),
+			actorMemberId: z.string().optional(),
+			actorName: z.string().optional(),
+			snoozedUntil: z.string().optional()
+		})
+	}),
+	[WebsocketEventEnum.MessageStatusChangedEvent]: z.object({
+		eventName: z.literal(WebsocketEventEnum.MessageStatusChangedEvent),
+		eventId: z.string(),
+		data: z.object({
+			conversationId: z.string(),
+			messageId: z.string(),
+			status: z.nativeEnum(MessageStatusEnum)
+		})
+	}),
+	[WebsocketEventEnum.TypingEvent]: z.object({
+		eventName: z.literal(WebsocketEventEnum.TypingEvent),
+		eventId: z.string(),
+		data: z.object({
+			conversationId: z.string()
+		})
+	}),
+	[WebsocketEventEnum.BackgroundJobProgressEvent]: z.object({
+		eventName: z.literal(WebsocketEventEnum.BackgroundJobProgressEvent),
+		eventId: z.string(),
+		data: z.object({
+			job: z.object({
+				uniqueId: z.string(),
+				type: z.nativeEnum(BackgroundJobTypeEnum),
+				status: z.nativeEnum(BackgroundJobStatusEnum),
+				totalItems: z.number().optional(),
+				processedItems: z.number(),
+				failedItems: z.number(),
+				result: z.record(z.unknown()).optional(),
+				error: z.string().optional(),
+				createdAt: z.string(),
+				startedAt: z.string().optional(),
+				completedAt: z.string().optional()
+			})
+		})
 	})
 }
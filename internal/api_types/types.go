@@ -44,14 +44,102 @@ const (
 	Mistral     AiModelEnum = "Mistral"
 )
 
+// Defines values for AnalyticsPeriodEnum.
+const (
+	Day   AnalyticsPeriodEnum = "Day"
+	Month AnalyticsPeriodEnum = "Month"
+	Week  AnalyticsPeriodEnum = "Week"
+)
+
+// Defines values for BackgroundJobStatusEnum.
+const (
+	BackgroundJobStatusEnumCompleted BackgroundJobStatusEnum = "Completed"
+	BackgroundJobStatusEnumFailed    BackgroundJobStatusEnum = "Failed"
+	BackgroundJobStatusEnumQueued    BackgroundJobStatusEnum = "Queued"
+	BackgroundJobStatusEnumRunning   BackgroundJobStatusEnum = "Running"
+)
+
+// Defines values for BackgroundJobTypeEnum.
+const (
+	ContactDuplicateScan BackgroundJobTypeEnum = "ContactDuplicateScan"
+	ContactImport        BackgroundJobTypeEnum = "ContactImport"
+	ContactListOperation BackgroundJobTypeEnum = "ContactListOperation"
+)
+
 // Defines values for CampaignStatusEnum.
 const (
-	Cancelled CampaignStatusEnum = "Cancelled"
-	Draft     CampaignStatusEnum = "Draft"
-	Finished  CampaignStatusEnum = "Finished"
-	Paused    CampaignStatusEnum = "Paused"
-	Running   CampaignStatusEnum = "Running"
-	Scheduled CampaignStatusEnum = "Scheduled"
+	CampaignStatusEnumCancelled CampaignStatusEnum = "Cancelled"
+	CampaignStatusEnumDraft     CampaignStatusEnum = "Draft"
+	CampaignStatusEnumFinished  CampaignStatusEnum = "Finished"
+	CampaignStatusEnumPaused    CampaignStatusEnum = "Paused"
+	CampaignStatusEnumRunning   CampaignStatusEnum = "Running"
+	CampaignStatusEnumScheduled CampaignStatusEnum = "Scheduled"
+)
+
+// Defines values for ContactCaptureSourceTypeEnum.
+const (
+	ContactCaptureSourceTypeEnumForm ContactCaptureSourceTypeEnum = "Form"
+	ContactCaptureSourceTypeEnumLink ContactCaptureSourceTypeEnum = "Link"
+)
+
+// Defines values for ContactConsentCategoryEnum.
+const (
+	Marketing ContactConsentCategoryEnum = "Marketing"
+	Utility   ContactConsentCategoryEnum = "Utility"
+)
+
+// Defines values for ContactConsentSourceEnum.
+const (
+	ContactConsentSourceEnumApi     ContactConsentSourceEnum = "Api"
+	ContactConsentSourceEnumForm    ContactConsentSourceEnum = "Form"
+	ContactConsentSourceEnumImport  ContactConsentSourceEnum = "Import"
+	ContactConsentSourceEnumKeyword ContactConsentSourceEnum = "Keyword"
+	ContactConsentSourceEnumLegacy  ContactConsentSourceEnum = "Legacy"
+)
+
+// Defines values for ContactConsentStatusEnum.
+const (
+	ContactConsentStatusEnumGranted ContactConsentStatusEnum = "Granted"
+	ContactConsentStatusEnumPending ContactConsentStatusEnum = "Pending"
+	ContactConsentStatusEnumRevoked ContactConsentStatusEnum = "Revoked"
+)
+
+// Defines values for ContactErasureModeEnum.
+const (
+	Anonymize ContactErasureModeEnum = "Anonymize"
+	Delete    ContactErasureModeEnum = "Delete"
+)
+
+// Defines values for ContactExportFormatEnum.
+const (
+	Csv  ContactExportFormatEnum = "Csv"
+	Json ContactExportFormatEnum = "Json"
+)
+
+// Defines values for ContactFieldTypeEnum.
+const (
+	Boolean ContactFieldTypeEnum = "Boolean"
+	Date    ContactFieldTypeEnum = "Date"
+	Enum    ContactFieldTypeEnum = "Enum"
+	Number  ContactFieldTypeEnum = "Number"
+	String  ContactFieldTypeEnum = "String"
+)
+
+// Defines values for ContactImportConflictStrategyEnum.
+const (
+	Skip   ContactImportConflictStrategyEnum = "Skip"
+	Upsert ContactImportConflictStrategyEnum = "Upsert"
+)
+
+// Defines values for ContactListOperationEnum.
+const (
+	Copy         ContactListOperationEnum = "Copy"
+	Dedupe       ContactListOperationEnum = "Dedupe"
+	Difference   ContactListOperationEnum = "Difference"
+	Intersection ContactListOperationEnum = "Intersection"
+	Move         ContactListOperationEnum = "Move"
+	Split        ContactListOperationEnum = "Split"
+	Union        ContactListOperationEnum = "Union"
 )
 
 // Defines values for ContactStatusEnum.
@@ -61,29 +149,83 @@ const (
 	ContactStatusEnumInactive ContactStatusEnum = "Inactive"
 )
 
+// Defines values for ContactTimelineEventTypeEnum.
+const (
+	AttributesChanged         ContactTimelineEventTypeEnum = "AttributesChanged"
+	CampaignMessage           ContactTimelineEventTypeEnum = "CampaignMessage"
+	ConversationAssigned      ContactTimelineEventTypeEnum = "ConversationAssigned"
+	ConversationStarted       ContactTimelineEventTypeEnum = "ConversationStarted"
+	ConversationStatusChanged ContactTimelineEventTypeEnum = "ConversationStatusChanged"
+	ConversationUnassigned    ContactTimelineEventTypeEnum = "ConversationUnassigned"
+	LinkClicked               ContactTimelineEventTypeEnum = "LinkClicked"
+	ListJoined                ContactTimelineEventTypeEnum = "ListJoined"
+	ListLeft                  ContactTimelineEventTypeEnum = "ListLeft"
+	Merged                    ContactTimelineEventTypeEnum = "Merged"
+	OptedIn                   ContactTimelineEventTypeEnum = "OptedIn"
+	OptedOut                  ContactTimelineEventTypeEnum = "OptedOut"
+	StatusChanged             ContactTimelineEventTypeEnum = "StatusChanged"
+)
+
 // Defines values for ConversationInitiatedByEnum.
 const (
-	Campaign ConversationInitiatedByEnum = "Campaign"
-	Contact  ConversationInitiatedByEnum = "Contact"
+	ConversationInitiatedByEnumCampaign ConversationInitiatedByEnum = "Campaign"
+	ConversationInitiatedByEnumContact  ConversationInitiatedByEnum = "Contact"
+)
+
+// Defines values for ConversationMessageItemTypeEnum.
+const (
+	ConversationMessageItemTypeEnumMessage ConversationMessageItemTypeEnum = "Message"
+	ConversationMessageItemTypeEnumNote    ConversationMessageItemTypeEnum = "Note"
+)
+
+// Defines values for ConversationRoutingStrategyEnum.
+const (
+	LeastBusy  ConversationRoutingStrategyEnum = "LeastBusy"
+	RoundRobin ConversationRoutingStrategyEnum = "RoundRobin"
 )
 
 // Defines values for ConversationStatusEnum.
 const (
-	ConversationStatusEnumActive  ConversationStatusEnum = "Active"
-	ConversationStatusEnumClosed  ConversationStatusEnum = "Closed"
-	ConversationStatusEnumDeleted ConversationStatusEnum = "Deleted"
+	ConversationStatusEnumActive   ConversationStatusEnum = "Active"
+	ConversationStatusEnumClosed   ConversationStatusEnum = "Closed"
+	ConversationStatusEnumDeleted  ConversationStatusEnum = "Deleted"
+	ConversationStatusEnumResolved ConversationStatusEnum = "Resolved"
+	ConversationStatusEnumSnoozed  ConversationStatusEnum = "Snoozed"
+)
+
+// Defines values for ConversationTimelineEventTriggerEnum.
+const (
+	ConversationTimelineEventTriggerEnumContact       ConversationTimelineEventTriggerEnum = "Contact"
+	ConversationTimelineEventTriggerEnumInactivity    ConversationTimelineEventTriggerEnum = "Inactivity"
+	ConversationTimelineEventTriggerEnumMember        ConversationTimelineEventTriggerEnum = "Member"
+	ConversationTimelineEventTriggerEnumSnoozeExpired ConversationTimelineEventTriggerEnum = "SnoozeExpired"
+)
+
+// Defines values for ConversationTimelineEventTypeEnum.
+const (
+	ConversationTimelineEventTypeEnumClosed   ConversationTimelineEventTypeEnum = "Closed"
+	ConversationTimelineEventTypeEnumReopened ConversationTimelineEventTypeEnum = "Reopened"
+	ConversationTimelineEventTypeEnumResolved ConversationTimelineEventTypeEnum = "Resolved"
+	ConversationTimelineEventTypeEnumSnoozed  ConversationTimelineEventTypeEnum = "Snoozed"
+	ConversationTimelineEventTypeEnumWoken    ConversationTimelineEventTypeEnum = "Woken"
 )
 
 // Defines values for IntegrationStatusEnum.
 const (
-	IntegrationStatusEnumActive   IntegrationStatusEnum = "Active"
-	IntegrationStatusEnumInactive IntegrationStatusEnum = "Inactive"
+	Active   IntegrationStatusEnum = "Active"
+	Inactive IntegrationStatusEnum = "Inactive"
+)
+
+// Defines values for InteractiveMessageTypeEnum.
+const (
+	Buttons InteractiveMessageTypeEnum = "Buttons"
+	List    InteractiveMessageTypeEnum = "List"
 )
 
 // Defines values for InviteStatusEnum.
 const (
-	Pending  InviteStatusEnum = "Pending"
-	Redeemed InviteStatusEnum = "Redeemed"
+	InviteStatusEnumPending  InviteStatusEnum = "Pending"
+	InviteStatusEnumRedeemed InviteStatusEnum = "Redeemed"
 )
 
 // Defines values for MessageDirectionEnum.
@@ -138,16 +280,18 @@ const (
 
 // Defines values for MessageTypeEnum.
 const (
-	Address  MessageTypeEnum = "Address"
-	Audio    MessageTypeEnum = "Audio"
-	Contacts MessageTypeEnum = "Contacts"
-	Document MessageTypeEnum = "Document"
-	Image    MessageTypeEnum = "Image"
-	Location MessageTypeEnum = "Location"
-	Reaction MessageTypeEnum = "Reaction"
-	Sticker  MessageTypeEnum = "Sticker"
-	Text     MessageTypeEnum = "Text"
-	Video    MessageTypeEnum = "Video"
+	Address     MessageTypeEnum = "Address"
+	Audio       MessageTypeEnum = "Audio"
+	Contacts    MessageTypeEnum = "Contacts"
+	Document    MessageTypeEnum = "Document"
+	Image       MessageTypeEnum = "Image"
+	Interactive MessageTypeEnum = "Interactive"
+	Location    MessageTypeEnum = "Location"
+	Reaction    MessageTypeEnum = "Reaction"
+	Sticker     MessageTypeEnum = "Sticker"
+	Template    MessageTypeEnum = "Template"
+	Text        MessageTypeEnum = "Text"
+	Video       MessageTypeEnum = "Video"
 )
 
 // Defines values for OrderEnum.
@@ -156,17 +300,27 @@ const (
 	Desc OrderEnum = "desc"
 )
 
+// Defines values for PresenceStatusEnum.
+const (
+	Away    PresenceStatusEnum = "Away"
+	Offline PresenceStatusEnum = "Offline"
+	OnBreak PresenceStatusEnum = "OnBreak"
+	Online  PresenceStatusEnum = "Online"
+)
+
 // Defines values for RolePermissionEnum.
 const (
 	AssignConversation        RolePermissionEnum = "Assign:Conversation"
 	BulkImportContacts        RolePermissionEnum = "BulkImport:Contacts"
 	CreateCampaign            RolePermissionEnum = "Create:Campaign"
+	CreateCannedResponse      RolePermissionEnum = "Create:CannedResponse"
 	CreateContact             RolePermissionEnum = "Create:Contact"
 	CreateList                RolePermissionEnum = "Create:List"
 	CreateOrganizationMember  RolePermissionEnum = "Create:OrganizationMember"
 	CreateOrganizationRole    RolePermissionEnum = "Create:OrganizationRole"
 	CreateTag                 RolePermissionEnum = "Create:Tag"
 	DeleteCampaign            RolePermissionEnum = "Delete:Campaign"
+	DeleteCannedResponse      RolePermissionEnum = "Delete:CannedResponse"
 	DeleteContact             RolePermissionEnum = "Delete:Contact"
 	DeleteConversation        RolePermissionEnum = "Delete:Conversation"
 	DeleteList                RolePermissionEnum = "Delete:List"
@@ -177,6 +331,7 @@ const (
 	GetAppSettings            RolePermissionEnum = "Get:AppSettings"
 	GetCampaign               RolePermissionEnum = "Get:Campaign"
 	GetCampaignAnalytics      RolePermissionEnum = "Get:CampaignAnalytics"
+	GetCannedResponse         RolePermissionEnum = "Get:CannedResponse"
 	GetContact                RolePermissionEnum = "Get:Contact"
 	GetConversation           RolePermissionEnum = "Get:Conversation"
 	GetList                   RolePermissionEnum = "Get:List"
@@ -191,6 +346,7 @@ const (
 	UnassignConversation      RolePermissionEnum = "Unassign:Conversation"
 	UpdateAppSettings         RolePermissionEnum = "Update:AppSettings"
 	UpdateCampaign            RolePermissionEnum = "Update:Campaign"
+	UpdateCannedResponse      RolePermissionEnum = "Update:CannedResponse"
 	UpdateContact             RolePermissionEnum = "Update:Contact"
 	UpdateConversation        RolePermissionEnum = "Update:Conversation"
 	UpdateIntegrationSettings RolePermissionEnum = "Update:IntegrationSettings"
@@ -201,6 +357,57 @@ const (
 	UpdateTag                 RolePermissionEnum = "Update:Tag"
 )
 
+// Defines values for SearchResultTypeEnum.
+const (
+	SearchResultTypeEnumContact SearchResultTypeEnum = "Contact"
+	SearchResultTypeEnumMessage SearchResultTypeEnum = "Message"
+)
+
+// Defines values for SegmentConditionFieldEnum.
+const (
+	Attribute           SegmentConditionFieldEnum = "Attribute"
+	ClickedCampaignLink SegmentConditionFieldEnum = "ClickedCampaignLink"
+	ConversationTag     SegmentConditionFieldEnum = "ConversationTag"
+	ListMembership      SegmentConditionFieldEnum = "ListMembership"
+	ReadCampaign        SegmentConditionFieldEnum = "ReadCampaign"
+	Replied             SegmentConditionFieldEnum = "Replied"
+	Status              SegmentConditionFieldEnum = "Status"
+)
+
+// Defines values for SegmentConditionOperatorEnum.
+const (
+	Contains      SegmentConditionOperatorEnum = "Contains"
+	Equals        SegmentConditionOperatorEnum = "Equals"
+	Exists        SegmentConditionOperatorEnum = "Exists"
+	GreaterThan   SegmentConditionOperatorEnum = "GreaterThan"
+	LessThan      SegmentConditionOperatorEnum = "LessThan"
+	NotContains   SegmentConditionOperatorEnum = "NotContains"
+	NotEquals     SegmentConditionOperatorEnum = "NotEquals"
+	NotExists     SegmentConditionOperatorEnum = "NotExists"
+	NotWithinDays SegmentConditionOperatorEnum = "NotWithinDays"
+	WithinDays    SegmentConditionOperatorEnum = "WithinDays"
+)
+
+// Defines values for SegmentMatchEnum.
+const (
+	All SegmentMatchEnum = "All"
+	Any SegmentMatchEnum = "Any"
+)
+
+// Defines values for SlaEscalationActionEnum.
+const (
+	Notify   SlaEscalationActionEnum = "Notify"
+	Reassign SlaEscalationActionEnum = "Reassign"
+	Tag      SlaEscalationActionEnum = "Tag"
+)
+
+// Defines values for SlaStatusEnum.
+const (
+	Breached SlaStatusEnum = "Breached"
+	Met      SlaStatusEnum = "Met"
+	Pending  SlaStatusEnum = "Pending"
+)
+
 // Defines values for TemplateMessageButtonType.
 const (
 	COPYCODE    TemplateMessageButtonType = "COPY_CODE"
@@ -215,6 +422,25 @@ const (
 	Owner  UserPermissionLevelEnum = "Owner"
 )
 
+// Defines values for WeekdayEnum.
+const (
+	Friday    WeekdayEnum = "Friday"
+	Monday    WeekdayEnum = "Monday"
+	Saturday  WeekdayEnum = "Saturday"
+	Sunday    WeekdayEnum = "Sunday"
+	Thursday  WeekdayEnum = "Thursday"
+	Tuesday   WeekdayEnum = "Tuesday"
+	Wednesday WeekdayEnum = "Wednesday"
+)
+
+// Defines values for WhatsAppBusinessAccountHealthStatusEnum.
+const (
+	Expiring WhatsAppBusinessAccountHealthStatusEnum = "Expiring"
+	Healthy  WhatsAppBusinessAccountHealthStatusEnum = "Healthy"
+	Invalid  WhatsAppBusinessAccountHealthStatusEnum = "Invalid"
+	Unknown  WhatsAppBusinessAccountHealthStatusEnum = "Unknown"
+)
+
 // Defines values for GetMessagesParamsStatus.
 const (
 	GetMessagesParamsStatusFailed GetMessagesParamsStatus = "failed"
@@ -324,6 +550,9 @@ type AiConfigurationDetailsSchema struct {
 // AiModelEnum defines model for AiModelEnum.
 type AiModelEnum string
 
+// AnalyticsPeriodEnum defines model for AnalyticsPeriodEnum.
+type AnalyticsPeriodEnum string
+
 // ApiKeySchema defines model for ApiKeySchema.
 type ApiKeySchema struct {
 	CreatedAt time.Time `json:"createdAt"`
@@ -341,9 +570,35 @@ type AssignConversationSchema struct {
 	OrganizationMemberId string `json:"organizationMemberId"`
 }
 
+// BackgroundJobSchema defines model for BackgroundJobSchema.
+type BackgroundJobSchema struct {
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	Error          *string    `json:"error,omitempty"`
+	FailedItems    int        `json:"failedItems"`
+	ProcessedItems int        `json:"processedItems"`
+
+	// Result the summary of a completed job, imports report the created, updated, skipped and failed contacts
+	Result    *map[string]interface{} `json:"result,omitempty"`
+	StartedAt *time.Time              `json:"startedAt,omitempty"`
+	Status    BackgroundJobStatusEnum `json:"status"`
+
+	// TotalItems unknown until the job has started
+	TotalItems *int                  `json:"totalItems,omitempty"`
+	Type       BackgroundJobTypeEnum `json:"type"`
+	UniqueId   string                `json:"uniqueId"`
+}
+
+// BackgroundJobStatusEnum defines model for BackgroundJobStatusEnum.
+type BackgroundJobStatusEnum string
+
+// BackgroundJobTypeEnum defines model for BackgroundJobTypeEnum.
+type BackgroundJobTypeEnum string
+
 // BulkImportResponseSchema defines model for BulkImportResponseSchema.
 type BulkImportResponseSchema struct {
-	Message string `json:"message"`
+	Job     BackgroundJobSchema `json:"job"`
+	Message string              `json:"message"`
 }
 
 // BulkImportSchema defines model for BulkImportSchema.
@@ -352,6 +607,24 @@ type BulkImportSchema struct {
 	ListIds   *[]string `json:"listIds,omitempty"`
 }
 
+// BusinessCalendarSchema defines model for BusinessCalendarSchema.
+type BusinessCalendarSchema struct {
+	// Holidays dates in the YYYY-MM-DD format on which the timers are paused for the whole day
+	Holidays    []string                    `json:"holidays"`
+	WeeklyHours []BusinessHoursWindowSchema `json:"weeklyHours"`
+}
+
+// BusinessHoursWindowSchema defines model for BusinessHoursWindowSchema.
+type BusinessHoursWindowSchema struct {
+	Day WeekdayEnum `json:"day"`
+
+	// End closing time in the HH:MM format
+	End string `json:"end"`
+
+	// Start opening time in the HH:MM format
+	Start string `json:"start"`
+}
+
 // CampaignAnalyticsResponseSchema defines model for CampaignAnalyticsResponseSchema.
 type CampaignAnalyticsResponseSchema struct {
 	ConversationInitiated int                              `json:"conversationInitiated"`
@@ -367,13 +640,16 @@ type CampaignAnalyticsResponseSchema struct {
 
 // CampaignSchema defines model for CampaignSchema.
 type CampaignSchema struct {
-	CreatedAt                   time.Time               `json:"createdAt"`
-	Description                 *string                 `json:"description,omitempty"`
-	IsLinkTrackingEnabled       bool                    `json:"isLinkTrackingEnabled"`
-	Lists                       []ContactListSchema     `json:"lists"`
-	Name                        string                  `json:"name"`
-	PhoneNumberInUse            *string                 `json:"phoneNumberInUse,omitempty"`
-	ScheduledAt                 *time.Time              `json:"scheduledAt,omitempty"`
+	CreatedAt             time.Time           `json:"createdAt"`
+	Description           *string             `json:"description,omitempty"`
+	IsLinkTrackingEnabled bool                `json:"isLinkTrackingEnabled"`
+	Lists                 []ContactListSchema `json:"lists"`
+	Name                  string              `json:"name"`
+	PhoneNumberInUse      *string             `json:"phoneNumberInUse,omitempty"`
+	ScheduledAt           *time.Time          `json:"scheduledAt,omitempty"`
+
+	// Segments the segments the campaign is sent to along with its lists
+	Segments                    *[]SegmentSchema        `json:"segments,omitempty"`
 	SentAt                      *time.Time              `json:"sentAt,omitempty"`
 	Status                      CampaignStatusEnum      `json:"status"`
 	Tags                        []TagSchema             `json:"tags"`
@@ -385,6 +661,198 @@ type CampaignSchema struct {
 // CampaignStatusEnum defines model for CampaignStatusEnum.
 type CampaignStatusEnum string
 
+// CannedResponseAttachmentSchema defines model for CannedResponseAttachmentSchema.
+type CannedResponseAttachmentSchema struct {
+	Caption  *string `json:"caption,omitempty"`
+	FileName *string `json:"fileName,omitempty"`
+
+	// Link public url of the media
+	Link *string `json:"link,omitempty"`
+
+	// MediaId id of media uploaded to whatsapp, either this or the link is required
+	MediaId   *string         `json:"mediaId,omitempty"`
+	MediaType MessageTypeEnum `json:"mediaType"`
+}
+
+// CannedResponseSchema defines model for CannedResponseSchema.
+type CannedResponseSchema struct {
+	Attachments []CannedResponseAttachmentSchema `json:"attachments"`
+
+	// Content text of the response, variables like {{contact.name}} are resolved when it is rendered for a conversation
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+	Folder    *string   `json:"folder,omitempty"`
+
+	// IsPersonal personal responses are only visible to the member who created them
+	IsPersonal bool        `json:"isPersonal"`
+	Name       string      `json:"name"`
+	Shortcode  string      `json:"shortcode"`
+	Tags       []TagSchema `json:"tags"`
+	UniqueId   string      `json:"uniqueId"`
+}
+
+// ContactAttributeChangeSchema an attribute of the contact which changed, the old value is missing when the attribute was added and the new one when it was removed
+type ContactAttributeChangeSchema struct {
+	Key      string       `json:"key"`
+	NewValue *interface{} `json:"newValue,omitempty"`
+	OldValue *interface{} `json:"oldValue,omitempty"`
+}
+
+// ContactCaptureSourceSchema defines model for ContactCaptureSourceSchema.
+type ContactCaptureSourceSchema struct {
+	// Attributes added to the attributes of the captured contacts, the values the contacts already have are kept
+	Attributes map[string]interface{} `json:"attributes"`
+
+	// Code identifies the form in its url, and the link in the message it prefills
+	Code string `json:"code"`
+
+	// ConsentText forms only, the statement the contact agrees to by submitting the form
+	ConsentText *string   `json:"consentText,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	IsActive    bool      `json:"isActive"`
+	ListId      string    `json:"listId"`
+	Name        string    `json:"name"`
+
+	// PhoneNumber the business phone number the link opens a chat with, or the contacts confirm the subscription of the form with
+	PhoneNumber *string `json:"phoneNumber,omitempty"`
+
+	// PrefilledMessage links only, the message prefilled for the contact before the code
+	PrefilledMessage *string                      `json:"prefilledMessage,omitempty"`
+	Type             ContactCaptureSourceTypeEnum `json:"type"`
+	UniqueId         string                       `json:"uniqueId"`
+	UpdatedAt        time.Time                    `json:"updatedAt"`
+
+	// Url the url of the hosted form, or the wa.me link
+	Url string `json:"url"`
+
+	// WelcomeCannedResponseId links only, the canned response sent to the contacts joining the list through the link
+	WelcomeCannedResponseId *string `json:"welcomeCannedResponseId,omitempty"`
+}
+
+// ContactCaptureSourceTypeEnum defines model for ContactCaptureSourceTypeEnum.
+type ContactCaptureSourceTypeEnum string
+
+// ContactConsentCategoryEnum defines model for ContactConsentCategoryEnum.
+type ContactConsentCategoryEnum string
+
+// ContactConsentSchema defines model for ContactConsentSchema.
+type ContactConsentSchema struct {
+	// CaptureSourceId the opt-in form the consent was given through
+	CaptureSourceId *string                    `json:"captureSourceId,omitempty"`
+	Category        ContactConsentCategoryEnum `json:"category"`
+
+	// ChangedAt when the consent was given or withdrawn
+	ChangedAt time.Time `json:"changedAt"`
+
+	// Evidence the proof of the consent, like the statement the contact agreed to or the message it opted out with
+	Evidence map[string]interface{} `json:"evidence"`
+
+	// RecordedByMemberId the member who recorded the consent, missing when the contact gave or withdrew it itself
+	RecordedByMemberId *string `json:"recordedByMemberId,omitempty"`
+
+	// Source a Legacy consent was granted by the owner of the organization for a contact created before consents were recorded, its evidence is the statement of the owner and not the proof of the contact
+	Source ContactConsentSourceEnum `json:"source"`
+
+	// Status a Pending consent was given through an opt-in form and waits for the contact to confirm it on WhatsApp, campaigns are only sent to Granted consents. Consents can only be Granted or Revoked through the API
+	Status    ContactConsentStatusEnum `json:"status"`
+	UpdatedAt time.Time                `json:"updatedAt"`
+}
+
+// ContactConsentSourceEnum a Legacy consent was granted by the owner of the organization for a contact created before consents were recorded, its evidence is the statement of the owner and not the proof of the contact
+type ContactConsentSourceEnum string
+
+// ContactConsentStatusEnum a Pending consent was given through an opt-in form and waits for the contact to confirm it on WhatsApp, campaigns are only sent to Granted consents. Consents can only be Granted or Revoked through the API
+type ContactConsentStatusEnum string
+
+// ContactDataConversationSchema defines model for ContactDataConversationSchema.
+type ContactDataConversationSchema struct {
+	CreatedAt time.Time              `json:"createdAt"`
+	Status    ConversationStatusEnum `json:"status"`
+	UniqueId  string                 `json:"uniqueId"`
+}
+
+// ContactDataExportSchema defines model for ContactDataExportSchema.
+type ContactDataExportSchema struct {
+	Consents      []ContactConsentSchema          `json:"consents"`
+	Contact       ContactSchema                   `json:"contact"`
+	Conversations []ContactDataConversationSchema `json:"conversations"`
+	ExportedAt    time.Time                       `json:"exportedAt"`
+	LinkClicks    []ContactLinkClickSchema        `json:"linkClicks"`
+	Messages      []MessageSchema                 `json:"messages"`
+}
+
+// ContactDuplicateScanSchema defines model for ContactDuplicateScanSchema.
+type ContactDuplicateScanSchema struct {
+	// Merge merge each group of duplicates into its oldest contact, instead of only reporting them
+	Merge bool `json:"merge"`
+}
+
+// ContactErasureModeEnum Anonymize replaces the personal data of the contact and keeps its conversations and statistics, Delete removes the contact and everything related to it
+type ContactErasureModeEnum string
+
+// ContactErasureSchema defines model for ContactErasureSchema.
+type ContactErasureSchema struct {
+	// Mode Anonymize replaces the personal data of the contact and keeps its conversations and statistics, Delete removes the contact and everything related to it
+	Mode ContactErasureModeEnum `json:"mode"`
+}
+
+// ContactExportFormatEnum defines model for ContactExportFormatEnum.
+type ContactExportFormatEnum string
+
+// ContactFieldSchema defines model for ContactFieldSchema.
+type ContactFieldSchema struct {
+	CreatedAt time.Time `json:"createdAt"`
+
+	// DefaultValue the value given to contacts written without the field, of the type of the field
+	DefaultValue *interface{} `json:"defaultValue,omitempty"`
+	IsRequired   bool         `json:"isRequired"`
+
+	// Key the key of the field in the attributes of the contacts, also usable as the template variable contact.<key>
+	Key   string `json:"key"`
+	Label string `json:"label"`
+
+	// Options the allowed values of Enum fields
+	Options   []string             `json:"options"`
+	Type      ContactFieldTypeEnum `json:"type"`
+	UniqueId  string               `json:"uniqueId"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+}
+
+// ContactFieldTypeEnum defines model for ContactFieldTypeEnum.
+type ContactFieldTypeEnum string
+
+// ContactImportConflictStrategyEnum what to do with rows whose phone number belongs to an existing contact
+type ContactImportConflictStrategyEnum string
+
+// ContactLinkClickSchema defines model for ContactLinkClickSchema.
+type ContactLinkClickSchema struct {
+	CampaignId string    `json:"campaignId"`
+	ClickedAt  time.Time `json:"clickedAt"`
+	Url        *string   `json:"url,omitempty"`
+}
+
+// ContactListOperationEnum defines model for ContactListOperationEnum.
+type ContactListOperationEnum string
+
+// ContactListOperationSchema defines model for ContactListOperationSchema.
+type ContactListOperationSchema struct {
+	// ContactIds the contacts moved by Move, every contact of the source list when left out
+	ContactIds *[]string `json:"contactIds,omitempty"`
+
+	// Groups the number of lists created by Split, from 2 to 20
+	Groups *int `json:"groups,omitempty"`
+
+	// Name the name of the list created by Union, Intersection, Difference and Copy, and the prefix of the names of the lists created by Split. Required by Union, Intersection and Difference, the name of the source list is used otherwise
+	Name      *string                  `json:"name,omitempty"`
+	Operation ContactListOperationEnum `json:"operation"`
+
+	// SourceListIds at least two lists for Union, Intersection and Difference, a single list for the other operations
+	SourceListIds []string `json:"sourceListIds"`
+
+	// TargetListId the list the contacts are moved to, required by Move
+	TargetListId *string `json:"targetListId,omitempty"`
+}
+
 // ContactListSchema defines model for ContactListSchema.
 type ContactListSchema struct {
 	CreatedAt             time.Time   `json:"createdAt"`
@@ -410,6 +878,54 @@ type ContactSchema struct {
 // ContactStatusEnum defines model for ContactStatusEnum.
 type ContactStatusEnum string
 
+// ContactTimelineEventSchema an event of the timeline of a contact, only the properties of its type are set
+type ContactTimelineEventSchema struct {
+	// ActorMemberId the member who made the change, missing when it was not made by a member
+	ActorMemberId      *string                         `json:"actorMemberId,omitempty"`
+	ActorName          *string                         `json:"actorName,omitempty"`
+	AssignedMemberId   *string                         `json:"assignedMemberId,omitempty"`
+	AssignedMemberName *string                         `json:"assignedMemberName,omitempty"`
+	AttributeChanges   *[]ContactAttributeChangeSchema `json:"attributeChanges,omitempty"`
+	CampaignId         *string                         `json:"campaignId,omitempty"`
+	CampaignName       *string                         `json:"campaignName,omitempty"`
+
+	// CaptureSourceId the opt-in form the contact submitted
+	CaptureSourceId   *string                     `json:"captureSourceId,omitempty"`
+	CaptureSourceName *string                     `json:"captureSourceName,omitempty"`
+	ConsentCategory   *ContactConsentCategoryEnum `json:"consentCategory,omitempty"`
+
+	// ConsentEvidence the proof of the consent given or withdrawn
+	ConsentEvidence *map[string]interface{} `json:"consentEvidence,omitempty"`
+
+	// ConsentSource a Legacy consent was granted by the owner of the organization for a contact created before consents were recorded, its evidence is the statement of the owner and not the proof of the contact
+	ConsentSource *ContactConsentSourceEnum `json:"consentSource,omitempty"`
+
+	// ConsentText the statement the contact agreed to when opting in
+	ConsentText            *string                            `json:"consentText,omitempty"`
+	ConversationEventType  *ConversationTimelineEventTypeEnum `json:"conversationEventType,omitempty"`
+	ConversationId         *string                            `json:"conversationId,omitempty"`
+	CreatedAt              time.Time                          `json:"createdAt"`
+	EventType              ContactTimelineEventTypeEnum       `json:"eventType"`
+	FromConversationStatus *ConversationStatusEnum            `json:"fromConversationStatus,omitempty"`
+	FromStatus             *ContactStatusEnum                 `json:"fromStatus,omitempty"`
+	ListId                 *string                            `json:"listId,omitempty"`
+
+	// ListName the name of the list when the contact joined or left it
+	ListName             *string                 `json:"listName,omitempty"`
+	MergedContactIds     *[]string               `json:"mergedContactIds,omitempty"`
+	MessageId            *string                 `json:"messageId,omitempty"`
+	MessageStatus        *MessageStatusEnum      `json:"messageStatus,omitempty"`
+	ToConversationStatus *ConversationStatusEnum `json:"toConversationStatus,omitempty"`
+	ToStatus             *ContactStatusEnum      `json:"toStatus,omitempty"`
+	UniqueId             string                  `json:"uniqueId"`
+
+	// Url the destination of the clicked link
+	Url *string `json:"url,omitempty"`
+}
+
+// ContactTimelineEventTypeEnum defines model for ContactTimelineEventTypeEnum.
+type ContactTimelineEventTypeEnum string
+
 // ConversationAnalyticsDataPointSchema defines model for ConversationAnalyticsDataPointSchema.
 type ConversationAnalyticsDataPointSchema struct {
 	Date                          time.Time `json:"date"`
@@ -421,6 +937,33 @@ type ConversationAnalyticsDataPointSchema struct {
 // ConversationInitiatedByEnum defines model for ConversationInitiatedByEnum.
 type ConversationInitiatedByEnum string
 
+// ConversationMessageItemSchema defines model for ConversationMessageItemSchema.
+type ConversationMessageItemSchema struct {
+	Message *MessageSchema                  `json:"message,omitempty"`
+	Note    *ConversationNoteSchema         `json:"note,omitempty"`
+	Type    ConversationMessageItemTypeEnum `json:"type"`
+}
+
+// ConversationMessageItemTypeEnum defines model for ConversationMessageItemTypeEnum.
+type ConversationMessageItemTypeEnum string
+
+// ConversationNoteSchema defines model for ConversationNoteSchema.
+type ConversationNoteSchema struct {
+	// AuthorMemberId not set once the author has been removed from the organization
+	AuthorMemberId *string `json:"authorMemberId,omitempty"`
+
+	// AuthorName not set once the author has been removed from the organization
+	AuthorName         *string   `json:"authorName,omitempty"`
+	Content            string    `json:"content"`
+	ConversationId     string    `json:"conversationId"`
+	CreatedAt          time.Time `json:"createdAt"`
+	MentionedMemberIds []string  `json:"mentionedMemberIds"`
+	UniqueId           string    `json:"uniqueId"`
+}
+
+// ConversationRoutingStrategyEnum defines model for ConversationRoutingStrategyEnum.
+type ConversationRoutingStrategyEnum string
+
 // ConversationSchema defines model for ConversationSchema.
 type ConversationSchema struct {
 	AssignedTo             *OrganizationMemberSchema   `json:"assignedTo,omitempty"`
@@ -432,19 +975,85 @@ type ConversationSchema struct {
 	Messages               []MessageSchema             `json:"messages"`
 	NumberOfUnreadMessages int                         `json:"numberOfUnreadMessages"`
 	OrganizationId         string                      `json:"organizationId"`
-	Status                 ConversationStatusEnum      `json:"status"`
-	Tags                   []TagSchema                 `json:"tags"`
-	UniqueId               string                      `json:"uniqueId"`
+
+	// SearchMatches the messages of the conversation matching the search query, the best match first. Only returned when conversations are searched
+	SearchMatches *[]ConversationSearchMatchSchema `json:"searchMatches,omitempty"`
+	Sla           *ConversationSlaSchema           `json:"sla,omitempty"`
+	SnoozedUntil  *time.Time                       `json:"snoozedUntil,omitempty"`
+	Status        ConversationStatusEnum           `json:"status"`
+	Tags          []TagSchema                      `json:"tags"`
+	UniqueId      string                           `json:"uniqueId"`
+}
+
+// ConversationSearchMatchSchema defines model for ConversationSearchMatchSchema.
+type ConversationSearchMatchSchema struct {
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Highlight the matched text with the matched words wrapped in <mark> tags, as safe html. The text itself is html escaped, so it can be rendered as html as it is
+	Highlight string  `json:"highlight"`
+	MessageId string  `json:"messageId"`
+	Rank      float32 `json:"rank"`
+}
+
+// ConversationSlaSchema defines model for ConversationSlaSchema.
+type ConversationSlaSchema struct {
+	FirstRespondedAt    *time.Time     `json:"firstRespondedAt,omitempty"`
+	FirstResponseDueAt  *time.Time     `json:"firstResponseDueAt,omitempty"`
+	FirstResponseStatus SlaStatusEnum  `json:"firstResponseStatus"`
+	PolicyId            string         `json:"policyId"`
+	ResolutionDueAt     *time.Time     `json:"resolutionDueAt,omitempty"`
+	ResolutionStatus    *SlaStatusEnum `json:"resolutionStatus,omitempty"`
+	ResolvedAt          *time.Time     `json:"resolvedAt,omitempty"`
 }
 
 // ConversationStatusEnum defines model for ConversationStatusEnum.
 type ConversationStatusEnum string
 
+// ConversationTimelineEventSchema defines model for ConversationTimelineEventSchema.
+type ConversationTimelineEventSchema struct {
+	ActorMemberId  *string                              `json:"actorMemberId,omitempty"`
+	ActorName      *string                              `json:"actorName,omitempty"`
+	ConversationId string                               `json:"conversationId"`
+	CreatedAt      time.Time                            `json:"createdAt"`
+	EventType      ConversationTimelineEventTypeEnum    `json:"eventType"`
+	FromStatus     ConversationStatusEnum               `json:"fromStatus"`
+	SnoozedUntil   *time.Time                           `json:"snoozedUntil,omitempty"`
+	ToStatus       ConversationStatusEnum               `json:"toStatus"`
+	Trigger        ConversationTimelineEventTriggerEnum `json:"trigger"`
+	UniqueId       string                               `json:"uniqueId"`
+}
+
+// ConversationTimelineEventTriggerEnum defines model for ConversationTimelineEventTriggerEnum.
+type ConversationTimelineEventTriggerEnum string
+
+// ConversationTimelineEventTypeEnum defines model for ConversationTimelineEventTypeEnum.
+type ConversationTimelineEventTypeEnum string
+
 // CreateAiChatMessageVoteResponseSchema defines model for CreateAiChatMessageVoteResponseSchema.
 type CreateAiChatMessageVoteResponseSchema struct {
 	Vote AiChatMessageVoteSchema `json:"vote"`
 }
 
+// CreateCannedResponseResponseSchema defines model for CreateCannedResponseResponseSchema.
+type CreateCannedResponseResponseSchema struct {
+	CannedResponse CannedResponseSchema `json:"cannedResponse"`
+}
+
+// CreateContactCaptureSourceResponseSchema defines model for CreateContactCaptureSourceResponseSchema.
+type CreateContactCaptureSourceResponseSchema struct {
+	Source ContactCaptureSourceSchema `json:"source"`
+}
+
+// CreateContactFieldResponseSchema defines model for CreateContactFieldResponseSchema.
+type CreateContactFieldResponseSchema struct {
+	Field ContactFieldSchema `json:"field"`
+}
+
+// CreateConversationNoteResponseSchema defines model for CreateConversationNoteResponseSchema.
+type CreateConversationNoteResponseSchema struct {
+	Note ConversationNoteSchema `json:"note"`
+}
+
 // CreateInviteResponseSchema defines model for CreateInviteResponseSchema.
 type CreateInviteResponseSchema struct {
 	Invite OrganizationMemberInviteSchema `json:"invite"`
@@ -486,16 +1095,100 @@ type CreateNewRoleResponseSchema struct {
 	Role OrganizationRoleSchema `json:"role"`
 }
 
+// CreateRoutingRuleResponseSchema defines model for CreateRoutingRuleResponseSchema.
+type CreateRoutingRuleResponseSchema struct {
+	Rule RoutingRuleSchema `json:"rule"`
+}
+
+// CreateSegmentResponseSchema defines model for CreateSegmentResponseSchema.
+type CreateSegmentResponseSchema struct {
+	Segment SegmentSchema `json:"segment"`
+}
+
+// CreateSlaPolicyResponseSchema defines model for CreateSlaPolicyResponseSchema.
+type CreateSlaPolicyResponseSchema struct {
+	Policy SlaPolicySchema `json:"policy"`
+}
+
+// CsatAnalyticsResponseSchema defines model for CsatAnalyticsResponseSchema.
+type CsatAnalyticsResponseSchema struct {
+	ByMember      []CsatMemberStatsSchema      `json:"byMember"`
+	ByPeriod      []CsatPeriodStatsSchema      `json:"byPeriod"`
+	ByPhoneNumber []CsatPhoneNumberStatsSchema `json:"byPhoneNumber"`
+	Overall       CsatStatsSchema              `json:"overall"`
+}
+
+// CsatConfigurationSchema defines model for CsatConfigurationSchema.
+type CsatConfigurationSchema struct {
+	IsEnabled bool `json:"isEnabled"`
+
+	// Message the question sent to the contact with the ratings, a default question is used when not set
+	Message *string `json:"message,omitempty"`
+}
+
+// CsatMemberStatsSchema defines model for CsatMemberStatsSchema.
+type CsatMemberStatsSchema struct {
+	// MemberId not set for conversations which were not assigned when resolved
+	MemberId   *string         `json:"memberId,omitempty"`
+	MemberName *string         `json:"memberName,omitempty"`
+	Stats      CsatStatsSchema `json:"stats"`
+}
+
+// CsatPeriodStatsSchema defines model for CsatPeriodStatsSchema.
+type CsatPeriodStatsSchema struct {
+	PeriodStart time.Time       `json:"periodStart"`
+	Stats       CsatStatsSchema `json:"stats"`
+}
+
+// CsatPhoneNumberStatsSchema defines model for CsatPhoneNumberStatsSchema.
+type CsatPhoneNumberStatsSchema struct {
+	PhoneNumberId string          `json:"phoneNumberId"`
+	Stats         CsatStatsSchema `json:"stats"`
+}
+
+// CsatStatsSchema defines model for CsatStatsSchema.
+type CsatStatsSchema struct {
+	AverageRating float32 `json:"averageRating"`
+
+	// CsatScore percentage of the responses which are satisfied
+	CsatScore float32 `json:"csatScore"`
+	Responses int     `json:"responses"`
+
+	// SatisfiedResponses responses rated 4 or 5
+	SatisfiedResponses int `json:"satisfiedResponses"`
+	SurveysSent        int `json:"surveysSent"`
+}
+
+// DeleteCannedResponseByIdResponseSchema defines model for DeleteCannedResponseByIdResponseSchema.
+type DeleteCannedResponseByIdResponseSchema struct {
+	Data bool `json:"data"`
+}
+
 // DeleteContactByIdResponseSchema defines model for DeleteContactByIdResponseSchema.
 type DeleteContactByIdResponseSchema struct {
 	Data bool `json:"data"`
 }
 
+// DeleteContactCaptureSourceByIdResponseSchema defines model for DeleteContactCaptureSourceByIdResponseSchema.
+type DeleteContactCaptureSourceByIdResponseSchema struct {
+	Data bool `json:"data"`
+}
+
+// DeleteContactFieldByIdResponseSchema defines model for DeleteContactFieldByIdResponseSchema.
+type DeleteContactFieldByIdResponseSchema struct {
+	Data bool `json:"data"`
+}
+
 // DeleteConversationByIdResponseSchema defines model for DeleteConversationByIdResponseSchema.
 type DeleteConversationByIdResponseSchema struct {
 	Data bool `json:"data"`
 }
 
+// DeleteConversationNoteByIdResponseSchema defines model for DeleteConversationNoteByIdResponseSchema.
+type DeleteConversationNoteByIdResponseSchema struct {
+	Data bool `json:"data"`
+}
+
 // DeleteOrganizationMemberByIdResponseSchema defines model for DeleteOrganizationMemberByIdResponseSchema.
 type DeleteOrganizationMemberByIdResponseSchema struct {
 	Data bool `json:"data"`
@@ -506,6 +1199,26 @@ type DeleteRoleByIdResponseSchema struct {
 	Data bool `json:"data"`
 }
 
+// DeleteRoutingRuleByIdResponseSchema defines model for DeleteRoutingRuleByIdResponseSchema.
+type DeleteRoutingRuleByIdResponseSchema struct {
+	Data bool `json:"data"`
+}
+
+// DeleteSegmentByIdResponseSchema defines model for DeleteSegmentByIdResponseSchema.
+type DeleteSegmentByIdResponseSchema struct {
+	Data bool `json:"data"`
+}
+
+// DeleteSlaPolicyByIdResponseSchema defines model for DeleteSlaPolicyByIdResponseSchema.
+type DeleteSlaPolicyByIdResponseSchema struct {
+	Data bool `json:"data"`
+}
+
+// DisableTwoFactorResponseSchema defines model for DisableTwoFactorResponseSchema.
+type DisableTwoFactorResponseSchema struct {
+	IsDisabled bool `json:"isDisabled"`
+}
+
 // EmailNotificationConfigurationSchema defines model for EmailNotificationConfigurationSchema.
 type EmailNotificationConfigurationSchema struct {
 	SmtpHost     string `json:"smtpHost"`
@@ -514,11 +1227,26 @@ type EmailNotificationConfigurationSchema struct {
 	SmtpUsername string `json:"smtpUsername"`
 }
 
+// EraseContactByIdResponseSchema defines model for EraseContactByIdResponseSchema.
+type EraseContactByIdResponseSchema struct {
+	Data bool `json:"data"`
+}
+
 // FeatureFlags defines model for FeatureFlags.
 type FeatureFlags struct {
 	SystemFeatureFlags SystemFeatureFlags `json:"SystemFeatureFlags"`
 }
 
+// ForgotPasswordRequestBodySchema defines model for ForgotPasswordRequestBodySchema.
+type ForgotPasswordRequestBodySchema struct {
+	Email string `json:"email"`
+}
+
+// ForgotPasswordResponseBodySchema defines model for ForgotPasswordResponseBodySchema.
+type ForgotPasswordResponseBodySchema struct {
+	IsResetLinkSent bool `json:"isResetLinkSent"`
+}
+
 // FullAiConfiguration defines model for FullAiConfiguration.
 type FullAiConfiguration struct {
 	ApiKey    string      `json:"apiKey"`
@@ -565,6 +1293,17 @@ type GetApiKeysResponseSchema struct {
 	ApiKey ApiKeySchema `json:"apiKey"`
 }
 
+// GetBackgroundJobByIdResponseSchema defines model for GetBackgroundJobByIdResponseSchema.
+type GetBackgroundJobByIdResponseSchema struct {
+	Job BackgroundJobSchema `json:"job"`
+}
+
+// GetBackgroundJobsResponseSchema defines model for GetBackgroundJobsResponseSchema.
+type GetBackgroundJobsResponseSchema struct {
+	Jobs           []BackgroundJobSchema `json:"jobs"`
+	PaginationMeta PaginationMeta        `json:"paginationMeta"`
+}
+
 // GetCampaignByIdResponseSchema defines model for GetCampaignByIdResponseSchema.
 type GetCampaignByIdResponseSchema struct {
 	Campaign CampaignSchema `json:"campaign"`
@@ -576,11 +1315,37 @@ type GetCampaignResponseSchema struct {
 	PaginationMeta PaginationMeta   `json:"paginationMeta"`
 }
 
+// GetCannedResponseByIdResponseSchema defines model for GetCannedResponseByIdResponseSchema.
+type GetCannedResponseByIdResponseSchema struct {
+	CannedResponse CannedResponseSchema `json:"cannedResponse"`
+}
+
+// GetCannedResponsesResponseSchema defines model for GetCannedResponsesResponseSchema.
+type GetCannedResponsesResponseSchema struct {
+	CannedResponses []CannedResponseSchema `json:"cannedResponses"`
+	Folders         []string               `json:"folders"`
+}
+
 // GetContactByIdResponseSchema defines model for GetContactByIdResponseSchema.
 type GetContactByIdResponseSchema struct {
 	Contact ContactSchema `json:"contact"`
 }
 
+// GetContactCaptureSourcesResponseSchema defines model for GetContactCaptureSourcesResponseSchema.
+type GetContactCaptureSourcesResponseSchema struct {
+	Sources []ContactCaptureSourceSchema `json:"sources"`
+}
+
+// GetContactConsentsResponseSchema defines model for GetContactConsentsResponseSchema.
+type GetContactConsentsResponseSchema struct {
+	Consents []ContactConsentSchema `json:"consents"`
+}
+
+// GetContactFieldsResponseSchema defines model for GetContactFieldsResponseSchema.
+type GetContactFieldsResponseSchema struct {
+	Fields []ContactFieldSchema `json:"fields"`
+}
+
 // GetContactListByIdSchema defines model for GetContactListByIdSchema.
 type GetContactListByIdSchema struct {
 	List ContactListSchema `json:"list"`
@@ -592,6 +1357,12 @@ type GetContactListResponseSchema struct {
 	PaginationMeta PaginationMeta      `json:"paginationMeta"`
 }
 
+// GetContactTimelineResponseSchema defines model for GetContactTimelineResponseSchema.
+type GetContactTimelineResponseSchema struct {
+	Events         []ContactTimelineEventSchema `json:"events"`
+	PaginationMeta PaginationMeta               `json:"paginationMeta"`
+}
+
 // GetContactsResponseSchema defines model for GetContactsResponseSchema.
 type GetContactsResponseSchema struct {
 	Contacts       []ContactSchema `json:"contacts"`
@@ -605,8 +1376,15 @@ type GetConversationByIdResponseSchema struct {
 
 // GetConversationMessagesResponseSchema defines model for GetConversationMessagesResponseSchema.
 type GetConversationMessagesResponseSchema struct {
-	Messages       []MessageSchema `json:"messages"`
-	PaginationMeta PaginationMeta  `json:"paginationMeta"`
+	// Items messages and notes of the page in the order they were created
+	Items          []ConversationMessageItemSchema `json:"items"`
+	Messages       []MessageSchema                 `json:"messages"`
+	PaginationMeta PaginationMeta                  `json:"paginationMeta"`
+}
+
+// GetConversationTimelineResponseSchema defines model for GetConversationTimelineResponseSchema.
+type GetConversationTimelineResponseSchema struct {
+	Events []ConversationTimelineEventSchema `json:"events"`
 }
 
 // GetConversationsResponseSchema defines model for GetConversationsResponseSchema.
@@ -633,6 +1411,21 @@ type GetMetaDataResponseSchema struct {
 	MetaTitle       *string `json:"metaTitle,omitempty"`
 }
 
+// GetOAuthAuthorizationUrlResponseSchema defines model for GetOAuthAuthorizationUrlResponseSchema.
+type GetOAuthAuthorizationUrlResponseSchema struct {
+	AuthorizationUrl string `json:"authorizationUrl"`
+}
+
+// GetOAuthProvidersResponseSchema defines model for GetOAuthProvidersResponseSchema.
+type GetOAuthProvidersResponseSchema struct {
+	Providers []OAuthProviderSchema `json:"providers"`
+}
+
+// GetOptInFormResponseSchema defines model for GetOptInFormResponseSchema.
+type GetOptInFormResponseSchema struct {
+	Form OptInFormSchema `json:"form"`
+}
+
 // GetOrganizationByIdResponseSchema defines model for GetOrganizationByIdResponseSchema.
 type GetOrganizationByIdResponseSchema struct {
 	Organization OrganizationSchema `json:"organization"`
@@ -655,6 +1448,11 @@ type GetOrganizationMembersResponseSchema struct {
 	PaginationMeta PaginationMeta             `json:"paginationMeta"`
 }
 
+// GetOrganizationPresenceResponseSchema defines model for GetOrganizationPresenceResponseSchema.
+type GetOrganizationPresenceResponseSchema struct {
+	Members []MemberPresenceSchema `json:"members"`
+}
+
 // GetOrganizationRolesResponseSchema defines model for GetOrganizationRolesResponseSchema.
 type GetOrganizationRolesResponseSchema struct {
 	PaginationMeta PaginationMeta           `json:"paginationMeta"`
@@ -681,6 +1479,37 @@ type GetRoleByIdResponseSchema struct {
 	Role OrganizationRoleSchema `json:"role"`
 }
 
+// GetRoutingRuleByIdResponseSchema defines model for GetRoutingRuleByIdResponseSchema.
+type GetRoutingRuleByIdResponseSchema struct {
+	Rule RoutingRuleSchema `json:"rule"`
+}
+
+// GetRoutingRulesResponseSchema defines model for GetRoutingRulesResponseSchema.
+type GetRoutingRulesResponseSchema struct {
+	Rules []RoutingRuleSchema `json:"rules"`
+}
+
+// GetSegmentByIdResponseSchema defines model for GetSegmentByIdResponseSchema.
+type GetSegmentByIdResponseSchema struct {
+	Segment SegmentSchema `json:"segment"`
+}
+
+// GetSegmentsResponseSchema defines model for GetSegmentsResponseSchema.
+type GetSegmentsResponseSchema struct {
+	PaginationMeta PaginationMeta  `json:"paginationMeta"`
+	Segments       []SegmentSchema `json:"segments"`
+}
+
+// GetSlaPoliciesResponseSchema defines model for GetSlaPoliciesResponseSchema.
+type GetSlaPoliciesResponseSchema struct {
+	Policies []SlaPolicySchema `json:"policies"`
+}
+
+// GetSlaPolicyByIdResponseSchema defines model for GetSlaPolicyByIdResponseSchema.
+type GetSlaPolicyByIdResponseSchema struct {
+	Policy SlaPolicySchema `json:"policy"`
+}
+
 // GetTemplateByIdResponseSchema defines model for GetTemplateByIdResponseSchema.
 type GetTemplateByIdResponseSchema struct {
 	Template TemplateSchema `json:"template"`
@@ -698,6 +1527,29 @@ type GetUserResponseSchema struct {
 	User UserSchema `json:"user"`
 }
 
+// GetUserSessionsResponseSchema defines model for GetUserSessionsResponseSchema.
+type GetUserSessionsResponseSchema struct {
+	Sessions []UserSessionSchema `json:"sessions"`
+}
+
+// GlobalSearchResponseSchema defines model for GlobalSearchResponseSchema.
+type GlobalSearchResponseSchema struct {
+	PaginationMeta PaginationMeta       `json:"paginationMeta"`
+	Results        []SearchResultSchema `json:"results"`
+}
+
+// GrantLegacyConsentsResponseSchema defines model for GrantLegacyConsentsResponseSchema.
+type GrantLegacyConsentsResponseSchema struct {
+	// GrantedCount the number of contacts whose marketing consent has been granted
+	GrantedCount int `json:"grantedCount"`
+}
+
+// GrantLegacyConsentsSchema defines model for GrantLegacyConsentsSchema.
+type GrantLegacyConsentsSchema struct {
+	// Statement how the contacts opted in to the marketing messages of the organization before consents were recorded, it is the evidence of every consent granted
+	Statement string `json:"statement"`
+}
+
 // IntegrationSchema defines model for IntegrationSchema.
 type IntegrationSchema struct {
 	CreatedAt   time.Time             `json:"createdAt"`
@@ -714,6 +1566,9 @@ type IntegrationSchema struct {
 // IntegrationStatusEnum defines model for IntegrationStatusEnum.
 type IntegrationStatusEnum string
 
+// InteractiveMessageTypeEnum defines model for InteractiveMessageTypeEnum.
+type InteractiveMessageTypeEnum string
+
 // InviteStatusEnum defines model for InviteStatusEnum.
 type InviteStatusEnum string
 
@@ -742,8 +1597,42 @@ type LoginRequestBodySchema struct {
 
 // LoginResponseBodySchema defines model for LoginResponseBodySchema.
 type LoginResponseBodySchema struct {
-	IsOnboardingCompleted bool   `json:"isOnboardingCompleted"`
-	Token                 string `json:"token"`
+	IsOnboardingCompleted bool `json:"isOnboardingCompleted"`
+
+	// IsTwoFactorRequired when true, the tokens are empty and the login must be completed with the two factor token
+	IsTwoFactorRequired *bool   `json:"isTwoFactorRequired,omitempty"`
+	RefreshToken        string  `json:"refreshToken"`
+	Token               string  `json:"token"`
+	TwoFactorToken      *string `json:"twoFactorToken,omitempty"`
+}
+
+// LogoutAllSessionsResponseSchema defines model for LogoutAllSessionsResponseSchema.
+type LogoutAllSessionsResponseSchema struct {
+	RevokedSessions int `json:"revokedSessions"`
+}
+
+// LogoutResponseSchema defines model for LogoutResponseSchema.
+type LogoutResponseSchema struct {
+	IsLoggedOut bool `json:"isLoggedOut"`
+}
+
+// MemberPresenceSchema defines model for MemberPresenceSchema.
+type MemberPresenceSchema struct {
+	LastSeenAt *time.Time         `json:"lastSeenAt,omitempty"`
+	MemberId   string             `json:"memberId"`
+	Status     PresenceStatusEnum `json:"status"`
+	UserId     string             `json:"userId"`
+}
+
+// MergeContactsResponseSchema defines model for MergeContactsResponseSchema.
+type MergeContactsResponseSchema struct {
+	Contact ContactSchema `json:"contact"`
+}
+
+// MergeContactsSchema defines model for MergeContactsSchema.
+type MergeContactsSchema struct {
+	// DuplicateIds the ids of the contacts to merge into the contact
+	DuplicateIds []string `json:"duplicateIds"`
 }
 
 // MessageAnalyticGraphDataPointSchema defines model for MessageAnalyticGraphDataPointSchema.
@@ -818,8 +1707,63 @@ type NewCampaignSchema struct {
 	ListIds               []string `json:"listIds"`
 	Name                  string   `json:"name"`
 	PhoneNumberToUse      string   `json:"phoneNumberToUse"`
-	Tags                  []string `json:"tags"`
-	TemplateMessageId     string   `json:"templateMessageId"`
+
+	// SegmentIds the segments to send the campaign to, in addition to the contacts of the lists
+	SegmentIds        *[]string `json:"segmentIds,omitempty"`
+	Tags              []string  `json:"tags"`
+	TemplateMessageId string    `json:"templateMessageId"`
+}
+
+// NewCannedResponseSchema defines model for NewCannedResponseSchema.
+type NewCannedResponseSchema struct {
+	Attachments *[]CannedResponseAttachmentSchema `json:"attachments,omitempty"`
+	Content     string                            `json:"content"`
+	Folder      *string                           `json:"folder,omitempty"`
+	IsPersonal  *bool                             `json:"isPersonal,omitempty"`
+	Name        string                            `json:"name"`
+	Shortcode   string                            `json:"shortcode"`
+	TagIds      *[]string                         `json:"tagIds,omitempty"`
+}
+
+// NewContactCaptureSourceSchema defines model for NewContactCaptureSourceSchema.
+type NewContactCaptureSourceSchema struct {
+	Attributes *map[string]interface{} `json:"attributes,omitempty"`
+
+	// ConsentText required for forms
+	ConsentText *string `json:"consentText,omitempty"`
+	ListId      string  `json:"listId"`
+	Name        string  `json:"name"`
+
+	// PhoneNumber required, the business phone number with its country code
+	PhoneNumber             *string                      `json:"phoneNumber,omitempty"`
+	PrefilledMessage        *string                      `json:"prefilledMessage,omitempty"`
+	Type                    ContactCaptureSourceTypeEnum `json:"type"`
+	WelcomeCannedResponseId *string                      `json:"welcomeCannedResponseId,omitempty"`
+}
+
+// NewContactConsentSchema defines model for NewContactConsentSchema.
+type NewContactConsentSchema struct {
+	Category ContactConsentCategoryEnum `json:"category"`
+
+	// ChangedAt when the consent was given or withdrawn, now by default
+	ChangedAt *time.Time `json:"changedAt,omitempty"`
+
+	// Evidence the proof of the consent, required to grant it. For example the text the contact agreed to and where
+	Evidence *map[string]interface{} `json:"evidence,omitempty"`
+
+	// Status a Pending consent was given through an opt-in form and waits for the contact to confirm it on WhatsApp, campaigns are only sent to Granted consents. Consents can only be Granted or Revoked through the API
+	Status ContactConsentStatusEnum `json:"status"`
+}
+
+// NewContactFieldSchema defines model for NewContactFieldSchema.
+type NewContactFieldSchema struct {
+	// DefaultValue the value given to contacts written without the field, of the type of the field
+	DefaultValue *interface{}         `json:"defaultValue,omitempty"`
+	IsRequired   bool                 `json:"isRequired"`
+	Key          string               `json:"key"`
+	Label        string               `json:"label"`
+	Options      *[]string            `json:"options,omitempty"`
+	Type         ContactFieldTypeEnum `json:"type"`
 }
 
 // NewContactListSchema defines model for NewContactListSchema.
@@ -833,24 +1777,53 @@ type NewContactListSchema struct {
 // NewContactSchema defines model for NewContactSchema.
 type NewContactSchema struct {
 	Attributes map[string]interface{} `json:"attributes"`
-	ListsIds   []string               `json:"listsIds"`
-	Name       string                 `json:"name"`
-	Phone      string                 `json:"phone"`
-	Status     ContactStatusEnum      `json:"status"`
+
+	// Consents the consents the contact has given, campaigns are only sent to the contacts which consented to marketing messages
+	Consents *[]NewContactConsentSchema `json:"consents,omitempty"`
+	ListsIds []string                   `json:"listsIds"`
+	Name     string                     `json:"name"`
+	Phone    string                     `json:"phone"`
+	Status   ContactStatusEnum          `json:"status"`
+}
+
+// NewConversationNoteSchema defines model for NewConversationNoteSchema.
+type NewConversationNoteSchema struct {
+	Content string `json:"content"`
+
+	// MentionedMemberIds members mentioned with @ in the content, they are notified of the note
+	MentionedMemberIds *[]string `json:"mentionedMemberIds,omitempty"`
 }
 
-// NewMessageSchema defines model for NewMessageSchema.
+// NewMessageSchema only the property of the message type is used, Image, Video, Audio, Document and Sticker messages use media
 type NewMessageSchema struct {
-	CreatedAt   *time.Time              `json:"createdAt,omitempty"`
-	MessageData *map[string]interface{} `json:"messageData,omitempty"`
-	MessageType *MessageTypeEnum        `json:"messageType,omitempty"`
+	Contacts    *[]OutboundContactCardSchema      `json:"contacts,omitempty"`
+	CreatedAt   *time.Time                        `json:"createdAt,omitempty"`
+	Interactive *OutboundInteractiveMessageSchema `json:"interactive,omitempty"`
+	Location    *OutboundLocationMessageSchema    `json:"location,omitempty"`
+
+	// Media media sent in image, video, audio, document and sticker messages. exactly one of id and link must be set
+	Media *OutboundMediaMessageSchema `json:"media,omitempty"`
+
+	// MessageData text messages sent as { text } before the typed properties existed, use text instead
+	// Deprecated:
+	MessageData *map[string]interface{}        `json:"messageData,omitempty"`
+	MessageType MessageTypeEnum                `json:"messageType"`
+	Reaction    *OutboundReactionMessageSchema `json:"reaction,omitempty"`
+
+	// ReplyToMessageId id of the message of the conversation this message replies to
+	ReplyToMessageId *string                        `json:"replyToMessageId,omitempty"`
+	Template         *OutboundTemplateMessageSchema `json:"template,omitempty"`
+	Text             *OutboundTextMessageSchema     `json:"text,omitempty"`
 }
 
 // NewOrganizationRoleSchema defines model for NewOrganizationRoleSchema.
 type NewOrganizationRoleSchema struct {
-	Description *string              `json:"description,omitempty"`
-	Name        string               `json:"name"`
-	Permissions []RolePermissionEnum `json:"permissions"`
+	Description *string `json:"description,omitempty"`
+
+	// IsTwoFactorRequired members with this role must use two factor authentication to access sensitive routes
+	IsTwoFactorRequired *bool                `json:"isTwoFactorRequired,omitempty"`
+	Name                string               `json:"name"`
+	Permissions         []RolePermissionEnum `json:"permissions"`
 }
 
 // NewOrganizationSchema defines model for NewOrganizationSchema.
@@ -864,6 +1837,40 @@ type NewOrganizationTagSchema struct {
 	Name string `json:"name"`
 }
 
+// NewRoutingRuleSchema defines model for NewRoutingRuleSchema.
+type NewRoutingRuleSchema struct {
+	IsEnabled                  *bool                           `json:"isEnabled,omitempty"`
+	MaxConcurrentConversations *int                            `json:"maxConcurrentConversations,omitempty"`
+	MemberIds                  *[]string                       `json:"memberIds,omitempty"`
+	Name                       string                          `json:"name"`
+	PhoneNumberId              *string                         `json:"phoneNumberId,omitempty"`
+	Priority                   *int                            `json:"priority,omitempty"`
+	Strategy                   ConversationRoutingStrategyEnum `json:"strategy"`
+	TagId                      *string                         `json:"tagId,omitempty"`
+}
+
+// NewSegmentSchema defines model for NewSegmentSchema.
+type NewSegmentSchema struct {
+	Description *string                `json:"description,omitempty"`
+	Name        string                 `json:"name"`
+	Rules       SegmentRuleGroupSchema `json:"rules"`
+}
+
+// NewSlaPolicySchema defines model for NewSlaPolicySchema.
+type NewSlaPolicySchema struct {
+	BusinessCalendar           *BusinessCalendarSchema  `json:"businessCalendar,omitempty"`
+	EscalationAction           *SlaEscalationActionEnum `json:"escalationAction,omitempty"`
+	EscalationMemberId         *string                  `json:"escalationMemberId,omitempty"`
+	EscalationTagId            *string                  `json:"escalationTagId,omitempty"`
+	FirstResponseTimeInMinutes int                      `json:"firstResponseTimeInMinutes"`
+	IsDefault                  *bool                    `json:"isDefault,omitempty"`
+	IsEnabled                  *bool                    `json:"isEnabled,omitempty"`
+	Name                       string                   `json:"name"`
+	PhoneNumberId              *string                  `json:"phoneNumberId,omitempty"`
+	ResolutionTimeInMinutes    *int                     `json:"resolutionTimeInMinutes,omitempty"`
+	Timezone                   *string                  `json:"timezone,omitempty"`
+}
+
 // NotFoundErrorResponseSchema defines model for NotFoundErrorResponseSchema.
 type NotFoundErrorResponseSchema struct {
 	Message string `json:"message"`
@@ -880,6 +1887,35 @@ type NotificationSchema struct {
 	UniqueId    string    `json:"uniqueId"`
 }
 
+// OAuthLoginRequestBodySchema defines model for OAuthLoginRequestBodySchema.
+type OAuthLoginRequestBodySchema struct {
+	Code     string `json:"code"`
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+}
+
+// OAuthProviderSchema defines model for OAuthProviderSchema.
+type OAuthProviderSchema struct {
+	DisplayName string `json:"displayName"`
+	Name        string `json:"name"`
+}
+
+// OptInFormSchema defines model for OptInFormSchema.
+type OptInFormSchema struct {
+	Code             string `json:"code"`
+	ConsentText      string `json:"consentText"`
+	Name             string `json:"name"`
+	OrganizationName string `json:"organizationName"`
+}
+
+// OptInFormSubmissionSchema defines model for OptInFormSubmissionSchema.
+type OptInFormSubmissionSchema struct {
+	// Consent the contact agrees to the consent text of the form, the form is rejected without it
+	Consent bool   `json:"consent"`
+	Name    string `json:"name"`
+	Phone   string `json:"phone"`
+}
+
 // OrderEnum defines model for OrderEnum.
 type OrderEnum string
 
@@ -894,9 +1930,12 @@ type OrganizationMemberInviteSchema struct {
 
 // OrganizationMemberSchema defines model for OrganizationMemberSchema.
 type OrganizationMemberSchema struct {
-	AccessLevel UserPermissionLevelEnum  `json:"accessLevel"`
-	CreatedAt   time.Time                `json:"createdAt"`
-	Email       string                   `json:"email"`
+	AccessLevel UserPermissionLevelEnum `json:"accessLevel"`
+	CreatedAt   time.Time               `json:"createdAt"`
+	Email       string                  `json:"email"`
+
+	// IsAvailable unavailable members are not assigned new conversations by the routing rules
+	IsAvailable *bool                    `json:"isAvailable,omitempty"`
 	Name        string                   `json:"name"`
 	Roles       []OrganizationRoleSchema `json:"roles"`
 	UniqueId    string                   `json:"uniqueId"`
@@ -904,26 +1943,122 @@ type OrganizationMemberSchema struct {
 
 // OrganizationRoleSchema defines model for OrganizationRoleSchema.
 type OrganizationRoleSchema struct {
-	Description *string              `json:"description,omitempty"`
-	Name        string               `json:"name"`
-	Permissions []RolePermissionEnum `json:"permissions"`
-	UniqueId    string               `json:"uniqueId"`
+	Description *string `json:"description,omitempty"`
+
+	// IsTwoFactorRequired members with this role must use two factor authentication to access sensitive routes
+	IsTwoFactorRequired *bool                `json:"isTwoFactorRequired,omitempty"`
+	Name                string               `json:"name"`
+	Permissions         []RolePermissionEnum `json:"permissions"`
+	UniqueId            string               `json:"uniqueId"`
 }
 
 // OrganizationSchema defines model for OrganizationSchema.
 type OrganizationSchema struct {
-	AiConfiguration                *AiConfigurationDetailsSchema         `json:"aiConfiguration,omitempty"`
-	BusinessAccountId              *string                               `json:"businessAccountId,omitempty"`
-	CreatedAt                      time.Time                             `json:"createdAt"`
-	Description                    *string                               `json:"description,omitempty"`
-	EmailNotificationConfiguration *EmailNotificationConfigurationSchema `json:"emailNotificationConfiguration,omitempty"`
-	FaviconUrl                     *string                               `json:"faviconUrl,omitempty"`
-	LogoUrl                        *string                               `json:"logoUrl,omitempty"`
-	Name                           string                                `json:"name"`
-	SlackNotificationConfiguration *SlackNotificationConfigurationSchema `json:"slackNotificationConfiguration,omitempty"`
-	UniqueId                       string                                `json:"uniqueId"`
-	WebsiteUrl                     *string                               `json:"websiteUrl,omitempty"`
-	WhatsappBusinessAccountDetails *WhatsAppBusinessAccountDetailsSchema `json:"whatsappBusinessAccountDetails,omitempty"`
+	AiConfiguration   *AiConfigurationDetailsSchema `json:"aiConfiguration,omitempty"`
+	BusinessAccountId *string                       `json:"businessAccountId,omitempty"`
+
+	// ConversationAutoCloseAfterMinutes active and resolved conversations without activity for this many minutes are closed, not set when conversations are never closed automatically
+	ConversationAutoCloseAfterMinutes *int                                  `json:"conversationAutoCloseAfterMinutes,omitempty"`
+	CreatedAt                         time.Time                             `json:"createdAt"`
+	CsatConfiguration                 *CsatConfigurationSchema              `json:"csatConfiguration,omitempty"`
+	Description                       *string                               `json:"description,omitempty"`
+	EmailNotificationConfiguration    *EmailNotificationConfigurationSchema `json:"emailNotificationConfiguration,omitempty"`
+	FaviconUrl                        *string                               `json:"faviconUrl,omitempty"`
+	IsTwoFactorRequiredForOwners      *bool                                 `json:"isTwoFactorRequiredForOwners,omitempty"`
+	LogoUrl                           *string                               `json:"logoUrl,omitempty"`
+	Name                              string                                `json:"name"`
+	SlackNotificationConfiguration    *SlackNotificationConfigurationSchema `json:"slackNotificationConfiguration,omitempty"`
+	UniqueId                          string                                `json:"uniqueId"`
+	WebsiteUrl                        *string                               `json:"websiteUrl,omitempty"`
+	WhatsappBusinessAccountDetails    *WhatsAppBusinessAccountDetailsSchema `json:"whatsappBusinessAccountDetails,omitempty"`
+}
+
+// OutboundContactCardSchema defines model for OutboundContactCardSchema.
+type OutboundContactCardSchema struct {
+	Company       *string   `json:"company,omitempty"`
+	Emails        *[]string `json:"emails,omitempty"`
+	FirstName     *string   `json:"firstName,omitempty"`
+	FormattedName string    `json:"formattedName"`
+	LastName      *string   `json:"lastName,omitempty"`
+	Phones        *[]string `json:"phones,omitempty"`
+	Urls          *[]string `json:"urls,omitempty"`
+}
+
+// OutboundInteractiveButtonSchema defines model for OutboundInteractiveButtonSchema.
+type OutboundInteractiveButtonSchema struct {
+	Id    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// OutboundInteractiveListRowSchema defines model for OutboundInteractiveListRowSchema.
+type OutboundInteractiveListRowSchema struct {
+	Description *string `json:"description,omitempty"`
+	Id          string  `json:"id"`
+	Title       string  `json:"title"`
+}
+
+// OutboundInteractiveListSectionSchema defines model for OutboundInteractiveListSectionSchema.
+type OutboundInteractiveListSectionSchema struct {
+	Rows  []OutboundInteractiveListRowSchema `json:"rows"`
+	Title string                             `json:"title"`
+}
+
+// OutboundInteractiveMessageSchema defines model for OutboundInteractiveMessageSchema.
+type OutboundInteractiveMessageSchema struct {
+	Body string `json:"body"`
+
+	// ButtonText label of the button opening the list, required for lists
+	ButtonText *string                                 `json:"buttonText,omitempty"`
+	Buttons    *[]OutboundInteractiveButtonSchema      `json:"buttons,omitempty"`
+	Sections   *[]OutboundInteractiveListSectionSchema `json:"sections,omitempty"`
+	Type       InteractiveMessageTypeEnum              `json:"type"`
+}
+
+// OutboundLocationMessageSchema defines model for OutboundLocationMessageSchema.
+type OutboundLocationMessageSchema struct {
+	Address   *string `json:"address,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      *string `json:"name,omitempty"`
+}
+
+// OutboundMediaMessageSchema media sent in image, video, audio, document and sticker messages. exactly one of id and link must be set
+type OutboundMediaMessageSchema struct {
+	// Caption only for images, videos and documents
+	Caption *string `json:"caption,omitempty"`
+
+	// Filename only for documents
+	Filename *string `json:"filename,omitempty"`
+
+	// Id id of media uploaded to whatsapp
+	Id *string `json:"id,omitempty"`
+
+	// Link public https url of the media
+	Link *string `json:"link,omitempty"`
+}
+
+// OutboundReactionMessageSchema defines model for OutboundReactionMessageSchema.
+type OutboundReactionMessageSchema struct {
+	Emoji string `json:"emoji"`
+
+	// MessageId id of the message of the conversation to react to
+	MessageId string `json:"messageId"`
+}
+
+// OutboundTemplateMessageSchema defines model for OutboundTemplateMessageSchema.
+type OutboundTemplateMessageSchema struct {
+	BodyParameters   *[]string `json:"bodyParameters,omitempty"`
+	HeaderParameters *[]string `json:"headerParameters,omitempty"`
+	Language         string    `json:"language"`
+	Name             string    `json:"name"`
+}
+
+// OutboundTextMessageSchema defines model for OutboundTextMessageSchema.
+type OutboundTextMessageSchema struct {
+	Body string `json:"body"`
+
+	// PreviewUrl render a preview of the first url in the body
+	PreviewUrl *bool `json:"previewUrl,omitempty"`
 }
 
 // PaginationMeta defines model for PaginationMeta.
@@ -945,6 +2080,9 @@ type PhoneNumberSchema struct {
 	VerifiedName       string `json:"verified_name"`
 }
 
+// PresenceStatusEnum defines model for PresenceStatusEnum.
+type PresenceStatusEnum string
+
 // PrimaryAnalyticsResponseSchema defines model for PrimaryAnalyticsResponseSchema.
 type PrimaryAnalyticsResponseSchema struct {
 	AggregateAnalytics AggregateAnalyticsSchema              `json:"aggregateAnalytics"`
@@ -952,6 +2090,17 @@ type PrimaryAnalyticsResponseSchema struct {
 	MessageAnalytics   []MessageAnalyticGraphDataPointSchema `json:"messageAnalytics"`
 }
 
+// RefreshTokenRequestBodySchema defines model for RefreshTokenRequestBodySchema.
+type RefreshTokenRequestBodySchema struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// RefreshTokenResponseBodySchema defines model for RefreshTokenResponseBodySchema.
+type RefreshTokenResponseBodySchema struct {
+	RefreshToken string `json:"refreshToken"`
+	Token        string `json:"token"`
+}
+
 // RegenerateApiKeyResponseSchema defines model for RegenerateApiKeyResponseSchema.
 type RegenerateApiKeyResponseSchema struct {
 	ApiKey *ApiKeySchema `json:"apiKey,omitempty"`
@@ -971,20 +2120,178 @@ type RegisterRequestResponseBodySchema struct {
 	IsOtpSent bool `json:"isOtpSent"`
 }
 
+// RenderCannedResponseResponseSchema defines model for RenderCannedResponseResponseSchema.
+type RenderCannedResponseResponseSchema struct {
+	Attachments []CannedResponseAttachmentSchema `json:"attachments"`
+	Content     string                           `json:"content"`
+
+	// UnresolvedVariables variables without a value for the conversation, they are left empty in the content
+	UnresolvedVariables []string `json:"unresolvedVariables"`
+}
+
+// RenderCannedResponseSchema defines model for RenderCannedResponseSchema.
+type RenderCannedResponseSchema struct {
+	ConversationId string `json:"conversationId"`
+}
+
+// ResetPasswordRequestBodySchema defines model for ResetPasswordRequestBodySchema.
+type ResetPasswordRequestBodySchema struct {
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+// ResetPasswordResponseBodySchema defines model for ResetPasswordResponseBodySchema.
+type ResetPasswordResponseBodySchema struct {
+	IsPasswordReset bool `json:"isPasswordReset"`
+}
+
+// RevokeUserSessionResponseSchema defines model for RevokeUserSessionResponseSchema.
+type RevokeUserSessionResponseSchema struct {
+	IsRevoked bool `json:"isRevoked"`
+}
+
 // RolePermissionEnum defines model for RolePermissionEnum.
 type RolePermissionEnum string
 
 // RoleUpdateSchema defines model for RoleUpdateSchema.
 type RoleUpdateSchema struct {
-	Description *string              `json:"description,omitempty"`
-	Name        string               `json:"name"`
-	Permissions []RolePermissionEnum `json:"permissions"`
+	Description *string `json:"description,omitempty"`
+
+	// IsTwoFactorRequired members with this role must use two factor authentication to access sensitive routes
+	IsTwoFactorRequired *bool                `json:"isTwoFactorRequired,omitempty"`
+	Name                string               `json:"name"`
+	Permissions         []RolePermissionEnum `json:"permissions"`
+}
+
+// RoutingRuleSchema defines model for RoutingRuleSchema.
+type RoutingRuleSchema struct {
+	CreatedAt time.Time `json:"createdAt"`
+	IsEnabled bool      `json:"isEnabled"`
+
+	// MaxConcurrentConversations members with this many active conversations are skipped
+	MaxConcurrentConversations *int `json:"maxConcurrentConversations,omitempty"`
+
+	// MemberIds members the conversations are distributed to, every member of the organization when empty
+	MemberIds []string `json:"memberIds"`
+	Name      string   `json:"name"`
+
+	// PhoneNumberId only conversations on this phone number match the rule
+	PhoneNumberId *string `json:"phoneNumberId,omitempty"`
+
+	// Priority rules with a lower priority are evaluated first
+	Priority int                             `json:"priority"`
+	Strategy ConversationRoutingStrategyEnum `json:"strategy"`
+
+	// TagId only conversations with contacts in a list with this tag match the rule
+	TagId    *string `json:"tagId,omitempty"`
+	UniqueId string  `json:"uniqueId"`
+}
+
+// RunContactListOperationResponseSchema defines model for RunContactListOperationResponseSchema.
+type RunContactListOperationResponseSchema struct {
+	Job     BackgroundJobSchema `json:"job"`
+	Message string              `json:"message"`
+}
+
+// ScanContactDuplicatesResponseSchema defines model for ScanContactDuplicatesResponseSchema.
+type ScanContactDuplicatesResponseSchema struct {
+	Job     BackgroundJobSchema `json:"job"`
+	Message string              `json:"message"`
 }
 
+// SearchResultSchema defines model for SearchResultSchema.
+type SearchResultSchema struct {
+	ContactId      string    `json:"contactId"`
+	ContactName    string    `json:"contactName"`
+	ContactPhone   string    `json:"contactPhone"`
+	ConversationId *string   `json:"conversationId,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+
+	// Highlight the matched text with the matched words wrapped in <mark> tags, as safe html. The text itself is html escaped, so it can be rendered as html as it is
+	Highlight string               `json:"highlight"`
+	MessageId *string              `json:"messageId,omitempty"`
+	Rank      float32              `json:"rank"`
+	Type      SearchResultTypeEnum `json:"type"`
+}
+
+// SearchResultTypeEnum defines model for SearchResultTypeEnum.
+type SearchResultTypeEnum string
+
 // SecondaryAnalyticsDashboardResponseSchema defines model for SecondaryAnalyticsDashboardResponseSchema.
 type SecondaryAnalyticsDashboardResponseSchema struct {
 	ConversationsAnalytics                  []ConversationAnalyticsDataPointSchema        `json:"conversationsAnalytics"`
 	MessageTypeTrafficDistributionAnalytics []MessageTypeDistributionGraphDataPointSchema `json:"messageTypeTrafficDistributionAnalytics"`
+	SlaAnalytics                            SlaAnalyticsSchema                            `json:"slaAnalytics"`
+}
+
+// SegmentConditionFieldEnum Attribute compares the attribute named by the condition, Status the status of the contact, ConversationTag checks
+// the tags of the conversations of the contact, ListMembership the lists of the contact, Replied the last message
+// of the contact, ClickedCampaignLink the tracked links the contact clicked and ReadCampaign the campaign messages
+// the contact read
+type SegmentConditionFieldEnum string
+
+// SegmentConditionOperatorEnum Attribute takes every operator but WithinDays and NotWithinDays, Replied only takes WithinDays and NotWithinDays,
+// the other fields take Equals and NotEquals
+type SegmentConditionOperatorEnum string
+
+// SegmentConditionSchema defines model for SegmentConditionSchema.
+type SegmentConditionSchema struct {
+	// Attribute the name of the attribute, required for Attribute conditions
+	Attribute *string `json:"attribute,omitempty"`
+
+	// Field Attribute compares the attribute named by the condition, Status the status of the contact, ConversationTag checks
+	// the tags of the conversations of the contact, ListMembership the lists of the contact, Replied the last message
+	// of the contact, ClickedCampaignLink the tracked links the contact clicked and ReadCampaign the campaign messages
+	// the contact read
+	Field SegmentConditionFieldEnum `json:"field"`
+
+	// Operator Attribute takes every operator but WithinDays and NotWithinDays, Replied only takes WithinDays and NotWithinDays,
+	// the other fields take Equals and NotEquals
+	Operator SegmentConditionOperatorEnum `json:"operator"`
+
+	// Value the value to compare with, the status for Status, the id of the tag, list or campaign for ConversationTag,
+	// ListMembership and ReadCampaign, the number of days for Replied. ClickedCampaignLink matches the links of
+	// any campaign when it is left out
+	Value *string `json:"value,omitempty"`
+}
+
+// SegmentMatchEnum All matches the contacts meeting every condition and group, Any the contacts meeting at least one
+type SegmentMatchEnum string
+
+// SegmentPreviewRequestSchema defines model for SegmentPreviewRequestSchema.
+type SegmentPreviewRequestSchema struct {
+	Rules SegmentRuleGroupSchema `json:"rules"`
+}
+
+// SegmentPreviewResponseSchema defines model for SegmentPreviewResponseSchema.
+type SegmentPreviewResponseSchema struct {
+	// Contacts the first contacts matching the rules
+	Contacts []ContactSchema `json:"contacts"`
+	Count    int             `json:"count"`
+}
+
+// SegmentRuleGroupSchema defines model for SegmentRuleGroupSchema.
+type SegmentRuleGroupSchema struct {
+	Conditions []SegmentConditionSchema `json:"conditions"`
+
+	// Groups nested groups, evaluated as one condition each
+	Groups *[]SegmentRuleGroupSchema `json:"groups,omitempty"`
+
+	// Match All matches the contacts meeting every condition and group, Any the contacts meeting at least one
+	Match SegmentMatchEnum `json:"match"`
+}
+
+// SegmentSchema defines model for SegmentSchema.
+type SegmentSchema struct {
+	CreatedAt   time.Time `json:"createdAt"`
+	Description *string   `json:"description,omitempty"`
+	Name        string    `json:"name"`
+
+	// NumberOfContacts the number of contacts matching the segment right now, only returned for a single segment
+	NumberOfContacts *int                   `json:"numberOfContacts,omitempty"`
+	Rules            SegmentRuleGroupSchema `json:"rules"`
+	UniqueId         string                 `json:"uniqueId"`
+	UpdatedAt        time.Time              `json:"updatedAt"`
 }
 
 // SendMessageInConversationResponseSchema defines model for SendMessageInConversationResponseSchema.
@@ -992,12 +2299,59 @@ type SendMessageInConversationResponseSchema struct {
 	Message MessageSchema `json:"message"`
 }
 
+// SlaAnalyticsSchema defines model for SlaAnalyticsSchema.
+type SlaAnalyticsSchema struct {
+	AverageFirstResponseTimeInMinutes float32 `json:"averageFirstResponseTimeInMinutes"`
+	ConversationsWithSla              int     `json:"conversationsWithSla"`
+	FirstResponseBreaches             int     `json:"firstResponseBreaches"`
+	FirstResponsesMet                 int     `json:"firstResponsesMet"`
+	ResolutionBreaches                int     `json:"resolutionBreaches"`
+	ResolutionsMet                    int     `json:"resolutionsMet"`
+}
+
+// SlaEscalationActionEnum defines model for SlaEscalationActionEnum.
+type SlaEscalationActionEnum string
+
+// SlaPolicySchema defines model for SlaPolicySchema.
+type SlaPolicySchema struct {
+	BusinessCalendar           *BusinessCalendarSchema  `json:"businessCalendar,omitempty"`
+	CreatedAt                  time.Time                `json:"createdAt"`
+	EscalationAction           *SlaEscalationActionEnum `json:"escalationAction,omitempty"`
+	EscalationMemberId         *string                  `json:"escalationMemberId,omitempty"`
+	EscalationTagId            *string                  `json:"escalationTagId,omitempty"`
+	FirstResponseTimeInMinutes int                      `json:"firstResponseTimeInMinutes"`
+
+	// IsDefault the default policy applies to conversations on phone numbers without a policy of their own
+	IsDefault               bool    `json:"isDefault"`
+	IsEnabled               bool    `json:"isEnabled"`
+	Name                    string  `json:"name"`
+	PhoneNumberId           *string `json:"phoneNumberId,omitempty"`
+	ResolutionTimeInMinutes *int    `json:"resolutionTimeInMinutes,omitempty"`
+	Timezone                string  `json:"timezone"`
+	UniqueId                string  `json:"uniqueId"`
+}
+
+// SlaStatusEnum defines model for SlaStatusEnum.
+type SlaStatusEnum string
+
 // SlackNotificationConfigurationSchema defines model for SlackNotificationConfigurationSchema.
 type SlackNotificationConfigurationSchema struct {
 	SlackChannel    string `json:"slackChannel"`
 	SlackWebhookUrl string `json:"slackWebhookUrl"`
 }
 
+// SnoozeConversationSchema defines model for SnoozeConversationSchema.
+type SnoozeConversationSchema struct {
+	SnoozedUntil time.Time `json:"snoozedUntil"`
+}
+
+// SubmitOptInFormResponseSchema defines model for SubmitOptInFormResponseSchema.
+type SubmitOptInFormResponseSchema struct {
+	// ConfirmationUrl the wa.me link the contact confirms the subscription with, it prefills the code of the form
+	ConfirmationUrl string `json:"confirmationUrl"`
+	Message         string `json:"message"`
+}
+
 // SwitchOrganizationResponseSchema defines model for SwitchOrganizationResponseSchema.
 type SwitchOrganizationResponseSchema struct {
 	Token string `json:"token"`
@@ -1066,6 +2420,31 @@ type TransferOrganizationOwnershipSchema struct {
 	NewOwnerId string `json:"newOwnerId"`
 }
 
+// TwoFactorCodeRequestBodySchema defines model for TwoFactorCodeRequestBodySchema.
+type TwoFactorCodeRequestBodySchema struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorLoginRequestBodySchema defines model for TwoFactorLoginRequestBodySchema.
+type TwoFactorLoginRequestBodySchema struct {
+	Code           string `json:"code"`
+	TwoFactorToken string `json:"twoFactorToken"`
+}
+
+// TwoFactorRecoveryCodesResponseSchema defines model for TwoFactorRecoveryCodesResponseSchema.
+type TwoFactorRecoveryCodesResponseSchema struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// TwoFactorSetupResponseSchema defines model for TwoFactorSetupResponseSchema.
+type TwoFactorSetupResponseSchema struct {
+	OtpauthUrl string `json:"otpauthUrl"`
+
+	// QrCode png data url of the otpauth url
+	QrCode string `json:"qrCode"`
+	Secret string `json:"secret"`
+}
+
 // UnassignConversationResponseSchema defines model for UnassignConversationResponseSchema.
 type UnassignConversationResponseSchema struct {
 	Data bool `json:"data"`
@@ -1083,6 +2462,16 @@ type UpdateAIConfigurationDetailsSchema struct {
 	Model     AiModelEnum `json:"model"`
 }
 
+// UpdateAvailabilityResponseSchema defines model for UpdateAvailabilityResponseSchema.
+type UpdateAvailabilityResponseSchema struct {
+	IsAvailable bool `json:"isAvailable"`
+}
+
+// UpdateAvailabilitySchema defines model for UpdateAvailabilitySchema.
+type UpdateAvailabilitySchema struct {
+	IsAvailable bool `json:"isAvailable"`
+}
+
 // UpdateCampaignByIdResponseSchema defines model for UpdateCampaignByIdResponseSchema.
 type UpdateCampaignByIdResponseSchema struct {
 	IsUpdated bool `json:"isUpdated"`
@@ -1090,22 +2479,70 @@ type UpdateCampaignByIdResponseSchema struct {
 
 // UpdateCampaignSchema defines model for UpdateCampaignSchema.
 type UpdateCampaignSchema struct {
-	Description                 *string                 `json:"description,omitempty"`
-	EnableLinkTracking          bool                    `json:"enableLinkTracking"`
-	ListIds                     []string                `json:"listIds"`
-	Name                        string                  `json:"name"`
-	PhoneNumber                 *string                 `json:"phoneNumber,omitempty"`
+	Description        *string  `json:"description,omitempty"`
+	EnableLinkTracking bool     `json:"enableLinkTracking"`
+	ListIds            []string `json:"listIds"`
+	Name               string   `json:"name"`
+	PhoneNumber        *string  `json:"phoneNumber,omitempty"`
+
+	// SegmentIds replaces the segments of the campaign when given
+	SegmentIds                  *[]string               `json:"segmentIds,omitempty"`
 	Status                      *CampaignStatusEnum     `json:"status,omitempty"`
 	Tags                        []string                `json:"tags"`
 	TemplateComponentParameters *map[string]interface{} `json:"templateComponentParameters,omitempty"`
 	TemplateMessageId           *string                 `json:"templateMessageId,omitempty"`
 }
 
+// UpdateCannedResponseByIdResponseSchema defines model for UpdateCannedResponseByIdResponseSchema.
+type UpdateCannedResponseByIdResponseSchema struct {
+	CannedResponse CannedResponseSchema `json:"cannedResponse"`
+}
+
 // UpdateContactByIdResponseSchema defines model for UpdateContactByIdResponseSchema.
 type UpdateContactByIdResponseSchema struct {
 	Contact ContactSchema `json:"contact"`
 }
 
+// UpdateContactCaptureSourceByIdResponseSchema defines model for UpdateContactCaptureSourceByIdResponseSchema.
+type UpdateContactCaptureSourceByIdResponseSchema struct {
+	Source ContactCaptureSourceSchema `json:"source"`
+}
+
+// UpdateContactCaptureSourceSchema defines model for UpdateContactCaptureSourceSchema.
+type UpdateContactCaptureSourceSchema struct {
+	Attributes              *map[string]interface{} `json:"attributes,omitempty"`
+	ConsentText             *string                 `json:"consentText,omitempty"`
+	IsActive                bool                    `json:"isActive"`
+	Name                    string                  `json:"name"`
+	PhoneNumber             *string                 `json:"phoneNumber,omitempty"`
+	PrefilledMessage        *string                 `json:"prefilledMessage,omitempty"`
+	WelcomeCannedResponseId *string                 `json:"welcomeCannedResponseId,omitempty"`
+}
+
+// UpdateContactConsentsResponseSchema defines model for UpdateContactConsentsResponseSchema.
+type UpdateContactConsentsResponseSchema struct {
+	Consents []ContactConsentSchema `json:"consents"`
+}
+
+// UpdateContactConsentsSchema defines model for UpdateContactConsentsSchema.
+type UpdateContactConsentsSchema struct {
+	Consents []NewContactConsentSchema `json:"consents"`
+}
+
+// UpdateContactFieldByIdResponseSchema defines model for UpdateContactFieldByIdResponseSchema.
+type UpdateContactFieldByIdResponseSchema struct {
+	Field ContactFieldSchema `json:"field"`
+}
+
+// UpdateContactFieldSchema defines model for UpdateContactFieldSchema.
+type UpdateContactFieldSchema struct {
+	// DefaultValue the value given to contacts written without the field, of the type of the field
+	DefaultValue *interface{} `json:"defaultValue,omitempty"`
+	IsRequired   bool         `json:"isRequired"`
+	Label        string       `json:"label"`
+	Options      *[]string    `json:"options,omitempty"`
+}
+
 // UpdateContactListSchema defines model for UpdateContactListSchema.
 type UpdateContactListSchema struct {
 	Description *string     `json:"description,omitempty"`
@@ -1132,6 +2569,13 @@ type UpdateConversationSchema struct {
 	Status ConversationStatusEnum `json:"status"`
 }
 
+// UpdateConversationStatusResponseSchema defines model for UpdateConversationStatusResponseSchema.
+type UpdateConversationStatusResponseSchema struct {
+	Event        ConversationTimelineEventSchema `json:"event"`
+	SnoozedUntil *time.Time                      `json:"snoozedUntil,omitempty"`
+	Status       ConversationStatusEnum          `json:"status"`
+}
+
 // UpdateListByIdResponseSchema defines model for UpdateListByIdResponseSchema.
 type UpdateListByIdResponseSchema struct {
 	List ContactListSchema `json:"list"`
@@ -1164,11 +2608,26 @@ type UpdateOrganizationMemberSchema struct {
 
 // UpdateOrganizationSchema defines model for UpdateOrganizationSchema.
 type UpdateOrganizationSchema struct {
-	AiConfiguration                *UpdateAIConfigurationDetailsSchema   `json:"aiConfiguration,omitempty"`
-	Description                    *string                               `json:"description,omitempty"`
-	EmailNotificationConfiguration *EmailNotificationConfigurationSchema `json:"emailNotificationConfiguration,omitempty"`
-	Name                           string                                `json:"name"`
-	SlackNotificationConfiguration *SlackNotificationConfigurationSchema `json:"slackNotificationConfiguration,omitempty"`
+	AiConfiguration *UpdateAIConfigurationDetailsSchema `json:"aiConfiguration,omitempty"`
+
+	// ConversationAutoCloseAfterMinutes close conversations after this many minutes without activity, 0 turns automatic closing off. left unchanged when not sent
+	ConversationAutoCloseAfterMinutes *int                                  `json:"conversationAutoCloseAfterMinutes,omitempty"`
+	CsatConfiguration                 *CsatConfigurationSchema              `json:"csatConfiguration,omitempty"`
+	Description                       *string                               `json:"description,omitempty"`
+	EmailNotificationConfiguration    *EmailNotificationConfigurationSchema `json:"emailNotificationConfiguration,omitempty"`
+	IsTwoFactorRequiredForOwners      *bool                                 `json:"isTwoFactorRequiredForOwners,omitempty"`
+	Name                              string                                `json:"name"`
+	SlackNotificationConfiguration    *SlackNotificationConfigurationSchema `json:"slackNotificationConfiguration,omitempty"`
+}
+
+// UpdatePresenceResponseSchema defines model for UpdatePresenceResponseSchema.
+type UpdatePresenceResponseSchema struct {
+	Presence MemberPresenceSchema `json:"presence"`
+}
+
+// UpdatePresenceSchema defines model for UpdatePresenceSchema.
+type UpdatePresenceSchema struct {
+	Status PresenceStatusEnum `json:"status"`
 }
 
 // UpdateRoleByIdResponseSchema defines model for UpdateRoleByIdResponseSchema.
@@ -1176,6 +2635,28 @@ type UpdateRoleByIdResponseSchema struct {
 	Role OrganizationRoleSchema `json:"role"`
 }
 
+// UpdateRoutingRuleByIdResponseSchema defines model for UpdateRoutingRuleByIdResponseSchema.
+type UpdateRoutingRuleByIdResponseSchema struct {
+	Rule RoutingRuleSchema `json:"rule"`
+}
+
+// UpdateSegmentByIdResponseSchema defines model for UpdateSegmentByIdResponseSchema.
+type UpdateSegmentByIdResponseSchema struct {
+	Segment SegmentSchema `json:"segment"`
+}
+
+// UpdateSegmentSchema defines model for UpdateSegmentSchema.
+type UpdateSegmentSchema struct {
+	Description *string                `json:"description,omitempty"`
+	Name        string                 `json:"name"`
+	Rules       SegmentRuleGroupSchema `json:"rules"`
+}
+
+// UpdateSlaPolicyByIdResponseSchema defines model for UpdateSlaPolicyByIdResponseSchema.
+type UpdateSlaPolicyByIdResponseSchema struct {
+	Policy SlaPolicySchema `json:"policy"`
+}
+
 // UpdateUserResponseSchema defines model for UpdateUserResponseSchema.
 type UpdateUserResponseSchema struct {
 	IsUpdated bool `json:"isUpdated"`
@@ -1203,11 +2684,26 @@ type UserSchema struct {
 	Email                          string                   `json:"email"`
 	FeatureFlags                   *FeatureFlags            `json:"featureFlags,omitempty"`
 	IsOwner                        bool                     `json:"isOwner"`
-	Name                           string                   `json:"name"`
-	Organization                   *OrganizationSchema      `json:"organization,omitempty"`
-	ProfilePicture                 *string                  `json:"profilePicture,omitempty"`
-	UniqueId                       string                   `json:"uniqueId"`
-	Username                       string                   `json:"username"`
+	IsTwoFactorEnabled             bool                     `json:"isTwoFactorEnabled"`
+
+	// IsTwoFactorRequired whether the organization policy requires the user to use two factor authentication
+	IsTwoFactorRequired bool                `json:"isTwoFactorRequired"`
+	Name                string              `json:"name"`
+	Organization        *OrganizationSchema `json:"organization,omitempty"`
+	ProfilePicture      *string             `json:"profilePicture,omitempty"`
+	UniqueId            string              `json:"uniqueId"`
+	Username            string              `json:"username"`
+}
+
+// UserSessionSchema defines model for UserSessionSchema.
+type UserSessionSchema struct {
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	IpAddress  *string   `json:"ipAddress,omitempty"`
+	IsCurrent  bool      `json:"isCurrent"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	UniqueId   string    `json:"uniqueId"`
+	UserAgent  *string   `json:"userAgent,omitempty"`
 }
 
 // VerifyOtpRequestBodySchema defines model for VerifyOtpRequestBodySchema.
@@ -1222,16 +2718,34 @@ type VerifyOtpRequestBodySchema struct {
 
 // VerifyOtpResponseBodySchema defines model for VerifyOtpResponseBodySchema.
 type VerifyOtpResponseBodySchema struct {
-	Token string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	Token        string `json:"token"`
 }
 
+// WeekdayEnum defines model for WeekdayEnum.
+type WeekdayEnum string
+
 // WhatsAppBusinessAccountDetailsSchema defines model for WhatsAppBusinessAccountDetailsSchema.
 type WhatsAppBusinessAccountDetailsSchema struct {
-	AccessToken       string `json:"accessToken"`
-	BusinessAccountId string `json:"businessAccountId"`
-	WebhookSecret     string `json:"webhookSecret"`
+	AccessToken       string                               `json:"accessToken"`
+	BusinessAccountId string                               `json:"businessAccountId"`
+	Health            *WhatsAppBusinessAccountHealthSchema `json:"health,omitempty"`
+	PhoneNumbers      *[]PhoneNumberSchema                 `json:"phoneNumbers,omitempty"`
+	WebhookSecret     string                               `json:"webhookSecret"`
+}
+
+// WhatsAppBusinessAccountHealthSchema defines model for WhatsAppBusinessAccountHealthSchema.
+type WhatsAppBusinessAccountHealthSchema struct {
+	CheckedAt      *time.Time                              `json:"checkedAt,omitempty"`
+	Error          *string                                 `json:"error,omitempty"`
+	Scopes         []string                                `json:"scopes"`
+	Status         WhatsAppBusinessAccountHealthStatusEnum `json:"status"`
+	TokenExpiresAt *time.Time                              `json:"tokenExpiresAt,omitempty"`
 }
 
+// WhatsAppBusinessAccountHealthStatusEnum defines model for WhatsAppBusinessAccountHealthStatusEnum.
+type WhatsAppBusinessAccountHealthStatusEnum string
+
 // WhatsAppBusinessHSMWhatsAppHSMComponent defines model for WhatsAppBusinessHSMWhatsAppHSMComponent.
 type WhatsAppBusinessHSMWhatsAppHSMComponent struct {
 	AddSecurityRecommendation *bool                             `json:"add_security_recommendation,omitempty"`
@@ -1290,6 +2804,18 @@ type GetCampaignsAnalyticsParams struct {
 	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
 }
 
+// GetCsatAnalyticsParams defines parameters for GetCsatAnalytics.
+type GetCsatAnalyticsParams struct {
+	// From starting range of time span to get analytics for
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To ending range of time span to get analytics for
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+
+	// Period length of the periods the ratings are grouped in, defaults to Day
+	Period *AnalyticsPeriodEnum `form:"period,omitempty" json:"period,omitempty"`
+}
+
 // GetPrimaryAnalyticsParams defines parameters for GetPrimaryAnalytics.
 type GetPrimaryAnalyticsParams struct {
 	// From starting range of time span to get analytics for
@@ -1308,6 +2834,12 @@ type GetSecondaryAnalyticsParams struct {
 	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
 }
 
+// GetOAuthAuthorizationUrlParams defines parameters for GetOAuthAuthorizationUrl.
+type GetOAuthAuthorizationUrlParams struct {
+	// InviteSlug organization invite slug, to join the organization after the login
+	InviteSlug *string `form:"inviteSlug,omitempty" json:"inviteSlug,omitempty"`
+}
+
 // SwitchOrganizationJSONBody defines parameters for SwitchOrganization.
 type SwitchOrganizationJSONBody struct {
 	OrganizationId *string `json:"organizationId,omitempty"`
@@ -1328,6 +2860,24 @@ type GetCampaignsParams struct {
 	Status *CampaignStatusEnum `form:"status,omitempty" json:"status,omitempty"`
 }
 
+// GetCannedResponsesParams defines parameters for GetCannedResponses.
+type GetCannedResponsesParams struct {
+	// Folder only return responses in this folder
+	Folder *string `form:"folder,omitempty" json:"folder,omitempty"`
+
+	// TagId only return responses with this tag
+	TagId *string `form:"tag_id,omitempty" json:"tag_id,omitempty"`
+
+	// Query search the name and shortcode of the responses
+	Query *string `form:"query,omitempty" json:"query,omitempty"`
+}
+
+// GetContactCaptureSourcesParams defines parameters for GetContactCaptureSources.
+type GetContactCaptureSourcesParams struct {
+	// ListId only return the forms and links of this list
+	ListId *string `form:"list_id,omitempty" json:"list_id,omitempty"`
+}
+
 // DeleteContactsByListParams defines parameters for DeleteContactsByList.
 type DeleteContactsByListParams struct {
 	// Id contact id/s to be deleted
@@ -1357,8 +2907,51 @@ type CreateContactsJSONBody = []NewContactSchema
 
 // BulkImportContactsMultipartBody defines parameters for BulkImportContacts.
 type BulkImportContactsMultipartBody struct {
-	// File The CSV file to be imported
-	File *openapi_types.File `json:"file,omitempty"`
+	// ColumnMapping JSON object naming the columns to read the contacts from, { "phone": "...", "name": "...", "attributes": { "<attribute>": "<column>" }, "attributesJson": "..." }.
+	// phone is required, attributesJson names a column holding the attributes as a JSON object.
+	// consents maps the consent categories to the columns holding the consent of the contacts, { "Marketing": "<column>" }, a yes, true or 1 grants the consent and a no, false or 0 revokes it.
+	// consentedAt names the column of the date the consents were given and consentEvidence the one holding their proof, the row is recorded as their evidence too.
+	// When left out the columns named name, phone and attributes are used.
+	ColumnMapping *string `json:"columnMapping,omitempty"`
+
+	// DefaultCountry ISO 3166-1 alpha-2 code of the country of phone numbers without a country code
+	DefaultCountry *string `json:"defaultCountry,omitempty"`
+
+	// Delimiter the delimiter of the CSV file, a comma by default
+	Delimiter *string `json:"delimiter,omitempty"`
+
+	// File The CSV or XLSX file to be imported, the first row must hold the column names
+	File openapi_types.File `json:"file"`
+
+	// ListIds JSON array of the ids of the lists to add the imported contacts to
+	ListIds *string `json:"listIds,omitempty"`
+
+	// OnConflict what to do with rows whose phone number belongs to an existing contact
+	OnConflict *ContactImportConflictStrategyEnum `json:"onConflict,omitempty"`
+}
+
+// ExportContactsParams defines parameters for ExportContacts.
+type ExportContactsParams struct {
+	// Format the format of the file, CSV by default
+	Format *ContactExportFormatEnum `form:"format,omitempty" json:"format,omitempty"`
+
+	// ListId only export the contacts of this list
+	ListId *string `form:"list_id,omitempty" json:"list_id,omitempty"`
+
+	// Order order by asc or desc
+	Order *OrderEnum `form:"order,omitempty" json:"order,omitempty"`
+
+	// Status only export the contacts in this status
+	Status *string `form:"status,omitempty" json:"status,omitempty"`
+}
+
+// GetContactTimelineParams defines parameters for GetContactTimeline.
+type GetContactTimelineParams struct {
+	// Page number of records to skip
+	Page int64 `form:"page" json:"page"`
+
+	// PerPage max number of records to return per page
+	PerPage int64 `form:"per_page" json:"per_page"`
 }
 
 // GetConversationMessagesParams defines parameters for GetConversationMessages.
@@ -1398,6 +2991,15 @@ type GetConversationsParams struct {
 
 	// MessageId query conversations with a message id.
 	MessageId *string `form:"message_id,omitempty" json:"message_id,omitempty"`
+
+	// Query full text search over the messages of the conversations and the name and phone number of their contact. The best matching conversations come first, with the highlights of their matching messages.
+	Query *string `form:"query,omitempty" json:"query,omitempty"`
+
+	// From only consider conversations with messages sent after this time
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To only consider conversations with messages sent before this time
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
 }
 
 // GetIntegrationsParams defines parameters for GetIntegrations.
@@ -1415,6 +3017,21 @@ type GetIntegrationsParams struct {
 	Status *IntegrationStatusEnum `form:"status,omitempty" json:"status,omitempty"`
 }
 
+// GetBackgroundJobsParams defines parameters for GetBackgroundJobs.
+type GetBackgroundJobsParams struct {
+	// Page number of records to skip
+	Page int64 `form:"page" json:"page"`
+
+	// PerPage max number of records to return per page
+	PerPage int64 `form:"per_page" json:"per_page"`
+
+	// Type only return jobs of this type
+	Type *BackgroundJobTypeEnum `form:"type,omitempty" json:"type,omitempty"`
+
+	// Status only return jobs in this status
+	Status *BackgroundJobStatusEnum `form:"status,omitempty" json:"status,omitempty"`
+}
+
 // GetContactListsParams defines parameters for GetContactLists.
 type GetContactListsParams struct {
 	// Page number of records to skip
@@ -1520,6 +3137,33 @@ type GetOrganizationRolesParams struct {
 	SortBy *OrderEnum `form:"sortBy,omitempty" json:"sortBy,omitempty"`
 }
 
+// GlobalSearchParams defines parameters for GlobalSearch.
+type GlobalSearchParams struct {
+	// Query words to search for, quoted phrases, OR and -word are supported
+	Query string `form:"query" json:"query"`
+
+	// Page number of records to skip
+	Page int64 `form:"page" json:"page"`
+
+	// PerPage max number of records to return per page
+	PerPage int64 `form:"per_page" json:"per_page"`
+
+	// From only search messages sent after this time
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To only search messages sent before this time
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// GetSegmentsParams defines parameters for GetSegments.
+type GetSegmentsParams struct {
+	// Page number of records to skip
+	Page int64 `form:"page" json:"page"`
+
+	// PerPage max number of records to return per page
+	PerPage int64 `form:"per_page" json:"per_page"`
+}
+
 // GetUserNotificationsParams defines parameters for GetUserNotifications.
 type GetUserNotificationsParams struct {
 	// Page number of records to skip
@@ -1538,18 +3182,33 @@ type SendMessageInAiChatJSONRequestBody = AiChatQuerySchema
 // VoteOnAiChatMessageJSONRequestBody defines body for VoteOnAiChatMessage for application/json ContentType.
 type VoteOnAiChatMessageJSONRequestBody = AiChatMessageVoteCreateSchema
 
+// ForgotPasswordJSONRequestBody defines body for ForgotPassword for application/json ContentType.
+type ForgotPasswordJSONRequestBody = ForgotPasswordRequestBodySchema
+
 // JoinOrganizationJSONRequestBody defines body for JoinOrganization for application/json ContentType.
 type JoinOrganizationJSONRequestBody = JoinOrganizationRequestBodySchema
 
 // LoginJSONRequestBody defines body for Login for application/json ContentType.
 type LoginJSONRequestBody = LoginRequestBodySchema
 
+// LoginWithOAuthJSONRequestBody defines body for LoginWithOAuth for application/json ContentType.
+type LoginWithOAuthJSONRequestBody = OAuthLoginRequestBodySchema
+
+// RefreshAccessTokenJSONRequestBody defines body for RefreshAccessToken for application/json ContentType.
+type RefreshAccessTokenJSONRequestBody = RefreshTokenRequestBodySchema
+
 // RegisterJSONRequestBody defines body for Register for application/json ContentType.
 type RegisterJSONRequestBody = RegisterRequestBodySchema
 
+// ResetPasswordJSONRequestBody defines body for ResetPassword for application/json ContentType.
+type ResetPasswordJSONRequestBody = ResetPasswordRequestBodySchema
+
 // SwitchOrganizationJSONRequestBody defines body for SwitchOrganization for application/json ContentType.
 type SwitchOrganizationJSONRequestBody SwitchOrganizationJSONBody
 
+// VerifyTwoFactorLoginJSONRequestBody defines body for VerifyTwoFactorLogin for application/json ContentType.
+type VerifyTwoFactorLoginJSONRequestBody = TwoFactorLoginRequestBodySchema
+
 // VerifyOtpJSONRequestBody defines body for VerifyOtp for application/json ContentType.
 type VerifyOtpJSONRequestBody = VerifyOtpRequestBodySchema
 
@@ -1559,6 +3218,27 @@ type CreateCampaignJSONRequestBody = NewCampaignSchema
 // UpdateCampaignByIdJSONRequestBody defines body for UpdateCampaignById for application/json ContentType.
 type UpdateCampaignByIdJSONRequestBody = UpdateCampaignSchema
 
+// CreateCannedResponseJSONRequestBody defines body for CreateCannedResponse for application/json ContentType.
+type CreateCannedResponseJSONRequestBody = NewCannedResponseSchema
+
+// UpdateCannedResponseByIdJSONRequestBody defines body for UpdateCannedResponseById for application/json ContentType.
+type UpdateCannedResponseByIdJSONRequestBody = NewCannedResponseSchema
+
+// RenderCannedResponseJSONRequestBody defines body for RenderCannedResponse for application/json ContentType.
+type RenderCannedResponseJSONRequestBody = RenderCannedResponseSchema
+
+// CreateContactCaptureSourceJSONRequestBody defines body for CreateContactCaptureSource for application/json ContentType.
+type CreateContactCaptureSourceJSONRequestBody = NewContactCaptureSourceSchema
+
+// UpdateContactCaptureSourceByIdJSONRequestBody defines body for UpdateContactCaptureSourceById for application/json ContentType.
+type UpdateContactCaptureSourceByIdJSONRequestBody = UpdateContactCaptureSourceSchema
+
+// CreateContactFieldJSONRequestBody defines body for CreateContactField for application/json ContentType.
+type CreateContactFieldJSONRequestBody = NewContactFieldSchema
+
+// UpdateContactFieldByIdJSONRequestBody defines body for UpdateContactFieldById for application/json ContentType.
+type UpdateContactFieldByIdJSONRequestBody = UpdateContactFieldSchema
+
 // CreateContactsJSONRequestBody defines body for CreateContacts for application/json ContentType.
 type CreateContactsJSONRequestBody = CreateContactsJSONBody
 
@@ -1568,9 +3248,24 @@ type BulkImportContactsJSONRequestBody = BulkImportSchema
 // BulkImportContactsMultipartRequestBody defines body for BulkImportContacts for multipart/form-data ContentType.
 type BulkImportContactsMultipartRequestBody BulkImportContactsMultipartBody
 
+// GrantLegacyConsentsJSONRequestBody defines body for GrantLegacyConsents for application/json ContentType.
+type GrantLegacyConsentsJSONRequestBody = GrantLegacyConsentsSchema
+
+// ScanContactDuplicatesJSONRequestBody defines body for ScanContactDuplicates for application/json ContentType.
+type ScanContactDuplicatesJSONRequestBody = ContactDuplicateScanSchema
+
 // UpdateContactByIdJSONRequestBody defines body for UpdateContactById for application/json ContentType.
 type UpdateContactByIdJSONRequestBody = UpdateContactSchema
 
+// UpdateContactConsentsJSONRequestBody defines body for UpdateContactConsents for application/json ContentType.
+type UpdateContactConsentsJSONRequestBody = UpdateContactConsentsSchema
+
+// EraseContactByIdJSONRequestBody defines body for EraseContactById for application/json ContentType.
+type EraseContactByIdJSONRequestBody = ContactErasureSchema
+
+// MergeContactsJSONRequestBody defines body for MergeContacts for application/json ContentType.
+type MergeContactsJSONRequestBody = MergeContactsSchema
+
 // UpdateConversationByIdJSONRequestBody defines body for UpdateConversationById for application/json ContentType.
 type UpdateConversationByIdJSONRequestBody = UpdateConversationSchema
 
@@ -1580,15 +3275,27 @@ type AssignConversationJSONRequestBody = AssignConversationSchema
 // SendMessageInConversationJSONRequestBody defines body for SendMessageInConversation for application/json ContentType.
 type SendMessageInConversationJSONRequestBody = NewMessageSchema
 
+// CreateConversationNoteJSONRequestBody defines body for CreateConversationNote for application/json ContentType.
+type CreateConversationNoteJSONRequestBody = NewConversationNoteSchema
+
+// SnoozeConversationJSONRequestBody defines body for SnoozeConversation for application/json ContentType.
+type SnoozeConversationJSONRequestBody = SnoozeConversationSchema
+
 // UnassignConversationJSONRequestBody defines body for UnassignConversation for application/json ContentType.
 type UnassignConversationJSONRequestBody = UnassignConversationSchema
 
 // CreateListJSONRequestBody defines body for CreateList for application/json ContentType.
 type CreateListJSONRequestBody = NewContactListSchema
 
+// RunContactListOperationJSONRequestBody defines body for RunContactListOperation for application/json ContentType.
+type RunContactListOperationJSONRequestBody = ContactListOperationSchema
+
 // UpdateListByIdJSONRequestBody defines body for UpdateListById for application/json ContentType.
 type UpdateListByIdJSONRequestBody = UpdateContactListSchema
 
+// SubmitOptInFormJSONRequestBody defines body for SubmitOptInForm for application/json ContentType.
+type SubmitOptInFormJSONRequestBody = OptInFormSubmissionSchema
+
 // CreateOrganizationJSONRequestBody defines body for CreateOrganization for application/json ContentType.
 type CreateOrganizationJSONRequestBody = NewOrganizationSchema
 
@@ -1613,11 +3320,47 @@ type UpdateOrganizationJSONRequestBody = UpdateOrganizationSchema
 // TransferOrganizationOwnershipJSONRequestBody defines body for TransferOrganizationOwnership for application/json ContentType.
 type TransferOrganizationOwnershipJSONRequestBody = TransferOrganizationOwnershipSchema
 
+// UpdatePresenceJSONRequestBody defines body for UpdatePresence for application/json ContentType.
+type UpdatePresenceJSONRequestBody = UpdatePresenceSchema
+
 // CreateOrganizationRoleJSONRequestBody defines body for CreateOrganizationRole for application/json ContentType.
 type CreateOrganizationRoleJSONRequestBody = NewOrganizationRoleSchema
 
 // UpdateOrganizationRoleByIdJSONRequestBody defines body for UpdateOrganizationRoleById for application/json ContentType.
 type UpdateOrganizationRoleByIdJSONRequestBody = RoleUpdateSchema
 
+// UpdateAvailabilityJSONRequestBody defines body for UpdateAvailability for application/json ContentType.
+type UpdateAvailabilityJSONRequestBody = UpdateAvailabilitySchema
+
+// CreateRoutingRuleJSONRequestBody defines body for CreateRoutingRule for application/json ContentType.
+type CreateRoutingRuleJSONRequestBody = NewRoutingRuleSchema
+
+// UpdateRoutingRuleByIdJSONRequestBody defines body for UpdateRoutingRuleById for application/json ContentType.
+type UpdateRoutingRuleByIdJSONRequestBody = NewRoutingRuleSchema
+
+// CreateSegmentJSONRequestBody defines body for CreateSegment for application/json ContentType.
+type CreateSegmentJSONRequestBody = NewSegmentSchema
+
+// PreviewSegmentJSONRequestBody defines body for PreviewSegment for application/json ContentType.
+type PreviewSegmentJSONRequestBody = SegmentPreviewRequestSchema
+
+// UpdateSegmentByIdJSONRequestBody defines body for UpdateSegmentById for application/json ContentType.
+type UpdateSegmentByIdJSONRequestBody = UpdateSegmentSchema
+
+// CreateSlaPolicyJSONRequestBody defines body for CreateSlaPolicy for application/json ContentType.
+type CreateSlaPolicyJSONRequestBody = NewSlaPolicySchema
+
+// UpdateSlaPolicyByIdJSONRequestBody defines body for UpdateSlaPolicyById for application/json ContentType.
+type UpdateSlaPolicyByIdJSONRequestBody = NewSlaPolicySchema
+
 // UpdateUserJSONRequestBody defines body for UpdateUser for application/json ContentType.
 type UpdateUserJSONRequestBody = UpdateUserSchema
+
+// DisableTwoFactorJSONRequestBody defines body for DisableTwoFactor for application/json ContentType.
+type DisableTwoFactorJSONRequestBody = TwoFactorCodeRequestBodySchema
+
+// EnableTwoFactorJSONRequestBody defines body for EnableTwoFactor for application/json ContentType.
+type EnableTwoFactorJSONRequestBody = TwoFactorCodeRequestBodySchema
+
+// RegenerateRecoveryCodesJSONRequestBody defines body for RegenerateRecoveryCodes for application/json ContentType.
+type RegenerateRecoveryCodesJSONRequestBody = TwoFactorCodeRequestBodySchema
@@ -11,14 +11,24 @@ import (
 type ApiServerEventType string
 
 const (
-	ApiServerNewNotificationEvent    ApiServerEventType = "NewNotification"
-	ApiServerNewMessageEvent         ApiServerEventType = "NewMessage"
-	ApiServerChatAssignmentEvent     ApiServerEventType = "ChatAssignment"
-	ApiServerChatUnAssignmentEvent   ApiServerEventType = "ChatUnAssignment"
-	ApiServerErrorEvent              ApiServerEventType = "Error"
-	ApiServerReloadRequiredEvent     ApiServerEventType = "ReloadRequired"
-	ApiServerConversationClosedEvent ApiServerEventType = "ConversationClosed"
-	ApiServerNewConversationEvent    ApiServerEventType = "NewConversation"
+	ApiServerNewNotificationEvent     ApiServerEventType = "NewNotification"
+	ApiServerNewMessageEvent          ApiServerEventType = "NewMessage"
+	ApiServerChatAssignmentEvent      ApiServerEventType = "ChatAssignment"
+	ApiServerChatUnAssignmentEvent    ApiServerEventType = "ChatUnAssignment"
+	ApiServerErrorEvent               ApiServerEventType = "Error"
+	ApiServerReloadRequiredEvent      ApiServerEventType = "ReloadRequired"
+	ApiServerConversationClosedEvent  ApiServerEventType = "ConversationClosed"
+	ApiServerNewConversationEvent     ApiServerEventType = "NewConversation"
+	ApiServerPresenceChangedEvent     ApiServerEventType = "PresenceChanged"
+	ApiServerNewConversationNoteEvent ApiServerEventType = "NewConversationNote"
+	// every status change of a conversation, closing it additionally produces a ConversationClosed event
+	ApiServerConversationStatusChangedEvent ApiServerEventType = "ConversationStatusChanged"
+	// a delivery receipt of whatsapp moved a message sent from the inbox to a new status
+	ApiServerMessageStatusChangedEvent ApiServerEventType = "MessageStatusChanged"
+	// a member read the messages of the contact in a conversation
+	ApiServerMessagesReadEvent ApiServerEventType = "MessagesRead"
+	// a background job of an organization made progress, started, completed or failed
+	ApiServerBackgroundJobProgressEvent ApiServerEventType = "BackgroundJobProgress"
 )
 
 type ApiServerEventInterface interface {
@@ -52,9 +62,31 @@ func (event *BaseApiServerEvent) ToJson() []byte {
 }
 
 type NewNotificationEvent struct {
-	BaseApiServerEvent                    // make it inline
-	EventType          ApiServerEventType `json:"eventType"`
-	Notification       string             `json:"notification"`
+	BaseApiServerEvent                              // make it inline
+	EventType          ApiServerEventType           `json:"eventType"`
+	UserId             string                       `json:"userId"`
+	OrganizationId     string                       `json:"organizationId"`
+	Notification       api_types.NotificationSchema `json:"notification"`
+}
+
+func NewNewNotificationEvent(organizationId, userId string, notification api_types.NotificationSchema) *NewNotificationEvent {
+	return &NewNotificationEvent{
+		BaseApiServerEvent: BaseApiServerEvent{
+			EventType: ApiServerNewNotificationEvent,
+		},
+		EventType:      ApiServerNewNotificationEvent,
+		UserId:         userId,
+		OrganizationId: organizationId,
+		Notification:   notification,
+	}
+}
+
+func (event *NewNotificationEvent) ToJson() []byte {
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		log.Print(err)
+	}
+	return bytes
 }
 
 type NewMessageEvent struct {
@@ -78,6 +110,26 @@ type ChatAssignmentEvent struct {
 	UserId    string             `json:"userId"`
 }
 
+func NewChatAssignmentEvent(conversation ConversationWithAllDetails, userId string) *ChatAssignmentEvent {
+	return &ChatAssignmentEvent{
+		BaseApiServerEvent: BaseApiServerEvent{
+			EventType:    ApiServerChatAssignmentEvent,
+			Conversation: conversation,
+		},
+		EventType: ApiServerChatAssignmentEvent,
+		ChatId:    conversation.UniqueId.String(),
+		UserId:    userId,
+	}
+}
+
+func (event *ChatAssignmentEvent) ToJson() []byte {
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		log.Print(err)
+	}
+	return bytes
+}
+
 type ChatUnAssignmentEvent struct {
 	BaseApiServerEvent
 	EventType ApiServerEventType `json:"eventType"`
@@ -85,6 +137,172 @@ type ChatUnAssignmentEvent struct {
 	UserId    string             `json:"userId"`
 }
 
+type PresenceChangedEvent struct {
+	BaseApiServerEvent
+	EventType      ApiServerEventType             `json:"eventType"`
+	OrganizationId string                         `json:"organizationId"`
+	Presence       api_types.MemberPresenceSchema `json:"presence"`
+}
+
+func NewPresenceChangedEvent(organizationId string, presence api_types.MemberPresenceSchema) *PresenceChangedEvent {
+	return &PresenceChangedEvent{
+		BaseApiServerEvent: BaseApiServerEvent{
+			EventType: ApiServerPresenceChangedEvent,
+		},
+		EventType:      ApiServerPresenceChangedEvent,
+		OrganizationId: organizationId,
+		Presence:       presence,
+	}
+}
+
+func (event *PresenceChangedEvent) ToJson() []byte {
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		log.Print(err)
+	}
+	return bytes
+}
+
+type NewConversationNoteEvent struct {
+	BaseApiServerEvent
+	EventType      ApiServerEventType               `json:"eventType"`
+	OrganizationId string                           `json:"organizationId"`
+	Note           api_types.ConversationNoteSchema `json:"note"`
+}
+
+func NewNewConversationNoteEvent(organizationId string, note api_types.ConversationNoteSchema) *NewConversationNoteEvent {
+	return &NewConversationNoteEvent{
+		BaseApiServerEvent: BaseApiServerEvent{
+			EventType: ApiServerNewConversationNoteEvent,
+		},
+		EventType:      ApiServerNewConversationNoteEvent,
+		OrganizationId: organizationId,
+		Note:           note,
+	}
+}
+
+func (event *NewConversationNoteEvent) ToJson() []byte {
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		log.Print(err)
+	}
+	return bytes
+}
+
+type ConversationStatusChangedEvent struct {
+	BaseApiServerEvent
+	EventType      ApiServerEventType                        `json:"eventType"`
+	OrganizationId string                                    `json:"organizationId"`
+	TimelineEvent  api_types.ConversationTimelineEventSchema `json:"timelineEvent"`
+}
+
+func NewConversationStatusChangedEvent(organizationId string, timelineEvent api_types.ConversationTimelineEventSchema) *ConversationStatusChangedEvent {
+	return &ConversationStatusChangedEvent{
+		BaseApiServerEvent: BaseApiServerEvent{
+			EventType: ApiServerConversationStatusChangedEvent,
+		},
+		EventType:      ApiServerConversationStatusChangedEvent,
+		OrganizationId: organizationId,
+		TimelineEvent:  timelineEvent,
+	}
+}
+
+func (event *ConversationStatusChangedEvent) ToJson() []byte {
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		log.Print(err)
+	}
+	return bytes
+}
+
+type MessageStatusChangedEvent struct {
+	BaseApiServerEvent
+	EventType      ApiServerEventType          `json:"eventType"`
+	OrganizationId string                      `json:"organizationId"`
+	ConversationId string                      `json:"conversationId"`
+	MessageId      string                      `json:"messageId"`
+	Status         api_types.MessageStatusEnum `json:"status"`
+}
+
+func NewMessageStatusChangedEvent(organizationId, conversationId, messageId string, status api_types.MessageStatusEnum) *MessageStatusChangedEvent {
+	return &MessageStatusChangedEvent{
+		BaseApiServerEvent: BaseApiServerEvent{
+			EventType: ApiServerMessageStatusChangedEvent,
+		},
+		EventType:      ApiServerMessageStatusChangedEvent,
+		OrganizationId: organizationId,
+		ConversationId: conversationId,
+		MessageId:      messageId,
+		Status:         status,
+	}
+}
+
+func (event *MessageStatusChangedEvent) ToJson() []byte {
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		log.Print(err)
+	}
+	return bytes
+}
+
+type MessagesReadEvent struct {
+	BaseApiServerEvent
+	EventType              ApiServerEventType `json:"eventType"`
+	OrganizationId         string             `json:"organizationId"`
+	ConversationId         string             `json:"conversationId"`
+	MessageIds             []string           `json:"messageIds"`
+	NumberOfUnreadMessages int                `json:"numberOfUnreadMessages"`
+	ReadByMemberId         string             `json:"readByMemberId"`
+}
+
+func NewMessagesReadEvent(organizationId, conversationId string, messageIds []string, numberOfUnreadMessages int, readByMemberId string) *MessagesReadEvent {
+	return &MessagesReadEvent{
+		BaseApiServerEvent: BaseApiServerEvent{
+			EventType: ApiServerMessagesReadEvent,
+		},
+		EventType:              ApiServerMessagesReadEvent,
+		OrganizationId:         organizationId,
+		ConversationId:         conversationId,
+		MessageIds:             messageIds,
+		NumberOfUnreadMessages: numberOfUnreadMessages,
+		ReadByMemberId:         readByMemberId,
+	}
+}
+
+func (event *MessagesReadEvent) ToJson() []byte {
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		log.Print(err)
+	}
+	return bytes
+}
+
+type ConversationClosedEvent struct {
+	BaseApiServerEvent
+	EventType      ApiServerEventType `json:"eventType"`
+	OrganizationId string             `json:"organizationId"`
+	ConversationId string             `json:"conversationId"`
+}
+
+func NewConversationClosedEvent(organizationId, conversationId string) *ConversationClosedEvent {
+	return &ConversationClosedEvent{
+		BaseApiServerEvent: BaseApiServerEvent{
+			EventType: ApiServerConversationClosedEvent,
+		},
+		EventType:      ApiServerConversationClosedEvent,
+		OrganizationId: organizationId,
+		ConversationId: conversationId,
+	}
+}
+
+func (event *ConversationClosedEvent) ToJson() []byte {
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		log.Print(err)
+	}
+	return bytes
+}
+
 // these events are meant to sent to the redis pubsub channel and our websocket server will consume these messages and react to them, also
 
 // ! flow of application:
@@ -93,3 +311,29 @@ type ChatUnAssignmentEvent struct {
 // ! 2. the api server will be handling all the rest api request from the frontend, and if there is something that needs to be immediately sent to the frontend client then the api server will publish a message which in code we wll refer to as ApiServerEvent, the event will then be consumed by the websocket server redis pubsub channel consumer and will be sent to the concerned connection as we have stored an slice of connections in the websocket server.
 // ! 3. example of ApiServerEvent can be error event, new notification event, event on a chat assignment to a user, our rest api server is also listening to the whatsapp business webhook so, every time we get a webhook event, and if this is something which requires to be sent on frontend suppose a new message comes in, so we will trigger a event to the redis pubsub channel and the websocket will consume it nd conveys it to the concerned connection.
 // ! 4. websocket server is responsible for the tasks mentioned above and whenever there is some message from a app user to a customer, the frontend will send the message over websocket, and then the websocket will call the whatsapp api to send the message to the customer.
+
+type BackgroundJobProgressEvent struct {
+	BaseApiServerEvent
+	EventType      ApiServerEventType            `json:"eventType"`
+	OrganizationId string                        `json:"organizationId"`
+	Job            api_types.BackgroundJobSchema `json:"job"`
+}
+
+func NewBackgroundJobProgressEvent(organizationId string, job api_types.BackgroundJobSchema) *BackgroundJobProgressEvent {
+	return &BackgroundJobProgressEvent{
+		BaseApiServerEvent: BaseApiServerEvent{
+			EventType: ApiServerBackgroundJobProgressEvent,
+		},
+		EventType:      ApiServerBackgroundJobProgressEvent,
+		OrganizationId: organizationId,
+		Job:            job,
+	}
+}
+
+func (event *BackgroundJobProgressEvent) ToJson() []byte {
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		log.Print(err)
+	}
+	return bytes
+}
@@ -0,0 +1,52 @@
+package audit_service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// Entry is an action taken on personal data, to be recorded in the audit log of the organization
+type Entry struct {
+	OrganizationId uuid.UUID
+	// nil when the action was not taken by a member
+	OrganizationMemberId *uuid.UUID
+	Action               model.AuditLogActionEnum
+	EntityId             *uuid.UUID
+	// stored as JSON, it must never hold the personal data the action was taken on
+	Details interface{}
+}
+
+// Record adds the entry to the audit log, pass the transaction of the action so that the action is never taken without
+// its entry
+func Record(ctx context.Context, db qrm.Executable, entry Entry) error {
+	var details *string
+
+	if entry.Details != nil {
+		detailsJson, err := json.Marshal(entry.Details)
+		if err != nil {
+			return err
+		}
+		stringDetails := string(detailsJson)
+		details = &stringDetails
+	}
+
+	_, err := table.AuditLog.INSERT(table.AuditLog.MutableColumns).
+		MODEL(model.AuditLog{
+			CreatedAt:            time.Now(),
+			OrganizationId:       entry.OrganizationId,
+			OrganizationMemberId: entry.OrganizationMemberId,
+			Action:               entry.Action,
+			EntityId:             entry.EntityId,
+			Details:              details,
+		}).
+		ExecContext(ctx, db)
+
+	return err
+}
@@ -0,0 +1,366 @@
+package background_job_service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/api_server_events"
+	cache "github.com/wapikit/wapikit/internal/core/redis"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+const (
+	// progress is written and published at most this often, a job processes thousands of items in between
+	progressInterval = time.Second
+	// the item errors are buffered and inserted together
+	itemErrorBatchSize = 500
+	// a running job writes its progress far more often, a job silent for longer was interrupted by a restart
+	StaleJobTimeout = 10 * time.Minute
+)
+
+var ErrJobNotFound = errors.New("background job not found")
+
+// Handler runs a claimed job and returns the summary stored as its result, returning an error fails the job
+type Handler func(ctx context.Context, job model.BackgroundJob, progress *Progress) (interface{}, error)
+
+// Create queues a job of the organization, parameters are stored as JSON and handed to the handler of the job type
+func Create(ctx context.Context, db qrm.Queryable, organizationId uuid.UUID, createdByMemberId *uuid.UUID, jobType model.BackgroundJobTypeEnum, parameters interface{}) (*model.BackgroundJob, error) {
+	parametersJson, err := json.Marshal(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	var job model.BackgroundJob
+
+	err = table.BackgroundJob.INSERT(
+		table.BackgroundJob.OrganizationId,
+		table.BackgroundJob.CreatedByOrganizationMemberId,
+		table.BackgroundJob.Type,
+		table.BackgroundJob.Status,
+		table.BackgroundJob.Parameters,
+		table.BackgroundJob.CreatedAt,
+		table.BackgroundJob.UpdatedAt,
+	).MODEL(model.BackgroundJob{
+		OrganizationId:                organizationId,
+		CreatedByOrganizationMemberId: createdByMemberId,
+		Type:                          jobType,
+		Status:                        model.BackgroundJobStatusEnum_Queued,
+		Parameters:                    string(parametersJson),
+		CreatedAt:                     time.Now(),
+		UpdatedAt:                     time.Now(),
+	}).
+		RETURNING(table.BackgroundJob.AllColumns).
+		QueryContext(ctx, db, &job)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// FetchJob returns the job of the organization
+func FetchJob(ctx context.Context, db qrm.Queryable, organizationId, jobId uuid.UUID) (*model.BackgroundJob, error) {
+	var job model.BackgroundJob
+
+	err := SELECT(table.BackgroundJob.AllColumns).
+		FROM(table.BackgroundJob).
+		WHERE(
+			table.BackgroundJob.UniqueId.EQ(UUID(jobId)).
+				AND(table.BackgroundJob.OrganizationId.EQ(UUID(organizationId))),
+		).
+		LIMIT(1).
+		QueryContext(ctx, db, &job)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// ClaimNext marks the oldest queued job as running and returns it, nil when no job is queued. The job row is locked with
+// SKIP LOCKED, so every running instance can claim jobs without picking the same one.
+func ClaimNext(ctx context.Context, db qrm.Queryable) (*model.BackgroundJob, error) {
+	var job model.BackgroundJob
+
+	oldestQueuedJob := SELECT(table.BackgroundJob.UniqueId).
+		FROM(table.BackgroundJob).
+		WHERE(table.BackgroundJob.Status.EQ(utils.EnumExpression(model.BackgroundJobStatusEnum_Queued.String()))).
+		ORDER_BY(table.BackgroundJob.CreatedAt.ASC()).
+		LIMIT(1).
+		FOR(UPDATE().SKIP_LOCKED())
+
+	err := table.BackgroundJob.UPDATE(table.BackgroundJob.Status, table.BackgroundJob.StartedAt, table.BackgroundJob.UpdatedAt).
+		SET(
+			utils.EnumExpression(model.BackgroundJobStatusEnum_Running.String()),
+			NOW(),
+			NOW(),
+		).
+		WHERE(table.BackgroundJob.UniqueId.IN(oldestQueuedJob)).
+		RETURNING(table.BackgroundJob.AllColumns).
+		QueryContext(ctx, db, &job)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// FailStaleJobs fails the running jobs which have not made progress for longer than StaleJobTimeout, they were interrupted
+// and are not picked up again because their items may have been partially processed
+func FailStaleJobs(ctx context.Context, db qrm.Queryable) ([]model.BackgroundJob, error) {
+	var jobs []model.BackgroundJob
+
+	err := table.BackgroundJob.UPDATE(table.BackgroundJob.Status, table.BackgroundJob.Error, table.BackgroundJob.CompletedAt, table.BackgroundJob.UpdatedAt).
+		SET(
+			utils.EnumExpression(model.BackgroundJobStatusEnum_Failed.String()),
+			String("the job was interrupted, please run it again"),
+			NOW(),
+			NOW(),
+		).
+		WHERE(
+			table.BackgroundJob.Status.EQ(utils.EnumExpression(model.BackgroundJobStatusEnum_Running.String())).
+				AND(table.BackgroundJob.UpdatedAt.LT(TimestampzT(time.Now().Add(-StaleJobTimeout)))),
+		).
+		RETURNING(table.BackgroundJob.AllColumns).
+		QueryContext(ctx, db, &jobs)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// Complete stores the result of the job and marks it as completed
+func Complete(ctx context.Context, db qrm.Queryable, jobId uuid.UUID, result interface{}) (*model.BackgroundJob, error) {
+	resultJson, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var job model.BackgroundJob
+
+	err = table.BackgroundJob.UPDATE(table.BackgroundJob.Status, table.BackgroundJob.Result, table.BackgroundJob.CompletedAt, table.BackgroundJob.UpdatedAt).
+		SET(
+			utils.EnumExpression(model.BackgroundJobStatusEnum_Completed.String()),
+			String(string(resultJson)),
+			NOW(),
+			NOW(),
+		).
+		WHERE(table.BackgroundJob.UniqueId.EQ(UUID(jobId))).
+		RETURNING(table.BackgroundJob.AllColumns).
+		QueryContext(ctx, db, &job)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// Fail marks the job as failed with the reason shown to the members
+func Fail(ctx context.Context, db qrm.Queryable, jobId uuid.UUID, reason string) (*model.BackgroundJob, error) {
+	var job model.BackgroundJob
+
+	err := table.BackgroundJob.UPDATE(table.BackgroundJob.Status, table.BackgroundJob.Error, table.BackgroundJob.CompletedAt, table.BackgroundJob.UpdatedAt).
+		SET(
+			utils.EnumExpression(model.BackgroundJobStatusEnum_Failed.String()),
+			String(reason),
+			NOW(),
+			NOW(),
+		).
+		WHERE(table.BackgroundJob.UniqueId.EQ(UUID(jobId))).
+		RETURNING(table.BackgroundJob.AllColumns).
+		QueryContext(ctx, db, &job)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// FetchItemErrors returns the item errors of the job after the given item number, in the order of the items
+func FetchItemErrors(ctx context.Context, db qrm.Queryable, jobId uuid.UUID, afterItemNumber int, limit int64) ([]model.BackgroundJobItemError, error) {
+	var itemErrors []model.BackgroundJobItemError
+
+	err := SELECT(table.BackgroundJobItemError.AllColumns).
+		FROM(table.BackgroundJobItemError).
+		WHERE(
+			table.BackgroundJobItemError.BackgroundJobId.EQ(UUID(jobId)).
+				AND(table.BackgroundJobItemError.ItemNumber.GT(Int(int64(afterItemNumber)))),
+		).
+		ORDER_BY(table.BackgroundJobItemError.ItemNumber.ASC()).
+		LIMIT(limit).
+		QueryContext(ctx, db, &itemErrors)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return itemErrors, nil
+}
+
+// Progress tracks the processed and failed items of a running job, the counters are written to the job and published to
+// the members of the organization at most once every progressInterval
+type Progress struct {
+	db               *sql.DB
+	redis            *cache.RedisClient
+	eventChannelName string
+	job              model.BackgroundJob
+	itemErrors       []model.BackgroundJobItemError
+	lastFlushedAt    time.Time
+}
+
+func NewProgress(db *sql.DB, redis *cache.RedisClient, eventChannelName string, job model.BackgroundJob) *Progress {
+	return &Progress{
+		db:               db,
+		redis:            redis,
+		eventChannelName: eventChannelName,
+		job:              job,
+		lastFlushedAt:    time.Now(),
+	}
+}
+
+// SetTotal sets the number of items the job is going to process, and publishes it right away
+func (p *Progress) SetTotal(ctx context.Context, total int) error {
+	totalItems := int32(total)
+	p.job.TotalItems = &totalItems
+	return p.Flush(ctx)
+}
+
+// ItemsProcessed counts items as processed successfully
+func (p *Progress) ItemsProcessed(ctx context.Context, count int) error {
+	p.job.ProcessedItems += int32(count)
+	return p.flushIfDue(ctx)
+}
+
+// ItemFailed counts the item as processed and records why it failed, item is the raw item as it was read
+func (p *Progress) ItemFailed(ctx context.Context, itemNumber int, item string, reason string) error {
+	p.job.ProcessedItems++
+	p.job.FailedItems++
+	p.itemErrors = append(p.itemErrors, model.BackgroundJobItemError{
+		BackgroundJobId: p.job.UniqueId,
+		ItemNumber:      int32(itemNumber),
+		Item:            &item,
+		Error:           reason,
+		CreatedAt:       time.Now(),
+	})
+
+	if len(p.itemErrors) >= itemErrorBatchSize {
+		if err := p.insertItemErrors(ctx); err != nil {
+			return err
+		}
+	}
+
+	return p.flushIfDue(ctx)
+}
+
+func (p *Progress) flushIfDue(ctx context.Context) error {
+	if time.Since(p.lastFlushedAt) < progressInterval {
+		return nil
+	}
+	return p.Flush(ctx)
+}
+
+// Flush writes the buffered item errors and the counters to the job, and publishes the progress
+func (p *Progress) Flush(ctx context.Context) error {
+	if err := p.insertItemErrors(ctx); err != nil {
+		return err
+	}
+
+	err := table.BackgroundJob.UPDATE(table.BackgroundJob.TotalItems, table.BackgroundJob.ProcessedItems, table.BackgroundJob.FailedItems, table.BackgroundJob.UpdatedAt).
+		MODEL(model.BackgroundJob{
+			TotalItems:     p.job.TotalItems,
+			ProcessedItems: p.job.ProcessedItems,
+			FailedItems:    p.job.FailedItems,
+			UpdatedAt:      time.Now(),
+		}).
+		WHERE(table.BackgroundJob.UniqueId.EQ(UUID(p.job.UniqueId))).
+		RETURNING(table.BackgroundJob.AllColumns).
+		QueryContext(ctx, p.db, &p.job)
+
+	if err != nil {
+		return err
+	}
+
+	p.lastFlushedAt = time.Now()
+	Publish(p.redis, p.eventChannelName, p.job)
+	return nil
+}
+
+func (p *Progress) insertItemErrors(ctx context.Context) error {
+	if len(p.itemErrors) == 0 {
+		return nil
+	}
+
+	_, err := table.BackgroundJobItemError.INSERT(
+		table.BackgroundJobItemError.BackgroundJobId,
+		table.BackgroundJobItemError.ItemNumber,
+		table.BackgroundJobItemError.Item,
+		table.BackgroundJobItemError.Error,
+		table.BackgroundJobItemError.CreatedAt,
+	).
+		MODELS(p.itemErrors).
+		ExecContext(ctx, p.db)
+
+	if err != nil {
+		return err
+	}
+
+	p.itemErrors = nil
+	return nil
+}
+
+// Publish sends the current state of the job to the members of its organization
+func Publish(redis *cache.RedisClient, eventChannelName string, job model.BackgroundJob) {
+	event := api_server_events.NewBackgroundJobProgressEvent(job.OrganizationId.String(), ToSchema(job))
+	redis.PublishMessageToRedisChannel(eventChannelName, event.ToJson())
+}
+
+func ToSchema(job model.BackgroundJob) api_types.BackgroundJobSchema {
+	jobToReturn := api_types.BackgroundJobSchema{
+		UniqueId:       job.UniqueId.String(),
+		Type:           api_types.BackgroundJobTypeEnum(job.Type.String()),
+		Status:         api_types.BackgroundJobStatusEnum(job.Status.String()),
+		ProcessedItems: int(job.ProcessedItems),
+		FailedItems:    int(job.FailedItems),
+		Error:          job.Error,
+		CreatedAt:      job.CreatedAt,
+		StartedAt:      job.StartedAt,
+		CompletedAt:    job.CompletedAt,
+	}
+
+	if job.TotalItems != nil {
+		totalItems := int(*job.TotalItems)
+		jobToReturn.TotalItems = &totalItems
+	}
+
+	if job.Result != nil {
+		var result map[string]interface{}
+		if err := json.Unmarshal([]byte(*job.Result), &result); err == nil {
+			jobToReturn.Result = &result
+		}
+	}
+
+	return jobToReturn
+}
@@ -0,0 +1,88 @@
+package canned_response_service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/wapikit/wapikit/.db-generated/model"
+)
+
+// variables are written as {{contact.name}}, spaces inside the braces are allowed
+var variablePattern = regexp.MustCompile(`{{\s*([a-zA-Z0-9_.]+)\s*}}`)
+
+// RenderContext holds everything the variables of a canned response can be resolved from
+type RenderContext struct {
+	Contact      model.Contact
+	Conversation model.Conversation
+	Organization model.Organization
+	AgentName    string
+	AgentEmail   string
+}
+
+// Variables returns the values of the variables available for the context, attributes of the contact are available as contact.<attribute>
+func (renderContext RenderContext) Variables() map[string]string {
+	variables := map[string]string{
+		"contact.name":             renderContext.Contact.Name,
+		"contact.phoneNumber":      renderContext.Contact.PhoneNumber,
+		"conversation.id":          renderContext.Conversation.UniqueId.String(),
+		"conversation.phoneNumber": renderContext.Conversation.PhoneNumberUsed,
+		"organization.name":        renderContext.Organization.Name,
+		"agent.name":               renderContext.AgentName,
+		"agent.email":              renderContext.AgentEmail,
+	}
+
+	if renderContext.Contact.Attributes == nil {
+		return variables
+	}
+
+	var attributes map[string]interface{}
+	if err := json.Unmarshal([]byte(*renderContext.Contact.Attributes), &attributes); err != nil {
+		return variables
+	}
+
+	for key, value := range attributes {
+		variableName := "contact." + key
+		if _, ok := variables[variableName]; ok {
+			// * attributes never shadow the built in fields of the contact
+			continue
+		}
+
+		switch value := value.(type) {
+		case string:
+			variables[variableName] = value
+		case float64, bool:
+			variables[variableName] = fmt.Sprint(value)
+		}
+	}
+
+	return variables
+}
+
+// Render replaces the variables of the content with their values, variables without a value are left empty and returned
+func Render(content string, variables map[string]string) (string, []string) {
+	unresolvedVariables := []string{}
+	seen := make(map[string]bool)
+
+	rendered := variablePattern.ReplaceAllStringFunc(content, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+
+		if value, ok := variables[name]; ok && value != "" {
+			return value
+		}
+
+		if !seen[name] {
+			seen[name] = true
+			unresolvedVariables = append(unresolvedVariables, name)
+		}
+		return ""
+	})
+
+	return rendered, unresolvedVariables
+}
+
+// NormalizeShortcode strips the leading slash agents type in the inbox, shortcodes are matched case insensitively
+func NormalizeShortcode(shortcode string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(shortcode), "/"))
+}
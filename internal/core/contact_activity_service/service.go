@@ -0,0 +1,152 @@
+package contact_activity_service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// Activity is a change made to a contact, to be shown in the timeline of the contact
+type Activity struct {
+	OrganizationId uuid.UUID
+	ContactId      uuid.UUID
+	// nil when the change was not made by a member
+	OrganizationMemberId *uuid.UUID
+	Type                 model.ContactActivityTypeEnum
+	// one of the data types below matching the type, stored as JSON
+	Data interface{}
+}
+
+// ListData is the data of the ListJoined and ListLeft activities, the name is kept as the list may be renamed or deleted
+type ListData struct {
+	ContactListId uuid.UUID `json:"contactListId"`
+	Name          string    `json:"name"`
+}
+
+// StatusData is the data of the StatusChanged activities
+type StatusData struct {
+	OldStatus string `json:"oldStatus"`
+	NewStatus string `json:"newStatus"`
+}
+
+// AttributeChange is one attribute of an AttributesChanged activity, a value is nil when the attribute was added or removed
+type AttributeChange struct {
+	Key      string      `json:"key"`
+	OldValue interface{} `json:"oldValue"`
+	NewValue interface{} `json:"newValue"`
+}
+
+// AttributesData is the data of the AttributesChanged activities
+type AttributesData struct {
+	Changes []AttributeChange `json:"changes"`
+}
+
+// ConsentData is the data of the OptedIn and OptedOut activities, it is the proof of the consent given or withdrawn
+type ConsentData struct {
+	Category  string                 `json:"category"`
+	Source    string                 `json:"source"`
+	ChangedAt time.Time              `json:"changedAt"`
+	Evidence  map[string]interface{} `json:"evidence"`
+	// set when the consent was given through an opt-in form, the name is kept as the form may be renamed or deleted
+	CaptureSourceId   *uuid.UUID `json:"captureSourceId,omitempty"`
+	CaptureSourceName string     `json:"captureSourceName,omitempty"`
+}
+
+// MergedData is the data of the Merged activities, only the ids are kept as the merged contacts are deleted
+type MergedData struct {
+	MergedContactIds []uuid.UUID `json:"mergedContactIds"`
+}
+
+// Record adds the activities to the log, pass the transaction of the change so that the change is never made without them
+func Record(ctx context.Context, db qrm.Executable, activities ...Activity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+
+	activitiesToInsert := make([]model.ContactActivity, 0, len(activities))
+
+	for _, activity := range activities {
+		var data *string
+
+		if activity.Data != nil {
+			dataJson, err := json.Marshal(activity.Data)
+			if err != nil {
+				return err
+			}
+			stringData := string(dataJson)
+			data = &stringData
+		}
+
+		activitiesToInsert = append(activitiesToInsert, model.ContactActivity{
+			CreatedAt:            time.Now(),
+			OrganizationId:       activity.OrganizationId,
+			ContactId:            activity.ContactId,
+			OrganizationMemberId: activity.OrganizationMemberId,
+			Type:                 activity.Type,
+			Data:                 data,
+		})
+	}
+
+	_, err := table.ContactActivity.INSERT(table.ContactActivity.MutableColumns).
+		MODELS(activitiesToInsert).
+		ExecContext(ctx, db)
+
+	return err
+}
+
+// AttributeChanges compares the attributes of a contact, as stored, before and after a change. The changes are sorted by
+// key and none are returned when the attributes are the same.
+func AttributeChanges(oldAttributes, newAttributes *string) ([]AttributeChange, error) {
+	oldValues, err := decodeAttributes(oldAttributes)
+	if err != nil {
+		return nil, err
+	}
+
+	newValues, err := decodeAttributes(newAttributes)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []AttributeChange{}
+
+	for key, oldValue := range oldValues {
+		newValue, ok := newValues[key]
+		if !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, AttributeChange{Key: key, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+
+	for key, newValue := range newValues {
+		if _, ok := oldValues[key]; !ok {
+			changes = append(changes, AttributeChange{Key: key, NewValue: newValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes, nil
+}
+
+func decodeAttributes(attributes *string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	if attributes == nil || *attributes == "" {
+		return values, nil
+	}
+
+	if err := json.Unmarshal([]byte(*attributes), &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
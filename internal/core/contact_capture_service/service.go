@@ -0,0 +1,446 @@
+package contact_capture_service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/contact_activity_service"
+	"github.com/wapikit/wapikit/internal/core/contact_consent_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+const (
+	// * letters and digits which can not be mistaken for each other when the code is read out
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	codeLength   = 8
+)
+
+// codes are prefilled in the messages of the links after a hash, e.g. "Hi, I want to join #K7QX2M9A"
+var codePattern = regexp.MustCompile(`#([A-HJ-NP-Z2-9]{8})\b`)
+
+var nonDigitPattern = regexp.MustCompile(`[^0-9]`)
+
+var (
+	ErrInvalidSource     = errors.New("invalid contact capture source")
+	ErrInvalidSubmission = errors.New("invalid opt-in form submission")
+)
+
+// Submission is a submitted opt-in form
+type Submission struct {
+	Name        string
+	PhoneNumber string
+	IpAddress   string
+	UserAgent   string
+}
+
+// NewCode returns a random code for a new source
+func NewCode() (string, error) {
+	return gonanoid.Generate(codeAlphabet, codeLength)
+}
+
+// FindCode returns the code of a link found in the text of a message, an empty string when there is none
+func FindCode(text string) string {
+	match := codePattern.FindStringSubmatch(text)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// PrefilledText returns the message the link prefills for the contact, the code ends it
+func PrefilledText(source model.ContactCaptureSource) string {
+	message := ""
+	if source.PrefilledMessage != nil {
+		message = strings.TrimSpace(*source.PrefilledMessage)
+	}
+
+	if message == "" {
+		return "#" + source.Code
+	}
+	return message + " #" + source.Code
+}
+
+// Url returns the wa.me link of a link, or the url of the hosted page of a form
+func Url(source model.ContactCaptureSource, rootUrl string) string {
+	if source.Type == model.ContactCaptureSourceTypeEnum_Link {
+		return chatUrl(source, PrefilledText(source))
+	}
+
+	return strings.TrimRight(rootUrl, "/") + "/opt-in?code=" + url.QueryEscape(source.Code)
+}
+
+// ConfirmationUrl returns the wa.me link the contacts submitting a form confirm their subscription with, the message it
+// prefills ends with the code of the form
+func ConfirmationUrl(source model.ContactCaptureSource) string {
+	return chatUrl(source, "Yes, I want to subscribe #"+source.Code)
+}
+
+// chatUrl returns the wa.me link opening a chat with the business phone number of the source, prefilled with the text
+func chatUrl(source model.ContactCaptureSource, text string) string {
+	phoneNumber := ""
+	if source.PhoneNumber != nil {
+		phoneNumber = nonDigitPattern.ReplaceAllString(*source.PhoneNumber, "")
+	}
+	return "https://wa.me/" + phoneNumber + "?text=" + url.QueryEscape(text)
+}
+
+// Validate checks the settings of the source for its type. Both need the business phone number, links open a chat with
+// it and the contacts confirm the subscription of a form with it, a form also needs the statement the contacts agree to.
+func Validate(source model.ContactCaptureSource) error {
+	if strings.TrimSpace(source.Name) == "" {
+		return fmt.Errorf("%w: the name is required", ErrInvalidSource)
+	}
+
+	if source.PhoneNumber == nil || nonDigitPattern.ReplaceAllString(*source.PhoneNumber, "") == "" {
+		return fmt.Errorf("%w: the business phone number is required", ErrInvalidSource)
+	}
+
+	switch source.Type {
+	case model.ContactCaptureSourceTypeEnum_Link:
+	case model.ContactCaptureSourceTypeEnum_Form:
+		if source.ConsentText == nil || strings.TrimSpace(*source.ConsentText) == "" {
+			return fmt.Errorf("%w: forms need the consent text the contacts agree to", ErrInvalidSource)
+		}
+		if source.WelcomeCannedResponseId != nil {
+			return fmt.Errorf("%w: only links can send a welcome message", ErrInvalidSource)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %s", ErrInvalidSource, source.Type.String())
+	}
+
+	return nil
+}
+
+// ToSchema converts the source to the schema returned by the API
+func ToSchema(source model.ContactCaptureSource, rootUrl string) api_types.ContactCaptureSourceSchema {
+	attributes := map[string]interface{}{}
+	if source.Attributes != nil {
+		json.Unmarshal([]byte(*source.Attributes), &attributes)
+	}
+
+	schema := api_types.ContactCaptureSourceSchema{
+		UniqueId:         source.UniqueId.String(),
+		ListId:           source.ContactListId.String(),
+		Type:             api_types.ContactCaptureSourceTypeEnum(source.Type.String()),
+		Name:             source.Name,
+		Code:             source.Code,
+		Url:              Url(source, rootUrl),
+		PhoneNumber:      source.PhoneNumber,
+		PrefilledMessage: source.PrefilledMessage,
+		ConsentText:      source.ConsentText,
+		Attributes:       attributes,
+		IsActive:         source.IsActive,
+		CreatedAt:        source.CreatedAt,
+		UpdatedAt:        source.UpdatedAt,
+	}
+
+	if source.WelcomeCannedResponseId != nil {
+		welcomeCannedResponseId := source.WelcomeCannedResponseId.String()
+		schema.WelcomeCannedResponseId = &welcomeCannedResponseId
+	}
+
+	return schema
+}
+
+// FetchByCode returns the active source of the code and type, nil when there is none
+func FetchByCode(ctx context.Context, db qrm.Queryable, code string, sourceType model.ContactCaptureSourceTypeEnum) (*model.ContactCaptureSource, error) {
+	var source model.ContactCaptureSource
+
+	err := SELECT(table.ContactCaptureSource.AllColumns).
+		FROM(table.ContactCaptureSource).
+		WHERE(
+			table.ContactCaptureSource.Code.EQ(String(code)).
+				AND(table.ContactCaptureSource.Type.EQ(utils.EnumExpression(sourceType.String()))).
+				AND(table.ContactCaptureSource.IsActive.IS_TRUE()),
+		).
+		LIMIT(1).
+		QueryContext(ctx, db, &source)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &source, nil
+}
+
+// Capture adds the contact to the list of the source and gives it the attributes of the source, the values the contact
+// already has are kept. It returns the contact and whether it joined the list, a contact already in the list stays in it.
+func Capture(ctx context.Context, db qrm.DB, source model.ContactCaptureSource, contact model.Contact) (*model.Contact, bool, error) {
+	return capture(ctx, db, source, contact, true)
+}
+
+// capture adds the contact to the list of the source, it is given the attributes of the source when withAttributes is set
+func capture(ctx context.Context, db qrm.DB, source model.ContactCaptureSource, contact model.Contact, withAttributes bool) (*model.Contact, bool, error) {
+	var insertedMemberships []model.ContactListContact
+
+	err := table.ContactListContact.
+		INSERT(table.ContactListContact.AllColumns).
+		MODEL(model.ContactListContact{
+			ContactListId: source.ContactListId,
+			ContactId:     contact.UniqueId,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}).
+		ON_CONFLICT(table.ContactListContact.ContactListId, table.ContactListContact.ContactId).
+		DO_NOTHING().
+		RETURNING(table.ContactListContact.AllColumns).
+		QueryContext(ctx, db, &insertedMemberships)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, false, err
+	}
+
+	joined := len(insertedMemberships) > 0
+	activities := []contact_activity_service.Activity{}
+
+	if joined {
+		var list model.ContactList
+
+		err := SELECT(table.ContactList.AllColumns).
+			FROM(table.ContactList).
+			WHERE(table.ContactList.UniqueId.EQ(UUID(source.ContactListId))).
+			QueryContext(ctx, db, &list)
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		activities = append(activities, contact_activity_service.Activity{
+			OrganizationId: contact.OrganizationId,
+			ContactId:      contact.UniqueId,
+			Type:           model.ContactActivityTypeEnum_ListJoined,
+			Data: contact_activity_service.ListData{
+				ContactListId: list.UniqueId,
+				Name:          list.Name,
+			},
+		})
+	}
+
+	attributes, err := captureAttributes(source, contact)
+	if err != nil {
+		return nil, false, err
+	}
+
+	attributeChanges, err := contact_activity_service.AttributeChanges(contact.Attributes, &attributes)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if withAttributes && len(attributeChanges) > 0 {
+		err = table.Contact.UPDATE(table.Contact.Attributes, table.Contact.UpdatedAt).
+			MODEL(model.Contact{
+				Attributes: &attributes,
+				UpdatedAt:  time.Now(),
+			}).
+			WHERE(table.Contact.UniqueId.EQ(UUID(contact.UniqueId))).
+			RETURNING(table.Contact.AllColumns).
+			QueryContext(ctx, db, &contact)
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		activities = append(activities, contact_activity_service.Activity{
+			OrganizationId: contact.OrganizationId,
+			ContactId:      contact.UniqueId,
+			Type:           model.ContactActivityTypeEnum_AttributesChanged,
+			Data: contact_activity_service.AttributesData{
+				Changes: attributeChanges,
+			},
+		})
+	}
+
+	if err := contact_activity_service.Record(ctx, db, activities...); err != nil {
+		return nil, false, err
+	}
+
+	return &contact, joined, nil
+}
+
+// captureAttributes adds the attributes of the source the contact does not have to the ones of the contact
+func captureAttributes(source model.ContactCaptureSource, contact model.Contact) (string, error) {
+	attributes := map[string]interface{}{}
+
+	if contact.Attributes != nil && *contact.Attributes != "" {
+		if err := json.Unmarshal([]byte(*contact.Attributes), &attributes); err != nil {
+			return "", err
+		}
+	}
+
+	if source.Attributes != nil && *source.Attributes != "" {
+		var sourceAttributes map[string]interface{}
+		if err := json.Unmarshal([]byte(*source.Attributes), &sourceAttributes); err != nil {
+			return "", err
+		}
+
+		for key, value := range sourceAttributes {
+			if _, ok := attributes[key]; !ok {
+				attributes[key] = value
+			}
+		}
+	}
+
+	attributesJson, err := json.Marshal(attributes)
+	if err != nil {
+		return "", err
+	}
+
+	return string(attributesJson), nil
+}
+
+// SubmitForm creates the contact of the submission when the organization does not have one with its phone number yet,
+// captures it through the form and records its consent to the marketing messages as pending, with the consent text of the
+// form as its evidence. Anyone can submit the form with any number, so the name and attributes of an existing contact are
+// left as they are and the consent is only granted once the contact confirms it from its number, see ConfirmForm.
+func SubmitForm(ctx context.Context, db *sql.DB, source model.ContactCaptureSource, submission Submission) (*model.Contact, error) {
+	if source.Type != model.ContactCaptureSourceTypeEnum_Form || !source.IsActive {
+		return nil, ErrInvalidSource
+	}
+
+	name := strings.TrimSpace(submission.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: the name is required", ErrInvalidSubmission)
+	}
+
+	phoneNumber, err := utils.NormalizePhoneNumber(submission.PhoneNumber, "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSubmission, err.Error())
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	contact, isCreated, err := findOrCreateContact(ctx, tx, source.OrganizationId, name, phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	contact, _, err = capture(ctx, tx, source, *contact, isCreated)
+	if err != nil {
+		return nil, err
+	}
+
+	consentText := ""
+	if source.ConsentText != nil {
+		consentText = *source.ConsentText
+	}
+
+	err = contact_consent_service.Request(ctx, tx, contact_consent_service.Change{
+		OrganizationId: contact.OrganizationId,
+		ContactId:      contact.UniqueId,
+		Category:       model.ContactConsentCategoryEnum_Marketing,
+		Source:         model.ContactConsentSourceEnum_Form,
+		CaptureSource:  &source,
+		Evidence: map[string]interface{}{
+			"consentText": consentText,
+			"ipAddress":   submission.IpAddress,
+			"userAgent":   submission.UserAgent,
+		},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return contact, nil
+}
+
+// ConfirmForm grants the pending consent the contact gave through the form of the code, once the contact has sent the
+// message of the confirmation link from its number. It reports whether there was a consent to confirm.
+func ConfirmForm(ctx context.Context, db *sql.DB, code string, contact model.Contact, message string, confirmedAt time.Time) (bool, error) {
+	source, err := FetchByCode(ctx, db, code, model.ContactCaptureSourceTypeEnum_Form)
+	if err != nil {
+		return false, err
+	}
+
+	// * codes are unique across organizations, a code of another organization is just text
+	if source == nil || source.OrganizationId != contact.OrganizationId {
+		return false, nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	isConfirmed, err := contact_consent_service.Confirm(ctx, tx, contact, model.ContactConsentCategoryEnum_Marketing, *source, message, confirmedAt)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return isConfirmed, nil
+}
+
+// findOrCreateContact returns the contact of the organization with the phone number, it is created when there is none.
+// It also reports whether the contact has been created.
+func findOrCreateContact(ctx context.Context, tx *sql.Tx, organizationId uuid.UUID, name, phoneNumber string) (*model.Contact, bool, error) {
+	var contact model.Contact
+
+	err := SELECT(table.Contact.AllColumns).
+		FROM(table.Contact).
+		WHERE(
+			table.Contact.OrganizationId.EQ(UUID(organizationId)).
+				AND(table.Contact.PhoneNumber.EQ(String(phoneNumber))),
+		).
+		LIMIT(1).
+		QueryContext(ctx, tx, &contact)
+
+	if err == nil {
+		return &contact, false, nil
+	}
+
+	if err.Error() != qrm.ErrNoRows.Error() {
+		return nil, false, err
+	}
+
+	emptyAttributes := "{}"
+
+	err = table.Contact.INSERT(table.Contact.MutableColumns).
+		MODEL(model.Contact{
+			OrganizationId: organizationId,
+			Name:           name,
+			PhoneNumber:    phoneNumber,
+			Attributes:     &emptyAttributes,
+			Status:         model.ContactStatusEnum_Active,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}).
+		RETURNING(table.Contact.AllColumns).
+		QueryContext(ctx, tx, &contact)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &contact, true, nil
+}
@@ -0,0 +1,491 @@
+package contact_consent_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/contact_activity_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+var ErrInvalidConsent = errors.New("invalid consent")
+
+// Categories are the categories of messages a contact consents to
+var Categories = []model.ContactConsentCategoryEnum{
+	model.ContactConsentCategoryEnum_Marketing,
+	model.ContactConsentCategoryEnum_Utility,
+}
+
+// a message made of one of these alone opts the contact out, "Stop promotions" is the opt-out button of marketing templates
+var optOutKeywords = map[string]bool{
+	"STOP":            true,
+	"STOP ALL":        true,
+	"STOP PROMOTIONS": true,
+	"UNSUBSCRIBE":     true,
+	"OPT OUT":         true,
+	"OPTOUT":          true,
+	"CANCEL":          true,
+	"END":             true,
+	"QUIT":            true,
+}
+
+// Change is a consent given or withdrawn by a contact
+type Change struct {
+	OrganizationId uuid.UUID
+	ContactId      uuid.UUID
+	// nil when the contact made the change itself
+	OrganizationMemberId *uuid.UUID
+	Category             model.ContactConsentCategoryEnum
+	Status               model.ContactConsentStatusEnum
+	Source               model.ContactConsentSourceEnum
+	// the opt-in form the consent was given through, nil for the other sources
+	CaptureSource *model.ContactCaptureSource
+	Evidence      map[string]interface{}
+	// when the consent was given or withdrawn, now when left zero
+	ChangedAt time.Time
+}
+
+type consentKey struct {
+	contactId uuid.UUID
+	category  model.ContactConsentCategoryEnum
+}
+
+// Validate checks the category and status of the change, a consent can only be given with its evidence
+func Validate(change Change) error {
+	switch change.Category {
+	case model.ContactConsentCategoryEnum_Marketing, model.ContactConsentCategoryEnum_Utility:
+	default:
+		return fmt.Errorf("%w: unknown category %s", ErrInvalidConsent, change.Category.String())
+	}
+
+	switch change.Status {
+	case model.ContactConsentStatusEnum_Granted:
+		if len(change.Evidence) == 0 {
+			return fmt.Errorf("%w: the evidence of the %s consent is required", ErrInvalidConsent, change.Category.String())
+		}
+	case model.ContactConsentStatusEnum_Revoked:
+	case model.ContactConsentStatusEnum_Pending:
+		return fmt.Errorf("%w: the %s consent can only be pending through an opt-in form", ErrInvalidConsent, change.Category.String())
+	default:
+		return fmt.Errorf("%w: unknown status %s", ErrInvalidConsent, change.Status.String())
+	}
+
+	if change.ChangedAt.After(time.Now().Add(time.Minute)) {
+		return fmt.Errorf("%w: the %s consent can not be changed in the future", ErrInvalidConsent, change.Category.String())
+	}
+
+	return nil
+}
+
+// Apply records the changes and adds them to the activity of the contacts, pending consents are only added to the activity
+// once they are confirmed. A change to the status the consent already has is ignored, so that a consent keeps the evidence
+// it was first given with. Of several changes to the same consent the last one wins.
+func Apply(ctx context.Context, db qrm.DB, changes ...Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	lastChanges := map[consentKey]int{}
+	contactIds := []Expression{}
+
+	for index, change := range changes {
+		key := consentKey{change.ContactId, change.Category}
+		if _, ok := lastChanges[key]; !ok {
+			contactIds = append(contactIds, UUID(change.ContactId))
+		}
+		lastChanges[key] = index
+	}
+
+	var existingConsents []model.ContactConsent
+
+	err := SELECT(table.ContactConsent.AllColumns).
+		FROM(table.ContactConsent).
+		WHERE(table.ContactConsent.ContactId.IN(contactIds...)).
+		QueryContext(ctx, db, &existingConsents)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return err
+	}
+
+	existingStatuses := make(map[consentKey]model.ContactConsentStatusEnum, len(existingConsents))
+	for _, consent := range existingConsents {
+		existingStatuses[consentKey{consent.ContactId, consent.Category}] = consent.Status
+	}
+
+	consentsToWrite := []model.ContactConsent{}
+	activities := []contact_activity_service.Activity{}
+
+	for index, change := range changes {
+		key := consentKey{change.ContactId, change.Category}
+		if lastChanges[key] != index {
+			continue
+		}
+
+		if status, ok := existingStatuses[key]; ok && status == change.Status {
+			continue
+		}
+
+		consent, activity, err := toConsent(change)
+		if err != nil {
+			return err
+		}
+
+		consentsToWrite = append(consentsToWrite, consent)
+		if change.Status != model.ContactConsentStatusEnum_Pending {
+			activities = append(activities, activity)
+		}
+	}
+
+	if len(consentsToWrite) == 0 {
+		return nil
+	}
+
+	_, err = table.ContactConsent.
+		INSERT(table.ContactConsent.MutableColumns).
+		MODELS(consentsToWrite).
+		ON_CONFLICT(table.ContactConsent.ContactId, table.ContactConsent.Category).
+		DO_UPDATE(SET(
+			table.ContactConsent.Status.SET(table.ContactConsent.EXCLUDED.Status),
+			table.ContactConsent.Source.SET(table.ContactConsent.EXCLUDED.Source),
+			table.ContactConsent.ChangedAt.SET(table.ContactConsent.EXCLUDED.ChangedAt),
+			table.ContactConsent.Evidence.SET(table.ContactConsent.EXCLUDED.Evidence),
+			table.ContactConsent.ContactCaptureSourceId.SET(table.ContactConsent.EXCLUDED.ContactCaptureSourceId),
+			table.ContactConsent.OrganizationMemberId.SET(table.ContactConsent.EXCLUDED.OrganizationMemberId),
+			table.ContactConsent.UpdatedAt.SET(table.ContactConsent.EXCLUDED.UpdatedAt),
+		)).
+		ExecContext(ctx, db)
+
+	if err != nil {
+		return err
+	}
+
+	return contact_activity_service.Record(ctx, db, activities...)
+}
+
+// toConsent returns the consent the change is stored as, and the activity recording it
+func toConsent(change Change) (model.ContactConsent, contact_activity_service.Activity, error) {
+	changedAt := change.ChangedAt
+	if changedAt.IsZero() {
+		changedAt = time.Now()
+	}
+
+	evidence := change.Evidence
+	if evidence == nil {
+		evidence = map[string]interface{}{}
+	}
+
+	evidenceJson, err := json.Marshal(evidence)
+	if err != nil {
+		return model.ContactConsent{}, contact_activity_service.Activity{}, err
+	}
+	stringEvidence := string(evidenceJson)
+
+	consent := model.ContactConsent{
+		CreatedAt:            time.Now(),
+		UpdatedAt:            time.Now(),
+		OrganizationId:       change.OrganizationId,
+		ContactId:            change.ContactId,
+		Category:             change.Category,
+		Status:               change.Status,
+		Source:               change.Source,
+		ChangedAt:            changedAt,
+		Evidence:             &stringEvidence,
+		OrganizationMemberId: change.OrganizationMemberId,
+	}
+
+	data := contact_activity_service.ConsentData{
+		Category:  change.Category.String(),
+		Source:    change.Source.String(),
+		ChangedAt: changedAt,
+		Evidence:  evidence,
+	}
+
+	if change.CaptureSource != nil {
+		consent.ContactCaptureSourceId = &change.CaptureSource.UniqueId
+		data.CaptureSourceId = &change.CaptureSource.UniqueId
+		data.CaptureSourceName = change.CaptureSource.Name
+	}
+
+	activityType := model.ContactActivityTypeEnum_OptedIn
+	if change.Status == model.ContactConsentStatusEnum_Revoked {
+		activityType = model.ContactActivityTypeEnum_OptedOut
+	}
+
+	return consent, contact_activity_service.Activity{
+		OrganizationId:       change.OrganizationId,
+		ContactId:            change.ContactId,
+		OrganizationMemberId: change.OrganizationMemberId,
+		Type:                 activityType,
+		Data:                 data,
+	}, nil
+}
+
+// Request records the consent as pending until the contact confirms it, for the consents given through an opt-in form. A
+// consent the contact has given already is kept, and so is one it has withdrawn with an opt-out keyword, only the contact
+// can take that back on WhatsApp.
+func Request(ctx context.Context, db qrm.DB, change Change) error {
+	var consents []model.ContactConsent
+
+	err := SELECT(table.ContactConsent.AllColumns).
+		FROM(table.ContactConsent).
+		WHERE(
+			table.ContactConsent.ContactId.EQ(UUID(change.ContactId)).
+				AND(table.ContactConsent.Category.EQ(utils.EnumExpression(change.Category.String()))),
+		).
+		QueryContext(ctx, db, &consents)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return err
+	}
+
+	if len(consents) > 0 {
+		consent := consents[0]
+		if consent.Status == model.ContactConsentStatusEnum_Granted {
+			return nil
+		}
+		if consent.Status == model.ContactConsentStatusEnum_Revoked && consent.Source == model.ContactConsentSourceEnum_Keyword {
+			return nil
+		}
+	}
+
+	change.Status = model.ContactConsentStatusEnum_Pending
+	return Apply(ctx, db, change)
+}
+
+// Confirm grants the pending consent the contact gave through the capture source, the message it confirmed the consent
+// with is added to the evidence it was given with. It reports whether there was such a consent to confirm.
+func Confirm(ctx context.Context, db qrm.DB, contact model.Contact, category model.ContactConsentCategoryEnum, captureSource model.ContactCaptureSource, message string, confirmedAt time.Time) (bool, error) {
+	var consents []model.ContactConsent
+
+	err := SELECT(table.ContactConsent.AllColumns).
+		FROM(table.ContactConsent).
+		WHERE(
+			table.ContactConsent.ContactId.EQ(UUID(contact.UniqueId)).
+				AND(table.ContactConsent.Category.EQ(utils.EnumExpression(category.String()))).
+				AND(table.ContactConsent.Status.EQ(utils.EnumExpression(model.ContactConsentStatusEnum_Pending.String()))).
+				AND(table.ContactConsent.ContactCaptureSourceId.EQ(UUID(captureSource.UniqueId))),
+		).
+		QueryContext(ctx, db, &consents)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return false, err
+	}
+
+	if len(consents) == 0 {
+		return false, nil
+	}
+
+	evidence := map[string]interface{}{}
+	if consents[0].Evidence != nil {
+		if err := json.Unmarshal([]byte(*consents[0].Evidence), &evidence); err != nil {
+			return false, err
+		}
+	}
+	evidence["confirmationMessage"] = message
+
+	err = Apply(ctx, db, Change{
+		OrganizationId: contact.OrganizationId,
+		ContactId:      contact.UniqueId,
+		Category:       category,
+		Status:         model.ContactConsentStatusEnum_Granted,
+		Source:         consents[0].Source,
+		CaptureSource:  &captureSource,
+		Evidence:       evidence,
+		ChangedAt:      confirmedAt,
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// the number of contacts GrantLegacy grants the consent of at once
+const legacyBatchSize = 1000
+
+// GrantLegacy grants the marketing consent of the contacts of the organization which have no marketing consent recorded,
+// for the organizations which collected the opt-ins of their contacts before consents were recorded. The statement of the
+// member is the evidence, and the Legacy source tells these consents apart from the ones the contacts gave. Blocked and
+// deleted contacts are left out. It returns the number of consents granted.
+func GrantLegacy(ctx context.Context, db qrm.DB, organizationId, organizationMemberId uuid.UUID, statement string, grantedAt time.Time) (int, error) {
+	evidence := map[string]interface{}{
+		"statement": statement,
+		"note":      "granted by the organization for a contact created before consents were recorded",
+	}
+
+	grantedCount := 0
+
+	for {
+		var contacts []model.Contact
+
+		err := SELECT(table.Contact.UniqueId, table.Contact.OrganizationId).
+			FROM(table.Contact).
+			WHERE(
+				table.Contact.OrganizationId.EQ(UUID(organizationId)).
+					AND(table.Contact.Status.NOT_IN(
+						utils.EnumExpression(model.ContactStatusEnum_Blocked.String()),
+						utils.EnumExpression(model.ContactStatusEnum_Deleted.String()),
+					)).
+					AND(NOT(EXISTS(
+						SELECT(table.ContactConsent.UniqueId).
+							FROM(table.ContactConsent).
+							WHERE(
+								table.ContactConsent.ContactId.EQ(table.Contact.UniqueId).
+									AND(table.ContactConsent.Category.EQ(utils.EnumExpression(model.ContactConsentCategoryEnum_Marketing.String()))),
+							),
+					))),
+			).
+			ORDER_BY(table.Contact.CreatedAt.ASC()).
+			LIMIT(legacyBatchSize).
+			QueryContext(ctx, db, &contacts)
+
+		if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+			return grantedCount, err
+		}
+
+		if len(contacts) == 0 {
+			return grantedCount, nil
+		}
+
+		changes := make([]Change, 0, len(contacts))
+		for _, contact := range contacts {
+			changes = append(changes, Change{
+				OrganizationId:       contact.OrganizationId,
+				ContactId:            contact.UniqueId,
+				OrganizationMemberId: &organizationMemberId,
+				Category:             model.ContactConsentCategoryEnum_Marketing,
+				Status:               model.ContactConsentStatusEnum_Granted,
+				Source:               model.ContactConsentSourceEnum_Legacy,
+				Evidence:             evidence,
+				ChangedAt:            grantedAt,
+			})
+		}
+
+		// * every contact of the batch gets a consent, so the next batch never selects them again
+		if err := Apply(ctx, db, changes...); err != nil {
+			return grantedCount, err
+		}
+
+		grantedCount += len(contacts)
+	}
+}
+
+// IsOptOutKeyword reports whether the text of a message is an opt-out keyword, like STOP
+func IsOptOutKeyword(text string) bool {
+	keyword := strings.ToUpper(strings.Join(strings.Fields(text), " "))
+	keyword = strings.TrimRight(keyword, ".!")
+	return optOutKeywords[keyword]
+}
+
+// OptOut withdraws every consent of the contact, the message it opted out with is the evidence
+func OptOut(ctx context.Context, db qrm.DB, contact model.Contact, evidence map[string]interface{}, optedOutAt time.Time) error {
+	changes := make([]Change, 0, len(Categories))
+
+	for _, category := range Categories {
+		changes = append(changes, Change{
+			OrganizationId: contact.OrganizationId,
+			ContactId:      contact.UniqueId,
+			Category:       category,
+			Status:         model.ContactConsentStatusEnum_Revoked,
+			Source:         model.ContactConsentSourceEnum_Keyword,
+			Evidence:       evidence,
+			ChangedAt:      optedOutAt,
+		})
+	}
+
+	return Apply(ctx, db, changes...)
+}
+
+// GrantedCondition matches the contacts which consented to the messages of the category, to be used in the queries of
+// the Contact table
+func GrantedCondition(category model.ContactConsentCategoryEnum) BoolExpression {
+	return EXISTS(
+		SELECT(table.ContactConsent.UniqueId).
+			FROM(table.ContactConsent).
+			WHERE(
+				table.ContactConsent.ContactId.EQ(table.Contact.UniqueId).
+					AND(table.ContactConsent.Category.EQ(utils.EnumExpression(category.String()))).
+					AND(table.ContactConsent.Status.EQ(utils.EnumExpression(model.ContactConsentStatusEnum_Granted.String()))),
+			),
+	)
+}
+
+// FetchConsents returns the consents of the contact, by category
+func FetchConsents(ctx context.Context, db qrm.Queryable, contactId uuid.UUID) ([]model.ContactConsent, error) {
+	var consents []model.ContactConsent
+
+	err := SELECT(table.ContactConsent.AllColumns).
+		FROM(table.ContactConsent).
+		WHERE(table.ContactConsent.ContactId.EQ(UUID(contactId))).
+		ORDER_BY(table.ContactConsent.Category.ASC()).
+		QueryContext(ctx, db, &consents)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return consents, nil
+}
+
+// FromSchema converts the consent given in a request to a change of the contact
+func FromSchema(consent api_types.NewContactConsentSchema, contact model.Contact, organizationMemberId *uuid.UUID) Change {
+	change := Change{
+		OrganizationId:       contact.OrganizationId,
+		ContactId:            contact.UniqueId,
+		OrganizationMemberId: organizationMemberId,
+		Category:             model.ContactConsentCategoryEnum(consent.Category),
+		Status:               model.ContactConsentStatusEnum(consent.Status),
+		Source:               model.ContactConsentSourceEnum_API,
+	}
+
+	if consent.Evidence != nil {
+		change.Evidence = *consent.Evidence
+	}
+
+	if consent.ChangedAt != nil {
+		change.ChangedAt = *consent.ChangedAt
+	}
+
+	return change
+}
+
+// ToSchema converts the consent to the schema returned by the API
+func ToSchema(consent model.ContactConsent) api_types.ContactConsentSchema {
+	evidence := map[string]interface{}{}
+	if consent.Evidence != nil {
+		json.Unmarshal([]byte(*consent.Evidence), &evidence)
+	}
+
+	schema := api_types.ContactConsentSchema{
+		Category:  api_types.ContactConsentCategoryEnum(consent.Category.String()),
+		Status:    api_types.ContactConsentStatusEnum(consent.Status.String()),
+		Source:    api_types.ContactConsentSourceEnum(consent.Source.String()),
+		ChangedAt: consent.ChangedAt,
+		Evidence:  evidence,
+		UpdatedAt: consent.UpdatedAt,
+	}
+
+	if consent.ContactCaptureSourceId != nil {
+		captureSourceId := consent.ContactCaptureSourceId.String()
+		schema.CaptureSourceId = &captureSourceId
+	}
+
+	if consent.OrganizationMemberId != nil {
+		organizationMemberId := consent.OrganizationMemberId.String()
+		schema.RecordedByMemberId = &organizationMemberId
+	}
+
+	return schema
+}
@@ -0,0 +1,203 @@
+package contact_duplicate_service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/core/background_job_service"
+	"github.com/wapikit/wapikit/internal/core/contact_lifecycle_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// the contacts of the organization are read in pages of this size
+const pageSize = 1000
+
+var nonDigitPattern = regexp.MustCompile(`[^0-9]`)
+
+// Parameters are stored with the job when the scan is queued
+type Parameters struct {
+	// merge each group of duplicates into its oldest contact, instead of only reporting the groups
+	Merge bool `json:"merge"`
+}
+
+// Group is a set of contacts whose phone numbers are the same once normalized
+type Group struct {
+	PhoneNumber string      `json:"phoneNumber"`
+	ContactIds  []uuid.UUID `json:"contactIds"`
+}
+
+// Result is the summary stored with the completed job
+type Result struct {
+	Groups []Group `json:"groups"`
+	// the number of contacts merged into another one, only when the scan was asked to merge
+	Merged int `json:"merged"`
+}
+
+// NewJobHandler returns the handler running the duplicate scan jobs
+func NewJobHandler(db *sql.DB) background_job_service.Handler {
+	return func(ctx context.Context, job model.BackgroundJob, progress *background_job_service.Progress) (interface{}, error) {
+		var parameters Parameters
+		if err := json.Unmarshal([]byte(job.Parameters), &parameters); err != nil {
+			return nil, err
+		}
+
+		groups, scannedContacts, err := findDuplicates(ctx, db, job.OrganizationId, progress)
+		if err != nil {
+			return nil, err
+		}
+
+		result := Result{Groups: make([]Group, 0, len(groups))}
+
+		for _, group := range groups {
+			result.Groups = append(result.Groups, Group{
+				PhoneNumber: group.phoneNumber,
+				ContactIds:  group.contactIds(),
+			})
+		}
+
+		if !parameters.Merge {
+			return result, nil
+		}
+
+		// * each merged group counts as one more item of the job, after the contacts which were scanned
+		if err := progress.SetTotal(ctx, scannedContacts+len(groups)); err != nil {
+			return nil, err
+		}
+
+		for index, group := range groups {
+			// * the oldest contact has the longest history, the others are merged into it and it keeps the normalized number
+			contact := group.contacts[0]
+			if _, err := utils.NormalizePhoneNumber(contact.PhoneNumber, ""); err == nil {
+				contact.PhoneNumber = group.phoneNumber
+			}
+
+			_, err := contact_lifecycle_service.Merge(ctx, db, contact, group.contacts[1:], contact_lifecycle_service.MergeReasonDuplicateScan, job.CreatedByOrganizationMemberId)
+			if err != nil {
+				if err := progress.ItemFailed(ctx, index+1, group.phoneNumber, err.Error()); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			result.Merged += len(group.contacts) - 1
+
+			if err := progress.ItemsProcessed(ctx, 1); err != nil {
+				return nil, err
+			}
+		}
+
+		return result, nil
+	}
+}
+
+// group holds the contacts of a normalized phone number, oldest first
+type group struct {
+	phoneNumber string
+	contacts    []model.Contact
+}
+
+func (g group) contactIds() []uuid.UUID {
+	contactIds := make([]uuid.UUID, 0, len(g.contacts))
+	for _, contact := range g.contacts {
+		contactIds = append(contactIds, contact.UniqueId)
+	}
+	return contactIds
+}
+
+// findDuplicates reads the contacts of the organization page by page and groups them by their normalized phone number,
+// only the groups of more than one contact are returned along with the number of contacts scanned
+func findDuplicates(ctx context.Context, db *sql.DB, organizationId uuid.UUID, progress *background_job_service.Progress) ([]group, int, error) {
+	organizationContacts := table.Contact.OrganizationId.EQ(UUID(organizationId)).
+		AND(table.Contact.Status.NOT_EQ(utils.EnumExpression(model.ContactStatusEnum_Deleted.String())))
+
+	var count struct {
+		Count int
+	}
+
+	err := SELECT(COUNT(table.Contact.UniqueId).AS("count")).
+		FROM(table.Contact).
+		WHERE(organizationContacts).
+		QueryContext(ctx, db, &count)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := progress.SetTotal(ctx, count.Count); err != nil {
+		return nil, 0, err
+	}
+
+	contactsByPhoneNumber := map[string][]model.Contact{}
+	lastContactId := uuid.Nil
+
+	for {
+		var contacts []model.Contact
+
+		err := SELECT(table.Contact.AllColumns).
+			FROM(table.Contact).
+			WHERE(organizationContacts.AND(table.Contact.UniqueId.GT(UUID(lastContactId)))).
+			ORDER_BY(table.Contact.UniqueId.ASC()).
+			LIMIT(pageSize).
+			QueryContext(ctx, db, &contacts)
+
+		if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+			return nil, 0, err
+		}
+
+		for _, contact := range contacts {
+			phoneNumber := NormalizedPhoneNumber(contact.PhoneNumber)
+			if phoneNumber == "" {
+				continue
+			}
+			contactsByPhoneNumber[phoneNumber] = append(contactsByPhoneNumber[phoneNumber], contact)
+		}
+
+		if err := progress.ItemsProcessed(ctx, len(contacts)); err != nil {
+			return nil, 0, err
+		}
+
+		if len(contacts) < pageSize {
+			break
+		}
+
+		lastContactId = contacts[len(contacts)-1].UniqueId
+	}
+
+	groups := []group{}
+
+	for phoneNumber, contacts := range contactsByPhoneNumber {
+		if len(contacts) < 2 {
+			continue
+		}
+
+		sort.Slice(contacts, func(i, j int) bool {
+			return contacts[i].CreatedAt.Before(contacts[j].CreatedAt)
+		})
+
+		groups = append(groups, group{phoneNumber: phoneNumber, contacts: contacts})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].phoneNumber < groups[j].phoneNumber
+	})
+
+	return groups, count.Count, nil
+}
+
+// NormalizedPhoneNumber returns the phone number in the form contacts are stored in. Numbers which can not be parsed are
+// reduced to their digits, so that differently formatted copies of them are still found.
+func NormalizedPhoneNumber(phoneNumber string) string {
+	if normalizedPhoneNumber, err := utils.NormalizePhoneNumber(phoneNumber, ""); err == nil {
+		return normalizedPhoneNumber
+	}
+	return nonDigitPattern.ReplaceAllString(strings.TrimSpace(phoneNumber), "")
+}
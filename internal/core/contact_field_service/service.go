@@ -0,0 +1,316 @@
+package contact_field_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/api_types"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// dates are stored in this layout, so that they sort and compare as text in segment conditions
+const dateLayout = "2006-01-02"
+
+// keys are usable as the template variable contact.<key>, so they are limited to the characters of variables
+var keyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,63}$`)
+
+// the built in fields of the contact are available as variables under these keys, attributes can not shadow them
+var reservedKeys = map[string]bool{
+	"name":        true,
+	"phoneNumber": true,
+}
+
+var (
+	ErrInvalidField      = errors.New("invalid contact field")
+	ErrInvalidAttributes = errors.New("invalid contact attributes")
+)
+
+// Field is a custom field of the organization with its default value and options decoded
+type Field struct {
+	model.ContactField
+	Default interface{}
+	Options []string
+}
+
+// FetchFields returns the custom fields of the organization, in the order they were defined
+func FetchFields(ctx context.Context, db qrm.Queryable, organizationId uuid.UUID) ([]Field, error) {
+	var contactFields []model.ContactField
+
+	err := SELECT(table.ContactField.AllColumns).
+		FROM(table.ContactField).
+		WHERE(table.ContactField.OrganizationId.EQ(UUID(organizationId))).
+		ORDER_BY(table.ContactField.CreatedAt.ASC()).
+		QueryContext(ctx, db, &contactFields)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	fields := make([]Field, 0, len(contactFields))
+	for _, contactField := range contactFields {
+		field, err := Decode(contactField)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// Decode reads the default value and the options stored with the field
+func Decode(contactField model.ContactField) (Field, error) {
+	field := Field{ContactField: contactField, Options: []string{}}
+
+	if contactField.DefaultValue != nil {
+		if err := json.Unmarshal([]byte(*contactField.DefaultValue), &field.Default); err != nil {
+			return field, err
+		}
+	}
+
+	if contactField.Options != nil {
+		if err := json.Unmarshal([]byte(*contactField.Options), &field.Options); err != nil {
+			return field, err
+		}
+	}
+
+	return field, nil
+}
+
+// NewField validates the definition of a field, the default value is converted to the type of the field. Errors caused by
+// the definition wrap ErrInvalidField.
+func NewField(organizationId uuid.UUID, key, label string, fieldType api_types.ContactFieldTypeEnum, isRequired bool, defaultValue *interface{}, options []string) (model.ContactField, error) {
+	contactField := model.ContactField{
+		OrganizationId: organizationId,
+		Key:            strings.TrimSpace(key),
+		Label:          strings.TrimSpace(label),
+		IsRequired:     isRequired,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if !keyPattern.MatchString(contactField.Key) {
+		return contactField, fmt.Errorf("%w: the key must start with a letter and hold only letters, digits and underscores", ErrInvalidField)
+	}
+
+	if reservedKeys[contactField.Key] {
+		return contactField, fmt.Errorf("%w: %s is a built in field of contacts", ErrInvalidField, contactField.Key)
+	}
+
+	if contactField.Label == "" {
+		return contactField, fmt.Errorf("%w: the label is required", ErrInvalidField)
+	}
+
+	if err := contactField.Type.Scan(string(fieldType)); err != nil {
+		return contactField, fmt.Errorf("%w: unknown type %q", ErrInvalidField, fieldType)
+	}
+
+	cleanOptions := []string{}
+	seenOptions := map[string]bool{}
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" || seenOptions[strings.ToLower(option)] {
+			continue
+		}
+		seenOptions[strings.ToLower(option)] = true
+		cleanOptions = append(cleanOptions, option)
+	}
+
+	if contactField.Type == model.ContactFieldTypeEnum_Enum && len(cleanOptions) == 0 {
+		return contactField, fmt.Errorf("%w: enum fields need at least one option", ErrInvalidField)
+	}
+
+	if contactField.Type != model.ContactFieldTypeEnum_Enum && len(cleanOptions) > 0 {
+		return contactField, fmt.Errorf("%w: only enum fields have options", ErrInvalidField)
+	}
+
+	if len(cleanOptions) > 0 {
+		optionsJson, _ := json.Marshal(cleanOptions)
+		stringOptions := string(optionsJson)
+		contactField.Options = &stringOptions
+	}
+
+	if defaultValue != nil && !isEmpty(*defaultValue) {
+		value, err := coerce(contactField.Type, cleanOptions, *defaultValue)
+		if err != nil {
+			return contactField, fmt.Errorf("%w: the default value %s", ErrInvalidField, err.Error())
+		}
+		defaultJson, _ := json.Marshal(value)
+		stringDefault := string(defaultJson)
+		contactField.DefaultValue = &stringDefault
+	}
+
+	return contactField, nil
+}
+
+// FillDefault gives the default value of the field to the contacts of the organization which have no value for it yet, so
+// that segments and templates see the same value for contacts written before the field was defined
+func FillDefault(ctx context.Context, db qrm.Executable, contactField model.ContactField) error {
+	if contactField.DefaultValue == nil {
+		return nil
+	}
+
+	args := RawArgs{"#key": contactField.Key, "#default": *contactField.DefaultValue}
+
+	_, err := table.Contact.UPDATE(table.Contact.Attributes).
+		SET(StringExp(Raw(`jsonb_build_object(#key::text, #default::jsonb) || COALESCE("Contact"."Attributes", '{}'::jsonb)`, args))).
+		WHERE(
+			table.Contact.OrganizationId.EQ(UUID(contactField.OrganizationId)).
+				AND(BoolExp(Raw(`NOT COALESCE("Contact"."Attributes" ? #key::text, false)`, args))),
+		).
+		ExecContext(ctx, db)
+
+	return err
+}
+
+// Validate checks the attributes written to a contact against the fields of the organization. Values are converted to the
+// type of their field, missing fields get their default value and a missing required field is an error. Attributes which
+// are not fields of the organization are kept as they are. Errors caused by the attributes wrap ErrInvalidAttributes.
+func Validate(fields []Field, attributes map[string]interface{}) error {
+	if err := ValidateValues(fields, attributes); err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		if _, ok := attributes[field.Key]; ok {
+			continue
+		}
+
+		if field.Default != nil {
+			attributes[field.Key] = field.Default
+			continue
+		}
+
+		if field.IsRequired {
+			return fmt.Errorf("%w: %s is required", ErrInvalidAttributes, field.Key)
+		}
+	}
+
+	return nil
+}
+
+// ValidateValues converts the values of the attributes present to the type of their field, without requiring any field. It
+// is meant for attributes merged into the ones a contact already has.
+func ValidateValues(fields []Field, attributes map[string]interface{}) error {
+	for _, field := range fields {
+		value, ok := attributes[field.Key]
+		if !ok {
+			continue
+		}
+
+		if isEmpty(value) {
+			delete(attributes, field.Key)
+			continue
+		}
+
+		coercedValue, err := coerce(field.Type, field.Options, value)
+		if err != nil {
+			return fmt.Errorf("%w: %s %s", ErrInvalidAttributes, field.Key, err.Error())
+		}
+		attributes[field.Key] = coercedValue
+	}
+
+	return nil
+}
+
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	stringValue, ok := value.(string)
+	return ok && strings.TrimSpace(stringValue) == ""
+}
+
+// coerce converts the value to the type of the field, values of imports and forms arrive as text whatever their type is
+func coerce(fieldType model.ContactFieldTypeEnum, options []string, value interface{}) (interface{}, error) {
+	stringValue, isString := value.(string)
+	stringValue = strings.TrimSpace(stringValue)
+
+	switch fieldType {
+	case model.ContactFieldTypeEnum_String:
+		switch value := value.(type) {
+		case string:
+			return value, nil
+		case float64, bool:
+			return fmt.Sprint(value), nil
+		}
+		return nil, errors.New("must be text")
+
+	case model.ContactFieldTypeEnum_Number:
+		if number, ok := value.(float64); ok {
+			return number, nil
+		}
+		if isString {
+			if number, err := strconv.ParseFloat(stringValue, 64); err == nil {
+				return number, nil
+			}
+		}
+		return nil, errors.New("must be a number")
+
+	case model.ContactFieldTypeEnum_Boolean:
+		if boolean, ok := value.(bool); ok {
+			return boolean, nil
+		}
+		switch strings.ToLower(stringValue) {
+		case "true", "yes", "1":
+			return true, nil
+		case "false", "no", "0":
+			return false, nil
+		}
+		return nil, errors.New("must be true or false")
+
+	case model.ContactFieldTypeEnum_Date:
+		if isString {
+			for _, layout := range []string{dateLayout, time.RFC3339} {
+				if date, err := time.Parse(layout, stringValue); err == nil {
+					return date.Format(dateLayout), nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("must be a date like %s", dateLayout)
+
+	case model.ContactFieldTypeEnum_Enum:
+		if isString {
+			for _, option := range options {
+				if strings.EqualFold(option, stringValue) {
+					return option, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("must be one of %s", strings.Join(options, ", "))
+	}
+
+	return nil, fmt.Errorf("has the unknown type %s", fieldType)
+}
+
+// ToSchema converts the field to the schema returned by the API
+func ToSchema(field Field) api_types.ContactFieldSchema {
+	fieldToReturn := api_types.ContactFieldSchema{
+		UniqueId:   field.UniqueId.String(),
+		Key:        field.Key,
+		Label:      field.Label,
+		Type:       api_types.ContactFieldTypeEnum(field.Type.String()),
+		IsRequired: field.IsRequired,
+		Options:    field.Options,
+		CreatedAt:  field.CreatedAt,
+		UpdatedAt:  field.UpdatedAt,
+	}
+
+	if field.Default != nil {
+		defaultValue := field.Default
+		fieldToReturn.DefaultValue = &defaultValue
+	}
+
+	return fieldToReturn
+}
@@ -0,0 +1,188 @@
+package contact_import_service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type FileFormat string
+
+const (
+	FileFormatCsv  FileFormat = "csv"
+	FileFormatXlsx FileFormat = "xlsx"
+)
+
+// errInvalidRow is returned for a row which could not be read, the rows after it can still be read
+var errInvalidRow = errors.New("the row could not be read")
+
+// rowReader reads the rows of the uploaded file one at a time, so that files with hundreds of thousands of rows are never
+// held in memory. io.EOF is returned after the last row.
+type rowReader interface {
+	Read() ([]string, error)
+	Close() error
+}
+
+// FileFormatOf returns the format of the file by its name, false when contacts can not be imported from it
+func FileFormatOf(fileName string) (FileFormat, bool) {
+	switch {
+	case strings.HasSuffix(strings.ToLower(fileName), ".csv"):
+		return FileFormatCsv, true
+	case strings.HasSuffix(strings.ToLower(fileName), ".xlsx"):
+		return FileFormatXlsx, true
+	default:
+		return "", false
+	}
+}
+
+func openRowReader(parameters Parameters) (rowReader, error) {
+	switch parameters.FileFormat {
+	case FileFormatCsv:
+		return openCsvReader(parameters.FilePath, parameters.Delimiter)
+	case FileFormatXlsx:
+		return openXlsxReader(parameters.FilePath)
+	default:
+		return nil, errors.New("unsupported file format " + string(parameters.FileFormat))
+	}
+}
+
+type csvRowReader struct {
+	file   *os.File
+	reader *csv.Reader
+}
+
+func openCsvReader(filePath, delimiter string) (*csvRowReader, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	bufferedFile := bufio.NewReader(file)
+
+	// * spreadsheet applications prefix the csv files they export with a byte order mark
+	if bom, err := bufferedFile.Peek(3); err == nil && string(bom) == "\xef\xbb\xbf" {
+		bufferedFile.Discard(3)
+	}
+
+	reader := csv.NewReader(bufferedFile)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	if delimiter != "" {
+		reader.Comma = []rune(delimiter)[0]
+	}
+
+	return &csvRowReader{
+		file:   file,
+		reader: reader,
+	}, nil
+}
+
+func (r *csvRowReader) Read() ([]string, error) {
+	row, err := r.reader.Read()
+	if err != nil {
+		var parseError *csv.ParseError
+		if errors.As(err, &parseError) {
+			return nil, errInvalidRow
+		}
+		return nil, err
+	}
+
+	return row, nil
+}
+
+func (r *csvRowReader) Close() error {
+	return r.file.Close()
+}
+
+// xlsxRowReader reads the first sheet of the workbook, excelize streams the rows of the sheet instead of loading it whole
+type xlsxRowReader struct {
+	file *excelize.File
+	rows *excelize.Rows
+}
+
+func openXlsxReader(filePath string) (*xlsxRowReader, error) {
+	file, err := excelize.OpenFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		file.Close()
+		return nil, errors.New("the workbook has no sheets")
+	}
+
+	rows, err := file.Rows(sheets[0])
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &xlsxRowReader{
+		file: file,
+		rows: rows,
+	}, nil
+}
+
+func (r *xlsxRowReader) Read() ([]string, error) {
+	if !r.rows.Next() {
+		if err := r.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	return r.rows.Columns()
+}
+
+func (r *xlsxRowReader) Close() error {
+	r.rows.Close()
+	return r.file.Close()
+}
+
+// isEmptyRow matches the blank rows spreadsheets commonly end with
+func isEmptyRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// countRows returns the number of non empty rows after the header, it reads the file once without parsing the contacts
+func countRows(parameters Parameters) (int, error) {
+	reader, err := openRowReader(parameters)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	count := 0
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != errInvalidRow {
+			return 0, err
+		}
+		if err == errInvalidRow || !isEmptyRow(row) {
+			count++
+		}
+	}
+
+	// * the header is not a contact
+	if count > 0 {
+		count--
+	}
+
+	return count, nil
+}
@@ -0,0 +1,763 @@
+package contact_import_service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/background_job_service"
+	"github.com/wapikit/wapikit/internal/core/contact_activity_service"
+	"github.com/wapikit/wapikit/internal/core/contact_consent_service"
+	"github.com/wapikit/wapikit/internal/core/contact_field_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+const (
+	// contacts are written in batches of this many rows
+	batchSize = 1000
+	// the phone number is unique across organizations too, this is the index violated by a number of another organization
+	phoneNumberIndexName = "ContactPhoneNumberIndex"
+)
+
+// ColumnMapping names the columns of the file the contacts are read from, column names are matched case insensitively
+type ColumnMapping struct {
+	Phone string `json:"phone"`
+	Name  string `json:"name,omitempty"`
+	// attribute name to the column holding its value
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// a column holding the attributes as a JSON object, the mapped attributes are added to them
+	AttributesJson string `json:"attributesJson,omitempty"`
+	// consent category to the column holding the consent of the contact, yes grants it and no revokes it
+	Consents map[string]string `json:"consents,omitempty"`
+	// the column of the date the consents were given, the date of the import when not mapped
+	ConsentedAt string `json:"consentedAt,omitempty"`
+	// the column holding the proof of the consents, the import and the row are recorded as their evidence too
+	ConsentEvidence string `json:"consentEvidence,omitempty"`
+}
+
+// Validate checks the consent categories of the mapping, the columns are checked against the file by the job
+func (mapping ColumnMapping) Validate() error {
+	for category := range mapping.Consents {
+		if !isConsentCategory(category) {
+			return fmt.Errorf("unknown consent category %q", category)
+		}
+	}
+	return nil
+}
+
+// DefaultColumnMapping reads the columns of the files exported from the contacts page
+var DefaultColumnMapping = ColumnMapping{
+	Phone:          "phone",
+	Name:           "name",
+	AttributesJson: "attributes",
+}
+
+// Parameters are stored with the import job
+type Parameters struct {
+	FilePath       string                                      `json:"filePath"`
+	FileFormat     FileFormat                                  `json:"fileFormat"`
+	Delimiter      string                                      `json:"delimiter,omitempty"`
+	ColumnMapping  ColumnMapping                               `json:"columnMapping"`
+	DefaultCountry string                                      `json:"defaultCountry,omitempty"`
+	OnConflict     api_types.ContactImportConflictStrategyEnum `json:"onConflict"`
+	ListIds        []uuid.UUID                                 `json:"listIds,omitempty"`
+}
+
+// Result is the summary stored with the completed job
+type Result struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+}
+
+// columnIndexes are the positions of the mapped columns in the rows, -1 for columns which are not mapped
+type columnIndexes struct {
+	phone           int
+	name            int
+	attributesJson  int
+	attributes      map[string]int
+	consents        map[model.ContactConsentCategoryEnum]int
+	consentedAt     int
+	consentEvidence int
+}
+
+// importRow is a validated row of the file
+type importRow struct {
+	number     int
+	raw        string
+	contact    model.Contact
+	attributes map[string]interface{}
+	// the consents of the row, the contact is set once it is written
+	consents []contact_consent_service.Change
+}
+
+type importer struct {
+	db         *sql.DB
+	job        model.BackgroundJob
+	parameters Parameters
+	progress   *background_job_service.Progress
+	result     Result
+	// the custom contact fields of the organization, the attributes of the rows are checked against them
+	fields []contact_field_service.Field
+	// the row number each phone number was first read from, to catch duplicates within the file
+	seenPhoneNumbers map[string]int
+	// the names of the lists the contacts are added to, for the activity of the contacts
+	listNames map[uuid.UUID]string
+}
+
+// NewJobHandler returns the handler running the contact import jobs
+func NewJobHandler(db *sql.DB) background_job_service.Handler {
+	return func(ctx context.Context, job model.BackgroundJob, progress *background_job_service.Progress) (interface{}, error) {
+		var parameters Parameters
+		if err := json.Unmarshal([]byte(job.Parameters), &parameters); err != nil {
+			return nil, err
+		}
+
+		// * the upload is only needed by this job, failed imports are uploaded again
+		defer os.Remove(parameters.FilePath)
+
+		im := &importer{
+			db:               db,
+			job:              job,
+			parameters:       parameters,
+			progress:         progress,
+			seenPhoneNumbers: map[string]int{},
+		}
+
+		if err := im.run(ctx); err != nil {
+			return nil, err
+		}
+
+		return im.result, nil
+	}
+}
+
+func (im *importer) run(ctx context.Context) error {
+	totalRows, err := countRows(im.parameters)
+	if err != nil {
+		return err
+	}
+
+	if err := im.progress.SetTotal(ctx, totalRows); err != nil {
+		return err
+	}
+
+	reader, err := openRowReader(im.parameters)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return errors.New("the file is empty")
+	}
+	if err != nil {
+		return err
+	}
+
+	columns, err := indexColumns(header, im.parameters.ColumnMapping)
+	if err != nil {
+		return err
+	}
+
+	im.fields, err = contact_field_service.FetchFields(ctx, im.db, im.job.OrganizationId)
+	if err != nil {
+		return err
+	}
+
+	im.listNames, err = fetchListNames(ctx, im.db, im.job.OrganizationId, im.parameters.ListIds)
+	if err != nil {
+		return err
+	}
+
+	batch := make([]importRow, 0, batchSize)
+	// * the header is the first row
+	rowNumber := 1
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		rowNumber++
+
+		if err == errInvalidRow {
+			if err := im.fail(ctx, rowNumber, "", "the row is not valid CSV"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if isEmptyRow(row) {
+			continue
+		}
+
+		contact, attributes, reason := im.parseRow(row, columns)
+		if reason != "" {
+			if err := im.fail(ctx, rowNumber, strings.Join(row, ","), reason); err != nil {
+				return err
+			}
+			continue
+		}
+
+		consents, reason := im.parseConsents(row, columns, rowNumber)
+		if reason != "" {
+			if err := im.fail(ctx, rowNumber, strings.Join(row, ","), reason); err != nil {
+				return err
+			}
+			continue
+		}
+
+		im.seenPhoneNumbers[contact.PhoneNumber] = rowNumber
+
+		batch = append(batch, importRow{
+			number:     rowNumber,
+			raw:        strings.Join(row, ","),
+			contact:    *contact,
+			attributes: attributes,
+			consents:   consents,
+		})
+
+		if len(batch) == batchSize {
+			if err := im.writeBatch(ctx, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := im.writeBatch(ctx, batch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func indexColumns(header []string, mapping ColumnMapping) (*columnIndexes, error) {
+	positions := map[string]int{}
+	for index, name := range header {
+		positions[strings.ToLower(strings.TrimSpace(name))] = index
+	}
+
+	find := func(column string) (int, error) {
+		if column == "" {
+			return -1, nil
+		}
+
+		index, ok := positions[strings.ToLower(strings.TrimSpace(column))]
+		if !ok {
+			return -1, fmt.Errorf("the file has no column named %q", column)
+		}
+
+		return index, nil
+	}
+
+	if mapping.Phone == "" {
+		return nil, errors.New("the phone number column is required")
+	}
+
+	if err := mapping.Validate(); err != nil {
+		return nil, err
+	}
+
+	columns := &columnIndexes{
+		attributes: map[string]int{},
+		consents:   map[model.ContactConsentCategoryEnum]int{},
+	}
+
+	var err error
+
+	if columns.phone, err = find(mapping.Phone); err != nil {
+		return nil, err
+	}
+
+	if columns.name, err = find(mapping.Name); err != nil {
+		return nil, err
+	}
+
+	if columns.attributesJson, err = find(mapping.AttributesJson); err != nil {
+		return nil, err
+	}
+
+	for attribute, column := range mapping.Attributes {
+		if columns.attributes[attribute], err = find(column); err != nil {
+			return nil, err
+		}
+	}
+
+	for category, column := range mapping.Consents {
+		if columns.consents[model.ContactConsentCategoryEnum(category)], err = find(column); err != nil {
+			return nil, err
+		}
+	}
+
+	if columns.consentedAt, err = find(mapping.ConsentedAt); err != nil {
+		return nil, err
+	}
+
+	if columns.consentEvidence, err = find(mapping.ConsentEvidence); err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+// parseRow validates the row and returns the contact read from it with its attributes, or the reason the row can not be
+// imported. Required fields and defaults are left to writeBatch, as they only apply to the contacts the import creates.
+func (im *importer) parseRow(row []string, columns *columnIndexes) (*model.Contact, map[string]interface{}, string) {
+	valueAt := func(index int) string {
+		if index < 0 || index >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[index])
+	}
+
+	rawPhoneNumber := valueAt(columns.phone)
+	if rawPhoneNumber == "" {
+		return nil, nil, "the phone number is missing"
+	}
+
+	phoneNumber, err := utils.NormalizePhoneNumber(rawPhoneNumber, im.parameters.DefaultCountry)
+	if err != nil {
+		return nil, nil, fmt.Sprintf("%s is not a valid phone number", rawPhoneNumber)
+	}
+
+	if firstRowNumber, ok := im.seenPhoneNumbers[phoneNumber]; ok {
+		return nil, nil, fmt.Sprintf("the phone number is a duplicate of row %d", firstRowNumber)
+	}
+
+	attributes := map[string]interface{}{}
+
+	if attributesJson := valueAt(columns.attributesJson); attributesJson != "" {
+		if err := json.Unmarshal([]byte(attributesJson), &attributes); err != nil {
+			return nil, nil, "the attributes are not a JSON object"
+		}
+	}
+
+	for attribute, index := range columns.attributes {
+		if value := valueAt(index); value != "" {
+			attributes[attribute] = value
+		}
+	}
+
+	if err := contact_field_service.ValidateValues(im.fields, attributes); err != nil {
+		return nil, nil, strings.TrimPrefix(err.Error(), contact_field_service.ErrInvalidAttributes.Error()+": ")
+	}
+
+	attributesJson, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, nil, "the attributes are not a JSON object"
+	}
+
+	name := valueAt(columns.name)
+	if name == "" {
+		name = phoneNumber
+	}
+
+	stringAttributes := string(attributesJson)
+
+	return &model.Contact{
+		OrganizationId: im.job.OrganizationId,
+		Name:           name,
+		PhoneNumber:    phoneNumber,
+		Attributes:     &stringAttributes,
+		Status:         model.ContactStatusEnum_Active,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}, attributes, ""
+}
+
+// parseConsents returns the consents of the row, or the reason the row can not be imported. Rows leaving the consent
+// of a category empty do not change it.
+func (im *importer) parseConsents(row []string, columns *columnIndexes, rowNumber int) ([]contact_consent_service.Change, string) {
+	valueAt := func(index int) string {
+		if index < 0 || index >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[index])
+	}
+
+	consentedAt := time.Now()
+	if rawConsentedAt := valueAt(columns.consentedAt); rawConsentedAt != "" {
+		var err error
+		if consentedAt, err = parseDate(rawConsentedAt); err != nil {
+			return nil, fmt.Sprintf("%s is not a valid date", rawConsentedAt)
+		}
+	}
+
+	evidence := map[string]interface{}{
+		"importJobId": im.job.UniqueId.String(),
+		"row":         rowNumber,
+	}
+	if rawEvidence := valueAt(columns.consentEvidence); rawEvidence != "" {
+		evidence["evidence"] = rawEvidence
+	}
+
+	consents := []contact_consent_service.Change{}
+
+	for category, index := range columns.consents {
+		value := valueAt(index)
+		if value == "" {
+			continue
+		}
+
+		var status model.ContactConsentStatusEnum
+		switch strings.ToLower(value) {
+		case "yes", "y", "true", "1", "granted":
+			status = model.ContactConsentStatusEnum_Granted
+		case "no", "n", "false", "0", "revoked":
+			status = model.ContactConsentStatusEnum_Revoked
+		default:
+			return nil, fmt.Sprintf("%s is not a valid %s consent, it must be yes or no", value, category.String())
+		}
+
+		change := contact_consent_service.Change{
+			OrganizationId:       im.job.OrganizationId,
+			OrganizationMemberId: im.job.CreatedByOrganizationMemberId,
+			Category:             category,
+			Status:               status,
+			Source:               model.ContactConsentSourceEnum_Import,
+			Evidence:             evidence,
+			ChangedAt:            consentedAt,
+		}
+
+		if err := contact_consent_service.Validate(change); err != nil {
+			return nil, strings.TrimPrefix(err.Error(), contact_consent_service.ErrInvalidConsent.Error()+": ")
+		}
+
+		consents = append(consents, change)
+	}
+
+	return consents, ""
+}
+
+// parseDate reads the dates of the files, either RFC 3339 timestamps or plain dates
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+func isConsentCategory(category string) bool {
+	for _, consentCategory := range contact_consent_service.Categories {
+		if consentCategory.String() == category {
+			return true
+		}
+	}
+	return false
+}
+
+// writeBatch creates or updates the contacts of the rows and adds them to the lists of the import
+func (im *importer) writeBatch(ctx context.Context, batch []importRow) error {
+	existingContactIds, err := im.fetchExistingContactIds(ctx, batch)
+	if err != nil {
+		return err
+	}
+
+	existingContacts := make(map[uuid.UUID]bool, len(existingContactIds))
+	for _, contactId := range existingContactIds {
+		existingContacts[contactId] = true
+	}
+
+	rowsToWrite := make([]importRow, 0, len(batch))
+	contactIds := make([]uuid.UUID, 0, len(batch))
+	invalidRows := 0
+
+	for _, row := range batch {
+		contactId, exists := existingContactIds[row.contact.PhoneNumber]
+		if exists && im.parameters.OnConflict != api_types.Upsert {
+			contactIds = append(contactIds, contactId)
+			im.result.Skipped++
+			continue
+		}
+
+		// * merged attributes keep the values the contact has, new contacts need the required fields and get the defaults
+		if !exists {
+			if err := contact_field_service.Validate(im.fields, row.attributes); err != nil {
+				invalidRows++
+				if err := im.fail(ctx, row.number, row.raw, strings.TrimPrefix(err.Error(), contact_field_service.ErrInvalidAttributes.Error()+": ")); err != nil {
+					return err
+				}
+				continue
+			}
+
+			attributesJson, err := json.Marshal(row.attributes)
+			if err != nil {
+				return err
+			}
+			stringAttributes := string(attributesJson)
+			row.contact.Attributes = &stringAttributes
+		}
+
+		rowsToWrite = append(rowsToWrite, row)
+	}
+
+	failedRows := 0
+
+	writtenContacts, err := im.writeContacts(ctx, rowsToWrite)
+	if err != nil {
+		// * one bad row fails the whole insert, the rows are written one by one to find it
+		for _, row := range rowsToWrite {
+			rowContacts, err := im.writeContacts(ctx, []importRow{row})
+			if err != nil {
+				failedRows++
+				if err := im.fail(ctx, row.number, row.raw, reasonOfWriteError(err)); err != nil {
+					return err
+				}
+				continue
+			}
+			writtenContacts = append(writtenContacts, rowContacts...)
+		}
+	}
+
+	for _, contact := range writtenContacts {
+		if existingContacts[contact.UniqueId] {
+			im.result.Updated++
+		} else {
+			im.result.Created++
+		}
+		contactIds = append(contactIds, contact.UniqueId)
+	}
+
+	// * contacts created by someone else since the existing ones were fetched are not returned by the insert
+	im.result.Skipped += len(rowsToWrite) - failedRows - len(writtenContacts)
+
+	if err := im.addToLists(ctx, contactIds); err != nil {
+		return err
+	}
+
+	if err := im.recordConsents(ctx, rowsToWrite, writtenContacts); err != nil {
+		return err
+	}
+
+	return im.progress.ItemsProcessed(ctx, len(batch)-invalidRows-failedRows)
+}
+
+// fail records why the row was not imported
+func (im *importer) fail(ctx context.Context, rowNumber int, row string, reason string) error {
+	im.result.Failed++
+	return im.progress.ItemFailed(ctx, rowNumber, row, reason)
+}
+
+// fetchExistingContactIds returns the ids of the contacts of the organization the rows already exist as, by phone number
+func (im *importer) fetchExistingContactIds(ctx context.Context, batch []importRow) (map[string]uuid.UUID, error) {
+	phoneNumbers := make([]Expression, 0, len(batch))
+	for _, row := range batch {
+		phoneNumbers = append(phoneNumbers, String(row.contact.PhoneNumber))
+	}
+
+	var contacts []model.Contact
+
+	err := SELECT(table.Contact.UniqueId, table.Contact.PhoneNumber).
+		FROM(table.Contact).
+		WHERE(
+			table.Contact.OrganizationId.EQ(UUID(im.job.OrganizationId)).
+				AND(table.Contact.PhoneNumber.IN(phoneNumbers...)),
+		).
+		QueryContext(ctx, im.db, &contacts)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	existingContactIds := make(map[string]uuid.UUID, len(contacts))
+	for _, contact := range contacts {
+		existingContactIds[contact.PhoneNumber] = contact.UniqueId
+	}
+
+	return existingContactIds, nil
+}
+
+// writeContacts inserts the contacts of the rows, with the upsert strategy existing contacts get the name of the row and
+// the attributes of the row merged into theirs. The ids and phone numbers of the written contacts are returned.
+func (im *importer) writeContacts(ctx context.Context, rows []importRow) ([]model.Contact, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	contacts := make([]model.Contact, 0, len(rows))
+	for _, row := range rows {
+		contacts = append(contacts, row.contact)
+	}
+
+	insertQuery := table.Contact.
+		INSERT(table.Contact.MutableColumns).
+		MODELS(contacts)
+
+	if im.parameters.OnConflict == api_types.Upsert {
+		insertQuery = insertQuery.
+			ON_CONFLICT(table.Contact.PhoneNumber, table.Contact.OrganizationId).
+			DO_UPDATE(SET(
+				table.Contact.Name.SET(table.Contact.EXCLUDED.Name),
+				table.Contact.Attributes.SET(StringExp(Raw(`COALESCE("Contact"."Attributes", '{}'::jsonb) || excluded."Attributes"`))),
+				table.Contact.UpdatedAt.SET(NOW()),
+			))
+	} else {
+		// * a contact created since the existing ones were fetched is skipped too
+		insertQuery = insertQuery.
+			ON_CONFLICT(table.Contact.PhoneNumber, table.Contact.OrganizationId).
+			DO_NOTHING()
+	}
+
+	var writtenContacts []model.Contact
+
+	err := insertQuery.
+		RETURNING(table.Contact.UniqueId, table.Contact.PhoneNumber).
+		QueryContext(ctx, im.db, &writtenContacts)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return writtenContacts, nil
+}
+
+// recordConsents records the consents of the rows of the written contacts, the skipped contacts keep theirs
+func (im *importer) recordConsents(ctx context.Context, rows []importRow, writtenContacts []model.Contact) error {
+	contactIds := make(map[string]uuid.UUID, len(writtenContacts))
+	for _, contact := range writtenContacts {
+		contactIds[contact.PhoneNumber] = contact.UniqueId
+	}
+
+	changes := []contact_consent_service.Change{}
+
+	for _, row := range rows {
+		contactId, ok := contactIds[row.contact.PhoneNumber]
+		if !ok {
+			continue
+		}
+
+		for _, change := range row.consents {
+			change.ContactId = contactId
+			changes = append(changes, change)
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	tx, err := im.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := contact_consent_service.Apply(ctx, tx, changes...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func reasonOfWriteError(err error) string {
+	if strings.Contains(err.Error(), phoneNumberIndexName) {
+		return "the phone number belongs to a contact of another organization"
+	}
+	return err.Error()
+}
+
+func (im *importer) addToLists(ctx context.Context, contactIds []uuid.UUID) error {
+	if len(im.parameters.ListIds) == 0 || len(contactIds) == 0 {
+		return nil
+	}
+
+	records := make([]model.ContactListContact, 0, len(contactIds)*len(im.parameters.ListIds))
+	for _, listId := range im.parameters.ListIds {
+		for _, contactId := range contactIds {
+			records = append(records, model.ContactListContact{
+				ContactListId: listId,
+				ContactId:     contactId,
+				CreatedAt:     time.Now(),
+				UpdatedAt:     time.Now(),
+			})
+		}
+	}
+
+	var insertedRecords []model.ContactListContact
+
+	err := table.ContactListContact.
+		INSERT(table.ContactListContact.AllColumns).
+		MODELS(records).
+		ON_CONFLICT(table.ContactListContact.ContactListId, table.ContactListContact.ContactId).
+		DO_NOTHING().
+		RETURNING(table.ContactListContact.AllColumns).
+		QueryContext(ctx, im.db, &insertedRecords)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return err
+	}
+
+	// * only the contacts which were not in the lists already joined them
+	activities := make([]contact_activity_service.Activity, 0, len(insertedRecords))
+	for _, record := range insertedRecords {
+		activities = append(activities, contact_activity_service.Activity{
+			OrganizationId:       im.job.OrganizationId,
+			ContactId:            record.ContactId,
+			OrganizationMemberId: im.job.CreatedByOrganizationMemberId,
+			Type:                 model.ContactActivityTypeEnum_ListJoined,
+			Data: contact_activity_service.ListData{
+				ContactListId: record.ContactListId,
+				Name:          im.listNames[record.ContactListId],
+			},
+		})
+	}
+
+	return contact_activity_service.Record(ctx, im.db, activities...)
+}
+
+func fetchListNames(ctx context.Context, db qrm.Queryable, organizationId uuid.UUID, listIds []uuid.UUID) (map[uuid.UUID]string, error) {
+	listNames := map[uuid.UUID]string{}
+
+	if len(listIds) == 0 {
+		return listNames, nil
+	}
+
+	listIdExpressions := make([]Expression, 0, len(listIds))
+	for _, listId := range listIds {
+		listIdExpressions = append(listIdExpressions, UUID(listId))
+	}
+
+	var lists []model.ContactList
+
+	err := SELECT(table.ContactList.AllColumns).
+		FROM(table.ContactList).
+		WHERE(
+			table.ContactList.OrganizationId.EQ(UUID(organizationId)).
+				AND(table.ContactList.UniqueId.IN(listIdExpressions...)),
+		).
+		QueryContext(ctx, db, &lists)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	for _, list := range lists {
+		listNames[list.UniqueId] = list.Name
+	}
+
+	return listNames, nil
+}
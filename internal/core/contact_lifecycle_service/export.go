@@ -0,0 +1,192 @@
+package contact_lifecycle_service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// the contacts are read and written to the export in pages of this size
+const exportPageSize = 1000
+
+// ExportFilter selects the contacts to export, like the filters of the contact list
+type ExportFilter struct {
+	ListId *uuid.UUID
+	Status *model.ContactStatusEnum
+	Order  api_types.OrderEnum
+}
+
+// contactEncoder writes the exported contacts in the format of the export
+type contactEncoder interface {
+	Begin() error
+	Write(contact api_types.ContactSchema) error
+	End() error
+}
+
+// Export writes the contacts of the organization matching the filter to the writer, page by page so that the whole address
+// book is never held in memory. The columns of the CSV export are the ones the import reads by default, so that an export can
+// be imported again. The number of exported contacts is returned.
+func Export(ctx context.Context, db qrm.Queryable, organizationId uuid.UUID, filter ExportFilter, format api_types.ContactExportFormatEnum, writer io.Writer) (int, error) {
+	var encoder contactEncoder
+
+	switch format {
+	case api_types.Csv:
+		encoder = &csvContactEncoder{writer: csv.NewWriter(writer)}
+	case api_types.Json:
+		encoder = &jsonContactEncoder{writer: bufio.NewWriter(writer)}
+	default:
+		return 0, errors.New("unknown export format " + string(format))
+	}
+
+	whereCondition := table.Contact.OrganizationId.EQ(UUID(organizationId))
+
+	if filter.ListId != nil {
+		whereCondition = whereCondition.AND(EXISTS(
+			SELECT(table.ContactListContact.ContactId).
+				FROM(table.ContactListContact).
+				WHERE(
+					table.ContactListContact.ContactId.EQ(table.Contact.UniqueId).
+						AND(table.ContactListContact.ContactListId.EQ(UUID(*filter.ListId))),
+				),
+		))
+	}
+
+	if filter.Status != nil {
+		whereCondition = whereCondition.AND(table.Contact.Status.EQ(utils.EnumExpression(filter.Status.String())))
+	}
+
+	orderBy := []OrderByClause{table.Contact.CreatedAt.DESC(), table.Contact.UniqueId.DESC()}
+	if filter.Order == api_types.Asc {
+		orderBy = []OrderByClause{table.Contact.CreatedAt.ASC(), table.Contact.UniqueId.ASC()}
+	}
+
+	if err := encoder.Begin(); err != nil {
+		return 0, err
+	}
+
+	exported := 0
+
+	for page := int64(0); ; page++ {
+		var contacts []model.Contact
+
+		err := SELECT(table.Contact.AllColumns).
+			FROM(table.Contact).
+			WHERE(whereCondition).
+			ORDER_BY(orderBy...).
+			LIMIT(exportPageSize).
+			OFFSET(page*exportPageSize).
+			QueryContext(ctx, db, &contacts)
+
+		if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+			return exported, err
+		}
+
+		contactIds := make([]uuid.UUID, 0, len(contacts))
+		for _, contact := range contacts {
+			contactIds = append(contactIds, contact.UniqueId)
+		}
+
+		contactLists, err := FetchContactLists(ctx, db, contactIds)
+		if err != nil {
+			return exported, err
+		}
+
+		for _, contact := range contacts {
+			if err := encoder.Write(ToSchema(contact, contactLists[contact.UniqueId])); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+
+		if len(contacts) < exportPageSize {
+			break
+		}
+	}
+
+	return exported, encoder.End()
+}
+
+type csvContactEncoder struct {
+	writer *csv.Writer
+}
+
+func (e *csvContactEncoder) Begin() error {
+	return e.writer.Write([]string{"id", "name", "phone", "status", "lists", "attributes", "createdAt"})
+}
+
+func (e *csvContactEncoder) Write(contact api_types.ContactSchema) error {
+	listNames := make([]string, 0, len(contact.Lists))
+	for _, list := range contact.Lists {
+		listNames = append(listNames, list.Name)
+	}
+
+	attributes, err := json.Marshal(contact.Attributes)
+	if err != nil {
+		return err
+	}
+
+	// * the csv writer buffers a few kilobytes at a time, the export is streamed without flushing every row
+	return e.writer.Write([]string{
+		contact.UniqueId,
+		contact.Name,
+		contact.Phone,
+		string(contact.Status),
+		strings.Join(listNames, "; "),
+		string(attributes),
+		contact.CreatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (e *csvContactEncoder) End() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// jsonContactEncoder writes the contacts as a JSON array, one contact at a time
+type jsonContactEncoder struct {
+	writer  *bufio.Writer
+	written int
+}
+
+func (e *jsonContactEncoder) Begin() error {
+	_, err := e.writer.WriteString("[")
+	return err
+}
+
+func (e *jsonContactEncoder) Write(contact api_types.ContactSchema) error {
+	contactJson, err := json.Marshal(contact)
+	if err != nil {
+		return err
+	}
+
+	if e.written > 0 {
+		if _, err := e.writer.WriteString(","); err != nil {
+			return err
+		}
+	}
+
+	e.written++
+	_, err = e.writer.Write(contactJson)
+	return err
+}
+
+func (e *jsonContactEncoder) End() error {
+	if _, err := e.writer.WriteString("]"); err != nil {
+		return err
+	}
+	return e.writer.Flush()
+}
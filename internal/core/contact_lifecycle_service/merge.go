@@ -0,0 +1,359 @@
+package contact_lifecycle_service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/core/audit_service"
+	"github.com/wapikit/wapikit/internal/core/contact_activity_service"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+var ErrInvalidMerge = errors.New("only other contacts of the same organization which are not erased can be merged")
+
+// MergeReason tells what led to a merge, it is recorded along with the merge in the audit log
+type MergeReason string
+
+const (
+	MergeReasonManual             MergeReason = "Manual"
+	MergeReasonDuplicateScan      MergeReason = "DuplicateScan"
+	MergeReasonPhoneNumberChanged MergeReason = "PhoneNumberChanged"
+)
+
+// mergeDetails are stored in the audit log, only the ids of the merged contacts are kept as they are deleted
+type mergeDetails struct {
+	MergedContactIds []uuid.UUID `json:"mergedContactIds"`
+	Reason           MergeReason `json:"reason"`
+}
+
+// Merge moves the conversations, messages, list memberships and link clicks of the duplicates to the contact and deletes the
+// duplicates. Attributes of the duplicates are added to the ones the contact does not have, and a blocked duplicate blocks
+// the contact. The merge is recorded in the audit log and the merged contact is returned.
+func Merge(ctx context.Context, db *sql.DB, contact model.Contact, duplicates []model.Contact, reason MergeReason, organizationMemberId *uuid.UUID) (*model.Contact, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	mergedContact, err := merge(ctx, tx, contact, duplicates, contact.PhoneNumber, reason, organizationMemberId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return mergedContact, nil
+}
+
+// ChangePhoneNumber moves the contact of the organization with the old phone number to the new one. A contact which
+// already exists with the new number is merged into it, so that the whole history of the person stays on one contact.
+// nil is returned when the organization has no contact with the old phone number.
+func ChangePhoneNumber(ctx context.Context, db *sql.DB, organizationId uuid.UUID, oldPhoneNumber, newPhoneNumber string) (*model.Contact, error) {
+	var contacts []model.Contact
+
+	err := SELECT(table.Contact.AllColumns).
+		FROM(table.Contact).
+		WHERE(
+			table.Contact.OrganizationId.EQ(UUID(organizationId)).
+				AND(table.Contact.PhoneNumber.IN(String(oldPhoneNumber), String(newPhoneNumber))),
+		).
+		QueryContext(ctx, db, &contacts)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	var contact *model.Contact
+	duplicates := []model.Contact{}
+
+	for index := range contacts {
+		if contacts[index].PhoneNumber == oldPhoneNumber {
+			contact = &contacts[index]
+		} else {
+			duplicates = append(duplicates, contacts[index])
+		}
+	}
+
+	if contact == nil {
+		return nil, nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	mergedContact, err := merge(ctx, tx, *contact, duplicates, newPhoneNumber, MergeReasonPhoneNumberChanged, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return mergedContact, nil
+}
+
+// merge merges the duplicates into the contact in the transaction and gives the contact the phone number, the phone number
+// is only written once the duplicates are deleted as it may be the number of one of them
+func merge(ctx context.Context, tx *sql.Tx, contact model.Contact, duplicates []model.Contact, phoneNumber string, reason MergeReason, organizationMemberId *uuid.UUID) (*model.Contact, error) {
+	if contact.Status == model.ContactStatusEnum_Deleted {
+		return nil, ErrInvalidMerge
+	}
+
+	for _, duplicate := range duplicates {
+		if duplicate.UniqueId == contact.UniqueId || duplicate.OrganizationId != contact.OrganizationId || duplicate.Status == model.ContactStatusEnum_Deleted {
+			return nil, ErrInvalidMerge
+		}
+	}
+
+	attributes, err := mergeAttributes(contact, duplicates)
+	if err != nil {
+		return nil, err
+	}
+
+	name := contact.Name
+	status := contact.Status
+	mergedContactIds := make([]uuid.UUID, 0, len(duplicates))
+
+	for _, duplicate := range duplicates {
+		if err := moveToContact(ctx, tx, duplicate, contact); err != nil {
+			return nil, err
+		}
+
+		// * contacts created from a number alone are named after it, a real name of a duplicate is kept instead
+		if (name == "" || name == contact.PhoneNumber) && duplicate.Name != "" && duplicate.Name != duplicate.PhoneNumber {
+			name = duplicate.Name
+		}
+
+		// * the person asked not to be messaged on one of the contacts, which must hold for the merged one too
+		if duplicate.Status == model.ContactStatusEnum_Blocked {
+			status = model.ContactStatusEnum_Blocked
+		}
+
+		mergedContactIds = append(mergedContactIds, duplicate.UniqueId)
+	}
+
+	var mergedContact model.Contact
+
+	err = table.Contact.UPDATE(
+		table.Contact.Name,
+		table.Contact.PhoneNumber,
+		table.Contact.Attributes,
+		table.Contact.Status,
+		table.Contact.UpdatedAt,
+	).
+		MODEL(model.Contact{
+			Name:        name,
+			PhoneNumber: phoneNumber,
+			Attributes:  &attributes,
+			Status:      status,
+			UpdatedAt:   time.Now(),
+		}).
+		WHERE(table.Contact.UniqueId.EQ(UUID(contact.UniqueId))).
+		RETURNING(table.Contact.AllColumns).
+		QueryContext(ctx, tx, &mergedContact)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mergedContactIds) == 0 {
+		return &mergedContact, nil
+	}
+
+	err = audit_service.Record(ctx, tx, audit_service.Entry{
+		OrganizationId:       contact.OrganizationId,
+		OrganizationMemberId: organizationMemberId,
+		Action:               model.AuditLogActionEnum_ContactsMerged,
+		EntityId:             &contact.UniqueId,
+		Details: mergeDetails{
+			MergedContactIds: mergedContactIds,
+			Reason:           reason,
+		},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = contact_activity_service.Record(ctx, tx, contact_activity_service.Activity{
+		OrganizationId:       contact.OrganizationId,
+		ContactId:            contact.UniqueId,
+		OrganizationMemberId: organizationMemberId,
+		Type:                 model.ContactActivityTypeEnum_Merged,
+		Data: contact_activity_service.MergedData{
+			MergedContactIds: mergedContactIds,
+		},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &mergedContact, nil
+}
+
+// mergeAttributes adds the attributes of the duplicates to the ones of the contact, the values of the contact win
+func mergeAttributes(contact model.Contact, duplicates []model.Contact) (string, error) {
+	attributes := map[string]interface{}{}
+
+	// * the contact comes last so that its values overwrite the ones of the duplicates
+	mergedContacts := append(append([]model.Contact{}, duplicates...), contact)
+
+	for _, mergedContact := range mergedContacts {
+		if mergedContact.Attributes == nil {
+			continue
+		}
+
+		var contactAttributes map[string]interface{}
+		if err := json.Unmarshal([]byte(*mergedContact.Attributes), &contactAttributes); err != nil {
+			return "", err
+		}
+
+		for key, value := range contactAttributes {
+			attributes[key] = value
+		}
+	}
+
+	attributesJson, err := json.Marshal(attributes)
+	if err != nil {
+		return "", err
+	}
+
+	return string(attributesJson), nil
+}
+
+// moveToContact moves everything related to the duplicate to the contact and deletes the duplicate
+func moveToContact(ctx context.Context, tx *sql.Tx, duplicate, contact model.Contact) error {
+	_, err := table.Conversation.UPDATE(table.Conversation.ContactId, table.Conversation.UpdatedAt).
+		SET(UUID(contact.UniqueId), TimestampzT(time.Now())).
+		WHERE(table.Conversation.ContactId.EQ(UUID(duplicate.UniqueId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.Message.UPDATE(table.Message.ContactId).
+		SET(UUID(contact.UniqueId)).
+		WHERE(table.Message.ContactId.EQ(UUID(duplicate.UniqueId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.TrackLinkClick.UPDATE(table.TrackLinkClick.ContactId).
+		SET(UUID(contact.UniqueId)).
+		WHERE(table.TrackLinkClick.ContactId.EQ(UUID(duplicate.UniqueId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	// * memberships of lists the contact already belongs to are dropped along with the duplicate
+	_, err = table.ContactListContact.UPDATE(table.ContactListContact.ContactId, table.ContactListContact.UpdatedAt).
+		SET(UUID(contact.UniqueId), TimestampzT(time.Now())).
+		WHERE(
+			table.ContactListContact.ContactId.EQ(UUID(duplicate.UniqueId)).
+				AND(table.ContactListContact.ContactListId.NOT_IN(
+					SELECT(table.ContactListContact.ContactListId).
+						FROM(table.ContactListContact).
+						WHERE(table.ContactListContact.ContactId.EQ(UUID(contact.UniqueId))),
+				)),
+		).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ContactActivity.UPDATE(table.ContactActivity.ContactId).
+		SET(UUID(contact.UniqueId)).
+		WHERE(table.ContactActivity.ContactId.EQ(UUID(duplicate.UniqueId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ContactListContact.DELETE().
+		WHERE(table.ContactListContact.ContactId.EQ(UUID(duplicate.UniqueId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	if err := moveConsents(ctx, tx, duplicate, contact); err != nil {
+		return err
+	}
+
+	_, err = table.Contact.DELETE().
+		WHERE(table.Contact.UniqueId.EQ(UUID(duplicate.UniqueId))).
+		ExecContext(ctx, tx)
+
+	return err
+}
+
+// moveConsents keeps, of the consents of the duplicate and the contact to the same category, the one changed last as it is
+// the latest wish of the contact
+func moveConsents(ctx context.Context, tx *sql.Tx, duplicate, contact model.Contact) error {
+	duplicateConsent := table.ContactConsent.AS("DuplicateConsent")
+
+	_, err := table.ContactConsent.DELETE().
+		WHERE(
+			table.ContactConsent.ContactId.EQ(UUID(contact.UniqueId)).
+				AND(EXISTS(
+					SELECT(duplicateConsent.UniqueId).
+						FROM(duplicateConsent).
+						WHERE(
+							duplicateConsent.ContactId.EQ(UUID(duplicate.UniqueId)).
+								AND(duplicateConsent.Category.EQ(table.ContactConsent.Category)).
+								AND(duplicateConsent.ChangedAt.GT(table.ContactConsent.ChangedAt)),
+						),
+				)),
+		).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ContactConsent.UPDATE(table.ContactConsent.ContactId, table.ContactConsent.UpdatedAt).
+		SET(UUID(contact.UniqueId), TimestampzT(time.Now())).
+		WHERE(
+			table.ContactConsent.ContactId.EQ(UUID(duplicate.UniqueId)).
+				AND(table.ContactConsent.Category.NOT_IN(
+					SELECT(table.ContactConsent.Category).
+						FROM(table.ContactConsent).
+						WHERE(table.ContactConsent.ContactId.EQ(UUID(contact.UniqueId))),
+				)),
+		).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ContactConsent.DELETE().
+		WHERE(table.ContactConsent.ContactId.EQ(UUID(duplicate.UniqueId))).
+		ExecContext(ctx, tx)
+
+	return err
+}
@@ -0,0 +1,463 @@
+package contact_lifecycle_service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/audit_service"
+	"github.com/wapikit/wapikit/internal/core/contact_consent_service"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// the name an anonymized contact is shown with in the conversations it is kept in
+const anonymizedContactName = "Anonymized contact"
+
+var ErrContactNotFound = errors.New("contact not found")
+
+// the attributes of contacts and the data of messages are read as JSON objects, so erased values are emptied instead of nulled
+var emptyJsonObject = StringExp(Raw(`'{}'::jsonb`))
+
+// FetchContact returns the contact of the organization
+func FetchContact(ctx context.Context, db qrm.Queryable, organizationId, contactId uuid.UUID) (*model.Contact, error) {
+	var contact model.Contact
+
+	err := tenant_service.Contact.Fetch(ctx, db, organizationId, contactId, &contact)
+	if err != nil {
+		if err == tenant_service.ErrNotFound {
+			return nil, ErrContactNotFound
+		}
+		return nil, err
+	}
+
+	return &contact, nil
+}
+
+// ToSchema converts the contact, with the lists it belongs to
+func ToSchema(contact model.Contact, lists []model.ContactList) api_types.ContactSchema {
+	attributes := map[string]interface{}{}
+	if contact.Attributes != nil {
+		json.Unmarshal([]byte(*contact.Attributes), &attributes)
+	}
+
+	contactLists := make([]api_types.ContactListSchema, 0, len(lists))
+	for _, list := range lists {
+		contactLists = append(contactLists, api_types.ContactListSchema{
+			UniqueId: list.UniqueId.String(),
+			Name:     list.Name,
+		})
+	}
+
+	return api_types.ContactSchema{
+		UniqueId:   contact.UniqueId.String(),
+		CreatedAt:  contact.CreatedAt,
+		Name:       contact.Name,
+		Phone:      contact.PhoneNumber,
+		Attributes: attributes,
+		Lists:      contactLists,
+		Status:     api_types.ContactStatusEnum(contact.Status),
+	}
+}
+
+// FetchContactLists returns the lists each of the contacts belongs to
+func FetchContactLists(ctx context.Context, db qrm.Queryable, contactIds []uuid.UUID) (map[uuid.UUID][]model.ContactList, error) {
+	contactLists := map[uuid.UUID][]model.ContactList{}
+
+	if len(contactIds) == 0 {
+		return contactLists, nil
+	}
+
+	contactIdExpressions := make([]Expression, 0, len(contactIds))
+	for _, contactId := range contactIds {
+		contactIdExpressions = append(contactIdExpressions, UUID(contactId))
+	}
+
+	var rows []struct {
+		model.ContactListContact
+		model.ContactList
+	}
+
+	err := SELECT(table.ContactListContact.AllColumns, table.ContactList.AllColumns).
+		FROM(table.ContactListContact.
+			INNER_JOIN(table.ContactList, table.ContactList.UniqueId.EQ(table.ContactListContact.ContactListId)),
+		).
+		WHERE(table.ContactListContact.ContactId.IN(contactIdExpressions...)).
+		ORDER_BY(table.ContactList.Name.ASC()).
+		QueryContext(ctx, db, &rows)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		contactLists[row.ContactListContact.ContactId] = append(contactLists[row.ContactListContact.ContactId], row.ContactList)
+	}
+
+	return contactLists, nil
+}
+
+// ExportContactData returns everything stored about the contact, for the data subject access requests of the contact
+func ExportContactData(ctx context.Context, db qrm.Queryable, contact model.Contact) (*api_types.ContactDataExportSchema, error) {
+	contactLists, err := FetchContactLists(ctx, db, []uuid.UUID{contact.UniqueId})
+	if err != nil {
+		return nil, err
+	}
+
+	var conversations []model.Conversation
+
+	err = SELECT(table.Conversation.AllColumns).
+		FROM(table.Conversation).
+		WHERE(table.Conversation.ContactId.EQ(UUID(contact.UniqueId))).
+		ORDER_BY(table.Conversation.CreatedAt.ASC()).
+		QueryContext(ctx, db, &conversations)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	var messages []model.Message
+
+	err = SELECT(table.Message.AllColumns).
+		FROM(table.Message).
+		WHERE(table.Message.ContactId.EQ(UUID(contact.UniqueId))).
+		ORDER_BY(table.Message.CreatedAt.ASC()).
+		QueryContext(ctx, db, &messages)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	var linkClicks []struct {
+		model.TrackLinkClick
+		model.TrackLink
+	}
+
+	err = SELECT(table.TrackLinkClick.AllColumns, table.TrackLink.AllColumns).
+		FROM(table.TrackLinkClick.
+			INNER_JOIN(table.TrackLink, table.TrackLink.UniqueId.EQ(table.TrackLinkClick.TrackLinkId)),
+		).
+		WHERE(table.TrackLinkClick.ContactId.EQ(UUID(contact.UniqueId))).
+		ORDER_BY(table.TrackLinkClick.CreatedAt.ASC()).
+		QueryContext(ctx, db, &linkClicks)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	conversationsToReturn := make([]api_types.ContactDataConversationSchema, 0, len(conversations))
+	for _, conversation := range conversations {
+		conversationsToReturn = append(conversationsToReturn, api_types.ContactDataConversationSchema{
+			UniqueId:  conversation.UniqueId.String(),
+			Status:    api_types.ConversationStatusEnum(conversation.Status.String()),
+			CreatedAt: conversation.CreatedAt,
+		})
+	}
+
+	messagesToReturn := make([]api_types.MessageSchema, 0, len(messages))
+	for _, message := range messages {
+		conversationId := ""
+		if message.ConversationId != nil {
+			conversationId = message.ConversationId.String()
+		}
+
+		messageData := map[string]interface{}{}
+		if message.MessageData != nil {
+			json.Unmarshal([]byte(*message.MessageData), &messageData)
+		}
+
+		messagesToReturn = append(messagesToReturn, api_types.MessageSchema{
+			UniqueId:       message.UniqueId.String(),
+			ConversationId: conversationId,
+			CreatedAt:      message.CreatedAt,
+			Direction:      api_types.MessageDirectionEnum(message.Direction.String()),
+			MessageData:    &messageData,
+			MessageType:    api_types.MessageTypeEnum(message.MessageType.String()),
+			Status:         api_types.MessageStatusEnum(message.Status.String()),
+		})
+	}
+
+	consents, err := contact_consent_service.FetchConsents(ctx, db, contact.UniqueId)
+	if err != nil {
+		return nil, err
+	}
+
+	consentsToReturn := make([]api_types.ContactConsentSchema, 0, len(consents))
+	for _, consent := range consents {
+		consentsToReturn = append(consentsToReturn, contact_consent_service.ToSchema(consent))
+	}
+
+	linkClicksToReturn := make([]api_types.ContactLinkClickSchema, 0, len(linkClicks))
+	for _, linkClick := range linkClicks {
+		linkClicksToReturn = append(linkClicksToReturn, api_types.ContactLinkClickSchema{
+			ClickedAt:  linkClick.TrackLinkClick.CreatedAt,
+			CampaignId: linkClick.TrackLink.CampaignId.String(),
+			Url:        linkClick.TrackLink.DestinationUrl,
+		})
+	}
+
+	return &api_types.ContactDataExportSchema{
+		ExportedAt:    time.Now(),
+		Contact:       ToSchema(contact, contactLists[contact.UniqueId]),
+		Conversations: conversationsToReturn,
+		Messages:      messagesToReturn,
+		LinkClicks:    linkClicksToReturn,
+		Consents:      consentsToReturn,
+	}, nil
+}
+
+// Erase removes the personal data of the contact and records the erasure in the audit log.
+//
+// Anonymize keeps the contact, its conversations and the campaign statistics, but replaces everything identifying it: the
+// name, phone number and attributes of the contact, the content of its messages, the comments of its csat ratings and the
+// notes of the members on its conversations. Delete removes the contact and everything related to it.
+func Erase(ctx context.Context, db *sql.DB, contact model.Contact, mode api_types.ContactErasureModeEnum, organizationMemberId *uuid.UUID) error {
+	action := model.AuditLogActionEnum_ContactAnonymized
+	if mode == api_types.Delete {
+		action = model.AuditLogActionEnum_ContactDeleted
+	} else if mode != api_types.Anonymize {
+		return errors.New("unknown erasure mode " + string(mode))
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if mode == api_types.Delete {
+		err = deleteContact(ctx, tx, contact)
+	} else {
+		err = anonymizeContact(ctx, tx, contact)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = audit_service.Record(ctx, tx, audit_service.Entry{
+		OrganizationId:       contact.OrganizationId,
+		OrganizationMemberId: organizationMemberId,
+		Action:               action,
+		EntityId:             &contact.UniqueId,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ContactConversationIds selects the ids of the conversations of the contact, to be used as a subquery
+func ContactConversationIds(contact model.Contact) SelectStatement {
+	return SELECT(table.Conversation.UniqueId).
+		FROM(table.Conversation).
+		WHERE(table.Conversation.ContactId.EQ(UUID(contact.UniqueId)))
+}
+
+// deleteConversationNotes deletes the notes of the members on the conversations of the contact, along with their mentions
+func deleteConversationNotes(ctx context.Context, tx *sql.Tx, contact model.Contact) error {
+	_, err := table.ConversationNoteMention.DELETE().
+		WHERE(table.ConversationNoteMention.ConversationNoteId.IN(
+			SELECT(table.ConversationNote.UniqueId).
+				FROM(table.ConversationNote).
+				WHERE(table.ConversationNote.ConversationId.IN(ContactConversationIds(contact))),
+		)).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ConversationNote.DELETE().
+		WHERE(table.ConversationNote.ConversationId.IN(ContactConversationIds(contact))).
+		ExecContext(ctx, tx)
+
+	return err
+}
+
+func anonymizeContact(ctx context.Context, tx *sql.Tx, contact model.Contact) error {
+	// * the phone number must stay unique, the id of the contact is used in its place
+	_, err := table.Contact.UPDATE(
+		table.Contact.Name,
+		table.Contact.PhoneNumber,
+		table.Contact.Attributes,
+		table.Contact.Status,
+		table.Contact.UpdatedAt,
+	).
+		SET(
+			String(anonymizedContactName),
+			String("anonymized-"+contact.UniqueId.String()),
+			emptyJsonObject,
+			utils.EnumExpression(model.ContactStatusEnum_Deleted.String()),
+			TimestampzT(time.Now()),
+		).
+		WHERE(table.Contact.UniqueId.EQ(UUID(contact.UniqueId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	// * the messages are kept for the statistics of the conversations and campaigns, only their content is removed
+	_, err = table.Message.UPDATE(table.Message.MessageData, table.Message.UpdatedAt).
+		SET(emptyJsonObject, TimestampzT(time.Now())).
+		WHERE(table.Message.ContactId.EQ(UUID(contact.UniqueId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.CsatSurvey.UPDATE(table.CsatSurvey.Comment).
+		SET(NULL).
+		WHERE(table.CsatSurvey.ConversationId.IN(ContactConversationIds(contact))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	if err := deleteConversationNotes(ctx, tx, contact); err != nil {
+		return err
+	}
+
+	// * the attribute changes hold the old values of the attributes and the evidence of the consents identifies the contact,
+	// * the rest of the activity of the contact is kept
+	_, err = table.ContactActivity.DELETE().
+		WHERE(
+			table.ContactActivity.ContactId.EQ(UUID(contact.UniqueId)).
+				AND(table.ContactActivity.Type.IN(
+					utils.EnumExpression(model.ContactActivityTypeEnum_AttributesChanged.String()),
+					utils.EnumExpression(model.ContactActivityTypeEnum_OptedIn.String()),
+					utils.EnumExpression(model.ContactActivityTypeEnum_OptedOut.String()),
+				)),
+		).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ContactConsent.DELETE().
+		WHERE(table.ContactConsent.ContactId.EQ(UUID(contact.UniqueId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	// * an anonymized contact must never be messaged again
+	_, err = table.ContactListContact.DELETE().
+		WHERE(table.ContactListContact.ContactId.EQ(UUID(contact.UniqueId))).
+		ExecContext(ctx, tx)
+
+	return err
+}
+
+func deleteContact(ctx context.Context, tx *sql.Tx, contact model.Contact) error {
+	if err := deleteConversationNotes(ctx, tx, contact); err != nil {
+		return err
+	}
+
+	conversationIds := ContactConversationIds(contact)
+
+	_, err := table.ConversationTag.DELETE().
+		WHERE(table.ConversationTag.ConversationId.IN(conversationIds)).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ConversationAssignment.DELETE().
+		WHERE(table.ConversationAssignment.ConversationId.IN(conversationIds)).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ConversationTimelineEvent.DELETE().
+		WHERE(table.ConversationTimelineEvent.ConversationId.IN(conversationIds)).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.CsatSurvey.DELETE().
+		WHERE(table.CsatSurvey.ConversationId.IN(conversationIds)).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.Message.DELETE().
+		WHERE(
+			table.Message.ContactId.EQ(UUID(contact.UniqueId)).
+				OR(table.Message.ConversationId.IN(conversationIds)),
+		).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.Conversation.DELETE().
+		WHERE(table.Conversation.ContactId.EQ(UUID(contact.UniqueId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.TrackLinkClick.DELETE().
+		WHERE(table.TrackLinkClick.ContactId.EQ(UUID(contact.UniqueId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ContactListContact.DELETE().
+		WHERE(table.ContactListContact.ContactId.EQ(UUID(contact.UniqueId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ContactActivity.DELETE().
+		WHERE(table.ContactActivity.ContactId.EQ(UUID(contact.UniqueId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ContactConsent.DELETE().
+		WHERE(table.ContactConsent.ContactId.EQ(UUID(contact.UniqueId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.Contact.DELETE().
+		WHERE(table.Contact.UniqueId.EQ(UUID(contact.UniqueId))).
+		ExecContext(ctx, tx)
+
+	return err
+}
@@ -0,0 +1,777 @@
+package contact_list_operation_service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/core/background_job_service"
+	"github.com/wapikit/wapikit/internal/core/contact_activity_service"
+	"github.com/wapikit/wapikit/internal/core/contact_duplicate_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// the contacts of a list are read, and written to the lists, in batches of this size
+const batchSize = 1000
+
+// MaxGroups is the most groups a list can be split into
+const MaxGroups = 20
+
+var ErrInvalidOperation = errors.New("invalid list operation")
+
+type Operation string
+
+const (
+	// Union creates a list of the contacts in any of the source lists
+	Union Operation = "Union"
+	// Intersection creates a list of the contacts in every source list
+	Intersection Operation = "Intersection"
+	// Difference creates a list of the contacts of the first source list which are in none of the others
+	Difference Operation = "Difference"
+	// Copy creates a list with the contacts of the source list
+	Copy Operation = "Copy"
+	// Split spreads the contacts of the source list randomly over new lists of about the same size
+	Split Operation = "Split"
+	// Move takes contacts out of the source list and adds them to the target list
+	Move Operation = "Move"
+	// Dedupe removes from the source list the contacts whose phone number is the same as an older contact of the list
+	Dedupe Operation = "Dedupe"
+)
+
+// Parameters are stored with the job when the operation is queued
+type Parameters struct {
+	Operation     Operation   `json:"operation"`
+	SourceListIds []uuid.UUID `json:"sourceListIds"`
+	// the name of the list created by Union, Intersection, Difference and Copy, and the prefix of the lists created by
+	// Split, the name of the source list is used when empty
+	Name string `json:"name,omitempty"`
+	// the number of lists created by Split
+	Groups int `json:"groups,omitempty"`
+	// the list the contacts are moved to
+	TargetListId *uuid.UUID `json:"targetListId,omitempty"`
+	// the contacts to move, every contact of the source list when empty
+	ContactIds []uuid.UUID `json:"contactIds,omitempty"`
+}
+
+// Validate checks the parameters have what the operation needs, it does not check that the lists exist
+func (p Parameters) Validate() error {
+	switch p.Operation {
+	case Union, Intersection, Difference:
+		if len(p.SourceListIds) < 2 {
+			return fmt.Errorf("%w: %s needs at least two source lists", ErrInvalidOperation, p.Operation)
+		}
+	case Copy, Split, Move, Dedupe:
+		if len(p.SourceListIds) != 1 {
+			return fmt.Errorf("%w: %s needs exactly one source list", ErrInvalidOperation, p.Operation)
+		}
+	default:
+		return fmt.Errorf("%w: unknown operation %s", ErrInvalidOperation, p.Operation)
+	}
+
+	seenListIds := make(map[uuid.UUID]bool, len(p.SourceListIds))
+	for _, listId := range p.SourceListIds {
+		if seenListIds[listId] {
+			return fmt.Errorf("%w: the source list %s is given more than once", ErrInvalidOperation, listId.String())
+		}
+		seenListIds[listId] = true
+	}
+
+	switch p.Operation {
+	case Union, Intersection, Difference:
+		if strings.TrimSpace(p.Name) == "" {
+			return fmt.Errorf("%w: the name of the new list is required", ErrInvalidOperation)
+		}
+	case Split:
+		if p.Groups < 2 || p.Groups > MaxGroups {
+			return fmt.Errorf("%w: a list can be split into 2 to %d groups", ErrInvalidOperation, MaxGroups)
+		}
+	case Move:
+		if p.TargetListId == nil {
+			return fmt.Errorf("%w: the target list is required", ErrInvalidOperation)
+		}
+		if *p.TargetListId == p.SourceListIds[0] {
+			return fmt.Errorf("%w: the contacts can not be moved to the list they are in", ErrInvalidOperation)
+		}
+	}
+
+	return nil
+}
+
+// ResultList is a list the operation created or added contacts to
+type ResultList struct {
+	UniqueId uuid.UUID `json:"uniqueId"`
+	Name     string    `json:"name"`
+	// the contacts added to the list by the operation
+	NumberOfContacts int `json:"numberOfContacts"`
+}
+
+// Result is the summary stored with the completed job
+type Result struct {
+	Lists []ResultList `json:"lists"`
+	// the contacts taken out of the source list, by Move and Dedupe
+	Removed int `json:"removed"`
+}
+
+// NewJobHandler returns the handler running the list operation jobs
+func NewJobHandler(db *sql.DB) background_job_service.Handler {
+	return func(ctx context.Context, job model.BackgroundJob, progress *background_job_service.Progress) (interface{}, error) {
+		var parameters Parameters
+		if err := json.Unmarshal([]byte(job.Parameters), &parameters); err != nil {
+			return nil, err
+		}
+
+		if err := parameters.Validate(); err != nil {
+			return nil, err
+		}
+
+		runner := &runner{
+			db:         db,
+			job:        job,
+			parameters: parameters,
+			progress:   progress,
+		}
+
+		return runner.run(ctx)
+	}
+}
+
+type runner struct {
+	db         *sql.DB
+	job        model.BackgroundJob
+	parameters Parameters
+	progress   *background_job_service.Progress
+}
+
+func (r *runner) run(ctx context.Context) (*Result, error) {
+	listIds := append([]uuid.UUID{}, r.parameters.SourceListIds...)
+	if r.parameters.TargetListId != nil {
+		listIds = append(listIds, *r.parameters.TargetListId)
+	}
+
+	lists, err := fetchLists(ctx, r.db, r.job.OrganizationId, listIds)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceLists := make([]model.ContactList, 0, len(r.parameters.SourceListIds))
+	for _, listId := range r.parameters.SourceListIds {
+		sourceLists = append(sourceLists, lists[listId])
+	}
+
+	switch r.parameters.Operation {
+	case Union, Intersection, Difference, Copy:
+		return r.combine(ctx, sourceLists)
+	case Split:
+		return r.split(ctx, sourceLists[0])
+	case Move:
+		return r.move(ctx, sourceLists[0], lists[*r.parameters.TargetListId])
+	case Dedupe:
+		return r.dedupe(ctx, sourceLists[0])
+	}
+
+	return nil, fmt.Errorf("%w: unknown operation %s", ErrInvalidOperation, r.parameters.Operation)
+}
+
+// combine creates a list of the contacts the set operation selects from the source lists. The set operation runs in the
+// database, the contacts are inserted into the new list batch by batch in the order of their ids.
+func (r *runner) combine(ctx context.Context, sourceLists []model.ContactList) (*Result, error) {
+	combinedContacts := r.combinedContacts(sourceLists)
+
+	total, err := countListContacts(ctx, r.db, combinedContacts)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(r.parameters.Name)
+	if name == "" {
+		name = sourceLists[0].Name + " (copy)"
+	}
+
+	if err := r.progress.SetTotal(ctx, total); err != nil {
+		return nil, err
+	}
+
+	list, err := createList(ctx, r.db, r.job.OrganizationId, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// * the contacts already in the new list are left out, so that every batch but the last one is full
+	newListMember := table.ContactListContact.AS("new_list_member")
+	notInNewList := NOT(EXISTS(
+		SELECT(newListMember.ContactId).
+			FROM(newListMember).
+			WHERE(
+				newListMember.ContactListId.EQ(UUID(list.UniqueId)).
+					AND(newListMember.ContactId.EQ(table.ContactListContact.ContactId)),
+			),
+	))
+
+	added := 0
+
+	for {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var insertedRecords []model.ContactListContact
+
+		err = table.ContactListContact.
+			INSERT(table.ContactListContact.ContactListId, table.ContactListContact.ContactId, table.ContactListContact.CreatedAt, table.ContactListContact.UpdatedAt).
+			QUERY(
+				SELECT(CAST(UUID(list.UniqueId)).AS("uuid"), table.ContactListContact.ContactId, TimestampzT(time.Now()), TimestampzT(time.Now())).
+					DISTINCT().
+					FROM(listContactsTable).
+					WHERE(combinedContacts.AND(notInNewList)).
+					ORDER_BY(table.ContactListContact.ContactId.ASC()).
+					LIMIT(batchSize),
+			).
+			ON_CONFLICT(table.ContactListContact.ContactListId, table.ContactListContact.ContactId).
+			DO_NOTHING().
+			RETURNING(table.ContactListContact.AllColumns).
+			QueryContext(ctx, tx, &insertedRecords)
+
+		if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+			tx.Rollback()
+			return nil, err
+		}
+
+		activities := make([]contact_activity_service.Activity, 0, len(insertedRecords))
+		for _, record := range insertedRecords {
+			activities = append(activities, r.listActivity(model.ContactActivityTypeEnum_ListJoined, *list, record.ContactId))
+		}
+
+		if err := contact_activity_service.Record(ctx, tx, activities...); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+
+		added += len(insertedRecords)
+
+		if err := r.progress.ItemsProcessed(ctx, len(insertedRecords)); err != nil {
+			return nil, err
+		}
+
+		if len(insertedRecords) < batchSize {
+			break
+		}
+	}
+
+	return &Result{
+		Lists: []ResultList{{UniqueId: list.UniqueId, Name: list.Name, NumberOfContacts: added}},
+	}, nil
+}
+
+// combinedContacts matches the rows of listContactsTable the operation selects. Union matches the rows of every source
+// list, the other operations the rows of the first list which are, or are not, in the other lists.
+func (r *runner) combinedContacts(sourceLists []model.ContactList) BoolExpression {
+	if r.parameters.Operation == Union {
+		listIdExpressions := make([]Expression, 0, len(sourceLists))
+		for _, list := range sourceLists {
+			listIdExpressions = append(listIdExpressions, UUID(list.UniqueId))
+		}
+		return table.ContactListContact.ContactListId.IN(listIdExpressions...).AND(isNotDeleted)
+	}
+
+	condition := table.ContactListContact.ContactListId.EQ(UUID(sourceLists[0].UniqueId)).AND(isNotDeleted)
+
+	otherListMember := table.ContactListContact.AS("other_list_member")
+	inOtherList := func(lists ...model.ContactList) BoolExpression {
+		listIdExpressions := make([]Expression, 0, len(lists))
+		for _, list := range lists {
+			listIdExpressions = append(listIdExpressions, UUID(list.UniqueId))
+		}
+
+		return EXISTS(
+			SELECT(otherListMember.ContactId).
+				FROM(otherListMember).
+				WHERE(
+					otherListMember.ContactListId.IN(listIdExpressions...).
+						AND(otherListMember.ContactId.EQ(table.ContactListContact.ContactId)),
+				),
+		)
+	}
+
+	switch r.parameters.Operation {
+	case Intersection:
+		for _, list := range sourceLists[1:] {
+			condition = condition.AND(inOtherList(list))
+		}
+	case Difference:
+		condition = condition.AND(NOT(inOtherList(sourceLists[1:]...)))
+	}
+
+	return condition
+}
+
+// split deals the contacts of the list to the groups at random, so that the groups differ by one contact at most. Every
+// contact goes to a group with the probability of the room left in it, which spreads the contacts like a shuffle would
+// without reading the whole list at once.
+func (r *runner) split(ctx context.Context, sourceList model.ContactList) (*Result, error) {
+	inSourceList := table.ContactListContact.ContactListId.EQ(UUID(sourceList.UniqueId)).AND(isNotDeleted)
+
+	total, err := countListContacts(ctx, r.db, inSourceList)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.progress.SetTotal(ctx, total); err != nil {
+		return nil, err
+	}
+
+	namePrefix := strings.TrimSpace(r.parameters.Name)
+	if namePrefix == "" {
+		namePrefix = sourceList.Name
+	}
+
+	lists := make([]model.ContactList, 0, r.parameters.Groups)
+	roomLeft := make([]int, 0, r.parameters.Groups)
+	totalRoomLeft := total
+
+	for index := 0; index < r.parameters.Groups; index++ {
+		list, err := createList(ctx, r.db, r.job.OrganizationId, fmt.Sprintf("%s - Group %d", namePrefix, index+1))
+		if err != nil {
+			return nil, err
+		}
+
+		lists = append(lists, *list)
+		room := total / r.parameters.Groups
+		if index < total%r.parameters.Groups {
+			room++
+		}
+		roomLeft = append(roomLeft, room)
+	}
+
+	added := make([]int, len(lists))
+	dealt := 0
+	lastContactId := uuid.Nil
+
+	for {
+		contactIds, err := fetchListContactIds(ctx, r.db, inSourceList, lastContactId)
+		if err != nil {
+			return nil, err
+		}
+
+		groups := make([][]uuid.UUID, len(lists))
+		for _, contactId := range contactIds {
+			group := dealt % len(lists)
+			// * contacts added to the list since it was counted are dealt in turn
+			if totalRoomLeft > 0 {
+				pick := rand.Intn(totalRoomLeft)
+				for group = 0; pick >= roomLeft[group]; group++ {
+					pick -= roomLeft[group]
+				}
+				roomLeft[group]--
+				totalRoomLeft--
+			}
+
+			groups[group] = append(groups[group], contactId)
+			dealt++
+		}
+
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for index, group := range groups {
+			addedToList, err := r.addToList(ctx, tx, lists[index], group)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			added[index] += addedToList
+		}
+
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+
+		if err := r.progress.ItemsProcessed(ctx, len(contactIds)); err != nil {
+			return nil, err
+		}
+
+		if len(contactIds) < batchSize {
+			break
+		}
+		lastContactId = contactIds[len(contactIds)-1]
+	}
+
+	result := &Result{Lists: make([]ResultList, 0, len(lists))}
+	for index, list := range lists {
+		result.Lists = append(result.Lists, ResultList{UniqueId: list.UniqueId, Name: list.Name, NumberOfContacts: added[index]})
+	}
+
+	return result, nil
+}
+
+// move takes the contacts out of the source list and adds them to the target list, the contacts asked to be moved which
+// are not in the source list are left out
+func (r *runner) move(ctx context.Context, sourceList model.ContactList, targetList model.ContactList) (*Result, error) {
+	contactsToMove := table.ContactListContact.ContactListId.EQ(UUID(sourceList.UniqueId)).AND(isNotDeleted)
+
+	if len(r.parameters.ContactIds) > 0 {
+		contactIdExpressions := make([]Expression, 0, len(r.parameters.ContactIds))
+		for _, contactId := range r.parameters.ContactIds {
+			contactIdExpressions = append(contactIdExpressions, UUID(contactId))
+		}
+		contactsToMove = contactsToMove.AND(table.ContactListContact.ContactId.IN(contactIdExpressions...))
+	}
+
+	total, err := countListContacts(ctx, r.db, contactsToMove)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.progress.SetTotal(ctx, total); err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	added := 0
+	lastContactId := uuid.Nil
+
+	for {
+		contactIds, err := fetchListContactIds(ctx, r.db, contactsToMove, lastContactId)
+		if err != nil {
+			return nil, err
+		}
+
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		removedContactIds, err := r.removeFromList(ctx, tx, sourceList, contactIds)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		addedToList, err := r.addToList(ctx, tx, targetList, removedContactIds)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+
+		result.Removed += len(removedContactIds)
+		added += addedToList
+
+		if err := r.progress.ItemsProcessed(ctx, len(contactIds)); err != nil {
+			return nil, err
+		}
+
+		if len(contactIds) < batchSize {
+			break
+		}
+		lastContactId = contactIds[len(contactIds)-1]
+	}
+
+	result.Lists = []ResultList{{UniqueId: targetList.UniqueId, Name: targetList.Name, NumberOfContacts: added}}
+	return result, nil
+}
+
+// dedupe removes the contacts of the list whose phone number is the same as the one of an older contact of the list, once
+// normalized. The contacts themselves are left as they are, the duplicate scan of the contacts merges them. The list is
+// read from its oldest contacts page by page, only the normalized phone numbers already seen are kept.
+func (r *runner) dedupe(ctx context.Context, sourceList model.ContactList) (*Result, error) {
+	inSourceList := table.ContactListContact.ContactListId.EQ(UUID(sourceList.UniqueId)).AND(isNotDeleted)
+
+	total, err := countListContacts(ctx, r.db, inSourceList)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.progress.SetTotal(ctx, total); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Lists: []ResultList{}}
+	seenPhoneNumbers := map[string]bool{}
+	var lastContact *model.Contact
+
+	for {
+		pageCondition := inSourceList
+		if lastContact != nil {
+			pageCondition = pageCondition.AND(
+				table.Contact.CreatedAt.GT(TimestampzT(lastContact.CreatedAt)).
+					OR(table.Contact.CreatedAt.EQ(TimestampzT(lastContact.CreatedAt)).AND(table.Contact.UniqueId.GT(UUID(lastContact.UniqueId)))),
+			)
+		}
+
+		var contacts []model.Contact
+
+		err := SELECT(table.Contact.UniqueId, table.Contact.PhoneNumber, table.Contact.CreatedAt).
+			FROM(listContactsTable).
+			WHERE(pageCondition).
+			ORDER_BY(table.Contact.CreatedAt.ASC(), table.Contact.UniqueId.ASC()).
+			LIMIT(batchSize).
+			QueryContext(ctx, r.db, &contacts)
+
+		if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+			return nil, err
+		}
+
+		duplicateContactIds := []uuid.UUID{}
+		for _, contact := range contacts {
+			phoneNumber := contact_duplicate_service.NormalizedPhoneNumber(contact.PhoneNumber)
+			if phoneNumber == "" {
+				continue
+			}
+			if seenPhoneNumbers[phoneNumber] {
+				duplicateContactIds = append(duplicateContactIds, contact.UniqueId)
+				continue
+			}
+			seenPhoneNumbers[phoneNumber] = true
+		}
+
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		removedContactIds, err := r.removeFromList(ctx, tx, sourceList, duplicateContactIds)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+
+		result.Removed += len(removedContactIds)
+
+		if err := r.progress.ItemsProcessed(ctx, len(contacts)); err != nil {
+			return nil, err
+		}
+
+		if len(contacts) < batchSize {
+			break
+		}
+		lastContact = &contacts[len(contacts)-1]
+	}
+
+	return result, nil
+}
+
+// addToList adds the contacts to the list, and records that they joined it for the ones which were not in it already
+func (r *runner) addToList(ctx context.Context, tx *sql.Tx, list model.ContactList, contactIds []uuid.UUID) (int, error) {
+	if len(contactIds) == 0 {
+		return 0, nil
+	}
+
+	records := make([]model.ContactListContact, 0, len(contactIds))
+	for _, contactId := range contactIds {
+		records = append(records, model.ContactListContact{
+			ContactListId: list.UniqueId,
+			ContactId:     contactId,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		})
+	}
+
+	var insertedRecords []model.ContactListContact
+
+	err := table.ContactListContact.
+		INSERT(table.ContactListContact.AllColumns).
+		MODELS(records).
+		ON_CONFLICT(table.ContactListContact.ContactListId, table.ContactListContact.ContactId).
+		DO_NOTHING().
+		RETURNING(table.ContactListContact.AllColumns).
+		QueryContext(ctx, tx, &insertedRecords)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return 0, err
+	}
+
+	activities := make([]contact_activity_service.Activity, 0, len(insertedRecords))
+	for _, record := range insertedRecords {
+		activities = append(activities, r.listActivity(model.ContactActivityTypeEnum_ListJoined, list, record.ContactId))
+	}
+
+	if err := contact_activity_service.Record(ctx, tx, activities...); err != nil {
+		return 0, err
+	}
+
+	return len(insertedRecords), nil
+}
+
+// removeFromList takes the contacts out of the list, records that they left it and returns the ones which were in it
+func (r *runner) removeFromList(ctx context.Context, tx *sql.Tx, list model.ContactList, contactIds []uuid.UUID) ([]uuid.UUID, error) {
+	if len(contactIds) == 0 {
+		return nil, nil
+	}
+
+	contactIdExpressions := make([]Expression, 0, len(contactIds))
+	for _, contactId := range contactIds {
+		contactIdExpressions = append(contactIdExpressions, UUID(contactId))
+	}
+
+	var deletedRecords []model.ContactListContact
+
+	err := table.ContactListContact.DELETE().
+		WHERE(
+			table.ContactListContact.ContactListId.EQ(UUID(list.UniqueId)).
+				AND(table.ContactListContact.ContactId.IN(contactIdExpressions...)),
+		).
+		RETURNING(table.ContactListContact.AllColumns).
+		QueryContext(ctx, tx, &deletedRecords)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	removedContactIds := make([]uuid.UUID, 0, len(deletedRecords))
+	activities := make([]contact_activity_service.Activity, 0, len(deletedRecords))
+
+	for _, record := range deletedRecords {
+		removedContactIds = append(removedContactIds, record.ContactId)
+		activities = append(activities, r.listActivity(model.ContactActivityTypeEnum_ListLeft, list, record.ContactId))
+	}
+
+	if err := contact_activity_service.Record(ctx, tx, activities...); err != nil {
+		return nil, err
+	}
+
+	return removedContactIds, nil
+}
+
+func (r *runner) listActivity(activityType model.ContactActivityTypeEnum, list model.ContactList, contactId uuid.UUID) contact_activity_service.Activity {
+	return contact_activity_service.Activity{
+		OrganizationId:       r.job.OrganizationId,
+		ContactId:            contactId,
+		OrganizationMemberId: r.job.CreatedByOrganizationMemberId,
+		Type:                 activityType,
+		Data: contact_activity_service.ListData{
+			ContactListId: list.UniqueId,
+			Name:          list.Name,
+		},
+	}
+}
+
+// fetchLists returns the lists of the organization by id, it fails when one of them has been deleted since the job was
+// queued
+func fetchLists(ctx context.Context, db qrm.Queryable, organizationId uuid.UUID, listIds []uuid.UUID) (map[uuid.UUID]model.ContactList, error) {
+	listIdExpressions := make([]Expression, 0, len(listIds))
+	for _, listId := range listIds {
+		listIdExpressions = append(listIdExpressions, UUID(listId))
+	}
+
+	var lists []model.ContactList
+
+	err := SELECT(table.ContactList.AllColumns).
+		FROM(table.ContactList).
+		WHERE(
+			table.ContactList.OrganizationId.EQ(UUID(organizationId)).
+				AND(table.ContactList.UniqueId.IN(listIdExpressions...)),
+		).
+		QueryContext(ctx, db, &lists)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	listsById := make(map[uuid.UUID]model.ContactList, len(lists))
+	for _, list := range lists {
+		listsById[list.UniqueId] = list
+	}
+
+	for _, listId := range listIds {
+		if _, ok := listsById[listId]; !ok {
+			return nil, fmt.Errorf("the list %s has been deleted", listId.String())
+		}
+	}
+
+	return listsById, nil
+}
+
+// the rows of the lists joined with their contacts, the conditions of the operations match rows of this table
+var listContactsTable = table.ContactListContact.
+	INNER_JOIN(table.Contact, table.Contact.UniqueId.EQ(table.ContactListContact.ContactId))
+
+// the deleted contacts are left out of every operation
+var isNotDeleted = table.Contact.Status.NOT_EQ(utils.EnumExpression(model.ContactStatusEnum_Deleted.String()))
+
+// countListContacts returns the number of distinct contacts of the rows matching the condition
+func countListContacts(ctx context.Context, db qrm.Queryable, condition BoolExpression) (int, error) {
+	var count struct {
+		Count int
+	}
+
+	err := SELECT(COUNT(DISTINCT(table.ContactListContact.ContactId)).AS("count")).
+		FROM(listContactsTable).
+		WHERE(condition).
+		QueryContext(ctx, db, &count)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count.Count, nil
+}
+
+// fetchListContactIds returns the next page of the ids of the contacts of the rows matching the condition, in the order
+// of their ids starting after the given one
+func fetchListContactIds(ctx context.Context, db qrm.Queryable, condition BoolExpression, afterContactId uuid.UUID) ([]uuid.UUID, error) {
+	var rows []model.ContactListContact
+
+	err := SELECT(table.ContactListContact.ContactListId, table.ContactListContact.ContactId).
+		FROM(listContactsTable).
+		WHERE(condition.AND(table.ContactListContact.ContactId.GT(UUID(afterContactId)))).
+		ORDER_BY(table.ContactListContact.ContactId.ASC()).
+		LIMIT(batchSize).
+		QueryContext(ctx, db, &rows)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	contactIds := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		contactIds = append(contactIds, row.ContactId)
+	}
+
+	return contactIds, nil
+}
+
+func createList(ctx context.Context, db qrm.Queryable, organizationId uuid.UUID, name string) (*model.ContactList, error) {
+	var list model.ContactList
+
+	err := table.ContactList.
+		INSERT(table.ContactList.MutableColumns).
+		MODEL(model.ContactList{
+			Name:           name,
+			OrganizationId: organizationId,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}).
+		RETURNING(table.ContactList.AllColumns).
+		QueryContext(ctx, db, &list)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
@@ -0,0 +1,489 @@
+package contact_timeline_service
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/contact_activity_service"
+	"github.com/wapikit/wapikit/internal/core/contact_lifecycle_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// source reads the newest events of one kind for the contact, at most the limit, along with the number of them there are
+type source func(ctx context.Context, db qrm.Queryable, contact model.Contact, limit int64) ([]api_types.ContactTimelineEventSchema, int, error)
+
+var sources = []source{
+	campaignMessages,
+	linkClicks,
+	conversationsStarted,
+	conversationStatusChanges,
+	conversationAssignments,
+	conversationUnassignments,
+	contactActivities,
+}
+
+// GetTimeline returns a page of the events of the contact, newest first, and the number of events there are.
+//
+// The events are kept in different tables, so the events of the page can not be selected in one query. Each source is
+// asked for its newest events up to the end of the page, which is enough to hold the page once they are merged.
+func GetTimeline(ctx context.Context, db qrm.Queryable, contact model.Contact, page, perPage int64) ([]api_types.ContactTimelineEventSchema, int, error) {
+	limit := page * perPage
+	events := []api_types.ContactTimelineEventSchema{}
+	total := 0
+
+	for _, source := range sources {
+		sourceEvents, count, err := source(ctx, db, contact, limit)
+		if err != nil {
+			return nil, 0, err
+		}
+		events = append(events, sourceEvents...)
+		total += count
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+
+	offset := (page - 1) * perPage
+	if offset >= int64(len(events)) {
+		return []api_types.ContactTimelineEventSchema{}, total, nil
+	}
+
+	if limit > int64(len(events)) {
+		limit = int64(len(events))
+	}
+
+	return events[offset:limit], total, nil
+}
+
+func count(ctx context.Context, db qrm.Queryable, from ReadableTable, condition BoolExpression) (int, error) {
+	var dest struct {
+		Count int
+	}
+
+	err := SELECT(COUNT(STAR).AS("count")).
+		FROM(from).
+		WHERE(condition).
+		QueryContext(ctx, db, &dest)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return dest.Count, nil
+}
+
+func campaignMessages(ctx context.Context, db qrm.Queryable, contact model.Contact, limit int64) ([]api_types.ContactTimelineEventSchema, int, error) {
+	from := table.Message.
+		INNER_JOIN(table.Campaign, table.Campaign.UniqueId.EQ(table.Message.CampaignId))
+	condition := table.Message.ContactId.EQ(UUID(contact.UniqueId))
+
+	var messages []struct {
+		model.Message
+		Campaign model.Campaign
+	}
+
+	err := SELECT(table.Message.AllColumns, table.Campaign.AllColumns).
+		FROM(from).
+		WHERE(condition).
+		ORDER_BY(table.Message.CreatedAt.DESC()).
+		LIMIT(limit).
+		QueryContext(ctx, db, &messages)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, 0, err
+	}
+
+	events := make([]api_types.ContactTimelineEventSchema, 0, len(messages))
+	for _, message := range messages {
+		messageId := message.Message.UniqueId.String()
+		campaignId := message.Campaign.UniqueId.String()
+		messageStatus := api_types.MessageStatusEnum(message.Message.Status.String())
+
+		events = append(events, api_types.ContactTimelineEventSchema{
+			UniqueId:      messageId,
+			EventType:     api_types.CampaignMessage,
+			CreatedAt:     message.Message.CreatedAt,
+			MessageId:     &messageId,
+			MessageStatus: &messageStatus,
+			CampaignId:    &campaignId,
+			CampaignName:  &message.Campaign.Name,
+		})
+	}
+
+	total, err := count(ctx, db, from, condition)
+	return events, total, err
+}
+
+func linkClicks(ctx context.Context, db qrm.Queryable, contact model.Contact, limit int64) ([]api_types.ContactTimelineEventSchema, int, error) {
+	from := table.TrackLinkClick.
+		INNER_JOIN(table.TrackLink, table.TrackLink.UniqueId.EQ(table.TrackLinkClick.TrackLinkId)).
+		INNER_JOIN(table.Campaign, table.Campaign.UniqueId.EQ(table.TrackLink.CampaignId))
+	condition := table.TrackLinkClick.ContactId.EQ(UUID(contact.UniqueId))
+
+	var clicks []struct {
+		model.TrackLinkClick
+		TrackLink model.TrackLink
+		Campaign  model.Campaign
+	}
+
+	err := SELECT(table.TrackLinkClick.AllColumns, table.TrackLink.AllColumns, table.Campaign.AllColumns).
+		FROM(from).
+		WHERE(condition).
+		ORDER_BY(table.TrackLinkClick.CreatedAt.DESC()).
+		LIMIT(limit).
+		QueryContext(ctx, db, &clicks)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, 0, err
+	}
+
+	events := make([]api_types.ContactTimelineEventSchema, 0, len(clicks))
+	for _, click := range clicks {
+		campaignId := click.Campaign.UniqueId.String()
+
+		events = append(events, api_types.ContactTimelineEventSchema{
+			UniqueId:     click.TrackLinkClick.UniqueId.String(),
+			EventType:    api_types.LinkClicked,
+			CreatedAt:    click.TrackLinkClick.CreatedAt,
+			CampaignId:   &campaignId,
+			CampaignName: &click.Campaign.Name,
+			Url:          click.TrackLink.DestinationUrl,
+		})
+	}
+
+	total, err := count(ctx, db, from, condition)
+	return events, total, err
+}
+
+func conversationsStarted(ctx context.Context, db qrm.Queryable, contact model.Contact, limit int64) ([]api_types.ContactTimelineEventSchema, int, error) {
+	condition := table.Conversation.ContactId.EQ(UUID(contact.UniqueId))
+
+	var conversations []model.Conversation
+
+	err := SELECT(table.Conversation.AllColumns).
+		FROM(table.Conversation).
+		WHERE(condition).
+		ORDER_BY(table.Conversation.CreatedAt.DESC()).
+		LIMIT(limit).
+		QueryContext(ctx, db, &conversations)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, 0, err
+	}
+
+	events := make([]api_types.ContactTimelineEventSchema, 0, len(conversations))
+	for _, conversation := range conversations {
+		conversationId := conversation.UniqueId.String()
+
+		event := api_types.ContactTimelineEventSchema{
+			UniqueId:       conversationId,
+			EventType:      api_types.ConversationStarted,
+			CreatedAt:      conversation.CreatedAt,
+			ConversationId: &conversationId,
+		}
+
+		if conversation.InitiatedByCampaignId != nil {
+			campaignId := conversation.InitiatedByCampaignId.String()
+			event.CampaignId = &campaignId
+		}
+
+		events = append(events, event)
+	}
+
+	total, err := count(ctx, db, table.Conversation, condition)
+	return events, total, err
+}
+
+func conversationStatusChanges(ctx context.Context, db qrm.Queryable, contact model.Contact, limit int64) ([]api_types.ContactTimelineEventSchema, int, error) {
+	condition := table.ConversationTimelineEvent.ConversationId.IN(contact_lifecycle_service.ContactConversationIds(contact))
+
+	var timelineEvents []struct {
+		model.ConversationTimelineEvent
+		Actor struct {
+			model.OrganizationMember
+			User model.User
+		}
+	}
+
+	err := SELECT(
+		table.ConversationTimelineEvent.AllColumns,
+		table.OrganizationMember.AllColumns,
+		table.User.AllColumns,
+	).
+		FROM(table.ConversationTimelineEvent.
+			LEFT_JOIN(table.OrganizationMember, table.OrganizationMember.UniqueId.EQ(table.ConversationTimelineEvent.ActorOrganizationMemberId)).
+			LEFT_JOIN(table.User, table.User.UniqueId.EQ(table.OrganizationMember.UserId)),
+		).
+		WHERE(condition).
+		ORDER_BY(table.ConversationTimelineEvent.CreatedAt.DESC()).
+		LIMIT(limit).
+		QueryContext(ctx, db, &timelineEvents)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, 0, err
+	}
+
+	events := make([]api_types.ContactTimelineEventSchema, 0, len(timelineEvents))
+	for _, timelineEvent := range timelineEvents {
+		conversationId := timelineEvent.ConversationId.String()
+		conversationEventType := api_types.ConversationTimelineEventTypeEnum(timelineEvent.EventType.String())
+		fromStatus := api_types.ConversationStatusEnum(timelineEvent.FromStatus.String())
+		toStatus := api_types.ConversationStatusEnum(timelineEvent.ToStatus.String())
+
+		event := api_types.ContactTimelineEventSchema{
+			UniqueId:               timelineEvent.ConversationTimelineEvent.UniqueId.String(),
+			EventType:              api_types.ConversationStatusChanged,
+			CreatedAt:              timelineEvent.ConversationTimelineEvent.CreatedAt,
+			ConversationId:         &conversationId,
+			ConversationEventType:  &conversationEventType,
+			FromConversationStatus: &fromStatus,
+			ToConversationStatus:   &toStatus,
+		}
+
+		if timelineEvent.ActorOrganizationMemberId != nil {
+			actorMemberId := timelineEvent.ActorOrganizationMemberId.String()
+			event.ActorMemberId = &actorMemberId
+			event.ActorName = &timelineEvent.Actor.User.Name
+		}
+
+		events = append(events, event)
+	}
+
+	total, err := count(ctx, db, table.ConversationTimelineEvent, condition)
+	return events, total, err
+}
+
+// assignment is an assignment of a conversation of the contact, with the member it was assigned to
+type assignment struct {
+	model.ConversationAssignment
+	Member struct {
+		model.OrganizationMember
+		User model.User
+	}
+}
+
+func fetchAssignments(ctx context.Context, db qrm.Queryable, condition BoolExpression, orderBy ColumnTimestampz, limit int64) ([]assignment, error) {
+	var assignments []assignment
+
+	err := SELECT(
+		table.ConversationAssignment.AllColumns,
+		table.OrganizationMember.AllColumns,
+		table.User.AllColumns,
+	).
+		FROM(table.ConversationAssignment.
+			INNER_JOIN(table.OrganizationMember, table.OrganizationMember.UniqueId.EQ(table.ConversationAssignment.AssignedToOrganizationMemberId)).
+			INNER_JOIN(table.User, table.User.UniqueId.EQ(table.OrganizationMember.UserId)),
+		).
+		WHERE(condition).
+		ORDER_BY(orderBy.DESC()).
+		LIMIT(limit).
+		QueryContext(ctx, db, &assignments)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// assignmentEvent converts an assignment, an assignment has no id of its own so the event is identified by the
+// conversation and the member along with the type
+func assignmentEvent(assignment assignment, eventType api_types.ContactTimelineEventTypeEnum) api_types.ContactTimelineEventSchema {
+	conversationId := assignment.ConversationId.String()
+	assignedMemberId := assignment.AssignedToOrganizationMemberId.String()
+
+	createdAt := assignment.ConversationAssignment.CreatedAt
+	if eventType == api_types.ConversationUnassigned {
+		createdAt = assignment.ConversationAssignment.UpdatedAt
+	}
+
+	return api_types.ContactTimelineEventSchema{
+		UniqueId:           string(eventType) + ":" + conversationId + ":" + assignedMemberId,
+		EventType:          eventType,
+		CreatedAt:          createdAt,
+		ConversationId:     &conversationId,
+		AssignedMemberId:   &assignedMemberId,
+		AssignedMemberName: &assignment.Member.User.Name,
+	}
+}
+
+func conversationAssignments(ctx context.Context, db qrm.Queryable, contact model.Contact, limit int64) ([]api_types.ContactTimelineEventSchema, int, error) {
+	condition := table.ConversationAssignment.ConversationId.IN(contact_lifecycle_service.ContactConversationIds(contact))
+
+	assignments, err := fetchAssignments(ctx, db, condition, table.ConversationAssignment.CreatedAt, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	events := make([]api_types.ContactTimelineEventSchema, 0, len(assignments))
+	for _, assignment := range assignments {
+		events = append(events, assignmentEvent(assignment, api_types.ConversationAssigned))
+	}
+
+	total, err := count(ctx, db, table.ConversationAssignment, condition)
+	return events, total, err
+}
+
+// conversationUnassignments are the assignments which ended, they were ended when they were last updated
+func conversationUnassignments(ctx context.Context, db qrm.Queryable, contact model.Contact, limit int64) ([]api_types.ContactTimelineEventSchema, int, error) {
+	condition := table.ConversationAssignment.ConversationId.IN(contact_lifecycle_service.ContactConversationIds(contact)).
+		AND(table.ConversationAssignment.Status.EQ(utils.EnumExpression(model.ConversationAssignmentStatus_Unassigned.String())))
+
+	assignments, err := fetchAssignments(ctx, db, condition, table.ConversationAssignment.UpdatedAt, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	events := make([]api_types.ContactTimelineEventSchema, 0, len(assignments))
+	for _, assignment := range assignments {
+		events = append(events, assignmentEvent(assignment, api_types.ConversationUnassigned))
+	}
+
+	total, err := count(ctx, db, table.ConversationAssignment, condition)
+	return events, total, err
+}
+
+func contactActivities(ctx context.Context, db qrm.Queryable, contact model.Contact, limit int64) ([]api_types.ContactTimelineEventSchema, int, error) {
+	condition := table.ContactActivity.ContactId.EQ(UUID(contact.UniqueId))
+
+	var activities []struct {
+		model.ContactActivity
+		Actor struct {
+			model.OrganizationMember
+			User model.User
+		}
+	}
+
+	err := SELECT(
+		table.ContactActivity.AllColumns,
+		table.OrganizationMember.AllColumns,
+		table.User.AllColumns,
+	).
+		FROM(table.ContactActivity.
+			LEFT_JOIN(table.OrganizationMember, table.OrganizationMember.UniqueId.EQ(table.ContactActivity.OrganizationMemberId)).
+			LEFT_JOIN(table.User, table.User.UniqueId.EQ(table.OrganizationMember.UserId)),
+		).
+		WHERE(condition).
+		ORDER_BY(table.ContactActivity.CreatedAt.DESC()).
+		LIMIT(limit).
+		QueryContext(ctx, db, &activities)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, 0, err
+	}
+
+	events := make([]api_types.ContactTimelineEventSchema, 0, len(activities))
+	for _, activity := range activities {
+		event, err := activityEvent(activity.ContactActivity)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if activity.ContactActivity.OrganizationMemberId != nil {
+			actorMemberId := activity.ContactActivity.OrganizationMemberId.String()
+			event.ActorMemberId = &actorMemberId
+			event.ActorName = &activity.Actor.User.Name
+		}
+
+		events = append(events, event)
+	}
+
+	total, err := count(ctx, db, table.ContactActivity, condition)
+	return events, total, err
+}
+
+// activityEvent converts an activity, its data is decoded into the properties of the event of its type
+func activityEvent(activity model.ContactActivity) (api_types.ContactTimelineEventSchema, error) {
+	event := api_types.ContactTimelineEventSchema{
+		UniqueId:  activity.UniqueId.String(),
+		EventType: api_types.ContactTimelineEventTypeEnum(activity.Type.String()),
+		CreatedAt: activity.CreatedAt,
+	}
+
+	if activity.Data == nil {
+		return event, nil
+	}
+
+	data := []byte(*activity.Data)
+
+	switch activity.Type {
+	case model.ContactActivityTypeEnum_ListJoined, model.ContactActivityTypeEnum_ListLeft:
+		var listData contact_activity_service.ListData
+		if err := json.Unmarshal(data, &listData); err != nil {
+			return event, err
+		}
+		listId := listData.ContactListId.String()
+		event.ListId = &listId
+		event.ListName = &listData.Name
+
+	case model.ContactActivityTypeEnum_StatusChanged:
+		var statusData contact_activity_service.StatusData
+		if err := json.Unmarshal(data, &statusData); err != nil {
+			return event, err
+		}
+		fromStatus := api_types.ContactStatusEnum(statusData.OldStatus)
+		toStatus := api_types.ContactStatusEnum(statusData.NewStatus)
+		event.FromStatus = &fromStatus
+		event.ToStatus = &toStatus
+
+	case model.ContactActivityTypeEnum_AttributesChanged:
+		var attributesData contact_activity_service.AttributesData
+		if err := json.Unmarshal(data, &attributesData); err != nil {
+			return event, err
+		}
+		attributeChanges := make([]api_types.ContactAttributeChangeSchema, 0, len(attributesData.Changes))
+		for _, change := range attributesData.Changes {
+			attributeChange := api_types.ContactAttributeChangeSchema{Key: change.Key}
+			if change.OldValue != nil {
+				oldValue := change.OldValue
+				attributeChange.OldValue = &oldValue
+			}
+			if change.NewValue != nil {
+				newValue := change.NewValue
+				attributeChange.NewValue = &newValue
+			}
+			attributeChanges = append(attributeChanges, attributeChange)
+		}
+		event.AttributeChanges = &attributeChanges
+
+	case model.ContactActivityTypeEnum_Merged:
+		var mergedData contact_activity_service.MergedData
+		if err := json.Unmarshal(data, &mergedData); err != nil {
+			return event, err
+		}
+		mergedContactIds := make([]string, 0, len(mergedData.MergedContactIds))
+		for _, mergedContactId := range mergedData.MergedContactIds {
+			mergedContactIds = append(mergedContactIds, mergedContactId.String())
+		}
+		event.MergedContactIds = &mergedContactIds
+
+	case model.ContactActivityTypeEnum_OptedIn, model.ContactActivityTypeEnum_OptedOut:
+		var consentData contact_activity_service.ConsentData
+		if err := json.Unmarshal(data, &consentData); err != nil {
+			return event, err
+		}
+		consentCategory := api_types.ContactConsentCategoryEnum(consentData.Category)
+		consentSource := api_types.ContactConsentSourceEnum(consentData.Source)
+		event.ConsentCategory = &consentCategory
+		event.ConsentSource = &consentSource
+		event.ConsentEvidence = &consentData.Evidence
+		if consentText, ok := consentData.Evidence["consentText"].(string); ok {
+			event.ConsentText = &consentText
+		}
+		if consentData.CaptureSourceId != nil {
+			captureSourceId := consentData.CaptureSourceId.String()
+			event.CaptureSourceId = &captureSourceId
+			event.CaptureSourceName = &consentData.CaptureSourceName
+		}
+	}
+
+	return event, nil
+}
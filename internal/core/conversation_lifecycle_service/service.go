@@ -0,0 +1,218 @@
+package conversation_lifecycle_service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/api_server_events"
+	cache "github.com/wapikit/wapikit/internal/core/redis"
+	"github.com/wapikit/wapikit/internal/core/sla_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+var ErrInvalidTransition = errors.New("conversation can not be moved to this status from its current status")
+
+// allowedTransitions lists the statuses every status can move to, deleted conversations never change anymore
+var allowedTransitions = map[model.ConversationStatusEnum][]model.ConversationStatusEnum{
+	model.ConversationStatusEnum_Active: {
+		model.ConversationStatusEnum_Resolved,
+		model.ConversationStatusEnum_Snoozed,
+		model.ConversationStatusEnum_Closed,
+	},
+	model.ConversationStatusEnum_Snoozed: {
+		model.ConversationStatusEnum_Active,
+		model.ConversationStatusEnum_Resolved,
+		model.ConversationStatusEnum_Snoozed,
+	},
+	model.ConversationStatusEnum_Resolved: {
+		model.ConversationStatusEnum_Active,
+		model.ConversationStatusEnum_Closed,
+	},
+	model.ConversationStatusEnum_Closed: {
+		model.ConversationStatusEnum_Active,
+	},
+}
+
+// Transition describes a status change of a conversation and what caused it
+type Transition struct {
+	To      model.ConversationStatusEnum
+	Trigger model.ConversationTimelineEventTriggerEnum
+	// the member changing the status, nil when the contact or the system does
+	ActorMemberId *uuid.UUID
+	// required when snoozing
+	SnoozedUntil *time.Time
+}
+
+// TransitionConversation moves the conversation to the status of the transition and records it in the timeline of the conversation.
+// ErrInvalidTransition is returned when the conversation can not move to the status, or its status changed in the meantime.
+func TransitionConversation(ctx context.Context, db *sql.DB, conversation model.Conversation, transition Transition) (*model.Conversation, *model.ConversationTimelineEvent, error) {
+	if !slices.Contains(allowedTransitions[conversation.Status], transition.To) {
+		return nil, nil, ErrInvalidTransition
+	}
+
+	if transition.To == model.ConversationStatusEnum_Snoozed && transition.SnoozedUntil == nil {
+		return nil, nil, ErrInvalidTransition
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	var updatedConversation model.Conversation
+
+	snoozedUntil := TimestampzExp(NULL)
+	if transition.To == model.ConversationStatusEnum_Snoozed {
+		snoozedUntil = TimestampzT(*transition.SnoozedUntil)
+	}
+
+	// * the current status is part of the condition, so that concurrent transitions of the conversation do not both apply
+	err = table.Conversation.UPDATE(table.Conversation.Status, table.Conversation.SnoozedUntil, table.Conversation.UpdatedAt).
+		SET(
+			utils.EnumExpression(transition.To.String()),
+			snoozedUntil,
+			TimestampzT(time.Now()),
+		).
+		WHERE(
+			table.Conversation.UniqueId.EQ(UUID(conversation.UniqueId)).
+				AND(table.Conversation.Status.EQ(utils.EnumExpression(conversation.Status.String()))),
+		).
+		RETURNING(table.Conversation.AllColumns).
+		QueryContext(ctx, tx, &updatedConversation)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, nil, ErrInvalidTransition
+		}
+		return nil, nil, err
+	}
+
+	if transition.To == model.ConversationStatusEnum_Resolved {
+		err = sla_service.RecordResolution(ctx, tx, conversation.UniqueId)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var timelineEvent model.ConversationTimelineEvent
+
+	err = table.ConversationTimelineEvent.INSERT(table.ConversationTimelineEvent.MutableColumns).
+		MODEL(model.ConversationTimelineEvent{
+			CreatedAt:                 time.Now(),
+			ConversationId:            conversation.UniqueId,
+			OrganizationId:            conversation.OrganizationId,
+			EventType:                 eventTypeOf(conversation.Status, transition.To),
+			Trigger:                   transition.Trigger,
+			FromStatus:                conversation.Status,
+			ToStatus:                  transition.To,
+			ActorOrganizationMemberId: transition.ActorMemberId,
+			SnoozedUntil:              updatedConversation.SnoozedUntil,
+		}).
+		RETURNING(table.ConversationTimelineEvent.AllColumns).
+		QueryContext(ctx, tx, &timelineEvent)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	return &updatedConversation, &timelineEvent, nil
+}
+
+func eventTypeOf(from, to model.ConversationStatusEnum) model.ConversationTimelineEventTypeEnum {
+	switch to {
+	case model.ConversationStatusEnum_Resolved:
+		return model.ConversationTimelineEventTypeEnum_Resolved
+	case model.ConversationStatusEnum_Snoozed:
+		return model.ConversationTimelineEventTypeEnum_Snoozed
+	case model.ConversationStatusEnum_Closed:
+		return model.ConversationTimelineEventTypeEnum_Closed
+	}
+
+	if from == model.ConversationStatusEnum_Snoozed {
+		return model.ConversationTimelineEventTypeEnum_Woken
+	}
+	return model.ConversationTimelineEventTypeEnum_Reopened
+}
+
+// PublishTransition lets the websocket server notify the members of the organization about the status change
+func PublishTransition(redisClient *cache.RedisClient, channelName string, timelineEvent api_types.ConversationTimelineEventSchema, organizationId string) {
+	statusChangedEvent := api_server_events.NewConversationStatusChangedEvent(organizationId, timelineEvent)
+	redisClient.PublishMessageToRedisChannel(channelName, statusChangedEvent.ToJson())
+
+	if timelineEvent.ToStatus == api_types.ConversationStatusEnumClosed {
+		closedEvent := api_server_events.NewConversationClosedEvent(organizationId, timelineEvent.ConversationId)
+		redisClient.PublishMessageToRedisChannel(channelName, closedEvent.ToJson())
+	}
+}
+
+// GetTimeline returns the status changes of the conversation, oldest first
+func GetTimeline(ctx context.Context, db qrm.Queryable, conversationId uuid.UUID) ([]api_types.ConversationTimelineEventSchema, error) {
+	var timelineEvents []struct {
+		model.ConversationTimelineEvent
+		Actor struct {
+			model.OrganizationMember
+			User model.User
+		}
+	}
+
+	err := SELECT(
+		table.ConversationTimelineEvent.AllColumns,
+		table.OrganizationMember.AllColumns,
+		table.User.AllColumns,
+	).
+		FROM(table.ConversationTimelineEvent.
+			LEFT_JOIN(table.OrganizationMember, table.OrganizationMember.UniqueId.EQ(table.ConversationTimelineEvent.ActorOrganizationMemberId)).
+			LEFT_JOIN(table.User, table.User.UniqueId.EQ(table.OrganizationMember.UserId)),
+		).
+		WHERE(table.ConversationTimelineEvent.ConversationId.EQ(UUID(conversationId))).
+		ORDER_BY(table.ConversationTimelineEvent.CreatedAt.ASC()).
+		QueryContext(ctx, db, &timelineEvents)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	timelineToReturn := make([]api_types.ConversationTimelineEventSchema, 0, len(timelineEvents))
+	for _, timelineEvent := range timelineEvents {
+		timelineToReturn = append(timelineToReturn, ToSchema(timelineEvent.ConversationTimelineEvent, timelineEvent.Actor.User.Name))
+	}
+
+	return timelineToReturn, nil
+}
+
+// ToSchema converts a timeline event, the actor name is ignored for events not caused by a member
+func ToSchema(timelineEvent model.ConversationTimelineEvent, actorName string) api_types.ConversationTimelineEventSchema {
+	schema := api_types.ConversationTimelineEventSchema{
+		UniqueId:       timelineEvent.UniqueId.String(),
+		ConversationId: timelineEvent.ConversationId.String(),
+		CreatedAt:      timelineEvent.CreatedAt,
+		EventType:      api_types.ConversationTimelineEventTypeEnum(timelineEvent.EventType.String()),
+		Trigger:        api_types.ConversationTimelineEventTriggerEnum(timelineEvent.Trigger.String()),
+		FromStatus:     api_types.ConversationStatusEnum(timelineEvent.FromStatus.String()),
+		ToStatus:       api_types.ConversationStatusEnum(timelineEvent.ToStatus.String()),
+		SnoozedUntil:   timelineEvent.SnoozedUntil,
+	}
+
+	if timelineEvent.ActorOrganizationMemberId != nil {
+		actorMemberId := timelineEvent.ActorOrganizationMemberId.String()
+		schema.ActorMemberId = &actorMemberId
+		schema.ActorName = &actorName
+	}
+
+	return schema
+}
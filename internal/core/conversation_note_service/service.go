@@ -0,0 +1,309 @@
+package conversation_note_service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/api_server_events"
+	cache "github.com/wapikit/wapikit/internal/core/redis"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+var (
+	ErrEmptyNote      = errors.New("content of the note is required")
+	ErrMemberNotFound = errors.New("mentioned member not found")
+
+	mentionNotificationType = "ConversationMention"
+	conversationsCtaBase    = "/conversations?id="
+)
+
+// descriptions of mention notifications only quote the beginning of long notes
+const notificationPreviewLength = 140
+
+type noteWithDetails struct {
+	model.ConversationNote
+	Author struct {
+		model.OrganizationMember
+		User model.User
+	}
+	Mentions []model.ConversationNoteMention
+}
+
+// CreatedNote is the note along with the notifications created for the members it mentions
+type CreatedNote struct {
+	OrganizationId string
+	Note           api_types.ConversationNoteSchema
+	Notifications  []model.Notification
+}
+
+// CreateNote leaves a note of the author on the conversation, every mentioned member except the author gets a notification
+func CreateNote(ctx context.Context, db *sql.DB, conversation model.Conversation, author model.OrganizationMember, authorName, content string, mentionedMemberIds []string) (*CreatedNote, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, ErrEmptyNote
+	}
+
+	mentionedMembers, err := fetchMentionedMembers(ctx, db, conversation.OrganizationId, mentionedMemberIds)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var insertedNote model.ConversationNote
+
+	err = table.ConversationNote.INSERT(table.ConversationNote.MutableColumns).
+		MODEL(model.ConversationNote{
+			ConversationId:             conversation.UniqueId,
+			OrganizationId:             conversation.OrganizationId,
+			AuthorOrganizationMemberId: &author.UniqueId,
+			Content:                    content,
+			CreatedAt:                  time.Now(),
+			UpdatedAt:                  time.Now(),
+		}).
+		RETURNING(table.ConversationNote.AllColumns).
+		QueryContext(ctx, tx, &insertedNote)
+
+	if err != nil {
+		return nil, err
+	}
+
+	authorMemberId := author.UniqueId.String()
+	createdNote := &CreatedNote{
+		OrganizationId: conversation.OrganizationId.String(),
+		Note: api_types.ConversationNoteSchema{
+			UniqueId:           insertedNote.UniqueId.String(),
+			ConversationId:     insertedNote.ConversationId.String(),
+			CreatedAt:          insertedNote.CreatedAt,
+			Content:            insertedNote.Content,
+			AuthorMemberId:     &authorMemberId,
+			AuthorName:         &authorName,
+			MentionedMemberIds: []string{},
+		},
+	}
+
+	if len(mentionedMembers) == 0 {
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return createdNote, nil
+	}
+
+	mentions := make([]model.ConversationNoteMention, 0, len(mentionedMembers))
+	notifications := make([]model.Notification, 0, len(mentionedMembers))
+	ctaUrl := conversationsCtaBase + conversation.UniqueId.String()
+	title := fmt.Sprintf("%s mentioned you in a conversation", authorName)
+	description := content
+	if len(description) > notificationPreviewLength {
+		description = description[:notificationPreviewLength] + "..."
+	}
+
+	for _, member := range mentionedMembers {
+		mentions = append(mentions, model.ConversationNoteMention{
+			ConversationNoteId:   insertedNote.UniqueId,
+			OrganizationMemberId: member.UniqueId,
+			CreatedAt:            time.Now(),
+		})
+		createdNote.Note.MentionedMemberIds = append(createdNote.Note.MentionedMemberIds, member.UniqueId.String())
+
+		if member.UniqueId == author.UniqueId {
+			continue
+		}
+
+		notifications = append(notifications, model.Notification{
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+			CtaUrl:      &ctaUrl,
+			Title:       title,
+			Description: description,
+			Type:        &mentionNotificationType,
+			UserId:      &member.UserId,
+		})
+	}
+
+	_, err = table.ConversationNoteMention.INSERT(table.ConversationNoteMention.AllColumns).
+		MODELS(mentions).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(notifications) > 0 {
+		err = table.Notification.INSERT(table.Notification.MutableColumns).
+			MODELS(notifications).
+			RETURNING(table.Notification.AllColumns).
+			QueryContext(ctx, tx, &createdNote.Notifications)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return createdNote, nil
+}
+
+// PublishNoteEvents lets the websocket server broadcast the note to the organization and deliver the mention notifications
+func PublishNoteEvents(redisClient *cache.RedisClient, channelName string, createdNote CreatedNote) {
+	noteEvent := api_server_events.NewNewConversationNoteEvent(createdNote.OrganizationId, createdNote.Note)
+	redisClient.PublishMessageToRedisChannel(channelName, noteEvent.ToJson())
+
+	for _, notification := range createdNote.Notifications {
+		notificationEvent := api_server_events.NewNewNotificationEvent(createdNote.OrganizationId, notification.UserId.String(), api_types.NotificationSchema{
+			UniqueId:    notification.UniqueId.String(),
+			CreatedAt:   notification.CreatedAt,
+			CtaUrl:      notification.CtaUrl,
+			Title:       notification.Title,
+			Description: notification.Description,
+			Type:        *notification.Type,
+			Read:        false,
+		})
+		redisClient.PublishMessageToRedisChannel(channelName, notificationEvent.ToJson())
+	}
+}
+
+// GetNotes returns the notes with the ids, keyed by id
+func GetNotes(ctx context.Context, db qrm.Queryable, noteIds []uuid.UUID) (map[uuid.UUID]api_types.ConversationNoteSchema, error) {
+	notesToReturn := make(map[uuid.UUID]api_types.ConversationNoteSchema, len(noteIds))
+
+	if len(noteIds) == 0 {
+		return notesToReturn, nil
+	}
+
+	noteIdExpressions := make([]Expression, 0, len(noteIds))
+	for _, noteId := range noteIds {
+		noteIdExpressions = append(noteIdExpressions, UUID(noteId))
+	}
+
+	var notes []noteWithDetails
+
+	err := SELECT(
+		table.ConversationNote.AllColumns,
+		table.OrganizationMember.AllColumns,
+		table.User.AllColumns,
+		table.ConversationNoteMention.AllColumns,
+	).
+		FROM(table.ConversationNote.
+			LEFT_JOIN(table.OrganizationMember, table.OrganizationMember.UniqueId.EQ(table.ConversationNote.AuthorOrganizationMemberId)).
+			LEFT_JOIN(table.User, table.User.UniqueId.EQ(table.OrganizationMember.UserId)).
+			LEFT_JOIN(table.ConversationNoteMention, table.ConversationNoteMention.ConversationNoteId.EQ(table.ConversationNote.UniqueId)),
+		).
+		WHERE(table.ConversationNote.UniqueId.IN(noteIdExpressions...)).
+		QueryContext(ctx, db, &notes)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	for _, note := range notes {
+		mentionedMemberIds := make([]string, 0, len(note.Mentions))
+		for _, mention := range note.Mentions {
+			mentionedMemberIds = append(mentionedMemberIds, mention.OrganizationMemberId.String())
+		}
+
+		noteToReturn := api_types.ConversationNoteSchema{
+			UniqueId:           note.UniqueId.String(),
+			ConversationId:     note.ConversationId.String(),
+			CreatedAt:          note.CreatedAt,
+			Content:            note.Content,
+			MentionedMemberIds: mentionedMemberIds,
+		}
+
+		// * notes of removed members are kept without their author
+		if note.AuthorOrganizationMemberId != nil {
+			authorMemberId := note.AuthorOrganizationMemberId.String()
+			authorName := note.Author.User.Name
+			noteToReturn.AuthorMemberId = &authorMemberId
+			noteToReturn.AuthorName = &authorName
+		}
+
+		notesToReturn[note.UniqueId] = noteToReturn
+	}
+
+	return notesToReturn, nil
+}
+
+// DeleteNote deletes the note along with its mentions, notifications already sent are kept
+func DeleteNote(ctx context.Context, db *sql.DB, noteId uuid.UUID) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = table.ConversationNoteMention.DELETE().
+		WHERE(table.ConversationNoteMention.ConversationNoteId.EQ(UUID(noteId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ConversationNote.DELETE().
+		WHERE(table.ConversationNote.UniqueId.EQ(UUID(noteId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func fetchMentionedMembers(ctx context.Context, db qrm.Queryable, organizationId uuid.UUID, mentionedMemberIds []string) ([]model.OrganizationMember, error) {
+	if len(mentionedMemberIds) == 0 {
+		return nil, nil
+	}
+
+	memberUuids := make(map[uuid.UUID]bool, len(mentionedMemberIds))
+	memberIdExpressions := make([]Expression, 0, len(mentionedMemberIds))
+	for _, memberId := range mentionedMemberIds {
+		memberUuid, err := uuid.Parse(memberId)
+		if err != nil {
+			return nil, ErrMemberNotFound
+		}
+		if memberUuids[memberUuid] {
+			continue
+		}
+		memberUuids[memberUuid] = true
+		memberIdExpressions = append(memberIdExpressions, UUID(memberUuid))
+	}
+
+	var members []model.OrganizationMember
+
+	err := SELECT(table.OrganizationMember.AllColumns).
+		FROM(table.OrganizationMember).
+		WHERE(
+			table.OrganizationMember.UniqueId.IN(memberIdExpressions...).
+				AND(table.OrganizationMember.OrganizationId.EQ(UUID(organizationId))),
+		).
+		QueryContext(ctx, db, &members)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	if len(members) != len(memberUuids) {
+		return nil, ErrMemberNotFound
+	}
+
+	return members, nil
+}
@@ -0,0 +1,269 @@
+package csat_service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	wapi "github.com/wapikit/wapi.go/pkg/client"
+	"github.com/wapikit/wapi.go/pkg/components"
+	"github.com/wapikit/wapikit/internal/core/message_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+const (
+	DefaultMessage = "How would you rate the support you received?"
+	commentPrompt  = "Thank you for your rating! If there is anything you would like to add, just reply with your comment."
+	buttonText     = "Rate us"
+	// * the ids of the list rows, the rating follows the prefix
+	ratingIdPrefix = "csat_"
+	// comments are only taken from the text sent shortly after the rating, anything later is a new message
+	commentWindow = 30 * time.Minute
+)
+
+var ErrSurveyNotFound = errors.New("no survey is waiting for a rating of this contact")
+
+var ratingTitles = []string{"Very poor", "Poor", "Okay", "Good", "Excellent"}
+
+// ParseRating returns the rating of the list row or button id, false when the id is not a rating of a survey
+func ParseRating(id string) (int, bool) {
+	if !strings.HasPrefix(id, ratingIdPrefix) {
+		return 0, false
+	}
+
+	rating, err := strconv.Atoi(strings.TrimPrefix(id, ratingIdPrefix))
+	if err != nil || rating < 1 || rating > len(ratingTitles) {
+		return 0, false
+	}
+
+	return rating, true
+}
+
+// SendSurvey sends the survey of the organization to the contact of the resolved conversation, and records it against the member
+// the conversation is assigned to. nil is returned when the organization has surveys turned off.
+func SendSurvey(ctx context.Context, db *sql.DB, wapiClient *wapi.Client, conversation model.Conversation) (*model.CsatSurvey, error) {
+	var organization model.Organization
+
+	err := SELECT(table.Organization.AllColumns).
+		FROM(table.Organization).
+		WHERE(table.Organization.UniqueId.EQ(UUID(conversation.OrganizationId))).
+		QueryContext(ctx, db, &organization)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !organization.IsCsatEnabled {
+		return nil, nil
+	}
+
+	var contact model.Contact
+
+	err = SELECT(table.Contact.AllColumns).
+		FROM(table.Contact).
+		WHERE(table.Contact.UniqueId.EQ(UUID(conversation.ContactId))).
+		QueryContext(ctx, db, &contact)
+
+	if err != nil {
+		return nil, err
+	}
+
+	assignedMemberId, err := fetchAssignedMemberId(ctx, db, conversation.UniqueId)
+	if err != nil {
+		return nil, err
+	}
+
+	surveyMessage := DefaultMessage
+	if organization.CsatMessage != nil && strings.TrimSpace(*organization.CsatMessage) != "" {
+		surveyMessage = *organization.CsatMessage
+	}
+
+	listMessage, err := components.NewListMessage(components.ListMessageParams{
+		ButtonText: buttonText,
+		BodyText:   surveyMessage,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	section, err := components.NewListSection(buttonText)
+	if err != nil {
+		return nil, err
+	}
+
+	// * best rating first, so that it is the first row the contact sees
+	for rating := len(ratingTitles); rating >= 1; rating-- {
+		row, err := components.NewListSectionRow(fmt.Sprintf("%s%d", ratingIdPrefix, rating), fmt.Sprintf("%d - %s", rating, ratingTitles[rating-1]), strings.Repeat("★", rating))
+		if err != nil {
+			return nil, err
+		}
+		section.AddRow(row)
+	}
+
+	listMessage.AddSection(section)
+
+	whatsAppMessageId, err := message_service.Send(wapiClient, conversation.PhoneNumberUsed, contact.PhoneNumber, listMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	var survey model.CsatSurvey
+
+	err = table.CsatSurvey.INSERT(table.CsatSurvey.MutableColumns).
+		MODEL(model.CsatSurvey{
+			CreatedAt:            time.Now(),
+			UpdatedAt:            time.Now(),
+			ConversationId:       conversation.UniqueId,
+			OrganizationId:       conversation.OrganizationId,
+			OrganizationMemberId: assignedMemberId,
+			PhoneNumberUsed:      conversation.PhoneNumberUsed,
+			WhatsAppMessageId:    &whatsAppMessageId,
+		}).
+		RETURNING(table.CsatSurvey.AllColumns).
+		QueryContext(ctx, db, &survey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &survey, nil
+}
+
+// RecordRating stores the rating on the survey the contact replied to, or on their latest unanswered survey
+// when the reply does not reference it. ErrSurveyNotFound is returned when no survey is waiting for a rating.
+func RecordRating(ctx context.Context, db *sql.DB, contactPhoneNumber, phoneNumberId, repliedToMessageId string, rating int) (*model.CsatSurvey, error) {
+	condition := table.Contact.PhoneNumber.EQ(String(contactPhoneNumber)).
+		AND(table.CsatSurvey.PhoneNumberUsed.EQ(String(phoneNumberId))).
+		AND(table.CsatSurvey.Rating.IS_NULL())
+
+	if repliedToMessageId != "" {
+		condition = condition.AND(table.CsatSurvey.WhatsAppMessageId.EQ(String(repliedToMessageId)))
+	}
+
+	surveyToRate := SELECT(table.CsatSurvey.UniqueId).
+		FROM(table.CsatSurvey.
+			INNER_JOIN(table.Conversation, table.Conversation.UniqueId.EQ(table.CsatSurvey.ConversationId)).
+			INNER_JOIN(table.Contact, table.Contact.UniqueId.EQ(table.Conversation.ContactId)),
+		).
+		WHERE(condition).
+		ORDER_BY(table.CsatSurvey.CreatedAt.DESC()).
+		LIMIT(1)
+
+	var survey model.CsatSurvey
+
+	err := table.CsatSurvey.UPDATE(table.CsatSurvey.Rating, table.CsatSurvey.RespondedAt, table.CsatSurvey.UpdatedAt).
+		SET(
+			Int32(int32(rating)),
+			TimestampzT(time.Now()),
+			TimestampzT(time.Now()),
+		).
+		WHERE(table.CsatSurvey.UniqueId.IN(surveyToRate)).
+		RETURNING(table.CsatSurvey.AllColumns).
+		QueryContext(ctx, db, &survey)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			if repliedToMessageId != "" {
+				// * the replied message may not be a survey the contact can still rate, fall back to their latest one
+				return RecordRating(ctx, db, contactPhoneNumber, phoneNumberId, "", rating)
+			}
+			return nil, ErrSurveyNotFound
+		}
+		return nil, err
+	}
+
+	return &survey, nil
+}
+
+// SendCommentPrompt thanks the contact for the rating and invites them to leave a comment
+func SendCommentPrompt(wapiClient *wapi.Client, survey model.CsatSurvey, contactPhoneNumber string) error {
+	textMessage, err := components.NewTextMessage(components.TextMessageConfigs{
+		Text: commentPrompt,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = message_service.Send(wapiClient, survey.PhoneNumberUsed, contactPhoneNumber, textMessage)
+	return err
+}
+
+// RecordComment stores the text as the comment of the survey the contact rated just before and returns the survey, nil is
+// returned when the contact has not rated a survey recently, in which case the text is a regular message of the contact.
+func RecordComment(ctx context.Context, db *sql.DB, contactPhoneNumber, phoneNumberId, comment string) (*model.CsatSurvey, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil, nil
+	}
+
+	surveyToComment := SELECT(table.CsatSurvey.UniqueId).
+		FROM(table.CsatSurvey.
+			INNER_JOIN(table.Conversation, table.Conversation.UniqueId.EQ(table.CsatSurvey.ConversationId)).
+			INNER_JOIN(table.Contact, table.Contact.UniqueId.EQ(table.Conversation.ContactId)),
+		).
+		WHERE(
+			table.Contact.PhoneNumber.EQ(String(contactPhoneNumber)).
+				AND(table.CsatSurvey.PhoneNumberUsed.EQ(String(phoneNumberId))).
+				AND(table.CsatSurvey.Comment.IS_NULL()).
+				AND(table.CsatSurvey.RespondedAt.GT_EQ(TimestampzT(time.Now().Add(-commentWindow)))).
+				// * once the conversation is reopened the contact is talking to the team again
+				AND(table.Conversation.Status.EQ(utils.EnumExpression(model.ConversationStatusEnum_Resolved.String()))),
+		).
+		ORDER_BY(table.CsatSurvey.RespondedAt.DESC()).
+		LIMIT(1)
+
+	var survey model.CsatSurvey
+
+	err := table.CsatSurvey.UPDATE(table.CsatSurvey.Comment, table.CsatSurvey.UpdatedAt).
+		SET(
+			String(comment),
+			TimestampzT(time.Now()),
+		).
+		WHERE(table.CsatSurvey.UniqueId.IN(surveyToComment)).
+		RETURNING(table.CsatSurvey.AllColumns).
+		QueryContext(ctx, db, &survey)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &survey, nil
+}
+
+func fetchAssignedMemberId(ctx context.Context, db qrm.Queryable, conversationId uuid.UUID) (*uuid.UUID, error) {
+	var assignment model.ConversationAssignment
+
+	err := SELECT(table.ConversationAssignment.AllColumns).
+		FROM(table.ConversationAssignment).
+		WHERE(
+			table.ConversationAssignment.ConversationId.EQ(UUID(conversationId)).
+				AND(table.ConversationAssignment.Status.EQ(utils.EnumExpression(model.ConversationAssignmentStatus_Assigned.String()))),
+		).
+		ORDER_BY(table.ConversationAssignment.UpdatedAt.DESC()).
+		LIMIT(1).
+		QueryContext(ctx, db, &assignment)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &assignment.AssignedToOrganizationMemberId, nil
+}
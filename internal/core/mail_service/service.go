@@ -0,0 +1,87 @@
+package mail_service
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+)
+
+var ErrInvalidHeader = errors.New("the recipient and subject of an email can not span several lines")
+
+// Mailer sends the emails of the app, like the password reset links
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// SmtpMailer sends the emails through the smtp server of the [smtp] section of the configuration
+type SmtpMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     mail.Address
+}
+
+// NewMailer returns the mailer of the configuration, nil when no smtp server is configured
+func NewMailer(koa *koanf.Koanf) Mailer {
+	host := koa.String("smtp.host")
+	if host == "" {
+		return nil
+	}
+
+	port := koa.String("smtp.port")
+	if port == "" {
+		port = "587"
+	}
+
+	return &SmtpMailer{
+		host:     host,
+		port:     port,
+		username: koa.String("smtp.username"),
+		password: koa.String("smtp.password"),
+		from: mail.Address{
+			Name:    koa.String("smtp.from_name"),
+			Address: koa.String("smtp.from_email"),
+		},
+	}
+}
+
+// Send sends a plain text email, the connection is upgraded to tls when the server supports it
+func (mailer *SmtpMailer) Send(to, subject, body string) error {
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return ErrInvalidHeader
+	}
+
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if mailer.username != "" {
+		auth = smtp.PlainAuth("", mailer.username, mailer.password, mailer.host)
+	}
+
+	message := strings.Join([]string{
+		"From: " + mailer.from.String(),
+		"To: " + recipient.String(),
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		strings.ReplaceAll(body, "\n", "\r\n"),
+	}, "\r\n")
+
+	err = smtp.SendMail(net.JoinHostPort(mailer.host, mailer.port), auth, mailer.from.Address, []string{recipient.Address}, []byte(message))
+	if err != nil {
+		return fmt.Errorf("error sending email through %s: %w", mailer.host, err)
+	}
+
+	return nil
+}
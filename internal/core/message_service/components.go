@@ -0,0 +1,46 @@
+package message_service
+
+import (
+	"encoding/json"
+
+	"github.com/wapikit/wapi.go/pkg/components"
+)
+
+// documentMessage is sent instead of the document message of wapi.go, which does not carry the document yet
+type documentMessage struct {
+	Id       string `json:"id,omitempty"`
+	Link     string `json:"link,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+	Filename string `json:"filename,omitempty"`
+}
+
+type documentMessageApiPayload struct {
+	components.BaseMessagePayload
+	Document documentMessage `json:"document"`
+}
+
+func (m *documentMessage) ToJson(configs components.ApiCompatibleJsonConverterConfigs) ([]byte, error) {
+	payload := documentMessageApiPayload{
+		BaseMessagePayload: components.NewBaseMessagePayload(configs.SendToPhoneNumber, components.MessageTypeDocument),
+		Document:           *m,
+	}
+
+	if configs.ReplyToMessageId != "" {
+		payload.Context = &components.Context{
+			MessageId: configs.ReplyToMessageId,
+		}
+	}
+
+	return json.Marshal(payload)
+}
+
+// replyMessage sends the message as a reply, the messaging client of wapi.go never passes the replied message on its own
+type replyMessage struct {
+	components.BaseMessage
+	replyToWhatsAppMessageId string
+}
+
+func (m *replyMessage) ToJson(configs components.ApiCompatibleJsonConverterConfigs) ([]byte, error) {
+	configs.ReplyToMessageId = m.replyToWhatsAppMessageId
+	return m.BaseMessage.ToJson(configs)
+}
@@ -0,0 +1,200 @@
+package message_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	wapi "github.com/wapikit/wapi.go/pkg/client"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+var ErrMessageNotFound = errors.New("message not found in the conversation")
+
+// isUnread matches the messages of the contact no member has read yet
+func isUnread() BoolExpression {
+	return table.Message.Direction.EQ(utils.EnumExpression(model.MessageDirectionEnum_InBound.String())).
+		AND(table.Message.Status.NOT_EQ(utils.EnumExpression(model.MessageStatusEnum_Read.String())))
+}
+
+// MarkInboundMessagesRead marks the unread messages of the contact in the conversation as read, up to and including the given
+// message when one is passed. The messages marked as read are returned, oldest first.
+func MarkInboundMessagesRead(ctx context.Context, db qrm.Queryable, conversationId uuid.UUID, upToMessageId *uuid.UUID) ([]model.Message, error) {
+	condition := table.Message.ConversationId.EQ(UUID(conversationId)).AND(isUnread())
+
+	if upToMessageId != nil {
+		var upToMessage model.Message
+
+		err := SELECT(table.Message.AllColumns).
+			FROM(table.Message).
+			WHERE(
+				table.Message.UniqueId.EQ(UUID(*upToMessageId)).
+					AND(table.Message.ConversationId.EQ(UUID(conversationId))),
+			).
+			LIMIT(1).
+			QueryContext(ctx, db, &upToMessage)
+
+		if err != nil {
+			if err.Error() == qrm.ErrNoRows.Error() {
+				return nil, ErrMessageNotFound
+			}
+			return nil, err
+		}
+
+		condition = condition.AND(table.Message.CreatedAt.LT_EQ(TimestampzT(upToMessage.CreatedAt)))
+	}
+
+	readMessages := []model.Message{}
+
+	err := table.Message.UPDATE(table.Message.Status, table.Message.UpdatedAt).
+		SET(
+			utils.EnumExpression(model.MessageStatusEnum_Read.String()),
+			NOW(),
+		).
+		WHERE(condition).
+		RETURNING(table.Message.AllColumns).
+		QueryContext(ctx, db, &readMessages)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	// * postgres does not order the returned rows
+	sort.Slice(readMessages, func(i, j int) bool {
+		return readMessages[i].CreatedAt.Before(readMessages[j].CreatedAt)
+	})
+
+	return readMessages, nil
+}
+
+// CountUnreadMessages returns the number of unread messages of the contact in each of the conversations, conversations
+// without unread messages are left out
+func CountUnreadMessages(ctx context.Context, db qrm.Queryable, conversationIds []uuid.UUID) (map[uuid.UUID]int, error) {
+	unreadCounts := map[uuid.UUID]int{}
+
+	if len(conversationIds) == 0 {
+		return unreadCounts, nil
+	}
+
+	conversationIdExpressions := make([]Expression, 0, len(conversationIds))
+	for _, conversationId := range conversationIds {
+		conversationIdExpressions = append(conversationIdExpressions, UUID(conversationId))
+	}
+
+	var rows []struct {
+		ConversationId uuid.UUID
+		UnreadMessages int
+	}
+
+	err := SELECT(
+		table.Message.ConversationId.AS("conversationId"),
+		COUNT(table.Message.UniqueId).AS("unreadMessages"),
+	).
+		FROM(table.Message).
+		WHERE(
+			table.Message.ConversationId.IN(conversationIdExpressions...).
+				AND(isUnread()),
+		).
+		GROUP_BY(table.Message.ConversationId).
+		QueryContext(ctx, db, &rows)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		unreadCounts[row.ConversationId] = row.UnreadMessages
+	}
+
+	return unreadCounts, nil
+}
+
+// FetchLastInboundWhatsAppMessageId returns the whatsapp id of the latest message of the contact in the conversation, an empty
+// string when the contact has not sent a message through whatsapp yet
+func FetchLastInboundWhatsAppMessageId(ctx context.Context, db qrm.Queryable, conversationId uuid.UUID) (string, error) {
+	var message model.Message
+
+	err := SELECT(table.Message.AllColumns).
+		FROM(table.Message).
+		WHERE(
+			table.Message.ConversationId.EQ(UUID(conversationId)).
+				AND(table.Message.Direction.EQ(utils.EnumExpression(model.MessageDirectionEnum_InBound.String()))).
+				AND(table.Message.WhatsAppMessageId.IS_NOT_NULL()),
+		).
+		ORDER_BY(table.Message.CreatedAt.DESC()).
+		LIMIT(1).
+		QueryContext(ctx, db, &message)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return *message.WhatsAppMessageId, nil
+}
+
+// MarkAsRead sends the blue ticks for the message of the contact, whatsapp shows the earlier messages of the chat as read too
+func MarkAsRead(wapiClient *wapi.Client, phoneNumberId, whatsAppMessageId string) error {
+	return sendReadStatus(wapiClient, phoneNumberId, whatsAppMessageId, false)
+}
+
+// SendTypingIndicator shows the contact that a reply is being written, whatsapp hides it once the reply is sent or after
+// 25 seconds. The indicator belongs to the message of the contact being answered, which is marked as read along with it.
+func SendTypingIndicator(wapiClient *wapi.Client, phoneNumberId, whatsAppMessageId string) error {
+	return sendReadStatus(wapiClient, phoneNumberId, whatsAppMessageId, true)
+}
+
+// sendReadStatus calls the messages endpoint of the cloud api directly, wapi.go has no support for read receipts yet
+func sendReadStatus(wapiClient *wapi.Client, phoneNumberId, whatsAppMessageId string, showTyping bool) error {
+	payload := map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"status":            "read",
+		"message_id":        whatsAppMessageId,
+	}
+
+	if showTyping {
+		payload["typing_indicator"] = map[string]string{
+			"type": "text",
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	messagingClient := wapiClient.NewMessagingClient(phoneNumberId)
+	apiRequest := messagingClient.Requester.NewApiRequest(strings.Join([]string{phoneNumberId, "messages"}, "/"), http.MethodPost)
+	apiRequest.SetBody(string(body))
+
+	response, err := apiRequest.Execute()
+	if err != nil {
+		return err
+	}
+
+	var statusResponse struct {
+		Success bool `json:"success"`
+	}
+
+	if err := json.Unmarshal([]byte(response), &statusResponse); err != nil {
+		return err
+	}
+
+	if !statusResponse.Success {
+		return fmt.Errorf("whatsapp did not accept the read status: %s", response)
+	}
+
+	return nil
+}
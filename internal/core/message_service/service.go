@@ -0,0 +1,639 @@
+package message_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	wapi "github.com/wapikit/wapi.go/pkg/client"
+	"github.com/wapikit/wapi.go/pkg/components"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// * limits of the whatsapp cloud api, checked here so that the member gets a clear error instead of the one of the api
+const (
+	maxTextLength              = 4096
+	maxCaptionLength           = 1024
+	maxInteractiveBodyLength   = 1024
+	maxListButtonTextLength    = 20
+	maxListSections            = 10
+	maxListRows                = 10
+	maxListSectionTitleLength  = 24
+	maxListRowTitleLength      = 24
+	maxListRowDescriptionLen   = 72
+	maxListRowIdLength         = 200
+	maxReplyButtons            = 3
+	maxReplyButtonTitleLength  = 20
+	maxReplyButtonIdLength     = 256
+	defaultContactPhoneType    = components.CellPhone
+	defaultContactUrlType      = components.UrlType(components.WorkUrl)
+	defaultContactEmailType    = components.WorkEmail
+	maxContactCardsPerMessage  = 20
+	maxTemplateParameterLength = 1024
+)
+
+var ErrInvalidMessage = errors.New("invalid message")
+
+// OutboundMessage is a message validated and ready to be sent to a contact
+type OutboundMessage struct {
+	Message     components.BaseMessage
+	MessageType model.MessageTypeEnum
+	// stored as the data of the message, text is kept under "text" and captions under "caption" so that search finds them
+	MessageData map[string]interface{}
+	// the message of the conversation this message replies to
+	RepliedTo *uuid.UUID
+}
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidMessage, fmt.Sprintf(format, args...))
+}
+
+// BuildOutboundMessage validates the message the member wants to send in the conversation and builds the wapi.go message for it.
+// Errors caused by the payload wrap ErrInvalidMessage.
+func BuildOutboundMessage(ctx context.Context, db qrm.Queryable, conversationId uuid.UUID, payload api_types.NewMessageSchema) (*OutboundMessage, error) {
+	var outboundMessage *OutboundMessage
+	var err error
+
+	switch payload.MessageType {
+	case api_types.Text:
+		outboundMessage, err = buildTextMessage(payload)
+	case api_types.Image, api_types.Video, api_types.Audio, api_types.Document, api_types.Sticker:
+		outboundMessage, err = buildMediaMessage(payload.MessageType, payload.Media)
+	case api_types.Location:
+		outboundMessage, err = buildLocationMessage(payload.Location)
+	case api_types.Contacts:
+		outboundMessage, err = buildContactsMessage(payload.Contacts)
+	case api_types.Reaction:
+		outboundMessage, err = buildReactionMessage(ctx, db, conversationId, payload.Reaction)
+	case api_types.Interactive:
+		outboundMessage, err = buildInteractiveMessage(payload.Interactive)
+	case api_types.Template:
+		outboundMessage, err = buildTemplateMessage(payload.Template)
+	default:
+		return nil, invalid("%s messages can not be sent", payload.MessageType)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if payload.ReplyToMessageId != nil && *payload.ReplyToMessageId != "" {
+		if payload.MessageType == api_types.Reaction {
+			return nil, invalid("reactions can not reply to a message")
+		}
+
+		repliedTo, whatsAppMessageId, err := fetchWhatsAppMessageId(ctx, db, conversationId, *payload.ReplyToMessageId)
+		if err != nil {
+			return nil, err
+		}
+
+		outboundMessage.Message = &replyMessage{
+			BaseMessage:              outboundMessage.Message,
+			replyToWhatsAppMessageId: whatsAppMessageId,
+		}
+		outboundMessage.RepliedTo = &repliedTo
+	}
+
+	return outboundMessage, nil
+}
+
+func buildTextMessage(payload api_types.NewMessageSchema) (*OutboundMessage, error) {
+	text := payload.Text
+
+	// * the inbox sent text messages as { text } before messages were typed
+	if text == nil && payload.MessageData != nil {
+		if body, ok := (*payload.MessageData)["text"].(string); ok {
+			text = &api_types.OutboundTextMessageSchema{Body: body}
+		}
+	}
+
+	if text == nil || strings.TrimSpace(text.Body) == "" {
+		return nil, invalid("text is required for text messages")
+	}
+
+	if utf8.RuneCountInString(text.Body) > maxTextLength {
+		return nil, invalid("text can not be longer than %d characters", maxTextLength)
+	}
+
+	previewUrl := text.PreviewUrl != nil && *text.PreviewUrl
+
+	textMessage, err := components.NewTextMessage(components.TextMessageConfigs{
+		Text:         text.Body,
+		AllowPreview: previewUrl,
+	})
+
+	if err != nil {
+		return nil, invalid("%s", err.Error())
+	}
+
+	return &OutboundMessage{
+		Message:     textMessage,
+		MessageType: model.MessageTypeEnum_Text,
+		MessageData: map[string]interface{}{
+			"text":       text.Body,
+			"previewUrl": previewUrl,
+		},
+	}, nil
+}
+
+func buildMediaMessage(messageType api_types.MessageTypeEnum, media *api_types.OutboundMediaMessageSchema) (*OutboundMessage, error) {
+	if media == nil {
+		return nil, invalid("media is required for %s messages", messageType)
+	}
+
+	id := valueOf(media.Id)
+	link := valueOf(media.Link)
+	caption := valueOf(media.Caption)
+	filename := valueOf(media.Filename)
+
+	if (id == "") == (link == "") {
+		return nil, invalid("exactly one of the media id and link is required")
+	}
+
+	if link != "" {
+		parsedLink, err := url.Parse(link)
+		if err != nil || (parsedLink.Scheme != "https" && parsedLink.Scheme != "http") || parsedLink.Host == "" {
+			return nil, invalid("media link must be a http or https url")
+		}
+	}
+
+	if caption != "" && messageType != api_types.Image && messageType != api_types.Video && messageType != api_types.Document {
+		return nil, invalid("%s messages can not have a caption", messageType)
+	}
+
+	if utf8.RuneCountInString(caption) > maxCaptionLength {
+		return nil, invalid("caption can not be longer than %d characters", maxCaptionLength)
+	}
+
+	if filename != "" && messageType != api_types.Document {
+		return nil, invalid("only documents can have a filename")
+	}
+
+	var message components.BaseMessage
+	var err error
+
+	switch messageType {
+	case api_types.Image:
+		message, err = components.NewImageMessage(components.ImageMessageConfigs{Id: id, Link: link, Caption: caption})
+	case api_types.Video:
+		message, err = components.NewVideoMessage(components.VideoMessageConfigs{Id: id, Link: link, Caption: caption})
+	case api_types.Audio:
+		message, err = components.NewAudioMessage(components.AudioMessageConfigs{Id: id, Link: link})
+	case api_types.Sticker:
+		message, err = components.NewStickerMessage(&components.StickerMessageConfigs{Id: id, Link: link})
+	case api_types.Document:
+		message = &documentMessage{Id: id, Link: link, Caption: caption, Filename: filename}
+	}
+
+	if err != nil {
+		return nil, invalid("%s", err.Error())
+	}
+
+	return &OutboundMessage{
+		Message:     message,
+		MessageType: model.MessageTypeEnum(messageType),
+		MessageData: toMessageData(media),
+	}, nil
+}
+
+func buildLocationMessage(location *api_types.OutboundLocationMessageSchema) (*OutboundMessage, error) {
+	if location == nil {
+		return nil, invalid("location is required for location messages")
+	}
+
+	if location.Latitude < -90 || location.Latitude > 90 || location.Longitude < -180 || location.Longitude > 180 {
+		return nil, invalid("latitude must be between -90 and 90, and longitude between -180 and 180")
+	}
+
+	locationMessage, err := components.NewLocationMessage(location.Latitude, location.Longitude)
+	if err != nil {
+		return nil, invalid("%s", err.Error())
+	}
+
+	if location.Name != nil {
+		locationMessage.SetName(*location.Name)
+	}
+
+	if location.Address != nil {
+		locationMessage.SetAddress(*location.Address)
+	}
+
+	return &OutboundMessage{
+		Message:     locationMessage,
+		MessageType: model.MessageTypeEnum_Location,
+		MessageData: toMessageData(location),
+	}, nil
+}
+
+func buildContactsMessage(contactCards *[]api_types.OutboundContactCardSchema) (*OutboundMessage, error) {
+	if contactCards == nil || len(*contactCards) == 0 {
+		return nil, invalid("at least one contact is required for contacts messages")
+	}
+
+	if len(*contactCards) > maxContactCardsPerMessage {
+		return nil, invalid("at most %d contacts can be sent in a message", maxContactCardsPerMessage)
+	}
+
+	contacts := make([]components.Contact, 0, len(*contactCards))
+
+	for _, contactCard := range *contactCards {
+		if strings.TrimSpace(contactCard.FormattedName) == "" {
+			return nil, invalid("every contact needs a formatted name")
+		}
+
+		contact := components.NewContact(components.ContactName{
+			FormattedName: contactCard.FormattedName,
+			FirstName:     valueOf(contactCard.FirstName),
+			LastName:      valueOf(contactCard.LastName),
+		})
+
+		if contactCard.Company != nil {
+			contact.SetOrg(components.ContactOrg{Company: *contactCard.Company})
+		}
+
+		for _, phone := range valuesOf(contactCard.Phones) {
+			contact.AddPhone(components.ContactPhone{Phone: phone, Type: defaultContactPhoneType})
+		}
+
+		for _, email := range valuesOf(contactCard.Emails) {
+			contact.AddEmail(components.ContactEmail{Email: email, Type: defaultContactEmailType})
+		}
+
+		for _, contactUrl := range valuesOf(contactCard.Urls) {
+			contact.AddUrl(components.ContactUrl{Url: contactUrl, Type: defaultContactUrlType})
+		}
+
+		if len(contact.Phones) == 0 && len(contact.Emails) == 0 && len(contact.Urls) == 0 {
+			return nil, invalid("contact %s needs a phone number, an email or a url", contactCard.FormattedName)
+		}
+
+		contacts = append(contacts, *contact)
+	}
+
+	contactMessage, err := components.NewContactMessage(contacts)
+	if err != nil {
+		return nil, invalid("%s", err.Error())
+	}
+
+	return &OutboundMessage{
+		Message:     contactMessage,
+		MessageType: model.MessageTypeEnum_Contacts,
+		MessageData: map[string]interface{}{
+			"contacts": *contactCards,
+		},
+	}, nil
+}
+
+func buildReactionMessage(ctx context.Context, db qrm.Queryable, conversationId uuid.UUID, reaction *api_types.OutboundReactionMessageSchema) (*OutboundMessage, error) {
+	if reaction == nil || reaction.MessageId == "" || reaction.Emoji == "" {
+		return nil, invalid("the message to react to and the emoji are required for reactions")
+	}
+
+	_, whatsAppMessageId, err := fetchWhatsAppMessageId(ctx, db, conversationId, reaction.MessageId)
+	if err != nil {
+		return nil, err
+	}
+
+	reactionMessage, err := components.NewReactionMessage(components.ReactionMessageParams{
+		MessageId: whatsAppMessageId,
+		Emoji:     reaction.Emoji,
+	})
+
+	if err != nil {
+		return nil, invalid("%s", err.Error())
+	}
+
+	return &OutboundMessage{
+		Message:     reactionMessage,
+		MessageType: model.MessageTypeEnum_Reaction,
+		MessageData: toMessageData(reaction),
+	}, nil
+}
+
+func buildInteractiveMessage(interactive *api_types.OutboundInteractiveMessageSchema) (*OutboundMessage, error) {
+	if interactive == nil || strings.TrimSpace(interactive.Body) == "" {
+		return nil, invalid("a body is required for interactive messages")
+	}
+
+	if utf8.RuneCountInString(interactive.Body) > maxInteractiveBodyLength {
+		return nil, invalid("body can not be longer than %d characters", maxInteractiveBodyLength)
+	}
+
+	var message components.BaseMessage
+
+	switch interactive.Type {
+	case api_types.List:
+		buttonText := valueOf(interactive.ButtonText)
+		if buttonText == "" || utf8.RuneCountInString(buttonText) > maxListButtonTextLength {
+			return nil, invalid("lists need a button text of at most %d characters", maxListButtonTextLength)
+		}
+
+		sections := valuesOf(interactive.Sections)
+		if len(sections) == 0 || len(sections) > maxListSections {
+			return nil, invalid("lists need between 1 and %d sections", maxListSections)
+		}
+
+		listMessage, err := components.NewListMessage(components.ListMessageParams{
+			ButtonText: buttonText,
+			BodyText:   interactive.Body,
+		})
+
+		if err != nil {
+			return nil, invalid("%s", err.Error())
+		}
+
+		numberOfRows := 0
+		rowIds := map[string]bool{}
+
+		for _, section := range sections {
+			if utf8.RuneCountInString(section.Title) > maxListSectionTitleLength {
+				return nil, invalid("section titles can not be longer than %d characters", maxListSectionTitleLength)
+			}
+
+			if len(section.Rows) == 0 {
+				return nil, invalid("every section needs at least one row")
+			}
+
+			listSection, err := components.NewListSection(section.Title)
+			if err != nil {
+				return nil, invalid("%s", err.Error())
+			}
+
+			for _, row := range section.Rows {
+				numberOfRows++
+
+				switch {
+				case row.Id == "" || utf8.RuneCountInString(row.Id) > maxListRowIdLength:
+					return nil, invalid("row ids are required and can not be longer than %d characters", maxListRowIdLength)
+				case rowIds[row.Id]:
+					return nil, invalid("row id %s is used more than once", row.Id)
+				case row.Title == "" || utf8.RuneCountInString(row.Title) > maxListRowTitleLength:
+					return nil, invalid("row titles are required and can not be longer than %d characters", maxListRowTitleLength)
+				case utf8.RuneCountInString(valueOf(row.Description)) > maxListRowDescriptionLen:
+					return nil, invalid("row descriptions can not be longer than %d characters", maxListRowDescriptionLen)
+				}
+
+				rowIds[row.Id] = true
+
+				listRow, err := components.NewListSectionRow(row.Id, row.Title, valueOf(row.Description))
+				if err != nil {
+					return nil, invalid("%s", err.Error())
+				}
+
+				listSection.AddRow(listRow)
+			}
+
+			listMessage.AddSection(listSection)
+		}
+
+		if numberOfRows > maxListRows {
+			return nil, invalid("lists can have at most %d rows", maxListRows)
+		}
+
+		message = listMessage
+
+	case api_types.Buttons:
+		buttons := valuesOf(interactive.Buttons)
+		if len(buttons) == 0 || len(buttons) > maxReplyButtons {
+			return nil, invalid("button messages need between 1 and %d buttons", maxReplyButtons)
+		}
+
+		buttonMessage, err := components.NewQuickReplyButtonMessage(interactive.Body)
+		if err != nil {
+			return nil, invalid("%s", err.Error())
+		}
+
+		buttonIds := map[string]bool{}
+
+		for _, button := range buttons {
+			switch {
+			case button.Id == "" || utf8.RuneCountInString(button.Id) > maxReplyButtonIdLength:
+				return nil, invalid("button ids are required and can not be longer than %d characters", maxReplyButtonIdLength)
+			case buttonIds[button.Id]:
+				return nil, invalid("button id %s is used more than once", button.Id)
+			case button.Title == "" || utf8.RuneCountInString(button.Title) > maxReplyButtonTitleLength:
+				return nil, invalid("button titles are required and can not be longer than %d characters", maxReplyButtonTitleLength)
+			}
+
+			buttonIds[button.Id] = true
+
+			if err := buttonMessage.AddButton(button.Id, button.Title); err != nil {
+				return nil, invalid("%s", err.Error())
+			}
+		}
+
+		message = buttonMessage
+
+	default:
+		return nil, invalid("interactive messages must be a list or buttons")
+	}
+
+	return &OutboundMessage{
+		Message:     message,
+		MessageType: model.MessageTypeEnum_Interactive,
+		MessageData: toMessageData(interactive),
+	}, nil
+}
+
+func buildTemplateMessage(template *api_types.OutboundTemplateMessageSchema) (*OutboundMessage, error) {
+	if template == nil || template.Name == "" || template.Language == "" {
+		return nil, invalid("the name and language of the template are required for template messages")
+	}
+
+	templateMessage, err := components.NewTemplateMessage(&components.TemplateMessageConfigs{
+		Name:     template.Name,
+		Language: template.Language,
+	})
+
+	if err != nil {
+		return nil, invalid("%s", err.Error())
+	}
+
+	headerParameters, err := textParameters(valuesOf(template.HeaderParameters))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(headerParameters) > 0 {
+		templateMessage.AddHeader(components.TemplateMessageComponentHeaderType{
+			Type:       components.TemplateMessageComponentTypeHeader,
+			Parameters: headerParameters,
+		})
+	}
+
+	bodyParameters, err := textParameters(valuesOf(template.BodyParameters))
+	if err != nil {
+		return nil, err
+	}
+
+	templateMessage.AddBody(components.TemplateMessageComponentBodyType{
+		Type:       components.TemplateMessageComponentTypeBody,
+		Parameters: bodyParameters,
+	})
+
+	return &OutboundMessage{
+		Message:     templateMessage,
+		MessageType: model.MessageTypeEnum_Template,
+		MessageData: toMessageData(template),
+	}, nil
+}
+
+func textParameters(values []string) ([]components.TemplateMessageParameter, error) {
+	parameters := []components.TemplateMessageParameter{}
+
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" || utf8.RuneCountInString(value) > maxTemplateParameterLength {
+			return nil, invalid("template parameters are required and can not be longer than %d characters", maxTemplateParameterLength)
+		}
+
+		text := value
+		parameters = append(parameters, components.TemplateMessageBodyAndHeaderParameter{
+			Type: components.TemplateMessageParameterTypeText,
+			Text: &text,
+		})
+	}
+
+	return parameters, nil
+}
+
+// fetchWhatsAppMessageId returns the whatsapp id of a message of the conversation, which replies and reactions refer to
+func fetchWhatsAppMessageId(ctx context.Context, db qrm.Queryable, conversationId uuid.UUID, messageId string) (uuid.UUID, string, error) {
+	messageUuid, err := uuid.Parse(messageId)
+	if err != nil {
+		return uuid.Nil, "", invalid("invalid message id %s", messageId)
+	}
+
+	var message model.Message
+
+	err = SELECT(table.Message.AllColumns).
+		FROM(table.Message).
+		WHERE(
+			table.Message.UniqueId.EQ(UUID(messageUuid)).
+				AND(table.Message.ConversationId.EQ(UUID(conversationId))),
+		).
+		QueryContext(ctx, db, &message)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return uuid.Nil, "", invalid("message %s not found in the conversation", messageId)
+		}
+		return uuid.Nil, "", err
+	}
+
+	if message.WhatsAppMessageId == nil || *message.WhatsAppMessageId == "" {
+		return uuid.Nil, "", invalid("message %s has not been sent through whatsapp", messageId)
+	}
+
+	return message.UniqueId, *message.WhatsAppMessageId, nil
+}
+
+// Send sends the message from the business phone number, and returns the whatsapp id of the sent message
+func Send(wapiClient *wapi.Client, phoneNumberId, contactPhoneNumber string, message components.BaseMessage) (string, error) {
+	messagingClient := wapiClient.NewMessagingClient(phoneNumberId)
+
+	response, err := messagingClient.Message.Send(message, contactPhoneNumber)
+	if err != nil {
+		return "", err
+	}
+
+	var sendResponse struct {
+		Messages []struct {
+			Id string `json:"id"`
+		} `json:"messages"`
+	}
+
+	if err := json.Unmarshal([]byte(response), &sendResponse); err != nil {
+		return "", err
+	}
+
+	if len(sendResponse.Messages) == 0 {
+		return "", fmt.Errorf("whatsapp did not return the id of the sent message: %s", response)
+	}
+
+	return sendResponse.Messages[0].Id, nil
+}
+
+// previousStatuses lists the statuses a message can be in before each receipt, receipts may arrive out of order
+// and a late receipt must not move a message back
+var previousStatuses = map[model.MessageStatusEnum][]model.MessageStatusEnum{
+	model.MessageStatusEnum_Delivered:   {model.MessageStatusEnum_Sent},
+	model.MessageStatusEnum_Read:        {model.MessageStatusEnum_Sent, model.MessageStatusEnum_Delivered},
+	model.MessageStatusEnum_Failed:      {model.MessageStatusEnum_Sent, model.MessageStatusEnum_Delivered},
+	model.MessageStatusEnum_UnDelivered: {model.MessageStatusEnum_Sent},
+}
+
+// UpdateStatus applies a receipt of whatsapp to the message with the whatsapp id, nil is returned when the message
+// is not known or the receipt is older than the status of the message
+func UpdateStatus(ctx context.Context, db qrm.Queryable, whatsAppMessageId string, status model.MessageStatusEnum) (*model.Message, error) {
+	statusesBefore, ok := previousStatuses[status]
+	if !ok || whatsAppMessageId == "" {
+		return nil, nil
+	}
+
+	statusExpressions := make([]Expression, 0, len(statusesBefore))
+	for _, statusBefore := range statusesBefore {
+		statusExpressions = append(statusExpressions, utils.EnumExpression(statusBefore.String()))
+	}
+
+	var message model.Message
+
+	err := table.Message.UPDATE(table.Message.Status, table.Message.UpdatedAt).
+		SET(
+			utils.EnumExpression(status.String()),
+			NOW(),
+		).
+		WHERE(
+			table.Message.WhatsAppMessageId.EQ(String(whatsAppMessageId)).
+				AND(table.Message.Status.IN(statusExpressions...)),
+		).
+		RETURNING(table.Message.AllColumns).
+		QueryContext(ctx, db, &message)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+// toMessageData converts the typed message to the json object stored as the data of the message
+func toMessageData(typedMessage interface{}) map[string]interface{} {
+	messageData := map[string]interface{}{}
+
+	jsonMessage, err := json.Marshal(typedMessage)
+	if err != nil {
+		return messageData
+	}
+
+	_ = json.Unmarshal(jsonMessage, &messageData)
+	return messageData
+}
+
+func valueOf[T any](pointer *T) T {
+	var value T
+	if pointer != nil {
+		value = *pointer
+	}
+	return value
+}
+
+func valuesOf[T any](pointer *[]T) []T {
+	if pointer == nil {
+		return nil
+	}
+	return *pointer
+}
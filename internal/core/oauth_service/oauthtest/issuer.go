@@ -0,0 +1,244 @@
+// Package oauthtest provides a local OIDC provider to test the oauth login against, the way net/http/httptest provides
+// a local http server.
+package oauthtest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+const keyId = "oauthtest"
+
+// Identity is the user the issuer signs in, as it ends up in the id token
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// authorization is a code handed out by Authorize, waiting to be exchanged at the token endpoint
+type authorization struct {
+	identity      Identity
+	nonce         string
+	codeChallenge string
+	redirectUrl   string
+}
+
+// Issuer serves the discovery document, the signing keys and the token endpoint of an OIDC provider. The authorization
+// endpoint is not served, Authorize stands for the user signing in at the provider.
+type Issuer struct {
+	Server       *httptest.Server
+	ClientId     string
+	ClientSecret string
+	key          *rsa.PrivateKey
+	codes        map[string]authorization
+	mutex        sync.Mutex
+}
+
+func NewIssuer(clientId, clientSecret string) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := &Issuer{
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		key:          key,
+		codes:        make(map[string]authorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.handleDiscovery)
+	mux.HandleFunc("/keys", issuer.handleKeys)
+	mux.HandleFunc("/token", issuer.handleToken)
+	issuer.Server = httptest.NewServer(mux)
+
+	return issuer, nil
+}
+
+func (issuer *Issuer) URL() string {
+	return issuer.Server.URL
+}
+
+func (issuer *Issuer) Close() {
+	issuer.Server.Close()
+}
+
+// Authorize signs the identity in for the authorization url the provider was sent to, and returns the code and state the
+// provider redirects back with. The code is bound to the nonce and the PKCE challenge of the url.
+func (issuer *Issuer) Authorize(authorizationUrl string, identity Identity) (string, string, error) {
+	parsedUrl, err := url.Parse(authorizationUrl)
+	if err != nil {
+		return "", "", err
+	}
+
+	query := parsedUrl.Query()
+
+	if query.Get("client_id") != issuer.ClientId {
+		return "", "", errors.New("unknown client id")
+	}
+
+	if query.Get("response_type") != "code" {
+		return "", "", errors.New("only the authorization code flow is supported")
+	}
+
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		return "", "", errors.New("a S256 PKCE challenge is required")
+	}
+
+	code, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	issuer.mutex.Lock()
+	issuer.codes[code] = authorization{
+		identity:      identity,
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		redirectUrl:   query.Get("redirect_uri"),
+	}
+	issuer.mutex.Unlock()
+
+	return code, query.Get("state"), nil
+}
+
+func (issuer *Issuer) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                issuer.URL(),
+		"authorization_endpoint":                issuer.URL() + "/authorize",
+		"token_endpoint":                        issuer.URL() + "/token",
+		"jwks_uri":                              issuer.URL() + "/keys",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (issuer *Issuer) handleKeys(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, jose.JSONWebKeySet{
+		Keys: []jose.JSONWebKey{{
+			Key:       &issuer.key.PublicKey,
+			KeyID:     keyId,
+			Algorithm: string(jose.RS256),
+			Use:       "sig",
+		}},
+	})
+}
+
+func (issuer *Issuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeTokenError(w, "invalid_request")
+		return
+	}
+
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientId, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	if clientId != issuer.ClientId || clientSecret != issuer.ClientSecret {
+		writeJson(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		writeTokenError(w, "unsupported_grant_type")
+		return
+	}
+
+	// * codes are single use, a code is gone once it has been tried
+	code := r.PostForm.Get("code")
+	issuer.mutex.Lock()
+	codeAuthorization, ok := issuer.codes[code]
+	delete(issuer.codes, code)
+	issuer.mutex.Unlock()
+
+	if !ok || r.PostForm.Get("redirect_uri") != codeAuthorization.redirectUrl {
+		writeTokenError(w, "invalid_grant")
+		return
+	}
+
+	verifierHash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(verifierHash[:]) != codeAuthorization.codeChallenge {
+		writeTokenError(w, "invalid_grant")
+		return
+	}
+
+	idToken, err := issuer.signIdToken(codeAuthorization)
+	if err != nil {
+		writeJson(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"access_token": "oauthtest-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (issuer *Issuer) signIdToken(codeAuthorization authorization) (string, error) {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: issuer.key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", keyId),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":            issuer.URL(),
+		"sub":            codeAuthorization.identity.Subject,
+		"aud":            issuer.ClientId,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"nonce":          codeAuthorization.nonce,
+		"email":          codeAuthorization.identity.Email,
+		"email_verified": codeAuthorization.identity.EmailVerified,
+		"name":           codeAuthorization.identity.Name,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signature, err := signer.Sign(claims)
+	if err != nil {
+		return "", err
+	}
+
+	return signature.CompactSerialize()
+}
+
+func randomString() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+func writeTokenError(w http.ResponseWriter, code string) {
+	writeJson(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJson(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,170 @@
+package oauth_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/knadh/koanf/v2"
+	"golang.org/x/oauth2"
+)
+
+// ProviderConfig is the configuration of an OIDC provider, loaded from the [oauth.<name>] section of config.toml
+type ProviderConfig struct {
+	Name         string   `koanf:"-"`
+	DisplayName  string   `koanf:"display_name"`
+	Enabled      bool     `koanf:"enabled"`
+	IssuerUrl    string   `koanf:"issuer_url"`
+	ClientId     string   `koanf:"client_id"`
+	ClientSecret string   `koanf:"client_secret"`
+	RedirectUrl  string   `koanf:"redirect_url"`
+	Scopes       []string `koanf:"scopes"`
+	// when enabled, users without an account or a pending invite get an account on their first login
+	AllowSignup bool `koanf:"allow_signup"`
+	// when enabled, an identity is linked to the existing account with the same email and redeems the pending invites of
+	// the email. Only enable it for providers whose email_verified claim can not be set by whoever controls the realm.
+	TrustEmailForLinking bool `koanf:"trust_email_for_linking"`
+}
+
+// UserInfo is the identity of the user as verified from the id token of the provider
+type UserInfo struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+}
+
+var ErrProviderNotFound = errors.New("oauth provider not found")
+
+type OidcProvider struct {
+	Config       ProviderConfig
+	verifier     *oidc.IDTokenVerifier
+	oauth2Config oauth2.Config
+}
+
+// AuthCodeURL returns the url the user must be sent to, the code verifier is used for the PKCE challenge
+func (provider *OidcProvider) AuthCodeURL(state, nonce, codeVerifier string) string {
+	return provider.oauth2Config.AuthCodeURL(
+		state,
+		oidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(codeVerifier),
+	)
+}
+
+// Exchange trades the authorization code for tokens and returns the verified identity of the user
+func (provider *OidcProvider) Exchange(ctx context.Context, code, nonce, codeVerifier string) (*UserInfo, error) {
+	token, err := provider.oauth2Config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, err
+	}
+
+	rawIdToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIdToken == "" {
+		return nil, errors.New("id token missing from the token response")
+	}
+
+	idToken, err := provider.verifier.Verify(ctx, rawIdToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if idToken.Nonce != nonce {
+		return nil, errors.New("id token nonce mismatch")
+	}
+
+	var userInfo UserInfo
+	if err := idToken.Claims(&userInfo); err != nil {
+		return nil, err
+	}
+
+	return &userInfo, nil
+}
+
+// ProviderRegistry holds the configured providers, the discovery document of a provider is only fetched on first use
+type ProviderRegistry struct {
+	configs   map[string]ProviderConfig
+	providers map[string]*OidcProvider
+	mutex     sync.Mutex
+}
+
+func NewProviderRegistry(koa *koanf.Koanf) *ProviderRegistry {
+	registry := &ProviderRegistry{
+		configs:   make(map[string]ProviderConfig),
+		providers: make(map[string]*OidcProvider),
+	}
+
+	for _, name := range koa.MapKeys("oauth") {
+		var config ProviderConfig
+		if err := koa.Unmarshal("oauth."+name, &config); err != nil {
+			continue
+		}
+
+		if !config.Enabled || config.IssuerUrl == "" || config.ClientId == "" {
+			continue
+		}
+
+		config.Name = name
+
+		if config.DisplayName == "" {
+			config.DisplayName = name
+		}
+
+		if len(config.Scopes) == 0 {
+			config.Scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+		}
+
+		registry.configs[name] = config
+	}
+
+	return registry
+}
+
+// EnabledProviders returns the configuration of every enabled provider, sorted by name
+func (registry *ProviderRegistry) EnabledProviders() []ProviderConfig {
+	providers := make([]ProviderConfig, 0, len(registry.configs))
+	for _, config := range registry.configs {
+		providers = append(providers, config)
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name < providers[j].Name
+	})
+	return providers
+}
+
+func (registry *ProviderRegistry) Get(ctx context.Context, name string) (*OidcProvider, error) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if provider, ok := registry.providers[name]; ok {
+		return provider, nil
+	}
+
+	config, ok := registry.configs[name]
+	if !ok {
+		return nil, ErrProviderNotFound
+	}
+
+	oidcProvider, err := oidc.NewProvider(ctx, config.IssuerUrl)
+	if err != nil {
+		return nil, fmt.Errorf("error discovering oauth provider %s: %w", name, err)
+	}
+
+	provider := &OidcProvider{
+		Config:   config,
+		verifier: oidcProvider.Verifier(&oidc.Config{ClientID: config.ClientId}),
+		oauth2Config: oauth2.Config{
+			ClientID:     config.ClientId,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectUrl,
+			Endpoint:     oidcProvider.Endpoint(),
+			Scopes:       config.Scopes,
+		},
+	}
+
+	registry.providers[name] = provider
+	return provider, nil
+}
@@ -0,0 +1,130 @@
+package oauth_service
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/knadh/koanf/v2"
+	"github.com/wapikit/wapikit/internal/core/oauth_service/oauthtest"
+	"golang.org/x/oauth2"
+)
+
+const redirectUrl = "http://localhost:3000/oauth/callback"
+
+func newTestProvider(t *testing.T) (*oauthtest.Issuer, *OidcProvider) {
+	t.Helper()
+
+	issuer, err := oauthtest.NewIssuer("wapikit", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(issuer.Close)
+
+	koa := koanf.New(".")
+	koa.Set("oauth.mock.enabled", true)
+	koa.Set("oauth.mock.issuer_url", issuer.URL())
+	koa.Set("oauth.mock.client_id", issuer.ClientId)
+	koa.Set("oauth.mock.client_secret", issuer.ClientSecret)
+	koa.Set("oauth.mock.redirect_url", redirectUrl)
+
+	provider, err := NewProviderRegistry(koa).Get(context.Background(), "mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return issuer, provider
+}
+
+func TestExchangeReturnsTheIdentityOfTheUser(t *testing.T) {
+	issuer, provider := newTestProvider(t)
+	codeVerifier := oauth2.GenerateVerifier()
+
+	code, state, err := issuer.Authorize(provider.AuthCodeURL("the-state", "the-nonce", codeVerifier), oauthtest.Identity{
+		Subject:       "subject-1",
+		Email:         "user@example.com",
+		EmailVerified: true,
+		Name:          "User",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state != "the-state" {
+		t.Fatalf("expected the state to be sent to the provider, got %q", state)
+	}
+
+	userInfo, err := provider.Exchange(context.Background(), code, "the-nonce", codeVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if userInfo.Subject != "subject-1" || userInfo.Email != "user@example.com" || !userInfo.EmailVerified || userInfo.Name != "User" {
+		t.Fatalf("unexpected identity %+v", userInfo)
+	}
+}
+
+func TestExchangeRequiresTheCodeVerifier(t *testing.T) {
+	issuer, provider := newTestProvider(t)
+
+	authorizationUrl, err := url.Parse(provider.AuthCodeURL("the-state", "the-nonce", oauth2.GenerateVerifier()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if authorizationUrl.Query().Get("code_challenge_method") != "S256" {
+		t.Fatalf("expected a S256 PKCE challenge, got %q", authorizationUrl.RawQuery)
+	}
+
+	code, _, err := issuer.Authorize(authorizationUrl.String(), oauthtest.Identity{Subject: "subject-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Exchange(context.Background(), code, "the-nonce", oauth2.GenerateVerifier()); err == nil {
+		t.Fatal("expected the exchange to fail with another code verifier")
+	}
+}
+
+func TestExchangeRejectsAnotherNonce(t *testing.T) {
+	issuer, provider := newTestProvider(t)
+	codeVerifier := oauth2.GenerateVerifier()
+
+	code, _, err := issuer.Authorize(provider.AuthCodeURL("the-state", "the-nonce", codeVerifier), oauthtest.Identity{Subject: "subject-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Exchange(context.Background(), code, "another-nonce", codeVerifier); err == nil {
+		t.Fatal("expected the exchange to fail when the nonce of the id token is not the one of the login")
+	}
+}
+
+func TestExchangeRejectsAReusedCode(t *testing.T) {
+	issuer, provider := newTestProvider(t)
+	codeVerifier := oauth2.GenerateVerifier()
+
+	code, _, err := issuer.Authorize(provider.AuthCodeURL("the-state", "the-nonce", codeVerifier), oauthtest.Identity{Subject: "subject-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Exchange(context.Background(), code, "the-nonce", codeVerifier); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Exchange(context.Background(), code, "the-nonce", codeVerifier); err == nil {
+		t.Fatal("expected the second exchange of the code to fail")
+	}
+}
+
+func TestDisabledProvidersAreNotRegistered(t *testing.T) {
+	koa := koanf.New(".")
+	koa.Set("oauth.mock.enabled", false)
+	koa.Set("oauth.mock.issuer_url", "http://localhost")
+	koa.Set("oauth.mock.client_id", "wapikit")
+
+	if _, err := NewProviderRegistry(koa).Get(context.Background(), "mock"); err != ErrProviderNotFound {
+		t.Fatalf("expected ErrProviderNotFound, got %v", err)
+	}
+}
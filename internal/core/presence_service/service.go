@@ -0,0 +1,183 @@
+package presence_service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/wapikit/wapikit/internal/api_types"
+	cache "github.com/wapikit/wapikit/internal/core/redis"
+)
+
+const (
+	// a connection without a heartbeat for this long is considered gone, clients ping every few seconds
+	ConnectionTTL = 45 * time.Second
+	// heartbeats are only written to redis at this interval, even if the client pings more often
+	HeartbeatInterval = 15 * time.Second
+	// last seen timestamps are kept long enough to show when a member was last online
+	lastSeenTTL = 30 * 24 * time.Hour
+)
+
+var ErrInvalidStatus = errors.New("presence status can only be set to online, away or on break")
+
+type Presence struct {
+	UserId     string
+	Status     api_types.PresenceStatusEnum
+	LastSeenAt *time.Time
+}
+
+// * presence is tracked per organization, a user can be online in one organization and offline in another
+// * every websocket connection of the user is a member of a sorted set scored by its last heartbeat, so several tabs keep the user online until the last one goes away
+
+func connectionsKey(redisClient *cache.RedisClient, organizationId, userId string) string {
+	return redisClient.ComputeCacheKey("presence", organizationId+":"+userId, "connections")
+}
+
+func statusKey(redisClient *cache.RedisClient, organizationId, userId string) string {
+	return redisClient.ComputeCacheKey("presence", organizationId+":"+userId, "status")
+}
+
+func lastSeenKey(redisClient *cache.RedisClient, organizationId, userId string) string {
+	return redisClient.ComputeCacheKey("presence", organizationId+":"+userId, "last-seen")
+}
+
+// Heartbeat marks the connection as alive and returns the presence of the user, along with whether it changed
+func Heartbeat(ctx context.Context, redisClient *cache.RedisClient, organizationId, userId, connectionId string) (*Presence, bool, error) {
+	previous, err := GetPresence(ctx, redisClient, organizationId, userId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	key := connectionsKey(redisClient, organizationId, userId)
+
+	pipeline := redisClient.TxPipeline()
+	pipeline.ZAdd(ctx, key, &redis.Z{Score: float64(now.Unix()), Member: connectionId})
+	pipeline.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.Add(-ConnectionTTL).Unix(), 10))
+	pipeline.Expire(ctx, key, ConnectionTTL)
+	pipeline.Set(ctx, lastSeenKey(redisClient, organizationId, userId), now.Unix(), lastSeenTTL)
+
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return nil, false, err
+	}
+
+	current, err := GetPresence(ctx, redisClient, organizationId, userId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return current, current.Status != previous.Status, nil
+}
+
+// Disconnect removes the connection, the explicit status of the user is cleared when it was their last connection
+func Disconnect(ctx context.Context, redisClient *cache.RedisClient, organizationId, userId, connectionId string) (*Presence, bool, error) {
+	previous, err := GetPresence(ctx, redisClient, organizationId, userId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := connectionsKey(redisClient, organizationId, userId)
+
+	pipeline := redisClient.TxPipeline()
+	pipeline.ZRem(ctx, key, connectionId)
+	pipeline.Set(ctx, lastSeenKey(redisClient, organizationId, userId), time.Now().Unix(), lastSeenTTL)
+
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return nil, false, err
+	}
+
+	current, err := GetPresence(ctx, redisClient, organizationId, userId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if current.Status == api_types.Offline {
+		if err := redisClient.Del(ctx, statusKey(redisClient, organizationId, userId)).Err(); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return current, current.Status != previous.Status, nil
+}
+
+// SetStatus stores the status chosen by the user, it only takes effect while the user has a live connection
+func SetStatus(ctx context.Context, redisClient *cache.RedisClient, organizationId, userId string, status api_types.PresenceStatusEnum) (*Presence, bool, error) {
+	if status != api_types.Online && status != api_types.Away && status != api_types.OnBreak {
+		return nil, false, ErrInvalidStatus
+	}
+
+	previous, err := GetPresence(ctx, redisClient, organizationId, userId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := redisClient.Set(ctx, statusKey(redisClient, organizationId, userId), string(status), 0).Err(); err != nil {
+		return nil, false, err
+	}
+
+	current, err := GetPresence(ctx, redisClient, organizationId, userId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return current, current.Status != previous.Status, nil
+}
+
+// GetPresence returns the effective presence of the user, users without a live connection are offline whatever status they chose
+func GetPresence(ctx context.Context, redisClient *cache.RedisClient, organizationId, userId string) (*Presence, error) {
+	presence := &Presence{
+		UserId: userId,
+		Status: api_types.Offline,
+	}
+
+	pipeline := redisClient.Pipeline()
+	liveConnections := pipeline.ZCount(ctx, connectionsKey(redisClient, organizationId, userId), strconv.FormatInt(time.Now().Add(-ConnectionTTL).Unix(), 10), "+inf")
+	status := pipeline.Get(ctx, statusKey(redisClient, organizationId, userId))
+	lastSeen := pipeline.Get(ctx, lastSeenKey(redisClient, organizationId, userId))
+
+	if _, err := pipeline.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	if lastSeenAt, err := lastSeen.Int64(); err == nil {
+		lastSeenTime := time.Unix(lastSeenAt, 0)
+		presence.LastSeenAt = &lastSeenTime
+	}
+
+	if liveConnections.Val() == 0 {
+		return presence, nil
+	}
+
+	presence.Status = api_types.Online
+	if chosenStatus, err := status.Result(); err == nil && chosenStatus != "" {
+		presence.Status = api_types.PresenceStatusEnum(chosenStatus)
+	}
+
+	return presence, nil
+}
+
+// GetPresences returns the presence of each of the users, keyed by user id
+func GetPresences(ctx context.Context, redisClient *cache.RedisClient, organizationId string, userIds []string) (map[string]Presence, error) {
+	presences := make(map[string]Presence, len(userIds))
+
+	for _, userId := range userIds {
+		presence, err := GetPresence(ctx, redisClient, organizationId, userId)
+		if err != nil {
+			return nil, err
+		}
+		presences[userId] = *presence
+	}
+
+	return presences, nil
+}
+
+func (presence Presence) ToSchema(memberId string) api_types.MemberPresenceSchema {
+	return api_types.MemberPresenceSchema{
+		MemberId:   memberId,
+		UserId:     presence.UserId,
+		Status:     presence.Status,
+		LastSeenAt: presence.LastSeenAt,
+	}
+}
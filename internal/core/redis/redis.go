@@ -48,6 +48,36 @@ func (client *RedisClient) GetCachedData(key string) (string, error) {
 	return val, nil
 }
 
+// TakeCachedData returns the cached value and drops it in one step, so that only one caller ever gets it
+func (client *RedisClient) TakeCachedData(key string) (string, error) {
+	ctx := context.Background()
+	return client.GetDel(ctx, key).Result()
+}
+
+// IsNotCached reports whether the error of GetCachedData is the key not being cached, rather than redis failing
+func IsNotCached(err error) bool {
+	return err == redis.Nil
+}
+
+// CountRequest counts a request under the key and returns the requests counted so far, the count is dropped once no
+// request has been counted under the key for the window
+func (client *RedisClient) CountRequest(key string, window time.Duration) (int64, error) {
+	ctx := context.Background()
+
+	var requests *redis.IntCmd
+	_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		requests = pipe.Incr(ctx, key)
+		pipe.PExpire(ctx, key, window)
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return requests.Val(), nil
+}
+
 func (client *RedisClient) ComputeCacheKey(context, id, object string) string {
 	return strings.Join([]string{context, object, id}, ":")
 }
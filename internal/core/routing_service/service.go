@@ -0,0 +1,333 @@
+package routing_service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/presence_service"
+	cache "github.com/wapikit/wapikit/internal/core/redis"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// RouteConversation assigns the conversation to a member of the organization using its routing rules.
+// Rules are tried in the order of their priority, and the first rule matching the conversation with an eligible member assigns it.
+// A nil member is returned when no rule could assign the conversation, the conversation is then left unassigned.
+// Only members who are online are assigned conversations, presence is skipped when redis is not configured.
+func RouteConversation(ctx context.Context, db *sql.DB, redisClient *cache.RedisClient, conversation model.Conversation) (*model.OrganizationMember, error) {
+	var rules []model.ConversationRoutingRule
+
+	err := SELECT(table.ConversationRoutingRule.AllColumns).
+		FROM(table.ConversationRoutingRule).
+		WHERE(
+			table.ConversationRoutingRule.OrganizationId.EQ(UUID(conversation.OrganizationId)).
+				AND(table.ConversationRoutingRule.IsEnabled.IS_TRUE()),
+		).
+		ORDER_BY(table.ConversationRoutingRule.Priority.ASC(), table.ConversationRoutingRule.CreatedAt.ASC()).
+		QueryContext(ctx, db, &rules)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		isMatching, err := ruleMatchesConversation(ctx, db, rule, conversation)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isMatching {
+			continue
+		}
+
+		member, err := assignWithRule(ctx, db, redisClient, rule.UniqueId, conversation)
+		if err != nil {
+			return nil, err
+		}
+
+		if member != nil {
+			return member, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// AssignConversation marks the current assignment of the conversation as unassigned and assigns it to the member
+func AssignConversation(ctx context.Context, db qrm.DB, conversationId, organizationMemberId uuid.UUID) error {
+	_, err := table.ConversationAssignment.UPDATE(table.ConversationAssignment.Status, table.ConversationAssignment.UpdatedAt).
+		SET(utils.EnumExpression(model.ConversationAssignmentStatus_Unassigned.String()), TimestampzT(time.Now())).
+		WHERE(
+			table.ConversationAssignment.ConversationId.EQ(UUID(conversationId)).
+				AND(table.ConversationAssignment.Status.EQ(utils.EnumExpression(model.ConversationAssignmentStatus_Assigned.String()))),
+		).
+		ExecContext(ctx, db)
+
+	if err != nil {
+		return err
+	}
+
+	// * the member might have been assigned this conversation before, in which case the old record is reused
+	_, err = table.ConversationAssignment.INSERT(table.ConversationAssignment.AllColumns).
+		MODEL(model.ConversationAssignment{
+			ConversationId:                 conversationId,
+			AssignedToOrganizationMemberId: organizationMemberId,
+			Status:                         model.ConversationAssignmentStatus_Assigned,
+			CreatedAt:                      time.Now(),
+			UpdatedAt:                      time.Now(),
+		}).
+		ON_CONFLICT(table.ConversationAssignment.ConversationId, table.ConversationAssignment.AssignedToOrganizationMemberId).
+		DO_UPDATE(SET(
+			table.ConversationAssignment.Status.SET(utils.EnumExpression(model.ConversationAssignmentStatus_Assigned.String())),
+			table.ConversationAssignment.UpdatedAt.SET(TimestampzT(time.Now())),
+		)).
+		ExecContext(ctx, db)
+
+	return err
+}
+
+func ruleMatchesConversation(ctx context.Context, db *sql.DB, rule model.ConversationRoutingRule, conversation model.Conversation) (bool, error) {
+	if rule.PhoneNumberId != nil && *rule.PhoneNumberId != "" && *rule.PhoneNumberId != conversation.PhoneNumberUsed {
+		return false, nil
+	}
+
+	if rule.TagId == nil {
+		return true, nil
+	}
+
+	// * a contact has a tag when it is part of a list with the tag
+	var taggedLists []model.ContactListContact
+
+	err := SELECT(table.ContactListContact.AllColumns).
+		FROM(table.ContactListContact.
+			INNER_JOIN(table.ContactListTag, table.ContactListTag.ContactListId.EQ(table.ContactListContact.ContactListId))).
+		WHERE(
+			table.ContactListContact.ContactId.EQ(UUID(conversation.ContactId)).
+				AND(table.ContactListTag.TagId.EQ(UUID(*rule.TagId))),
+		).
+		LIMIT(1).
+		QueryContext(ctx, db, &taggedLists)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return false, err
+	}
+
+	return len(taggedLists) > 0, nil
+}
+
+// assignWithRule picks a member using the strategy of the rule and assigns the conversation to them.
+// The rule is locked while picking, so concurrent conversations do not all go to the same member.
+func assignWithRule(ctx context.Context, db *sql.DB, redisClient *cache.RedisClient, ruleId uuid.UUID, conversation model.Conversation) (*model.OrganizationMember, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var rule model.ConversationRoutingRule
+
+	err = SELECT(table.ConversationRoutingRule.AllColumns).
+		FROM(table.ConversationRoutingRule).
+		WHERE(table.ConversationRoutingRule.UniqueId.EQ(UUID(ruleId))).
+		FOR(UPDATE()).
+		QueryContext(ctx, tx, &rule)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	candidates, err := fetchCandidates(ctx, tx, rule)
+	if err != nil || len(candidates) == 0 {
+		return nil, err
+	}
+
+	candidates, err = filterOnlineMembers(ctx, redisClient, rule.OrganizationId, candidates)
+	if err != nil || len(candidates) == 0 {
+		return nil, err
+	}
+
+	activeConversations, err := countActiveConversations(ctx, tx, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	eligibleMembers := make([]model.OrganizationMember, 0, len(candidates))
+	for _, candidate := range candidates {
+		if rule.MaxConcurrentConversations != nil && activeConversations[candidate.UniqueId] >= int(*rule.MaxConcurrentConversations) {
+			continue
+		}
+		eligibleMembers = append(eligibleMembers, candidate)
+	}
+
+	if len(eligibleMembers) == 0 {
+		return nil, nil
+	}
+
+	var member model.OrganizationMember
+
+	switch rule.Strategy {
+	case model.ConversationRoutingStrategyEnum_LeastBusy:
+		member = eligibleMembers[0]
+		for _, eligibleMember := range eligibleMembers[1:] {
+			if activeConversations[eligibleMember.UniqueId] < activeConversations[member.UniqueId] {
+				member = eligibleMember
+			}
+		}
+	default:
+		member = nextInRotation(candidates, eligibleMembers, rule.LastAssignedOrganizationMemberId)
+	}
+
+	err = AssignConversation(ctx, tx, conversation.UniqueId, member.UniqueId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = table.ConversationRoutingRule.UPDATE(table.ConversationRoutingRule.LastAssignedOrganizationMemberId).
+		SET(UUID(member.UniqueId)).
+		WHERE(table.ConversationRoutingRule.UniqueId.EQ(UUID(rule.UniqueId))).
+		ExecContext(ctx, tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// fetchCandidates returns the available members of the rule, or every available member of the organization when the rule has none
+func fetchCandidates(ctx context.Context, tx *sql.Tx, rule model.ConversationRoutingRule) ([]model.OrganizationMember, error) {
+	var ruleMembers []model.ConversationRoutingRuleMember
+
+	err := SELECT(table.ConversationRoutingRuleMember.AllColumns).
+		FROM(table.ConversationRoutingRuleMember).
+		WHERE(table.ConversationRoutingRuleMember.RoutingRuleId.EQ(UUID(rule.UniqueId))).
+		QueryContext(ctx, tx, &ruleMembers)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	whereCondition := table.OrganizationMember.OrganizationId.EQ(UUID(rule.OrganizationId)).
+		AND(table.OrganizationMember.IsAvailable.IS_TRUE())
+
+	if len(ruleMembers) > 0 {
+		memberIds := make([]Expression, 0, len(ruleMembers))
+		for _, ruleMember := range ruleMembers {
+			memberIds = append(memberIds, UUID(ruleMember.OrganizationMemberId))
+		}
+		whereCondition = whereCondition.AND(table.OrganizationMember.UniqueId.IN(memberIds...))
+	}
+
+	var members []model.OrganizationMember
+
+	err = SELECT(table.OrganizationMember.AllColumns).
+		FROM(table.OrganizationMember).
+		WHERE(whereCondition).
+		ORDER_BY(table.OrganizationMember.CreatedAt.ASC(), table.OrganizationMember.UniqueId.ASC()).
+		QueryContext(ctx, tx, &members)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func filterOnlineMembers(ctx context.Context, redisClient *cache.RedisClient, organizationId uuid.UUID, members []model.OrganizationMember) ([]model.OrganizationMember, error) {
+	if redisClient == nil {
+		return members, nil
+	}
+
+	userIds := make([]string, 0, len(members))
+	for _, member := range members {
+		userIds = append(userIds, member.UserId.String())
+	}
+
+	presences, err := presence_service.GetPresences(ctx, redisClient, organizationId.String(), userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	onlineMembers := make([]model.OrganizationMember, 0, len(members))
+	for _, member := range members {
+		if presences[member.UserId.String()].Status == api_types.Online {
+			onlineMembers = append(onlineMembers, member)
+		}
+	}
+
+	return onlineMembers, nil
+}
+
+func countActiveConversations(ctx context.Context, tx *sql.Tx, members []model.OrganizationMember) (map[uuid.UUID]int, error) {
+	memberIds := make([]Expression, 0, len(members))
+	for _, member := range members {
+		memberIds = append(memberIds, UUID(member.UniqueId))
+	}
+
+	var assignments []model.ConversationAssignment
+
+	err := SELECT(table.ConversationAssignment.AllColumns).
+		FROM(table.ConversationAssignment.
+			INNER_JOIN(table.Conversation, table.Conversation.UniqueId.EQ(table.ConversationAssignment.ConversationId))).
+		WHERE(
+			table.ConversationAssignment.AssignedToOrganizationMemberId.IN(memberIds...).
+				AND(table.ConversationAssignment.Status.EQ(utils.EnumExpression(model.ConversationAssignmentStatus_Assigned.String()))).
+				AND(table.Conversation.Status.EQ(utils.EnumExpression(model.ConversationStatusEnum_Active.String()))),
+		).
+		QueryContext(ctx, tx, &assignments)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	activeConversations := make(map[uuid.UUID]int, len(members))
+	for _, assignment := range assignments {
+		activeConversations[assignment.AssignedToOrganizationMemberId]++
+	}
+
+	return activeConversations, nil
+}
+
+// nextInRotation returns the first eligible member after the last assigned one, members are ordered by when they joined the organization
+func nextInRotation(candidates []model.OrganizationMember, eligibleMembers []model.OrganizationMember, lastAssignedMemberId *uuid.UUID) model.OrganizationMember {
+	isEligible := make(map[uuid.UUID]bool, len(eligibleMembers))
+	for _, member := range eligibleMembers {
+		isEligible[member.UniqueId] = true
+	}
+
+	lastIndex := -1
+	if lastAssignedMemberId != nil {
+		for index, candidate := range candidates {
+			if candidate.UniqueId == *lastAssignedMemberId {
+				lastIndex = index
+				break
+			}
+		}
+	}
+
+	for offset := 1; offset <= len(candidates); offset++ {
+		candidate := candidates[(lastIndex+offset)%len(candidates)]
+		if isEligible[candidate.UniqueId] {
+			return candidate
+		}
+	}
+
+	return eligibleMembers[0]
+}
@@ -0,0 +1,83 @@
+package search_service
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// * the documents below must stay identical to the expressions of the MessageSearchIndex and ContactSearchIndex
+// * indexes in the schema, otherwise postgres can not use the indexes and falls back to scanning the tables.
+// * the simple configuration does not stem words, contacts write in whatever language they speak.
+
+const (
+	messageDocument = `to_tsvector('simple'::regconfig, ((COALESCE(("Message"."MessageData" ->> 'text'::text), ''::text) || ' '::text) || COALESCE(("Message"."MessageData" ->> 'caption'::text), ''::text)))`
+	messageText     = `COALESCE("Message"."MessageData" ->> 'text', "Message"."MessageData" ->> 'caption', '')`
+	contactDocument = `to_tsvector('simple'::regconfig, (("Contact"."Name" || ' '::text) || "Contact"."PhoneNumber"))`
+	contactText     = `"Contact"."Name" || ' ' || "Contact"."PhoneNumber"`
+
+	// websearch syntax lets users quote phrases, use OR and exclude words with a leading -
+	searchQuery     = `websearch_to_tsquery('simple'::regconfig, #query)`
+	headlineOptions = `'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=5, MaxWords=20'`
+)
+
+// escapeHtml escapes the text of the expression before it is highlighted, the highlights are rendered as html and the
+// text is written by contacts. postgres parses the escaped characters as entities, which ts_headline copies as they are.
+func escapeHtml(text string) string {
+	return `replace(replace(replace(replace(replace(` + text + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+}
+
+// NormalizeQuery trims the query, an empty query means no search was asked for
+func NormalizeQuery(query *string) string {
+	if query == nil {
+		return ""
+	}
+	return strings.TrimSpace(*query)
+}
+
+// MessageMatches is true for the messages whose text or caption match the query
+func MessageMatches(query string) BoolExpression {
+	return RawBool(messageDocument+" @@ "+searchQuery, RawArgs{"#query": query})
+}
+
+// ContactMatches is true for the contacts whose name or phone number match the query
+func ContactMatches(query string) BoolExpression {
+	return RawBool(contactDocument+" @@ "+searchQuery, RawArgs{"#query": query})
+}
+
+func MessageRank(query string) FloatExpression {
+	return RawFloat("ts_rank("+messageDocument+", "+searchQuery+")", RawArgs{"#query": query})
+}
+
+func ContactRank(query string) FloatExpression {
+	return RawFloat("ts_rank("+contactDocument+", "+searchQuery+")", RawArgs{"#query": query})
+}
+
+// MessageHighlight returns the fragments of the message around the matched words, wrapped in <mark> tags. The result is
+// safe html, the text of the message is escaped.
+func MessageHighlight(query string) StringExpression {
+	return RawString("ts_headline('simple'::regconfig, "+escapeHtml(messageText)+", "+searchQuery+", "+headlineOptions+")", RawArgs{"#query": query})
+}
+
+// ContactHighlight returns the name and phone number of the contact with the matched words wrapped in <mark> tags. The
+// result is safe html, the name of the contact is escaped.
+func ContactHighlight(query string) StringExpression {
+	return RawString("ts_headline('simple'::regconfig, "+escapeHtml(contactText)+", "+searchQuery+", "+headlineOptions+")", RawArgs{"#query": query})
+}
+
+// MessageSentBetween limits messages to the time span, either end of it can be left open
+func MessageSentBetween(from, to *time.Time) BoolExpression {
+	condition := Bool(true)
+
+	if from != nil {
+		condition = condition.AND(table.Message.CreatedAt.GT_EQ(TimestampzT(*from)))
+	}
+
+	if to != nil {
+		condition = condition.AND(table.Message.CreatedAt.LT_EQ(TimestampzT(*to)))
+	}
+
+	return condition
+}
@@ -0,0 +1,250 @@
+package secret_service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	encryptedValuePrefix = "enc:v1:"
+	// masked secrets are sent to the clients instead of the secret itself, only the last few characters are kept
+	maskPrefix = "********"
+)
+
+var (
+	ErrMissingEncryptionKey = errors.New("app.encryption_key is not configured")
+	ErrUnknownEncryptionKey = errors.New("secret was encrypted with a key which is not configured")
+	ErrMalformedSecret      = errors.New("malformed encrypted secret")
+)
+
+// SecretService encrypts the secrets organizations store with us, like whatsapp access tokens, AI api keys and smtp passwords.
+// Every secret is encrypted with its own random data key, and the data key is wrapped with the master key from app.encryption_key.
+// The id of the master key is stored along with the secret, so rotating the master key only requires re-wrapping the data keys.
+type SecretService struct {
+	currentKeyId string
+	keys         map[string][]byte
+}
+
+// NewSecretService takes the current master key and the previous master keys, the previous keys are only used for decryption
+func NewSecretService(currentKey string, previousKeys []string) (*SecretService, error) {
+	if currentKey == "" {
+		return nil, ErrMissingEncryptionKey
+	}
+
+	service := &SecretService{
+		keys: make(map[string][]byte),
+	}
+
+	for _, key := range append([]string{currentKey}, previousKeys...) {
+		if key == "" {
+			continue
+		}
+		keyId, keyBytes := deriveMasterKey(key)
+		service.keys[keyId] = keyBytes
+	}
+
+	service.currentKeyId, _ = deriveMasterKey(currentKey)
+
+	return service, nil
+}
+
+func deriveMasterKey(key string) (string, []byte) {
+	keyBytes := sha256.Sum256([]byte(key))
+	keyIdBytes := sha256.Sum256(keyBytes[:])
+	return hex.EncodeToString(keyIdBytes[:4]), keyBytes[:]
+}
+
+// IsEncrypted reports whether the value was produced by Encrypt, values stored before encryption was introduced are plaintext
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedValuePrefix)
+}
+
+func (service *SecretService) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	wrappedDataKey, err := seal(service.keys[service.currentKeyId], dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedValuePrefix + strings.Join([]string{
+		service.currentKeyId,
+		base64.RawStdEncoding.EncodeToString(wrappedDataKey),
+		base64.RawStdEncoding.EncodeToString(ciphertext),
+	}, ":"), nil
+}
+
+// Decrypt returns the plaintext of an encrypted value, plaintext values are returned as is
+func (service *SecretService) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	_, dataKey, ciphertext, err := service.unwrap(value)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(dataKey, ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// Rewrap re-wraps the data key of the value with the current master key, plaintext values are encrypted.
+// The returned boolean is false if the value did not need to change.
+func (service *SecretService) Rewrap(value string) (string, bool, error) {
+	if value == "" {
+		return value, false, nil
+	}
+
+	if !IsEncrypted(value) {
+		encrypted, err := service.Encrypt(value)
+		return encrypted, err == nil, err
+	}
+
+	keyId, dataKey, ciphertext, err := service.unwrap(value)
+	if err != nil {
+		return "", false, err
+	}
+
+	if keyId == service.currentKeyId {
+		return value, false, nil
+	}
+
+	wrappedDataKey, err := seal(service.keys[service.currentKeyId], dataKey)
+	if err != nil {
+		return "", false, err
+	}
+
+	return encryptedValuePrefix + strings.Join([]string{
+		service.currentKeyId,
+		base64.RawStdEncoding.EncodeToString(wrappedDataKey),
+		base64.RawStdEncoding.EncodeToString(ciphertext),
+	}, ":"), true, nil
+}
+
+func (service *SecretService) unwrap(value string) (string, []byte, []byte, error) {
+	parts := strings.Split(strings.TrimPrefix(value, encryptedValuePrefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, ErrMalformedSecret
+	}
+
+	masterKey, ok := service.keys[parts[0]]
+	if !ok {
+		return "", nil, nil, ErrUnknownEncryptionKey
+	}
+
+	wrappedDataKey, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, ErrMalformedSecret
+	}
+
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, ErrMalformedSecret
+	}
+
+	dataKey, err := open(masterKey, wrappedDataKey)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	return parts[0], dataKey, ciphertext, nil
+}
+
+// seal encrypts with AES-256-GCM, the nonce is prefixed to the ciphertext
+func seal(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aesGCM.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aesGCM.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aesGCM.NonceSize() {
+		return nil, ErrMalformedSecret
+	}
+
+	nonce, ciphertext := sealed[:aesGCM.NonceSize()], sealed[aesGCM.NonceSize():]
+	return aesGCM.Open(nil, nonce, ciphertext, nil)
+}
+
+// Mask hides all but the last four characters of a plaintext secret, so users can still tell which secret is configured
+func Mask(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) <= 8 {
+		return maskPrefix
+	}
+	return maskPrefix + secret[len(secret)-4:]
+}
+
+// IsMasked reports whether a value sent by a client is a masked secret, in which case the stored secret must be kept as is
+func IsMasked(value string) bool {
+	return strings.HasPrefix(value, maskPrefix)
+}
+
+// MaskStored masks a secret as stored in the database, encrypted or not
+func (service *SecretService) MaskStored(value string) string {
+	if value == "" {
+		return ""
+	}
+	plaintext, err := service.Decrypt(value)
+	if err != nil {
+		return maskPrefix
+	}
+	return Mask(plaintext)
+}
+
+// EncryptUpdate returns the value to store for a secret sent by a client, a masked secret means the stored one is kept
+func (service *SecretService) EncryptUpdate(value, stored string) (string, error) {
+	if IsMasked(value) {
+		return stored, nil
+	}
+	return service.Encrypt(value)
+}
@@ -0,0 +1,537 @@
+package segment_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/api_types"
+	"github.com/wapikit/wapikit/internal/core/tenant_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+const (
+	// nesting deeper than this is never needed by a real audience, and keeps the generated SQL readable
+	maxGroupDepth = 5
+	maxConditions = 50
+)
+
+var (
+	ErrInvalidRules    = errors.New("invalid segment rules")
+	ErrSegmentNotFound = errors.New("segment not found")
+)
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRules, fmt.Sprintf(format, args...))
+}
+
+// Condition compiles the rules to the condition matching the contacts of the organization in the segment. The condition
+// refers to the Contact table, so it can be used in any query selecting from it. Errors caused by the rules wrap
+// ErrInvalidRules.
+func Condition(organizationId uuid.UUID, rules api_types.SegmentRuleGroupSchema) (BoolExpression, error) {
+	conditionCount := 0
+
+	rulesCondition, err := groupCondition(rules, 1, &conditionCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.Contact.OrganizationId.EQ(UUID(organizationId)).AND(rulesCondition), nil
+}
+
+func groupCondition(group api_types.SegmentRuleGroupSchema, depth int, conditionCount *int) (BoolExpression, error) {
+	if depth > maxGroupDepth {
+		return nil, invalid("groups can not be nested more than %d levels deep", maxGroupDepth)
+	}
+
+	if group.Match != api_types.All && group.Match != api_types.Any {
+		return nil, invalid("unknown match %q", group.Match)
+	}
+
+	expressions := make([]BoolExpression, 0, len(group.Conditions))
+
+	for _, condition := range group.Conditions {
+		*conditionCount++
+		if *conditionCount > maxConditions {
+			return nil, invalid("a segment can not have more than %d conditions", maxConditions)
+		}
+
+		expression, err := conditionExpression(condition)
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, expression)
+	}
+
+	if group.Groups != nil {
+		for _, nestedGroup := range *group.Groups {
+			expression, err := groupCondition(nestedGroup, depth+1, conditionCount)
+			if err != nil {
+				return nil, err
+			}
+			expressions = append(expressions, expression)
+		}
+	}
+
+	if len(expressions) == 0 {
+		return nil, invalid("every group needs at least one condition")
+	}
+
+	if group.Match == api_types.Any {
+		return OR(expressions...), nil
+	}
+
+	return AND(expressions...), nil
+}
+
+func conditionExpression(condition api_types.SegmentConditionSchema) (BoolExpression, error) {
+	value := ""
+	if condition.Value != nil {
+		value = strings.TrimSpace(*condition.Value)
+	}
+
+	switch condition.Field {
+	case api_types.Attribute:
+		return attributeCondition(condition, value)
+
+	case api_types.Status:
+		status := model.ContactStatusEnum("")
+		if err := status.Scan(value); err != nil {
+			return nil, invalid("unknown contact status %q", value)
+		}
+		return equality(condition.Operator, table.Contact.Status.EQ(utils.EnumExpression(status.String())))
+
+	case api_types.ConversationTag:
+		tagUuid, err := uuid.Parse(value)
+		if err != nil {
+			return nil, invalid("invalid tag id %q", value)
+		}
+		return equality(condition.Operator, EXISTS(
+			SELECT(table.ConversationTag.TagId).
+				FROM(table.ConversationTag.
+					INNER_JOIN(table.Conversation, table.Conversation.UniqueId.EQ(table.ConversationTag.ConversationId)),
+				).
+				WHERE(
+					table.Conversation.ContactId.EQ(table.Contact.UniqueId).
+						AND(table.ConversationTag.TagId.EQ(UUID(tagUuid))),
+				),
+		))
+
+	case api_types.ListMembership:
+		listUuid, err := uuid.Parse(value)
+		if err != nil {
+			return nil, invalid("invalid list id %q", value)
+		}
+		return equality(condition.Operator, EXISTS(
+			SELECT(table.ContactListContact.ContactId).
+				FROM(table.ContactListContact).
+				WHERE(
+					table.ContactListContact.ContactId.EQ(table.Contact.UniqueId).
+						AND(table.ContactListContact.ContactListId.EQ(UUID(listUuid))),
+				),
+		))
+
+	case api_types.Replied:
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 {
+			return nil, invalid("the number of days must be a positive number")
+		}
+
+		repliedCondition := EXISTS(
+			SELECT(table.Message.UniqueId).
+				FROM(table.Message).
+				WHERE(
+					table.Message.ContactId.EQ(table.Contact.UniqueId).
+						AND(table.Message.Direction.EQ(utils.EnumExpression(model.MessageDirectionEnum_InBound.String()))).
+						AND(table.Message.CreatedAt.GT_EQ(TimestampzT(time.Now().AddDate(0, 0, -days)))),
+				),
+		)
+
+		switch condition.Operator {
+		case api_types.WithinDays:
+			return repliedCondition, nil
+		case api_types.NotWithinDays:
+			return NOT(repliedCondition), nil
+		default:
+			return nil, invalid("%s conditions only support WithinDays and NotWithinDays", condition.Field)
+		}
+
+	case api_types.ClickedCampaignLink:
+		clickCondition := table.TrackLinkClick.ContactId.EQ(table.Contact.UniqueId)
+
+		// * without a campaign the links of any campaign count
+		if value != "" {
+			campaignUuid, err := uuid.Parse(value)
+			if err != nil {
+				return nil, invalid("invalid campaign id %q", value)
+			}
+			clickCondition = clickCondition.AND(table.TrackLink.CampaignId.EQ(UUID(campaignUuid)))
+		}
+
+		return equality(condition.Operator, EXISTS(
+			SELECT(table.TrackLinkClick.UniqueId).
+				FROM(table.TrackLinkClick.
+					INNER_JOIN(table.TrackLink, table.TrackLink.UniqueId.EQ(table.TrackLinkClick.TrackLinkId)),
+				).
+				WHERE(clickCondition),
+		))
+
+	case api_types.ReadCampaign:
+		campaignUuid, err := uuid.Parse(value)
+		if err != nil {
+			return nil, invalid("invalid campaign id %q", value)
+		}
+		return equality(condition.Operator, EXISTS(
+			SELECT(table.Message.UniqueId).
+				FROM(table.Message).
+				WHERE(
+					table.Message.ContactId.EQ(table.Contact.UniqueId).
+						AND(table.Message.CampaignId.EQ(UUID(campaignUuid))).
+						AND(table.Message.Status.EQ(utils.EnumExpression(model.MessageStatusEnum_Read.String()))),
+				),
+		))
+
+	default:
+		return nil, invalid("unknown field %q", condition.Field)
+	}
+}
+
+// equality applies the Equals and NotEquals operators of the fields matched by a single expression
+func equality(operator api_types.SegmentConditionOperatorEnum, expression BoolExpression) (BoolExpression, error) {
+	switch operator {
+	case api_types.Equals:
+		return expression, nil
+	case api_types.NotEquals:
+		return NOT(expression), nil
+	default:
+		return nil, invalid("the operator %s is not supported by this field", operator)
+	}
+}
+
+func attributeCondition(condition api_types.SegmentConditionSchema, value string) (BoolExpression, error) {
+	if condition.Attribute == nil || strings.TrimSpace(*condition.Attribute) == "" {
+		return nil, invalid("attribute conditions need the name of the attribute")
+	}
+
+	attributeArgs := RawArgs{"#attribute": strings.TrimSpace(*condition.Attribute)}
+	attribute := StringExp(Raw(`"Contact"."Attributes"->>#attribute`, attributeArgs))
+
+	switch condition.Operator {
+	// * containment is served by the jsonb_path_ops GIN index on the attributes, ->> comparisons can not use any index
+	case api_types.Equals:
+		return attributeEquals(strings.TrimSpace(*condition.Attribute), value)
+
+	case api_types.NotEquals:
+		equals, err := attributeEquals(strings.TrimSpace(*condition.Attribute), value)
+		if err != nil {
+			return nil, err
+		}
+		// * contacts without the attribute are not equal to the value either
+		return NOT(BoolExp(COALESCE(equals, Bool(false)))), nil
+
+	case api_types.Contains:
+		return LOWER(attribute).LIKE(String(containsPattern(value))), nil
+
+	case api_types.NotContains:
+		return attribute.IS_NULL().OR(NOT(LOWER(attribute).LIKE(String(containsPattern(value))))), nil
+
+	case api_types.GreaterThan, api_types.LessThan:
+		// * date fields are stored as YYYY-MM-DD, which compares in the order of the dates
+		if _, err := time.Parse("2006-01-02", strings.TrimSpace(value)); err == nil {
+			dateAttribute := StringExp(Raw(
+				`CASE WHEN "Contact"."Attributes"->>#attribute ~ '^[0-9]{4}-[0-9]{2}-[0-9]{2}$' THEN "Contact"."Attributes"->>#attribute END`,
+				attributeArgs,
+			))
+
+			if condition.Operator == api_types.GreaterThan {
+				return dateAttribute.GT(String(strings.TrimSpace(value))), nil
+			}
+			return dateAttribute.LT(String(strings.TrimSpace(value))), nil
+		}
+
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, invalid("%s needs a number or a date like 2006-01-02 to compare with", condition.Operator)
+		}
+
+		// * attributes are free form, values which are not numbers never match instead of failing the whole query
+		numericAttribute := FloatExp(Raw(
+			`CASE WHEN "Contact"."Attributes"->>#attribute ~ '^\s*-?[0-9]+(\.[0-9]+)?\s*$' THEN ("Contact"."Attributes"->>#attribute)::numeric END`,
+			attributeArgs,
+		))
+
+		if condition.Operator == api_types.GreaterThan {
+			return numericAttribute.GT(Float(number)), nil
+		}
+		return numericAttribute.LT(Float(number)), nil
+
+	// * the ? operator is served by the GIN index on the attributes
+	case api_types.Exists:
+		return BoolExp(Raw(`"Contact"."Attributes" ? #attribute`, attributeArgs)), nil
+
+	case api_types.NotExists:
+		return BoolExp(Raw(`NOT COALESCE("Contact"."Attributes" ? #attribute, false)`, attributeArgs)), nil
+
+	default:
+		return nil, invalid("the operator %s is not supported by attribute conditions", condition.Operator)
+	}
+}
+
+// attributeEquals matches the attributes equal to the value, number and boolean fields are stored as JSON numbers and
+// booleans, so the value also matches them when it reads as one
+func attributeEquals(attribute, value string) (BoolExpression, error) {
+	candidates := []interface{}{value}
+
+	if number, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+		candidates = append(candidates, number)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true":
+		candidates = append(candidates, true)
+	case "false":
+		candidates = append(candidates, false)
+	}
+
+	var condition BoolExpression
+	for _, candidate := range candidates {
+		document, err := json.Marshal(map[string]interface{}{attribute: candidate})
+		if err != nil {
+			return nil, err
+		}
+
+		contains := BoolExp(Raw(`"Contact"."Attributes" @> #document::jsonb`, RawArgs{"#document": string(document)}))
+		if condition == nil {
+			condition = contains
+		} else {
+			condition = condition.OR(contains)
+		}
+	}
+
+	return condition, nil
+}
+
+// containsPattern escapes the LIKE wildcards of the value, so that it is matched literally
+func containsPattern(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(value))
+	return "%" + escaped + "%"
+}
+
+// ParseRules reads the rules stored with a segment
+func ParseRules(rules string) (api_types.SegmentRuleGroupSchema, error) {
+	var parsedRules api_types.SegmentRuleGroupSchema
+	err := json.Unmarshal([]byte(rules), &parsedRules)
+	return parsedRules, err
+}
+
+// CountContacts returns the number of contacts matching the condition
+func CountContacts(ctx context.Context, db qrm.Queryable, condition BoolExpression) (int, error) {
+	var count struct {
+		Count int
+	}
+
+	err := SELECT(COUNT(table.Contact.UniqueId).AS("count")).
+		FROM(table.Contact).
+		WHERE(condition).
+		QueryContext(ctx, db, &count)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count.Count, nil
+}
+
+// FetchSegment returns the segment of the organization
+func FetchSegment(ctx context.Context, db qrm.Queryable, organizationId, segmentId uuid.UUID) (*model.Segment, error) {
+	var segment model.Segment
+
+	err := tenant_service.Segment.Fetch(ctx, db, organizationId, segmentId, &segment)
+	if err != nil {
+		if err == tenant_service.ErrNotFound {
+			return nil, ErrSegmentNotFound
+		}
+		return nil, err
+	}
+
+	return &segment, nil
+}
+
+// SegmentCondition returns the condition matching the contacts of the saved segment
+func SegmentCondition(segment model.Segment) (BoolExpression, error) {
+	rules, err := ParseRules(segment.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return Condition(segment.OrganizationId, rules)
+}
+
+// SetCampaignSegments replaces the segments of the campaign, every segment must belong to the organization
+func SetCampaignSegments(ctx context.Context, db qrm.Executable, organizationId, campaignId uuid.UUID, segmentIds []string) error {
+	segmentUuids := make(map[uuid.UUID]bool, len(segmentIds))
+	segmentIdExpressions := make([]Expression, 0, len(segmentIds))
+
+	for _, segmentId := range segmentIds {
+		segmentUuid, err := uuid.Parse(segmentId)
+		if err != nil {
+			return ErrSegmentNotFound
+		}
+		if !segmentUuids[segmentUuid] {
+			segmentUuids[segmentUuid] = true
+			segmentIdExpressions = append(segmentIdExpressions, UUID(segmentUuid))
+		}
+	}
+
+	_, err := table.CampaignSegment.DELETE().
+		WHERE(table.CampaignSegment.CampaignId.EQ(UUID(campaignId))).
+		ExecContext(ctx, db)
+
+	if err != nil || len(segmentIdExpressions) == 0 {
+		return err
+	}
+
+	// * only the segments of the organization are inserted, any other id is reported as not found
+	result, err := table.CampaignSegment.INSERT(
+		table.CampaignSegment.SegmentId,
+		table.CampaignSegment.CampaignId,
+		table.CampaignSegment.CreatedAt,
+		table.CampaignSegment.UpdatedAt,
+	).
+		QUERY(
+			SELECT(
+				table.Segment.UniqueId,
+				UUID(campaignId),
+				NOW(),
+				NOW(),
+			).
+				FROM(table.Segment).
+				WHERE(
+					table.Segment.UniqueId.IN(segmentIdExpressions...).
+						AND(table.Segment.OrganizationId.EQ(UUID(organizationId))),
+				),
+		).
+		ExecContext(ctx, db)
+
+	if err != nil {
+		return err
+	}
+
+	if insertedRows, err := result.RowsAffected(); err == nil && int(insertedRows) != len(segmentUuids) {
+		return ErrSegmentNotFound
+	}
+
+	return nil
+}
+
+// FetchCampaignSegments returns the segments of each of the campaigns
+func FetchCampaignSegments(ctx context.Context, db qrm.Queryable, campaignIds []uuid.UUID) (map[uuid.UUID][]model.Segment, error) {
+	campaignSegments := map[uuid.UUID][]model.Segment{}
+
+	if len(campaignIds) == 0 {
+		return campaignSegments, nil
+	}
+
+	campaignIdExpressions := make([]Expression, 0, len(campaignIds))
+	for _, campaignId := range campaignIds {
+		campaignIdExpressions = append(campaignIdExpressions, UUID(campaignId))
+	}
+
+	var rows []struct {
+		model.CampaignSegment
+		model.Segment
+	}
+
+	err := SELECT(table.CampaignSegment.AllColumns, table.Segment.AllColumns).
+		FROM(table.CampaignSegment.
+			INNER_JOIN(table.Segment, table.Segment.UniqueId.EQ(table.CampaignSegment.SegmentId)),
+		).
+		WHERE(table.CampaignSegment.CampaignId.IN(campaignIdExpressions...)).
+		ORDER_BY(table.Segment.Name.ASC()).
+		QueryContext(ctx, db, &rows)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		campaignSegments[row.CampaignSegment.CampaignId] = append(campaignSegments[row.CampaignSegment.CampaignId], row.Segment)
+	}
+
+	return campaignSegments, nil
+}
+
+// CampaignAudienceCondition returns the condition matching the contacts the campaign is sent to, the contacts of its lists
+// and of its segments. false is returned when the campaign has neither.
+func CampaignAudienceCondition(ctx context.Context, db qrm.Queryable, campaign model.Campaign) (BoolExpression, bool, error) {
+	var campaignLists []model.CampaignList
+
+	err := SELECT(table.CampaignList.AllColumns).
+		FROM(table.CampaignList).
+		WHERE(table.CampaignList.CampaignId.EQ(UUID(campaign.UniqueId))).
+		QueryContext(ctx, db, &campaignLists)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, false, err
+	}
+
+	audience := make([]BoolExpression, 0)
+
+	if len(campaignLists) > 0 {
+		listIdExpressions := make([]Expression, 0, len(campaignLists))
+		for _, campaignList := range campaignLists {
+			listIdExpressions = append(listIdExpressions, UUID(campaignList.ContactListId))
+		}
+
+		audience = append(audience, EXISTS(
+			SELECT(table.ContactListContact.ContactId).
+				FROM(table.ContactListContact).
+				WHERE(
+					table.ContactListContact.ContactId.EQ(table.Contact.UniqueId).
+						AND(table.ContactListContact.ContactListId.IN(listIdExpressions...)),
+				),
+		))
+	}
+
+	segments, err := FetchCampaignSegments(ctx, db, []uuid.UUID{campaign.UniqueId})
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, segment := range segments[campaign.UniqueId] {
+		condition, err := SegmentCondition(segment)
+		if err != nil {
+			return nil, false, fmt.Errorf("segment %s: %w", segment.UniqueId.String(), err)
+		}
+		audience = append(audience, condition)
+	}
+
+	if len(audience) == 0 {
+		return nil, false, nil
+	}
+
+	return table.Contact.OrganizationId.EQ(UUID(campaign.OrganizationId)).AND(OR(audience...)), true, nil
+}
+
+func ToSchema(segment model.Segment) api_types.SegmentSchema {
+	rules, _ := ParseRules(segment.Rules)
+
+	return api_types.SegmentSchema{
+		UniqueId:    segment.UniqueId.String(),
+		Name:        segment.Name,
+		Description: segment.Description,
+		Rules:       rules,
+		CreatedAt:   segment.CreatedAt,
+		UpdatedAt:   segment.UpdatedAt,
+	}
+}
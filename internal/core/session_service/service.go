@@ -0,0 +1,246 @@
+package session_service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	cache "github.com/wapikit/wapikit/internal/core/redis"
+	"github.com/wapikit/wapikit/internal/interfaces"
+
+	"github.com/go-jet/jet/qrm"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+const (
+	// access tokens are short lived, clients are expected to use the refresh token to get a new one
+	AccessTokenTTL = 15 * time.Minute
+	// refresh tokens are rotated on every use, the expiry slides forward with each rotation
+	RefreshTokenTTL = 30 * 24 * time.Hour
+	// password reset tokens are single use and are only valid for a short time
+	PasswordResetTokenTTL = 30 * time.Minute
+)
+
+var ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+type SessionMetaData struct {
+	UserAgent string
+	IpAddress string
+}
+
+// GenerateOpaqueToken returns a random url safe token, used for refresh tokens and password reset tokens
+func GenerateOpaqueToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// HashToken returns the sha256 hash of a token, only the hash is ever persisted
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func SignAccessToken(user interfaces.ContextUser, sessionId, secret string) (string, error) {
+	claims := &interfaces.JwtPayload{
+		ContextUser: user,
+		SessionId:   sessionId,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(AccessTokenTTL).Unix(),
+			Issuer:    "wapikit",
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
+
+// CreateSession persists a new session for the user and returns it along with the plain refresh token
+func CreateSession(ctx context.Context, db *sql.DB, userId uuid.UUID, organizationId *uuid.UUID, metaData SessionMetaData) (*model.UserSession, string, error) {
+	refreshToken, err := GenerateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var session model.UserSession
+
+	sessionToInsert := model.UserSession{
+		UserId:           userId,
+		OrganizationId:   organizationId,
+		RefreshTokenHash: HashToken(refreshToken),
+		LastUsedAt:       time.Now(),
+		ExpiresAt:        time.Now().Add(RefreshTokenTTL),
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+
+	if metaData.UserAgent != "" {
+		sessionToInsert.UserAgent = &metaData.UserAgent
+	}
+
+	if metaData.IpAddress != "" {
+		sessionToInsert.IpAddress = &metaData.IpAddress
+	}
+
+	err = table.UserSession.INSERT(table.UserSession.MutableColumns).
+		MODEL(sessionToInsert).
+		RETURNING(table.UserSession.AllColumns).
+		QueryContext(ctx, db, &session)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &session, refreshToken, nil
+}
+
+// RotateSession validates the refresh token and replaces it with a new one, a refresh token can only be used once
+func RotateSession(ctx context.Context, db *sql.DB, refreshToken string) (*model.UserSession, string, error) {
+	var session model.UserSession
+
+	sessionQuery := SELECT(table.UserSession.AllColumns).
+		FROM(table.UserSession).
+		WHERE(
+			table.UserSession.RefreshTokenHash.EQ(String(HashToken(refreshToken))).
+				AND(table.UserSession.RevokedAt.IS_NULL()).
+				AND(table.UserSession.ExpiresAt.GT(TimestampzT(time.Now()))),
+		).
+		LIMIT(1)
+
+	err := sessionQuery.QueryContext(ctx, db, &session)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, "", ErrInvalidRefreshToken
+		}
+		return nil, "", err
+	}
+
+	newRefreshToken, err := GenerateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var updatedSession model.UserSession
+
+	// * the old hash is part of the where clause, so two concurrent refreshes with the same token can not both succeed
+	err = table.UserSession.UPDATE(
+		table.UserSession.RefreshTokenHash,
+		table.UserSession.LastUsedAt,
+		table.UserSession.ExpiresAt,
+		table.UserSession.UpdatedAt,
+	).
+		SET(
+			String(HashToken(newRefreshToken)),
+			TimestampzT(time.Now()),
+			TimestampzT(time.Now().Add(RefreshTokenTTL)),
+			TimestampzT(time.Now()),
+		).
+		WHERE(
+			table.UserSession.UniqueId.EQ(UUID(session.UniqueId)).
+				AND(table.UserSession.RefreshTokenHash.EQ(String(session.RefreshTokenHash))),
+		).
+		RETURNING(table.UserSession.AllColumns).
+		QueryContext(ctx, db, &updatedSession)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, "", ErrInvalidRefreshToken
+		}
+		return nil, "", err
+	}
+
+	return &updatedSession, newRefreshToken, nil
+}
+
+// UpdateSessionOrganization records the organization the session is currently logged in with, so refreshed access tokens keep the same organization
+func UpdateSessionOrganization(ctx context.Context, db *sql.DB, sessionId, organizationId uuid.UUID) error {
+	_, err := table.UserSession.UPDATE(table.UserSession.OrganizationId, table.UserSession.UpdatedAt).
+		SET(UUID(organizationId), TimestampzT(time.Now())).
+		WHERE(table.UserSession.UniqueId.EQ(UUID(sessionId))).
+		ExecContext(ctx, db)
+	return err
+}
+
+// RevokeSession revokes a single session of the user, returns false if there was no active session to revoke
+func RevokeSession(ctx context.Context, db *sql.DB, redis *cache.RedisClient, userId, sessionId uuid.UUID) (bool, error) {
+	var revokedSessions []model.UserSession
+
+	err := table.UserSession.UPDATE(table.UserSession.RevokedAt, table.UserSession.UpdatedAt).
+		SET(TimestampzT(time.Now()), TimestampzT(time.Now())).
+		WHERE(
+			table.UserSession.UniqueId.EQ(UUID(sessionId)).
+				AND(table.UserSession.UserId.EQ(UUID(userId))).
+				AND(table.UserSession.RevokedAt.IS_NULL()),
+		).
+		RETURNING(table.UserSession.AllColumns).
+		QueryContext(ctx, db, &revokedSessions)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, session := range revokedSessions {
+		markSessionRevoked(redis, session.UniqueId.String())
+	}
+
+	return len(revokedSessions) > 0, nil
+}
+
+// RevokeAllSessions revokes every active session of the user and returns the number of sessions revoked
+func RevokeAllSessions(ctx context.Context, db *sql.DB, redis *cache.RedisClient, userId uuid.UUID) (int, error) {
+	var revokedSessions []model.UserSession
+
+	err := table.UserSession.UPDATE(table.UserSession.RevokedAt, table.UserSession.UpdatedAt).
+		SET(TimestampzT(time.Now()), TimestampzT(time.Now())).
+		WHERE(
+			table.UserSession.UserId.EQ(UUID(userId)).
+				AND(table.UserSession.RevokedAt.IS_NULL()),
+		).
+		RETURNING(table.UserSession.AllColumns).
+		QueryContext(ctx, db, &revokedSessions)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	for _, session := range revokedSessions {
+		markSessionRevoked(redis, session.UniqueId.String())
+	}
+
+	return len(revokedSessions), nil
+}
+
+// IsSessionRevoked reports whether the access tokens of a session must no longer be accepted.
+// The revocation marker only needs to outlive the access tokens issued for the session. The session is treated as revoked
+// when redis can not be reached, as a revocation could not be told apart from a valid session then.
+func IsSessionRevoked(redis *cache.RedisClient, sessionId string) bool {
+	if redis == nil {
+		return true
+	}
+	_, err := redis.GetCachedData(redis.ComputeCacheKey("session", sessionId, "revoked"))
+	return !cache.IsNotCached(err)
+}
+
+func markSessionRevoked(redis *cache.RedisClient, sessionId string) {
+	if redis == nil {
+		return
+	}
+	redis.CacheData(redis.ComputeCacheKey("session", sessionId, "revoked"), true, AccessTokenTTL)
+}
@@ -0,0 +1,318 @@
+package sla_service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/api_types"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// deadlines further than this in business time are not computed, it only happens with calendars having almost no open hours
+const maxCalendarDays = 2 * 366
+
+var weekdays = map[api_types.WeekdayEnum]time.Weekday{
+	api_types.Sunday:    time.Sunday,
+	api_types.Monday:    time.Monday,
+	api_types.Tuesday:   time.Tuesday,
+	api_types.Wednesday: time.Wednesday,
+	api_types.Thursday:  time.Thursday,
+	api_types.Friday:    time.Friday,
+	api_types.Saturday:  time.Saturday,
+}
+
+type businessHoursWindow struct {
+	// minutes since midnight
+	start int
+	end   int
+}
+
+// ValidateCalendar makes sure the business hours of the calendar can be used to compute deadlines
+func ValidateCalendar(calendar api_types.BusinessCalendarSchema) error {
+	for _, window := range calendar.WeeklyHours {
+		if _, ok := weekdays[window.Day]; !ok {
+			return fmt.Errorf("invalid day %s", window.Day)
+		}
+
+		start, err := parseClockTime(window.Start)
+		if err != nil {
+			return err
+		}
+
+		end, err := parseClockTime(window.End)
+		if err != nil {
+			return err
+		}
+
+		if end <= start {
+			return fmt.Errorf("business hours on %s must close after they open", window.Day)
+		}
+	}
+
+	for _, holiday := range calendar.Holidays {
+		if _, err := time.Parse(time.DateOnly, holiday); err != nil {
+			return fmt.Errorf("invalid holiday %s, dates must be in the YYYY-MM-DD format", holiday)
+		}
+	}
+
+	return nil
+}
+
+// ParseCalendar decodes the business calendar stored on the policy, nil is returned when the timers run around the clock
+func ParseCalendar(policy model.SlaPolicy) (*api_types.BusinessCalendarSchema, error) {
+	if policy.BusinessCalendar == nil {
+		return nil, nil
+	}
+
+	calendar := new(api_types.BusinessCalendarSchema)
+	if err := json.Unmarshal([]byte(*policy.BusinessCalendar), calendar); err != nil {
+		return nil, err
+	}
+
+	return calendar, nil
+}
+
+// AddBusinessTime returns the time at which the duration has elapsed after from, counting only the open hours of the calendar
+func AddBusinessTime(from time.Time, duration time.Duration, location *time.Location, calendar *api_types.BusinessCalendarSchema) time.Time {
+	if calendar == nil || len(calendar.WeeklyHours) == 0 {
+		return from.Add(duration)
+	}
+
+	windows := make(map[time.Weekday][]businessHoursWindow)
+	for _, window := range calendar.WeeklyHours {
+		weekday, ok := weekdays[window.Day]
+		start, startErr := parseClockTime(window.Start)
+		end, endErr := parseClockTime(window.End)
+		if !ok || startErr != nil || endErr != nil || end <= start {
+			continue
+		}
+		windows[weekday] = append(windows[weekday], businessHoursWindow{start: start, end: end})
+	}
+
+	for _, dayWindows := range windows {
+		sort.Slice(dayWindows, func(i, j int) bool {
+			return dayWindows[i].start < dayWindows[j].start
+		})
+	}
+
+	holidays := make(map[string]bool, len(calendar.Holidays))
+	for _, holiday := range calendar.Holidays {
+		holidays[holiday] = true
+	}
+
+	current := from.In(location)
+	remaining := duration
+
+	for day := 0; day < maxCalendarDays; day++ {
+		year, month, date := current.Date()
+
+		if !holidays[current.Format(time.DateOnly)] {
+			for _, window := range windows[current.Weekday()] {
+				windowStart := time.Date(year, month, date, 0, window.start, 0, 0, location)
+				windowEnd := time.Date(year, month, date, 0, window.end, 0, 0, location)
+
+				if !current.Before(windowEnd) {
+					continue
+				}
+
+				if current.Before(windowStart) {
+					current = windowStart
+				}
+
+				available := windowEnd.Sub(current)
+				if remaining <= available {
+					return current.Add(remaining)
+				}
+
+				remaining -= available
+				current = windowEnd
+			}
+		}
+
+		current = time.Date(year, month, date+1, 0, 0, 0, 0, location)
+	}
+
+	return from.Add(duration)
+}
+
+// FindPolicy returns the policy applying to conversations on the phone number, a policy of the phone number wins over the default one
+func FindPolicy(ctx context.Context, db qrm.Queryable, organizationId uuid.UUID, phoneNumberId string) (*model.SlaPolicy, error) {
+	var policies []model.SlaPolicy
+
+	err := SELECT(table.SlaPolicy.AllColumns).
+		FROM(table.SlaPolicy).
+		WHERE(
+			table.SlaPolicy.OrganizationId.EQ(UUID(organizationId)).
+				AND(table.SlaPolicy.IsEnabled.IS_TRUE()).
+				AND(table.SlaPolicy.PhoneNumberId.EQ(String(phoneNumberId)).OR(table.SlaPolicy.IsDefault.IS_TRUE())),
+		).
+		ORDER_BY(table.SlaPolicy.CreatedAt.ASC()).
+		QueryContext(ctx, db, &policies)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	var defaultPolicy *model.SlaPolicy
+	for index, policy := range policies {
+		if policy.PhoneNumberId != nil && *policy.PhoneNumberId == phoneNumberId {
+			return &policies[index], nil
+		}
+		if defaultPolicy == nil && policy.IsDefault {
+			defaultPolicy = &policies[index]
+		}
+	}
+
+	return defaultPolicy, nil
+}
+
+// StartTimers computes the deadlines of the conversation from now, it is called when the contact opens or reopens the conversation.
+// Timers of a previous cycle of the conversation are reset, and cleared when no policy applies anymore.
+func StartTimers(ctx context.Context, db *sql.DB, conversation model.Conversation) (*model.Conversation, error) {
+	policy, err := FindPolicy(ctx, db, conversation.OrganizationId, conversation.PhoneNumberUsed)
+	if err != nil {
+		return nil, err
+	}
+
+	timers := model.Conversation{
+		UpdatedAt: time.Now(),
+	}
+
+	if policy != nil {
+		location, err := time.LoadLocation(policy.Timezone)
+		if err != nil {
+			location = time.UTC
+		}
+
+		calendar, err := ParseCalendar(*policy)
+		if err != nil {
+			return nil, err
+		}
+
+		startedAt := time.Now()
+		firstResponseDueAt := AddBusinessTime(startedAt, time.Duration(policy.FirstResponseTimeInMinutes)*time.Minute, location, calendar)
+
+		timers.SlaPolicyId = &policy.UniqueId
+		timers.FirstResponseDueAt = &firstResponseDueAt
+
+		if policy.ResolutionTimeInMinutes != nil {
+			resolutionDueAt := AddBusinessTime(startedAt, time.Duration(*policy.ResolutionTimeInMinutes)*time.Minute, location, calendar)
+			timers.ResolutionDueAt = &resolutionDueAt
+		}
+	}
+
+	var updatedConversation model.Conversation
+
+	err = table.Conversation.UPDATE(
+		table.Conversation.SlaPolicyId,
+		table.Conversation.FirstResponseDueAt,
+		table.Conversation.FirstRespondedAt,
+		table.Conversation.FirstResponseBreachedAt,
+		table.Conversation.ResolutionDueAt,
+		table.Conversation.ResolvedAt,
+		table.Conversation.ResolutionBreachedAt,
+		table.Conversation.UpdatedAt,
+	).
+		MODEL(timers).
+		WHERE(table.Conversation.UniqueId.EQ(UUID(conversation.UniqueId))).
+		RETURNING(table.Conversation.AllColumns).
+		QueryContext(ctx, db, &updatedConversation)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &updatedConversation, nil
+}
+
+// RecordFirstResponse stops the first response timer of the conversation, only the first reply of the team counts
+func RecordFirstResponse(ctx context.Context, db qrm.Executable, conversationId uuid.UUID) error {
+	_, err := table.Conversation.UPDATE(table.Conversation.FirstRespondedAt).
+		SET(TimestampzT(time.Now())).
+		WHERE(
+			table.Conversation.UniqueId.EQ(UUID(conversationId)).
+				AND(table.Conversation.SlaPolicyId.IS_NOT_NULL()).
+				AND(table.Conversation.FirstRespondedAt.IS_NULL()),
+		).
+		ExecContext(ctx, db)
+
+	return err
+}
+
+// RecordResolution stops the resolution timer of the conversation, reopening the conversation starts a new cycle of timers
+func RecordResolution(ctx context.Context, db qrm.Executable, conversationId uuid.UUID) error {
+	_, err := table.Conversation.UPDATE(table.Conversation.ResolvedAt).
+		SET(TimestampzT(time.Now())).
+		WHERE(
+			table.Conversation.UniqueId.EQ(UUID(conversationId)).
+				AND(table.Conversation.SlaPolicyId.IS_NOT_NULL()).
+				AND(table.Conversation.ResolvedAt.IS_NULL()),
+		).
+		ExecContext(ctx, db)
+
+	return err
+}
+
+// BuildConversationSla returns the sla state of the conversation, nil when no policy applies to it
+func BuildConversationSla(conversation model.Conversation) *api_types.ConversationSlaSchema {
+	if conversation.SlaPolicyId == nil {
+		return nil
+	}
+
+	sla := &api_types.ConversationSlaSchema{
+		PolicyId:            conversation.SlaPolicyId.String(),
+		FirstResponseDueAt:  conversation.FirstResponseDueAt,
+		FirstRespondedAt:    conversation.FirstRespondedAt,
+		FirstResponseStatus: timerStatus(conversation.FirstResponseDueAt, conversation.FirstRespondedAt, conversation.FirstResponseBreachedAt),
+		ResolutionDueAt:     conversation.ResolutionDueAt,
+		ResolvedAt:          conversation.ResolvedAt,
+	}
+
+	if conversation.ResolutionDueAt != nil {
+		resolutionStatus := timerStatus(conversation.ResolutionDueAt, conversation.ResolvedAt, conversation.ResolutionBreachedAt)
+		sla.ResolutionStatus = &resolutionStatus
+	}
+
+	return sla
+}
+
+// * the worker may not have flagged a breach yet, so the deadline is compared here too
+func timerStatus(dueAt, stoppedAt, breachedAt *time.Time) api_types.SlaStatusEnum {
+	if breachedAt != nil {
+		return api_types.Breached
+	}
+
+	if dueAt == nil {
+		return api_types.Pending
+	}
+
+	if stoppedAt != nil {
+		if stoppedAt.After(*dueAt) {
+			return api_types.Breached
+		}
+		return api_types.Met
+	}
+
+	if time.Now().After(*dueAt) {
+		return api_types.Breached
+	}
+
+	return api_types.Pending
+}
+
+func parseClockTime(clockTime string) (int, error) {
+	parsed, err := time.Parse("15:04", clockTime)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %s, times must be in the HH:MM format", clockTime)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
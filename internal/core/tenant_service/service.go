@@ -0,0 +1,109 @@
+package tenant_service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+// ErrNotFound is returned for ids which do not exist as well as for ids of rows of another organization, the two are never
+// told apart so that members can not probe which ids exist in other organizations
+var ErrNotFound = errors.New("not found in the organization")
+
+// OwnedTable is a table whose rows belong to a single organization, its rows are only ever looked up along with the
+// organization of the member asking for them
+type OwnedTable struct {
+	Name           string
+	table          Table
+	uniqueId       ColumnString
+	organizationId ColumnString
+	allColumns     ColumnList
+}
+
+var (
+	AiChat                  = OwnedTable{"Chat", table.AiChat, table.AiChat.UniqueId, table.AiChat.OrganizationId, table.AiChat.AllColumns}
+	Campaign                = OwnedTable{"Campaign", table.Campaign, table.Campaign.UniqueId, table.Campaign.OrganizationId, table.Campaign.AllColumns}
+	CannedResponse          = OwnedTable{"Canned response", table.CannedResponse, table.CannedResponse.UniqueId, table.CannedResponse.OrganizationId, table.CannedResponse.AllColumns}
+	Contact                 = OwnedTable{"Contact", table.Contact, table.Contact.UniqueId, table.Contact.OrganizationId, table.Contact.AllColumns}
+	ContactCaptureSource    = OwnedTable{"Contact capture source", table.ContactCaptureSource, table.ContactCaptureSource.UniqueId, table.ContactCaptureSource.OrganizationId, table.ContactCaptureSource.AllColumns}
+	ContactField            = OwnedTable{"Contact field", table.ContactField, table.ContactField.UniqueId, table.ContactField.OrganizationId, table.ContactField.AllColumns}
+	ContactList             = OwnedTable{"Contact list", table.ContactList, table.ContactList.UniqueId, table.ContactList.OrganizationId, table.ContactList.AllColumns}
+	Conversation            = OwnedTable{"Conversation", table.Conversation, table.Conversation.UniqueId, table.Conversation.OrganizationId, table.Conversation.AllColumns}
+	ConversationRoutingRule = OwnedTable{"Routing rule", table.ConversationRoutingRule, table.ConversationRoutingRule.UniqueId, table.ConversationRoutingRule.OrganizationId, table.ConversationRoutingRule.AllColumns}
+	OrganizationMember      = OwnedTable{"Organization member", table.OrganizationMember, table.OrganizationMember.UniqueId, table.OrganizationMember.OrganizationId, table.OrganizationMember.AllColumns}
+	OrganizationRole        = OwnedTable{"Role", table.OrganizationRole, table.OrganizationRole.UniqueId, table.OrganizationRole.OrganizationId, table.OrganizationRole.AllColumns}
+	Segment                 = OwnedTable{"Segment", table.Segment, table.Segment.UniqueId, table.Segment.OrganizationId, table.Segment.AllColumns}
+	SlaPolicy               = OwnedTable{"SLA policy", table.SlaPolicy, table.SlaPolicy.UniqueId, table.SlaPolicy.OrganizationId, table.SlaPolicy.AllColumns}
+	Tag                     = OwnedTable{"Tag", table.Tag, table.Tag.UniqueId, table.Tag.OrganizationId, table.Tag.AllColumns}
+)
+
+// Scope matches the rows of the organization, every query reading rows of the table by id must be scoped with it
+func (t OwnedTable) Scope(organizationId uuid.UUID) BoolExpression {
+	return t.organizationId.EQ(UUID(organizationId))
+}
+
+// ById matches the row of the id, only when it belongs to the organization
+func (t OwnedTable) ById(organizationId, id uuid.UUID) BoolExpression {
+	return t.uniqueId.EQ(UUID(id)).AND(t.Scope(organizationId))
+}
+
+// Fetch loads the row of the id into dest, ErrNotFound is returned when there is no such row in the organization
+func (t OwnedTable) Fetch(ctx context.Context, db qrm.Queryable, organizationId, id uuid.UUID, dest interface{}) error {
+	err := SELECT(t.allColumns).
+		FROM(t.table).
+		WHERE(t.ById(organizationId, id)).
+		LIMIT(1).
+		QueryContext(ctx, db, dest)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// CheckOwned makes sure every id belongs to the organization, it is meant for the ids of other rows a payload links to, like
+// the tags of a campaign. ErrNotFound is returned when any of the ids is not a row of the organization.
+func (t OwnedTable) CheckOwned(ctx context.Context, db qrm.Queryable, organizationId uuid.UUID, ids ...uuid.UUID) error {
+	uniqueIds := make(map[uuid.UUID]bool, len(ids))
+	idExpressions := make([]Expression, 0, len(ids))
+
+	for _, id := range ids {
+		if uniqueIds[id] {
+			continue
+		}
+		uniqueIds[id] = true
+		idExpressions = append(idExpressions, UUID(id))
+	}
+
+	if len(idExpressions) == 0 {
+		return nil
+	}
+
+	var dest struct {
+		Count int
+	}
+
+	err := SELECT(COUNT(t.uniqueId).AS("count")).
+		FROM(t.table).
+		WHERE(t.uniqueId.IN(idExpressions...).AND(t.Scope(organizationId))).
+		QueryContext(ctx, db, &dest)
+
+	if err != nil {
+		return err
+	}
+
+	if dest.Count != len(idExpressions) {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package two_factor_service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	cache "github.com/wapikit/wapikit/internal/core/redis"
+	"github.com/wapikit/wapikit/internal/core/secret_service"
+	"github.com/wapikit/wapikit/internal/core/session_service"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+const (
+	Issuer            = "WapiKit"
+	RecoveryCodeCount = 10
+	// the second step of the login must be completed within this time
+	LoginChallengeTTL = 5 * time.Minute
+	MaxLoginAttempts  = 5
+	// a totp code stays valid for one period on either side of the current one, used codes are remembered for as long
+	usedCodeTTL = 90 * time.Second
+)
+
+var (
+	ErrNotEnrolled  = errors.New("two factor authentication is not enabled")
+	recoveryCodeSet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// GenerateKey generates a new totp secret for the user, the key carries the otpauth:// url used for provisioning
+func GenerateKey(accountName string) (*otp.Key, error) {
+	return totp.Generate(totp.GenerateOpts{
+		Issuer:      Issuer,
+		AccountName: accountName,
+	})
+}
+
+// ProvisioningQrCode returns the QR code of the key as a png data url, to be scanned by an authenticator app
+func ProvisioningQrCode(key *otp.Key) (string, error) {
+	image, err := key.Image(256, 256)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, image); err != nil {
+		return "", err
+	}
+
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buffer.Bytes()), nil
+}
+
+// ValidateTotpCode checks the code against the stored secret, a code accepted once is rejected if it is replayed.
+// Codes are refused when redis is unavailable, as a replayed code could not be told apart from a fresh one.
+func ValidateTotpCode(redis *cache.RedisClient, secrets *secret_service.SecretService, userId, storedSecret, code string) bool {
+	secret, err := secrets.Decrypt(storedSecret)
+	if err != nil {
+		return false
+	}
+
+	code = strings.TrimSpace(code)
+	if !totp.Validate(code, secret) {
+		return false
+	}
+
+	if redis == nil {
+		return false
+	}
+
+	isFirstUse, err := redis.SetNX(context.Background(), redis.ComputeCacheKey("two-factor", userId, "used-code:"+code), true, usedCodeTTL).Result()
+	if err != nil {
+		return false
+	}
+
+	return isFirstUse
+}
+
+// GenerateRecoveryCodes returns a fresh set of plain recovery codes, formatted as xxxxx-xxxxx
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+
+	for i := 0; i < RecoveryCodeCount; i++ {
+		randomBytes := make([]byte, 10)
+		if _, err := rand.Read(randomBytes); err != nil {
+			return nil, err
+		}
+
+		var code strings.Builder
+		for index, randomByte := range randomBytes {
+			if index == 5 {
+				code.WriteByte('-')
+			}
+			code.WriteByte(recoveryCodeSet[int(randomByte)%len(recoveryCodeSet)])
+		}
+		codes = append(codes, code.String())
+	}
+
+	return codes, nil
+}
+
+// ReplaceRecoveryCodes removes every existing recovery code of the user and stores the hashes of the given ones
+func ReplaceRecoveryCodes(ctx context.Context, db qrm.Executable, userId uuid.UUID, codes []string) error {
+	_, err := table.UserRecoveryCode.DELETE().
+		WHERE(table.UserRecoveryCode.UserId.EQ(UUID(userId))).
+		ExecContext(ctx, db)
+
+	if err != nil {
+		return err
+	}
+
+	if len(codes) == 0 {
+		return nil
+	}
+
+	recoveryCodes := make([]model.UserRecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		recoveryCodes = append(recoveryCodes, model.UserRecoveryCode{
+			UserId:    userId,
+			CodeHash:  session_service.HashToken(normalizeRecoveryCode(code)),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+	}
+
+	_, err = table.UserRecoveryCode.INSERT(table.UserRecoveryCode.MutableColumns).
+		MODELS(recoveryCodes).
+		ExecContext(ctx, db)
+
+	return err
+}
+
+// ConsumeRecoveryCode marks the recovery code as used, returns false if the code does not exist or was already used
+func ConsumeRecoveryCode(ctx context.Context, db *sql.DB, userId uuid.UUID, code string) (bool, error) {
+	var usedCodes []model.UserRecoveryCode
+
+	err := table.UserRecoveryCode.UPDATE(table.UserRecoveryCode.UsedAt, table.UserRecoveryCode.UpdatedAt).
+		SET(TimestampzT(time.Now()), TimestampzT(time.Now())).
+		WHERE(
+			table.UserRecoveryCode.UserId.EQ(UUID(userId)).
+				AND(table.UserRecoveryCode.CodeHash.EQ(String(session_service.HashToken(normalizeRecoveryCode(code))))).
+				AND(table.UserRecoveryCode.UsedAt.IS_NULL()),
+		).
+		RETURNING(table.UserRecoveryCode.AllColumns).
+		QueryContext(ctx, db, &usedCodes)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return len(usedCodes) > 0, nil
+}
+
+// VerifyCode accepts either a totp code from the authenticator app or one of the recovery codes of the user
+func VerifyCode(ctx context.Context, db *sql.DB, redis *cache.RedisClient, secrets *secret_service.SecretService, user model.User, code string) (bool, error) {
+	if !IsEnabled(user) {
+		return false, ErrNotEnrolled
+	}
+
+	if ValidateTotpCode(redis, secrets, user.UniqueId.String(), *user.TwoFactorSecret, code) {
+		return true, nil
+	}
+
+	return ConsumeRecoveryCode(ctx, db, user.UniqueId, code)
+}
+
+func IsEnabled(user model.User) bool {
+	return user.TwoFactorEnabledAt != nil && user.TwoFactorSecret != nil
+}
+
+// IsRequired reports whether the organization policy requires the member to use two factor authentication
+func IsRequired(organization model.Organization, accessLevel model.UserPermissionLevelEnum, roles []model.OrganizationRole) bool {
+	if accessLevel == model.UserPermissionLevelEnum_Owner && organization.IsTwoFactorRequiredForOwners {
+		return true
+	}
+
+	for _, role := range roles {
+		if role.IsTwoFactorRequired {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsRequiredForMember is IsRequired for callers which have not already loaded the roles assigned to the member
+func IsRequiredForMember(ctx context.Context, db *sql.DB, organization model.Organization, member model.OrganizationMember) (bool, error) {
+	if member.AccessLevel == model.UserPermissionLevelEnum_Owner && organization.IsTwoFactorRequiredForOwners {
+		return true, nil
+	}
+
+	var roles []model.OrganizationRole
+
+	err := SELECT(table.OrganizationRole.AllColumns).
+		FROM(table.OrganizationRole.
+			INNER_JOIN(table.RoleAssignment, table.RoleAssignment.OrganizationRoleId.EQ(table.OrganizationRole.UniqueId))).
+		WHERE(table.RoleAssignment.OrganizationMemberId.EQ(UUID(member.UniqueId))).
+		QueryContext(ctx, db, &roles)
+
+	if err != nil && err.Error() != qrm.ErrNoRows.Error() {
+		return false, err
+	}
+
+	return IsRequired(organization, member.AccessLevel, roles), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+}
@@ -0,0 +1,65 @@
+package two_factor_service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pquerna/otp/totp"
+	"github.com/wapikit/wapikit/internal/testutil"
+)
+
+func TestTotpCodesAreRefusedWithoutRedis(t *testing.T) {
+	app := testutil.NewApp(t)
+
+	key, err := GenerateKey("user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storedSecret, err := app.Secrets.Encrypt(key.Secret())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := totp.GenerateCode(key.Secret(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ValidateTotpCode(nil, app.Secrets, uuid.NewString(), storedSecret, code) {
+		t.Fatal("expected the code to be refused without redis")
+	}
+}
+
+func TestTotpCodesOfTheEncryptedSecretCanOnlyBeUsedOnce(t *testing.T) {
+	app := testutil.NewApp(t)
+	userId := uuid.NewString()
+
+	key, err := GenerateKey("user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storedSecret, err := app.Secrets.Encrypt(key.Secret())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if storedSecret == key.Secret() {
+		t.Fatal("expected the secret to be encrypted")
+	}
+
+	code, err := totp.GenerateCode(key.Secret(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ValidateTotpCode(app.Redis, app.Secrets, userId, storedSecret, code) {
+		t.Fatal("expected the code to be accepted")
+	}
+
+	if ValidateTotpCode(app.Redis, app.Secrets, userId, storedSecret, code) {
+		t.Fatal("expected the replayed code to be refused")
+	}
+}
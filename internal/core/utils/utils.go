@@ -6,6 +6,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	mathRandom "math/rand"
@@ -98,6 +99,27 @@ func ParsePhoneNumber(phoneNumber string) (*phonenumbers.PhoneNumber, error) {
 	return &parsedPhoneNumber, err
 }
 
+// NormalizePhoneNumber returns the number in E.164 without the leading +, the way whatsapp identifies contacts in webhooks.
+// defaultRegion is the ISO 3166-1 alpha-2 code of the country assumed for numbers written without a country code, numbers
+// which are not valid in that country are tried once more as international numbers missing their +.
+func NormalizePhoneNumber(phoneNumber, defaultRegion string) (string, error) {
+	parsedPhoneNumber, err := phonenumbers.Parse(phoneNumber, strings.ToUpper(defaultRegion))
+
+	if (err != nil || !phonenumbers.IsValidNumber(parsedPhoneNumber)) && !strings.HasPrefix(strings.TrimSpace(phoneNumber), "+") {
+		parsedPhoneNumber, err = phonenumbers.Parse("+"+strings.TrimSpace(phoneNumber), "")
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if !phonenumbers.IsValidNumber(parsedPhoneNumber) {
+		return "", errors.New("invalid phone number")
+	}
+
+	return strings.TrimPrefix(phonenumbers.Format(parsedPhoneNumber, phonenumbers.E164), "+"), nil
+}
+
 func EnumExpression(value string) StringExpression {
 	return RawString(strings.Join([]string{"'", value, "'"}, ""))
 }
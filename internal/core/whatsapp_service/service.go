@@ -0,0 +1,227 @@
+package whatsapp_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+const (
+	graphApiBaseUrl = "https://graph.facebook.com/v20.0"
+	// tokens expiring within this window are reported as expiring, so the owners get time to replace them
+	ExpiryWarningWindow = 7 * 24 * time.Hour
+)
+
+// RequiredScopes are the permissions the access token needs for wapikit to manage templates and send messages
+var RequiredScopes = []string{"whatsapp_business_management", "whatsapp_business_messaging"}
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+type graphApiError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    int    `json:"code"`
+}
+
+type debugTokenResponse struct {
+	Data struct {
+		IsValid        bool     `json:"is_valid"`
+		ExpiresAt      int64    `json:"expires_at"`
+		Scopes         []string `json:"scopes"`
+		GranularScopes []struct {
+			Scope     string   `json:"scope"`
+			TargetIds []string `json:"target_ids"`
+		} `json:"granular_scopes"`
+		Error *graphApiError `json:"error"`
+	} `json:"data"`
+	Error *graphApiError `json:"error"`
+}
+
+// HealthReport is the result of checking the credentials of a business account against the graph api
+type HealthReport struct {
+	Status         model.WhatsappBusinessAccountHealthStatusEnum
+	TokenExpiresAt *time.Time
+	Scopes         []string
+	// reason the credentials are not healthy, empty when they are
+	Error string
+}
+
+func (report HealthReport) IsValid() bool {
+	return report.Status == model.WhatsappBusinessAccountHealthStatusEnum_Healthy ||
+		report.Status == model.WhatsappBusinessAccountHealthStatusEnum_Expiring
+}
+
+// CheckCredentials inspects the access token and verifies it has the required permissions on the business account.
+// An error is only returned when the graph api could not be reached, invalid credentials are reported through the status.
+func CheckCredentials(ctx context.Context, businessAccountId, accessToken string) (*HealthReport, error) {
+	query := url.Values{}
+	query.Set("input_token", accessToken)
+	query.Set("access_token", accessToken)
+
+	var response debugTokenResponse
+	if err := graphApiRequest(ctx, http.MethodGet, "/debug_token?"+query.Encode(), accessToken, &response); err != nil {
+		var apiError *graphApiError
+		if errors.As(err, &apiError) {
+			return invalidReport(apiError.Message), nil
+		}
+		return nil, err
+	}
+
+	if !response.Data.IsValid {
+		if response.Data.Error != nil {
+			return invalidReport(response.Data.Error.Message), nil
+		}
+		return invalidReport("access token is not valid"), nil
+	}
+
+	report := &HealthReport{
+		Status: model.WhatsappBusinessAccountHealthStatusEnum_Healthy,
+		Scopes: response.Data.Scopes,
+	}
+
+	if response.Data.ExpiresAt > 0 {
+		expiresAt := time.Unix(response.Data.ExpiresAt, 0)
+		report.TokenExpiresAt = &expiresAt
+	}
+
+	for _, scope := range RequiredScopes {
+		if !slices.Contains(report.Scopes, scope) {
+			report.Status = model.WhatsappBusinessAccountHealthStatusEnum_Invalid
+			report.Error = fmt.Sprintf("access token is missing the %s permission", scope)
+			return report, nil
+		}
+	}
+
+	// * granular scopes list the business accounts the token was granted access to, they are absent for tokens with access to every asset
+	for _, granularScope := range response.Data.GranularScopes {
+		if granularScope.Scope != "whatsapp_business_management" || len(granularScope.TargetIds) == 0 {
+			continue
+		}
+		if !slices.Contains(granularScope.TargetIds, businessAccountId) {
+			report.Status = model.WhatsappBusinessAccountHealthStatusEnum_Invalid
+			report.Error = "access token has not been granted access to this business account"
+			return report, nil
+		}
+	}
+
+	if report.TokenExpiresAt != nil && time.Until(*report.TokenExpiresAt) < ExpiryWarningWindow {
+		report.Status = model.WhatsappBusinessAccountHealthStatusEnum_Expiring
+		report.Error = fmt.Sprintf("access token expires on %s", report.TokenExpiresAt.Format(time.RFC1123))
+	}
+
+	return report, nil
+}
+
+// SubscribeApp subscribes the app owning the access token to the webhooks of the business account
+func SubscribeApp(ctx context.Context, businessAccountId, accessToken string) error {
+	var response struct {
+		Success bool `json:"success"`
+	}
+
+	if err := graphApiRequest(ctx, http.MethodPost, "/"+url.PathEscape(businessAccountId)+"/subscribed_apps", accessToken, &response); err != nil {
+		return err
+	}
+
+	if !response.Success {
+		return errors.New("graph api did not confirm the webhook subscription")
+	}
+
+	return nil
+}
+
+// SaveHealthReport stores the result of a credentials check on the business account record
+func SaveHealthReport(ctx context.Context, db qrm.Queryable, businessAccountUniqueId uuid.UUID, report HealthReport) (*model.WhatsappBusinessAccount, error) {
+	var healthError *string
+	if report.Error != "" {
+		healthError = &report.Error
+	}
+
+	var tokenScopes *string
+	if len(report.Scopes) > 0 {
+		scopes := strings.Join(report.Scopes, ",")
+		tokenScopes = &scopes
+	}
+
+	checkedAt := time.Now()
+
+	var updatedBusinessAccount model.WhatsappBusinessAccount
+
+	err := table.WhatsappBusinessAccount.UPDATE(
+		table.WhatsappBusinessAccount.HealthStatus,
+		table.WhatsappBusinessAccount.HealthError,
+		table.WhatsappBusinessAccount.HealthCheckedAt,
+		table.WhatsappBusinessAccount.TokenExpiresAt,
+		table.WhatsappBusinessAccount.TokenScopes,
+		table.WhatsappBusinessAccount.UpdatedAt,
+	).
+		MODEL(model.WhatsappBusinessAccount{
+			HealthStatus:    report.Status,
+			HealthError:     healthError,
+			HealthCheckedAt: &checkedAt,
+			TokenExpiresAt:  report.TokenExpiresAt,
+			TokenScopes:     tokenScopes,
+			UpdatedAt:       checkedAt,
+		}).
+		WHERE(table.WhatsappBusinessAccount.UniqueId.EQ(UUID(businessAccountUniqueId))).
+		RETURNING(table.WhatsappBusinessAccount.AllColumns).
+		QueryContext(ctx, db, &updatedBusinessAccount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &updatedBusinessAccount, nil
+}
+
+func (err *graphApiError) Error() string {
+	return err.Message
+}
+
+func invalidReport(reason string) *HealthReport {
+	return &HealthReport{
+		Status: model.WhatsappBusinessAccountHealthStatusEnum_Invalid,
+		Error:  reason,
+	}
+}
+
+func graphApiRequest(ctx context.Context, method, path, accessToken string, response interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, method, graphApiBaseUrl+path, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	httpResponse, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("graph api request failed with status %d", httpResponse.StatusCode)
+	}
+
+	if httpResponse.StatusCode >= http.StatusBadRequest {
+		var errorResponse struct {
+			Error *graphApiError `json:"error"`
+		}
+		if err := json.NewDecoder(httpResponse.Body).Decode(&errorResponse); err != nil || errorResponse.Error == nil {
+			return fmt.Errorf("graph api request failed with status %d", httpResponse.StatusCode)
+		}
+		return errorResponse.Error
+	}
+
+	return json.NewDecoder(httpResponse.Body).Decode(response)
+}
@@ -0,0 +1,20 @@
+-- Create "UserSession" table
+CREATE TABLE "public"."UserSession" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "UserId" uuid NOT NULL,
+  "OrganizationId" uuid NULL,
+  "RefreshTokenHash" text NOT NULL,
+  "UserAgent" text NULL,
+  "IpAddress" text NULL,
+  "LastUsedAt" timestamptz NOT NULL,
+  "ExpiresAt" timestamptz NOT NULL,
+  "RevokedAt" timestamptz NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "UserSessionToUserForeignKey" FOREIGN KEY ("UserId") REFERENCES "public"."User" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "UserSessionRefreshTokenHashIndex" to table: "UserSession"
+CREATE UNIQUE INDEX "UserSessionRefreshTokenHashIndex" ON "public"."UserSession" ("RefreshTokenHash");
+-- Create index "UserSessionUserIdIndex" to table: "UserSession"
+CREATE INDEX "UserSessionUserIdIndex" ON "public"."UserSession" ("UserId");
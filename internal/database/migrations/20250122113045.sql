@@ -0,0 +1,18 @@
+-- Add value to enum type: "OauthProviderEnum"
+ALTER TYPE "public"."OauthProviderEnum" ADD VALUE 'Oidc';
+-- Create "UserOauthAccount" table
+CREATE TABLE "public"."UserOauthAccount" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "UserId" uuid NOT NULL,
+  "Provider" text NOT NULL,
+  "Subject" text NOT NULL,
+  "Email" text NOT NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "UserOauthAccountToUserForeignKey" FOREIGN KEY ("UserId") REFERENCES "public"."User" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "UserOauthAccountProviderSubjectIndex" to table: "UserOauthAccount"
+CREATE UNIQUE INDEX "UserOauthAccountProviderSubjectIndex" ON "public"."UserOauthAccount" ("Provider", "Subject");
+-- Create index "UserOauthAccountUserIdIndex" to table: "UserOauthAccount"
+CREATE INDEX "UserOauthAccountUserIdIndex" ON "public"."UserOauthAccount" ("UserId");
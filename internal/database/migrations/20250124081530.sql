@@ -0,0 +1,19 @@
+-- Modify "Organization" table
+ALTER TABLE "public"."Organization" ADD COLUMN "IsTwoFactorRequiredForOwners" boolean NOT NULL DEFAULT false;
+-- Modify "OrganizationRole" table
+ALTER TABLE "public"."OrganizationRole" ADD COLUMN "IsTwoFactorRequired" boolean NOT NULL DEFAULT false;
+-- Modify "User" table
+ALTER TABLE "public"."User" ADD COLUMN "TwoFactorSecret" text NULL, ADD COLUMN "TwoFactorEnabledAt" timestamptz NULL;
+-- Create "UserRecoveryCode" table
+CREATE TABLE "public"."UserRecoveryCode" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "UserId" uuid NOT NULL,
+  "CodeHash" text NOT NULL,
+  "UsedAt" timestamptz NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "UserRecoveryCodeToUserForeignKey" FOREIGN KEY ("UserId") REFERENCES "public"."User" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "UserRecoveryCodeUserIdIndex" to table: "UserRecoveryCode"
+CREATE INDEX "UserRecoveryCodeUserIdIndex" ON "public"."UserRecoveryCode" ("UserId");
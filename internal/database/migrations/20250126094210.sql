@@ -0,0 +1,4 @@
+-- Create enum type "WhatsappBusinessAccountHealthStatusEnum"
+CREATE TYPE "public"."WhatsappBusinessAccountHealthStatusEnum" AS ENUM ('Unknown', 'Healthy', 'Expiring', 'Invalid');
+-- Modify "WhatsappBusinessAccount" table
+ALTER TABLE "public"."WhatsappBusinessAccount" ADD COLUMN "TokenExpiresAt" timestamptz NULL, ADD COLUMN "TokenScopes" text NULL, ADD COLUMN "HealthStatus" "public"."WhatsappBusinessAccountHealthStatusEnum" NOT NULL DEFAULT 'Unknown', ADD COLUMN "HealthCheckedAt" timestamptz NULL, ADD COLUMN "HealthError" text NULL;
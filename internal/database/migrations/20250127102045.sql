@@ -0,0 +1,34 @@
+-- Create enum type "ConversationRoutingStrategyEnum"
+CREATE TYPE "public"."ConversationRoutingStrategyEnum" AS ENUM ('RoundRobin', 'LeastBusy');
+-- Modify "OrganizationMember" table
+ALTER TABLE "public"."OrganizationMember" ADD COLUMN "IsAvailable" boolean NOT NULL DEFAULT true;
+-- Create "ConversationRoutingRule" table
+CREATE TABLE "public"."ConversationRoutingRule" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "OrganizationId" uuid NOT NULL,
+  "Name" text NOT NULL,
+  "Strategy" "public"."ConversationRoutingStrategyEnum" NOT NULL,
+  "Priority" integer NOT NULL DEFAULT 0,
+  "IsEnabled" boolean NOT NULL DEFAULT true,
+  "PhoneNumberId" text NULL,
+  "TagId" uuid NULL,
+  "MaxConcurrentConversations" integer NULL,
+  "LastAssignedOrganizationMemberId" uuid NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "ConversationRoutingRuleToOrganizationForeignKey" FOREIGN KEY ("OrganizationId") REFERENCES "public"."Organization" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "ConversationRoutingRuleToTagForeignKey" FOREIGN KEY ("TagId") REFERENCES "public"."Tag" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "ConversationRoutingRuleOrganizationIdIndex" to table: "ConversationRoutingRule"
+CREATE INDEX "ConversationRoutingRuleOrganizationIdIndex" ON "public"."ConversationRoutingRule" ("OrganizationId");
+-- Create "ConversationRoutingRuleMember" table
+CREATE TABLE "public"."ConversationRoutingRuleMember" (
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "RoutingRuleId" uuid NOT NULL,
+  "OrganizationMemberId" uuid NOT NULL,
+  PRIMARY KEY ("RoutingRuleId", "OrganizationMemberId"),
+  CONSTRAINT "ConversationRoutingRuleMemberToOrgMemberForeignKey" FOREIGN KEY ("OrganizationMemberId") REFERENCES "public"."OrganizationMember" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "ConversationRoutingRuleMemberToRoutingRuleForeignKey" FOREIGN KEY ("RoutingRuleId") REFERENCES "public"."ConversationRoutingRule" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
@@ -0,0 +1,28 @@
+-- Create enum type "SlaEscalationActionEnum"
+CREATE TYPE "public"."SlaEscalationActionEnum" AS ENUM ('Notify', 'Reassign', 'Tag');
+-- Create "SlaPolicy" table
+CREATE TABLE "public"."SlaPolicy" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "OrganizationId" uuid NOT NULL,
+  "Name" text NOT NULL,
+  "IsDefault" boolean NOT NULL DEFAULT false,
+  "IsEnabled" boolean NOT NULL DEFAULT true,
+  "PhoneNumberId" text NULL,
+  "FirstResponseTimeInMinutes" integer NOT NULL,
+  "ResolutionTimeInMinutes" integer NULL,
+  "Timezone" text NOT NULL DEFAULT 'UTC',
+  "BusinessCalendar" jsonb NULL,
+  "EscalationAction" "public"."SlaEscalationActionEnum" NULL,
+  "EscalationTagId" uuid NULL,
+  "EscalationOrganizationMemberId" uuid NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "SlaPolicyToOrgMemberForeignKey" FOREIGN KEY ("EscalationOrganizationMemberId") REFERENCES "public"."OrganizationMember" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "SlaPolicyToOrganizationForeignKey" FOREIGN KEY ("OrganizationId") REFERENCES "public"."Organization" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "SlaPolicyToTagForeignKey" FOREIGN KEY ("EscalationTagId") REFERENCES "public"."Tag" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "SlaPolicyOrganizationIdIndex" to table: "SlaPolicy"
+CREATE INDEX "SlaPolicyOrganizationIdIndex" ON "public"."SlaPolicy" ("OrganizationId");
+-- Modify "Conversation" table
+ALTER TABLE "public"."Conversation" ADD COLUMN "SlaPolicyId" uuid NULL, ADD COLUMN "FirstResponseDueAt" timestamptz NULL, ADD COLUMN "FirstRespondedAt" timestamptz NULL, ADD COLUMN "FirstResponseBreachedAt" timestamptz NULL, ADD COLUMN "ResolutionDueAt" timestamptz NULL, ADD COLUMN "ResolvedAt" timestamptz NULL, ADD COLUMN "ResolutionBreachedAt" timestamptz NULL, ADD CONSTRAINT "ConversationToSlaPolicyForeignKey" FOREIGN KEY ("SlaPolicyId") REFERENCES "public"."SlaPolicy" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION;
@@ -0,0 +1,36 @@
+-- Add value to enum type: "OrgRolePermissionEnum"
+ALTER TYPE "public"."OrgRolePermissionEnum" ADD VALUE 'Get:CannedResponse';
+-- Add value to enum type: "OrgRolePermissionEnum"
+ALTER TYPE "public"."OrgRolePermissionEnum" ADD VALUE 'Create:CannedResponse';
+-- Add value to enum type: "OrgRolePermissionEnum"
+ALTER TYPE "public"."OrgRolePermissionEnum" ADD VALUE 'Update:CannedResponse';
+-- Add value to enum type: "OrgRolePermissionEnum"
+ALTER TYPE "public"."OrgRolePermissionEnum" ADD VALUE 'Delete:CannedResponse';
+-- Create "CannedResponse" table
+CREATE TABLE "public"."CannedResponse" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "OrganizationId" uuid NOT NULL,
+  "OrganizationMemberId" uuid NULL,
+  "Name" text NOT NULL,
+  "Shortcode" text NOT NULL,
+  "Content" text NOT NULL,
+  "Folder" text NULL,
+  "Attachments" jsonb NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "CannedResponseToOrgMemberForeignKey" FOREIGN KEY ("OrganizationMemberId") REFERENCES "public"."OrganizationMember" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "CannedResponseToOrganizationForeignKey" FOREIGN KEY ("OrganizationId") REFERENCES "public"."Organization" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "CannedResponseOrganizationIdIndex" to table: "CannedResponse"
+CREATE INDEX "CannedResponseOrganizationIdIndex" ON "public"."CannedResponse" ("OrganizationId");
+-- Create "CannedResponseTag" table
+CREATE TABLE "public"."CannedResponseTag" (
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "CannedResponseId" uuid NOT NULL,
+  "TagId" uuid NOT NULL,
+  PRIMARY KEY ("CannedResponseId", "TagId"),
+  CONSTRAINT "CannedResponseTagToCannedResponseForeignKey" FOREIGN KEY ("CannedResponseId") REFERENCES "public"."CannedResponse" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "CannedResponseTagToTagForeignKey" FOREIGN KEY ("TagId") REFERENCES "public"."Tag" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
@@ -0,0 +1,25 @@
+-- Create "ConversationNote" table
+CREATE TABLE "public"."ConversationNote" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "ConversationId" uuid NOT NULL,
+  "OrganizationId" uuid NOT NULL,
+  "AuthorOrganizationMemberId" uuid NOT NULL,
+  "Content" text NOT NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "ConversationNoteToConversationForeignKey" FOREIGN KEY ("ConversationId") REFERENCES "public"."Conversation" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "ConversationNoteToOrgMemberForeignKey" FOREIGN KEY ("AuthorOrganizationMemberId") REFERENCES "public"."OrganizationMember" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "ConversationNoteToOrganizationForeignKey" FOREIGN KEY ("OrganizationId") REFERENCES "public"."Organization" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "ConversationNoteConversationIdIndex" to table: "ConversationNote"
+CREATE INDEX "ConversationNoteConversationIdIndex" ON "public"."ConversationNote" ("ConversationId", "CreatedAt");
+-- Create "ConversationNoteMention" table
+CREATE TABLE "public"."ConversationNoteMention" (
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "ConversationNoteId" uuid NOT NULL,
+  "OrganizationMemberId" uuid NOT NULL,
+  PRIMARY KEY ("ConversationNoteId", "OrganizationMemberId"),
+  CONSTRAINT "ConversationNoteMentionToConversationNoteForeignKey" FOREIGN KEY ("ConversationNoteId") REFERENCES "public"."ConversationNote" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "ConversationNoteMentionToOrgMemberForeignKey" FOREIGN KEY ("OrganizationMemberId") REFERENCES "public"."OrganizationMember" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
@@ -0,0 +1,4 @@
+-- Create index "ContactSearchIndex" to table: "Contact"
+CREATE INDEX "ContactSearchIndex" ON "public"."Contact" USING GIN ((to_tsvector('simple'::regconfig, (("Name" || ' '::text) || "PhoneNumber"))));
+-- Create index "MessageSearchIndex" to table: "Message"
+CREATE INDEX "MessageSearchIndex" ON "public"."Message" USING GIN ((to_tsvector('simple'::regconfig, ((COALESCE(("MessageData" ->> 'text'::text), ''::text) || ' '::text) || COALESCE(("MessageData" ->> 'caption'::text), ''::text)))));
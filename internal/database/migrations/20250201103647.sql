@@ -0,0 +1,29 @@
+-- Add value to enum type: "ConversationStatusEnum"
+ALTER TYPE "public"."ConversationStatusEnum" ADD VALUE 'Snoozed';
+-- Create enum type "ConversationTimelineEventTypeEnum"
+CREATE TYPE "public"."ConversationTimelineEventTypeEnum" AS ENUM ('Resolved', 'Reopened', 'Snoozed', 'Woken', 'Closed');
+-- Create enum type "ConversationTimelineEventTriggerEnum"
+CREATE TYPE "public"."ConversationTimelineEventTriggerEnum" AS ENUM ('Member', 'Contact', 'Inactivity', 'SnoozeExpired');
+-- Modify "Organization" table
+ALTER TABLE "public"."Organization" ADD COLUMN "ConversationAutoCloseAfterMinutes" integer NULL;
+-- Modify "Conversation" table
+ALTER TABLE "public"."Conversation" ADD COLUMN "SnoozedUntil" timestamptz NULL;
+-- Create "ConversationTimelineEvent" table
+CREATE TABLE "public"."ConversationTimelineEvent" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "ConversationId" uuid NOT NULL,
+  "OrganizationId" uuid NOT NULL,
+  "EventType" "public"."ConversationTimelineEventTypeEnum" NOT NULL,
+  "Trigger" "public"."ConversationTimelineEventTriggerEnum" NOT NULL,
+  "FromStatus" "public"."ConversationStatusEnum" NOT NULL,
+  "ToStatus" "public"."ConversationStatusEnum" NOT NULL,
+  "ActorOrganizationMemberId" uuid NULL,
+  "SnoozedUntil" timestamptz NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "ConversationTimelineEventToConversationForeignKey" FOREIGN KEY ("ConversationId") REFERENCES "public"."Conversation" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "ConversationTimelineEventToOrgMemberForeignKey" FOREIGN KEY ("ActorOrganizationMemberId") REFERENCES "public"."OrganizationMember" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "ConversationTimelineEventToOrganizationForeignKey" FOREIGN KEY ("OrganizationId") REFERENCES "public"."Organization" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "ConversationTimelineEventConversationIdIndex" to table: "ConversationTimelineEvent"
+CREATE INDEX "ConversationTimelineEventConversationIdIndex" ON "public"."ConversationTimelineEvent" ("ConversationId", "CreatedAt");
@@ -0,0 +1,24 @@
+-- Modify "Organization" table
+ALTER TABLE "public"."Organization" ADD COLUMN "IsCsatEnabled" boolean NOT NULL DEFAULT false, ADD COLUMN "CsatMessage" text NULL;
+-- Create "CsatSurvey" table
+CREATE TABLE "public"."CsatSurvey" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "ConversationId" uuid NOT NULL,
+  "OrganizationId" uuid NOT NULL,
+  "OrganizationMemberId" uuid NULL,
+  "PhoneNumberUsed" text NOT NULL,
+  "WhatsAppMessageId" text NULL,
+  "Rating" integer NULL,
+  "Comment" text NULL,
+  "RespondedAt" timestamptz NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "CsatSurveyToConversationForeignKey" FOREIGN KEY ("ConversationId") REFERENCES "public"."Conversation" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "CsatSurveyToOrgMemberForeignKey" FOREIGN KEY ("OrganizationMemberId") REFERENCES "public"."OrganizationMember" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "CsatSurveyToOrganizationForeignKey" FOREIGN KEY ("OrganizationId") REFERENCES "public"."Organization" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "CsatSurveyConversationIdIndex" to table: "CsatSurvey"
+CREATE INDEX "CsatSurveyConversationIdIndex" ON "public"."CsatSurvey" ("ConversationId", "CreatedAt");
+-- Create index "CsatSurveyOrganizationIdIndex" to table: "CsatSurvey"
+CREATE INDEX "CsatSurveyOrganizationIdIndex" ON "public"."CsatSurvey" ("OrganizationId", "CreatedAt");
@@ -0,0 +1,2 @@
+-- Create index "MessageWhatsAppMessageIdIndex" to table: "Message"
+CREATE INDEX "MessageWhatsAppMessageIdIndex" ON "public"."Message" ("WhatsAppMessageId");
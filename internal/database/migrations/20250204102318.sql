@@ -0,0 +1,42 @@
+-- Create enum type "BackgroundJobTypeEnum"
+CREATE TYPE "public"."BackgroundJobTypeEnum" AS ENUM ('ContactImport');
+-- Create enum type "BackgroundJobStatusEnum"
+CREATE TYPE "public"."BackgroundJobStatusEnum" AS ENUM ('Queued', 'Running', 'Completed', 'Failed');
+-- Create "BackgroundJob" table
+CREATE TABLE "public"."BackgroundJob" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "OrganizationId" uuid NOT NULL,
+  "CreatedByOrganizationMemberId" uuid NULL,
+  "Type" "public"."BackgroundJobTypeEnum" NOT NULL,
+  "Status" "public"."BackgroundJobStatusEnum" NOT NULL,
+  "Parameters" jsonb NOT NULL,
+  "TotalItems" integer NULL,
+  "ProcessedItems" integer NOT NULL DEFAULT 0,
+  "FailedItems" integer NOT NULL DEFAULT 0,
+  "Result" jsonb NULL,
+  "Error" text NULL,
+  "StartedAt" timestamptz NULL,
+  "CompletedAt" timestamptz NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "BackgroundJobToOrgMemberForeignKey" FOREIGN KEY ("CreatedByOrganizationMemberId") REFERENCES "public"."OrganizationMember" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "BackgroundJobToOrganizationForeignKey" FOREIGN KEY ("OrganizationId") REFERENCES "public"."Organization" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "BackgroundJobOrganizationIdIndex" to table: "BackgroundJob"
+CREATE INDEX "BackgroundJobOrganizationIdIndex" ON "public"."BackgroundJob" ("OrganizationId", "CreatedAt");
+-- Create index "BackgroundJobStatusIndex" to table: "BackgroundJob"
+CREATE INDEX "BackgroundJobStatusIndex" ON "public"."BackgroundJob" ("Status", "CreatedAt");
+-- Create "BackgroundJobItemError" table
+CREATE TABLE "public"."BackgroundJobItemError" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "BackgroundJobId" uuid NOT NULL,
+  "ItemNumber" integer NOT NULL,
+  "Item" text NULL,
+  "Error" text NOT NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "BackgroundJobItemErrorToBackgroundJobForeignKey" FOREIGN KEY ("BackgroundJobId") REFERENCES "public"."BackgroundJob" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "BackgroundJobItemErrorJobIdIndex" to table: "BackgroundJobItemError"
+CREATE INDEX "BackgroundJobItemErrorJobIdIndex" ON "public"."BackgroundJobItemError" ("BackgroundJobId", "ItemNumber");
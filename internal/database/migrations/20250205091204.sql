@@ -0,0 +1,26 @@
+-- Create "Segment" table
+CREATE TABLE "public"."Segment" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "OrganizationId" uuid NOT NULL,
+  "Name" text NOT NULL,
+  "Description" text NULL,
+  "Rules" jsonb NOT NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "SegmentToOrganizationForeignKey" FOREIGN KEY ("OrganizationId") REFERENCES "public"."Organization" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "SegmentOrganizationIdIndex" to table: "Segment"
+CREATE INDEX "SegmentOrganizationIdIndex" ON "public"."Segment" ("OrganizationId");
+-- Create "CampaignSegment" table
+CREATE TABLE "public"."CampaignSegment" (
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "SegmentId" uuid NOT NULL,
+  "CampaignId" uuid NOT NULL,
+  PRIMARY KEY ("SegmentId", "CampaignId"),
+  CONSTRAINT "CampaignSegmentToCampaignForeignKey" FOREIGN KEY ("CampaignId") REFERENCES "public"."Campaign" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "CampaignSegmentToSegmentForeignKey" FOREIGN KEY ("SegmentId") REFERENCES "public"."Segment" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "CampaignSegmentCampaignIdIndex" to table: "CampaignSegment"
+CREATE INDEX "CampaignSegmentCampaignIdIndex" ON "public"."CampaignSegment" ("CampaignId");
@@ -0,0 +1,19 @@
+-- Create enum type "AuditLogActionEnum"
+CREATE TYPE "public"."AuditLogActionEnum" AS ENUM ('ContactsExported', 'ContactDataExported', 'ContactAnonymized', 'ContactDeleted');
+-- Create "AuditLog" table
+CREATE TABLE "public"."AuditLog" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "OrganizationId" uuid NOT NULL,
+  "OrganizationMemberId" uuid NULL,
+  "Action" "public"."AuditLogActionEnum" NOT NULL,
+  "EntityId" uuid NULL,
+  "Details" jsonb NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "AuditLogToOrgMemberForeignKey" FOREIGN KEY ("OrganizationMemberId") REFERENCES "public"."OrganizationMember" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "AuditLogToOrganizationForeignKey" FOREIGN KEY ("OrganizationId") REFERENCES "public"."Organization" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "AuditLogEntityIdIndex" to table: "AuditLog"
+CREATE INDEX "AuditLogEntityIdIndex" ON "public"."AuditLog" ("EntityId");
+-- Create index "AuditLogOrganizationIdIndex" to table: "AuditLog"
+CREATE INDEX "AuditLogOrganizationIdIndex" ON "public"."AuditLog" ("OrganizationId", "CreatedAt");
@@ -0,0 +1,21 @@
+-- Create enum type "ContactFieldTypeEnum"
+CREATE TYPE "public"."ContactFieldTypeEnum" AS ENUM ('String', 'Number', 'Date', 'Boolean', 'Enum');
+-- Create index "ContactAttributesIndex" to table: "Contact"
+CREATE INDEX "ContactAttributesIndex" ON "public"."Contact" USING GIN ("Attributes");
+-- Create "ContactField" table
+CREATE TABLE "public"."ContactField" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "OrganizationId" uuid NOT NULL,
+  "Key" text NOT NULL,
+  "Label" text NOT NULL,
+  "Type" "public"."ContactFieldTypeEnum" NOT NULL,
+  "IsRequired" boolean NOT NULL DEFAULT false,
+  "DefaultValue" jsonb NULL,
+  "Options" jsonb NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "ContactFieldToOrganizationForeignKey" FOREIGN KEY ("OrganizationId") REFERENCES "public"."Organization" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "ContactFieldOrganizationIdKeyUniqueIndex" to table: "ContactField"
+CREATE UNIQUE INDEX "ContactFieldOrganizationIdKeyUniqueIndex" ON "public"."ContactField" ("OrganizationId", "Key");
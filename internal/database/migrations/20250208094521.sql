@@ -0,0 +1,4 @@
+-- Add value to enum type: "BackgroundJobTypeEnum"
+ALTER TYPE "public"."BackgroundJobTypeEnum" ADD VALUE 'ContactDuplicateScan';
+-- Add value to enum type: "AuditLogActionEnum"
+ALTER TYPE "public"."AuditLogActionEnum" ADD VALUE 'ContactsMerged';
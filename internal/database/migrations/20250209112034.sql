@@ -0,0 +1,18 @@
+-- Create enum type "ContactActivityTypeEnum"
+CREATE TYPE "public"."ContactActivityTypeEnum" AS ENUM ('AttributesChanged', 'StatusChanged', 'ListJoined', 'ListLeft', 'Merged');
+-- Create "ContactActivity" table
+CREATE TABLE "public"."ContactActivity" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "OrganizationId" uuid NOT NULL,
+  "ContactId" uuid NOT NULL,
+  "Type" "public"."ContactActivityTypeEnum" NOT NULL,
+  "OrganizationMemberId" uuid NULL,
+  "Data" jsonb NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "ContactActivityToContactForeignKey" FOREIGN KEY ("ContactId") REFERENCES "public"."Contact" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "ContactActivityToOrgMemberForeignKey" FOREIGN KEY ("OrganizationMemberId") REFERENCES "public"."OrganizationMember" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "ContactActivityToOrganizationForeignKey" FOREIGN KEY ("OrganizationId") REFERENCES "public"."Organization" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "ContactActivityContactIdIndex" to table: "ContactActivity"
+CREATE INDEX "ContactActivityContactIdIndex" ON "public"."ContactActivity" ("ContactId", "CreatedAt");
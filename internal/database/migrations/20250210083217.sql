@@ -0,0 +1,29 @@
+-- Add value to enum type: "ContactActivityTypeEnum"
+ALTER TYPE "public"."ContactActivityTypeEnum" ADD VALUE 'OptedIn';
+-- Create enum type "ContactCaptureSourceTypeEnum"
+CREATE TYPE "public"."ContactCaptureSourceTypeEnum" AS ENUM ('Form', 'Link');
+-- Create "ContactCaptureSource" table
+CREATE TABLE "public"."ContactCaptureSource" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "OrganizationId" uuid NOT NULL,
+  "ContactListId" uuid NOT NULL,
+  "Type" "public"."ContactCaptureSourceTypeEnum" NOT NULL,
+  "Name" text NOT NULL,
+  "Code" text NOT NULL,
+  "PhoneNumber" text NULL,
+  "PrefilledMessage" text NULL,
+  "ConsentText" text NULL,
+  "Attributes" jsonb NULL,
+  "WelcomeCannedResponseId" uuid NULL,
+  "IsActive" boolean NOT NULL DEFAULT true,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "ContactCaptureSourceToCannedResponseForeignKey" FOREIGN KEY ("WelcomeCannedResponseId") REFERENCES "public"."CannedResponse" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "ContactCaptureSourceToContactListForeignKey" FOREIGN KEY ("ContactListId") REFERENCES "public"."ContactList" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "ContactCaptureSourceToOrganizationForeignKey" FOREIGN KEY ("OrganizationId") REFERENCES "public"."Organization" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "ContactCaptureSourceCodeIndex" to table: "ContactCaptureSource"
+CREATE UNIQUE INDEX "ContactCaptureSourceCodeIndex" ON "public"."ContactCaptureSource" ("Code");
+-- Create index "ContactCaptureSourceContactListIdIndex" to table: "ContactCaptureSource"
+CREATE INDEX "ContactCaptureSourceContactListIdIndex" ON "public"."ContactCaptureSource" ("ContactListId");
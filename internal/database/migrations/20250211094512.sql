@@ -0,0 +1,30 @@
+-- Add value to enum type: "ContactActivityTypeEnum"
+ALTER TYPE "public"."ContactActivityTypeEnum" ADD VALUE 'OptedOut';
+-- Create enum type "ContactConsentCategoryEnum"
+CREATE TYPE "public"."ContactConsentCategoryEnum" AS ENUM ('Marketing', 'Utility');
+-- Create enum type "ContactConsentStatusEnum"
+CREATE TYPE "public"."ContactConsentStatusEnum" AS ENUM ('Granted', 'Revoked');
+-- Create enum type "ContactConsentSourceEnum"
+CREATE TYPE "public"."ContactConsentSourceEnum" AS ENUM ('Api', 'Import', 'Form', 'Keyword');
+-- Create "ContactConsent" table
+CREATE TABLE "public"."ContactConsent" (
+  "UniqueId" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+  "UpdatedAt" timestamptz NOT NULL,
+  "OrganizationId" uuid NOT NULL,
+  "ContactId" uuid NOT NULL,
+  "Category" "public"."ContactConsentCategoryEnum" NOT NULL,
+  "Status" "public"."ContactConsentStatusEnum" NOT NULL,
+  "Source" "public"."ContactConsentSourceEnum" NOT NULL,
+  "ChangedAt" timestamptz NOT NULL,
+  "Evidence" jsonb NULL,
+  "ContactCaptureSourceId" uuid NULL,
+  "OrganizationMemberId" uuid NULL,
+  PRIMARY KEY ("UniqueId"),
+  CONSTRAINT "ContactConsentToContactCaptureSourceForeignKey" FOREIGN KEY ("ContactCaptureSourceId") REFERENCES "public"."ContactCaptureSource" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "ContactConsentToContactForeignKey" FOREIGN KEY ("ContactId") REFERENCES "public"."Contact" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "ContactConsentToOrganizationForeignKey" FOREIGN KEY ("OrganizationId") REFERENCES "public"."Organization" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "ContactConsentToOrganizationMemberForeignKey" FOREIGN KEY ("OrganizationMemberId") REFERENCES "public"."OrganizationMember" ("UniqueId") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "ContactConsentContactIdCategoryIndex" to table: "ContactConsent"
+CREATE UNIQUE INDEX "ContactConsentContactIdCategoryIndex" ON "public"."ContactConsent" ("ContactId", "Category");
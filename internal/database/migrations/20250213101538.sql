@@ -0,0 +1,2 @@
+-- Add value to enum type: "BackgroundJobTypeEnum"
+ALTER TYPE "public"."BackgroundJobTypeEnum" ADD VALUE 'ContactListOperation';
@@ -0,0 +1,2 @@
+-- Modify "ConversationNote" table
+ALTER TABLE "public"."ConversationNote" ALTER COLUMN "AuthorOrganizationMemberId" DROP NOT NULL;
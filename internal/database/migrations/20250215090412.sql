@@ -0,0 +1,2 @@
+-- Add value to enum type: "ContactConsentStatusEnum"
+ALTER TYPE "public"."ContactConsentStatusEnum" ADD VALUE 'Pending';
@@ -0,0 +1,4 @@
+-- Add value to enum type: "AuditLogActionEnum"
+ALTER TYPE "public"."AuditLogActionEnum" ADD VALUE 'LegacyConsentsGranted';
+-- Add value to enum type: "ContactConsentSourceEnum"
+ALTER TYPE "public"."ContactConsentSourceEnum" ADD VALUE 'Legacy';
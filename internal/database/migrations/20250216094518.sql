@@ -0,0 +1,2 @@
+-- Create index "ContactAttributesPathIndex" to table: "Contact"
+CREATE INDEX "ContactAttributesPathIndex" ON "public"."Contact" USING GIN ("Attributes" jsonb_path_ops);
@@ -10,7 +10,12 @@ enum "UserAccountStatusEnum" {
 
 enum "OauthProviderEnum" {
   schema = schema.public
-  values = ["Google"]
+  values = ["Google", "Oidc"]
+}
+
+enum "WhatsappBusinessAccountHealthStatusEnum" {
+  schema = schema.public
+  values = ["Unknown", "Healthy", "Expiring", "Invalid"]
 }
 
 enum "OrganizationInviteStatusEnum" {
@@ -25,7 +30,64 @@ enum "ContactStatusEnum" {
 
 enum "ConversationStatusEnum" {
   schema = schema.public
-  values = ["Active", "Closed", "Deleted", "Resolved"]
+  values = ["Active", "Closed", "Deleted", "Resolved", "Snoozed"]
+}
+
+enum "ConversationTimelineEventTypeEnum" {
+  schema = schema.public
+  values = ["Resolved", "Reopened", "Snoozed", "Woken", "Closed"]
+}
+
+// what caused a status change of a conversation
+enum "ConversationTimelineEventTriggerEnum" {
+  schema = schema.public
+  values = ["Member", "Contact", "Inactivity", "SnoozeExpired"]
+}
+
+// work run in the background by the job manager
+enum "BackgroundJobTypeEnum" {
+  schema = schema.public
+  values = ["ContactImport", "ContactDuplicateScan", "ContactListOperation"]
+}
+
+enum "BackgroundJobStatusEnum" {
+  schema = schema.public
+  values = ["Queued", "Running", "Completed", "Failed"]
+}
+
+enum "AuditLogActionEnum" {
+  schema = schema.public
+  values = ["ContactsExported", "ContactDataExported", "ContactAnonymized", "ContactDeleted", "ContactsMerged", "LegacyConsentsGranted"]
+}
+
+enum "ContactActivityTypeEnum" {
+  schema = schema.public
+  values = ["AttributesChanged", "StatusChanged", "ListJoined", "ListLeft", "Merged", "OptedIn", "OptedOut"]
+}
+
+enum "ContactCaptureSourceTypeEnum" {
+  schema = schema.public
+  values = ["Form", "Link"]
+}
+
+enum "ContactConsentCategoryEnum" {
+  schema = schema.public
+  values = ["Marketing", "Utility"]
+}
+
+enum "ContactConsentStatusEnum" {
+  schema = schema.public
+  values = ["Granted", "Revoked", "Pending"]
+}
+
+enum "ContactConsentSourceEnum" {
+  schema = schema.public
+  values = ["Api", "Import", "Form", "Keyword", "Legacy"]
+}
+
+enum "ContactFieldTypeEnum" {
+  schema = schema.public
+  values = ["String", "Number", "Date", "Boolean", "Enum"]
 }
 
 enum "MessageDirectionEnum" {
@@ -48,6 +110,16 @@ enum "ConversationAssignmentStatus" {
   values = ["Assigned", "Unassigned"]
 }
 
+enum "ConversationRoutingStrategyEnum" {
+  schema = schema.public
+  values = ["RoundRobin", "LeastBusy"]
+}
+
+enum "SlaEscalationActionEnum" {
+  schema = schema.public
+  values = ["Notify", "Reassign", "Tag"]
+}
+
 enum "CampaignStatusEnum" {
   schema = schema.public
   values = ["Draft", "Running", "Finished", "Paused", "Cancelled", "Scheduled"]
@@ -106,7 +178,11 @@ enum "OrgRolePermissionEnum" {
     "Delete:OrganizationRole",
     "Update:IntegrationSettings",
     "Get:MessageTemplates",
-    "Get:PhoneNumbers"
+    "Get:PhoneNumbers",
+    "Get:CannedResponse",
+    "Create:CannedResponse",
+    "Update:CannedResponse",
+    "Delete:CannedResponse"
   ]
 }
 
@@ -209,6 +285,17 @@ table "User" {
     null = false
   }
 
+  // set while enrolling, two factor authentication is only enforced once TwoFactorEnabledAt is set
+  column "TwoFactorSecret" {
+    type = text
+    null = true
+  }
+
+  column "TwoFactorEnabledAt" {
+    type = timestamptz
+    null = true
+  }
+
   primary_key {
     columns = [column.UniqueId]
   }
@@ -310,6 +397,31 @@ table "Organization" {
     null = false
   }
 
+  column "IsTwoFactorRequiredForOwners" {
+    type    = boolean
+    default = false
+    null    = false
+  }
+
+  // active and resolved conversations without any activity for this long are closed, null never closes them
+  column "ConversationAutoCloseAfterMinutes" {
+    type = integer
+    null = true
+  }
+
+  // a satisfaction survey is sent to the contact whenever a member resolves a conversation
+  column "IsCsatEnabled" {
+    type    = boolean
+    default = false
+    null    = false
+  }
+
+  // the question sent with the ratings, null uses the default question
+  column "CsatMessage" {
+    type = text
+    null = true
+  }
+
   primary_key {
     columns = [column.UniqueId]
   }
@@ -352,6 +464,13 @@ table "OrganizationMember" {
     null = true
   }
 
+  // members who are not available are skipped by the conversation routing rules
+  column "IsAvailable" {
+    type    = boolean
+    null    = false
+    default = true
+  }
+
   primary_key {
     columns = [column.UniqueId]
   }
@@ -508,6 +627,13 @@ table "OrganizationRole" {
     null = false
   }
 
+  // members with this role must enroll in two factor authentication before accessing sensitive routes
+  column "IsTwoFactorRequired" {
+    type    = boolean
+    default = false
+    null    = false
+  }
+
   primary_key {
     columns = [column.UniqueId]
   }
@@ -651,7 +777,7 @@ table "ApiKey" {
 }
 
 
-table "WhatsappBusinessAccount" {
+table "UserOauthAccount" {
   schema = schema.public
   column "UniqueId" {
     type    = uuid
@@ -668,24 +794,25 @@ table "WhatsappBusinessAccount" {
     null = false
   }
 
-  column "AccountId" {
-    type = text
+  column "UserId" {
+    type = uuid
     null = false
   }
 
-  column "AccessToken" {
+  // name of the provider as configured in config.toml
+  column "Provider" {
     type = text
     null = false
   }
 
-  column "WebhookSecret" {
+  // subject of the user at the provider, stable across email changes
+  column "Subject" {
     type = text
     null = false
   }
 
-
-  column "OrganizationId" {
-    type = uuid
+  column "Email" {
+    type = text
     null = false
   }
 
@@ -693,24 +820,24 @@ table "WhatsappBusinessAccount" {
     columns = [column.UniqueId]
   }
 
-  foreign_key "WhatsappBusinessAccountToOrganizationForeignKey" {
-    columns     = [column.OrganizationId]
-    ref_columns = [table.Organization.column.UniqueId]
+  foreign_key "UserOauthAccountToUserForeignKey" {
+    columns     = [column.UserId]
+    ref_columns = [table.User.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  index "WhatsappBusinessAccountOrganizationIdIndex" {
-    columns = [column.OrganizationId]
+  index "UserOauthAccountUserIdIndex" {
+    columns = [column.UserId]
   }
 
-  index "WhatsappBusinessAccountAccountIdIndex" {
-    columns = [column.AccountId]
+  index "UserOauthAccountProviderSubjectIndex" {
+    columns = [column.Provider, column.Subject]
     unique  = true
   }
 }
 
-table "Contact" {
+table "UserRecoveryCode" {
   schema = schema.public
   column "UniqueId" {
     type    = uuid
@@ -726,25 +853,20 @@ table "Contact" {
     type = timestamptz
     null = false
   }
-  column "OrganizationId" {
+
+  column "UserId" {
     type = uuid
     null = false
   }
-  column "Status" {
-    type = enum.ContactStatusEnum
-    null = false
-  }
-  column "Name" {
-    type = text
-    null = false
-  }
-  column "PhoneNumber" {
+
+  // only the sha256 hash of the recovery code is stored
+  column "CodeHash" {
     type = text
     null = false
   }
 
-  column "Attributes" {
-    type = jsonb
+  column "UsedAt" {
+    type = timestamptz
     null = true
   }
 
@@ -752,27 +874,19 @@ table "Contact" {
     columns = [column.UniqueId]
   }
 
-  foreign_key "OrganizationToContactForeignKey" {
-    columns     = [column.OrganizationId]
-    ref_columns = [table.Organization.column.UniqueId]
+  foreign_key "UserRecoveryCodeToUserForeignKey" {
+    columns     = [column.UserId]
+    ref_columns = [table.User.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
-  index "ContactOrganizationIdIndex" {
-    columns = [column.OrganizationId]
-  }
-  index "ContactPhoneNumberIndex" {
-    columns = [column.PhoneNumber]
-    unique  = true
-  }
 
-  index "ContactNumberOrganizationIdUniqueIndex" {
-    columns = [column.OrganizationId, column.PhoneNumber]
-    unique  = true
+  index "UserRecoveryCodeUserIdIndex" {
+    columns = [column.UserId]
   }
 }
 
-table "ContactList" {
+table "UserSession" {
   schema = schema.public
   column "UniqueId" {
     type    = uuid
@@ -788,30 +902,69 @@ table "ContactList" {
     type = timestamptz
     null = false
   }
+
+  column "UserId" {
+    type = uuid
+    null = false
+  }
+
   column "OrganizationId" {
     type = uuid
+    null = true
+  }
+
+  column "RefreshTokenHash" {
+    type = text
     null = false
   }
-  column "Name" {
+
+  column "UserAgent" {
     type = text
+    null = true
+  }
+
+  column "IpAddress" {
+    type = text
+    null = true
+  }
+
+  column "LastUsedAt" {
+    type = timestamptz
     null = false
   }
+
+  column "ExpiresAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "RevokedAt" {
+    type = timestamptz
+    null = true
+  }
+
   primary_key {
     columns = [column.UniqueId]
   }
-  foreign_key "OrganizationToContactListForeignKey" {
-    columns     = [column.OrganizationId]
-    ref_columns = [table.Organization.column.UniqueId]
+
+  foreign_key "UserSessionToUserForeignKey" {
+    columns     = [column.UserId]
+    ref_columns = [table.User.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
-  index "ContactListOrganizationIdIndex" {
-    columns = [column.OrganizationId]
+
+  index "UserSessionUserIdIndex" {
+    columns = [column.UserId]
   }
-}
 
+  index "UserSessionRefreshTokenHashIndex" {
+    columns = [column.RefreshTokenHash]
+    unique  = true
+  }
+}
 
-table "Campaign" {
+table "WhatsappBusinessAccount" {
   schema = schema.public
   column "UniqueId" {
     type    = uuid
@@ -828,88 +981,77 @@ table "Campaign" {
     null = false
   }
 
-  column "Description" {
+  column "AccountId" {
     type = text
-    null = true
+    null = false
   }
 
-  column "Name" {
+  column "AccessToken" {
     type = text
     null = false
   }
 
-
-  column "Status" {
-    type    = enum.CampaignStatusEnum
-    null    = false
-    default = "Draft"
-  }
-
-  column "LastContactSent" {
-    type = uuid
-    null = true
+  column "WebhookSecret" {
+    type = text
+    null = false
   }
 
-  column "IsLinkTrackingEnabled" {
-    type    = boolean
-    default = false
-    null    = false
-  }
 
-  column "CreatedByOrganizationMemberId" {
+  column "OrganizationId" {
     type = uuid
     null = false
   }
 
-  column "OrganizationId" {
-    type = uuid
-    null = false
+  // null when the access token never expires, like the tokens of system users
+  column "TokenExpiresAt" {
+    type = timestamptz
+    null = true
   }
 
-  // this would be the template Id provided by whatsapp business platform only
-  column "MessageTemplateId" {
+  // comma separated permissions granted to the access token
+  column "TokenScopes" {
     type = text
     null = true
   }
 
-  column "PhoneNumber" {
-    type = text
-    null = false
+  column "HealthStatus" {
+    type    = enum.WhatsappBusinessAccountHealthStatusEnum
+    null    = false
+    default = "Unknown"
   }
 
-  column "TemplateMessageComponentParameters" {
-    type = jsonb
+  column "HealthCheckedAt" {
+    type = timestamptz
     null = true
   }
 
-  primary_key {
-    columns = [column.UniqueId]
+  column "HealthError" {
+    type = text
+    null = true
   }
 
-  foreign_key "CampaignToOrganizationMemberForeignKey" {
-    columns     = [column.CreatedByOrganizationMemberId]
-    ref_columns = [table.OrganizationMember.column.UniqueId]
-    on_delete   = NO_ACTION
-    on_update   = NO_ACTION
+  primary_key {
+    columns = [column.UniqueId]
   }
 
-  foreign_key "OrganizationToCampaignForeignKey" {
+  foreign_key "WhatsappBusinessAccountToOrganizationForeignKey" {
     columns     = [column.OrganizationId]
     ref_columns = [table.Organization.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  index "CampaignCreatedByOrganizationMemberIdIndex" {
-    columns = [column.CreatedByOrganizationMemberId]
+  index "WhatsappBusinessAccountOrganizationIdIndex" {
+    columns = [column.OrganizationId]
   }
 
-  index "CampaignMessageTemplateIndex" {
-    columns = [column.MessageTemplateId]
+  index "WhatsappBusinessAccountAccountIdIndex" {
+    columns = [column.AccountId]
+    unique  = true
   }
 }
 
-table "Conversation" {
+table "Contact" {
   schema = schema.public
   column "UniqueId" {
     type    = uuid
@@ -925,34 +1067,25 @@ table "Conversation" {
     type = timestamptz
     null = false
   }
-
-  column "ContactId" {
-    type = uuid
-    null = false
-  }
-
   column "OrganizationId" {
     type = uuid
     null = false
   }
-
   column "Status" {
-    type = enum.ConversationStatusEnum
+    type = enum.ContactStatusEnum
     null = false
   }
-
-  column "PhoneNumberUsed" {
+  column "Name" {
     type = text
     null = false
   }
-
-  column "InitiatedBy" {
-    type = enum.ConversationInitiatedEnum
+  column "PhoneNumber" {
+    type = text
     null = false
   }
 
-  column "InitiatedByCampaignId" {
-    type = uuid
+  column "Attributes" {
+    type = jsonb
     null = true
   }
 
@@ -960,30 +1093,96 @@ table "Conversation" {
     columns = [column.UniqueId]
   }
 
-  foreign_key "ConversationToContactForeignKey" {
-    columns     = [column.ContactId]
-    ref_columns = [table.Contact.column.UniqueId]
+  foreign_key "OrganizationToContactForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
+  index "ContactOrganizationIdIndex" {
+    columns = [column.OrganizationId]
+  }
+  index "ContactPhoneNumberIndex" {
+    columns = [column.PhoneNumber]
+    unique  = true
+  }
+
+  index "ContactNumberOrganizationIdUniqueIndex" {
+    columns = [column.OrganizationId, column.PhoneNumber]
+    unique  = true
+  }
 
+  // full text search over the name and phone number of contacts
+  index "ContactSearchIndex" {
+    type = GIN
+    on {
+      expr = "to_tsvector('simple'::regconfig, ((\"Name\" || ' '::text) || \"PhoneNumber\"))"
+    }
+  }
 
+  // filtering by attributes, the custom fields of the organization are kept in the attributes. This index serves the ?
+  // operator of the exists conditions of segments
+  index "ContactAttributesIndex" {
+    type    = GIN
+    columns = [column.Attributes]
+  }
 
-  foreign_key "ConversationToOrganizationForeignKey" {
+  // the equality conditions of segments match the attributes with the @> operator, which this smaller index serves
+  index "ContactAttributesPathIndex" {
+    type = GIN
+    on {
+      column = column.Attributes
+      ops    = jsonb_path_ops
+    }
+  }
+}
+
+table "ContactList" {
+  schema = schema.public
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+  column "OrganizationId" {
+    type = uuid
+    null = false
+  }
+  column "Name" {
+    type = text
+    null = false
+  }
+  primary_key {
+    columns = [column.UniqueId]
+  }
+  foreign_key "OrganizationToContactListForeignKey" {
     columns     = [column.OrganizationId]
     ref_columns = [table.Organization.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
-
-  index "ConversationContactIdIndex" {
-    columns = [column.ContactId]
+  index "ContactListOrganizationIdIndex" {
+    columns = [column.OrganizationId]
   }
-
 }
 
-table "ConversationAssignment" {
+
+table "Campaign" {
   schema = schema.public
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
   column "CreatedAt" {
     type    = timestamptz
     null    = false
@@ -994,91 +1193,116 @@ table "ConversationAssignment" {
     null = false
   }
 
-  column "ConversationId" {
+  column "Description" {
+    type = text
+    null = true
+  }
+
+  column "Name" {
+    type = text
+    null = false
+  }
+
+
+  column "Status" {
+    type    = enum.CampaignStatusEnum
+    null    = false
+    default = "Draft"
+  }
+
+  column "LastContactSent" {
+    type = uuid
+    null = true
+  }
+
+  column "IsLinkTrackingEnabled" {
+    type    = boolean
+    default = false
+    null    = false
+  }
+
+  column "CreatedByOrganizationMemberId" {
     type = uuid
     null = false
   }
 
-  column "AssignedToOrganizationMemberId" {
+  column "OrganizationId" {
     type = uuid
     null = false
   }
 
-  column "Status" {
-    type = enum.ConversationAssignmentStatus
+  // this would be the template Id provided by whatsapp business platform only
+  column "MessageTemplateId" {
+    type = text
+    null = true
+  }
+
+  column "PhoneNumber" {
+    type = text
     null = false
   }
 
+  column "TemplateMessageComponentParameters" {
+    type = jsonb
+    null = true
+  }
+
   primary_key {
-    columns = [column.ConversationId, column.AssignedToOrganizationMemberId]
+    columns = [column.UniqueId]
   }
 
-  foreign_key "ConversationAssignmentToConversationForeignKey" {
-    columns     = [column.ConversationId]
-    ref_columns = [table.Conversation.column.UniqueId]
+  foreign_key "CampaignToOrganizationMemberForeignKey" {
+    columns     = [column.CreatedByOrganizationMemberId]
+    ref_columns = [table.OrganizationMember.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  foreign_key "ConversationAssignmentToOrgMemberForeignKey" {
-    columns     = [column.AssignedToOrganizationMemberId]
-    ref_columns = [table.OrganizationMember.column.UniqueId]
+  foreign_key "OrganizationToCampaignForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  index "ConversationAssignmentConversationIdIndex" {
-    columns = [column.ConversationId]
+  index "CampaignCreatedByOrganizationMemberIdIndex" {
+    columns = [column.CreatedByOrganizationMemberId]
   }
 
-  index "ConversationAssignmentAssignedToUserIdIndex" {
-    columns = [column.AssignedToOrganizationMemberId]
+  index "CampaignMessageTemplateIndex" {
+    columns = [column.MessageTemplateId]
   }
 }
 
-table "Message" {
+table "Conversation" {
   schema = schema.public
   column "UniqueId" {
     type    = uuid
     null    = false
     default = sql("gen_random_uuid()")
   }
-
-  # this will be the message id returned  by the whatsapp business platform
-  column "WhatsAppMessageId" {
-    type = text
-    null = true
-  }
-
-  # this will be the id provided by whatsapp business platform, not the unique id of the account record as in our db
-  column "WhatsappBusinessAccountId" {
-    type = text
-    null = true
-  }
-
   column "CreatedAt" {
     type    = timestamptz
     null    = false
     default = sql("now()")
   }
-
   column "UpdatedAt" {
     type = timestamptz
     null = false
   }
 
-  column "ConversationId" {
+  column "ContactId" {
     type = uuid
-    null = true
+    null = false
   }
 
-  column "CampaignId" {
+  column "OrganizationId" {
     type = uuid
-    null = true
+    null = false
   }
 
-  column "ContactId" {
-    type = uuid
+  column "Status" {
+    type = enum.ConversationStatusEnum
     null = false
   }
 
@@ -1087,34 +1311,55 @@ table "Message" {
     null = false
   }
 
-  column "Direction" {
-    type = enum.MessageDirectionEnum
+  column "InitiatedBy" {
+    type = enum.ConversationInitiatedEnum
     null = false
   }
 
-  # this message data type will depend on the type of message, if it is a text message then it will be a text, if it is a media message then it will be a jsonb
-  column "MessageData" {
-    type = jsonb
+  column "InitiatedByCampaignId" {
+    type = uuid
     null = true
   }
 
-  column "OrganizationId" {
+  // sla timers are started when the contact opens or reopens the conversation
+  column "SlaPolicyId" {
     type = uuid
-    null = false
+    null = true
   }
 
-  column "Status" {
-    type = enum.MessageStatusEnum
-    null = false
+  column "FirstResponseDueAt" {
+    type = timestamptz
+    null = true
   }
 
-  column "MessageType" {
-    type = enum.MessageTypeEnum
-    null = false
+  column "FirstRespondedAt" {
+    type = timestamptz
+    null = true
   }
 
-  column "RepliedTo" {
-    type = uuid
+  column "FirstResponseBreachedAt" {
+    type = timestamptz
+    null = true
+  }
+
+  column "ResolutionDueAt" {
+    type = timestamptz
+    null = true
+  }
+
+  column "ResolvedAt" {
+    type = timestamptz
+    null = true
+  }
+
+  column "ResolutionBreachedAt" {
+    type = timestamptz
+    null = true
+  }
+
+  // snoozed conversations wake up at this time, or as soon as the contact writes again
+  column "SnoozedUntil" {
+    type = timestamptz
     null = true
   }
 
@@ -1122,60 +1367,37 @@ table "Message" {
     columns = [column.UniqueId]
   }
 
-  foreign_key "MessageToCampaignForeignKey" {
-    columns     = [column.CampaignId]
-    ref_columns = [table.Campaign.column.UniqueId]
+  foreign_key "ConversationToSlaPolicyForeignKey" {
+    columns     = [column.SlaPolicyId]
+    ref_columns = [table.SlaPolicy.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-
-  foreign_key "MessageToContactForeignKey" {
+  foreign_key "ConversationToContactForeignKey" {
     columns     = [column.ContactId]
     ref_columns = [table.Contact.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  foreign_key "MessageToConversationForeignKey" {
-    columns     = [column.ConversationId]
-    ref_columns = [table.Conversation.column.UniqueId]
-    on_delete   = NO_ACTION
-    on_update   = NO_ACTION
-  }
 
-  foreign_key "MessageToOrganizationForeignKey" {
+
+  foreign_key "ConversationToOrganizationForeignKey" {
     columns     = [column.OrganizationId]
     ref_columns = [table.Organization.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  foreign_key "MessageToWhatsappBusinessAccountForeignKey" {
-    columns     = [column.WhatsappBusinessAccountId]
-    ref_columns = [table.WhatsappBusinessAccount.column.AccountId]
-    on_delete   = NO_ACTION
-    on_update   = NO_ACTION
-  }
-
-  index "MessageCampaignIdIndex" {
-    columns = [column.CampaignId]
-  }
-
-  index "MessageContactIdIndex" {
+  index "ConversationContactIdIndex" {
     columns = [column.ContactId]
   }
 
-
 }
 
-table "TrackLink" {
+table "ConversationAssignment" {
   schema = schema.public
-  column "UniqueId" {
-    type    = uuid
-    null    = false
-    default = sql("gen_random_uuid()")
-  }
   column "CreatedAt" {
     type    = timestamptz
     null    = false
@@ -1186,51 +1408,51 @@ table "TrackLink" {
     null = false
   }
 
-  column "OrganizationId" {
+  column "ConversationId" {
     type = uuid
     null = false
   }
 
-  column "CampaignId" {
+  column "AssignedToOrganizationMemberId" {
     type = uuid
     null = false
   }
 
-  column "Slug" {
-    type = text
+  column "Status" {
+    type = enum.ConversationAssignmentStatus
     null = false
   }
 
-  column "DestinationUrl" {
-    type = text
-    null = true
-  }
-
   primary_key {
-    columns = [column.UniqueId]
+    columns = [column.ConversationId, column.AssignedToOrganizationMemberId]
   }
 
-  foreign_key "TrackLinkToCampaignForeignKey" {
-    columns     = [column.CampaignId]
-    ref_columns = [table.Campaign.column.UniqueId]
+  foreign_key "ConversationAssignmentToConversationForeignKey" {
+    columns     = [column.ConversationId]
+    ref_columns = [table.Conversation.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  foreign_key "TrackLinkToOrganizationForeignKey" {
-    columns     = [column.OrganizationId]
-    ref_columns = [table.Organization.column.UniqueId]
+  foreign_key "ConversationAssignmentToOrgMemberForeignKey" {
+    columns     = [column.AssignedToOrganizationMemberId]
+    ref_columns = [table.OrganizationMember.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  index "TrackLinkCampaignIdIndex" {
-    columns = [column.CampaignId]
+  index "ConversationAssignmentConversationIdIndex" {
+    columns = [column.ConversationId]
+  }
 
+  index "ConversationAssignmentAssignedToUserIdIndex" {
+    columns = [column.AssignedToOrganizationMemberId]
   }
 }
 
-table "TrackLinkClick" {
+// routing rules assign new and reopened conversations to the members of the organization.
+// rules are evaluated in the order of their priority, the first rule matching the conversation with an eligible member wins.
+table "ConversationRoutingRule" {
   schema = schema.public
   column "UniqueId" {
     type    = uuid
@@ -1247,44 +1469,123 @@ table "TrackLinkClick" {
     null = false
   }
 
-  column "TrackLinkId" {
+  column "OrganizationId" {
     type = uuid
     null = false
   }
 
-  column "ContactId" {
-    type = uuid
+  column "Name" {
+    type = text
+    null = false
+  }
+
+  column "Strategy" {
+    type = enum.ConversationRoutingStrategyEnum
     null = false
   }
 
+  column "Priority" {
+    type    = int
+    null    = false
+    default = 0
+  }
+
+  column "IsEnabled" {
+    type    = boolean
+    null    = false
+    default = true
+  }
+
+  // the rule only matches conversations on this phone number
+  column "PhoneNumberId" {
+    type = text
+    null = true
+  }
+
+  // the rule only matches conversations with contacts in a list with this tag
+  column "TagId" {
+    type = uuid
+    null = true
+  }
+
+  // members with this many active conversations are skipped
+  column "MaxConcurrentConversations" {
+    type = int
+    null = true
+  }
+
+  // used by the round robin strategy to pick the next member
+  column "LastAssignedOrganizationMemberId" {
+    type = uuid
+    null = true
+  }
+
   primary_key {
     columns = [column.UniqueId]
   }
 
-  foreign_key "TrackLinkClickToTrackLinkForeignKey" {
-    columns     = [column.TrackLinkId]
-    ref_columns = [table.TrackLink.column.UniqueId]
+  foreign_key "ConversationRoutingRuleToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  foreign_key "TrackLinkClickToContactForeignKey" {
-    columns     = [column.ContactId]
-    ref_columns = [table.Contact.column.UniqueId]
+  foreign_key "ConversationRoutingRuleToTagForeignKey" {
+    columns     = [column.TagId]
+    ref_columns = [table.Tag.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  index "TrackLinkClickTrackLinkIdIndex" {
-    columns = [column.TrackLinkId]
+  index "ConversationRoutingRuleOrganizationIdIndex" {
+    columns = [column.OrganizationId]
   }
+}
 
-  index "TrackLinkClickContactIdIndex" {
-    columns = [column.ContactId]
+// members a routing rule assigns conversations to, a rule without members assigns to every member of the organization
+table "ConversationRoutingRuleMember" {
+  schema = schema.public
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "RoutingRuleId" {
+    type = uuid
+    null = false
+  }
+
+  column "OrganizationMemberId" {
+    type = uuid
+    null = false
+  }
+
+  primary_key {
+    columns = [column.RoutingRuleId, column.OrganizationMemberId]
+  }
+
+  foreign_key "ConversationRoutingRuleMemberToRoutingRuleForeignKey" {
+    columns     = [column.RoutingRuleId]
+    ref_columns = [table.ConversationRoutingRule.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "ConversationRoutingRuleMemberToOrgMemberForeignKey" {
+    columns     = [column.OrganizationMemberId]
+    ref_columns = [table.OrganizationMember.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
   }
 }
 
-table "Tag" {
+table "SlaPolicy" {
   schema = schema.public
   column "UniqueId" {
     type    = uuid
@@ -1301,53 +1602,105 @@ table "Tag" {
     null = false
   }
 
-  column "Label" {
-    type = text
+  column "OrganizationId" {
+    type = uuid
     null = false
   }
 
-  column "Slug" {
+  column "Name" {
     type = text
     null = false
   }
 
-  column "OrganizationId" {
-    type = uuid
+  // the default policy applies to conversations on phone numbers without a policy of their own
+  column "IsDefault" {
+    type    = boolean
+    null    = false
+    default = false
+  }
+
+  column "IsEnabled" {
+    type    = boolean
+    null    = false
+    default = true
+  }
+
+  column "PhoneNumberId" {
+    type = text
+    null = true
+  }
+
+  column "FirstResponseTimeInMinutes" {
+    type = int
     null = false
   }
 
-  primary_key {
-    columns = [column.UniqueId]
+  column "ResolutionTimeInMinutes" {
+    type = int
+    null = true
   }
 
-  unique "UniqueSlug" {
-    columns = [column.Slug]
+  column "Timezone" {
+    type    = text
+    null    = false
+    default = "UTC"
   }
 
-  index "slugIndex" {
-    columns = [column.Slug]
+  // weekly business hours and holidays, the timers run around the clock when not set
+  column "BusinessCalendar" {
+    type = jsonb
+    null = true
   }
 
-  foreign_key "TagToOrganizationForeignKey" {
+  column "EscalationAction" {
+    type = enum.SlaEscalationActionEnum
+    null = true
+  }
+
+  // tag added to the conversation by the tag escalation
+  column "EscalationTagId" {
+    type = uuid
+    null = true
+  }
+
+  // member the conversation is reassigned to by the reassign escalation
+  column "EscalationOrganizationMemberId" {
+    type = uuid
+    null = true
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+  foreign_key "SlaPolicyToOrganizationForeignKey" {
     columns     = [column.OrganizationId]
     ref_columns = [table.Organization.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  index "TagOrganizationIdIndex" {
-    columns = [column.OrganizationId]
+  foreign_key "SlaPolicyToTagForeignKey" {
+    columns     = [column.EscalationTagId]
+    ref_columns = [table.Tag.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
   }
 
-  index "TagLabelOragnizationIdUniqueIndex" {
-    columns = [column.Label, column.OrganizationId]
-    unique  = true
+  foreign_key "SlaPolicyToOrgMemberForeignKey" {
+    columns     = [column.EscalationOrganizationMemberId]
+    ref_columns = [table.OrganizationMember.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "SlaPolicyOrganizationIdIndex" {
+    columns = [column.OrganizationId]
   }
 }
 
-table "Integration" {
+table "CannedResponse" {
   schema = schema.public
-
   column "UniqueId" {
     type    = uuid
     null    = false
@@ -1363,44 +1716,1303 @@ table "Integration" {
     null = false
   }
 
+  column "OrganizationId" {
+    type = uuid
+    null = false
+  }
+
+  // the response is personal to this member, it is shared with the whole organization when not set
+  column "OrganizationMemberId" {
+    type = uuid
+    null = true
+  }
+
+  column "Name" {
+    type = text
+    null = false
+  }
+
+  // typed in the inbox after a slash to pick the response, e.g. /thanks
+  column "Shortcode" {
+    type = text
+    null = false
+  }
+
+  // text of the response, may contain variables like {{contact.name}}
+  column "Content" {
+    type = text
+    null = false
+  }
+
+  column "Folder" {
+    type = text
+    null = true
+  }
+
+  // media sent along with the text of the response
+  column "Attachments" {
+    type = jsonb
+    null = true
+  }
+
   primary_key {
     columns = [column.UniqueId]
   }
+
+  foreign_key "CannedResponseToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "CannedResponseToOrgMemberForeignKey" {
+    columns     = [column.OrganizationMemberId]
+    ref_columns = [table.OrganizationMember.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "CannedResponseOrganizationIdIndex" {
+    columns = [column.OrganizationId]
+  }
 }
 
-// this stores the installed integration for a Organization
-table "OrganizationIntegration" {
+table "Message" {
   schema = schema.public
-
   column "UniqueId" {
     type    = uuid
     null    = false
     default = sql("gen_random_uuid()")
   }
+
+  # this will be the message id returned  by the whatsapp business platform
+  column "WhatsAppMessageId" {
+    type = text
+    null = true
+  }
+
+  # this will be the id provided by whatsapp business platform, not the unique id of the account record as in our db
+  column "WhatsappBusinessAccountId" {
+    type = text
+    null = true
+  }
+
   column "CreatedAt" {
     type    = timestamptz
     null    = false
     default = sql("now()")
   }
+
   column "UpdatedAt" {
     type = timestamptz
     null = false
   }
 
-  primary_key {
-    columns = [column.UniqueId]
+  column "ConversationId" {
+    type = uuid
+    null = true
+  }
+
+  column "CampaignId" {
+    type = uuid
+    null = true
+  }
+
+  column "ContactId" {
+    type = uuid
+    null = false
   }
 
+  column "PhoneNumberUsed" {
+    type = text
+    null = false
+  }
+
+  column "Direction" {
+    type = enum.MessageDirectionEnum
+    null = false
+  }
+
+  # this message data type will depend on the type of message, if it is a text message then it will be a text, if it is a media message then it will be a jsonb
+  column "MessageData" {
+    type = jsonb
+    null = true
+  }
+
+  column "OrganizationId" {
+    type = uuid
+    null = false
+  }
+
+  column "Status" {
+    type = enum.MessageStatusEnum
+    null = false
+  }
+
+  column "MessageType" {
+    type = enum.MessageTypeEnum
+    null = false
+  }
+
+  column "RepliedTo" {
+    type = uuid
+    null = true
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+  foreign_key "MessageToCampaignForeignKey" {
+    columns     = [column.CampaignId]
+    ref_columns = [table.Campaign.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+
+  foreign_key "MessageToContactForeignKey" {
+    columns     = [column.ContactId]
+    ref_columns = [table.Contact.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "MessageToConversationForeignKey" {
+    columns     = [column.ConversationId]
+    ref_columns = [table.Conversation.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "MessageToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "MessageToWhatsappBusinessAccountForeignKey" {
+    columns     = [column.WhatsappBusinessAccountId]
+    ref_columns = [table.WhatsappBusinessAccount.column.AccountId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "MessageCampaignIdIndex" {
+    columns = [column.CampaignId]
+  }
+
+  index "MessageContactIdIndex" {
+    columns = [column.ContactId]
+  }
+
+  // delivery receipts of whatsapp refer to the message by its whatsapp id
+  index "MessageWhatsAppMessageIdIndex" {
+    columns = [column.WhatsAppMessageId]
+  }
+
+  // full text search over the text of text messages and the caption of media messages
+  index "MessageSearchIndex" {
+    type = GIN
+    on {
+      expr = "to_tsvector('simple'::regconfig, ((COALESCE((\"MessageData\" ->> 'text'::text), ''::text) || ' '::text) || COALESCE((\"MessageData\" ->> 'caption'::text), ''::text)))"
+    }
+  }
+
+}
+
+// every status change of a conversation, in the order they happened
+table "ConversationTimelineEvent" {
+  schema = schema.public
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+
+  column "ConversationId" {
+    type = uuid
+    null = false
+  }
+
+  column "OrganizationId" {
+    type = uuid
+    null = false
+  }
+
+  column "EventType" {
+    type = enum.ConversationTimelineEventTypeEnum
+    null = false
+  }
+
+  column "Trigger" {
+    type = enum.ConversationTimelineEventTriggerEnum
+    null = false
+  }
+
+  column "FromStatus" {
+    type = enum.ConversationStatusEnum
+    null = false
+  }
+
+  column "ToStatus" {
+    type = enum.ConversationStatusEnum
+    null = false
+  }
+
+  // the member who changed the status, null when the contact or the system did
+  column "ActorOrganizationMemberId" {
+    type = uuid
+    null = true
+  }
+
+  column "SnoozedUntil" {
+    type = timestamptz
+    null = true
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+  foreign_key "ConversationTimelineEventToConversationForeignKey" {
+    columns     = [column.ConversationId]
+    ref_columns = [table.Conversation.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "ConversationTimelineEventToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "ConversationTimelineEventToOrgMemberForeignKey" {
+    columns     = [column.ActorOrganizationMemberId]
+    ref_columns = [table.OrganizationMember.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "ConversationTimelineEventConversationIdIndex" {
+    columns = [column.ConversationId, column.CreatedAt]
+  }
+}
+
+// satisfaction surveys sent to contacts when their conversation is resolved, and the rating they replied with
+table "CsatSurvey" {
+  schema = schema.public
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "ConversationId" {
+    type = uuid
+    null = false
+  }
+
+  column "OrganizationId" {
+    type = uuid
+    null = false
+  }
+
+  // the member the conversation was assigned to when it was resolved
+  column "OrganizationMemberId" {
+    type = uuid
+    null = true
+  }
+
+  column "PhoneNumberUsed" {
+    type = text
+    null = false
+  }
+
+  column "WhatsAppMessageId" {
+    type = text
+    null = true
+  }
+
+  // 1 to 5, null until the contact replies
+  column "Rating" {
+    type = integer
+    null = true
+  }
+
+  column "Comment" {
+    type = text
+    null = true
+  }
+
+  column "RespondedAt" {
+    type = timestamptz
+    null = true
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+  foreign_key "CsatSurveyToConversationForeignKey" {
+    columns     = [column.ConversationId]
+    ref_columns = [table.Conversation.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "CsatSurveyToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "CsatSurveyToOrgMemberForeignKey" {
+    columns     = [column.OrganizationMemberId]
+    ref_columns = [table.OrganizationMember.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "CsatSurveyConversationIdIndex" {
+    columns = [column.ConversationId, column.CreatedAt]
+  }
+
+  index "CsatSurveyOrganizationIdIndex" {
+    columns = [column.OrganizationId, column.CreatedAt]
+  }
+}
+
+// private notes left by members on a conversation, they are never sent to the contact
+table "ConversationNote" {
+  schema = schema.public
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "ConversationId" {
+    type = uuid
+    null = false
+  }
+
+  column "OrganizationId" {
+    type = uuid
+    null = false
+  }
+
+  column "AuthorOrganizationMemberId" {
+    type = uuid
+    null = true
+  }
+
+  column "Content" {
+    type = text
+    null = false
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+  foreign_key "ConversationNoteToConversationForeignKey" {
+    columns     = [column.ConversationId]
+    ref_columns = [table.Conversation.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "ConversationNoteToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "ConversationNoteToOrgMemberForeignKey" {
+    columns     = [column.AuthorOrganizationMemberId]
+    ref_columns = [table.OrganizationMember.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "ConversationNoteConversationIdIndex" {
+    columns = [column.ConversationId, column.CreatedAt]
+  }
+}
+
+table "ConversationNoteMention" {
+  schema = schema.public
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+
+  column "ConversationNoteId" {
+    type = uuid
+    null = false
+  }
+
+  column "OrganizationMemberId" {
+    type = uuid
+    null = false
+  }
+
+  primary_key {
+    columns = [column.ConversationNoteId, column.OrganizationMemberId]
+  }
+
+  foreign_key "ConversationNoteMentionToConversationNoteForeignKey" {
+    columns     = [column.ConversationNoteId]
+    ref_columns = [table.ConversationNote.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "ConversationNoteMentionToOrgMemberForeignKey" {
+    columns     = [column.OrganizationMemberId]
+    ref_columns = [table.OrganizationMember.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+}
+
+table "TrackLink" {
+  schema = schema.public
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "OrganizationId" {
+    type = uuid
+    null = false
+  }
+
+  column "CampaignId" {
+    type = uuid
+    null = false
+  }
+
+  column "Slug" {
+    type = text
+    null = false
+  }
+
+  column "DestinationUrl" {
+    type = text
+    null = true
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+  foreign_key "TrackLinkToCampaignForeignKey" {
+    columns     = [column.CampaignId]
+    ref_columns = [table.Campaign.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "TrackLinkToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "TrackLinkCampaignIdIndex" {
+    columns = [column.CampaignId]
+
+  }
+}
+
+table "TrackLinkClick" {
+  schema = schema.public
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "TrackLinkId" {
+    type = uuid
+    null = false
+  }
+
+  column "ContactId" {
+    type = uuid
+    null = false
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+  foreign_key "TrackLinkClickToTrackLinkForeignKey" {
+    columns     = [column.TrackLinkId]
+    ref_columns = [table.TrackLink.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "TrackLinkClickToContactForeignKey" {
+    columns     = [column.ContactId]
+    ref_columns = [table.Contact.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "TrackLinkClickTrackLinkIdIndex" {
+    columns = [column.TrackLinkId]
+  }
+
+  index "TrackLinkClickContactIdIndex" {
+    columns = [column.ContactId]
+  }
+}
+
+table "Tag" {
+  schema = schema.public
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "Label" {
+    type = text
+    null = false
+  }
+
+  column "Slug" {
+    type = text
+    null = false
+  }
+
+  column "OrganizationId" {
+    type = uuid
+    null = false
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+  unique "UniqueSlug" {
+    columns = [column.Slug]
+  }
+
+  index "slugIndex" {
+    columns = [column.Slug]
+  }
+
+  foreign_key "TagToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "TagOrganizationIdIndex" {
+    columns = [column.OrganizationId]
+  }
+
+  index "TagLabelOragnizationIdUniqueIndex" {
+    columns = [column.Label, column.OrganizationId]
+    unique  = true
+  }
+}
+
+table "Integration" {
+  schema = schema.public
+
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+}
+
+// this stores the installed integration for a Organization
+table "OrganizationIntegration" {
+  schema = schema.public
+
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+}
+
+table "Notification" {
+  schema = schema.public
+
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "ctaUrl" {
+    type = text
+    null = true
+  }
+
+  column "title" {
+    type = text
+    null = false
+  }
+
+  column "description" {
+    type = text
+    null = false
+  }
+
+  column "type" {
+    type = text
+    null = true
+  }
+
+  column "isBroadcast" {
+    type    = boolean
+    default = false
+    null    = false
+  }
+
+  // if the above broadcast is true then the user id can be null, because the notification has been sent to all platform users
+  column "UserId" {
+    type = uuid
+    null = true
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+}
+
+table "NotificationReadLog" {
+  schema = schema.public
+
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "ReadByUserId" {
+    type = uuid
+    null = false
+  }
+
+  column "NotificationId" {
+    type = uuid
+    null = false
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+  foreign_key "NotificationReadLogToNotificationForeignKey" {
+    columns     = [column.NotificationId]
+    ref_columns = [table.Notification.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "NotificationReadLogToUserForeignKey" {
+    columns     = [column.ReadByUserId]
+    ref_columns = [table.User.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "NotificationReadLogNotificationIdIndex" {
+    columns = [column.NotificationId]
+  }
+
+  index "NotificationReadLogReadByUserIdIndex" {
+    columns = [column.ReadByUserId]
+  }
+}
+
+table "AiChat" {
+  schema = schema.public
+
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "Status" {
+    type    = enum.AiChatStatusEnum
+    null    = false
+    default = "Active"
+  }
+
+  column "OrganizationId" {
+    type = uuid
+    null = false
+  }
+
+  column "OrganizationMemberId" {
+    type = uuid
+    null = false
+  }
+
+  column "Title" {
+    type = text
+    null = false
+  }
+
+  column "Visibility" {
+    type    = enum.AiChatVisibilityEnum
+    null    = false
+    default = "Public"
+  }
+
+  column "Description" {
+    type = text
+    null = true
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+  foreign_key "AiChatToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "AiChatToOrganizationMemberForeignKey" {
+    columns     = [column.OrganizationMemberId]
+    ref_columns = [table.OrganizationMember.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+}
+
+table "AiChatMessage" {
+  schema = schema.public
+
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "Content" {
+    type = text
+    null = false
+  }
+
+  column "AiChatId" {
+    type = uuid
+    null = false
+  }
+
+  column "OrganizationId" {
+    type = uuid
+    null = false
+  }
+
+  column "OrganizationMemberId" {
+    type = uuid
+    null = false
+  }
+
+  column "Role" {
+    type = enum.AiChatMessageRoleEnum
+    null = false
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+  foreign_key "AiChatMessageToChatForeignKey" {
+    columns     = [column.AiChatId]
+    ref_columns = [table.AiChat.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "AiChatToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "AiChatToOrganizationMemberForeignKey" {
+    columns     = [column.OrganizationMemberId]
+    ref_columns = [table.OrganizationMember.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "AiChatMessageOrganizationIdIndex" {
+    columns = [column.OrganizationId]
+  }
+
+  index "AiChatMessageOrganizationMemberIdIndex" {
+    columns = [column.OrganizationMemberId]
+  }
+
+  index "AiChatMessageChatIdIndex" {
+    columns = [column.AiChatId]
+  }
+
+  index "AiChatMessageRoleIndex" {
+    columns = [column.Role]
+  }
+}
+
+table "AiChatMessageVote" {
+  schema = schema.public
+
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "AiChatMessageId" {
+    type = uuid
+    null = false
+  }
+
+  column "Vote" {
+    type = enum.AiChatMessageVoteEnum
+    null = false
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+  foreign_key "AiChatMessageVoteToAiChatMessageForeignKey" {
+    columns     = [column.AiChatMessageId]
+    ref_columns = [table.AiChatMessage.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "AiChatMessageVoteAiChatMessageIdIndex" {
+    columns = [column.AiChatMessageId]
+  }
+}
+
+table "AiChatSuggestions" {
+  schema = schema.public
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "AiChatId" {
+    type = uuid
+    null = false
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+  foreign_key "AiChatSuggestionsToAiChatForeignKey" {
+    columns     = [column.AiChatId]
+    ref_columns = [table.AiChat.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+}
+
+
+table "AiApiCallLogs" {
+  schema = schema.public
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
+
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "AiChatId" {
+    type = uuid
+    null = false
+  }
+
+  column "Request" {
+    type = jsonb
+    null = false
+  }
+
+  column "Response" {
+    type = jsonb
+    null = false
+  }
+
+  column "InputTokenUsed" {
+    type = int
+    null = false
+  }
+
+  column "OutputTokenUsed" {
+    type = int
+    null = false
+  }
+
+  column "Model" {
+    type = enum.AiModelEnum
+    null = false
+  }
+
+  primary_key {
+    columns = [column.UniqueId]
+  }
+
+  foreign_key "AiApiCallLogsToAiChatForeignKey" {
+    columns     = [column.AiChatId]
+    ref_columns = [table.AiChat.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "AiApiCallLogsAiChatIdIndex" {
+    columns = [column.AiChatId]
+  }
+}
+
+// ==== JOIN TABLES ======
+
+table "ContactListContact" {
+  schema = schema.public
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "ContactListId" {
+    type = uuid
+    null = false
+  }
+
+  column "ContactId" {
+    type = uuid
+    null = false
+  }
+
+  primary_key {
+    columns = [column.ContactListId, column.ContactId]
+  }
+
+  foreign_key "ContactListContactToContactListForeignKey" {
+    columns     = [column.ContactListId]
+    ref_columns = [table.ContactList.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "ContactListContactToContactForeignKey" {
+    columns     = [column.ContactId]
+    ref_columns = [table.Contact.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+}
+
+table "ContactListTag" {
+  schema = schema.public
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "ContactListId" {
+    type = uuid
+    null = false
+  }
+
+  column "TagId" {
+    type = uuid
+    null = false
+  }
+
+  primary_key {
+    columns = [column.ContactListId, column.TagId]
+  }
+
+  foreign_key "ContactListTagToContactListForeignKey" {
+    columns     = [column.ContactListId]
+    ref_columns = [table.ContactList.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "ContactListTagToTagForeignKey" {
+    columns     = [column.TagId]
+    ref_columns = [table.Tag.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+}
+
+table "CampaignList" {
+
+  schema = schema.public
+
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "ContactListId" {
+    type = uuid
+    null = false
+  }
+
+  column "CampaignId" {
+    type = uuid
+    null = false
+  }
+
+  primary_key {
+    columns = [column.ContactListId, column.CampaignId]
+  }
+
+  foreign_key "CampaignListToContactListForeignKey" {
+    columns     = [column.ContactListId]
+    ref_columns = [table.ContactList.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "CampaignListToCampaignForeignKey" {
+    columns     = [column.CampaignId]
+    ref_columns = [table.Campaign.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+}
+
+table "ConversationTag" {
+  schema = schema.public
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+
+  column "ConversationId" {
+    type = uuid
+    null = false
+  }
+
+  column "TagId" {
+    type = uuid
+    null = false
+  }
+
+  primary_key {
+    columns = [column.ConversationId, column.TagId]
+  }
+
+  foreign_key "ConversationTagToConversationForeignKey" {
+    columns     = [column.ConversationId]
+    ref_columns = [table.Conversation.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "ConversationTagToTagForeignKey" {
+    columns     = [column.TagId]
+    ref_columns = [table.Tag.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
 }
 
-table "Notification" {
+table "CannedResponseTag" {
   schema = schema.public
-
-  column "UniqueId" {
-    type    = uuid
-    null    = false
-    default = sql("gen_random_uuid()")
-  }
   column "CreatedAt" {
     type    = timestamptz
     null    = false
@@ -1411,51 +3023,37 @@ table "Notification" {
     null = false
   }
 
-  column "ctaUrl" {
-    type = text
-    null = true
-  }
-
-  column "title" {
-    type = text
+  column "CannedResponseId" {
+    type = uuid
     null = false
   }
 
-  column "description" {
-    type = text
+  column "TagId" {
+    type = uuid
     null = false
   }
 
-  column "type" {
-    type = text
-    null = true
-  }
-
-  column "isBroadcast" {
-    type    = boolean
-    default = false
-    null    = false
+  primary_key {
+    columns = [column.CannedResponseId, column.TagId]
   }
 
-  // if the above broadcast is true then the user id can be null, because the notification has been sent to all platform users
-  column "UserId" {
-    type = uuid
-    null = true
+  foreign_key "CannedResponseTagToCannedResponseForeignKey" {
+    columns     = [column.CannedResponseId]
+    ref_columns = [table.CannedResponse.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
   }
 
-  primary_key {
-    columns = [column.UniqueId]
+  foreign_key "CannedResponseTagToTagForeignKey" {
+    columns     = [column.TagId]
+    ref_columns = [table.Tag.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
   }
 }
 
-table "NotificationReadLog" {
+table "CampaignTag" {
   schema = schema.public
-
-  column "UniqueId" {
-    type    = uuid
-    null    = false
-    default = sql("gen_random_uuid()")
-  }
   column "CreatedAt" {
     type    = timestamptz
     null    = false
@@ -1466,155 +3064,182 @@ table "NotificationReadLog" {
     null = false
   }
 
-  column "ReadByUserId" {
+  column "CampaignId" {
     type = uuid
     null = false
   }
 
-  column "NotificationId" {
+  column "TagId" {
     type = uuid
     null = false
   }
 
   primary_key {
-    columns = [column.UniqueId]
+    columns = [column.CampaignId, column.TagId]
   }
 
-  foreign_key "NotificationReadLogToNotificationForeignKey" {
-    columns     = [column.NotificationId]
-    ref_columns = [table.Notification.column.UniqueId]
+  foreign_key "CampaignTagToCampaignForeignKey" {
+    columns     = [column.CampaignId]
+    ref_columns = [table.Campaign.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  foreign_key "NotificationReadLogToUserForeignKey" {
-    columns     = [column.ReadByUserId]
-    ref_columns = [table.User.column.UniqueId]
+  foreign_key "CampaignTagToTagForeignKey" {
+    columns     = [column.TagId]
+    ref_columns = [table.Tag.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  index "NotificationReadLogNotificationIdIndex" {
-    columns = [column.NotificationId]
-  }
-
-  index "NotificationReadLogReadByUserIdIndex" {
-    columns = [column.ReadByUserId]
+  index "CampaignTagIdCampaignIdUniqueIndex" {
+    columns = [column.CampaignId, column.TagId]
+    unique  = true
   }
 }
 
-table "AiChat" {
+table "BackgroundJob" {
   schema = schema.public
-
   column "UniqueId" {
     type    = uuid
     null    = false
     default = sql("gen_random_uuid()")
   }
-
   column "CreatedAt" {
     type    = timestamptz
     null    = false
     default = sql("now()")
   }
-
+  // refreshed with every progress update, a running job not updated for a while has been interrupted
   column "UpdatedAt" {
     type = timestamptz
     null = false
   }
 
-  column "Status" {
-    type    = enum.AiChatStatusEnum
-    null    = false
-    default = "Active"
-  }
-
   column "OrganizationId" {
     type = uuid
     null = false
   }
 
-  column "OrganizationMemberId" {
+  column "CreatedByOrganizationMemberId" {
     type = uuid
+    null = true
+  }
+
+  column "Type" {
+    type = enum.BackgroundJobTypeEnum
     null = false
   }
 
-  column "Title" {
-    type = text
+  column "Status" {
+    type = enum.BackgroundJobStatusEnum
     null = false
   }
 
-  column "Visibility" {
-    type    = enum.AiChatVisibilityEnum
+  // the input of the job, its shape depends on the type
+  column "Parameters" {
+    type = jsonb
+    null = false
+  }
+
+  // null until the job knows how many items it has to process
+  column "TotalItems" {
+    type = integer
+    null = true
+  }
+
+  column "ProcessedItems" {
+    type    = integer
     null    = false
-    default = "Public"
+    default = 0
   }
 
-  column "Description" {
+  column "FailedItems" {
+    type    = integer
+    null    = false
+    default = 0
+  }
+
+  // the summary of a completed job, its shape depends on the type
+  column "Result" {
+    type = jsonb
+    null = true
+  }
+
+  column "Error" {
     type = text
     null = true
   }
 
+  column "StartedAt" {
+    type = timestamptz
+    null = true
+  }
+
+  column "CompletedAt" {
+    type = timestamptz
+    null = true
+  }
+
   primary_key {
     columns = [column.UniqueId]
   }
 
-  foreign_key "AiChatToOrganizationForeignKey" {
+  foreign_key "BackgroundJobToOrganizationForeignKey" {
     columns     = [column.OrganizationId]
     ref_columns = [table.Organization.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  foreign_key "AiChatToOrganizationMemberForeignKey" {
-    columns     = [column.OrganizationMemberId]
+  foreign_key "BackgroundJobToOrgMemberForeignKey" {
+    columns     = [column.CreatedByOrganizationMemberId]
     ref_columns = [table.OrganizationMember.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
+
+  index "BackgroundJobOrganizationIdIndex" {
+    columns = [column.OrganizationId, column.CreatedAt]
+  }
+
+  index "BackgroundJobStatusIndex" {
+    columns = [column.Status, column.CreatedAt]
+  }
 }
 
-table "AiChatMessage" {
+// an item a background job could not process, like a row of an imported file
+table "BackgroundJobItemError" {
   schema = schema.public
-
   column "UniqueId" {
     type    = uuid
     null    = false
     default = sql("gen_random_uuid()")
   }
-
   column "CreatedAt" {
     type    = timestamptz
     null    = false
     default = sql("now()")
   }
-  column "UpdatedAt" {
-    type = timestamptz
-    null = false
-  }
-
-  column "Content" {
-    type = text
-    null = false
-  }
 
-  column "AiChatId" {
+  column "BackgroundJobId" {
     type = uuid
     null = false
   }
 
-  column "OrganizationId" {
-    type = uuid
+  // the position of the item in the input, the row number for imports
+  column "ItemNumber" {
+    type = integer
     null = false
   }
 
-  column "OrganizationMemberId" {
-    type = uuid
-    null = false
+  column "Item" {
+    type = text
+    null = true
   }
 
-  column "Role" {
-    type = enum.AiChatMessageRoleEnum
+  column "Error" {
+    type = text
     null = false
   }
 
@@ -1622,53 +3247,68 @@ table "AiChatMessage" {
     columns = [column.UniqueId]
   }
 
-  foreign_key "AiChatMessageToChatForeignKey" {
-    columns     = [column.AiChatId]
-    ref_columns = [table.AiChat.column.UniqueId]
-    on_delete   = NO_ACTION
-    on_update   = NO_ACTION
-  }
-
-  foreign_key "AiChatToOrganizationForeignKey" {
-    columns     = [column.OrganizationId]
-    ref_columns = [table.Organization.column.UniqueId]
-    on_delete   = NO_ACTION
-    on_update   = NO_ACTION
-  }
-
-  foreign_key "AiChatToOrganizationMemberForeignKey" {
-    columns     = [column.OrganizationMemberId]
-    ref_columns = [table.OrganizationMember.column.UniqueId]
+  foreign_key "BackgroundJobItemErrorToBackgroundJobForeignKey" {
+    columns     = [column.BackgroundJobId]
+    ref_columns = [table.BackgroundJob.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  index "AiChatMessageOrganizationIdIndex" {
-    columns = [column.OrganizationId]
-  }
-
-  index "AiChatMessageOrganizationMemberIdIndex" {
-    columns = [column.OrganizationMemberId]
-  }
-
-  index "AiChatMessageChatIdIndex" {
-    columns = [column.AiChatId]
-  }
-
-  index "AiChatMessageRoleIndex" {
-    columns = [column.Role]
+  index "BackgroundJobItemErrorJobIdIndex" {
+    columns = [column.BackgroundJobId, column.ItemNumber]
   }
 }
 
-table "AiChatMessageVote" {
+// saved audiences, the contacts of a segment are the ones matching its rules at the time it is evaluated
+table "Segment" {
   schema = schema.public
-
   column "UniqueId" {
     type    = uuid
     null    = false
     default = sql("gen_random_uuid()")
   }
+  column "CreatedAt" {
+    type    = timestamptz
+    null    = false
+    default = sql("now()")
+  }
+  column "UpdatedAt" {
+    type = timestamptz
+    null = false
+  }
+  column "OrganizationId" {
+    type = uuid
+    null = false
+  }
+  column "Name" {
+    type = text
+    null = false
+  }
+  column "Description" {
+    type = text
+    null = true
+  }
+  // the rule group of the segment as defined by SegmentRuleGroupSchema
+  column "Rules" {
+    type = jsonb
+    null = false
+  }
+  primary_key {
+    columns = [column.UniqueId]
+  }
+  foreign_key "SegmentToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+  index "SegmentOrganizationIdIndex" {
+    columns = [column.OrganizationId]
+  }
+}
 
+table "CampaignSegment" {
+  schema = schema.public
   column "CreatedAt" {
     type    = timestamptz
     null    = false
@@ -1679,76 +3319,116 @@ table "AiChatMessageVote" {
     null = false
   }
 
-  column "AiChatMessageId" {
+  column "SegmentId" {
     type = uuid
     null = false
   }
 
-  column "Vote" {
-    type = enum.AiChatMessageVoteEnum
+  column "CampaignId" {
+    type = uuid
     null = false
   }
 
   primary_key {
-    columns = [column.UniqueId]
+    columns = [column.SegmentId, column.CampaignId]
   }
 
-  foreign_key "AiChatMessageVoteToAiChatMessageForeignKey" {
-    columns     = [column.AiChatMessageId]
-    ref_columns = [table.AiChatMessage.column.UniqueId]
+  foreign_key "CampaignSegmentToSegmentForeignKey" {
+    columns     = [column.SegmentId]
+    ref_columns = [table.Segment.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  index "AiChatMessageVoteAiChatMessageIdIndex" {
-    columns = [column.AiChatMessageId]
+  foreign_key "CampaignSegmentToCampaignForeignKey" {
+    columns     = [column.CampaignId]
+    ref_columns = [table.Campaign.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "CampaignSegmentCampaignIdIndex" {
+    columns = [column.CampaignId]
   }
 }
 
-table "AiChatSuggestions" {
+// actions taken on the personal data of contacts, kept as proof for the data protection obligations of the organization
+table "AuditLog" {
   schema = schema.public
   column "UniqueId" {
     type    = uuid
     null    = false
     default = sql("gen_random_uuid()")
   }
-
   column "CreatedAt" {
     type    = timestamptz
     null    = false
     default = sql("now()")
   }
-  column "UpdatedAt" {
-    type = timestamptz
+
+  column "OrganizationId" {
+    type = uuid
+    null = false
+  }
+
+  // null when the action was not taken by a member, or the member has been removed since
+  column "OrganizationMemberId" {
+    type = uuid
+    null = true
+  }
+
+  column "Action" {
+    type = enum.AuditLogActionEnum
     null = false
   }
 
-  column "AiChatId" {
-    type = uuid
-    null = false
+  // the record the action was taken on, not a foreign key as the record may have been deleted by the action
+  column "EntityId" {
+    type = uuid
+    null = true
+  }
+
+  // what the action was taken with, like the filters of an export, its shape depends on the action
+  column "Details" {
+    type = jsonb
+    null = true
   }
 
   primary_key {
     columns = [column.UniqueId]
   }
 
-  foreign_key "AiChatSuggestionsToAiChatForeignKey" {
-    columns     = [column.AiChatId]
-    ref_columns = [table.AiChat.column.UniqueId]
+  foreign_key "AuditLogToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "AuditLogToOrgMemberForeignKey" {
+    columns     = [column.OrganizationMemberId]
+    ref_columns = [table.OrganizationMember.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
-}
 
+  index "AuditLogOrganizationIdIndex" {
+    columns = [column.OrganizationId, column.CreatedAt]
+  }
 
-table "AiApiCallLogs" {
+  index "AuditLogEntityIdIndex" {
+    columns = [column.EntityId]
+  }
+}
+
+// the custom fields an organization defines for its contacts, their values are kept in the attributes of the contacts
+table "ContactField" {
   schema = schema.public
   column "UniqueId" {
     type    = uuid
     null    = false
     default = sql("gen_random_uuid()")
   }
-
   column "CreatedAt" {
     type    = timestamptz
     null    = false
@@ -1758,68 +3438,68 @@ table "AiApiCallLogs" {
     type = timestamptz
     null = false
   }
-
-  column "AiChatId" {
+  column "OrganizationId" {
     type = uuid
     null = false
   }
-
-  column "Request" {
-    type = jsonb
+  // the key of the field in the attributes of the contacts
+  column "Key" {
+    type = text
     null = false
   }
-
-  column "Response" {
-    type = jsonb
+  column "Label" {
+    type = text
     null = false
   }
-
-  column "InputTokenUsed" {
-    type = int
+  column "Type" {
+    type = enum.ContactFieldTypeEnum
     null = false
   }
-
-  column "OutputTokenUsed" {
-    type = int
-    null = false
+  column "IsRequired" {
+    type    = boolean
+    null    = false
+    default = false
   }
-
-  column "Model" {
-    type = enum.AiModelEnum
-    null = false
+  // a JSON value of the type of the field
+  column "DefaultValue" {
+    type = jsonb
+    null = true
+  }
+  // the allowed values of enum fields, as a JSON array of strings
+  column "Options" {
+    type = jsonb
+    null = true
   }
-
   primary_key {
     columns = [column.UniqueId]
   }
-
-  foreign_key "AiApiCallLogsToAiChatForeignKey" {
-    columns     = [column.AiChatId]
-    ref_columns = [table.AiChat.column.UniqueId]
+  foreign_key "ContactFieldToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
-
-  index "AiApiCallLogsAiChatIdIndex" {
-    columns = [column.AiChatId]
+  index "ContactFieldOrganizationIdKeyUniqueIndex" {
+    columns = [column.OrganizationId, column.Key]
+    unique  = true
   }
 }
 
-// ==== JOIN TABLES ======
-
-table "ContactListContact" {
+// the changes made to contacts which are not kept anywhere else, they are shown in the timelines of the contacts
+table "ContactActivity" {
   schema = schema.public
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
   column "CreatedAt" {
     type    = timestamptz
     null    = false
     default = sql("now()")
   }
-  column "UpdatedAt" {
-    type = timestamptz
-    null = false
-  }
 
-  column "ContactListId" {
+  column "OrganizationId" {
     type = uuid
     null = false
   }
@@ -1829,27 +3509,61 @@ table "ContactListContact" {
     null = false
   }
 
+  column "Type" {
+    type = enum.ContactActivityTypeEnum
+    null = false
+  }
+
+  // null when the change was not made by a member, or the member has been removed since
+  column "OrganizationMemberId" {
+    type = uuid
+    null = true
+  }
+
+  // what changed, like the list joined or the old and new values of the attributes, its shape depends on the type
+  column "Data" {
+    type = jsonb
+    null = true
+  }
+
   primary_key {
-    columns = [column.ContactListId, column.ContactId]
+    columns = [column.UniqueId]
   }
 
-  foreign_key "ContactListContactToContactListForeignKey" {
-    columns     = [column.ContactListId]
-    ref_columns = [table.ContactList.column.UniqueId]
+  foreign_key "ContactActivityToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  foreign_key "ContactListContactToContactForeignKey" {
+  foreign_key "ContactActivityToContactForeignKey" {
     columns     = [column.ContactId]
     ref_columns = [table.Contact.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
+
+  foreign_key "ContactActivityToOrgMemberForeignKey" {
+    columns     = [column.OrganizationMemberId]
+    ref_columns = [table.OrganizationMember.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "ContactActivityContactIdIndex" {
+    columns = [column.ContactId, column.CreatedAt]
+  }
 }
 
-table "ContactListTag" {
+// the opt-in forms and click-to-chat links of a list, contacts captured through them are added to the list
+table "ContactCaptureSource" {
   schema = schema.public
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
   column "CreatedAt" {
     type    = timestamptz
     null    = false
@@ -1860,80 +3574,112 @@ table "ContactListTag" {
     null = false
   }
 
-  column "ContactListId" {
+  column "OrganizationId" {
     type = uuid
     null = false
   }
 
-  column "TagId" {
+  column "ContactListId" {
     type = uuid
     null = false
   }
 
-  primary_key {
-    columns = [column.ContactListId, column.TagId]
+  column "Type" {
+    type = enum.ContactCaptureSourceTypeEnum
+    null = false
   }
 
-  foreign_key "ContactListTagToContactListForeignKey" {
-    columns     = [column.ContactListId]
-    ref_columns = [table.ContactList.column.UniqueId]
-    on_delete   = NO_ACTION
-    on_update   = NO_ACTION
+  column "Name" {
+    type = text
+    null = false
   }
 
-  foreign_key "ContactListTagToTagForeignKey" {
-    columns     = [column.TagId]
-    ref_columns = [table.Tag.column.UniqueId]
-    on_delete   = NO_ACTION
-    on_update   = NO_ACTION
+  // identifies the form in its public url, and the link in the message prefilled by it
+  column "Code" {
+    type = text
+    null = false
   }
-}
 
-table "CampaignList" {
+  // links only, the business phone number the link opens a chat with
+  column "PhoneNumber" {
+    type = text
+    null = true
+  }
 
-  schema = schema.public
+  // links only, the message prefilled for the contact, the code is added to it
+  column "PrefilledMessage" {
+    type = text
+    null = true
+  }
 
-  column "CreatedAt" {
-    type    = timestamptz
-    null    = false
-    default = sql("now()")
+  // forms only, the statement the contact agrees to, it is recorded with each submission
+  column "ConsentText" {
+    type = text
+    null = true
   }
-  column "UpdatedAt" {
-    type = timestamptz
-    null = false
+
+  // added to the attributes of the captured contacts, like the source of the contact
+  column "Attributes" {
+    type = jsonb
+    null = true
   }
 
-  column "ContactListId" {
+  // sent to the contacts which message the business through the link for the first time
+  column "WelcomeCannedResponseId" {
     type = uuid
-    null = false
+    null = true
   }
 
-  column "CampaignId" {
-    type = uuid
-    null = false
+  column "IsActive" {
+    type    = boolean
+    null    = false
+    default = true
   }
 
   primary_key {
-    columns = [column.ContactListId, column.CampaignId]
+    columns = [column.UniqueId]
   }
 
-  foreign_key "CampaignListToContactListForeignKey" {
+  foreign_key "ContactCaptureSourceToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "ContactCaptureSourceToContactListForeignKey" {
     columns     = [column.ContactListId]
     ref_columns = [table.ContactList.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  foreign_key "CampaignListToCampaignForeignKey" {
-    columns     = [column.CampaignId]
-    ref_columns = [table.Campaign.column.UniqueId]
+  foreign_key "ContactCaptureSourceToCannedResponseForeignKey" {
+    columns     = [column.WelcomeCannedResponseId]
+    ref_columns = [table.CannedResponse.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
+
+  index "ContactCaptureSourceCodeIndex" {
+    columns = [column.Code]
+    unique  = true
+  }
+
+  index "ContactCaptureSourceContactListIdIndex" {
+    columns = [column.ContactListId]
+  }
 }
 
-table "ConversationTag" {
+// the current consent of a contact to receive the messages of a category, each change is recorded in the activity of the
+// contact along with its evidence
+table "ContactConsent" {
   schema = schema.public
+  column "UniqueId" {
+    type    = uuid
+    null    = false
+    default = sql("gen_random_uuid()")
+  }
   column "CreatedAt" {
     type    = timestamptz
     null    = false
@@ -1944,78 +3690,89 @@ table "ConversationTag" {
     null = false
   }
 
-  column "ConversationId" {
+  column "OrganizationId" {
     type = uuid
     null = false
   }
 
-  column "TagId" {
+  column "ContactId" {
     type = uuid
     null = false
   }
 
-  primary_key {
-    columns = [column.ConversationId, column.TagId]
+  column "Category" {
+    type = enum.ContactConsentCategoryEnum
+    null = false
   }
 
-  foreign_key "ConversationTagToConversationForeignKey" {
-    columns     = [column.ConversationId]
-    ref_columns = [table.Conversation.column.UniqueId]
-    on_delete   = NO_ACTION
-    on_update   = NO_ACTION
+  column "Status" {
+    type = enum.ContactConsentStatusEnum
+    null = false
   }
 
-  foreign_key "ConversationTagToTagForeignKey" {
-    columns     = [column.TagId]
-    ref_columns = [table.Tag.column.UniqueId]
-    on_delete   = NO_ACTION
-    on_update   = NO_ACTION
+  column "Source" {
+    type = enum.ContactConsentSourceEnum
+    null = false
   }
-}
 
-table "CampaignTag" {
-  schema = schema.public
-  column "CreatedAt" {
-    type    = timestamptz
-    null    = false
-    default = sql("now()")
-  }
-  column "UpdatedAt" {
+  // when the consent was given or withdrawn, imported consents may have been given long before they were recorded
+  column "ChangedAt" {
     type = timestamptz
     null = false
   }
 
-  column "CampaignId" {
+  // the proof of the change, like the statement the contact agreed to or the message it opted out with
+  column "Evidence" {
+    type = jsonb
+    null = true
+  }
+
+  // the opt-in form the consent was given through
+  column "ContactCaptureSourceId" {
     type = uuid
-    null = false
+    null = true
   }
 
-  column "TagId" {
+  // the member who recorded the change, null when the contact made it
+  column "OrganizationMemberId" {
     type = uuid
-    null = false
+    null = true
   }
 
   primary_key {
-    columns = [column.CampaignId, column.TagId]
+    columns = [column.UniqueId]
   }
 
-  foreign_key "CampaignTagToCampaignForeignKey" {
-    columns     = [column.CampaignId]
-    ref_columns = [table.Campaign.column.UniqueId]
+  foreign_key "ContactConsentToOrganizationForeignKey" {
+    columns     = [column.OrganizationId]
+    ref_columns = [table.Organization.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  foreign_key "CampaignTagToTagForeignKey" {
-    columns     = [column.TagId]
-    ref_columns = [table.Tag.column.UniqueId]
+  foreign_key "ContactConsentToContactForeignKey" {
+    columns     = [column.ContactId]
+    ref_columns = [table.Contact.column.UniqueId]
     on_delete   = NO_ACTION
     on_update   = NO_ACTION
   }
 
-  index "CampaignTagIdCampaignIdUniqueIndex" {
-    columns = [column.CampaignId, column.TagId]
+  foreign_key "ContactConsentToContactCaptureSourceForeignKey" {
+    columns     = [column.ContactCaptureSourceId]
+    ref_columns = [table.ContactCaptureSource.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  foreign_key "ContactConsentToOrganizationMemberForeignKey" {
+    columns     = [column.OrganizationMemberId]
+    ref_columns = [table.OrganizationMember.column.UniqueId]
+    on_delete   = NO_ACTION
+    on_update   = NO_ACTION
+  }
+
+  index "ContactConsentContactIdCategoryIndex" {
+    columns = [column.ContactId, column.Category]
     unique  = true
   }
 }
-
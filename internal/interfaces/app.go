@@ -8,7 +8,10 @@ import (
 	"github.com/knadh/stuffbin"
 	wapi "github.com/wapikit/wapi.go/pkg/client"
 	"github.com/wapikit/wapikit/internal/core/ai_service"
+	"github.com/wapikit/wapikit/internal/core/mail_service"
+	"github.com/wapikit/wapikit/internal/core/oauth_service"
 	cache "github.com/wapikit/wapikit/internal/core/redis"
+	"github.com/wapikit/wapikit/internal/core/secret_service"
 	campaign_manager "github.com/wapikit/wapikit/manager/campaign"
 )
 
@@ -23,6 +26,8 @@ type Constants struct {
 	IsProduction          bool
 	RedisEventChannelName string `koanf:"redis_event_channel_name"`
 	IsDebugModeEnabled    bool
+	// uploaded files are kept here until the background jobs processing them are done
+	UploadDirectory string `koanf:"upload_directory"`
 }
 
 type App struct {
@@ -35,5 +40,9 @@ type App struct {
 	Constants       *Constants
 	CampaignManager *campaign_manager.CampaignManager
 	AiService       *ai_service.AiService
+	OAuthProviders  *oauth_service.ProviderRegistry
+	Secrets         *secret_service.SecretService
+	// nil when no smtp server is configured
+	Mailer mail_service.Mailer
 	// ! TODO: add some api server event utility so anybody api server event can be published easily.
 }
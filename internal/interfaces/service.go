@@ -9,6 +9,8 @@ import (
 type RateLimitConfig struct {
 	MaxRequests    int   `json:"maxRequests"`
 	WindowTimeInMs int64 `json:"windowTime"`
+	// IsEnforced refuses the requests over the limit, the limits of the other routes have not been reviewed to be enforced
+	IsEnforced bool `json:"isEnforced"`
 }
 
 type RouteMetaData struct {
@@ -56,8 +58,9 @@ type ContextUser struct {
 }
 
 type ContextSession struct {
-	Token string      `json:"token"`
-	User  ContextUser `json:"user"`
+	Token     string      `json:"token"`
+	SessionId string      `json:"session_id,omitempty"`
+	User      ContextUser `json:"user"`
 }
 
 type ContextWithSession struct {
@@ -73,5 +76,6 @@ type ContextWithoutSession struct {
 
 type JwtPayload struct {
 	ContextUser        `json:",inline"`
+	SessionId          string `json:"session_id,omitempty"`
 	jwt.StandardClaims `json:",inline"`
 }
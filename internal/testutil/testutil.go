@@ -0,0 +1,271 @@
+// Package testutil sets up the app the handlers are tested with. The tests using it run against a postgres database and a
+// redis server, given by WAPIKIT_TEST_DATABASE_URL and WAPIKIT_TEST_REDIS_URL, and are skipped when those are not set.
+// The database is migrated from internal/database/migrations when it has no schema yet, every test seeds its own rows.
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knadh/koanf/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/wapikit/wapikit/internal/api_types"
+	cache "github.com/wapikit/wapikit/internal/core/redis"
+	"github.com/wapikit/wapikit/internal/core/secret_service"
+	"github.com/wapikit/wapikit/internal/core/session_service"
+	"github.com/wapikit/wapikit/internal/interfaces"
+
+	_ "github.com/lib/pq"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+const JwtSecret = "wapikit-test-jwt-secret"
+
+var migrateOnce sync.Once
+
+// NewApp returns the app the handlers run with, the test is skipped when the test database and redis are not configured
+func NewApp(t *testing.T) *interfaces.App {
+	t.Helper()
+
+	databaseUrl := os.Getenv("WAPIKIT_TEST_DATABASE_URL")
+	redisUrl := os.Getenv("WAPIKIT_TEST_REDIS_URL")
+
+	if databaseUrl == "" || redisUrl == "" {
+		t.Skip("WAPIKIT_TEST_DATABASE_URL and WAPIKIT_TEST_REDIS_URL are required to run the tests of the handlers")
+	}
+
+	db, err := sql.Open("postgres", databaseUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	var migrationErr error
+	migrateOnce.Do(func() {
+		migrationErr = migrate(db)
+	})
+	if migrationErr != nil {
+		t.Fatal(migrationErr)
+	}
+
+	redis := cache.NewRedisClient(redisUrl)
+	if redis == nil {
+		t.Fatalf("unable to connect to redis at %s", redisUrl)
+	}
+	t.Cleanup(func() { redis.Close() })
+
+	secrets, err := secret_service.NewSecretService("wapikit-test-encryption-key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	koa := koanf.New(".")
+	koa.Set("app.jwt_secret", JwtSecret)
+
+	return &interfaces.App{
+		Db:     db,
+		Redis:  redis,
+		Logger: *slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Koa:    koa,
+		Constants: &interfaces.Constants{
+			RedisEventChannelName: "wapikit-test-" + uuid.NewString(),
+			UploadDirectory:       t.TempDir(),
+		},
+		Secrets: secrets,
+	}
+}
+
+// NewServer returns a server with the routes of the controllers, the way the api server mounts them
+func NewServer(app *interfaces.App, controllers ...interfaces.ApiController) *echo.Echo {
+	server := echo.New()
+	server.IPExtractor = echo.ExtractIPDirect()
+	server.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("app", app)
+			return next(c)
+		}
+	})
+
+	for _, controller := range controllers {
+		controller.Register(server)
+	}
+
+	return server
+}
+
+// migrate applies the migrations to a database without a schema, the migrations are not idempotent so a database which
+// has the tables already is left as it is
+// the test binaries of the packages run in parallel, only one of them migrates the database while holding this lock
+const migrationLockId = 20250101
+
+func migrate(db *sql.DB) error {
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockId); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockId)
+
+	var userTable sql.NullString
+	if err := conn.QueryRowContext(ctx, `SELECT to_regclass('public."User"')::text`).Scan(&userTable); err != nil {
+		return err
+	}
+
+	if userTable.Valid {
+		return nil
+	}
+
+	_, currentFile, _, _ := runtime.Caller(0)
+	migrationFiles, err := filepath.Glob(filepath.Join(filepath.Dir(currentFile), "..", "database", "migrations", "*.sql"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(migrationFiles)
+
+	for _, migrationFile := range migrationFiles {
+		migration, err := os.ReadFile(migrationFile)
+		if err != nil {
+			return err
+		}
+		if _, err := conn.ExecContext(ctx, string(migration)); err != nil {
+			return fmt.Errorf("error applying migration %s: %w", filepath.Base(migrationFile), err)
+		}
+	}
+
+	return nil
+}
+
+// Organization is an organization seeded for a test, with its owner logged in
+type Organization struct {
+	Organization model.Organization
+	Owner        model.User
+	Member       model.OrganizationMember
+	// the access token of a session of the owner, to be sent in the x-access-token header
+	Token     string
+	SessionId uuid.UUID
+}
+
+// SeedOrganization creates an organization and its owner, and logs the owner in
+func SeedOrganization(t *testing.T, app *interfaces.App) Organization {
+	t.Helper()
+
+	ctx := context.Background()
+	suffix := uuid.NewString()[:8]
+	seeded := Organization{}
+
+	err := table.Organization.INSERT(table.Organization.MutableColumns).
+		MODEL(model.Organization{
+			Name:      "Organization " + suffix,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}).
+		RETURNING(table.Organization.AllColumns).
+		QueryContext(ctx, app.Db, &seeded.Organization)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seeded.Owner = SeedUser(t, app, "owner-"+suffix+"@example.com")
+
+	err = table.OrganizationMember.INSERT(table.OrganizationMember.MutableColumns).
+		MODEL(model.OrganizationMember{
+			AccessLevel:    model.UserPermissionLevelEnum_Owner,
+			OrganizationId: seeded.Organization.UniqueId,
+			UserId:         seeded.Owner.UniqueId,
+			IsAvailable:    true,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}).
+		RETURNING(table.OrganizationMember.AllColumns).
+		QueryContext(ctx, app.Db, &seeded.Member)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seeded.Token, seeded.SessionId = login(t, app, seeded.Owner, &seeded.Organization.UniqueId)
+
+	return seeded
+}
+
+// SeedUser creates an active user with a password account
+func SeedUser(t *testing.T, app *interfaces.App, email string) model.User {
+	t.Helper()
+
+	password := "not-a-real-password-hash"
+	var user model.User
+
+	err := table.User.INSERT(table.User.MutableColumns).
+		MODEL(model.User{
+			Name:      "User " + email,
+			Email:     email,
+			Username:  "user-" + uuid.NewString()[:12],
+			Password:  &password,
+			Status:    model.UserAccountStatusEnum_Active,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}).
+		RETURNING(table.User.AllColumns).
+		QueryContext(context.Background(), app.Db, &user)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return user
+}
+
+// Login creates a session of the user and returns its access token, the user is logged in with the organization if given
+func Login(t *testing.T, app *interfaces.App, user model.User, organizationId *uuid.UUID) string {
+	t.Helper()
+
+	token, _ := login(t, app, user, organizationId)
+	return token
+}
+
+func login(t *testing.T, app *interfaces.App, user model.User, organizationId *uuid.UUID) (string, uuid.UUID) {
+	t.Helper()
+
+	session, _, err := session_service.CreateSession(context.Background(), app.Db, user.UniqueId, organizationId, session_service.SessionMetaData{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contextUser := interfaces.ContextUser{
+		Name:     user.Name,
+		UniqueId: user.UniqueId.String(),
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     api_types.Owner,
+	}
+
+	if organizationId != nil {
+		contextUser.OrganizationId = organizationId.String()
+	}
+
+	token, err := session_service.SignAccessToken(contextUser, session.UniqueId.String(), JwtSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token, session.UniqueId
+}
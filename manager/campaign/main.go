@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"reflect"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -15,6 +16,10 @@ import (
 	"github.com/paulbellamy/ratecounter"
 	wapi "github.com/wapikit/wapi.go/pkg/client"
 	wapiComponents "github.com/wapikit/wapi.go/pkg/components"
+	"github.com/wapikit/wapikit/internal/core/canned_response_service"
+	"github.com/wapikit/wapikit/internal/core/contact_consent_service"
+	"github.com/wapikit/wapikit/internal/core/secret_service"
+	"github.com/wapikit/wapikit/internal/core/segment_service"
 	"github.com/wapikit/wapikit/internal/core/utils"
 
 	. "github.com/go-jet/jet/v2/postgres"
@@ -66,60 +71,39 @@ func (rc *runningCampaign) nextContactsBatch() bool {
 	lastContactSentUuid, err := uuid.Parse(rc.LastContactIdSent)
 
 	if err != nil {
-		rc.Manager.Logger.Error("error parsing lastContactSentUuid", err.Error())
+		rc.Manager.Logger.Error("error parsing lastContactSentUuid", "error", err.Error())
 		return false
 	}
 
 	campaignUniqueId, err := uuid.Parse(rc.UniqueId.String())
 
 	if err != nil {
-		rc.Manager.Logger.Error("error parsing campaignUniqueId", err.Error())
+		rc.Manager.Logger.Error("error parsing campaignUniqueId", "error", err.Error())
 		return false
 	}
 
-	var contactLists []model.ContactList
-
-	listIdsQuery := SELECT(table.ContactList.AllColumns, table.CampaignList.AllColumns).
-		FROM(table.ContactList.INNER_JOIN(table.CampaignList, table.ContactList.UniqueId.EQ(table.CampaignList.ContactListId))).
-		WHERE(table.CampaignList.CampaignId.EQ(UUID(campaignUniqueId)))
-
-	err = listIdsQuery.Query(rc.Manager.Db, &contactLists)
+	// * the audience is the contacts of the campaign lists and of its segments, a contact in more than one is sent once
+	audienceCondition, hasAudience, err := segment_service.CampaignAudienceCondition(context.Background(), rc.Manager.Db, rc.Campaign)
 
 	if err != nil {
-		rc.Manager.Logger.Error("error fetching contact lists from the database", err.Error())
+		rc.Manager.Logger.Error("error building the audience of the campaign", err.Error(), nil)
 		return false
 	}
 
-	contactListIdExpression := make([]Expression, 0, len(contactLists))
-	for _, contactList := range contactLists {
-		contactListUuid, err := uuid.Parse(contactList.UniqueId.String())
-		if err != nil {
-			continue
-		}
-		contactListIdExpression = append(contactListIdExpression, UUID(contactListUuid))
-	}
-
-	var fromClause ReadableTable
-
-	if len(contactListIdExpression) > 0 {
-		fromClause = table.Contact.
-			INNER_JOIN(
-				table.ContactListContact, table.ContactListContact.ContactId.EQ(table.Contact.UniqueId).
-					AND(table.ContactListContact.ContactListId.IN(contactListIdExpression...)),
-			)
-	} else {
-		fromClause = table.Contact.
-			INNER_JOIN(
-				table.ContactListContact, table.ContactListContact.ContactId.EQ(table.Contact.UniqueId),
-			)
+	if !hasAudience {
+		return false
 	}
 
 	nextContactsQuery := WITH(
 		contactsCte.AS(
-			SELECT(table.Contact.AllColumns, table.ContactListContact.AllColumns).
-				FROM(fromClause).
-				WHERE(table.Contact.UniqueId.GT(UUID(lastContactSentUuid))).
-				DISTINCT(table.Contact.UniqueId).
+			SELECT(table.Contact.AllColumns).
+				FROM(table.Contact).
+				WHERE(
+					table.Contact.UniqueId.GT(UUID(lastContactSentUuid)).
+						AND(audienceCondition).
+						// * whatsapp requires the proof of the opt-in of the contacts to send them marketing messages
+						AND(contact_consent_service.GrantedCondition(model.ContactConsentCategoryEnum_Marketing)),
+				).
 				ORDER_BY(table.Contact.UniqueId).
 				LIMIT(100),
 		),
@@ -206,8 +190,9 @@ func (rc *runningCampaign) cleanUp() {
 }
 
 type CampaignManager struct {
-	Db     *sql.DB
-	Logger slog.Logger
+	Db      *sql.DB
+	Logger  slog.Logger
+	Secrets *secret_service.SecretService
 
 	runningCampaigns      map[string]*runningCampaign
 	runningCampaignsMutex sync.RWMutex
@@ -218,10 +203,11 @@ type CampaignManager struct {
 	rateLimiter *ratecounter.RateCounter
 }
 
-func NewCampaignManager(db *sql.DB, logger slog.Logger) *CampaignManager {
+func NewCampaignManager(db *sql.DB, logger slog.Logger, secrets *secret_service.SecretService) *CampaignManager {
 	return &CampaignManager{
-		Db:     db,
-		Logger: logger,
+		Db:      db,
+		Logger:  logger,
+		Secrets: secrets,
 
 		runningCampaigns:      make(map[string]*runningCampaign),
 		runningCampaignsMutex: sync.RWMutex{},
@@ -240,11 +226,17 @@ type CampaignMessage struct {
 }
 
 func (cm *CampaignManager) newRunningCampaign(dbCampaign model.Campaign, businessAccount model.WhatsappBusinessAccount) *runningCampaign {
+	accessToken, err := cm.Secrets.Decrypt(businessAccount.AccessToken)
+
+	if err != nil {
+		cm.Logger.Error("error decrypting business account access token", "error", err.Error())
+	}
+
 	campaign := runningCampaign{
 		Campaign: dbCampaign,
 		WapiClient: wapi.New(&wapi.ClientConfig{
 			BusinessAccountId: businessAccount.AccountId,
-			ApiAccessToken:    businessAccount.AccessToken,
+			ApiAccessToken:    accessToken,
 			WebhookSecret:     businessAccount.WebhookSecret,
 		}),
 		PhoneNumberToUse:  dbCampaign.PhoneNumber,
@@ -303,7 +295,7 @@ func (cm *CampaignManager) updatedCampaignStatus(campaignId string, status model
 	_, err := campaignUpdateQuery.Exec(cm.Db)
 
 	if err != nil {
-		cm.Logger.Error("error updating campaign status", err.Error())
+		cm.Logger.Error("error updating campaign status", "error", err.Error())
 		return false, err
 	}
 
@@ -329,7 +321,7 @@ func (cm *CampaignManager) scanCampaigns() {
 			for _, campaignId := range currentRunningCampaignIds {
 				campaignUuid, err := uuid.Parse(campaignId)
 				if err != nil {
-					cm.Logger.Error("error parsing campaign id", err.Error())
+					cm.Logger.Error("error parsing campaign id", "error", err.Error())
 					continue
 				}
 				runningCampaignExpression = append(runningCampaignExpression, UUID(campaignUuid))
@@ -351,7 +343,7 @@ func (cm *CampaignManager) scanCampaigns() {
 			err := campaignsQuery.QueryContext(context, cm.Db, &runningCampaigns)
 
 			if err != nil {
-				cm.Logger.Error("error fetching running campaigns from the database", err)
+				cm.Logger.Error("error fetching running campaigns from the database", "error", err.Error())
 			}
 
 			if len(runningCampaigns) == 0 {
@@ -398,7 +390,7 @@ func (cm *CampaignManager) processMessageQueue() {
 			err := cm.sendMessage(message)
 			// // ! TODO: send an update to the websocket server, updating the count of messages sent for the campaign
 			if err != nil {
-				cm.Logger.Error("error sending message to user", err.Error())
+				cm.Logger.Error("error sending message to user", "error", err.Error())
 				// ! TODO: broadcast this message to websocket via the API server event
 			}
 		}
@@ -442,7 +434,7 @@ func (cm *CampaignManager) sendMessage(message *CampaignMessage) error {
 
 	for _, component := range templateInUse.Components {
 
-		if len(component.Example.BodyText) > 0 || len(component.Example.HeaderText) > 0 || len(component.Example.HeaderText) > 0 {
+		if len(component.Example.BodyText) > 0 || len(component.Example.HeaderText) > 0 {
 			doTemplateRequireParameter = true
 		}
 
@@ -474,6 +466,19 @@ func (cm *CampaignManager) sendMessage(message *CampaignMessage) error {
 		return fmt.Errorf("template requires parameters, but no parameter found in the database")
 	}
 
+	// * parameters may use the variables of the contact, like {{contact.name}} or {{contact.<attribute>}}
+	variables := canned_response_service.RenderContext{Contact: message.Contact}.Variables()
+	for _, parameters := range [][]string{parameterStoredInDb.Header, parameterStoredInDb.Body, parameterStoredInDb.Buttons} {
+		for index, parameter := range parameters {
+			renderedParameter, unresolvedVariables := canned_response_service.Render(parameter, variables)
+			if len(unresolvedVariables) > 0 {
+				message.Campaign.ErrorCount.Add(1)
+				return fmt.Errorf("contact %s has no value for %s", message.Contact.UniqueId.String(), strings.Join(unresolvedVariables, ", "))
+			}
+			parameters[index] = renderedParameter
+		}
+	}
+
 	for _, component := range templateInUse.Components {
 		switch component.Type {
 		case "BODY":
@@ -683,7 +688,7 @@ func (cm *CampaignManager) sendMessage(message *CampaignMessage) error {
 		err := messageSentRecordQuery.Query(cm.Db, &messageSent)
 
 		if err != nil {
-			cm.Logger.Error("error saving message record to the database", err.Error())
+			cm.Logger.Error("error saving message record to the database", "error", err.Error())
 		}
 	}
 
@@ -0,0 +1,168 @@
+package health_manager
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/wapikit/wapikit/internal/core/notification"
+	"github.com/wapikit/wapikit/internal/core/secret_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+	"github.com/wapikit/wapikit/internal/core/whatsapp_service"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+var (
+	// access tokens of user accounts expire in about 60 days, checking a few times a day leaves enough room to replace them
+	healthCheckInterval     = 6 * time.Hour
+	businessAccountSettings = "/settings?tab=whatsapp-business-account"
+	healthNotificationType  = "WhatsappBusinessAccountHealth"
+)
+
+// HealthManager periodically verifies the credentials of every whatsapp business account,
+// and notifies the owners of the organization when a token is about to expire or has been revoked
+type HealthManager struct {
+	Db      *sql.DB
+	Logger  slog.Logger
+	Secrets *secret_service.SecretService
+}
+
+func NewHealthManager(db *sql.DB, logger slog.Logger, secrets *secret_service.SecretService) *HealthManager {
+	return &HealthManager{
+		Db:      db,
+		Logger:  logger,
+		Secrets: secrets,
+	}
+}
+
+func (hm *HealthManager) Run() {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	hm.checkBusinessAccounts()
+
+	for range ticker.C {
+		hm.checkBusinessAccounts()
+	}
+}
+
+func (hm *HealthManager) checkBusinessAccounts() {
+	var businessAccounts []model.WhatsappBusinessAccount
+
+	err := SELECT(table.WhatsappBusinessAccount.AllColumns).
+		FROM(table.WhatsappBusinessAccount).
+		Query(hm.Db, &businessAccounts)
+
+	if err != nil {
+		hm.Logger.Error("error fetching business accounts for the health check", "error", err.Error())
+		return
+	}
+
+	for _, businessAccount := range businessAccounts {
+		hm.checkBusinessAccount(context.Background(), businessAccount)
+	}
+}
+
+func (hm *HealthManager) checkBusinessAccount(ctx context.Context, businessAccount model.WhatsappBusinessAccount) {
+	accessToken, err := hm.Secrets.Decrypt(businessAccount.AccessToken)
+
+	if err != nil {
+		hm.Logger.Error("error decrypting business account access token", "businessAccountId", businessAccount.UniqueId.String(), "error", err.Error())
+		return
+	}
+
+	report, err := whatsapp_service.CheckCredentials(ctx, businessAccount.AccountId, accessToken)
+
+	if err != nil {
+		// * the graph api could not be reached, the last known status is kept
+		hm.Logger.Error("error checking business account credentials", "businessAccountId", businessAccount.UniqueId.String(), "error", err.Error())
+		return
+	}
+
+	_, err = whatsapp_service.SaveHealthReport(ctx, hm.Db, businessAccount.UniqueId, *report)
+
+	if err != nil {
+		hm.Logger.Error("error saving business account health", "businessAccountId", businessAccount.UniqueId.String(), "error", err.Error())
+		return
+	}
+
+	// * owners are only notified when the status changes, not on every check
+	if report.Status == businessAccount.HealthStatus || report.Status == model.WhatsappBusinessAccountHealthStatusEnum_Healthy {
+		return
+	}
+
+	hm.notifyOwners(ctx, businessAccount, *report)
+}
+
+func (hm *HealthManager) notifyOwners(ctx context.Context, businessAccount model.WhatsappBusinessAccount, report whatsapp_service.HealthReport) {
+	title := "WhatsApp access token is about to expire"
+	if report.Status == model.WhatsappBusinessAccountHealthStatusEnum_Invalid {
+		title = "WhatsApp access token is no longer valid"
+	}
+
+	description := fmt.Sprintf("The access token of the WhatsApp business account %s needs to be replaced: %s.", businessAccount.AccountId, report.Error)
+
+	var owners []model.OrganizationMember
+
+	err := SELECT(table.OrganizationMember.AllColumns).
+		FROM(table.OrganizationMember).
+		WHERE(
+			table.OrganizationMember.OrganizationId.EQ(UUID(businessAccount.OrganizationId)).
+				AND(table.OrganizationMember.AccessLevel.EQ(utils.EnumExpression(model.UserPermissionLevelEnum_Owner.String()))),
+		).
+		QueryContext(ctx, hm.Db, &owners)
+
+	if err != nil {
+		hm.Logger.Error("error fetching organization owners", "organizationId", businessAccount.OrganizationId.String(), "error", err.Error())
+		return
+	}
+
+	notifications := make([]model.Notification, 0, len(owners))
+	for _, owner := range owners {
+		notifications = append(notifications, model.Notification{
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+			CtaUrl:      &businessAccountSettings,
+			Title:       title,
+			Description: description,
+			Type:        &healthNotificationType,
+			UserId:      &owner.UserId,
+		})
+	}
+
+	if len(notifications) > 0 {
+		_, err = table.Notification.INSERT(table.Notification.MutableColumns).
+			MODELS(notifications).
+			ExecContext(ctx, hm.Db)
+
+		if err != nil {
+			hm.Logger.Error("error creating business account health notifications", "organizationId", businessAccount.OrganizationId.String(), "error", err.Error())
+		}
+	}
+
+	var organization model.Organization
+
+	err = SELECT(table.Organization.AllColumns).
+		FROM(table.Organization).
+		WHERE(table.Organization.UniqueId.EQ(UUID(businessAccount.OrganizationId))).
+		QueryContext(ctx, hm.Db, &organization)
+
+	if err != nil {
+		hm.Logger.Error("error fetching organization", "organizationId", businessAccount.OrganizationId.String(), "error", err.Error())
+		return
+	}
+
+	if organization.SlackWebhookUrl != nil && organization.SlackChannel != nil {
+		notification.SendSlackNotification(notification.SlackNotificationParams{
+			Title:      title,
+			Message:    description,
+			Channel:    *organization.SlackChannel,
+			WebhookUrl: *organization.SlackWebhookUrl,
+		})
+	}
+}
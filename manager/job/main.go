@@ -0,0 +1,127 @@
+package job_manager
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/wapikit/wapikit/internal/core/background_job_service"
+	cache "github.com/wapikit/wapikit/internal/core/redis"
+
+	"github.com/wapikit/wapikit/.db-generated/model"
+)
+
+var (
+	// queued jobs are picked up within this interval, a job runs until it is done so only the wait for the next one is affected
+	jobPollInterval = 5 * time.Second
+)
+
+// JobManager runs the queued background jobs one at a time, with the handler registered for their type
+type JobManager struct {
+	Db               *sql.DB
+	Logger           slog.Logger
+	Redis            *cache.RedisClient
+	EventChannelName string
+	handlers         map[model.BackgroundJobTypeEnum]background_job_service.Handler
+}
+
+func NewJobManager(db *sql.DB, logger slog.Logger, redis *cache.RedisClient, eventChannelName string) *JobManager {
+	return &JobManager{
+		Db:               db,
+		Logger:           logger,
+		Redis:            redis,
+		EventChannelName: eventChannelName,
+		handlers:         map[model.BackgroundJobTypeEnum]background_job_service.Handler{},
+	}
+}
+
+// Register sets the handler running the jobs of the type, it must be called before Run
+func (jm *JobManager) Register(jobType model.BackgroundJobTypeEnum, handler background_job_service.Handler) {
+	jm.handlers[jobType] = handler
+}
+
+func (jm *JobManager) Run() {
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		jm.failStaleJobs()
+
+		// * drain the queue before waiting for the next tick
+		for jm.runNextJob() {
+		}
+	}
+}
+
+func (jm *JobManager) failStaleJobs() {
+	jobs, err := background_job_service.FailStaleJobs(context.Background(), jm.Db)
+	if err != nil {
+		jm.Logger.Error("error failing stale background jobs", "error", err.Error())
+		return
+	}
+
+	for _, job := range jobs {
+		background_job_service.Publish(jm.Redis, jm.EventChannelName, job)
+	}
+}
+
+// runNextJob runs the oldest queued job, false when there was none to run
+func (jm *JobManager) runNextJob() bool {
+	ctx := context.Background()
+
+	job, err := background_job_service.ClaimNext(ctx, jm.Db)
+	if err != nil {
+		jm.Logger.Error("error claiming background job", "error", err.Error())
+		return false
+	}
+
+	if job == nil {
+		return false
+	}
+
+	background_job_service.Publish(jm.Redis, jm.EventChannelName, *job)
+
+	result, err := jm.execute(ctx, *job)
+
+	if err != nil {
+		jm.Logger.Error("background job failed", "jobId", job.UniqueId.String(), "type", job.Type.String(), "error", err.Error())
+		job, err = background_job_service.Fail(ctx, jm.Db, job.UniqueId, err.Error())
+	} else {
+		job, err = background_job_service.Complete(ctx, jm.Db, job.UniqueId, result)
+	}
+
+	if err != nil {
+		jm.Logger.Error("error finishing background job", "error", err.Error())
+		return true
+	}
+
+	background_job_service.Publish(jm.Redis, jm.EventChannelName, *job)
+	return true
+}
+
+func (jm *JobManager) execute(ctx context.Context, job model.BackgroundJob) (result interface{}, err error) {
+	handler, ok := jm.handlers[job.Type]
+	if !ok {
+		return nil, fmt.Errorf("no handler for jobs of type %s", job.Type.String())
+	}
+
+	// * a bug in a handler must not take the other jobs down with it
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("the job crashed: %v", recovered)
+		}
+	}()
+
+	progress := background_job_service.NewProgress(jm.Db, jm.Redis, jm.EventChannelName, job)
+
+	result, err = handler(ctx, job, progress)
+
+	// * the counters and item errors of a failed job are kept too, they show how far it got
+	if flushErr := progress.Flush(ctx); flushErr != nil && err == nil {
+		return nil, flushErr
+	}
+
+	return result, err
+}
@@ -0,0 +1,117 @@
+package lifecycle_manager
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/wapikit/wapikit/internal/core/conversation_lifecycle_service"
+	cache "github.com/wapikit/wapikit/internal/core/redis"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+var (
+	// snooze times and inactivity periods are both minute based, so checking every minute is precise enough
+	lifecycleCheckInterval = time.Minute
+	// the latest of the last message, the creation and the last status change of the conversation
+	lastActivityOfConversation = RawTimestampz(`GREATEST("Conversation"."UpdatedAt", COALESCE((SELECT MAX("Message"."CreatedAt") FROM public."Message" WHERE "Message"."ConversationId" = "Conversation"."UniqueId"), "Conversation"."CreatedAt"))`)
+	inactivityCutoff           = RawTimestampz(`now() - make_interval(mins => "Organization"."ConversationAutoCloseAfterMinutes")`)
+)
+
+// LifecycleManager wakes snoozed conversations once their snooze time has passed, and closes the conversations
+// which have been inactive for longer than their organization allows
+type LifecycleManager struct {
+	Db               *sql.DB
+	Logger           slog.Logger
+	Redis            *cache.RedisClient
+	EventChannelName string
+}
+
+func NewLifecycleManager(db *sql.DB, logger slog.Logger, redis *cache.RedisClient, eventChannelName string) *LifecycleManager {
+	return &LifecycleManager{
+		Db:               db,
+		Logger:           logger,
+		Redis:            redis,
+		EventChannelName: eventChannelName,
+	}
+}
+
+func (lm *LifecycleManager) Run() {
+	ticker := time.NewTicker(lifecycleCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		lm.wakeSnoozedConversations()
+		lm.closeInactiveConversations()
+	}
+}
+
+func (lm *LifecycleManager) wakeSnoozedConversations() {
+	var conversations []model.Conversation
+
+	err := SELECT(table.Conversation.AllColumns).
+		FROM(table.Conversation).
+		WHERE(
+			table.Conversation.Status.EQ(utils.EnumExpression(model.ConversationStatusEnum_Snoozed.String())).
+				AND(table.Conversation.SnoozedUntil.LT_EQ(TimestampzT(time.Now()))),
+		).
+		QueryContext(context.Background(), lm.Db, &conversations)
+
+	if err != nil {
+		lm.Logger.Error("error fetching conversations to wake up", "error", err.Error())
+		return
+	}
+
+	for _, conversation := range conversations {
+		lm.transition(conversation, model.ConversationStatusEnum_Active, model.ConversationTimelineEventTriggerEnum_SnoozeExpired)
+	}
+}
+
+func (lm *LifecycleManager) closeInactiveConversations() {
+	var conversations []model.Conversation
+
+	err := SELECT(table.Conversation.AllColumns).
+		FROM(table.Conversation.
+			INNER_JOIN(table.Organization, table.Organization.UniqueId.EQ(table.Conversation.OrganizationId)),
+		).
+		WHERE(
+			table.Organization.ConversationAutoCloseAfterMinutes.IS_NOT_NULL().
+				AND(table.Conversation.Status.IN(
+					utils.EnumExpression(model.ConversationStatusEnum_Active.String()),
+					utils.EnumExpression(model.ConversationStatusEnum_Resolved.String()),
+				)).
+				AND(lastActivityOfConversation.LT(inactivityCutoff)),
+		).
+		QueryContext(context.Background(), lm.Db, &conversations)
+
+	if err != nil {
+		lm.Logger.Error("error fetching inactive conversations", "error", err.Error())
+		return
+	}
+
+	for _, conversation := range conversations {
+		lm.transition(conversation, model.ConversationStatusEnum_Closed, model.ConversationTimelineEventTriggerEnum_Inactivity)
+	}
+}
+
+func (lm *LifecycleManager) transition(conversation model.Conversation, to model.ConversationStatusEnum, trigger model.ConversationTimelineEventTriggerEnum) {
+	_, timelineEvent, err := conversation_lifecycle_service.TransitionConversation(context.Background(), lm.Db, conversation, conversation_lifecycle_service.Transition{
+		To:      to,
+		Trigger: trigger,
+	})
+
+	if err != nil {
+		// * the conversation may have been changed by a member or the contact since it was fetched
+		if err != conversation_lifecycle_service.ErrInvalidTransition {
+			lm.Logger.Error("error changing status of conversation", "conversationId", conversation.UniqueId.String(), "status", to.String(), "error", err.Error())
+		}
+		return
+	}
+
+	conversation_lifecycle_service.PublishTransition(lm.Redis, lm.EventChannelName, conversation_lifecycle_service.ToSchema(*timelineEvent, ""), conversation.OrganizationId.String())
+}
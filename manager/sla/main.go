@@ -0,0 +1,288 @@
+package sla_manager
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wapikit/wapikit/internal/core/api_server_events"
+	"github.com/wapikit/wapikit/internal/core/notification"
+	cache "github.com/wapikit/wapikit/internal/core/redis"
+	"github.com/wapikit/wapikit/internal/core/routing_service"
+	"github.com/wapikit/wapikit/internal/core/utils"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
+)
+
+var (
+	// deadlines are checked every minute, policies are configured in minutes so a breach is never flagged later than that
+	breachCheckInterval  = time.Minute
+	slaNotificationType  = "SlaBreach"
+	conversationsCtaBase = "/conversations?id="
+)
+
+type slaTimer string
+
+const (
+	firstResponseTimer slaTimer = "first response"
+	resolutionTimer    slaTimer = "resolution"
+)
+
+// SlaManager flags the conversations whose sla deadlines went by without a response or resolution,
+// and runs the escalation action of their policy once for every breach
+type SlaManager struct {
+	Db               *sql.DB
+	Logger           slog.Logger
+	Redis            *cache.RedisClient
+	EventChannelName string
+}
+
+func NewSlaManager(db *sql.DB, logger slog.Logger, redis *cache.RedisClient, eventChannelName string) *SlaManager {
+	return &SlaManager{
+		Db:               db,
+		Logger:           logger,
+		Redis:            redis,
+		EventChannelName: eventChannelName,
+	}
+}
+
+func (sm *SlaManager) Run() {
+	ticker := time.NewTicker(breachCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sm.checkBreaches()
+	}
+}
+
+func (sm *SlaManager) checkBreaches() {
+	ctx := context.Background()
+	now := TimestampzT(time.Now())
+
+	var conversations []model.Conversation
+
+	err := SELECT(table.Conversation.AllColumns).
+		FROM(table.Conversation).
+		WHERE(
+			table.Conversation.Status.EQ(utils.EnumExpression(model.ConversationStatusEnum_Active.String())).
+				AND(table.Conversation.SlaPolicyId.IS_NOT_NULL()).
+				AND(
+					table.Conversation.FirstResponseDueAt.LT(now).
+						AND(table.Conversation.FirstRespondedAt.IS_NULL()).
+						AND(table.Conversation.FirstResponseBreachedAt.IS_NULL()).
+						OR(
+							table.Conversation.ResolutionDueAt.LT(now).
+								AND(table.Conversation.ResolvedAt.IS_NULL()).
+								AND(table.Conversation.ResolutionBreachedAt.IS_NULL()),
+						),
+				),
+		).
+		QueryContext(ctx, sm.Db, &conversations)
+
+	if err != nil {
+		sm.Logger.Error("error fetching conversations with overdue sla timers", "error", err.Error())
+		return
+	}
+
+	for _, conversation := range conversations {
+		if sm.flagBreach(ctx, conversation.UniqueId, table.Conversation.FirstResponseDueAt, table.Conversation.FirstRespondedAt, table.Conversation.FirstResponseBreachedAt) {
+			sm.escalate(ctx, conversation, firstResponseTimer)
+		}
+
+		if sm.flagBreach(ctx, conversation.UniqueId, table.Conversation.ResolutionDueAt, table.Conversation.ResolvedAt, table.Conversation.ResolutionBreachedAt) {
+			sm.escalate(ctx, conversation, resolutionTimer)
+		}
+	}
+}
+
+// flagBreach marks the timer of the conversation as breached, it returns false when the timer was stopped or already flagged in the meantime
+func (sm *SlaManager) flagBreach(ctx context.Context, conversationId uuid.UUID, dueAt, stoppedAt, breachedAt ColumnTimestampz) bool {
+	result, err := table.Conversation.UPDATE(breachedAt).
+		SET(TimestampzT(time.Now())).
+		WHERE(
+			table.Conversation.UniqueId.EQ(UUID(conversationId)).
+				AND(dueAt.LT(TimestampzT(time.Now()))).
+				AND(stoppedAt.IS_NULL()).
+				AND(breachedAt.IS_NULL()),
+		).
+		ExecContext(ctx, sm.Db)
+
+	if err != nil {
+		sm.Logger.Error("error flagging sla breach", "conversationId", conversationId.String(), "error", err.Error())
+		return false
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return err == nil && rowsAffected > 0
+}
+
+func (sm *SlaManager) escalate(ctx context.Context, conversation model.Conversation, timer slaTimer) {
+	var policy model.SlaPolicy
+
+	err := SELECT(table.SlaPolicy.AllColumns).
+		FROM(table.SlaPolicy).
+		WHERE(table.SlaPolicy.UniqueId.EQ(UUID(*conversation.SlaPolicyId))).
+		QueryContext(ctx, sm.Db, &policy)
+
+	if err != nil {
+		sm.Logger.Error("error fetching sla policy", "policyId", conversation.SlaPolicyId.String(), "error", err.Error())
+		return
+	}
+
+	if policy.EscalationAction == nil {
+		return
+	}
+
+	switch *policy.EscalationAction {
+	case model.SlaEscalationActionEnum_Notify:
+		sm.notify(ctx, conversation, policy, timer)
+	case model.SlaEscalationActionEnum_Reassign:
+		sm.reassign(ctx, conversation, policy)
+	case model.SlaEscalationActionEnum_Tag:
+		sm.tag(ctx, conversation, policy)
+	}
+}
+
+// notify lets the assignee of the conversation and the owners of the organization know about the breach
+func (sm *SlaManager) notify(ctx context.Context, conversation model.Conversation, policy model.SlaPolicy, timer slaTimer) {
+	title := fmt.Sprintf("SLA %s time breached", timer)
+	description := fmt.Sprintf("A conversation on %s went past the %s time of the %s policy.", conversation.PhoneNumberUsed, timer, policy.Name)
+	ctaUrl := conversationsCtaBase + conversation.UniqueId.String()
+
+	var members []model.OrganizationMember
+
+	err := SELECT(table.OrganizationMember.AllColumns).
+		FROM(table.OrganizationMember.
+			LEFT_JOIN(table.ConversationAssignment, table.ConversationAssignment.AssignedToOrganizationMemberId.EQ(table.OrganizationMember.UniqueId).
+				AND(table.ConversationAssignment.ConversationId.EQ(UUID(conversation.UniqueId))).
+				AND(table.ConversationAssignment.Status.EQ(utils.EnumExpression(model.ConversationAssignmentStatus_Assigned.String()))),
+			),
+		).
+		WHERE(
+			table.OrganizationMember.OrganizationId.EQ(UUID(conversation.OrganizationId)).
+				AND(
+					table.OrganizationMember.AccessLevel.EQ(utils.EnumExpression(model.UserPermissionLevelEnum_Owner.String())).
+						OR(table.ConversationAssignment.ConversationId.IS_NOT_NULL()),
+				),
+		).
+		QueryContext(ctx, sm.Db, &members)
+
+	if err != nil {
+		sm.Logger.Error("error fetching members to notify of the sla breach", "conversationId", conversation.UniqueId.String(), "error", err.Error())
+		return
+	}
+
+	notifications := make([]model.Notification, 0, len(members))
+	for _, member := range members {
+		notifications = append(notifications, model.Notification{
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+			CtaUrl:      &ctaUrl,
+			Title:       title,
+			Description: description,
+			Type:        &slaNotificationType,
+			UserId:      &member.UserId,
+		})
+	}
+
+	if len(notifications) > 0 {
+		_, err = table.Notification.INSERT(table.Notification.MutableColumns).
+			MODELS(notifications).
+			ExecContext(ctx, sm.Db)
+
+		if err != nil {
+			sm.Logger.Error("error creating sla breach notifications", "conversationId", conversation.UniqueId.String(), "error", err.Error())
+		}
+	}
+
+	var organization model.Organization
+
+	err = SELECT(table.Organization.AllColumns).
+		FROM(table.Organization).
+		WHERE(table.Organization.UniqueId.EQ(UUID(conversation.OrganizationId))).
+		QueryContext(ctx, sm.Db, &organization)
+
+	if err != nil {
+		sm.Logger.Error("error fetching organization", "organizationId", conversation.OrganizationId.String(), "error", err.Error())
+		return
+	}
+
+	if organization.SlackWebhookUrl != nil && organization.SlackChannel != nil {
+		notification.SendSlackNotification(notification.SlackNotificationParams{
+			Title:      title,
+			Message:    description,
+			Channel:    *organization.SlackChannel,
+			WebhookUrl: *organization.SlackWebhookUrl,
+		})
+	}
+}
+
+// reassign hands the conversation over to the escalation member of the policy
+func (sm *SlaManager) reassign(ctx context.Context, conversation model.Conversation, policy model.SlaPolicy) {
+	if policy.EscalationOrganizationMemberId == nil {
+		return
+	}
+
+	err := routing_service.AssignConversation(ctx, sm.Db, conversation.UniqueId, *policy.EscalationOrganizationMemberId)
+
+	if err != nil {
+		sm.Logger.Error("error reassigning breached conversation", "conversationId", conversation.UniqueId.String(), "error", err.Error())
+		return
+	}
+
+	conversationDetails := api_server_events.ConversationWithAllDetails{
+		Conversation: conversation,
+	}
+
+	err = SELECT(table.OrganizationMember.AllColumns, table.User.AllColumns).
+		FROM(table.OrganizationMember.
+			INNER_JOIN(table.User, table.User.UniqueId.EQ(table.OrganizationMember.UserId)),
+		).
+		WHERE(table.OrganizationMember.UniqueId.EQ(UUID(*policy.EscalationOrganizationMemberId))).
+		QueryContext(ctx, sm.Db, &conversationDetails.AssignedTo)
+
+	if err != nil {
+		sm.Logger.Error("error fetching escalation member", "memberId", policy.EscalationOrganizationMemberId.String(), "error", err.Error())
+		return
+	}
+
+	err = SELECT(table.Contact.AllColumns).
+		FROM(table.Contact).
+		WHERE(table.Contact.UniqueId.EQ(UUID(conversation.ContactId))).
+		QueryContext(ctx, sm.Db, &conversationDetails.Contact)
+
+	if err != nil {
+		sm.Logger.Error("error fetching contact of the conversation", "conversationId", conversation.UniqueId.String(), "error", err.Error())
+		return
+	}
+
+	event := api_server_events.NewChatAssignmentEvent(conversationDetails, conversationDetails.AssignedTo.UserId.String())
+	sm.Redis.PublishMessageToRedisChannel(sm.EventChannelName, event.ToJson())
+}
+
+// tag adds the escalation tag of the policy to the conversation, so that breached conversations can be filtered
+func (sm *SlaManager) tag(ctx context.Context, conversation model.Conversation, policy model.SlaPolicy) {
+	if policy.EscalationTagId == nil {
+		return
+	}
+
+	_, err := table.ConversationTag.INSERT(table.ConversationTag.AllColumns).
+		MODEL(model.ConversationTag{
+			ConversationId: conversation.UniqueId,
+			TagId:          *policy.EscalationTagId,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}).
+		ON_CONFLICT(table.ConversationTag.ConversationId, table.ConversationTag.TagId).
+		DO_NOTHING().
+		ExecContext(ctx, sm.Db)
+
+	if err != nil {
+		sm.Logger.Error("error tagging breached conversation", "conversationId", conversation.UniqueId.String(), "error", err.Error())
+	}
+}
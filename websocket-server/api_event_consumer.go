@@ -39,7 +39,22 @@ func (server *WebSocketServer) HandleApiServerEvents(ctx context.Context, app in
 			handleChatAssignmentEvent(app)
 
 		case api_server_events.ApiServerNewNotificationEvent:
-			handleNewNotificationEvent(app)
+			var event api_server_events.NewNotificationEvent
+			err := json.Unmarshal(apiServerEventData, &event)
+			if err != nil {
+				app.Logger.Error("unable to unmarshal new notification event", err.Error(), nil)
+				continue
+			}
+			handleNewNotificationEvent(app, server, event)
+
+		case api_server_events.ApiServerNewConversationNoteEvent:
+			var event api_server_events.NewConversationNoteEvent
+			err := json.Unmarshal(apiServerEventData, &event)
+			if err != nil {
+				app.Logger.Error("unable to unmarshal new conversation note event", err.Error(), nil)
+				continue
+			}
+			handleNewConversationNoteEvent(app, server, event)
 
 		case api_server_events.ApiServerNewMessageEvent:
 			var event api_server_events.NewMessageEvent
@@ -48,7 +63,7 @@ func (server *WebSocketServer) HandleApiServerEvents(ctx context.Context, app in
 				app.Logger.Error("unable to unmarshal new message event", err.Error(), nil)
 				continue
 			}
-			handleNewMessageEvent(app, *server, event)
+			handleNewMessageEvent(app, server, event)
 
 		case api_server_events.ApiServerChatUnAssignmentEvent:
 
@@ -57,9 +72,61 @@ func (server *WebSocketServer) HandleApiServerEvents(ctx context.Context, app in
 		case api_server_events.ApiServerReloadRequiredEvent:
 
 		case api_server_events.ApiServerConversationClosedEvent:
+			var event api_server_events.ConversationClosedEvent
+			err := json.Unmarshal(apiServerEventData, &event)
+			if err != nil {
+				app.Logger.Error("unable to unmarshal conversation closed event", err.Error(), nil)
+				continue
+			}
+			handleConversationClosedEvent(app, server, event)
+
+		case api_server_events.ApiServerConversationStatusChangedEvent:
+			var event api_server_events.ConversationStatusChangedEvent
+			err := json.Unmarshal(apiServerEventData, &event)
+			if err != nil {
+				app.Logger.Error("unable to unmarshal conversation status changed event", err.Error(), nil)
+				continue
+			}
+			handleConversationStatusChangedEvent(app, server, event)
+
+		case api_server_events.ApiServerMessageStatusChangedEvent:
+			var event api_server_events.MessageStatusChangedEvent
+			err := json.Unmarshal(apiServerEventData, &event)
+			if err != nil {
+				app.Logger.Error("unable to unmarshal message status changed event", err.Error(), nil)
+				continue
+			}
+			handleMessageStatusChangedEvent(app, server, event)
+
+		case api_server_events.ApiServerMessagesReadEvent:
+			var event api_server_events.MessagesReadEvent
+			err := json.Unmarshal(apiServerEventData, &event)
+			if err != nil {
+				app.Logger.Error("unable to unmarshal messages read event", err.Error(), nil)
+				continue
+			}
+			handleMessagesReadEvent(app, server, event)
+
+		case api_server_events.ApiServerBackgroundJobProgressEvent:
+			var event api_server_events.BackgroundJobProgressEvent
+			err := json.Unmarshal(apiServerEventData, &event)
+			if err != nil {
+				app.Logger.Error("unable to unmarshal background job progress event", err.Error(), nil)
+				continue
+			}
+			handleBackgroundJobProgressEvent(app, server, event)
 
 		case api_server_events.ApiServerNewConversationEvent:
 
+		case api_server_events.ApiServerPresenceChangedEvent:
+			var event api_server_events.PresenceChangedEvent
+			err := json.Unmarshal(apiServerEventData, &event)
+			if err != nil {
+				app.Logger.Error("unable to unmarshal presence changed event", err.Error(), nil)
+				continue
+			}
+			handlePresenceChangedEvent(app, server, event)
+
 		default:
 			app.Logger.Info("unknown event type received")
 		}
@@ -74,14 +141,34 @@ func handleChatAssignmentEvent(app interfaces.App) {
 
 }
 
-func handleNewNotificationEvent(app interfaces.App) {
-	// this will broadcast the notification to the frontend, which is websocketServerEvent
+func handleNewNotificationEvent(app interfaces.App, ws *WebSocketServer, event api_server_events.NewNotificationEvent) {
+	// * the notification is delivered to every tab the user has open in the organization it belongs to
+	newNotificationWebsocketEvent := NewNotificationWebsocketEvent(utils.GenerateWebsocketEventId(), NewNotificationEventData{
+		UserId:              event.UserId,
+		OrganizationId:      event.OrganizationId,
+		NotificationPayload: event.Notification,
+	})
 
-	// get the connection from the connections map
-	// send the message to the connection, by building an instance of the WebsocketEventTypeNewNotification
+	errors := ws.broadcast(ws.connectionsMatching(func(connection *WebsocketConnectionData) bool {
+		return connection.UserId == event.UserId && connection.OrganizationId == event.OrganizationId
+	}), newNotificationWebsocketEvent.toJson())
+
+	if len(errors) > 0 {
+		app.Logger.Error("error sending notification to client", "userId", event.UserId, "failedConnections", len(errors))
+	}
+}
+
+func handleNewConversationNoteEvent(app interfaces.App, ws *WebSocketServer, event api_server_events.NewConversationNoteEvent) {
+	// * notes are internal to the organization, so they are only broadcast to its members
+	newConversationNoteWebsocketEvent := NewConversationNoteWebsocketEvent(utils.GenerateWebsocketEventId(), event.Note)
+	errors := ws.broadcastToOrganization(event.OrganizationId, newConversationNoteWebsocketEvent.toJson())
+
+	if len(errors) > 0 {
+		app.Logger.Error("error sending conversation note to clients", "failedConnections", len(errors))
+	}
 }
 
-func handleNewMessageEvent(app interfaces.App, ws WebSocketServer, event api_server_events.NewMessageEvent) error {
+func handleNewMessageEvent(app interfaces.App, ws *WebSocketServer, event api_server_events.NewMessageEvent) error {
 	// * this event means we have received a new message from the whatsapp webhook, so we have to broadcast it to the frontend client if connected
 	fmt.Println("websocket server have received a new message to broadcast to the frontend", event.Message)
 	newMessageReceivedWebsocketEvent := NewMessageReceivedWebsocketEvent(utils.GenerateWebsocketEventId(), event.Message)
@@ -89,10 +176,70 @@ func handleNewMessageEvent(app interfaces.App, ws WebSocketServer, event api_ser
 	errors := ws.broadcastToAll(newMessageReceivedWebsocketEvent.toJson())
 
 	if len(errors) > 0 {
-		app.Logger.Error("error sending message to client", errors, nil)
+		app.Logger.Error("error sending message to client", "errors", errors)
 		return fmt.Errorf("error sending message to clients")
 		// ! TODO retry the message sending
 	}
 
 	return nil
 }
+
+func handlePresenceChangedEvent(app interfaces.App, ws *WebSocketServer, event api_server_events.PresenceChangedEvent) {
+	// * presence is broadcast to every member of the organization, including the other tabs of the member whose presence changed
+	presenceChangedWebsocketEvent := NewPresenceChangedWebsocketEvent(utils.GenerateWebsocketEventId(), event.Presence)
+	errors := ws.broadcastToOrganization(event.OrganizationId, presenceChangedWebsocketEvent.toJson())
+
+	if len(errors) > 0 {
+		app.Logger.Error("error sending presence to clients", "failedConnections", len(errors))
+	}
+}
+
+func handleConversationClosedEvent(app interfaces.App, ws *WebSocketServer, event api_server_events.ConversationClosedEvent) {
+	conversationClosedWebsocketEvent := NewConversationClosedWebsocketEvent(utils.GenerateWebsocketEventId(), event.ConversationId)
+	errors := ws.broadcastToOrganization(event.OrganizationId, conversationClosedWebsocketEvent.toJson())
+
+	if len(errors) > 0 {
+		app.Logger.Error("error sending conversation closed event to clients", "failedConnections", len(errors))
+	}
+}
+
+func handleConversationStatusChangedEvent(app interfaces.App, ws *WebSocketServer, event api_server_events.ConversationStatusChangedEvent) {
+	statusChangedWebsocketEvent := NewConversationStatusChangedWebsocketEvent(utils.GenerateWebsocketEventId(), event.TimelineEvent)
+	errors := ws.broadcastToOrganization(event.OrganizationId, statusChangedWebsocketEvent.toJson())
+
+	if len(errors) > 0 {
+		app.Logger.Error("error sending conversation status change to clients", "failedConnections", len(errors))
+	}
+}
+
+func handleMessageStatusChangedEvent(app interfaces.App, ws *WebSocketServer, event api_server_events.MessageStatusChangedEvent) {
+	messageStatusWebsocketEvent := NewMessageStatusChangedWebsocketEvent(utils.GenerateWebsocketEventId(), event.ConversationId, event.MessageId, event.Status)
+	errors := ws.broadcastToOrganization(event.OrganizationId, messageStatusWebsocketEvent.toJson())
+
+	if len(errors) > 0 {
+		app.Logger.Error("error sending message status change to clients", "failedConnections", len(errors))
+	}
+}
+
+func handleMessagesReadEvent(app interfaces.App, ws *WebSocketServer, event api_server_events.MessagesReadEvent) {
+	messagesReadWebsocketEvent := NewMessagesReadWebsocketEvent(utils.GenerateWebsocketEventId(), MessagesReadWebsocketEventData{
+		ConversationId:         event.ConversationId,
+		MessageIds:             event.MessageIds,
+		NumberOfUnreadMessages: event.NumberOfUnreadMessages,
+		ReadByMemberId:         event.ReadByMemberId,
+	})
+	errors := ws.broadcastToOrganization(event.OrganizationId, messagesReadWebsocketEvent.toJson())
+
+	if len(errors) > 0 {
+		app.Logger.Error("error sending messages read event to clients", "failedConnections", len(errors))
+	}
+}
+
+func handleBackgroundJobProgressEvent(app interfaces.App, ws *WebSocketServer, event api_server_events.BackgroundJobProgressEvent) {
+	backgroundJobProgressWebsocketEvent := NewBackgroundJobProgressWebsocketEvent(utils.GenerateWebsocketEventId(), event.Job)
+	errors := ws.broadcastToOrganization(event.OrganizationId, backgroundJobProgressWebsocketEvent.toJson())
+
+	if len(errors) > 0 {
+		app.Logger.Error("error sending background job progress to clients", "failedConnections", len(errors))
+	}
+}
@@ -1,20 +1,43 @@
 package websocket_server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"time"
 
-	"github.com/gorilla/websocket"
+	"github.com/google/uuid"
+	wapi "github.com/wapikit/wapi.go/pkg/client"
+	controller "github.com/wapikit/wapikit/api/controllers"
+	"github.com/wapikit/wapikit/internal/core/api_server_events"
+	"github.com/wapikit/wapikit/internal/core/conversation_note_service"
+	"github.com/wapikit/wapikit/internal/core/message_service"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/wapikit/wapikit/.db-generated/model"
+	table "github.com/wapikit/wapikit/.db-generated/table"
 )
 
 // * these are event handlers for the events received from the client
 
-func (s *WebSocketServer) handlePingEvent(messageId string, data json.RawMessage, connection *websocket.Conn) error {
+// whatsapp shows the typing indicator for 25 seconds, it is sent again shortly before it disappears while the member keeps typing
+const typingIndicatorInterval = 20 * time.Second
+
+var (
+	errInvalidConversationId = errors.New("Invalid conversation id")
+	errConversationNotFound  = errors.New("Conversation not found")
+)
+
+func (s *WebSocketServer) handlePingEvent(messageId string, data json.RawMessage, connection *WebsocketConnectionData) error {
 	logger := s.app.Logger
 	var eventData PingEventData
 	if err := json.Unmarshal(data, &eventData); err != nil {
 		logger.Error("error unmarshalling event data: %v", err.Error(), nil)
 		return err
 	}
+	// * pings double as heartbeats for the presence of the user
+	s.recordHeartbeat(connection)
 	ackBytes := NewAcknowledgementEvent(messageId, "Pong").toJson()
 	err := s.sendWebsocketEvent(connection, ackBytes)
 	if err != nil {
@@ -23,7 +46,7 @@ func (s *WebSocketServer) handlePingEvent(messageId string, data json.RawMessage
 	return err
 }
 
-func (server *WebSocketServer) handleMessageEvent(messageId string, data json.RawMessage, connection *websocket.Conn) error {
+func (server *WebSocketServer) handleMessageEvent(messageId string, data json.RawMessage, connection *WebsocketConnectionData) error {
 	logger := server.app.Logger
 	var eventData MessageEventData
 
@@ -31,13 +54,249 @@ func (server *WebSocketServer) handleMessageEvent(messageId string, data json.Ra
 	// ! TODO: get the logged in organization
 
 	if err := json.Unmarshal(data, &eventData); err != nil {
-		logger.Error("error unmarshalling event data: %v", err)
+		logger.Error("error unmarshalling event data", "error", err.Error())
 		return err
 	}
 	ackBytes := NewAcknowledgementEvent(messageId, "Message received").toJson()
 	err := server.sendWebsocketEvent(connection, ackBytes)
 	if err != nil {
-		logger.Error("error sending message to client: %v", err)
+		logger.Error("error sending message to client", "error", err.Error())
 	}
 	return err
 }
+
+// handleNewConversationNoteEvent leaves a note on a conversation of the organization of the connection, the note reaches
+// the other members through the api server event published for it, so only an acknowledgement is sent back here
+func (server *WebSocketServer) handleNewConversationNoteEvent(messageId string, data json.RawMessage, connection *WebsocketConnectionData) error {
+	ctx := context.Background()
+	var eventData NewConversationNoteEventData
+	if err := json.Unmarshal(data, &eventData); err != nil {
+		return err
+	}
+
+	conversation, err := server.fetchConversationOfConnection(ctx, eventData.ConversationId, connection)
+	if err != nil {
+		if err == errInvalidConversationId || err == errConversationNotFound {
+			return server.sendWebsocketEvent(connection, NewAcknowledgementEvent(messageId, err.Error()).toJson())
+		}
+		return err
+	}
+
+	memberUuid, err := uuid.Parse(connection.MemberId)
+	if err != nil {
+		return err
+	}
+
+	var author model.OrganizationMember
+
+	err = SELECT(table.OrganizationMember.AllColumns).
+		FROM(table.OrganizationMember).
+		WHERE(table.OrganizationMember.UniqueId.EQ(UUID(memberUuid))).
+		LIMIT(1).
+		QueryContext(ctx, server.app.Db, &author)
+
+	if err != nil {
+		return err
+	}
+
+	createdNote, err := conversation_note_service.CreateNote(ctx, server.app.Db, *conversation, author, connection.Username, eventData.Content, eventData.MentionedMemberIds)
+
+	if err != nil {
+		switch err {
+		case conversation_note_service.ErrEmptyNote, conversation_note_service.ErrMemberNotFound:
+			return server.sendWebsocketEvent(connection, NewAcknowledgementEvent(messageId, err.Error()).toJson())
+		}
+		return err
+	}
+
+	conversation_note_service.PublishNoteEvents(server.app.Redis, server.app.Constants.RedisEventChannelName, *createdNote)
+
+	return server.sendWebsocketEvent(connection, NewAcknowledgementEvent(messageId, "Note created").toJson())
+}
+
+// handleMessageReadEvent marks the messages of the contact as read once a member has seen them, sends the blue ticks to
+// the contact and updates the unread count in every inbox of the organization
+func (server *WebSocketServer) handleMessageReadEvent(messageId string, data json.RawMessage, connection *WebsocketConnectionData) error {
+	ctx := context.Background()
+	logger := server.app.Logger
+	var eventData MessageReadEventData
+	if err := json.Unmarshal(data, &eventData); err != nil {
+		return err
+	}
+
+	conversation, err := server.fetchConversationOfConnection(ctx, eventData.ConversationId, connection)
+	if err != nil {
+		if err == errInvalidConversationId || err == errConversationNotFound {
+			return server.sendWebsocketEvent(connection, NewAcknowledgementEvent(messageId, err.Error()).toJson())
+		}
+		return err
+	}
+
+	var upToMessageId *uuid.UUID
+	if eventData.MessageId != nil {
+		messageUuid, err := uuid.Parse(*eventData.MessageId)
+		if err != nil {
+			return server.sendWebsocketEvent(connection, NewAcknowledgementEvent(messageId, "Invalid message id").toJson())
+		}
+		upToMessageId = &messageUuid
+	}
+
+	readMessages, err := message_service.MarkInboundMessagesRead(ctx, server.app.Db, conversation.UniqueId, upToMessageId)
+	if err != nil {
+		if err == message_service.ErrMessageNotFound {
+			return server.sendWebsocketEvent(connection, NewAcknowledgementEvent(messageId, err.Error()).toJson())
+		}
+		return err
+	}
+
+	if len(readMessages) == 0 {
+		return server.sendWebsocketEvent(connection, NewAcknowledgementEvent(messageId, "No unread messages").toJson())
+	}
+
+	// * whatsapp shows the earlier messages of the chat as read too, so the receipt is only sent for the latest message
+	lastWhatsAppMessageId := ""
+	for index := len(readMessages) - 1; index >= 0; index-- {
+		if readMessages[index].WhatsAppMessageId != nil && *readMessages[index].WhatsAppMessageId != "" {
+			lastWhatsAppMessageId = *readMessages[index].WhatsAppMessageId
+			break
+		}
+	}
+
+	if lastWhatsAppMessageId != "" {
+		wapiClient, err := server.wapiClientOfOrganization(ctx, conversation.OrganizationId)
+		if err == nil {
+			err = message_service.MarkAsRead(wapiClient, conversation.PhoneNumberUsed, lastWhatsAppMessageId)
+		}
+
+		// * the messages stay read for the team even when whatsapp could not be told
+		if err != nil {
+			logger.Error("error sending read receipt to whatsapp", "conversationId", conversation.UniqueId.String(), "error", err.Error())
+		}
+	}
+
+	unreadCounts, err := message_service.CountUnreadMessages(ctx, server.app.Db, []uuid.UUID{conversation.UniqueId})
+	if err != nil {
+		return err
+	}
+
+	readMessageIds := make([]string, 0, len(readMessages))
+	for _, readMessage := range readMessages {
+		readMessageIds = append(readMessageIds, readMessage.UniqueId.String())
+	}
+
+	messagesReadEvent := api_server_events.NewMessagesReadEvent(
+		conversation.OrganizationId.String(),
+		conversation.UniqueId.String(),
+		readMessageIds,
+		unreadCounts[conversation.UniqueId],
+		connection.MemberId,
+	)
+
+	err = server.app.Redis.PublishMessageToRedisChannel(server.app.Constants.RedisEventChannelName, messagesReadEvent.ToJson())
+	if err != nil {
+		logger.Error("error publishing messages read event", "error", err.Error())
+	}
+
+	return server.sendWebsocketEvent(connection, NewAcknowledgementEvent(messageId, "Messages read").toJson())
+}
+
+// handleTypingEvent shows the contact of the conversation that the member is writing a reply
+func (server *WebSocketServer) handleTypingEvent(messageId string, data json.RawMessage, connection *WebsocketConnectionData) error {
+	ctx := context.Background()
+	var eventData TypingEventData
+	if err := json.Unmarshal(data, &eventData); err != nil {
+		return err
+	}
+
+	conversation, err := server.fetchConversationOfConnection(ctx, eventData.ConversationId, connection)
+	if err != nil {
+		if err == errInvalidConversationId || err == errConversationNotFound {
+			return server.sendWebsocketEvent(connection, NewAcknowledgementEvent(messageId, err.Error()).toJson())
+		}
+		return err
+	}
+
+	conversationId := conversation.UniqueId.String()
+
+	// * clients send the event on every key stroke, the indicator is still showing from the last one
+	if sentAt, ok := connection.typingIndicatorSentAt[conversationId]; ok && time.Since(sentAt) < typingIndicatorInterval {
+		return server.sendWebsocketEvent(connection, NewAcknowledgementEvent(messageId, "Typing indicator showing").toJson())
+	}
+
+	lastWhatsAppMessageId, err := message_service.FetchLastInboundWhatsAppMessageId(ctx, server.app.Db, conversation.UniqueId)
+	if err != nil {
+		return err
+	}
+
+	// * whatsapp ties the indicator to a message of the contact
+	if lastWhatsAppMessageId == "" {
+		return server.sendWebsocketEvent(connection, NewAcknowledgementEvent(messageId, "No message of the contact to reply to").toJson())
+	}
+
+	wapiClient, err := server.wapiClientOfOrganization(ctx, conversation.OrganizationId)
+	if err != nil {
+		return err
+	}
+
+	err = message_service.SendTypingIndicator(wapiClient, conversation.PhoneNumberUsed, lastWhatsAppMessageId)
+	if err != nil {
+		return err
+	}
+
+	if connection.typingIndicatorSentAt == nil {
+		connection.typingIndicatorSentAt = map[string]time.Time{}
+	}
+	connection.typingIndicatorSentAt[conversationId] = time.Now()
+
+	return server.sendWebsocketEvent(connection, NewAcknowledgementEvent(messageId, "Typing indicator sent").toJson())
+}
+
+// fetchConversationOfConnection loads the conversation, conversations of other organizations than the one of the connection are not found
+func (server *WebSocketServer) fetchConversationOfConnection(ctx context.Context, conversationId string, connection *WebsocketConnectionData) (*model.Conversation, error) {
+	conversationUuid, err := uuid.Parse(conversationId)
+	if err != nil {
+		return nil, errInvalidConversationId
+	}
+
+	organizationUuid, err := uuid.Parse(connection.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	var conversation model.Conversation
+
+	err = SELECT(table.Conversation.AllColumns).
+		FROM(table.Conversation).
+		WHERE(
+			table.Conversation.UniqueId.EQ(UUID(conversationUuid)).
+				AND(table.Conversation.OrganizationId.EQ(UUID(organizationUuid))),
+		).
+		LIMIT(1).
+		QueryContext(ctx, server.app.Db, &conversation)
+
+	if err != nil {
+		if err.Error() == qrm.ErrNoRows.Error() {
+			return nil, errConversationNotFound
+		}
+		return nil, err
+	}
+
+	return &conversation, nil
+}
+
+// wapiClientOfOrganization returns the whatsapp api client of the business account the organization has connected
+func (server *WebSocketServer) wapiClientOfOrganization(ctx context.Context, organizationId uuid.UUID) (*wapi.Client, error) {
+	var businessAccount model.WhatsappBusinessAccount
+
+	err := SELECT(table.WhatsappBusinessAccount.AllColumns).
+		FROM(table.WhatsappBusinessAccount).
+		WHERE(table.WhatsappBusinessAccount.OrganizationId.EQ(UUID(organizationId))).
+		LIMIT(1).
+		QueryContext(ctx, server.app.Db, &businessAccount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return controller.NewWapiClient(&server.app, businessAccount)
+}
@@ -22,6 +22,14 @@ const (
 	WebsocketEventTypeConversationClosed     WebsocketEventType = "ConversationClosedEvent"
 	WebsocketEventTypeNewConversation        WebsocketEventType = "NewConversationEvent"
 	WebsocketEventTypePing                   WebsocketEventType = "PingEvent"
+	WebsocketEventTypePresenceChanged        WebsocketEventType = "PresenceChangedEvent"
+	// sent by clients to leave a note, and to clients when a note has been left on a conversation of their organization
+	WebsocketEventTypeNewConversationNote       WebsocketEventType = "NewConversationNoteEvent"
+	WebsocketEventTypeConversationStatusChanged WebsocketEventType = "ConversationStatusChangedEvent"
+	WebsocketEventTypeMessageStatusChanged      WebsocketEventType = "MessageStatusChangedEvent"
+	// sent by clients while a member writes a reply, relayed to the contact as the typing indicator of whatsapp
+	WebsocketEventTypeTyping                WebsocketEventType = "TypingEvent"
+	WebsocketEventTypeBackgroundJobProgress WebsocketEventType = "BackgroundJobProgressEvent"
 )
 
 type WebsocketEvent struct {
@@ -100,18 +108,49 @@ type NotificationReadEventData struct {
 	} `json:"data"`
 }
 
+// MessageReadEventData is sent by clients when a member has seen the messages of a conversation, the messages up to the
+// given one are marked as read, all of them when it is left out
 type MessageReadEventData struct {
-	BaseWebsocketEventData `json:"-,inline"`
-	Data                   struct {
-		MessageID string `json:"messageId"`
-	} `json:"data"`
+	ConversationId string  `json:"conversationId"`
+	MessageId      *string `json:"messageId,omitempty"`
+}
+
+type MessagesReadWebsocketEventData struct {
+	ConversationId         string   `json:"conversationId"`
+	MessageIds             []string `json:"messageIds"`
+	NumberOfUnreadMessages int      `json:"numberOfUnreadMessages"`
+	ReadByMemberId         string   `json:"readByMemberId"`
+}
+
+// NewMessagesReadWebsocketEvent lets every inbox of the organization know that the messages have been read
+func NewMessagesReadWebsocketEvent(eventId string, data MessagesReadWebsocketEventData) *WebsocketEvent {
+	marshalData, _ := json.Marshal(data)
+
+	return &WebsocketEvent{
+		EventName: WebsocketEventTypeMessageRead,
+		EventId:   eventId,
+		Data:      marshalData,
+	}
+}
+
+type TypingEventData struct {
+	ConversationId string `json:"conversationId"`
 }
 
 type NewNotificationEventData struct {
-	BaseWebsocketEventData `json:"-,inline"`
-	Data                   struct {
-		NotificationID string `json:"notificationId"`
-	} `json:"data"`
+	UserId              string                       `json:"userId"`
+	OrganizationId      string                       `json:"organizationId"`
+	NotificationPayload api_types.NotificationSchema `json:"notificationPayload"`
+}
+
+func NewNotificationWebsocketEvent(eventId string, data NewNotificationEventData) *WebsocketEvent {
+	marshalData, _ := json.Marshal(data)
+
+	return &WebsocketEvent{
+		EventName: WebsocketEventTypeNewNotification,
+		EventId:   eventId,
+		Data:      marshalData,
+	}
 }
 
 type SystemReloadEventData struct {
@@ -145,3 +184,79 @@ type NewConversationEventData struct {
 		ConversationID string `json:"conversationId"`
 	} `json:"data"`
 }
+
+func NewPresenceChangedWebsocketEvent(eventId string, presence api_types.MemberPresenceSchema) *WebsocketEvent {
+	// * the presence schema only holds strings and a timestamp, marshalling it can not fail
+	marshalData, _ := json.Marshal(presence)
+
+	return &WebsocketEvent{
+		EventName: WebsocketEventTypePresenceChanged,
+		EventId:   eventId,
+		Data:      marshalData,
+	}
+}
+
+// NewConversationNoteEventData is what clients send to leave a note on a conversation
+type NewConversationNoteEventData struct {
+	ConversationId     string   `json:"conversationId"`
+	Content            string   `json:"content"`
+	MentionedMemberIds []string `json:"mentionedMemberIds"`
+}
+
+func NewConversationNoteWebsocketEvent(eventId string, note api_types.ConversationNoteSchema) *WebsocketEvent {
+	marshalData, _ := json.Marshal(note)
+
+	return &WebsocketEvent{
+		EventName: WebsocketEventTypeNewConversationNote,
+		EventId:   eventId,
+		Data:      marshalData,
+	}
+}
+
+func NewConversationClosedWebsocketEvent(eventId string, conversationId string) *WebsocketEvent {
+	marshalData, _ := json.Marshal(map[string]string{
+		"conversationId": conversationId,
+	})
+
+	return &WebsocketEvent{
+		EventName: WebsocketEventTypeConversationClosed,
+		EventId:   eventId,
+		Data:      marshalData,
+	}
+}
+
+func NewConversationStatusChangedWebsocketEvent(eventId string, timelineEvent api_types.ConversationTimelineEventSchema) *WebsocketEvent {
+	marshalData, _ := json.Marshal(timelineEvent)
+
+	return &WebsocketEvent{
+		EventName: WebsocketEventTypeConversationStatusChanged,
+		EventId:   eventId,
+		Data:      marshalData,
+	}
+}
+
+func NewMessageStatusChangedWebsocketEvent(eventId, conversationId, messageId string, status api_types.MessageStatusEnum) *WebsocketEvent {
+	marshalData, _ := json.Marshal(map[string]interface{}{
+		"conversationId": conversationId,
+		"messageId":      messageId,
+		"status":         status,
+	})
+
+	return &WebsocketEvent{
+		EventName: WebsocketEventTypeMessageStatusChanged,
+		EventId:   eventId,
+		Data:      marshalData,
+	}
+}
+
+func NewBackgroundJobProgressWebsocketEvent(eventId string, job api_types.BackgroundJobSchema) *WebsocketEvent {
+	marshalData, _ := json.Marshal(map[string]interface{}{
+		"job": job,
+	})
+
+	return &WebsocketEvent{
+		EventName: WebsocketEventTypeBackgroundJobProgress,
+		EventId:   eventId,
+		Data:      marshalData,
+	}
+}
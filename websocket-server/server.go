@@ -8,14 +8,19 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	. "github.com/go-jet/jet/v2/postgres"
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 	"github.com/wapikit/wapikit/.db-generated/model"
 	table "github.com/wapikit/wapikit/.db-generated/table"
 
+	"github.com/wapikit/wapikit/internal/core/api_server_events"
+	"github.com/wapikit/wapikit/internal/core/presence_service"
+	"github.com/wapikit/wapikit/internal/core/session_service"
 	"github.com/wapikit/wapikit/internal/interfaces"
 )
 
@@ -25,20 +30,31 @@ import (
 // ! 3. there must be a retry mechanism for sending message if in case the
 
 type WebsocketConnectionData struct {
+	// every tab of a user opens its own connection, this identifies the connection among them
+	ConnectionId   string                        `json:"connectionId"`
 	UserId         string                        `json:"userId"`
+	MemberId       string                        `json:"memberId"`
 	Token          string                        `json:"token"`
 	AccessLevel    model.UserPermissionLevelEnum `json:"access_level"`
 	Connection     *websocket.Conn               `json:"connection"`
 	OrganizationId string                        `json:"organizationId"`
 	Email          string                        `json:"email"`
 	Username       string                        `json:"username"`
+
+	// gorilla connections support only one concurrent writer, events are written from the api event consumer too
+	writeMutex      sync.Mutex
+	lastHeartbeatAt time.Time
+	// when the typing indicator was last sent to the contact of each conversation, only the event loop of the connection uses it
+	typingIndicatorSentAt map[string]time.Time
 }
 
 type WebSocketServer struct {
-	upgrader    websocket.Upgrader
-	connections map[string]*WebsocketConnectionData
-	server      *echo.Echo
-	app         interfaces.App
+	upgrader websocket.Upgrader
+	// connections are keyed by user id, then by connection id
+	connections      map[string]map[string]*WebsocketConnectionData
+	connectionsMutex sync.RWMutex
+	server           *echo.Echo
+	app              interfaces.App
 }
 
 func newWebSocketServer(server *echo.Echo, app interfaces.App) *WebSocketServer {
@@ -51,10 +67,52 @@ func newWebSocketServer(server *echo.Echo, app interfaces.App) *WebSocketServer
 			},
 			// EnableCompression: true,
 		},
-		connections: make(map[string]*WebsocketConnectionData),
+		connections: make(map[string]map[string]*WebsocketConnectionData),
 	}
 }
 
+func (server *WebSocketServer) addConnection(connectionData *WebsocketConnectionData) {
+	server.connectionsMutex.Lock()
+	defer server.connectionsMutex.Unlock()
+
+	if _, ok := server.connections[connectionData.UserId]; !ok {
+		server.connections[connectionData.UserId] = make(map[string]*WebsocketConnectionData)
+	}
+	server.connections[connectionData.UserId][connectionData.ConnectionId] = connectionData
+}
+
+func (server *WebSocketServer) removeConnection(connectionData *WebsocketConnectionData) {
+	server.connectionsMutex.Lock()
+	defer server.connectionsMutex.Unlock()
+
+	userConnections, ok := server.connections[connectionData.UserId]
+	if !ok {
+		return
+	}
+
+	delete(userConnections, connectionData.ConnectionId)
+	if len(userConnections) == 0 {
+		delete(server.connections, connectionData.UserId)
+	}
+}
+
+// connectionsMatching returns a snapshot of the connections passing the filter, so events can be sent without holding the lock
+func (server *WebSocketServer) connectionsMatching(filter func(*WebsocketConnectionData) bool) []*WebsocketConnectionData {
+	server.connectionsMutex.RLock()
+	defer server.connectionsMutex.RUnlock()
+
+	var matchingConnections []*WebsocketConnectionData
+	for _, userConnections := range server.connections {
+		for _, connection := range userConnections {
+			if filter(connection) {
+				matchingConnections = append(matchingConnections, connection)
+			}
+		}
+	}
+
+	return matchingConnections
+}
+
 func (server *WebSocketServer) authorizeConnectionRequest(ctx echo.Context) (*WebsocketConnectionData, error) {
 	token := ctx.QueryParam("token")
 	if token == "" {
@@ -101,6 +159,10 @@ func (server *WebSocketServer) authorizeConnectionRequest(ctx echo.Context) (*We
 			return nil, echo.NewHTTPError(echo.ErrUnauthorized.Code, "Unauthorized access")
 		}
 
+		if sessionId, ok := castedPayload["session_id"].(string); ok && session_service.IsSessionRevoked(app.Redis, sessionId) {
+			return nil, echo.NewHTTPError(echo.ErrUnauthorized.Code, "Session has been revoked")
+		}
+
 		user := UserWithOrgDetails{}
 		userQuery := SELECT(
 			table.User.AllColumns,
@@ -133,7 +195,9 @@ func (server *WebSocketServer) authorizeConnectionRequest(ctx echo.Context) (*We
 			if org.Organization.UniqueId.String() == organizationId {
 				accessLevel := model.UserPermissionLevelEnum(org.MemberDetails.AccessLevel)
 				connectionData := WebsocketConnectionData{
+					ConnectionId:   uuid.New().String(),
 					UserId:         user.User.UniqueId.String(),
+					MemberId:       org.MemberDetails.OrganizationMember.UniqueId.String(),
 					Token:          token,
 					AccessLevel:    accessLevel,
 					OrganizationId: org.Organization.UniqueId.String(),
@@ -174,10 +238,15 @@ func (server *WebSocketServer) handleWebSocket(ctx echo.Context) error {
 	}
 	defer ws.Close()
 
-	// * Store connection data
+	// * Store connection data, a user may have several connections open at once, one per tab
 	connectionData.Connection = ws
-	server.connections[connectionData.UserId] = connectionData
-	defer delete(server.connections, connectionData.UserId)
+	server.addConnection(connectionData)
+	server.recordHeartbeat(connectionData)
+
+	defer func() {
+		server.removeConnection(connectionData)
+		server.recordDisconnect(connectionData)
+	}()
 
 	// * Create a dedicated channel for receiving websocket events from this connection
 	websocketEventChannel := make(chan []byte)
@@ -206,22 +275,34 @@ func (server *WebSocketServer) handleWebSocket(ctx echo.Context) error {
 	for websocketEventData := range websocketEventChannel {
 		event := new(WebsocketEvent)
 		if err := json.Unmarshal(websocketEventData, &event); err != nil {
-			logger.Error("error unmarshalling message: %v\n", err)
+			logger.Error("error unmarshalling message", "error", err.Error())
 			// Send an error message to the client (optional)
-			server.sendWebsocketEvent(ws, []byte(`{"error": "Invalid message format"}`))
+			server.sendWebsocketEvent(connectionData, []byte(`{"error": "Invalid message format"}`))
 			continue
 		}
 
 		switch event.EventName {
 		case WebsocketEventTypePing:
-			if err := server.handlePingEvent(event.EventId, event.Data, ws); err != nil {
+			if err := server.handlePingEvent(event.EventId, event.Data, connectionData); err != nil {
 				logger.Error("error handling ping: %v", err.Error(), nil)
 			}
 		case WebsocketEventTypeMessage:
 			// ! TODO: user from the frontend has sent a new message to a contact
+		case WebsocketEventTypeNewConversationNote:
+			if err := server.handleNewConversationNoteEvent(event.EventId, event.Data, connectionData); err != nil {
+				logger.Error("error handling new conversation note", "error", err.Error())
+			}
+		case WebsocketEventTypeMessageRead:
+			if err := server.handleMessageReadEvent(event.EventId, event.Data, connectionData); err != nil {
+				logger.Error("error handling message read", "error", err.Error())
+			}
+		case WebsocketEventTypeTyping:
+			if err := server.handleTypingEvent(event.EventId, event.Data, connectionData); err != nil {
+				logger.Error("error handling typing", "error", err.Error())
+			}
 
 		default:
-			logger.Warn("Unknown WebSocket event: %s", event.EventName, nil)
+			logger.Warn("unknown websocket event", "eventName", event.EventName)
 		}
 	}
 
@@ -230,12 +311,24 @@ func (server *WebSocketServer) handleWebSocket(ctx echo.Context) error {
 }
 
 func (ws *WebSocketServer) broadcastToAll(message []byte) []error {
+	return ws.broadcast(ws.connectionsMatching(func(*WebsocketConnectionData) bool {
+		return true
+	}), message)
+}
+
+func (ws *WebSocketServer) broadcastToOrganization(organizationId string, message []byte) []error {
+	return ws.broadcast(ws.connectionsMatching(func(connection *WebsocketConnectionData) bool {
+		return connection.OrganizationId == organizationId
+	}), message)
+}
+
+func (ws *WebSocketServer) broadcast(connections []*WebsocketConnectionData, message []byte) []error {
 	logger := ws.app.Logger
 
 	var errors []error
 
-	for _, conn := range ws.connections {
-		err := ws.sendWebsocketEvent(conn.Connection, message)
+	for _, conn := range connections {
+		err := ws.sendWebsocketEvent(conn, message)
 		if err != nil {
 			// Handle error (e.g., log, remove closed connection)
 			logger.Info("error sending message to client: %v", err.Error(), nil)
@@ -247,7 +340,7 @@ func (ws *WebSocketServer) broadcastToAll(message []byte) []error {
 	return errors
 }
 
-func (ws *WebSocketServer) sendWebsocketEvent(conn *websocket.Conn, eventBytes []byte) error {
+func (ws *WebSocketServer) sendWebsocketEvent(connectionData *WebsocketConnectionData, eventBytes []byte) error {
 
 	var buffer bytes.Buffer
 
@@ -256,16 +349,60 @@ func (ws *WebSocketServer) sendWebsocketEvent(conn *websocket.Conn, eventBytes [
 	// ! TODO: implement a retry mechanism to send the message to the client, also as we know every message will be acknowledged, so we can wait for the acknowledgment and then retry if error
 
 	logger := ws.app.Logger
-	err := conn.WriteMessage(websocket.BinaryMessage, buffer.Bytes())
+
+	connectionData.writeMutex.Lock()
+	err := connectionData.Connection.WriteMessage(websocket.BinaryMessage, buffer.Bytes())
+	connectionData.writeMutex.Unlock()
+
 	if err != nil {
-		logger.Error("error sending websocket event to client: %v", err)
-		conn.Close()
-		delete(ws.connections, conn.RemoteAddr().String()) // Cleanup
+		logger.Error("error sending websocket event to client", "error", err.Error())
+		// * closing the connection stops its read loop, which removes it from the connections
+		connectionData.Connection.Close()
 	}
 
 	return err
 }
 
+// recordHeartbeat refreshes the presence of the user, heartbeats are throttled so a ping every few seconds does not hit redis every time
+func (ws *WebSocketServer) recordHeartbeat(connectionData *WebsocketConnectionData) {
+	if ws.app.Redis == nil || time.Since(connectionData.lastHeartbeatAt) < presence_service.HeartbeatInterval {
+		return
+	}
+	connectionData.lastHeartbeatAt = time.Now()
+
+	presence, isChanged, err := presence_service.Heartbeat(context.Background(), ws.app.Redis, connectionData.OrganizationId, connectionData.UserId, connectionData.ConnectionId)
+	if err != nil {
+		ws.app.Logger.Error("error recording presence heartbeat", "error", err.Error())
+		return
+	}
+
+	if isChanged {
+		ws.publishPresenceChange(connectionData, *presence)
+	}
+}
+
+func (ws *WebSocketServer) recordDisconnect(connectionData *WebsocketConnectionData) {
+	if ws.app.Redis == nil {
+		return
+	}
+
+	presence, isChanged, err := presence_service.Disconnect(context.Background(), ws.app.Redis, connectionData.OrganizationId, connectionData.UserId, connectionData.ConnectionId)
+	if err != nil {
+		ws.app.Logger.Error("error recording presence disconnect", "error", err.Error())
+		return
+	}
+
+	if isChanged {
+		ws.publishPresenceChange(connectionData, *presence)
+	}
+}
+
+// publishPresenceChange goes through the redis channel rather than broadcasting directly, so teammates connected to other websocket servers get it too
+func (ws *WebSocketServer) publishPresenceChange(connectionData *WebsocketConnectionData, presence presence_service.Presence) {
+	event := api_server_events.NewPresenceChangedEvent(connectionData.OrganizationId, presence.ToSchema(connectionData.MemberId))
+	ws.app.Redis.PublishMessageToRedisChannel(ws.app.Constants.RedisEventChannelName, event.ToJson())
+}
+
 func InitWebsocketServer(app *interfaces.App, wg *sync.WaitGroup) *WebSocketServer {
 	logger := app.Logger
 	koa := app.Koa